@@ -0,0 +1,311 @@
+// Package binast stores parsed documents in a compact binary format that loads
+// much faster than JSON text, so a document can be parsed once and shared.
+//
+// An encoded document starts with the magic bytes "JPAST" and a format
+// version byte, followed by the root value. Each value is a tag byte and
+// its payload:
+//
+//	'n', 't', 'f'  null, true, false; no payload
+//	's'            string: uvarint length, UTF-8 bytes
+//	'd'            number: uvarint length, literal as written in the source
+//	'a'            array: uint64 body size, uvarint count, elements
+//	'o'            object: uint64 body size, uvarint count, then per member
+//	               a uvarint key length, the key and the value, in document order
+//
+// Body sizes are little-endian and count the bytes after the size field,
+// so a reader can skip a container without decoding it. Version 1 documents,
+// which differ only in storing object keys sorted, are still read. Readers
+// refuse documents nested deeper than the parser's default limit, and
+// Unmarshal refuses objects that repeat a key.
+package binast
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// Version is the format version written by Write
+const Version = 2
+
+// minVersion is the oldest format version that can be read
+const minVersion = 1
+
+// magic opens every encoded document
+const magic = "JPAST"
+
+// headerSize is the length of the magic bytes plus the version byte
+const headerSize = len(magic) + 1
+
+// Value tags
+const (
+	tagNull   = 'n'
+	tagTrue   = 't'
+	tagFalse  = 'f'
+	tagString = 's'
+	tagNumber = 'd'
+	tagArray  = 'a'
+	tagObject = 'o'
+)
+
+// ErrVersion is returned when data was written by an unsupported format version
+var ErrVersion = errors.New("binast: unsupported format version")
+
+// Marshal returns the binary encoding of v
+func Marshal(v ast.Value) ([]byte, error) {
+	buf := append(make([]byte, 0, 256), magic...)
+	buf = append(buf, Version)
+	return appendValue(buf, v)
+}
+
+// Write writes the binary encoding of v to w
+func Write(w io.Writer, v ast.Value) error {
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// appendValue appends the encoding of v to buf
+func appendValue(buf []byte, v ast.Value) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case *ast.Null:
+		buf = append(buf, tagNull)
+	case *ast.Boolean:
+		switch v.Value {
+		case "true":
+			buf = append(buf, tagTrue)
+		case "false":
+			buf = append(buf, tagFalse)
+		default:
+			return nil, fmt.Errorf("binast: invalid boolean literal %q", v.Value)
+		}
+	case *ast.String:
+		buf = appendBytes(append(buf, tagString), v.Value)
+	case *ast.Number:
+		buf = appendBytes(append(buf, tagNumber), v.Value)
+	case *ast.Array:
+		sizeAt := len(buf) + 1
+		buf = append(buf, tagArray, 0, 0, 0, 0, 0, 0, 0, 0)
+		buf = binary.AppendUvarint(buf, uint64(len(v.Elements)))
+		for _, elem := range v.Elements {
+			if buf, err = appendValue(buf, elem); err != nil {
+				return nil, err
+			}
+		}
+		binary.LittleEndian.PutUint64(buf[sizeAt:], uint64(len(buf)-sizeAt-8))
+	case *ast.Object:
+		keys := v.OrderedKeys()
+		sizeAt := len(buf) + 1
+		buf = append(buf, tagObject, 0, 0, 0, 0, 0, 0, 0, 0)
+		buf = binary.AppendUvarint(buf, uint64(len(keys)))
+		for _, key := range keys {
+			buf = appendBytes(buf, key)
+			if buf, err = appendValue(buf, v.Pairs[key]); err != nil {
+				return nil, err
+			}
+		}
+		binary.LittleEndian.PutUint64(buf[sizeAt:], uint64(len(buf)-sizeAt-8))
+	default:
+		return nil, fmt.Errorf("binast: unsupported value of type %T", v)
+	}
+	return buf, nil
+}
+
+// appendBytes appends s prefixed with its length
+func appendBytes(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// Unmarshal decodes a document produced by Marshal or Write
+func Unmarshal(data []byte) (ast.Value, error) {
+	if err := checkHeader(data); err != nil {
+		return nil, err
+	}
+	r := &reader{data: data, pos: headerSize}
+	v, err := r.value()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(data) {
+		return nil, r.errorf("unexpected data after the root value")
+	}
+	return v, nil
+}
+
+// Read decodes a document written by Write from r
+func Read(r io.Reader) (ast.Value, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
+
+// checkHeader verifies the magic bytes and the version
+func checkHeader(data []byte) error {
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return errors.New("binast: not a binary AST document")
+	}
+	if version := data[len(magic)]; version < minVersion || version > Version {
+		return fmt.Errorf("%w %d, expected %d", ErrVersion, data[len(magic)], Version)
+	}
+	return nil
+}
+
+// reader decodes values from data, tracking the current offset for error messages
+type reader struct {
+	data  []byte
+	pos   int
+	depth int // open containers around the current offset
+}
+
+func (r *reader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("binast: corrupt data at offset %d: %s", r.pos, fmt.Sprintf(format, args...))
+}
+
+func (r *reader) value() (ast.Value, error) {
+	if r.pos >= len(r.data) {
+		return nil, r.errorf("truncated value")
+	}
+	tag := r.data[r.pos]
+	r.pos++
+
+	switch tag {
+	case tagNull:
+		return &ast.Null{}, nil
+	case tagTrue:
+		return &ast.Boolean{Value: "true"}, nil
+	case tagFalse:
+		return &ast.Boolean{Value: "false"}, nil
+	case tagString:
+		s, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.String{Value: s}, nil
+	case tagNumber:
+		s, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Number{Value: s}, nil
+	case tagArray:
+		end, count, err := r.container()
+		if err != nil {
+			return nil, err
+		}
+		defer r.leave()
+		array := &ast.Array{}
+		if count > 0 {
+			array.Elements = make([]ast.Value, 0, count)
+		}
+		for i := 0; i < count; i++ {
+			elem, err := r.value()
+			if err != nil {
+				return nil, err
+			}
+			array.Elements = append(array.Elements, elem)
+		}
+		if r.pos != end {
+			return nil, r.errorf("array size does not match its contents")
+		}
+		return array, nil
+	case tagObject:
+		end, count, err := r.container()
+		if err != nil {
+			return nil, err
+		}
+		defer r.leave()
+		obj := &ast.Object{Pairs: make(map[string]ast.Value, count)}
+		for i := 0; i < count; i++ {
+			at := r.pos
+			key, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := obj.Pairs[key]; ok {
+				r.pos = at
+				return nil, r.errorf("duplicate key %q", key)
+			}
+			value, err := r.value()
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key, value)
+		}
+		if r.pos != end {
+			return nil, r.errorf("object size does not match its contents")
+		}
+		return obj, nil
+	default:
+		r.pos--
+		return nil, r.errorf("unknown tag %q", tag)
+	}
+}
+
+// container reads a container's body size and element count, returning the
+// offset where it ends. It counts one level of nesting, which leave undoes,
+// and fails deeper than parser.DefaultMaxDepth so crafted input cannot
+// exhaust the stack.
+func (r *reader) container() (end, count int, err error) {
+	if r.depth == parser.DefaultMaxDepth {
+		return 0, 0, r.errorf("nesting deeper than %d levels", parser.DefaultMaxDepth)
+	}
+	if len(r.data)-r.pos < 8 {
+		return 0, 0, r.errorf("truncated container size")
+	}
+	size := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	if size > uint64(len(r.data)-r.pos) {
+		return 0, 0, r.errorf("container size %d exceeds the input", size)
+	}
+	end = r.pos + int(size)
+
+	n, err := r.uvarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	// every element takes at least one byte, which bounds the count by the body size
+	if r.pos > end || n > uint64(end-r.pos) {
+		return 0, 0, r.errorf("element count %d exceeds the container size", n)
+	}
+	r.depth++
+	return end, int(n), nil
+}
+
+// leave closes the container opened by the last successful call to container
+func (r *reader) leave() {
+	r.depth--
+}
+
+// bytes reads a length-prefixed string
+func (r *reader) bytes() (string, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		return "", r.errorf("length %d exceeds the input", n)
+	}
+	s := string(r.data[r.pos : r.pos+int(n)])
+	r.pos += int(n)
+	return s, nil
+}
+
+func (r *reader) uvarint() (uint64, error) {
+	n, size := binary.Uvarint(r.data[r.pos:])
+	if size <= 0 {
+		return 0, r.errorf("invalid length")
+	}
+	r.pos += size
+	return n, nil
+}
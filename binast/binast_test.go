@@ -0,0 +1,173 @@
+package binast
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func TestRoundTrip(t *testing.T) {
+	inputs := []string{
+		`null`,
+		`true`,
+		`"héllo 😀"`,
+		`12345678901234567890123`,
+		`[]`,
+		`{}`,
+		`{"name": "api", "ports": [80, 443], "tls": {"enabled": true, "cert": null}, "ratio": -1.5e-3}`,
+	}
+
+	for _, input := range inputs {
+		want := mustParse(t, input)
+
+		var buf bytes.Buffer
+		if err := Write(&buf, want); err != nil {
+			t.Fatalf("%s: Write error: %v", input, err)
+		}
+		got, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("%s: Read error: %v", input, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			gotJSON, _ := encoder.Marshal(got)
+			t.Errorf("%s: round trip produced %s", input, gotJSON)
+		}
+	}
+}
+
+func TestMarshal_PreservesKeyOrder(t *testing.T) {
+	input := `{"b": 1, "a": 2, "c": {"y": 1, "x": 2}}`
+	data, err := Marshal(mustParse(t, input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, err := Marshal(mustParse(t, input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("expected equal documents to encode identically")
+	}
+
+	value, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := value.(*ast.Object).String(); got != `{"b":1,"a":2,"c":{"y":1,"x":2}}` {
+		t.Errorf("expected keys in document order, got %s", got)
+	}
+}
+
+func TestUnmarshal_Version1(t *testing.T) {
+	// Version 1 wrote the same encoding with object keys sorted
+	data, err := Marshal(mustParse(t, `{"a": 1, "b": [true]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data[len(magic)] = 1
+
+	value, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := value.(*ast.Object).String(); got != `{"a":1,"b":[true]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+}
+
+func TestUnmarshal_Version(t *testing.T) {
+	data, err := Marshal(&ast.Null{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data[len(magic)] = Version + 1
+
+	if _, err := Unmarshal(data); !errors.Is(err, ErrVersion) {
+		t.Errorf("expected ErrVersion, got %v", err)
+	}
+	if _, err := Unmarshal([]byte(`{"a": 1}`)); err == nil {
+		t.Errorf("expected JSON text to be rejected")
+	}
+}
+
+func TestUnmarshal_CorruptData(t *testing.T) {
+	data, err := Marshal(mustParse(t, `{"list": [1, "two", {"three": [true, false, null]}], "x": "y"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if _, err := Unmarshal(data[:i]); err == nil {
+			t.Errorf("truncation at %d: expected an error", i)
+		}
+	}
+	for i := headerSize; i < len(data); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0xff
+		Unmarshal(corrupt) // must not panic; some flips still decode
+	}
+}
+
+func TestUnmarshal_DepthLimit(t *testing.T) {
+	nested := func(depth int) []byte {
+		var v ast.Value = &ast.Null{}
+		for i := 0; i < depth; i++ {
+			v = &ast.Array{Elements: []ast.Value{v}}
+		}
+		data, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		return data
+	}
+
+	if _, err := Unmarshal(nested(parser.DefaultMaxDepth)); err != nil {
+		t.Errorf("expected %d levels to decode, got %v", parser.DefaultMaxDepth, err)
+	}
+	deep := nested(parser.DefaultMaxDepth + 1)
+	if _, err := Unmarshal(deep); err == nil || !strings.Contains(err.Error(), "nesting deeper than") {
+		t.Errorf("Unmarshal: expected a nesting error, got %v", err)
+	}
+	if _, err := View(deep); err == nil || !strings.Contains(err.Error(), "nesting deeper than") {
+		t.Errorf("View: expected a nesting error, got %v", err)
+	}
+}
+
+func TestUnmarshal_RejectsDuplicateKeys(t *testing.T) {
+	data, err := Marshal(mustParse(t, `{"a": 1, "b": 2}`))
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	data[bytes.LastIndexByte(data, 'b')] = 'a'
+
+	_, err = Unmarshal(data)
+	if err == nil || !strings.Contains(err.Error(), `duplicate key "a"`) {
+		t.Errorf("expected a duplicate key error, got %v", err)
+	}
+}
+
+func TestMarshal_RejectsUnknownValues(t *testing.T) {
+	if _, err := Marshal(map[string]int{}); err == nil {
+		t.Errorf("expected an error for a non-AST value")
+	}
+}
@@ -0,0 +1,39 @@
+package binast
+
+// Mapping is an encoded document file mapped read-only into memory.
+// Processes mapping the same file share its pages instead of each holding a copy.
+type Mapping struct {
+	root  Node
+	unmap func() error
+}
+
+// Open maps the document file at path and checks its structure.
+// Nodes obtained from the mapping must not be used after Close.
+func Open(path string) (*Mapping, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := View(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	return &Mapping{root: root, unmap: unmap}, nil
+}
+
+// Root returns the document's root node
+func (m *Mapping) Root() Node {
+	return m.root
+}
+
+// Close releases the mapping
+func (m *Mapping) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	err := m.unmap()
+	m.unmap = nil
+	m.root = Node{}
+	return err
+}
@@ -0,0 +1,53 @@
+package binast
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(f, mustParse(t, `{"service": {"name": "api", "ports": [80, 443]}}`)); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	service, _ := m.Root().Get("service")
+	ports, _ := service.Get("ports")
+	if port, ok := ports.Index(1); !ok || port.Text() != "443" {
+		t.Errorf("expected service.ports[1] 443, got %q", port.Text())
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("Close error: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("expected a second Close to be a no-op, got %v", err)
+	}
+}
+
+func TestOpen_RejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"empty": "", "json": `{"a": 1}`} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Open(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
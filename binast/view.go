@@ -0,0 +1,260 @@
+package binast
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Kind identifies the type of a Node
+type Kind int
+
+// Node kinds
+const (
+	Null Kind = iota
+	Bool
+	String
+	Number
+	Array
+	Object
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case Null:
+		return "null"
+	case Bool:
+		return "bool"
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case Array:
+		return "array"
+	case Object:
+		return "object"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Node is a value inside an encoded document, read in place without decoding.
+// Nodes share the document's bytes, which must not change while nodes are in use.
+// The zero Node reads as null.
+type Node struct {
+	data []byte
+	pos  int // offset of the value's tag
+}
+
+// View checks the structure of an encoded document once and returns its root
+// node. Lookups through the node then read data directly, so a document mapped
+// into memory can be queried without copying it.
+func View(data []byte) (Node, error) {
+	if err := checkHeader(data); err != nil {
+		return Node{}, err
+	}
+	r := &reader{data: data, pos: headerSize}
+	if err := r.skip(); err != nil {
+		return Node{}, err
+	}
+	if r.pos != len(data) {
+		return Node{}, r.errorf("unexpected data after the root value")
+	}
+	return Node{data: data, pos: headerSize}, nil
+}
+
+// skip checks the value at the current offset and moves past it without allocating
+func (r *reader) skip() error {
+	if r.pos >= len(r.data) {
+		return r.errorf("truncated value")
+	}
+	tag := r.data[r.pos]
+	r.pos++
+
+	switch tag {
+	case tagNull, tagTrue, tagFalse:
+		return nil
+	case tagString, tagNumber:
+		_, err := r.span()
+		return err
+	case tagArray, tagObject:
+		end, count, err := r.container()
+		if err != nil {
+			return err
+		}
+		defer r.leave()
+		for i := 0; i < count; i++ {
+			if tag == tagObject {
+				if _, err := r.span(); err != nil {
+					return err
+				}
+			}
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+		if r.pos != end {
+			return r.errorf("container size does not match its contents")
+		}
+		return nil
+	default:
+		r.pos--
+		return r.errorf("unknown tag %q", tag)
+	}
+}
+
+// span reads a length-prefixed string without copying it
+func (r *reader) span() ([]byte, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		return nil, r.errorf("length %d exceeds the input", n)
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// next moves past the value at the current offset, which View has checked,
+// jumping over a container by its body size instead of reading its contents
+func (r *reader) next() {
+	tag := r.data[r.pos]
+	r.pos++
+	switch tag {
+	case tagString, tagNumber:
+		r.span()
+	case tagArray, tagObject:
+		r.pos += 8 + int(binary.LittleEndian.Uint64(r.data[r.pos:]))
+	}
+}
+
+// Kind returns the type of the node
+func (n Node) Kind() Kind {
+	switch n.tag() {
+	case tagTrue, tagFalse:
+		return Bool
+	case tagString:
+		return String
+	case tagNumber:
+		return Number
+	case tagArray:
+		return Array
+	case tagObject:
+		return Object
+	default:
+		return Null
+	}
+}
+
+// Bool reports whether the node is true
+func (n Node) Bool() bool {
+	return n.tag() == tagTrue
+}
+
+// Raw returns the bytes of a string or number node, sharing the document's memory.
+// The result must not be modified. It is nil for other kinds.
+func (n Node) Raw() []byte {
+	if k := n.Kind(); k != String && k != Number {
+		return nil
+	}
+	r := n.reader(n.pos + 1)
+	b, _ := r.span()
+	return b
+}
+
+// Text returns a copy of the contents of a string node or the literal of a number node
+func (n Node) Text() string {
+	return string(n.Raw())
+}
+
+// Len returns the number of elements of an array or members of an object, and 0 for other kinds
+func (n Node) Len() int {
+	if k := n.Kind(); k != Array && k != Object {
+		return 0
+	}
+	r := n.reader(n.pos + 1)
+	_, count, _ := r.container()
+	return count
+}
+
+// Index returns the i-th element of an array node.
+// Elements are found by jumping over their predecessors, so the cost grows with i
+// but not with the size of the elements before it.
+func (n Node) Index(i int) (Node, bool) {
+	if n.Kind() != Array || i < 0 {
+		return Node{}, false
+	}
+	r := n.reader(n.pos + 1)
+	_, count, _ := r.container()
+	if i >= count {
+		return Node{}, false
+	}
+	for ; i > 0; i-- {
+		r.next()
+	}
+	return Node{data: n.data, pos: r.pos}, true
+}
+
+// Get returns the member of an object node with the given key
+func (n Node) Get(key string) (Node, bool) {
+	if n.Kind() != Object {
+		return Node{}, false
+	}
+	r := n.reader(n.pos + 1)
+	_, count, _ := r.container()
+	for i := 0; i < count; i++ {
+		k, _ := r.span()
+		if string(k) == key {
+			return Node{data: n.data, pos: r.pos}, true
+		}
+		r.next()
+	}
+	return Node{}, false
+}
+
+// Range calls fn for each member of an object node in document order, or for each
+// element of an array node with an empty key, until fn returns false
+func (n Node) Range(fn func(key string, value Node) bool) {
+	kind := n.Kind()
+	if kind != Array && kind != Object {
+		return
+	}
+	r := n.reader(n.pos + 1)
+	_, count, _ := r.container()
+	for i := 0; i < count; i++ {
+		var key []byte
+		if kind == Object {
+			key, _ = r.span()
+		}
+		if !fn(string(key), Node{data: n.data, pos: r.pos}) {
+			return
+		}
+		r.next()
+	}
+}
+
+// Value decodes the node and everything below it into AST nodes
+func (n Node) Value() (ast.Value, error) {
+	if n.data == nil {
+		return &ast.Null{}, nil
+	}
+	r := n.reader(n.pos)
+	return r.value()
+}
+
+// reader starts reading the node's document at pos
+func (n Node) reader(pos int) reader {
+	return reader{data: n.data, pos: pos}
+}
+
+// tag returns the node's tag; the zero Node reads as null
+func (n Node) tag() byte {
+	if n.data == nil {
+		return tagNull
+	}
+	return n.data[n.pos]
+}
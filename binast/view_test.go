@@ -0,0 +1,147 @@
+package binast
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func mustView(t *testing.T, input string) Node {
+	t.Helper()
+	data, err := Marshal(mustParse(t, input))
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	root, err := View(data)
+	if err != nil {
+		t.Fatalf("View error: %v", err)
+	}
+	return root
+}
+
+func TestView_Lookups(t *testing.T) {
+	root := mustView(t, `{"name": "api", "replicas": 3, "tls": {"enabled": true}, "ports": [80, 443, {"alt": null}]}`)
+
+	if root.Kind() != Object || root.Len() != 4 {
+		t.Fatalf("expected an object with 4 members, got %s with %d", root.Kind(), root.Len())
+	}
+	if name, ok := root.Get("name"); !ok || name.Kind() != String || name.Text() != "api" {
+		t.Errorf("expected name api, got %s %q", name.Kind(), name.Text())
+	}
+	if replicas, ok := root.Get("replicas"); !ok || replicas.Kind() != Number || string(replicas.Raw()) != "3" {
+		t.Errorf("expected replicas 3, got %s %q", replicas.Kind(), replicas.Raw())
+	}
+	if _, ok := root.Get("missing"); ok {
+		t.Errorf("expected no member named missing")
+	}
+
+	tls, _ := root.Get("tls")
+	if enabled, ok := tls.Get("enabled"); !ok || enabled.Kind() != Bool || !enabled.Bool() {
+		t.Errorf("expected tls.enabled true")
+	}
+
+	ports, _ := root.Get("ports")
+	if second, ok := ports.Index(1); !ok || second.Text() != "443" {
+		t.Errorf("expected ports[1] 443, got %q", second.Text())
+	}
+	third, _ := ports.Index(2)
+	if alt, ok := third.Get("alt"); !ok || alt.Kind() != Null {
+		t.Errorf("expected ports[2].alt null, got %s", alt.Kind())
+	}
+	if _, ok := ports.Index(3); ok {
+		t.Errorf("expected ports[3] to be out of range")
+	}
+}
+
+func TestView_Range(t *testing.T) {
+	root := mustView(t, `{"b": 2, "a": 1, "c": 3}`)
+
+	var keys []string
+	root.Range(func(key string, value Node) bool {
+		keys = append(keys, key+"="+value.Text())
+		return key != "a"
+	})
+	if !reflect.DeepEqual(keys, []string{"b=2", "a=1"}) {
+		t.Errorf("expected members in document order until a, got %v", keys)
+	}
+}
+
+func TestView_JumpsOverSiblings(t *testing.T) {
+	data, err := Marshal(mustParse(t, `{"skipped": [[1, 2], {"x": "y"}], "wanted": "found"}`))
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	root, err := View(data)
+	if err != nil {
+		t.Fatalf("View error: %v", err)
+	}
+	// scribble over the body of the first member after View has checked it;
+	// lookups past it must go by its stored size and never read the body
+	skipped, _ := root.Get("skipped")
+	body := data[skipped.pos+9 : skipped.pos+9+int(binary.LittleEndian.Uint64(data[skipped.pos+1:]))]
+	for i := range body {
+		body[i] = 0xff
+	}
+
+	if wanted, ok := root.Get("wanted"); !ok || wanted.Text() != "found" {
+		t.Errorf("expected Get to find wanted, got %v %q", ok, wanted.Text())
+	}
+	var keys []string
+	root.Range(func(key string, _ Node) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []string{"skipped", "wanted"}) {
+		t.Errorf("expected Range to visit both members, got %v", keys)
+	}
+}
+
+func TestView_Value(t *testing.T) {
+	root := mustView(t, `{"list": [1, "two", {"three": [true]}]}`)
+	list, _ := root.Get("list")
+
+	got, err := list.Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := mustParse(t, `[1, "two", {"three": [true]}]`); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the decoded subtree to match, got %#v", got)
+	}
+}
+
+func TestView_CorruptData(t *testing.T) {
+	data, err := Marshal(mustParse(t, `{"list": [1, "two", {"three": [true, false, null]}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if _, err := View(data[:i]); err == nil {
+			t.Errorf("truncation at %d: expected an error", i)
+		}
+	}
+	for i := headerSize; i < len(data); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0xff
+		if root, err := View(corrupt); err == nil {
+			// whatever survived the check must be safe to walk
+			walk(root)
+		}
+	}
+}
+
+// walk visits every node below n
+func walk(n Node) {
+	n.Text()
+	n.Range(func(_ string, child Node) bool {
+		walk(child)
+		return true
+	})
+}
+
+func TestView_ZeroNode(t *testing.T) {
+	var n Node
+	if n.Kind() != Null || n.Len() != 0 || n.Raw() != nil {
+		t.Errorf("expected the zero Node to read as null")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !jsonparser_minimal
+
+package jsonparser
+
+// minimalBuild reports a build with the jsonparser_minimal tag
+const minimalBuild = false
@@ -0,0 +1,131 @@
+package jsonparser
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// ErrChecksum is wrapped by the errors of VerifyChecksum and
+// VerifyChecksumComments when a document does not match its checksum
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Checksum returns the checksum of the JSON values read from r: "sha256:"
+// and the hex SHA-256 digest of their compact text, each value followed by
+// a newline, which is what FormatOptions{}.Format writes. It depends only
+// on the tokens, so reformatting the input or adding comments, which are
+// skipped, leaves it unchanged, while changing any key, value or bracket
+// changes it. A pipeline stage can send it alongside a document for a later
+// stage to check with VerifyChecksum.
+func Checksum(r io.Reader) (string, error) {
+	in := bufio.NewReader(r)
+	skipBOM(in)
+	dec := newCommentDecoder(in, false)
+	sum := newValueHash()
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return sum.sum(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		sum.token(tok)
+	}
+}
+
+// VerifyChecksum checks that the JSON values read from r have the checksum
+// want, as returned by Checksum
+func VerifyChecksum(r io.Reader, want string) error {
+	got, err := Checksum(r)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksum, got, want)
+	}
+	return nil
+}
+
+// VerifyChecksumComments checks the output of FormatOptions.Checksum: every
+// value read from r must be followed by a comment holding its own checksum.
+// It stops at the first value that is not.
+func VerifyChecksumComments(r io.Reader) error {
+	in := bufio.NewReader(r)
+	skipped := skipBOM(in)
+	dec := newCommentDecoder(in, true)
+	sum := newValueHash()
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !sum.token(tok) {
+			continue
+		}
+		end := skipped + dec.InputOffset()
+		dec.lex.DropComments()
+		dec.More() // scans up to the next token, past the comments after the value
+		want := "// " + sum.sum()
+		sum.reset()
+		comments := dec.lex.Comments()
+		if len(comments) == 0 || !strings.HasPrefix(comments[0].Text, "// sha256:") {
+			return fmt.Errorf("%w: no checksum after the value ending at byte %d", ErrChecksum, end)
+		}
+		if comment := comments[0].Text; comment != want {
+			return fmt.Errorf("%w: the value ending at byte %d has checksum %s, but its comment says %s", ErrChecksum, end, strings.TrimPrefix(want, "// "), strings.TrimPrefix(comment, "// "))
+		}
+	}
+}
+
+// newCommentDecoder returns a Decoder for r that skips comments, recording
+// them on its lexer when keep is set
+func newCommentDecoder(r io.Reader, keep bool) *Decoder {
+	dec := NewDecoder(r)
+	dec.lex.SetOptions(lexer.Options{AllowComments: true, KeepComments: keep})
+	return dec
+}
+
+// valueHash hashes the compact text of the top-level values of a token
+// stream, formatting the tokens as Format does
+type valueHash struct {
+	hash hash.Hash
+	f    *formatter
+}
+
+func newValueHash() *valueHash {
+	h := sha256.New()
+	return &valueHash{hash: h, f: &formatter{out: bufio.NewWriter(h), eol: "\n"}}
+}
+
+// token adds tok, reporting whether it ends a top-level value
+func (v *valueHash) token(tok Token) bool {
+	v.f.token(tok)
+	if len(v.f.levels) > 0 {
+		return false
+	}
+	v.f.write(v.f.eol)
+	return true
+}
+
+// sum returns the checksum of the values added since the last reset
+func (v *valueHash) sum() string {
+	v.f.out.Flush()
+	return "sha256:" + hex.EncodeToString(v.hash.Sum(nil))
+}
+
+// reset starts the checksum over, for the next value
+func (v *valueHash) reset() {
+	v.f.out.Flush()
+	v.hash.Reset()
+}
@@ -0,0 +1,74 @@
+package jsonparser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	digest := sha256.Sum256([]byte("{\"a\":[1,\"x\"],\"b\":null}\n"))
+	want := "sha256:" + hex.EncodeToString(digest[:])
+	for _, input := range []string{
+		`{"a":[1,"x"],"b":null}`,
+		"{\n  \"a\": [1, \"\\u0078\"], // note\n  \"b\": null\n}\n",
+		"\xef\xbb\xbf{\"a\":[1,\"x\"],\"b\":null}",
+	} {
+		got, err := Checksum(strings.NewReader(input))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: expected %s, got %s", input, want, got)
+		}
+		if err := VerifyChecksum(strings.NewReader(input), want); err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+		}
+	}
+
+	for _, changed := range []string{`{"a":[1,"y"],"b":null}`, `{"a":[1,"x"],"b":null,"c":1}`, `{"a":[1,"x"]}`, `{"b":null,"a":[1,"x"]}`} {
+		if err := VerifyChecksum(strings.NewReader(changed), want); !errors.Is(err, ErrChecksum) {
+			t.Errorf("%q: expected a checksum mismatch, got %v", changed, err)
+		}
+	}
+	if _, err := Checksum(strings.NewReader(`{"a":`)); err == nil || errors.Is(err, ErrChecksum) {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+}
+
+func TestFormatOptions_Checksum(t *testing.T) {
+	input := `{"a": [1, 2]} "x" 3`
+	var out strings.Builder
+	if err := (FormatOptions{Indent: "  ", Checksum: true}).Format(&out, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 9 || !strings.HasPrefix(lines[5], "} // sha256:") || !strings.HasPrefix(lines[6], `"x" // sha256:`) {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if sum, _ := Checksum(strings.NewReader("3")); lines[7] != "3 // "+sum {
+		t.Errorf("expected the checksum of 3 after it, got %q", lines[7])
+	}
+	if err := VerifyChecksumComments(strings.NewReader(out.String())); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{"changed value", strings.Replace(out.String(), "2", "5", 1), "checksum mismatch: the value ending at byte 29 has checksum sha256:"},
+		{"missing checksum", strings.Replace(out.String(), `"x" //`, `"x" /* */ //`, 1), "checksum mismatch: no checksum after the value ending at byte 108"},
+		{"no comments", `{"a": 1}`, "checksum mismatch: no checksum after the value ending at byte 8"},
+	}
+	for _, tt := range tests {
+		err := VerifyChecksumComments(strings.NewReader(tt.output))
+		if !errors.Is(err, ErrChecksum) || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected an error starting %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -1,42 +1,509 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/dialect"
+	"github.com/letsmakecakes/jsonparser/internal/analysis"
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
 	"github.com/letsmakecakes/jsonparser/internal/lexer"
 	"github.com/letsmakecakes/jsonparser/internal/parser"
+	"github.com/letsmakecakes/jsonparser/schema"
+	"github.com/letsmakecakes/jsonparser/transform"
 )
 
+// inputDialect is the dialect -jsonc or -json5 selects for every input, or
+// nil for standard JSON
+var inputDialect *dialect.Dialect
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		runWatch(os.Args[2:])
+		return
+	}
+
 	filepath := flag.String("file", "", "Path to the JSON fike to parse")
+	hotKeys := flag.Bool("hotkeys", false, "Report the most common and heaviest paths across the JSON files given as arguments")
+	top := flag.Int("top", 20, "Number of paths to list in the -hotkeys report (0 for all)")
+	dedupe := flag.Bool("dedupe", false, "Report duplicate documents across the JSON files given as arguments")
+	subtrees := flag.Bool("subtrees", false, "With -dedupe, also report repeated arrays and objects of at least -min-size bytes")
+	minSize := flag.Int("min-size", 64, "Smallest subtree, in bytes of compact JSON, that -subtrees reports")
+	similarity := flag.Float64("similarity", 0.9, "With -dedupe, report documents sharing at least this share of values as near-duplicates (0 to disable)")
+	transforms := flag.String("transform", "", "Comma-separated transforms to apply to the -file document before printing it")
+	plugins := flag.String("plugin", "", "Comma-separated Go plugins to load transforms from")
+	mappingFile := flag.String("mapping", "", "Path to a mapping spec reshaping the -file document, applied after any -transform and before -filter and -map")
+	filterExpr := flag.String("filter", "", "Condition keeping the elements of a root array it holds for, applied after any -transform and before -map, e.g. 'x.price > 10 && x.tags contains \"sale\"'")
+	mapExpr := flag.String("map", "", "Expression applied to the -file document, or to each element of a root array, after any -transform, e.g. 'x.price * 1.2'")
+	selectExpr := flag.String("select", "", "Condition to find values at any depth of the -file document, printing the JSON Pointer, line and column of each, e.g. 'x.price > 10'")
+	inferSchema := flag.Bool("infer-schema", false, "Print a JSON Schema describing all the JSON files given as arguments")
+	schemaFile := flag.String("schema", "", "Path to a JSON Schema to validate the -file document against, listing each violation")
+	schemaCompat := flag.String("schema-compat", "", "Path to an older JSON Schema to check the -file schema against, listing the changes and failing on breaking ones")
+	jsonc := flag.Bool("jsonc", false, "Read inputs as JSONC, allowing // and /* */ comments and trailing commas, as in VS Code settings files")
+	json5 := flag.Bool("json5", false, "Read inputs as JSON5, allowing comments, trailing commas, unquoted keys, single-quoted strings, hex numbers, Infinity and NaN")
+	severities := flag.String("severity", "", "Comma-separated keyword=level pairs grading -schema violations as error, warning or info, e.g. 'required=warning'; only errors fail")
+	baselineFile := flag.String("baseline", "", "Path to a baseline of known -schema violations, which are not reported")
+	updateBaseline := flag.Bool("write-baseline", false, "With -schema, record the current violations in the -baseline file instead of reporting them")
+	version := flag.Bool("version", false, "Print the parser version and the features compiled in")
 	flag.Parse()
 
+	if *version {
+		runVersion()
+		return
+	}
+
+	switch {
+	case *json5:
+		inputDialect = dialect.JSON5
+	case *jsonc:
+		inputDialect = dialect.JSONC
+	}
+
+	for _, path := range splitList(*plugins) {
+		if err := transform.LoadPlugin(path); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if *hotKeys || *dedupe || *inferSchema {
+		files := flag.Args()
+		if *filepath != "" {
+			files = append([]string{*filepath}, files...)
+		}
+		if *inferSchema {
+			runInferSchema(files)
+		} else if *dedupe {
+			runDedupe(files, analysis.DedupeOptions{Subtrees: *subtrees, MinSize: *minSize, Similarity: *similarity})
+		} else {
+			runHotKeys(files, *top)
+		}
+		return
+	}
+
+	var checks *policy
+	if *schemaFile != "" {
+		var err error
+		if checks, err = newPolicy(*severities, *baselineFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if *updateBaseline && *baselineFile == "" {
+			fmt.Println("Please provide the file for -write-baseline using -baseline flag.")
+			os.Exit(1)
+		}
+	}
+
 	if *filepath == "" {
 		fmt.Println("Please provide a JSON file using -file flag.")
 		os.Exit(1)
 	}
 
+	if *schemaFile != "" {
+		if *updateBaseline {
+			runWriteBaseline(*schemaFile, *filepath, *baselineFile)
+		} else {
+			runSchema(*schemaFile, *filepath, checks)
+		}
+		return
+	}
+
+	if *schemaCompat != "" {
+		runSchemaCompat(*schemaCompat, *filepath)
+		return
+	}
+
+	if *selectExpr != "" {
+		runSelect(*filepath, *selectExpr)
+		return
+	}
+
+	if *transforms != "" || *mappingFile != "" || *filterExpr != "" || *mapExpr != "" {
+		runTransforms(*filepath, splitList(*transforms), *mappingFile, *filterExpr, *mapExpr)
+		return
+	}
+
+	// Read the whole file so that errors can show the line they were found on
 	data, err := os.ReadFile(*filepath)
 	if err != nil {
 		fmt.Println("Error reading file:", err)
 		os.Exit(1)
 	}
 
-	lex := lexer.NewLexer(string(data))
+	lex := lexer.NewBytesLexer(data)
+	opts := withDialect(lex, inputDialect, parser.Options{})
 	tokens, lexErr := lex.Tokenize()
 	if lexErr != nil {
-		fmt.Println("Lexing Error:", lexErr)
+		fmt.Printf("Lexing Error: %+v\n", lexErr)
 		os.Exit(1)
 	}
 
-	_, parseErr := parser.Parse(tokens)
-	if parseErr != nil {
-		fmt.Println("Parsing Error:", parseErr)
+	p := parser.NewParser(tokens)
+	p.SetOptions(opts)
+	if _, parseErr := p.ParseDocument(); parseErr != nil {
+		fmt.Printf("Parsing Error: %+v\n", parser.WithSnippets(parseErr, lex))
 		os.Exit(1)
 	}
 
-	fmt.Println("Valid JSON")
+	if inputDialect != nil {
+		fmt.Printf("Valid %s\n", strings.ToUpper(inputDialect.Name))
+	} else {
+		fmt.Println("Valid JSON")
+	}
 	os.Exit(0)
 }
+
+// runVersion prints the version and the features compiled in
+func runVersion() {
+	f := jsonparser.Features()
+	fmt.Println("jsonparser", f.Version)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "query\t%s\n", f.Query)
+	fmt.Fprintf(w, "dialects\t%s\n", strings.Join(f.Dialects, ", "))
+	fmt.Fprintf(w, "plugins\t%v\n", f.Plugins)
+	fmt.Fprintf(w, "minimal\t%v\n", f.Minimal)
+	fmt.Fprintf(w, "simd\t%v\n", f.SIMD)
+	fmt.Fprintf(w, "wasm\t%v\n", f.WASM)
+	w.Flush()
+}
+
+// runHotKeys profiles the given files and prints the paths by frequency and by size
+func runHotKeys(files []string, top int) {
+	if len(files) == 0 {
+		fmt.Println("Please provide one or more JSON files to profile.")
+		os.Exit(1)
+	}
+
+	profiler := analysis.NewProfiler()
+	for _, file := range files {
+		doc, err := parseFile(file, parser.Options{})
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", file, err)
+			continue
+		}
+		profiler.Add(doc)
+	}
+
+	report := profiler.Report()
+	fmt.Printf("Profiled %d document(s)\n\n", report.Documents)
+
+	fmt.Println("Most common paths:")
+	printPathStats(report.TopByCount(top))
+	fmt.Println()
+	fmt.Println("Heaviest paths:")
+	printPathStats(report.TopBySize(top))
+}
+
+// runDedupe hashes the given files and prints the duplicate and near-duplicate documents
+func runDedupe(files []string, opts analysis.DedupeOptions) {
+	if len(files) == 0 {
+		fmt.Println("Please provide one or more JSON files to deduplicate.")
+		os.Exit(1)
+	}
+
+	deduper := analysis.NewDeduper(opts)
+	for _, file := range files {
+		doc, err := parseFile(file, parser.Options{})
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", file, err)
+			continue
+		}
+		deduper.Add(file, doc)
+	}
+
+	report := deduper.Report()
+	fmt.Printf("Hashed %d document(s)\n\n", report.Documents)
+
+	fmt.Printf("Duplicates: %d group(s)\n", len(report.Duplicates))
+	for _, group := range report.Duplicates {
+		fmt.Printf("\n%s (%d bytes, %d copies)\n", group.Hash[:12], group.Size, len(group.Locations))
+		for _, loc := range group.Locations {
+			fmt.Printf("  %s %s\n", loc.Source, loc.Path)
+		}
+	}
+
+	if opts.Similarity > 0 {
+		fmt.Println()
+		fmt.Println("Near-duplicates:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILE\tFILE\tSIMILARITY")
+		for _, pair := range report.NearDuplicates {
+			fmt.Fprintf(w, "%s\t%s\t%.0f%%\n", pair.A.Source, pair.B.Source, 100*pair.Similarity)
+		}
+		w.Flush()
+	}
+}
+
+// runTransforms applies the named transforms, then the filter and map expressions, to a file and prints the result
+func runTransforms(file string, names []string, mappingFile, filterExpr, mapExpr string) {
+	pipeline, err := transform.Pipeline(names...)
+	if err != nil {
+		fmt.Printf("%v (available: %s)\n", err, strings.Join(transform.Names(), ", "))
+		os.Exit(1)
+	}
+	if mappingFile != "" {
+		spec, err := parseFile(mappingFile, parser.Options{})
+		if err != nil {
+			fmt.Printf("Parsing Error: %s: %+v\n", mappingFile, err)
+			os.Exit(1)
+		}
+		mapping, err := transform.Mapping(spec)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		pipeline = transform.Chain(pipeline, mapping)
+	}
+	if filterExpr != "" {
+		filter, err := transform.Filter(filterExpr)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		pipeline = transform.Chain(pipeline, filter)
+	}
+	if mapExpr != "" {
+		mapper, err := transform.Map(mapExpr)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		pipeline = transform.Chain(pipeline, mapper)
+	}
+
+	doc, err := parseFile(file, parser.Options{})
+	if err != nil {
+		fmt.Printf("Parsing Error: %+v\n", err)
+		os.Exit(1)
+	}
+	result, err := pipeline.Apply(doc)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	out, err := encoder.Marshal(result)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
+
+// runSelect prints every value of a file matching a condition, with where it was found
+func runSelect(file, selectExpr string) {
+	doc, err := parseFile(file, parser.Options{RecordSpans: true})
+	if err != nil {
+		fmt.Printf("Parsing Error: %+v\n", err)
+		os.Exit(1)
+	}
+	results, err := transform.Select(selectExpr, doc)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "POINTER\tAT\tVALUE")
+	for _, result := range results {
+		out, err := encoder.Marshal(result.Value)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		pointer := result.Pointer
+		if pointer == "" {
+			pointer = `""`
+		}
+		fmt.Fprintf(w, "%s\t%d:%d\t%s\n", pointer, result.Span.Start.Line, result.Span.Start.Column, out)
+	}
+	w.Flush()
+}
+
+// runInferSchema prints a schema inferred from the given files
+func runInferSchema(files []string) {
+	docs := make([]ast.Value, 0, len(files))
+	for _, file := range files {
+		doc, err := parseFile(file, parser.Options{})
+		if err != nil {
+			fmt.Printf("Parsing Error: %s: %+v\n", file, err)
+			os.Exit(1)
+		}
+		docs = append(docs, doc)
+	}
+	out, err := encoder.Marshal(schema.Infer(docs...))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := (jsonparser.FormatOptions{Indent: "  "}).Format(os.Stdout, bytes.NewReader(out)); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// runSchema validates file against the schema in schemaFile, printing each
+// violation the baseline does not know with its severity, and exiting with a
+// failure when any of them is an error
+func runSchema(schemaFile, file string, checks *policy) {
+	var reported []schema.Violation
+	for _, v := range validateFile(schemaFile, file) {
+		if !checks.known(file, v) {
+			reported = append(reported, v)
+		}
+	}
+	if len(reported) == 0 {
+		fmt.Println("Valid")
+		return
+	}
+	failed := false
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tPOINTER\tSCHEMA\tVIOLATION")
+	for _, v := range reported {
+		level := checks.severity(v)
+		failed = failed || level == severityError
+		pointer := v.InstancePath
+		if pointer == "" {
+			pointer = `""`
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", level, pointer, v.SchemaPath, v.Message)
+	}
+	w.Flush()
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runWriteBaseline records every violation of the schema in file in
+// baselineFile, so later runs report only new ones
+func runWriteBaseline(schemaFile, file, baselineFile string) {
+	violations := validateFile(schemaFile, file)
+	entries := make([]baselineEntry, 0, len(violations))
+	for _, v := range violations {
+		entries = append(entries, entryFor(file, v))
+	}
+	if err := writeBaseline(baselineFile, entries); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Recorded %d violations in %s\n", len(entries), baselineFile)
+}
+
+// validateFile validates file against the schema in schemaFile, exiting
+// with a failure when either cannot be read
+func validateFile(schemaFile, file string) []schema.Violation {
+	doc, err := parseFile(schemaFile, parser.Options{})
+	if err != nil {
+		fmt.Printf("Parsing Error: %s: %+v\n", schemaFile, err)
+		os.Exit(1)
+	}
+	s, err := schema.Compile(doc)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if doc, err = parseFile(file, parser.Options{}); err != nil {
+		fmt.Printf("Parsing Error: %s: %+v\n", file, err)
+		os.Exit(1)
+	}
+	return s.Validate(doc)
+}
+
+// runSchemaCompat prints how the schema in file changed from the one in
+// oldFile, and exits with a failure when any change is breaking
+func runSchemaCompat(oldFile, file string) {
+	old, err := parseFile(oldFile, parser.Options{})
+	if err != nil {
+		fmt.Printf("Parsing Error: %s: %+v\n", oldFile, err)
+		os.Exit(1)
+	}
+	doc, err := parseFile(file, parser.Options{})
+	if err != nil {
+		fmt.Printf("Parsing Error: %s: %+v\n", file, err)
+		os.Exit(1)
+	}
+	changes := schema.Compare(old, doc)
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANGE\tPOINTER\tDETAIL")
+	for _, c := range changes {
+		kind, pointer := "compatible", c.Path
+		if c.Breaking {
+			kind = "breaking"
+		}
+		if pointer == "" {
+			pointer = `""`
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", kind, pointer, c.Message)
+	}
+	w.Flush()
+	if schema.HasBreaking(changes) {
+		os.Exit(1)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFile reads and parses a single JSON document of any root type, in
+// the dialect chosen by -jsonc or -json5
+func parseFile(file string, opts parser.Options) (ast.Value, error) {
+	return parseFileAs(file, inputDialect, opts)
+}
+
+// parseFileAs reads and parses a single document of any root type in d, or
+// in standard JSON when d is nil
+func parseFileAs(file string, d *dialect.Dialect, opts parser.Options) (ast.Value, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lex := lexer.NewReaderLexer(f)
+	opts = withDialect(lex, d, opts)
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	p := parser.NewParser(tokens)
+	p.SetOptions(opts)
+	return p.ParseDocument()
+}
+
+// withDialect sets up lex for d and returns opts extended for it; a nil d
+// leaves both as they are
+func withDialect(lex *lexer.Lexer, d *dialect.Dialect, opts parser.Options) parser.Options {
+	if d == nil {
+		return opts
+	}
+	lex.SetOptions(lexer.Options{AllowComments: d.AllowComments, Hooks: d.Tokens})
+	opts.AllowTrailingCommas = opts.AllowTrailingCommas || d.AllowTrailingCommas
+	opts.Values = d.Values
+	return opts
+}
+
+// printPathStats writes path statistics as an aligned table
+func printPathStats(paths []analysis.PathStat) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tCOUNT\tDOCS\tBYTES")
+	for _, stat := range paths {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", stat.Path, stat.Count, stat.Documents, stat.Size)
+	}
+	w.Flush()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+	"github.com/letsmakecakes/jsonparser/schema"
+)
+
+// severity grades a schema violation; only errors fail a run
+type severity int
+
+// Severities, most serious first
+const (
+	severityError severity = iota
+	severityWarning
+	severityInfo
+)
+
+// String returns the name used for the severity in -severity and in reports
+func (s severity) String() string {
+	switch s {
+	case severityWarning:
+		return "warning"
+	case severityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// baselineEntry identifies one known violation. The message is left out so
+// an entry keeps matching when the offending value changes, and the file is
+// spelled by baselinePath.
+type baselineEntry struct {
+	file    string
+	pointer string
+	keyword string // JSON Pointer to the schema keyword
+}
+
+// policy decides how each violation is reported: the severity of its
+// keyword, and whether a baseline already records it
+type policy struct {
+	levels   map[string]severity // By keyword name, e.g. "required"
+	baseline map[baselineEntry]bool
+}
+
+// newPolicy reads the -severity list and the baseline file. A baseline that
+// does not exist yet is empty, so -write-baseline can create it.
+func newPolicy(levels, baselineFile string) (*policy, error) {
+	p := &policy{levels: make(map[string]severity), baseline: make(map[baselineEntry]bool)}
+	for _, item := range splitList(levels) {
+		keyword, name, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -severity entry %q, expected keyword=level", item)
+		}
+		switch name {
+		case "error":
+			p.levels[keyword] = severityError
+		case "warning":
+			p.levels[keyword] = severityWarning
+		case "info":
+			p.levels[keyword] = severityInfo
+		default:
+			return nil, fmt.Errorf("invalid severity %q for %s, expected error, warning or info", name, keyword)
+		}
+	}
+	if baselineFile == "" {
+		return p, nil
+	}
+	doc, err := parseFile(baselineFile, parser.Options{})
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("baseline %s: %v", baselineFile, err)
+	}
+	entries, ok := doc.(*ast.Array)
+	if !ok {
+		return nil, fmt.Errorf("baseline %s: expected an array of entries", baselineFile)
+	}
+	for i, elem := range entries.Elements {
+		obj, ok := elem.(*ast.Object)
+		if !ok {
+			return nil, fmt.Errorf("baseline %s: entry %d is not an object", baselineFile, i)
+		}
+		str := func(key string) string {
+			s, _ := obj.Pairs[key].(*ast.String)
+			if s == nil {
+				return ""
+			}
+			return s.Value
+		}
+		p.baseline[baselineEntry{file: baselinePath(str("file")), pointer: str("pointer"), keyword: str("schema")}] = true
+	}
+	return p, nil
+}
+
+// severity returns the configured severity of the keyword a violation failed
+func (p *policy) severity(v schema.Violation) severity {
+	keyword := v.SchemaPath[strings.LastIndexByte(v.SchemaPath, '/')+1:]
+	return p.levels[keyword]
+}
+
+// known reports whether the baseline records the violation in file
+func (p *policy) known(file string, v schema.Violation) bool {
+	return p.baseline[entryFor(file, v)]
+}
+
+// entryFor returns the baseline entry matching a violation in file
+func entryFor(file string, v schema.Violation) baselineEntry {
+	return baselineEntry{file: baselinePath(file), pointer: v.InstancePath, keyword: v.SchemaPath}
+}
+
+// baselinePath spells file the same way however it was named, whether as
+// -file ./a.json, as an absolute path or as the path watch found: relative
+// to the working directory when it can be, cleaned, with forward slashes
+func baselinePath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// writeBaseline stores entries in baselineFile, replacing what it held
+func writeBaseline(baselineFile string, entries []baselineEntry) error {
+	list := &ast.Array{Elements: make([]ast.Value, 0, len(entries))}
+	for _, e := range entries {
+		obj := &ast.Object{Pairs: make(map[string]ast.Value, 3)}
+		obj.Set("file", &ast.String{Value: e.file})
+		obj.Set("pointer", &ast.String{Value: e.pointer})
+		obj.Set("schema", &ast.String{Value: e.keyword})
+		list.Elements = append(list.Elements, obj)
+	}
+	out, err := encoder.Marshal(list)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := (jsonparser.FormatOptions{Indent: "  "}).Format(&buf, bytes.NewReader(out)); err != nil {
+		return err
+	}
+	return os.WriteFile(baselineFile, buf.Bytes(), 0o644)
+}
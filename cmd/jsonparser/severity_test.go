@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/schema"
+)
+
+func TestNewPolicy_Severities(t *testing.T) {
+	tests := []struct {
+		levels     string
+		schemaPath string
+		want       severity
+		err        string
+	}{
+		{"", "/required", severityError, ""},
+		{"required=warning", "/required", severityWarning, ""},
+		{"required=warning", "/properties/name/required", severityWarning, ""},
+		{"required=warning, minLength=info", "/properties/name/minLength", severityInfo, ""},
+		{"minLength=info", "/properties/minLength/type", severityError, ""},
+		{"type=info,type=error", "/type", severityError, ""},
+		{"required", "", 0, "expected keyword=level"},
+		{"required=fatal", "", 0, `invalid severity "fatal"`},
+	}
+	for _, tt := range tests {
+		p, err := newPolicy(tt.levels, "")
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: expected an error containing %q, got %v", tt.levels, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.levels, err)
+			continue
+		}
+		if got := p.severity(schema.Violation{SchemaPath: tt.schemaPath}); got != tt.want {
+			t.Errorf("%q: expected %s for %s, got %s", tt.levels, tt.want, tt.schemaPath, got)
+		}
+	}
+}
+
+func TestPolicy_Baseline(t *testing.T) {
+	baseline := filepath.Join(t.TempDir(), "known.json")
+	missing := schema.Violation{InstancePath: "", SchemaPath: "/required", Message: `missing required property "id"`}
+	short := schema.Violation{InstancePath: "/name", SchemaPath: "/properties/name/minLength", Message: "must have at least 3 characters"}
+
+	p, err := newPolicy("", baseline)
+	if err != nil {
+		t.Fatalf("expected a missing baseline to be empty, got %v", err)
+	}
+	if p.known("configs/a.json", missing) {
+		t.Fatalf("expected an empty baseline to know nothing")
+	}
+	if err := writeBaseline(baseline, []baselineEntry{entryFor("./configs/a.json", missing), entryFor("configs/a.json", short)}); err != nil {
+		t.Fatalf("writeBaseline error: %v", err)
+	}
+	if p, err = newPolicy("", baseline); err != nil {
+		t.Fatalf("newPolicy error: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		file      string
+		violation schema.Violation
+		known     bool
+	}{
+		{"as written", "configs/a.json", short, true},
+		{"dot prefix", "./configs/a.json", short, true},
+		{"written with a dot prefix", "configs/a.json", missing, true},
+		{"unclean", "configs/../configs//a.json", missing, true},
+		{"absolute", filepath.Join(wd, "configs", "a.json"), missing, true},
+		{"other file", "configs/b.json", missing, false},
+		{"other pointer", "configs/a.json", schema.Violation{InstancePath: "/id", SchemaPath: "/required"}, false},
+		{"other keyword", "configs/a.json", schema.Violation{InstancePath: "/name", SchemaPath: "/properties/name/pattern"}, false},
+		{"new message", "configs/a.json", schema.Violation{InstancePath: "/name", SchemaPath: "/properties/name/minLength", Message: "changed"}, true},
+	}
+	for _, tt := range tests {
+		if got := p.known(tt.file, tt.violation); got != tt.known {
+			t.Errorf("%s: expected known=%v, got %v", tt.name, tt.known, got)
+		}
+	}
+}
+
+func TestNewPolicy_BadBaseline(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"object.json":  `{"file": "a.json"}`,
+		"entry.json":   `["a.json"]`,
+		"invalid.json": `[{"file": `,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newPolicy("", path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser/dialect"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+	"github.com/letsmakecakes/jsonparser/schema"
+)
+
+// clearScreen moves the cursor home and clears the terminal before a redraw
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchedFile is what a watch remembers about one file between polls
+type watchedFile struct {
+	modTime  time.Time
+	size     int64
+	problems []problem
+}
+
+// problem is one diagnostic for a watched file
+type problem struct {
+	level   severity
+	pointer string // JSON Pointer to the value, empty for parse errors
+	keyword string // JSON Pointer to the schema keyword that failed, empty for parse errors
+	message string
+}
+
+// watcher re-validates the JSON files below a directory as they change
+type watcher struct {
+	dir        string
+	schemaFile string
+	checks     *policy
+	schema     *schema.Schema
+	schemaErr  error
+	schemaMod  time.Time
+	files      map[string]*watchedFile
+}
+
+// watchOptions are the arguments of the watch subcommand
+type watchOptions struct {
+	dir          string
+	schemaFile   string
+	severities   string
+	baselineFile string
+	interval     time.Duration
+}
+
+// parseWatchArgs reads the arguments following "watch": the directory, and
+// flags before or after it, as in "watch configs/ -schema service.schema.json"
+func parseWatchArgs(args []string) (watchOptions, error) {
+	var o watchOptions
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flags.StringVar(&o.schemaFile, "schema", "", "Path to the JSON Schema to validate the watched files against")
+	flags.StringVar(&o.severities, "severity", "", "Comma-separated keyword=level pairs grading violations as error, warning or info, e.g. 'required=warning'")
+	flags.StringVar(&o.baselineFile, "baseline", "", "Path to a baseline of known violations, which are not reported")
+	flags.DurationVar(&o.interval, "interval", time.Second, "How often to look for changed files")
+	for {
+		if err := flags.Parse(args); err != nil {
+			return o, err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		if o.dir != "" {
+			return o, fmt.Errorf("unexpected argument %q, expected one directory to watch", flags.Arg(0))
+		}
+		o.dir, args = flags.Arg(0), flags.Args()[1:]
+	}
+	return o, nil
+}
+
+// runWatch runs the watch subcommand. It validates every JSON file below
+// the directory against the schema, then polls every interval and
+// re-validates the files that changed, redrawing the diagnostics each time,
+// until interrupted. A change to the schema itself re-validates every file.
+// Violations are graded and filtered as they are for -schema.
+func runWatch(args []string) {
+	o, err := parseWatchArgs(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if o.dir == "" || o.schemaFile == "" {
+		fmt.Println("Please provide a directory and a JSON Schema to watch, as in: jsonparser watch configs/ -schema service.schema.json")
+		os.Exit(1)
+	}
+	if info, err := os.Stat(o.dir); err != nil || !info.IsDir() {
+		fmt.Printf("Please provide a directory to watch: %s\n", o.dir)
+		os.Exit(1)
+	}
+	checks, err := newPolicy(o.severities, o.baselineFile)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	w := &watcher{dir: o.dir, schemaFile: o.schemaFile, checks: checks}
+	for {
+		if w.poll() {
+			w.draw()
+		}
+		time.Sleep(o.interval)
+	}
+}
+
+// poll reloads the schema when it changed and re-validates new and changed
+// files, reporting whether anything needs redrawing
+func (w *watcher) poll() bool {
+	changed := false
+	var mod time.Time
+	if info, err := os.Stat(w.schemaFile); err == nil {
+		mod = info.ModTime()
+	}
+	if w.files == nil || !mod.Equal(w.schemaMod) {
+		w.loadSchema()
+		w.schemaMod = mod
+		// every file must be checked again against the new schema
+		w.files = make(map[string]*watchedFile)
+		changed = true
+	}
+
+	present := make(map[string]bool)
+	filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isJSONFile(path) || sameFile(path, w.schemaFile) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		present[path] = true
+		if f, ok := w.files[path]; ok && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+			return nil
+		}
+		w.files[path] = &watchedFile{modTime: info.ModTime(), size: info.Size(), problems: w.check(path)}
+		changed = true
+		return nil
+	})
+	for path := range w.files {
+		if !present[path] {
+			delete(w.files, path)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// loadSchema parses and compiles the schema, keeping the error to show when it fails
+func (w *watcher) loadSchema() {
+	w.schema, w.schemaErr = nil, nil
+	doc, err := parseFileAs(w.schemaFile, dialectOf(w.schemaFile), parser.Options{})
+	if err != nil {
+		w.schemaErr = fmt.Errorf("%s: %v", w.schemaFile, err)
+		return
+	}
+	if w.schema, err = schema.Compile(doc); err != nil {
+		w.schemaErr = err
+	}
+}
+
+// check parses file and validates it against the schema, if it compiled
+func (w *watcher) check(file string) []problem {
+	doc, err := parseFileAs(file, dialectOf(file), parser.Options{})
+	if err != nil {
+		return []problem{{message: err.Error()}}
+	}
+	if w.schema == nil {
+		return nil
+	}
+	var problems []problem
+	for _, v := range w.schema.Validate(doc) {
+		if w.checks.known(file, v) {
+			continue
+		}
+		pointer := v.InstancePath
+		if pointer == "" {
+			pointer = `""`
+		}
+		problems = append(problems, problem{level: w.checks.severity(v), pointer: pointer, keyword: v.SchemaPath, message: v.Message})
+	}
+	return problems
+}
+
+// draw clears the terminal and prints the current diagnostics of every file
+func (w *watcher) draw() {
+	fmt.Print(clearScreen)
+	fmt.Printf("Watching %s against %s (%s)\n\n", w.dir, w.schemaFile, time.Now().Format("15:04:05"))
+	if w.schemaErr != nil {
+		fmt.Printf("Schema Error: %v\n\n", w.schemaErr)
+	}
+
+	var paths []string
+	total := 0
+	for path, f := range w.files {
+		if len(f.problems) > 0 {
+			paths = append(paths, path)
+			total += len(f.problems)
+		}
+	}
+	sort.Strings(paths)
+	if total == 0 {
+		fmt.Printf("%d files valid\n", len(w.files))
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tSEVERITY\tPOINTER\tSCHEMA\tVIOLATION")
+	for _, path := range paths {
+		for _, p := range w.files[path].problems {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", path, p.level, p.pointer, p.keyword, p.message)
+		}
+	}
+	tw.Flush()
+	fmt.Printf("\n%d problems in %d files\n", total, len(paths))
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	return err == nil && os.SameFile(ia, ib)
+}
+
+// isJSONFile reports whether path has an extension the watch validates
+func isJSONFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonc", ".json5":
+		return true
+	}
+	return false
+}
+
+// dialectOf returns the dialect a file's extension names: JSONC for .jsonc,
+// JSON5 for .json5 and standard JSON, nil, for anything else
+func dialectOf(path string) *dialect.Dialect {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonc":
+		return dialect.JSONC
+	case ".json5":
+		return dialect.JSON5
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWatchArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		dir      string
+		schema   string
+		interval time.Duration
+		err      string
+	}{
+		{[]string{"configs/", "--schema", "s.json"}, "configs/", "s.json", time.Second, ""},
+		{[]string{"-schema", "s.json", "configs/"}, "configs/", "s.json", time.Second, ""},
+		{[]string{"configs/", "-interval", "5s", "-schema=s.json"}, "configs/", "s.json", 5 * time.Second, ""},
+		{[]string{"-schema", "s.json"}, "", "s.json", time.Second, ""},
+		{[]string{"a/", "b/"}, "", "", 0, `unexpected argument "b/"`},
+		{[]string{"a/", "-nope"}, "", "", 0, "-nope"},
+	}
+	for _, tt := range tests {
+		o, err := parseWatchArgs(tt.args)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: expected an error containing %q, got %v", tt.args, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.args, err)
+			continue
+		}
+		if o.dir != tt.dir || o.schemaFile != tt.schema || o.interval != tt.interval {
+			t.Errorf("%q: unexpected options %+v", tt.args, o)
+		}
+	}
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "service.schema.json")
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Push the modification time on, so an edit is seen however coarse the clock
+		if info, err := os.Stat(path); err == nil {
+			later := info.ModTime().Add(time.Second)
+			os.Chtimes(path, later, later)
+		}
+		return path
+	}
+	problems := func(w *watcher, path string) []problem {
+		t.Helper()
+		f, ok := w.files[path]
+		if !ok {
+			t.Fatalf("expected %s to be watched", path)
+		}
+		return f.problems
+	}
+
+	write("service.schema.json", `{"required": ["port"]}`)
+	good := write("a.json", `{"port": 80}`)
+	commented := write("b.jsonc", "{\n  // the default\n  \"port\": 80,\n}")
+	relaxed := write("c.json5", `{port: 0x50, name: 'api'}`)
+	write("notes.txt", `not json`)
+
+	checks, err := newPolicy("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &watcher{dir: dir, schemaFile: schemaFile, checks: checks}
+	if !w.poll() {
+		t.Fatalf("expected the first poll to check every file")
+	}
+	if len(w.files) != 3 {
+		t.Fatalf("expected the three JSON files watched, not the schema or notes.txt, got %v", w.files)
+	}
+	for _, path := range []string{good, commented, relaxed} {
+		if p := problems(w, path); len(p) != 0 {
+			t.Errorf("%s: expected no problems in its own dialect, got %+v", path, p)
+		}
+	}
+	if w.poll() {
+		t.Errorf("expected nothing to redraw when no file changed")
+	}
+
+	write("a.json", `{"host": "example.com"}`)
+	if !w.poll() {
+		t.Fatalf("expected an edited file to be checked again")
+	}
+	if p := problems(w, good); len(p) != 1 || p[0].keyword != "/required" || p[0].level != severityError {
+		t.Errorf("expected the missing port reported, got %+v", p)
+	}
+
+	write("a.json", `{"port": `)
+	w.poll()
+	if p := problems(w, good); len(p) != 1 || p[0].pointer != "" {
+		t.Errorf("expected the parse error reported, got %+v", p)
+	}
+
+	write("service.schema.json", `{"required": ["port", "name"]}`)
+	if !w.poll() {
+		t.Fatalf("expected a schema change to check every file again")
+	}
+	if p := problems(w, commented); len(p) != 1 || !strings.Contains(p[0].message, "name") {
+		t.Errorf("expected the new requirement checked against unchanged files, got %+v", p)
+	}
+	if p := problems(w, relaxed); len(p) != 0 {
+		t.Errorf("expected c.json5 to meet the new schema, got %+v", p)
+	}
+
+	if err := os.Remove(commented); err != nil {
+		t.Fatal(err)
+	}
+	if !w.poll() {
+		t.Fatalf("expected a removed file to be dropped")
+	}
+	if _, ok := w.files[commented]; ok {
+		t.Errorf("expected %s no longer watched", commented)
+	}
+}
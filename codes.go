@@ -0,0 +1,69 @@
+package jsonparser
+
+import (
+	"context"
+	"errors"
+
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// ErrorCode identifies the kind of an error, such as a trailing comma, so
+// tools can branch on it without matching messages. A code keeps its value
+// from release to release.
+type ErrorCode = lexer.ErrorCode
+
+// Error codes; see the lexer and parser errors they are attached to
+const (
+	CodeUnexpectedCharacter = lexer.CodeUnexpectedCharacter
+	CodeInvalidLiteral      = lexer.CodeInvalidLiteral
+	CodeSingleQuotes        = lexer.CodeSingleQuotes
+	CodeUnquotedString      = lexer.CodeUnquotedString
+	CodeUnterminatedString  = lexer.CodeUnterminatedString
+	CodeUnterminatedComment = lexer.CodeUnterminatedComment
+	CodeBadEscape           = lexer.CodeBadEscape
+	CodeInvalidUTF8         = lexer.CodeInvalidUTF8
+	CodeControlCharacter    = lexer.CodeControlCharacter
+	CodeBadNumber           = lexer.CodeBadNumber
+	CodeRead                = lexer.CodeRead
+	CodeDialect             = lexer.CodeDialect
+	CodeBadHook             = lexer.CodeBadHook
+	CodeUnexpectedToken     = lexer.CodeUnexpectedToken
+	CodeUnexpectedEOF       = lexer.CodeUnexpectedEOF
+	CodeTrailingData        = lexer.CodeTrailingData
+	CodeTrailingComma       = lexer.CodeTrailingComma
+	CodeMissingComma        = lexer.CodeMissingComma
+	CodeMissingColon        = lexer.CodeMissingColon
+	CodeDuplicateKey        = lexer.CodeDuplicateKey
+	CodeTooDeep             = lexer.CodeTooDeep
+	CodeTooManyKeys         = lexer.CodeTooManyKeys
+	CodeTooManyElements     = lexer.CodeTooManyElements
+	CodeTooManyErrors       = lexer.CodeTooManyErrors
+	CodeInvalidValue        = lexer.CodeInvalidValue
+
+	CodeCanceled ErrorCode = "E_CANCELED" // The context passed to ParseContext or ParseBytesContext was done
+	CodeInternal ErrorCode = "E_INTERNAL" // A bug in this package; see InternalError
+)
+
+// Errors with codes that do not describe malformed JSON
+type (
+	ReadError  = lexer.ReadError   // The reader a Decoder reads from failed
+	ValueError = parser.ValueError // A dialect rejected a value
+)
+
+// ErrorCodeOf returns the code of err, or of the first error in an
+// ErrorList, and "" for an error from outside the package
+func ErrorCodeOf(err error) ErrorCode {
+	if code := lexer.CodeOf(err); code != "" {
+		return code
+	}
+	var internal *guard.Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return CodeCanceled
+	case errors.As(err, &internal):
+		return CodeInternal
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package jsonparser
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  []Option
+		code  ErrorCode
+	}{
+		{`[1, @]`, nil, CodeUnexpectedCharacter},
+		{`[True]`, nil, CodeInvalidLiteral},
+		{`[tru]`, nil, CodeInvalidLiteral},
+		{`{'a': 1}`, nil, CodeSingleQuotes},
+		{`{a: 1}`, nil, CodeUnquotedString},
+		{`["open`, nil, CodeUnterminatedString},
+		{`[1] /* open`, []Option{WithAllowComments(true)}, CodeUnterminatedComment},
+		{`["\q"]`, nil, CodeBadEscape},
+		{`["\ud800"]`, nil, CodeBadEscape},
+		{"[\"\xff\"]", []Option{WithStrictMode(true)}, CodeInvalidUTF8},
+		{"[\"a\tb\"]", nil, CodeControlCharacter},
+		{`[01]`, nil, CodeBadNumber},
+		{`[1.]`, nil, CodeBadNumber},
+		{`[1`, nil, CodeUnexpectedEOF},
+		{`[1] 2`, nil, CodeTrailingData},
+		{`[1, 2,]`, nil, CodeTrailingComma},
+		{`{"a": 1,}`, nil, CodeTrailingComma},
+		{`[1 2]`, nil, CodeMissingComma},
+		{`{"a" 1}`, nil, CodeMissingColon},
+		{`[1,,2]`, nil, CodeUnexpectedToken},
+		{`{"a": 1, "a": 2}`, []Option{WithStrictMode(true)}, CodeDuplicateKey},
+		{`[[1]]`, []Option{WithMaxDepth(1)}, CodeTooDeep},
+		{`{"a": 1, "b": 2}`, []Option{WithMaxObjectKeys(1)}, CodeTooManyKeys},
+		{`[1, 2]`, []Option{WithMaxArrayElements(1)}, CodeTooManyElements},
+		{`[1, 2,, @]`, []Option{WithRecovery(true)}, CodeUnexpectedToken},
+	}
+	for _, tt := range tests {
+		_, parseErr := Parse(tt.input, tt.opts...)
+		validateErr := Validate([]byte(tt.input), tt.opts...)
+		for _, err := range []error{parseErr, validateErr} {
+			if got := ErrorCodeOf(err); got != tt.code {
+				t.Errorf("%s: expected %s, got %s from %v", tt.input, tt.code, got, err)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(ctx, `[1]`); ErrorCodeOf(err) != CodeCanceled {
+		t.Errorf("expected %s, got %s from %v", CodeCanceled, ErrorCodeOf(err), err)
+	}
+	if got := ErrorCodeOf(errors.New("other")); got != "" {
+		t.Errorf("expected no code, got %s", got)
+	}
+	if got := ErrorCodeOf(nil); got != "" {
+		t.Errorf("expected no code for nil, got %s", got)
+	}
+}
@@ -0,0 +1,220 @@
+// Package config keeps a JSON configuration in memory, with typed getters
+// for its values, and reloads it when its files change. A configuration can
+// be a single file or layers, such as a defaults file, a file for the
+// environment, environment variables and command-line flags, merged with
+// later layers taking precedence.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/merge"
+)
+
+// DefaultInterval is how often Watch checks the file unless told otherwise
+const DefaultInterval = time.Second
+
+// Store holds the merged contents of its layers. The tree is frozen, and a
+// reload swaps in a new one, so any number of goroutines can read from a
+// Store while it reloads.
+type Store struct {
+	layers []Layer
+
+	current atomic.Pointer[snapshot]
+
+	reload sync.Mutex // serializes reloads, so subscribers see changes in order
+	mu     sync.Mutex // guards subs and next
+	subs   map[int]func(Event)
+	next   int
+}
+
+// snapshot is one version of the configuration
+type snapshot struct {
+	root    jsonparser.Value
+	sources map[string][]setting // the layers that set each value, by JSON Pointer
+	stamps  []stamp              // the state of each layer's file when it was read
+}
+
+// stamp is what Watch compares to tell that a file changed
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime int64
+}
+
+// stampOf returns the stamp of the file at path
+func stampOf(path string) stamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{exists: true, size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
+
+// Event tells subscribers what a reload found. After a change, Root is the
+// new tree and Previous the one it replaced. When a reload failed, Err says
+// why and Root is the tree still in use.
+type Event struct {
+	Root     jsonparser.Value
+	Previous jsonparser.Value
+	Err      error
+}
+
+// Open reads and parses the file at path, using opts for every parse
+func Open(path string, opts ...jsonparser.Option) (*Store, error) {
+	return OpenLayers(File(path, opts...))
+}
+
+// OpenLayers loads the layers and merges them in order: objects merge key by
+// key, and any other value of a later layer replaces the earlier one. Pass
+// them from lowest to highest precedence, usually
+//
+//	config.OpenLayers(
+//		config.File("defaults.json"),
+//		config.OptionalFile("production.json"),
+//		config.Env("APP_"),
+//		config.Flags(flag.CommandLine),
+//	)
+func OpenLayers(layers ...Layer) (*Store, error) {
+	s := &Store{layers: layers, subs: make(map[int]func(Event))}
+	snap, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(snap)
+	return s, nil
+}
+
+// Root returns the current tree. It is frozen; Clone it to make changes.
+func (s *Store) Root() jsonparser.Value {
+	return s.current.Load().root
+}
+
+// Subscribe calls fn after every reload that changed the tree or failed,
+// until the returned function is called. Calls are made one at a time, in
+// the goroutine that reloaded.
+func (s *Store) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.next
+	s.next++
+	s.subs[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+// Reload loads every layer again and swaps in the new tree if it changed. A
+// layer that fails to load leaves the current tree in place; the error is
+// returned and passed to subscribers.
+func (s *Store) Reload() error {
+	s.reload.Lock()
+	defer s.reload.Unlock()
+	return s.reloadLocked(false)
+}
+
+// reloadLocked reloads under s.reload. With ifModified, nothing is loaded
+// unless the size or modification time of a layer's file changed.
+func (s *Store) reloadLocked(ifModified bool) error {
+	old := s.current.Load()
+	if ifModified && !s.modified(old) {
+		return nil
+	}
+	snap, err := s.load()
+	if err != nil {
+		s.notify(Event{Root: old.root, Err: err})
+		return err
+	}
+	if jsonparser.Equal(snap.root, old.root) {
+		// Touched but not changed; remember the new stamps so it is not read again
+		snap.root = old.root
+		s.current.Store(snap)
+		return nil
+	}
+	s.current.Store(snap)
+	s.notify(Event{Root: snap.root, Previous: old.root})
+	return nil
+}
+
+// modified reports whether any layer's file changed since old was loaded
+func (s *Store) modified(old *snapshot) bool {
+	for i, l := range s.layers {
+		if l.path != "" && stampOf(l.path) != old.stamps[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch checks the layers' files every interval, reloading when the size or
+// modification time of one changes, until ctx is done; it then returns
+// ctx.Err(). Layers that are not files are only loaded again by Reload. An
+// interval of 0 selects DefaultInterval.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.reload.Lock()
+			s.reloadLocked(true)
+			s.reload.Unlock()
+		}
+	}
+}
+
+// load loads and merges the layers, noting where each value came from
+func (s *Store) load() (*snapshot, error) {
+	snap := &snapshot{sources: make(map[string][]setting), stamps: make([]stamp, len(s.layers))}
+	var docs []jsonparser.Value
+	for i, l := range s.layers {
+		if l.path != "" {
+			snap.stamps[i] = stampOf(l.path)
+		}
+		v, err := l.Load()
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if v == nil {
+			continue
+		}
+		record(snap.sources, l.Name, "", v)
+		docs = append(docs, v)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, &jsonparser.Object{Pairs: make(map[string]jsonparser.Value)})
+	}
+	root, err := merge.MergeAll(merge.Rules{}, docs...)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	jsonparser.Freeze(root)
+	snap.root = root
+	return snap, nil
+}
+
+// notify passes ev to every subscriber
+func (s *Store) notify(ev Event) {
+	s.mu.Lock()
+	subs := make([]func(Event), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.mu.Unlock()
+	for _, fn := range subs {
+		fn(ev)
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+// writeConfig writes text to the file at path, moving its modification time
+// on by a second so that a change is seen however coarse the clock
+func writeConfig(t *testing.T, path, text string) {
+	t.Helper()
+	var mod time.Time
+	if info, err := os.Stat(path); err == nil {
+		mod = info.ModTime().Add(time.Second)
+	} else {
+		mod = time.Now()
+	}
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"port": 80}`)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !jsonparser.IsFrozen(s.Root()) {
+		t.Error("expected the tree to be frozen")
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	writeConfig(t, path, `{"port": }`)
+	if _, err := Open(path); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+	writeConfig(t, path, "{\"port\": 80 // web\n}")
+	if _, err := Open(path, jsonparser.WithAllowComments(true)); err != nil {
+		t.Errorf("expected options to reach the parser, got %v", err)
+	}
+}
+
+func TestStore_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"port": 80}`)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var events []Event
+	unsubscribe := s.Subscribe(func(ev Event) { events = append(events, ev) })
+
+	first := s.Root()
+	writeConfig(t, path, `{"port": 81}`)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Previous != first || events[0].Root != s.Root() || events[0].Err != nil {
+		t.Fatalf("expected one change event, got %+v", events)
+	}
+
+	writeConfig(t, path, `{"port": 81}`)
+	if err := s.Reload(); err != nil || len(events) != 1 {
+		t.Errorf("expected no event for unchanged contents, got %v, %+v", err, events)
+	}
+
+	kept := s.Root()
+	writeConfig(t, path, `{"port": `)
+	if err := s.Reload(); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+	if s.Root() != kept || len(events) != 2 || events[1].Err == nil || events[1].Root != kept {
+		t.Errorf("expected the failure to be reported and the old tree kept, got %+v", events)
+	}
+
+	unsubscribe()
+	writeConfig(t, path, `{"port": 82}`)
+	if err := s.Reload(); err != nil || len(events) != 2 {
+		t.Errorf("expected no event after unsubscribing, got %v, %+v", err, events)
+	}
+}
+
+func TestStore_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"port": 80}`)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed := make(chan Event, 1)
+	s.Subscribe(func(ev Event) { changed <- ev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := s.Watch(ctx, 10*time.Millisecond); err != context.Canceled {
+			t.Errorf("expected Watch to end with the context, got %v", err)
+		}
+	}()
+
+	// Readers run while the tree is swapped
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if _, err := s.Int("$.port"); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	writeConfig(t, path, `{"port": 81}`)
+	select {
+	case ev := <-changed:
+		if ev.Err != nil {
+			t.Errorf("unexpected error: %v", ev.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected Watch to see the change")
+	}
+	if port, err := s.Int("$.port"); err != nil || port != 81 {
+		t.Errorf("expected the new port, got %d, %v", port, err)
+	}
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+// Get returns the single value a JSONPath expression such as
+// "$.server.port" selects in the current tree, stored in a T as
+// jsonparser.Unmarshal would. It fails when the path selects nothing or
+// more than one value.
+func Get[T any](s *Store, path string) (T, error) {
+	var zero T
+	results, err := jsonparser.QueryAs[T](s.Root(), path)
+	if err != nil {
+		return zero, fmt.Errorf("config: %w", err)
+	}
+	switch len(results) {
+	case 0:
+		return zero, fmt.Errorf("config: no value at %s", path)
+	case 1:
+		return results[0], nil
+	}
+	return zero, fmt.Errorf("config: %s selects %d values", path, len(results))
+}
+
+// String returns the string at path; see Get
+func (s *Store) String(path string) (string, error) {
+	return Get[string](s, path)
+}
+
+// Int returns the integer at path; see Get
+func (s *Store) Int(path string) (int64, error) {
+	return Get[int64](s, path)
+}
+
+// Float returns the number at path; see Get
+func (s *Store) Float(path string) (float64, error) {
+	return Get[float64](s, path)
+}
+
+// Bool returns the boolean at path; see Get
+func (s *Store) Bool(path string) (bool, error) {
+	return Get[bool](s, path)
+}
+
+// Duration returns the duration written as a string such as "1m30s" at
+// path; see Get and time.ParseDuration
+func (s *Store) Duration(path string) (time.Duration, error) {
+	text, err := s.String(path)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{
+		"name": "api",
+		"server": {"port": 8080, "debug": true, "ratio": 0.5, "timeout": "1m30s"},
+		"hosts": ["a", "b"]
+	}`)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if name, err := s.String("$.name"); err != nil || name != "api" {
+		t.Errorf("String: got %q, %v", name, err)
+	}
+	if port, err := s.Int("$.server.port"); err != nil || port != 8080 {
+		t.Errorf("Int: got %d, %v", port, err)
+	}
+	if ratio, err := s.Float("$.server.ratio"); err != nil || ratio != 0.5 {
+		t.Errorf("Float: got %v, %v", ratio, err)
+	}
+	if debug, err := s.Bool("$.server.debug"); err != nil || !debug {
+		t.Errorf("Bool: got %t, %v", debug, err)
+	}
+	if timeout, err := s.Duration("$.server.timeout"); err != nil || timeout != 90*time.Second {
+		t.Errorf("Duration: got %v, %v", timeout, err)
+	}
+	if hosts, err := Get[[]string](s, "$.hosts"); err != nil || len(hosts) != 2 {
+		t.Errorf("Get: got %q, %v", hosts, err)
+	}
+	type server struct {
+		Port  int  `json:"port"`
+		Debug bool `json:"debug"`
+	}
+	if got, err := Get[server](s, "$.server"); err != nil || got.Port != 8080 || !got.Debug {
+		t.Errorf("Get: got %+v, %v", got, err)
+	}
+
+	errorCases := map[string]func() error{
+		"no value at $.missing":          func() error { _, err := s.String("$.missing"); return err },
+		"$.hosts[*] selects 2 values":    func() error { _, err := s.String("$.hosts[*]"); return err },
+		"cannot decode string at $.name": func() error { _, err := s.Int("$.name"); return err },
+		"$.name: time: invalid duration": func() error { _, err := s.Duration("$.name"); return err },
+		"config: ":                       func() error { _, err := s.Int("$["); return err },
+	}
+	for want, get := range errorCases {
+		if err := get(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+// Layer is one source of configuration values, such as a file or the
+// environment. Custom sources, such as a remote store, can be layers too.
+type Layer struct {
+	// Name identifies the layer in provenance, such as a file's path or "env"
+	Name string
+	// Load returns the layer's values. A nil Value contributes nothing.
+	Load func() (jsonparser.Value, error)
+
+	path string // the file Watch checks, for File and OptionalFile layers
+}
+
+// File returns a layer holding the JSON file at path, parsed with opts. The
+// file must exist.
+func File(path string, opts ...jsonparser.Option) Layer {
+	return Layer{Name: path, Load: func() (jsonparser.Value, error) { return loadFile(path, opts) }, path: path}
+}
+
+// OptionalFile is like File, but a missing file contributes nothing, as for
+// an environment that has no settings of its own
+func OptionalFile(path string, opts ...jsonparser.Option) Layer {
+	load := func() (jsonparser.Value, error) {
+		v, err := loadFile(path, opts)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return v, err
+	}
+	return Layer{Name: path, Load: load, path: path}
+}
+
+func loadFile(path string, opts []jsonparser.Option) (jsonparser.Value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := jsonparser.ParseBytes(data, append(opts[:len(opts):len(opts)], jsonparser.WithSpans(true))...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return root, nil
+}
+
+// Env returns a layer, named "env", holding the environment variables whose
+// names start with prefix. The rest of a name, lowercased and split at
+// double underscores, is the path of its value, so APP_SERVER__PORT=8080
+// sets server.port for the prefix "APP_". A value that is valid JSON, such
+// as 8080, true or ["a","b"], is parsed; any other is a string.
+func Env(prefix string) Layer {
+	load := func() (jsonparser.Value, error) {
+		var names []string
+		values := make(map[string]string)
+		for _, kv := range os.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			if rest, ok := strings.CutPrefix(name, prefix); ok && rest != "" {
+				names = append(names, rest)
+				values[rest] = value
+			}
+		}
+		sort.Strings(names)
+		root := newObject()
+		for _, name := range names {
+			pointer := pointerOf(strings.Split(strings.ToLower(name), "__"))
+			if err := root.SetPath(pointer, valueOf(values[name])); err != nil {
+				return nil, fmt.Errorf("env: %s%s: %w", prefix, name, err)
+			}
+		}
+		return root, nil
+	}
+	return Layer{Name: "env", Load: load}
+}
+
+// Flags returns a layer, named "flags", holding those flags of set that were
+// given on the command line, so that a flag's default does not hide a file's
+// value. A flag's name, split at dots, is the path of its value, so
+// -server.port=8080 sets server.port. Booleans, numbers and strings keep
+// their type; durations and other flags are stored as strings.
+func Flags(set *flag.FlagSet) Layer {
+	load := func() (jsonparser.Value, error) {
+		root := newObject()
+		var err error
+		set.Visit(func(f *flag.Flag) {
+			if err == nil {
+				if e := root.SetPath(pointerOf(strings.Split(f.Name, ".")), flagValue(f.Value)); e != nil {
+					err = fmt.Errorf("flags: -%s: %w", f.Name, e)
+				}
+			}
+		})
+		return root, err
+	}
+	return Layer{Name: "flags", Load: load}
+}
+
+// pointerEscaper escapes '~' and '/' in JSON Pointer segments
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointerOf joins segments into a JSON Pointer
+func pointerOf(segments []string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(s))
+	}
+	return b.String()
+}
+
+func newObject() *jsonparser.Object {
+	return &jsonparser.Object{Pairs: make(map[string]jsonparser.Value)}
+}
+
+// valueOf parses text as JSON, or keeps it as a string when it is not valid
+func valueOf(text string) jsonparser.Value {
+	if v, err := jsonparser.Parse(text); err == nil {
+		return v
+	}
+	return &jsonparser.String{Value: text}
+}
+
+// flagValue converts a flag's value to a JSON value of the matching type
+func flagValue(v flag.Value) jsonparser.Value {
+	getter, ok := v.(flag.Getter)
+	if !ok {
+		return &jsonparser.String{Value: v.String()}
+	}
+	switch x := getter.Get().(type) {
+	case bool:
+		return &jsonparser.Boolean{Value: strconv.FormatBool(x)}
+	case int, int64, uint, uint64:
+		return &jsonparser.Number{Value: fmt.Sprint(x)}
+	case float64:
+		if text := strconv.FormatFloat(x, 'g', -1, 64); jsonparser.Valid([]byte(text)) {
+			return &jsonparser.Number{Value: text}
+		}
+	case time.Duration:
+		return &jsonparser.String{Value: x.String()}
+	}
+	return &jsonparser.String{Value: v.String()}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func TestOpenLayers(t *testing.T) {
+	dir := t.TempDir()
+	defaults := filepath.Join(dir, "defaults.json")
+	production := filepath.Join(dir, "production.json")
+	writeConfig(t, defaults, `{
+		"server": {"host": "localhost", "port": 80, "timeout": "5s"},
+		"tags": ["a", "b"],
+		"debug": false
+	}`)
+	writeConfig(t, production, `{"server": {"host": "example.com"}, "tags": ["c"]}`)
+	t.Setenv("APP_SERVER__PORT", "8080")
+	t.Setenv("APP_NAME", "api")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Duration("server.timeout", time.Second, "")
+	flags.Bool("debug", false, "")
+	flags.Int("server.port", 0, "")
+	if err := flags.Parse([]string{"-server.timeout=1m", "-debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenLayers(File(defaults), OptionalFile(production), OptionalFile(filepath.Join(dir, "missing.json")), Env("APP_"), Flags(flags))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"server":{"host":"example.com","port":8080,"timeout":"1m0s"},"tags":["c"],"debug":true,"name":"api"}`
+	if got := fmt.Sprint(s.Root()); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	sources := []struct {
+		path, want string
+	}{
+		{"$.server.host", production},
+		{"$.server.port", "env"},
+		{"$.server.timeout", "flags"},
+		{"$.server", "flags"},
+		{"$.debug", "flags"},
+		{"$.name", "env"},
+		{"$.tags", production},
+		{"$.tags[0]", production},
+		{"$", "flags"},
+	}
+	for _, tt := range sources {
+		if got, err := s.Source(tt.path); err != nil || got.Layer != tt.want {
+			t.Errorf("%s: expected %q, got %q, %v", tt.path, tt.want, got, err)
+		}
+	}
+	if _, err := s.Source("$.tags[1]"); err == nil {
+		t.Error("expected an error for a value replaced by a later layer")
+	}
+	if _, err := s.Source("$.server.*"); err == nil {
+		t.Error("expected an error for a path selecting several values")
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("TEST_LIST", `["x", 1]`)
+	t.Setenv("TEST_TEXT", "not json")
+	t.Setenv("TEST_DB__MAX_CONNS", "10")
+	t.Setenv("OTHER_VALUE", "1")
+	root, err := Env("TEST_").Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"db":{"max_conns":10},"list":["x",1],"text":"not json"}`
+	if got := fmt.Sprint(root); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	t.Setenv("TEST_TEXT__INNER", "1")
+	if _, err := Env("TEST_").Load(); err == nil || !strings.Contains(err.Error(), "TEST_TEXT__INNER") {
+		t.Errorf("expected an error naming the variable, got %v", err)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("name", "default", "")
+	flags.Float64("ratio", 0, "")
+	flags.Uint("workers", 4, "")
+	if err := flags.Parse([]string{"-ratio=0.5", "-name=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	root, err := Flags(flags).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := fmt.Sprint(root), `{"name":"8080","ratio":0.5}`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestStore_ReloadLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.json")
+	local := filepath.Join(dir, "local.json")
+	writeConfig(t, base, `{"port": 80, "host": "a"}`)
+	s, err := OpenLayers(File(base), OptionalFile(local))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var events []Event
+	s.Subscribe(func(ev Event) { events = append(events, ev) })
+
+	writeConfig(t, local, `{"port": 81}`)
+	if err := s.reloadLocked(true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || !jsonparser.Equal(events[0].Root, mustParse(t, `{"port": 81, "host": "a"}`)) {
+		t.Fatalf("expected the new file to be merged in, got %v", events)
+	}
+	if got, _ := s.Source("$.port"); got.Layer != local {
+		t.Errorf("expected port to come from %s, got %q", local, got)
+	}
+	if err := s.reloadLocked(true); err != nil || len(events) != 1 {
+		t.Errorf("expected no reload of unchanged files, got %v and %d events", err, len(events))
+	}
+
+	writeConfig(t, local, `{"port": }`)
+	if err := s.Reload(); err == nil || !strings.Contains(err.Error(), local) {
+		t.Errorf("expected an error naming the file, got %v", err)
+	}
+	if got, _ := s.Int("$.port"); got != 81 {
+		t.Errorf("expected the last good tree to be kept, got port %d", got)
+	}
+}
+
+func mustParse(t *testing.T, text string) jsonparser.Value {
+	t.Helper()
+	v, err := jsonparser.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+// Origin is where a value of the configuration was set
+type Origin struct {
+	Layer  string // the Name of the layer
+	Line   int    // the position in the layer's file, from 1; 0 for layers that are not files
+	Column int
+}
+
+// String returns the origin as file:line:column, or the layer's name alone
+// when it has no position
+func (o Origin) String() string {
+	if o.Line == 0 {
+		return o.Layer
+	}
+	return fmt.Sprintf("%s:%d:%d", o.Layer, o.Line, o.Column)
+}
+
+// setting is one layer's value at a path, before merging
+type setting struct {
+	origin Origin
+	value  jsonparser.Value
+}
+
+// Source returns where the single value a JSONPath expression selects was
+// set. An object merged from several layers names the last of them.
+func (s *Store) Source(path string) (Origin, error) {
+	history, err := s.history(path)
+	if err != nil {
+		return Origin{}, err
+	}
+	return history[len(history)-1].origin, nil
+}
+
+// Explain describes where the single value a JSONPath expression selects
+// came from, and what it overrode or was merged with, latest first:
+//
+//	$.server.port = 8080 from env
+//	  overriding 81 from production.json:3:13
+//	  overriding 80 from defaults.json:2:28
+func (s *Store) Explain(path string) (string, error) {
+	history, err := s.history(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	last := history[len(history)-1]
+	fmt.Fprintf(&b, "%s = %s from %s", path, describe(last.value), last.origin)
+	for i := len(history) - 2; i >= 0; i-- {
+		verb := "overriding"
+		_, later := history[i+1].value.(*jsonparser.Object)
+		if _, earlier := history[i].value.(*jsonparser.Object); later && earlier {
+			verb = "merged with"
+		}
+		fmt.Fprintf(&b, "\n  %s %s from %s", verb, describe(history[i].value), history[i].origin)
+	}
+	return b.String(), nil
+}
+
+// history returns the settings of the single value path selects, oldest first
+func (s *Store) history(path string) ([]setting, error) {
+	snap := s.current.Load()
+	results, err := jsonparser.Query(snap.root, path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	switch len(results) {
+	case 0:
+		return nil, fmt.Errorf("config: no value at %s", path)
+	case 1:
+		if history := snap.sources[results[0].Pointer]; len(history) > 0 {
+			return history, nil
+		}
+		// Only the empty root of a configuration with no layers is unset
+		return nil, fmt.Errorf("config: no layer set %s", path)
+	}
+	return nil, fmt.Errorf("config: %s selects %d values", path, len(results))
+}
+
+// record notes that the layer name set v, at pointer, and everything below
+// it. Unless v and the value it merges into are both objects, v replaces
+// what earlier layers had below it, so their settings are dropped.
+func record(sources map[string][]setting, name, pointer string, v jsonparser.Value) {
+	history := sources[pointer]
+	_, object := v.(*jsonparser.Object)
+	if n := len(history); n > 0 {
+		if _, ok := history[n-1].value.(*jsonparser.Object); !ok || !object {
+			for p := range sources {
+				if strings.HasPrefix(p, pointer+"/") {
+					delete(sources, p)
+				}
+			}
+		}
+	}
+	origin := Origin{Layer: name}
+	if span := jsonparser.SpanOf(v); span.IsValid() {
+		origin.Line, origin.Column = span.Start.Line, span.Start.Column
+	}
+	sources[pointer] = append(history, setting{origin: origin, value: v})
+	switch v := v.(type) {
+	case *jsonparser.Object:
+		for _, key := range v.OrderedKeys() {
+			record(sources, name, pointer+"/"+pointerEscaper.Replace(key), v.Pairs[key])
+		}
+	case *jsonparser.Array:
+		for i, e := range v.Elements {
+			record(sources, name, pointer+"/"+strconv.Itoa(i), e)
+		}
+	}
+}
+
+// describe returns v as JSON, with arrays and objects elided
+func describe(v jsonparser.Value) string {
+	switch v.(type) {
+	case *jsonparser.Object:
+		return "{...}"
+	case *jsonparser.Array:
+		return "[...]"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_Explain(t *testing.T) {
+	dir := t.TempDir()
+	defaults := filepath.Join(dir, "defaults.json")
+	production := filepath.Join(dir, "production.json")
+	writeConfig(t, defaults, "{\n  \"server\": {\"port\": 80, \"host\": \"a\"},\n  \"tags\": [\"x\", \"y\"]\n}")
+	writeConfig(t, production, "{\"server\": {\"port\": 81},\n \"tags\": {\"name\": \"z\"}}")
+	t.Setenv("EXPLAIN_SERVER__PORT", "8080")
+	s, err := OpenLayers(File(defaults), File(production), Env("EXPLAIN_"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path, want string
+	}{
+		{"$.server.port", "$.server.port = 8080 from env\n" +
+			"  overriding 81 from " + production + ":1:21\n" +
+			"  overriding 80 from " + defaults + ":2:22"},
+		{"$.server.host", `$.server.host = "a" from ` + defaults + ":2:34"},
+		{"$.server", "$.server = {...} from env\n" +
+			"  merged with {...} from " + production + ":1:12\n" +
+			"  merged with {...} from " + defaults + ":2:13"},
+		{"$.tags", "$.tags = {...} from " + production + ":2:10\n" +
+			"  overriding [...] from " + defaults + ":3:11"},
+		{"$.tags.name", `$.tags.name = "z" from ` + production + ":2:19"},
+	}
+	for _, tt := range tests {
+		if got, err := s.Explain(tt.path); err != nil || got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s (%v)", tt.path, tt.want, got, err)
+		}
+	}
+	if _, err := s.Explain("$.tags[0]"); err == nil {
+		t.Error("expected an error for a value replaced by a later layer")
+	}
+
+	empty, err := OpenLayers(OptionalFile(filepath.Join(dir, "missing.json")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := empty.Explain("$"); err == nil {
+		t.Error("expected an error for a root no layer set")
+	}
+}
+
+func TestOrigin_String(t *testing.T) {
+	tests := []struct {
+		origin Origin
+		want   string
+	}{
+		{Origin{Layer: "a.json", Line: 3, Column: 7}, "a.json:3:7"},
+		{Origin{Layer: "env"}, "env"},
+	}
+	for _, tt := range tests {
+		if got := tt.origin.String(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
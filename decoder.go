@@ -0,0 +1,254 @@
+package jsonparser
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// Token is a lexical token with its type, literal value and position
+type Token = lexer.Token
+
+// TokenType identifies the kind of a Token
+type TokenType = lexer.TokenType
+
+// Token types returned by Decoder.Token
+const (
+	TokenLeftBrace    = lexer.TokenLeftBrace
+	TokenRightBrace   = lexer.TokenRightBrace
+	TokenLeftBracket  = lexer.TokenLeftBracket
+	TokenRightBracket = lexer.TokenRightBracket
+	TokenString       = lexer.TokenString
+	TokenNumber       = lexer.TokenNumber
+	TokenTrue         = lexer.TokenTrue
+	TokenFalse        = lexer.TokenFalse
+	TokenNull         = lexer.TokenNull
+)
+
+// decodeState tracks where the Decoder is in the grammar
+type decodeState int
+
+// Decoder states
+const (
+	stateTopValue    decodeState = iota // expecting a top-level value
+	stateArrayStart                     // after '[', expecting a value or ']'
+	stateArrayValue                     // after ',' in an array, expecting a value
+	stateArrayComma                     // after an array element, expecting ',' or ']'
+	stateObjectStart                    // after '{', expecting a key or '}'
+	stateObjectKey                      // after ',' in an object, expecting a key
+	stateObjectColon                    // after a key, expecting ':'
+	stateObjectValue                    // after ':', expecting a value
+	stateObjectComma                    // after an object value, expecting ',' or '}'
+)
+
+// Decoder reads JSON tokens one at a time from an input stream.
+//
+// Token validates the grammar as it goes and consumes commas and colons
+// itself, so callers only see delimiters, keys and values. A stream may
+// hold several top-level values one after another.
+type Decoder struct {
+	lex       *lexer.Lexer
+	peeked    *Token
+	peekedEnd int64         // stream offset just past the peeked token
+	offset    int64         // stream offset just past the last token returned
+	stack     []decodeState // states to return to when the open containers close
+	state     decodeState
+	err       error
+}
+
+// DecodeError is returned by Decoder.Token for malformed input. Offset is the
+// absolute byte offset in the stream of the token that failed, counted across
+// every read, and the line and column in the message are just as global.
+type DecodeError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v (byte offset %d)", e.Err, e.Offset)
+}
+
+// Unwrap returns the underlying lexer or parser error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// NewDecoder returns a Decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{lex: lexer.NewReaderLexer(r)}
+}
+
+// Token returns the next delimiter, key or value in the stream.
+// It returns io.EOF once every top-level value has been read.
+func (d *Decoder) Token() (tok Token, err error) {
+	defer func() {
+		if _, ok := err.(*guard.Error); ok {
+			d.err = err
+		}
+	}()
+	defer guard.Recover("token", &err, func() guard.Position {
+		offset, line, column := d.lex.Position()
+		return guard.Position{Offset: offset, Line: line, Column: column}
+	})
+
+	for {
+		tok, err := d.peek()
+		if err != nil {
+			return Token{}, err
+		}
+
+		switch tok.Type {
+		case lexer.TokenComma:
+			switch d.state {
+			case stateArrayComma:
+				d.state = stateArrayValue
+			case stateObjectComma:
+				d.state = stateObjectKey
+			default:
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.peeked = nil
+			d.offset = d.peekedEnd
+			continue
+		case lexer.TokenColon:
+			if d.state != stateObjectColon {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.state = stateObjectValue
+			d.peeked = nil
+			d.offset = d.peekedEnd
+			continue
+		case lexer.TokenRightBracket:
+			if d.state != stateArrayStart && d.state != stateArrayComma {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.pop()
+		case lexer.TokenRightBrace:
+			if d.state != stateObjectStart && d.state != stateObjectComma {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.pop()
+		case lexer.TokenEOF:
+			if d.state != stateTopValue || len(d.stack) > 0 {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			return Token{}, io.EOF
+		case lexer.TokenString:
+			if d.state == stateObjectStart || d.state == stateObjectKey {
+				d.state = stateObjectColon
+				break
+			}
+			if !d.valueAllowed() {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.valueEnd()
+		case lexer.TokenLeftBracket, lexer.TokenLeftBrace:
+			if !d.valueAllowed() {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.valueEnd()
+			d.stack = append(d.stack, d.state)
+			d.state = stateArrayStart
+			if tok.Type == lexer.TokenLeftBrace {
+				d.state = stateObjectStart
+			}
+		default:
+			if !d.valueAllowed() {
+				return Token{}, d.fail(lexer.NewUnexpectedTokenError(tok, d.expected()))
+			}
+			d.valueEnd()
+		}
+
+		d.peeked = nil
+		d.offset = d.peekedEnd
+		return tok, nil
+	}
+}
+
+// InputOffset returns the stream byte offset just past the last token
+// returned by Token, where the next token's leading whitespace begins
+func (d *Decoder) InputOffset() int64 {
+	return d.offset
+}
+
+// More reports whether the current array or object has another element,
+// or at the top level whether another value follows in the stream
+func (d *Decoder) More() bool {
+	tok, err := d.peek()
+	if err != nil {
+		return false
+	}
+	if tok.Type == lexer.TokenComma {
+		return d.state == stateArrayComma || d.state == stateObjectComma
+	}
+	return tok.Type != lexer.TokenRightBracket && tok.Type != lexer.TokenRightBrace && tok.Type != lexer.TokenEOF
+}
+
+// peek returns the next lexical token without consuming it
+func (d *Decoder) peek() (Token, error) {
+	if d.err != nil {
+		return Token{}, d.err
+	}
+	if d.peeked == nil {
+		tok, err := d.lex.NextToken()
+		if err != nil {
+			return Token{}, d.fail(err)
+		}
+		d.peeked = &tok
+		d.peekedEnd, _, _ = d.lex.Position()
+	}
+	return *d.peeked, nil
+}
+
+// fail records err, located at the token being examined, so every later call returns it too
+func (d *Decoder) fail(err error) error {
+	d.err = &DecodeError{Offset: d.lex.TokenOffset(), Err: err}
+	return d.err
+}
+
+// valueAllowed checks if a value may start in the current state
+func (d *Decoder) valueAllowed() bool {
+	switch d.state {
+	case stateTopValue, stateArrayStart, stateArrayValue, stateObjectValue:
+		return true
+	}
+	return false
+}
+
+// valueEnd moves to the state that follows a complete value
+func (d *Decoder) valueEnd() {
+	switch d.state {
+	case stateArrayStart, stateArrayValue:
+		d.state = stateArrayComma
+	case stateObjectValue:
+		d.state = stateObjectComma
+	}
+}
+
+// pop closes the innermost container
+func (d *Decoder) pop() {
+	d.state = d.stack[len(d.stack)-1]
+	d.stack = d.stack[:len(d.stack)-1]
+}
+
+// expected describes what the current state accepts, for error messages
+func (d *Decoder) expected() TokenType {
+	switch d.state {
+	case stateArrayStart:
+		return "a value or ']'"
+	case stateArrayComma:
+		return "',' or ']'"
+	case stateObjectStart:
+		return "a string key or '}'"
+	case stateObjectKey:
+		return lexer.TokenString
+	case stateObjectColon:
+		return lexer.TokenColon
+	case stateObjectComma:
+		return "',' or '}'"
+	default:
+		return "a valid value"
+	}
+}
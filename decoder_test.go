@@ -0,0 +1,223 @@
+package jsonparser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/chaos"
+)
+
+func TestDecoder_Token(t *testing.T) {
+	input := `{"name": "Ada", "langs": ["go", 1.5, true, null], "nested": {}} [] "next"`
+	dec := NewDecoder(strings.NewReader(input))
+
+	want := []struct {
+		typ     TokenType
+		literal string
+	}{
+		{TokenLeftBrace, "{"},
+		{TokenString, "name"},
+		{TokenString, "Ada"},
+		{TokenString, "langs"},
+		{TokenLeftBracket, "["},
+		{TokenString, "go"},
+		{TokenNumber, "1.5"},
+		{TokenTrue, "true"},
+		{TokenNull, "null"},
+		{TokenRightBracket, "]"},
+		{TokenString, "nested"},
+		{TokenLeftBrace, "{"},
+		{TokenRightBrace, "}"},
+		{TokenRightBrace, "}"},
+		{TokenLeftBracket, "["},
+		{TokenRightBracket, "]"},
+		{TokenString, "next"},
+	}
+
+	for i, w := range want {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, err)
+		}
+		if tok.Type != w.typ || tok.Literal != w.literal {
+			t.Errorf("token %d: expected %s %q, got %s %q", i, w.typ, w.literal, tok.Type, tok.Literal)
+		}
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoder_More(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`[1, 2, 3]`))
+	if _, err := dec.Token(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var values []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		values = append(values, tok.Literal)
+	}
+	if strings.Join(values, ",") != "1,2,3" {
+		t.Errorf("expected 1,2,3, got %v", values)
+	}
+
+	if tok, err := dec.Token(); err != nil || tok.Type != TokenRightBracket {
+		t.Errorf("expected closing bracket, got %v (%v)", tok, err)
+	}
+	if dec.More() {
+		t.Errorf("expected no more values at the end of the stream")
+	}
+}
+
+func TestDecoder_SyntaxErrors(t *testing.T) {
+	inputs := map[string]string{
+		"trailing comma in array":  `[1,]`,
+		"trailing comma in object": `{"a": 1,}`,
+		"missing comma":            `[1 2]`,
+		"missing colon":            `{"a" 1}`,
+		"non-string key":           `{1: 2}`,
+		"mismatched close":         `[}`,
+		"unexpected close":         `]`,
+		"unterminated":             `{"a": [1`,
+		"leading comma":            `[,1]`,
+		"stray colon":              `[1: 2]`,
+	}
+
+	for name, input := range inputs {
+		dec := NewDecoder(strings.NewReader(input))
+		var err error
+		for err == nil {
+			_, err = dec.Token()
+		}
+		if err == io.EOF {
+			t.Errorf("%s: expected a syntax error for %s, got io.EOF", name, input)
+			continue
+		}
+		if _, again := dec.Token(); again != err {
+			t.Errorf("%s: expected the error to be sticky, got %v then %v", name, err, again)
+		}
+	}
+}
+
+func TestDecoder_Chaos(t *testing.T) {
+	corpus := [][]byte{
+		[]byte(`{"name": "Jöhn 😀", "list": [1, -2.5e3, {"a": []}], "ok": false, "none": null} {"second": 2}`),
+	}
+	chaos.Check(t, corpus, chaos.Options{Seed: 2}, func(r io.Reader) error {
+		dec := NewDecoder(r)
+		for {
+			if _, err := dec.Token(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	})
+}
+
+type panickingReader struct {
+	data []byte
+}
+
+func (r *panickingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		panic("reader exploded")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestDecoder_RecoversPanics(t *testing.T) {
+	dec := NewDecoder(&panickingReader{data: []byte(`[1, `)})
+
+	var err error
+	for err == nil {
+		_, err = dec.Token()
+	}
+	var internal *InternalError
+	if !errors.As(err, &internal) || !errors.Is(err, ErrInternal) {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+	if internal.Op != "token" || internal.Position.Offset != 3 {
+		t.Errorf("expected the token op at offset 3, got %q at %d", internal.Op, internal.Position.Offset)
+	}
+	if _, again := dec.Token(); again != err {
+		t.Errorf("expected the internal error to be sticky, got %v", again)
+	}
+}
+
+// repeatReader yields its pattern count times without holding the whole stream in memory
+type repeatReader struct {
+	pattern string
+	count   int
+	pos     int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && r.count > 0 {
+		c := copy(p[n:], r.pattern[r.pos:])
+		n += c
+		r.pos += c
+		if r.pos == len(r.pattern) {
+			r.pos = 0
+			r.count--
+		}
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func TestDecoder_ErrorPositionAcrossRefills(t *testing.T) {
+	const lines = 1 << 20
+	pattern := "[1, \"é\"]\n" // 11 bytes, 10 characters
+	stream := io.MultiReader(&repeatReader{pattern: pattern, count: lines}, strings.NewReader(`  {"a" 1}`))
+
+	dec := NewDecoder(stream)
+	var err error
+	for err == nil {
+		_, err = dec.Token()
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a *DecodeError, got %v", err)
+	}
+	if want := int64(len(pattern)*lines + 7); decodeErr.Offset != want {
+		t.Errorf("expected offset %d, got %d", want, decodeErr.Offset)
+	}
+	if want := "line 1048577, column 8"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected the error at %s, got %v", want, err)
+	}
+	if want := int64(len(pattern)*lines + 6); dec.InputOffset() != want {
+		t.Errorf("expected InputOffset %d after the key, got %d", want, dec.InputOffset())
+	}
+}
+
+func TestDecoder_InputOffset(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"a": [10, true]}`))
+	var offsets []int64
+	for {
+		if _, err := dec.Token(); err != nil {
+			break
+		}
+		offsets = append(offsets, dec.InputOffset())
+	}
+	expected := []int64{1, 4, 7, 9, 15, 16, 17}
+	if !reflect.DeepEqual(offsets, expected) {
+		t.Errorf("expected offsets %v, got %v", expected, offsets)
+	}
+}
@@ -0,0 +1,129 @@
+package jsonparser
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// ValueDecoder is a source of documents, such as a LineDecoder or a
+// SequenceDecoder, that returns io.EOF after the last
+type ValueDecoder interface {
+	Decode() (Value, error)
+}
+
+// SeenSet remembers the IDs of the records a DedupeDecoder has passed on. An
+// implementation backed by a shared store lets several consumers, or a
+// restarted one, skip the same duplicates.
+type SeenSet interface {
+	// Add records id and reports whether it was already there
+	Add(id string) bool
+}
+
+// DedupeOptions says how a DedupeDecoder identifies records and remembers them
+type DedupeOptions struct {
+	ID     string  // JSON Pointer of each record's ID, such as "/event_id"
+	Seen   SeenSet // nil selects a WindowSet of Window IDs
+	Window int     // how many of the latest IDs the default set remembers; 0 selects 10000
+}
+
+// DedupeDecoder passes on the documents of a ValueDecoder, skipping those
+// whose ID was already seen, for pipelines whose sources deliver events at
+// least once. IDs are compared by their compact JSON, so 1 and "1" are
+// different IDs, and an object ID may hold several fields. Records without
+// an ID are always passed on.
+type DedupeDecoder struct {
+	dec     ValueDecoder
+	id      string
+	seen    SeenSet
+	skipped int
+}
+
+// NewDedupeDecoder returns a DedupeDecoder reading from dec
+func NewDedupeDecoder(dec ValueDecoder, opts DedupeOptions) (*DedupeDecoder, error) {
+	if _, err := ast.SplitPointer(opts.ID); err != nil {
+		return nil, fmt.Errorf("dedupe: %w", err)
+	}
+	seen := opts.Seen
+	if seen == nil {
+		window := opts.Window
+		if window == 0 {
+			window = 10000
+		}
+		seen = NewWindowSet(window)
+	}
+	return &DedupeDecoder{dec: dec, id: opts.ID, seen: seen}, nil
+}
+
+// Decode returns the next document whose ID has not been seen. Errors from
+// the underlying decoder are returned as they are, so one that can carry on
+// after a bad record still can.
+func (d *DedupeDecoder) Decode() (Value, error) {
+	for {
+		v, err := d.dec.Decode()
+		if err != nil {
+			return nil, err
+		}
+		id, ok := d.key(v)
+		if ok && d.seen.Add(id) {
+			d.skipped++
+			continue
+		}
+		return v, nil
+	}
+}
+
+// Skipped returns how many duplicates have been skipped
+func (d *DedupeDecoder) Skipped() int {
+	return d.skipped
+}
+
+// key returns the ID of the record, and false when it has none
+func (d *DedupeDecoder) key(v Value) (string, bool) {
+	id, err := ast.Lookup(v, d.id)
+	if err != nil {
+		return "", false
+	}
+	data, err := ast.AppendJSON(nil, id)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// WindowSet is a SeenSet remembering the latest IDs added, forgetting the
+// oldest once it is full. It is safe for concurrent use.
+type WindowSet struct {
+	mu   sync.Mutex
+	ids  map[string]bool
+	ring []string // the IDs in the order added
+	next int      // where the next ID goes in ring
+}
+
+// NewWindowSet returns a WindowSet remembering up to size IDs
+func NewWindowSet(size int) *WindowSet {
+	if size < 1 {
+		size = 1
+	}
+	return &WindowSet{ids: make(map[string]bool, size), ring: make([]string, 0, size)}
+}
+
+// Add records id and reports whether it was already there. Adding an ID
+// again does not move it to the end of the window.
+func (s *WindowSet) Add(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids[id] {
+		return true
+	}
+	if len(s.ring) < cap(s.ring) {
+		s.ring = append(s.ring, id)
+	} else {
+		delete(s.ids, s.ring[s.next])
+		s.ring[s.next] = id
+		s.next = (s.next + 1) % len(s.ring)
+	}
+	s.ids[id] = true
+	return false
+}
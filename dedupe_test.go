@@ -0,0 +1,174 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDedupeDecoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    DedupeOptions
+		want    []string
+		skipped int
+	}{
+		{
+			name:    "skips repeated IDs",
+			input:   `{"id":"a","n":1}` + "\n" + `{"id":"b","n":2}` + "\n" + `{"id":"a","n":3}`,
+			opts:    DedupeOptions{ID: "/id"},
+			want:    []string{`{"id":"a","n":1}`, `{"id":"b","n":2}`},
+			skipped: 1,
+		},
+		{
+			name:    "nested ID, numbers and strings differ",
+			input:   `{"meta":{"id":1}}` + "\n" + `{"meta":{"id":"1"}}` + "\n" + `{"meta":{"id":1}}`,
+			opts:    DedupeOptions{ID: "/meta/id"},
+			want:    []string{`{"meta":{"id":1}}`, `{"meta":{"id":"1"}}`},
+			skipped: 1,
+		},
+		{
+			name:  "records without an ID pass",
+			input: `{"n":1}` + "\n" + `{"n":1}` + "\n" + `[1]`,
+			opts:  DedupeOptions{ID: "/id"},
+			want:  []string{`{"n":1}`, `{"n":1}`, `[1]`},
+		},
+		{
+			name:    "window forgets the oldest",
+			input:   `{"id":1}` + "\n" + `{"id":2}` + "\n" + `{"id":3}` + "\n" + `{"id":1}` + "\n" + `{"id":3}`,
+			opts:    DedupeOptions{ID: "/id", Window: 2},
+			want:    []string{`{"id":1}`, `{"id":2}`, `{"id":3}`, `{"id":1}`},
+			skipped: 1,
+		},
+		{
+			name:    "whole record as ID",
+			input:   `{"a":1}` + "\n" + `{"a":1}` + "\n" + `{"a":2}`,
+			opts:    DedupeOptions{},
+			want:    []string{`{"a":1}`, `{"a":2}`},
+			skipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec, err := NewDedupeDecoder(NewLineDecoder(strings.NewReader(tt.input)), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for {
+				v, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := Marshal(v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(data))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("decoded %q, want %q", got, tt.want)
+			}
+			if dec.Skipped() != tt.skipped {
+				t.Errorf("Skipped() = %d, want %d", dec.Skipped(), tt.skipped)
+			}
+		})
+	}
+}
+
+func TestDedupeDecoder_Errors(t *testing.T) {
+	if _, err := NewDedupeDecoder(NewLineDecoder(strings.NewReader("")), DedupeOptions{ID: "id"}); err == nil {
+		t.Error("NewDedupeDecoder accepted an ID that is not a pointer")
+	}
+
+	// A bad record is passed through and decoding carries on after it
+	input := `{"id":1}` + "\n" + `{"id":` + "\n" + `{"id":1}` + "\n" + `{"id":2}`
+	dec, err := NewDedupeDecoder(NewLineDecoder(strings.NewReader(input)), DedupeOptions{ID: "/id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	var lineErr *LineError
+	for {
+		v, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if errors.As(err, &lineErr) {
+			ids = append(ids, fmt.Sprintf("error on line %d", lineErr.Line))
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := Lookup(v, "/id")
+		ids = append(ids, id.(*Number).Value)
+	}
+	if got := strings.Join(ids, "|"); got != "1|error on line 2|2" {
+		t.Errorf("decoded %s", got)
+	}
+}
+
+// sharedSet is a SeenSet standing in for one backed by an external store
+type sharedSet struct{ ids map[string]bool }
+
+func (s *sharedSet) Add(id string) bool {
+	seen := s.ids[id]
+	s.ids[id] = true
+	return seen
+}
+
+func TestDedupeDecoder_SeenSet(t *testing.T) {
+	seen := &sharedSet{ids: map[string]bool{`"a"`: true}}
+	for _, input := range []string{`{"id":"a"}` + "\n" + `{"id":"b"}`, `{"id":"b"}` + "\n" + `{"id":"c"}`} {
+		dec, err := NewDedupeDecoder(NewLineDecoder(strings.NewReader(input)), DedupeOptions{ID: "/id", Seen: seen})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			if _, err := dec.Decode(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if dec.Skipped() != 1 {
+			t.Errorf("%q: Skipped() = %d, want 1", input, dec.Skipped())
+		}
+	}
+	if len(seen.ids) != 3 {
+		t.Errorf("seen set holds %v", seen.ids)
+	}
+}
+
+func TestWindowSet_Concurrent(t *testing.T) {
+	s := NewWindowSet(100)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				if !s.Add(fmt.Sprint(i)) {
+					mu.Lock()
+					added++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if added != 50 {
+		t.Errorf("%d IDs were new, want 50", added)
+	}
+}
@@ -0,0 +1,121 @@
+// Package dialect is the extension point for formats related to JSON, such
+// as HJSON or Relaxed JSON, so they can live in separate modules.
+//
+// A Dialect adds lexer hooks, which recognize extra tokens, and parser hooks,
+// which build values from those tokens. Hooks see the input only through
+// the Scanner and Parser interfaces, which stay stable while the internals
+// of the lexer and parser change. Dialects usually register
+// themselves from an init function and are selected with
+// jsonparser.WithDialect:
+//
+//	value, err := jsonparser.Parse(input, jsonparser.WithDialect(hjson.Dialect))
+package dialect
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// Token is a lexical token: its type, its literal text and where it starts
+type Token = lexer.Token
+
+// TokenType names a kind of token. Dialects may define their own.
+type TokenType = lexer.TokenType
+
+// Standard token types. A TokenHook that returns TokenString, TokenNumber,
+// TokenTrue, TokenFalse or TokenNull needs no ValueHook, TokenSkip makes
+// the lexer ignore the input the hook consumed, and TokenIdentifier is an
+// unquoted name the parser accepts as an object key. An identifier in the
+// place of a value needs a ValueHook.
+const (
+	TokenLeftBrace    = lexer.TokenLeftBrace
+	TokenRightBrace   = lexer.TokenRightBrace
+	TokenLeftBracket  = lexer.TokenLeftBracket
+	TokenRightBracket = lexer.TokenRightBracket
+	TokenColon        = lexer.TokenColon
+	TokenComma        = lexer.TokenComma
+	TokenString       = lexer.TokenString
+	TokenNumber       = lexer.TokenNumber
+	TokenTrue         = lexer.TokenTrue
+	TokenFalse        = lexer.TokenFalse
+	TokenNull         = lexer.TokenNull
+	TokenEOF          = lexer.TokenEOF
+	TokenSkip         = lexer.TokenSkip
+	TokenIdentifier   = lexer.TokenIdentifier
+)
+
+// Scanner is the view of the input given to a TokenHook: Peek(i) returns the
+// character i positions after the current one, or 0 past the end of input,
+// and Advance(n) consumes n characters.
+type Scanner = lexer.Scanner
+
+// TokenHook recognizes a dialect token at the current character. When the
+// input does not start with its token it returns ok false without advancing;
+// otherwise it advances past the token and returns it.
+type TokenHook = lexer.TokenHook
+
+// Parser is the view of the parser given to a ValueHook. Current returns
+// the token being parsed, Next consumes it, ParseValue parses a nested value
+// and Errorf reports an error at the current token.
+type Parser = parser.HookParser
+
+// ValueHook parses a value starting with a dialect token. It must consume
+// every token of the value.
+type ValueHook = parser.ValueHook
+
+// Dialect describes the extensions a format makes to standard JSON
+type Dialect struct {
+	Name string
+	// Tokens are offered every token position, in order, before the standard grammar
+	Tokens []TokenHook
+	// Values parse values starting with tokens of the given dialect types
+	Values map[TokenType]ValueHook
+	// AllowComments skips // line and /* block */ comments between tokens
+	AllowComments bool
+	// AllowTrailingCommas accepts a comma before a closing bracket or brace
+	AllowTrailingCommas bool
+}
+
+var (
+	mu       sync.RWMutex
+	dialects = make(map[string]*Dialect)
+)
+
+// Register makes d available to Lookup under its name, failing if the name is empty or already taken
+func Register(d *Dialect) error {
+	if d.Name == "" {
+		return fmt.Errorf("dialect: empty name")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := dialects[d.Name]; ok {
+		return fmt.Errorf("dialect: %q is already registered", d.Name)
+	}
+	dialects[d.Name] = d
+	return nil
+}
+
+// Lookup returns the dialect registered under name
+func Lookup(name string) (*Dialect, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	d, ok := dialects[name]
+	return d, ok
+}
+
+// Names returns the registered dialect names in sorted order
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,246 @@
+package dialect_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/dialect"
+)
+
+const (
+	tokenHex   dialect.TokenType = "HEX"
+	tokenTuple dialect.TokenType = "<"
+)
+
+// relaxed is a small dialect built only on the public API: 'single quoted'
+// and bare word strings, # comments, 0x hex numbers, <a, b> tuples read as
+// arrays, and trailing commas
+var relaxed = &dialect.Dialect{
+	Name:                "relaxed-test",
+	Tokens:              []dialect.TokenHook{scanComment, scanQuoted, scanWord, scanHex, scanTuple},
+	Values:              map[dialect.TokenType]dialect.ValueHook{tokenHex: parseHex, tokenTuple: parseTuple},
+	AllowTrailingCommas: true,
+}
+
+func scanComment(s dialect.Scanner) (dialect.Token, bool, error) {
+	if s.Peek(0) != '#' {
+		return dialect.Token{}, false, nil
+	}
+	for s.Peek(0) != '\n' && s.Peek(0) != 0 {
+		s.Advance(1)
+	}
+	return dialect.Token{Type: dialect.TokenSkip}, true, nil
+}
+
+func scanQuoted(s dialect.Scanner) (dialect.Token, bool, error) {
+	if s.Peek(0) != '\'' {
+		return dialect.Token{}, false, nil
+	}
+	s.Advance(1)
+	var b strings.Builder
+	for s.Peek(0) != '\'' {
+		if s.Peek(0) == 0 {
+			return dialect.Token{}, false, errors.New("unterminated string")
+		}
+		b.WriteRune(s.Peek(0))
+		s.Advance(1)
+	}
+	s.Advance(1)
+	return dialect.Token{Type: dialect.TokenString, Literal: b.String()}, true, nil
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_'
+}
+
+func scanWord(s dialect.Scanner) (dialect.Token, bool, error) {
+	n := 0
+	for isLetter(s.Peek(n)) {
+		n++
+	}
+	if n == 0 {
+		return dialect.Token{}, false, nil
+	}
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteRune(s.Peek(i))
+	}
+	word := b.String()
+	if word == "true" || word == "false" || word == "null" {
+		return dialect.Token{}, false, nil // leave keywords to the standard grammar
+	}
+	s.Advance(n)
+	return dialect.Token{Type: dialect.TokenString, Literal: word}, true, nil
+}
+
+func scanHex(s dialect.Scanner) (dialect.Token, bool, error) {
+	if s.Peek(0) != '0' || s.Peek(1) != 'x' {
+		return dialect.Token{}, false, nil
+	}
+	n := 2
+	for strings.ContainsRune("0123456789abcdefABCDEF", s.Peek(n)) && s.Peek(n) != 0 {
+		n++
+	}
+	var b strings.Builder
+	for i := 2; i < n; i++ {
+		b.WriteRune(s.Peek(i))
+	}
+	s.Advance(n)
+	return dialect.Token{Type: tokenHex, Literal: b.String()}, true, nil
+}
+
+func scanTuple(s dialect.Scanner) (dialect.Token, bool, error) {
+	switch s.Peek(0) {
+	case '<':
+		s.Advance(1)
+		return dialect.Token{Type: tokenTuple, Literal: "<"}, true, nil
+	case '>':
+		s.Advance(1)
+		return dialect.Token{Type: ">", Literal: ">"}, true, nil
+	}
+	return dialect.Token{}, false, nil
+}
+
+func parseHex(p dialect.Parser) (jsonparser.Value, error) {
+	tok := p.Current()
+	n, err := strconv.ParseUint(tok.Literal, 16, 64)
+	if err != nil {
+		return nil, p.Errorf("invalid hex number %q", tok.Literal)
+	}
+	p.Next()
+	return &jsonparser.Number{Value: strconv.FormatUint(n, 10)}, nil
+}
+
+func parseTuple(p dialect.Parser) (jsonparser.Value, error) {
+	p.Next() // skip '<'
+	tuple := &jsonparser.Array{}
+	for {
+		value, err := p.ParseValue()
+		if err != nil {
+			return nil, err
+		}
+		tuple.Elements = append(tuple.Elements, value)
+		if p.Current().Type != dialect.TokenComma {
+			break
+		}
+		p.Next()
+	}
+	if p.Current().Type != ">" {
+		return nil, p.Errorf("expected '>' to close the tuple")
+	}
+	p.Next()
+	return tuple, nil
+}
+
+func TestDialect_Parse(t *testing.T) {
+	input := `
+# relaxed config
+{
+	name: 'my app',
+	port: 0x1F90,
+	origin: <1, 0x10, [true,]>,
+	tags: [web, api,],
+}`
+	value, err := jsonparser.Parse(input, jsonparser.WithDialect(relaxed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"name":"my app","port":8080,"origin":[1,16,[true]],"tags":["web","api"]}`
+	if got := value.(*jsonparser.Object).String(); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	if _, err := jsonparser.Parse(input); err == nil {
+		t.Error("expected standard JSON to reject the dialect input")
+	}
+}
+
+func TestDialect_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+		code  jsonparser.ErrorCode
+	}{
+		{`'open`, "Lexer error at line 1, column 1: unterminated string", jsonparser.CodeDialect},
+		{`[0xZ]`, `Parser error at line 1, column 2: invalid hex number "" at $[0]`, jsonparser.CodeInvalidValue},
+		{`<1 2>`, "Parser error at line 1, column 4: expected '>' to close the tuple", jsonparser.CodeInvalidValue},
+		{`>`, `Parser error at line 1, column 1: expected a valid value, got > ">"`, jsonparser.CodeUnexpectedToken},
+	}
+
+	for _, tt := range tests {
+		_, err := jsonparser.Parse(tt.input, jsonparser.WithDialect(relaxed))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.err)
+		}
+		verr := jsonparser.Validate([]byte(tt.input), jsonparser.WithDialect(relaxed))
+		if verr == nil || verr.Error() != tt.err {
+			t.Errorf("Validate(%q) error = %v, want %q", tt.input, verr, tt.err)
+		}
+		if code := jsonparser.ErrorCodeOf(err); code != tt.code {
+			t.Errorf("Parse(%q) error code = %s, want %s", tt.input, code, tt.code)
+		}
+	}
+}
+
+func TestDialect_MaxDepth(t *testing.T) {
+	input := strings.Repeat("<", 5) + "1" + strings.Repeat(">", 5)
+	if _, err := jsonparser.Parse(input, jsonparser.WithDialect(relaxed), jsonparser.WithMaxDepth(5)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := jsonparser.Parse(input, jsonparser.WithDialect(relaxed), jsonparser.WithMaxDepth(4)); err == nil {
+		t.Error("expected tuples to count towards the depth limit")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if err := dialect.Register(relaxed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dialect.Register(relaxed); err == nil {
+		t.Error("expected an error registering a name twice")
+	}
+	if err := dialect.Register(&dialect.Dialect{}); err == nil {
+		t.Error("expected an error registering an empty name")
+	}
+
+	if d, ok := dialect.Lookup("relaxed-test"); !ok || d != relaxed {
+		t.Errorf("Lookup returned %v, %v", d, ok)
+	}
+	if names := dialect.Names(); len(names) != 1 || names[0] != "relaxed-test" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func TestJSONC(t *testing.T) {
+	input := `// settings.json
+{
+	"editor.tabSize": 2, // spaces
+	/* block
+	   comment */
+	"files.exclude": {"**/.git": true,},
+}`
+	v, err := jsonparser.Parse(input, jsonparser.WithDialect(dialect.JSONC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := jsonparser.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"editor.tabSize":2,"files.exclude":{"**/.git":true}}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	for _, input := range []string{`{"a": 1 /* open`, `{"a": 1,, }`, `{"a": 1} // trailing`} {
+		_, err := jsonparser.Parse(input, jsonparser.WithDialect(dialect.JSONC))
+		if (err == nil) != strings.HasSuffix(input, "trailing") {
+			t.Errorf("Parse(%q) error = %v", input, err)
+		}
+	}
+	if _, err := jsonparser.Parse(`{"a": 1,}`); err == nil {
+		t.Error("expected standard JSON to reject a trailing comma")
+	}
+}
@@ -0,0 +1,336 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// JSON5 is the JSON5 grammar (https://spec.json5.org): comments, trailing
+// commas, unquoted identifiers as object keys, single-quoted strings with
+// JavaScript escapes and line continuations, and numbers in hexadecimal,
+// with a leading or trailing decimal point, with a plus sign, or Infinity
+// and NaN. Numbers are stored as the standard JSON literal for the same
+// value, so 0x1F is read as 31 and .5 as 0.5; only Infinity, -Infinity and
+// NaN, which JSON has no literal for, are kept as written. Like JSONC it is
+// not registered.
+var JSON5 = &Dialect{
+	Name:                "json5",
+	Tokens:              []TokenHook{skipJSON5Space, scanJSON5String, scanJSON5Number, scanIdentifier},
+	Values:              map[TokenType]ValueHook{TokenIdentifier: parseIdentifier},
+	AllowComments:       true,
+	AllowTrailingCommas: true,
+}
+
+// skipJSON5Space skips the whitespace JSON5 allows beyond JSON's: '\v',
+// '\f', the Unicode space separators, line and paragraph separators, and
+// the byte order mark
+func skipJSON5Space(s Scanner) (Token, bool, error) {
+	n := 0
+	for r := s.Peek(n); r == '\v' || r == '\f' || r == '\u2028' || r == '\u2029' || r == '\ufeff' || unicode.Is(unicode.Zs, r); r = s.Peek(n) {
+		n++
+	}
+	if n == 0 {
+		return Token{}, false, nil
+	}
+	s.Advance(n)
+	return Token{Type: TokenSkip}, true, nil
+}
+
+// scanJSON5String scans a single- or double-quoted string
+func scanJSON5String(s Scanner) (Token, bool, error) {
+	quote := s.Peek(0)
+	if quote != '"' && quote != '\'' {
+		return Token{}, false, nil
+	}
+	s.Advance(1)
+	var b strings.Builder
+	for {
+		c := s.Peek(0)
+		switch {
+		case c == quote:
+			s.Advance(1)
+			return Token{Type: TokenString, Literal: b.String()}, true, nil
+		case c == 0:
+			return Token{}, false, errors.New("unterminated string")
+		case c == '\n' || c == '\r':
+			return Token{}, false, errors.New("line break in string; escape it with a backslash")
+		case c == '\\':
+			s.Advance(1)
+			if err := scanEscape(s, &b); err != nil {
+				return Token{}, false, err
+			}
+		default:
+			b.WriteRune(c)
+			s.Advance(1)
+		}
+	}
+}
+
+// singleEscapes maps the characters of single-character escapes to what they stand for
+var singleEscapes = map[rune]rune{'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v'}
+
+// scanEscape scans the escape sequence after a backslash in a string
+func scanEscape(s Scanner, b *strings.Builder) error {
+	c := s.Peek(0)
+	switch {
+	case c == 0:
+		return errors.New("unterminated string")
+	case c == '\r':
+		s.Advance(1)
+		if s.Peek(0) == '\n' {
+			s.Advance(1)
+		}
+		return nil // a line continuation
+	case c == '\n' || c == '\u2028' || c == '\u2029':
+		s.Advance(1)
+		return nil
+	case c == 'x':
+		s.Advance(1)
+		r, err := scanHexDigits(s, 2)
+		if err != nil {
+			return err
+		}
+		b.WriteRune(r)
+		return nil
+	case c == 'u':
+		s.Advance(1)
+		r, err := scanUnicodeEscape(s)
+		if err != nil {
+			return err
+		}
+		b.WriteRune(r)
+		return nil
+	case c == '0' && !isDecimal(s.Peek(1)):
+		s.Advance(1)
+		b.WriteByte(0)
+		return nil
+	case isDecimal(c):
+		return fmt.Errorf("invalid escape \\%c in string", c)
+	}
+	if r, ok := singleEscapes[c]; ok {
+		c = r
+	}
+	b.WriteRune(c) // any other character, such as a quote, stands for itself
+	s.Advance(1)
+	return nil
+}
+
+// scanUnicodeEscape scans the four hex digits after \u, and the low half of
+// a surrogate pair when they are a high half
+func scanUnicodeEscape(s Scanner) (rune, error) {
+	r, err := scanHexDigits(s, 4)
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+	if s.Peek(0) != '\\' || s.Peek(1) != 'u' {
+		return unicode.ReplacementChar, nil
+	}
+	s.Advance(2)
+	low, err := scanHexDigits(s, 4)
+	if err != nil {
+		return 0, err
+	}
+	return utf16.DecodeRune(r, low), nil
+}
+
+// scanHexDigits scans n hex digits
+func scanHexDigits(s Scanner, n int) (rune, error) {
+	var r rune
+	for range n {
+		d, ok := hexValue(s.Peek(0))
+		if !ok {
+			return 0, errors.New("invalid hex escape in string")
+		}
+		r = r<<4 | d
+		s.Advance(1)
+	}
+	return r, nil
+}
+
+func hexValue(c rune) (rune, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func isDecimal(c rune) bool {
+	return '0' <= c && c <= '9'
+}
+
+// scanJSON5Number scans the numbers JSON5 adds to JSON, leaving standard
+// JSON numbers to the lexer
+func scanJSON5Number(s Scanner) (Token, bool, error) {
+	i, literal := 0, ""
+	if c := s.Peek(0); c == '+' || c == '-' {
+		if c == '-' {
+			literal = "-"
+		}
+		i++
+	}
+
+	c := s.Peek(i)
+	decimal := false
+	switch {
+	case c == 'I' || c == 'N':
+		if i == 0 {
+			return Token{}, false, nil // an identifier, unless it is a value
+		}
+		n := i
+		for unicode.IsLetter(s.Peek(n)) {
+			n++
+		}
+		switch word := peekString(s, i, n); word {
+		case "Infinity":
+			literal += word
+		case "NaN":
+			literal = word
+		default:
+			return Token{}, false, fmt.Errorf("invalid number %q", peekString(s, 0, n))
+		}
+		i = n
+	case c == '0' && (s.Peek(i+1) == 'x' || s.Peek(i+1) == 'X'):
+		n := i + 2
+		for _, ok := hexValue(s.Peek(n)); ok; _, ok = hexValue(s.Peek(n)) {
+			n++
+		}
+		if n == i+2 {
+			return Token{}, false, errors.New("expected hex digit in number")
+		}
+		value, _ := new(big.Int).SetString(peekString(s, i+2, n), 16)
+		literal += value.String()
+		i = n
+	case isDecimal(c) || c == '.' && isDecimal(s.Peek(i+1)):
+		decimal = true
+		start := i
+		for isDecimal(s.Peek(i)) {
+			i++
+		}
+		integer := peekString(s, start, i)
+		if len(integer) > 1 && integer[0] == '0' {
+			return Token{}, false, errors.New("invalid number format: leading zeros are not allowed")
+		}
+		if integer == "" {
+			integer = "0"
+		}
+		literal += integer
+		if s.Peek(i) == '.' {
+			i++
+			start = i
+			for isDecimal(s.Peek(i)) {
+				i++
+			}
+			if start < i {
+				literal += "." + peekString(s, start, i)
+			}
+		}
+		if e := s.Peek(i); e == 'e' || e == 'E' {
+			start = i
+			i++
+			if sign := s.Peek(i); sign == '+' || sign == '-' {
+				i++
+			}
+			if !isDecimal(s.Peek(i)) {
+				return Token{}, false, errors.New("expected digit after exponent")
+			}
+			for isDecimal(s.Peek(i)) {
+				i++
+			}
+			literal += peekString(s, start, i)
+		}
+	default:
+		return Token{}, false, nil
+	}
+
+	if next := s.Peek(i); unicode.IsLetter(next) || isDecimal(next) {
+		return Token{}, false, errors.New("invalid character following number")
+	}
+	if decimal && literal == peekString(s, 0, i) {
+		return Token{}, false, nil // standard JSON
+	}
+	if _, err := strconv.ParseFloat(literal, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+		return Token{}, false, fmt.Errorf("invalid number format: %v", err)
+	}
+	s.Advance(i)
+	return Token{Type: TokenNumber, Literal: literal}, true, nil
+}
+
+// peekString returns the characters from position i up to n
+func peekString(s Scanner, i, n int) string {
+	var b strings.Builder
+	for ; i < n; i++ {
+		b.WriteRune(s.Peek(i))
+	}
+	return b.String()
+}
+
+// scanIdentifier scans an ECMAScript IdentifierName, which may hold \u
+// escapes
+func scanIdentifier(s Scanner) (Token, bool, error) {
+	c := s.Peek(0)
+	if !isIdentifierStart(c) && c != '\\' {
+		return Token{}, false, nil
+	}
+	var b strings.Builder
+	for first := true; ; first = false {
+		c := s.Peek(0)
+		if c == '\\' {
+			if s.Peek(1) != 'u' {
+				return Token{}, false, errors.New("invalid escape in identifier")
+			}
+			s.Advance(2)
+			r, err := scanUnicodeEscape(s)
+			if err != nil {
+				return Token{}, false, err
+			}
+			if !isIdentifierStart(r) && (first || !isIdentifierPart(r)) {
+				return Token{}, false, fmt.Errorf("escape for %q in identifier", r)
+			}
+			b.WriteRune(r)
+			continue
+		}
+		if !isIdentifierStart(c) && !isIdentifierPart(c) {
+			return Token{Type: TokenIdentifier, Literal: b.String()}, true, nil
+		}
+		b.WriteRune(c)
+		s.Advance(1)
+	}
+}
+
+func isIdentifierStart(c rune) bool {
+	return c == '$' || c == '_' || unicode.IsLetter(c) || unicode.Is(unicode.Nl, c)
+}
+
+func isIdentifierPart(c rune) bool {
+	return unicode.In(c, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) || c == '\u200c' || c == '\u200d'
+}
+
+// parseIdentifier reads the identifiers that are values; any other is only
+// allowed as an object key
+func parseIdentifier(p Parser) (ast.Value, error) {
+	var v ast.Value
+	switch name := p.Current().Literal; name {
+	case "true", "false":
+		v = &ast.Boolean{Value: name}
+	case "null":
+		v = &ast.Null{}
+	case "Infinity", "NaN":
+		v = &ast.Number{Value: name}
+	default:
+		return nil, p.Errorf("unquoted %q is only allowed as an object key", name)
+	}
+	p.Next()
+	return v, nil
+}
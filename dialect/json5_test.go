@@ -0,0 +1,124 @@
+package dialect_test
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/dialect"
+)
+
+func TestJSON5(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"identifier keys", `{unquoted: 1, $dollar_1: 2, café: 3, a\u0062: 4}`, `{"unquoted":1,"$dollar_1":2,"café":3,"ab":4}`},
+		{"keywords as keys", `{null: null, true: true, Infinity: 1, NaN: 2}`, `{"null":null,"true":true,"Infinity":1,"NaN":2}`},
+		{"single quotes", `['it\'s', '"quoted"', "it's"]`, `["it's","\"quoted\"","it's"]`},
+		{"escapes", `['\x41é\v\0\q', "😀"]`, `["Aé\u000b\u0000q","😀"]`},
+		{"line continuation", "'one \\\ntwo \\\r\nthree'", `"one two three"`},
+		{"hex", `[0x1F, 0XaB, -0x10, 0xFFFFFFFFFFFFFFFFFF]`, `[31,171,-16,4722366482869645213695]`},
+		{"decimal points", `[.5, 5., -.5, 5.e2, .5E-1]`, `[0.5,5,-0.5,5e2,0.5E-1]`},
+		{"plus sign", `[+1, +.5, +0x10]`, `[1,0.5,16]`},
+		{"standard numbers", `[0, -1.5e3, 12]`, `[0,-1.5e3,12]`},
+		{"numbers beyond float64", `[.1e400, -1e999, 0x1` + strings.Repeat("0", 300) + `]`, `[0.1e400,-1e999,` + new(big.Int).Lsh(big.NewInt(1), 1200).String() + `]`},
+		{"infinity and NaN", `[Infinity, -Infinity, +Infinity, NaN, -NaN]`, `[Infinity,-Infinity,Infinity,NaN,NaN]`},
+		{"comments and trailing commas", "// top\n{a: [1, 2,], /* b */ b: {c: 3,},}", `{"a":[1,2],"b":{"c":3}}`},
+		{"byte order mark", "\ufeff{a: 1}", `{"a":1}`},
+		{"keywords", `[true, false, null]`, `[true,false,null]`},
+		{"whitespace", "{\va:\f1 }", `{"a":1}`},
+		{"Unicode whitespace", "[1,\u00a0\u2028 \u3000\ufeff2\u2029]", `[1,2]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := jsonparser.Parse(tt.input, jsonparser.WithDialect(dialect.JSON5))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := jsonparser.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if !jsonparser.Valid([]byte(tt.input), jsonparser.WithDialect(dialect.JSON5)) {
+				t.Error("Valid reported the input invalid")
+			}
+		})
+	}
+}
+
+func TestJSON5_Infinity(t *testing.T) {
+	v, err := jsonparser.Parse(`[Infinity, -Infinity, NaN]`, jsonparser.WithDialect(dialect.JSON5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	native, err := jsonparser.ToInterface(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := native.([]interface{})
+	if !math.IsInf(values[0].(float64), 1) || !math.IsInf(values[1].(float64), -1) || !math.IsNaN(values[2].(float64)) {
+		t.Errorf("ToInterface returned %v", values)
+	}
+}
+
+func TestJSON5_Spans(t *testing.T) {
+	input := "{\"k\": 'abc',\n n: 0x10}"
+	root, err := jsonparser.Parse(input, jsonparser.WithDialect(dialect.JSON5), jsonparser.WithSpans(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pointer, text string
+		line, column  int
+	}{
+		{"", input, 1, 1},
+		{"/k", "'abc'", 1, 7},
+		{"/n", "0x10", 2, 5},
+	}
+	for _, tt := range tests {
+		v, err := jsonparser.Lookup(root, tt.pointer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		span := jsonparser.SpanOf(v)
+		if got := input[span.Start.Offset:span.End.Offset]; got != tt.text || span.Start.Line != tt.line || span.Start.Column != tt.column {
+			t.Errorf("%q: span %+v covers %q, want %q at line %d, column %d", tt.pointer, span, got, tt.text, tt.line, tt.column)
+		}
+	}
+}
+
+func TestJSON5_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{a: foo}`, `Parser error at line 1, column 5: unquoted "foo" is only allowed as an object key at $.a`},
+		{`[foo]`, `Parser error at line 1, column 2: unquoted "foo" is only allowed as an object key at $[0]`},
+		{`'open`, "Lexer error at line 1, column 1: unterminated string"},
+		{"'one\ntwo'", "Lexer error at line 1, column 1: line break in string; escape it with a backslash"},
+		{`'\1'`, `Lexer error at line 1, column 1: invalid escape \1 in string`},
+		{`'\xZZ'`, "Lexer error at line 1, column 1: invalid hex escape in string"},
+		{`0x`, "Lexer error at line 1, column 1: expected hex digit in number"},
+		{`0x1G`, "Lexer error at line 1, column 1: invalid character following number"},
+		{`-Infinit`, `Lexer error at line 1, column 1: invalid number "-Infinit"`},
+		{`01.`, "Lexer error at line 1, column 1: invalid number format: leading zeros are not allowed"},
+		{`.5e`, "Lexer error at line 1, column 1: expected digit after exponent"},
+		{`{a\u0020b: 1}`, `Lexer error at line 1, column 2: escape for ' ' in identifier`},
+		{`{a: 1 b: 2}`, `Parser error at line 1, column 7: expected }, got IDENTIFIER "b"`},
+	}
+
+	for _, tt := range tests {
+		_, err := jsonparser.Parse(tt.input, jsonparser.WithDialect(dialect.JSON5))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
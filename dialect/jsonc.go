@@ -0,0 +1,7 @@
+package dialect
+
+// JSONC is JSON with comments, the format of VS Code's settings.json and
+// of tsconfig.json: // line and /* block */ comments between tokens, and a
+// comma before a closing bracket or brace. It is not registered, so that
+// Lookup holds only the dialects a program adds.
+var JSONC = &Dialect{Name: "jsonc", AllowComments: true, AllowTrailingCommas: true}
@@ -0,0 +1,49 @@
+package jsonparser
+
+import (
+	"context"
+	"io"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// Document holds a parsed tree with the options to parse it, so documents
+// plug into io plumbing: it is an io.ReaderFrom parsing a stream such as a
+// request body, and an io.WriterTo writing the tree back out, as to an
+// http.ResponseWriter. Every node is an io.WriterTo too.
+type Document struct {
+	Root    Value
+	Options []Option
+}
+
+// ReadFrom parses the document read from r, up to EOF, into Root, and
+// returns the number of bytes read. The input is tokenized as it arrives
+// rather than read whole first. Root is left alone when nothing could be
+// parsed; WithRecovery, it holds what could be, next to the errors.
+func (d *Document) ReadFrom(r io.Reader) (int64, error) {
+	counter := &countingReader{r: r}
+	root, err := parse(context.Background(), lexer.NewReaderLexer(counter), newConfig(d.Options))
+	if root != nil {
+		d.Root = root
+	}
+	return counter.n, err
+}
+
+// WriteTo writes the compact JSON text of Root to w, as it is built, and
+// returns the number of bytes written
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	return ast.WriteTo(w, d.Root)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
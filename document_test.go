@@ -0,0 +1,53 @@
+package jsonparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDocument_ReadFrom(t *testing.T) {
+	input := "// settings\n{\"name\": \"api\", \"ports\": [80, 443]}\n"
+	var doc Document
+	doc.Options = []Option{WithAllowComments(true)}
+	n, err := doc.ReadFrom(iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != int64(len(input)) {
+		t.Errorf("expected %d bytes read, got %d", len(input), n)
+	}
+	if got := doc.Root.(*Object).String(); got != `{"name":"api","ports":[80,443]}` {
+		t.Errorf("unexpected root %s", got)
+	}
+
+	if _, err := doc.ReadFrom(strings.NewReader(`{"name": }`)); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+	if doc.Root == nil {
+		t.Error("expected a failed read to keep the previous root")
+	}
+	failing := iotest.ErrReader(errors.New("connection reset"))
+	if _, err := doc.ReadFrom(io.MultiReader(strings.NewReader(`[1, `), failing)); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+func TestDocument_WriteTo(t *testing.T) {
+	doc := &Document{Root: MustParse(`{"ok": true, "items": [1, "two", null]}`)}
+	rec := httptest.NewRecorder()
+	n, err := doc.WriteTo(rec)
+	want := `{"ok":true,"items":[1,"two",null]}`
+	if err != nil || rec.Body.String() != want || n != int64(len(want)) {
+		t.Errorf("WriteTo() = %d, %v, wrote %q, want %q", n, err, rec.Body.String(), want)
+	}
+
+	var buf bytes.Buffer
+	if n, err := (&Document{}).WriteTo(&buf); err != nil || buf.String() != "null" || n != 4 {
+		t.Errorf("expected an empty document to write null, got %d, %v, %q", n, err, buf.String())
+	}
+}
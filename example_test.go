@@ -0,0 +1,166 @@
+package jsonparser_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func ExampleParse() {
+	value, err := jsonparser.Parse(`{"name": "Ada", "langs": ["go", "c"]}`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	obj := value.(*jsonparser.Object)
+	fmt.Println(obj.Pairs["name"].(*jsonparser.String).Value)
+	fmt.Println(len(obj.Pairs["langs"].(*jsonparser.Array).Elements))
+	// Output:
+	// Ada
+	// 2
+}
+
+func ExampleParse_error() {
+	_, err := jsonparser.Parse(`{"name": "Ada",}`)
+	var tokenErr *jsonparser.UnexpectedTokenError
+	fmt.Println(errors.As(err, &tokenErr), errors.Is(err, jsonparser.ErrSyntax))
+	fmt.Println(jsonparser.ErrorCodeOf(err))
+	// Output:
+	// true true
+	// E_TRAILING_COMMA
+}
+
+func ExampleValid() {
+	fmt.Println(jsonparser.Valid([]byte(`[1, 2, 3]`)))
+	fmt.Println(jsonparser.Valid([]byte(`[1, 2,`)))
+	// Output:
+	// true
+	// false
+}
+
+func ExampleMustParse() {
+	defaults := jsonparser.MustParse(`{"port": 8080}`)
+	port, _ := jsonparser.Lookup(defaults, "/port")
+	fmt.Println(port)
+	// Output: 8080
+}
+
+func ExampleMarshal() {
+	data, err := jsonparser.Marshal(struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags,omitempty"`
+	}{Name: "Ada"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+	// Output: {"name":"Ada"}
+}
+
+func ExampleUnmarshal() {
+	var config struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+	if err := jsonparser.Unmarshal([]byte(`{"name": "api", "port": 8080}`), &config); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(config.Name, config.Port)
+	// Output: api 8080
+}
+
+func ExampleNewDecoder() {
+	dec := jsonparser.NewDecoder(strings.NewReader(`{"id": 1} {"id": 2}`))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if tok.Type == jsonparser.TokenNumber {
+			fmt.Println("id", tok.Literal)
+		}
+	}
+	// Output:
+	// id 1
+	// id 2
+}
+
+func ExampleNewLineDecoder() {
+	dec := jsonparser.NewLineDecoder(strings.NewReader("{\"level\": \"info\"}\n{\"level\": \"error\"}\n"))
+	for {
+		v, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		level, _ := jsonparser.Lookup(v, "/level")
+		fmt.Println(level.(*jsonparser.String).Value)
+	}
+	// Output:
+	// info
+	// error
+}
+
+func ExampleQuery() {
+	doc := jsonparser.MustParse(`{"items": [{"sku": "a", "qty": 1}, {"sku": "b", "qty": 3}]}`)
+	found, err := jsonparser.Query(doc, "$.items[?@.qty > 1].sku")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range found {
+		fmt.Println(c.Pointer, c.Value)
+	}
+	// Output: /items/1/sku "b"
+}
+
+func ExampleCompile() {
+	skus := jsonparser.MustCompile("$.items[*].sku")
+	for _, input := range []string{`{"items": [{"sku": "a"}]}`, `{"items": [{"sku": "b"}, {"sku": "c"}]}`} {
+		fmt.Println(skus.Query(jsonparser.MustParse(input)).Values())
+	}
+	// Output:
+	// ["a"]
+	// ["b" "c"]
+}
+
+func ExampleFormatOptions_Format() {
+	err := jsonparser.FormatOptions{Indent: "  "}.Format(os.Stdout, strings.NewReader(`{"a":[1,2],"b":{}}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// {
+	//   "a": [
+	//     1,
+	//     2
+	//   ],
+	//   "b": {}
+	// }
+}
+
+func ExampleMarshalOptions_canonical() {
+	data, err := jsonparser.MarshalOptions{Canonical: true}.Marshal(jsonparser.MustParse(`{"b": 1.50, "a": 1e2}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+	// Output: {"a":100,"b":1.5}
+}
+
+func ExampleWithJSON5() {
+	value, err := jsonparser.Parse(`{name: 'app', port: 0x1F90, ratio: .5,}`, jsonparser.WithJSON5(true))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(value)
+	// Output: {"name":"app","port":8080,"ratio":0.5}
+}
@@ -0,0 +1,30 @@
+// Command format pretty-prints the JSON read from standard input, streaming
+// it so documents of any size can be formatted:
+//
+//	format -indent '    ' < compact.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("format", flag.ContinueOnError)
+	indent := flags.String("indent", "  ", "the indentation for each level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return jsonparser.FormatOptions{Indent: *indent}.Format(stdout, stdin)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args    []string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{nil, `{"a":[1,true]}`, "{\n  \"a\": [\n    1,\n    true\n  ]\n}\n", false},
+		{[]string{"-indent", "\t"}, `{"a":{}}`, "{\n\t\"a\": {}\n}\n", false},
+		{nil, `{"a":`, "", true},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := run(tt.args, strings.NewReader(tt.input), &out)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("run(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if !tt.wantErr && out.String() != tt.want {
+			t.Errorf("run(%q) wrote %q, want %q", tt.input, out.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Command patch applies a JSON Patch (RFC 6902) to the document read from
+// standard input and prints the result:
+//
+//	patch changes.json < config.json
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/patch"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("usage: patch patch.json < document.json")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	patchDoc, err := jsonparser.ParseBytes(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	p, err := patch.Parse(patchDoc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	data, err = io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonparser.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	result, err := p.Apply(doc)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, result)
+	return err
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	patchFile := filepath.Join(t.TempDir(), "patch.json")
+	err := os.WriteFile(patchFile, []byte(`[
+		{"op": "test", "path": "/version", "value": 1},
+		{"op": "replace", "path": "/version", "value": 2},
+		{"op": "add", "path": "/features/-", "value": "search"}
+	]`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{patchFile}, strings.NewReader(`{"version": 1, "features": []}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"version":2,"features":["search"]}` + "\n"; out.String() != want {
+		t.Errorf("run() wrote %q, want %q", out.String(), want)
+	}
+
+	// The test operation fails on a document already at version 2
+	out.Reset()
+	if err := run([]string{patchFile}, strings.NewReader(`{"version": 2, "features": []}`), &out); err == nil {
+		t.Errorf("run() succeeded with %q, want the test operation to fail", out.String())
+	}
+}
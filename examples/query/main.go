@@ -0,0 +1,45 @@
+// Command query prints the values a JSONPath expression selects from the
+// document read from standard input, each after its JSON Pointer:
+//
+//	query '$.items[?@.qty > 1].sku' < order.json
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("usage: query <jsonpath> < document.json")
+	}
+	path, err := jsonparser.Compile(args[0])
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonparser.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	for _, c := range path.Query(doc) {
+		if _, err := fmt.Fprintln(stdout, c.Pointer, c.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	input := `{"items": [{"sku": "a", "qty": 1}, {"sku": "b", "qty": 3}, {"sku": "c", "qty": 5}]}`
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"$.items[?@.qty > 1].sku", "/items/1/sku \"b\"\n/items/2/sku \"c\"\n", false},
+		{"$.items[0]", "/items/0 {\"sku\":\"a\",\"qty\":1}\n", false},
+		{"$.missing", "", false},
+		{"$.items[", "", true},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := run([]string{tt.path}, strings.NewReader(input), &out)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("run(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+		if out.String() != tt.want {
+			t.Errorf("run(%q) wrote %q, want %q", tt.path, out.String(), tt.want)
+		}
+	}
+}
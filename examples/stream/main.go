@@ -0,0 +1,68 @@
+// Command stream totals the "amount" members of the objects in a JSON
+// array read from standard input, one token at a time, so the array can be
+// far larger than memory:
+//
+//	stream < payments.json
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 0 {
+		return errors.New("usage: stream < payments.json")
+	}
+	dec := jsonparser.NewDecoder(stdin)
+	count, total := 0, 0.0
+	depth, key := 0, ""
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch tok.Type {
+		case jsonparser.TokenLeftBrace, jsonparser.TokenLeftBracket:
+			depth++
+			if depth == 2 {
+				count++
+			}
+		case jsonparser.TokenRightBrace, jsonparser.TokenRightBracket:
+			depth--
+		case jsonparser.TokenString:
+			if key == "" && depth == 2 {
+				key = tok.Literal // a key; the value follows
+				continue
+			}
+		case jsonparser.TokenNumber:
+			if depth == 2 && key == "amount" {
+				amount, err := strconv.ParseFloat(tok.Literal, 64)
+				if err != nil {
+					return err
+				}
+				total += amount
+			}
+		}
+		if depth <= 2 {
+			key = ""
+		}
+	}
+	_, err := fmt.Fprintf(stdout, "%d objects, total amount %g\n", count, total)
+	return err
+}
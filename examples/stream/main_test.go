@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	input := `[{"amount": 10.5, "note": "amount"}, {"meta": {"amount": 99}, "amount": 4}, {}]`
+	var out bytes.Buffer
+	if err := run(nil, strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "3 objects, total amount 14.5\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	if err := run(nil, strings.NewReader(`[{"amount": }]`), &out); err == nil {
+		t.Error("expected an error for malformed input")
+	}
+}
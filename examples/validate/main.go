@@ -0,0 +1,58 @@
+// Command validate checks the document read from standard input against a
+// JSON Schema, printing each violation:
+//
+//	validate schema.json < order.json
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/schema"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("usage: validate schema.json < document.json")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	schemaDoc, err := jsonparser.ParseBytes(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	s, err := schema.Compile(schemaDoc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	data, err = io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonparser.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	violations := s.Validate(doc)
+	for _, v := range violations {
+		fmt.Fprintln(stdout, v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d violations", len(violations))
+	}
+	_, err = fmt.Fprintln(stdout, "valid")
+	return err
+}
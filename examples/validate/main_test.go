@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	err := os.WriteFile(schemaFile, []byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {"id": {"type": "integer"}, "qty": {"type": "integer", "minimum": 1}}
+	}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"valid", `{"id": 7, "qty": 2}`, "valid\n", ""},
+		{"violations", `{"qty": 0}`, `"": missing required property "id" (schema /required)` + "\n" +
+			"/qty: must be at least 1 (schema /properties/qty/minimum)\n", "2 violations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run([]string{schemaFile}, strings.NewReader(tt.input), &out)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("run() wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
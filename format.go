@@ -0,0 +1,249 @@
+package jsonparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// FormatOptions controls the layout FormatOptions.Format writes
+type FormatOptions struct {
+	// Indent is repeated once per nesting level before every member and
+	// element; empty selects compact output
+	Indent string
+	// Partial closes the open arrays and objects when formatting fails, so
+	// the output written so far is still valid JSON
+	Partial bool
+	// BOM starts the output with a UTF-8 byte order mark. A mark at the
+	// start of the input is dropped either way.
+	BOM bool
+	// LineEnding is written at the end of every line, LF unless set
+	LineEnding LineEnding
+	// Checksum ends every value with a comment holding its Checksum, as in
+	// "} // sha256:...", for VerifyChecksumComments to check further along
+	// a pipeline. The output is then JSONC rather than JSON.
+	Checksum bool
+}
+
+// FormatError is returned by Format when the input is malformed or cannot be
+// read, or the output cannot be written. Offsets count bytes from the start
+// of the stream.
+type FormatError struct {
+	// Consumed is the input offset just past the last token in the output
+	Consumed int64
+	// Checkpoint is the input offset just past the last complete top-level
+	// value, where formatting can resume
+	Checkpoint int64
+	// Written is the number of output bytes for the values before Checkpoint
+	Written int64
+	Err     error
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%v (input consumed to byte %d)", e.Err, e.Consumed)
+}
+
+// Unwrap returns the underlying decode, read or write error
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// Format reads a stream of JSON values from r and writes each of them to w,
+// laid out as the options say and followed by a newline. Output is buffered
+// and flushed when Format returns, including when it fails.
+func (o FormatOptions) Format(w io.Writer, r io.Reader) error {
+	in := bufio.NewReader(r)
+	skipped := skipBOM(in) // the decoder never sees the input's byte order mark
+	dec := NewDecoder(in)
+	f := &formatter{opts: o, out: bufio.NewWriter(w), eol: o.LineEnding.String()}
+	var sum *valueHash
+	if o.Checksum {
+		sum = newValueHash()
+	}
+	f.consumed, f.checkpoint = skipped, skipped
+	if o.BOM {
+		f.write(utf8BOM)
+		f.checkpointWritten = f.written
+	}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return f.fail(err)
+		}
+		if f.token(tok) {
+			f.consumed = skipped + dec.InputOffset()
+		}
+		if sum != nil && sum.token(tok) {
+			f.write(" // " + sum.sum())
+			sum.reset()
+		}
+		if len(f.levels) == 0 {
+			f.write(f.eol)
+			f.checkpoint, f.checkpointWritten = f.consumed, f.written
+		}
+		if f.err != nil {
+			return f.fail(f.err)
+		}
+	}
+	if err := f.out.Flush(); err != nil {
+		return f.fail(err)
+	}
+	return nil
+}
+
+// skipBOM skips a byte order mark at the start of in, returning its length
+func skipBOM(in *bufio.Reader) int64 {
+	if mark, _ := in.Peek(len(utf8BOM)); string(mark) == utf8BOM {
+		in.Discard(len(utf8BOM))
+		return int64(len(utf8BOM))
+	}
+	return 0
+}
+
+// FormatValue writes node laid out as the options say and followed by a
+// newline, as Format writes the same JSON text. With an Indent, the
+// comments kept on the nodes by WithKeepComments are written back where they
+// were found, so a JSONC file can be edited and saved without losing them;
+// compact output has no lines to put them on and drops them.
+func (o FormatOptions) FormatValue(w io.Writer, node Value) error {
+	var data []byte
+	if o.BOM {
+		data = append(data, utf8BOM...)
+	}
+	var err error
+	if o.Indent == "" {
+		data, err = ast.AppendJSON(data, node)
+	} else {
+		data, err = ast.AppendIndent(data, node, o.Indent, o.LineEnding.String())
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, o.LineEnding.String()...))
+	return err
+}
+
+// formatLevel is an array or object whose output is still open
+type formatLevel struct {
+	object  bool
+	members int
+}
+
+// formatter writes the tokens of a Decoder. Keys and separators are only
+// written once the value after them starts, so closing the open levels
+// always leaves valid JSON.
+type formatter struct {
+	opts              FormatOptions
+	out               *bufio.Writer
+	levels            []formatLevel
+	key               *string // key waiting for its value
+	buf               []byte  // scratch space for quoting strings
+	eol               string  // the line ending
+	written           int64   // output bytes so far
+	consumed          int64   // input offset just past the last token written
+	checkpoint        int64   // input offset just past the last complete top-level value
+	checkpointWritten int64   // output bytes at checkpoint
+	err               error   // first write error
+}
+
+// token writes tok, reporting false for a key that waits for its value
+func (f *formatter) token(tok Token) bool {
+	if n := len(f.levels); n > 0 && f.levels[n-1].object && f.key == nil && tok.Type == TokenString {
+		key := tok.Literal
+		f.key = &key
+		return false
+	}
+
+	switch tok.Type {
+	case TokenRightBrace, TokenRightBracket:
+		f.close()
+		return true
+	}
+
+	f.separate()
+	switch tok.Type {
+	case TokenLeftBrace, TokenLeftBracket:
+		f.write(tok.Literal)
+		f.levels = append(f.levels, formatLevel{object: tok.Type == TokenLeftBrace})
+	case TokenString:
+		f.buf = ast.AppendQuoted(f.buf[:0], tok.Literal)
+		f.write(string(f.buf))
+	default:
+		f.write(tok.Literal)
+	}
+	return true
+}
+
+// separate writes what comes before a value: the comma after the previous
+// member, the indentation and any waiting key
+func (f *formatter) separate() {
+	n := len(f.levels)
+	if n == 0 {
+		return
+	}
+	level := &f.levels[n-1]
+	if level.members > 0 {
+		f.write(",")
+	}
+	level.members++
+	f.newline(n)
+	if f.key != nil {
+		f.buf = ast.AppendQuoted(f.buf[:0], *f.key)
+		f.write(string(f.buf))
+		f.write(":")
+		if f.opts.Indent != "" {
+			f.write(" ")
+		}
+		f.key = nil
+	}
+}
+
+// close ends the innermost open level
+func (f *formatter) close() {
+	level := f.levels[len(f.levels)-1]
+	f.levels = f.levels[:len(f.levels)-1]
+	if level.members > 0 {
+		f.newline(len(f.levels))
+	}
+	if level.object {
+		f.write("}")
+	} else {
+		f.write("]")
+	}
+}
+
+// newline starts a line indented depth levels, unless the output is compact
+func (f *formatter) newline(depth int) {
+	if f.opts.Indent != "" {
+		f.write(f.eol + strings.Repeat(f.opts.Indent, depth))
+	}
+}
+
+func (f *formatter) write(s string) {
+	if f.err != nil {
+		return
+	}
+	n, err := f.out.WriteString(s)
+	f.written += int64(n)
+	f.err = err
+}
+
+// fail flushes the output, closing the open levels first with Partial, and
+// reports err with the formatter's progress
+func (f *formatter) fail(err error) error {
+	if f.opts.Partial && f.err == nil && len(f.levels) > 0 {
+		f.key = nil
+		for len(f.levels) > 0 {
+			f.close()
+		}
+		f.write(f.eol)
+	}
+	f.out.Flush()
+	return &FormatError{Consumed: f.consumed, Checkpoint: f.checkpoint, Written: f.checkpointWritten, Err: err}
+}
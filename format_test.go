@@ -0,0 +1,136 @@
+package jsonparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatOptions_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     FormatOptions
+		input    string
+		expected string
+	}{
+		{"compact", FormatOptions{}, `{"a": [1, 2], "b": {}}  [true, "x\n"]`, "{\"a\":[1,2],\"b\":{}}\n[true,\"x\\n\"]\n"},
+		{"indented", FormatOptions{Indent: "  "}, `{"a":[1,{}],"b":"x"}`, "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": \"x\"\n}\n"},
+		{"scalars", FormatOptions{Indent: "  "}, "1 null\n\"s\"", "1\nnull\n\"s\"\n"},
+		{"empty", FormatOptions{}, "  ", ""},
+		{"crlf", FormatOptions{Indent: "\t", LineEnding: CRLF}, `{"a":[1]} 2`, "{\r\n\t\"a\": [\r\n\t\t1\r\n\t]\r\n}\r\n2\r\n"},
+		{"bom written", FormatOptions{BOM: true}, `[1, 2]`, "\xef\xbb\xbf[1,2]\n"},
+		{"bom stripped", FormatOptions{}, "\xef\xbb\xbf {\"a\": 1}", "{\"a\":1}\n"},
+		{"bom kept", FormatOptions{BOM: true, LineEnding: CRLF}, "\xef\xbb\xbf1 2", "\xef\xbb\xbf1\r\n2\r\n"},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := tt.opts.Format(&out, strings.NewReader(tt.input)); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, out.String())
+		}
+	}
+}
+
+func TestFormatOptions_FormatValue(t *testing.T) {
+	input := "// service\n{\n  \"port\": 8080, // default\n  \"hosts\": [\n    \"a\"\n    /* more later */\n  ]\n}\n"
+	value, err := Parse(input, WithKeepComments(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		opts     FormatOptions
+		expected string
+	}{
+		{"round trip", FormatOptions{Indent: "  "}, input},
+		{"crlf", FormatOptions{Indent: "\t", LineEnding: CRLF, BOM: true},
+			"\xef\xbb\xbf// service\r\n{\r\n\t\"port\": 8080, // default\r\n\t\"hosts\": [\r\n\t\t\"a\"\r\n\t\t/* more later */\r\n\t]\r\n}\r\n"},
+		{"compact", FormatOptions{}, "{\"port\":8080,\"hosts\":[\"a\"]}\n"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := tt.opts.FormatValue(&out, value); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, out.String())
+		}
+	}
+
+	if c := CommentsOf(MustParse(`[1]`)); c != nil {
+		t.Errorf("expected no comments without WithKeepComments, got %+v", c)
+	}
+}
+
+func TestFormatOptions_FormatError(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     FormatOptions
+		input    string
+		expected string
+		err      FormatError
+	}{
+		{"partial", FormatOptions{Partial: true}, `{"a": 1} {"b": [1, 2 x`, "{\"a\":1}\n{\"b\":[1,2]}\n", FormatError{Consumed: 20, Checkpoint: 8, Written: 8}},
+		{"waiting key dropped", FormatOptions{Partial: true}, `[{"a": 1, "b": `, "[{\"a\":1}]\n", FormatError{Consumed: 8}},
+		{"unclosed", FormatOptions{}, `[1, x`, "[1", FormatError{Consumed: 2}},
+		{"between values", FormatOptions{Partial: true}, `1 ]`, "1\n", FormatError{Consumed: 1, Checkpoint: 1, Written: 2}},
+		{"after boms", FormatOptions{BOM: true, LineEnding: CRLF}, "\xef\xbb\xbf1 ]", "\xef\xbb\xbf1\r\n", FormatError{Consumed: 4, Checkpoint: 4, Written: 6}},
+		{"bom before error", FormatOptions{BOM: true}, "\xef\xbb\xbf]", "\xef\xbb\xbf", FormatError{Consumed: 3, Checkpoint: 3, Written: 3}},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		err := tt.opts.Format(&out, strings.NewReader(tt.input))
+		var formatErr *FormatError
+		if !errors.As(err, &formatErr) {
+			t.Errorf("%s: expected a *FormatError, got %v", tt.name, err)
+			continue
+		}
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("%s: expected the cause to be a *DecodeError, got %v", tt.name, formatErr.Err)
+		}
+		got := *formatErr
+		got.Err = nil
+		if got != tt.err {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.err, got)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.name, tt.expected, out.String())
+		}
+	}
+}
+
+// failingWriter accepts limit bytes and then fails
+type failingWriter struct {
+	limit int
+}
+
+var errWriteFailed = errors.New("disk full")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errWriteFailed
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestFormatOptions_FormatWriteError(t *testing.T) {
+	input := strings.Repeat(`{"key": "value"} `, 1000)
+	err := FormatOptions{}.Format(&failingWriter{limit: 100}, strings.NewReader(input))
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) || !errors.Is(err, errWriteFailed) {
+		t.Fatalf("expected a *FormatError for the write, got %v", err)
+	}
+	if formatErr.Consumed >= int64(len(input)) {
+		t.Errorf("expected formatting to stop early, consumed %d bytes", formatErr.Consumed)
+	}
+}
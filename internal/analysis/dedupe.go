@@ -0,0 +1,253 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// DedupeOptions selects what a Deduper looks for
+type DedupeOptions struct {
+	Subtrees   bool    // Also report arrays and objects repeated inside or across documents
+	MinSize    int     // Smallest subtree, in bytes of compact JSON, worth reporting
+	Similarity float64 // Report document pairs at least this similar as near-duplicates; 0 disables
+}
+
+// Location names a document, or a subtree of one, in the corpus
+type Location struct {
+	Source string // Name the document was added under, such as its file name
+	Path   string // Path of the subtree, $ for the whole document
+}
+
+// DuplicateGroup lists the places a value occurs with the same canonical form:
+// the same content once object keys are sorted and whitespace is removed
+type DuplicateGroup struct {
+	Hash      string // Hex SHA-256 of the canonical form
+	Size      int    // Size of the value in bytes of compact JSON
+	Locations []Location
+}
+
+// NearDuplicate is a pair of documents that differ in only some of their values
+type NearDuplicate struct {
+	A, B       Location
+	Similarity float64 // Share of leaf paths with equal values, from 0 to 1
+}
+
+// DedupeReport is the result of deduplicating a corpus
+type DedupeReport struct {
+	Documents      int
+	Duplicates     []DuplicateGroup // Largest values first
+	NearDuplicates []NearDuplicate  // Most similar pairs first
+}
+
+// occurrence is one place a hashed value was seen
+type occurrence struct {
+	location Location
+	parent   string // hash of the enclosing value, empty for a document
+}
+
+// Deduper finds repeated documents and subtrees across a corpus
+type Deduper struct {
+	opts        DedupeOptions
+	documents   int
+	occurrences map[string][]occurrence
+	sizes       map[string]int
+	leaves      []leafSet // per document, only collected for near-duplicates
+}
+
+// leafSet holds a "path=value" entry for every scalar in a document
+type leafSet struct {
+	location Location
+	entries  map[string]bool
+}
+
+// NewDeduper initializes an empty Deduper
+func NewDeduper(opts DedupeOptions) *Deduper {
+	return &Deduper{
+		opts:        opts,
+		occurrences: make(map[string][]occurrence),
+		sizes:       make(map[string]int),
+	}
+}
+
+// Add hashes the document added under the given name
+func (d *Deduper) Add(source string, doc ast.Value) {
+	d.documents++
+	var leaves map[string]bool
+	if d.opts.Similarity > 0 {
+		leaves = make(map[string]bool)
+	}
+	sum, size := d.hash(source, "$", doc, leaves)
+	d.record(sum, size, occurrence{location: Location{Source: source, Path: "$"}})
+	if leaves != nil {
+		d.leaves = append(d.leaves, leafSet{location: Location{Source: source, Path: "$"}, entries: leaves})
+	}
+}
+
+// hash returns the canonical hash and compact size of value and, with
+// Subtrees, records the arrays and objects inside it. Objects hash their
+// members in key order, so the order they were written in does not matter.
+func (d *Deduper) hash(source, path string, value ast.Value, leaves map[string]bool) (string, int) {
+	h := sha256.New()
+	size := 0
+	var subtrees []subtree
+	add := func(path string, value ast.Value) {
+		sum, n := d.hash(source, path, value, leaves)
+		h.Write([]byte(sum))
+		size += n
+		if isContainer(value) {
+			subtrees = append(subtrees, subtree{sum: sum, size: n, occ: occurrence{location: Location{Source: source, Path: path}}})
+		}
+	}
+
+	switch v := value.(type) {
+	case *ast.Object:
+		keys := append([]string(nil), v.OrderedKeys()...)
+		sort.Strings(keys)
+		h.Write([]byte{'o'})
+		size = 2 + separators(len(keys))
+		for _, key := range keys {
+			writeString(h, key)
+			size += len(ast.AppendQuoted(nil, key)) + 1 // quoted key and colon
+			add(childPath(path, key), v.Pairs[key])
+		}
+	case *ast.Array:
+		h.Write([]byte{'a'})
+		size = 2 + separators(len(v.Elements))
+		for i, element := range v.Elements {
+			add(path+"["+strconv.Itoa(i)+"]", element)
+		}
+	default:
+		encoded, _ := ast.AppendJSON(nil, value)
+		h.Write([]byte{'s'})
+		h.Write(encoded)
+		size = len(encoded)
+		if leaves != nil {
+			leaves[path+"="+string(encoded)] = true
+		}
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if d.opts.Subtrees {
+		for _, t := range subtrees {
+			t.occ.parent = sum
+			d.record(t.sum, t.size, t.occ)
+		}
+	}
+	return sum, size
+}
+
+// subtree is an array or object found inside a value being hashed
+type subtree struct {
+	sum  string
+	size int
+	occ  occurrence
+}
+
+// isContainer checks if value is an array or object
+func isContainer(value ast.Value) bool {
+	switch value.(type) {
+	case *ast.Object, *ast.Array:
+		return true
+	}
+	return false
+}
+
+// separators counts the commas between n members
+func separators(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return n - 1
+}
+
+// writeString writes s with its length, so consecutive strings cannot run together
+func writeString(h io.Writer, s string) {
+	var n [binary.MaxVarintLen64]byte
+	h.Write(n[:binary.PutUvarint(n[:], uint64(len(s)))])
+	h.Write([]byte(s))
+}
+
+// record notes an occurrence of a hashed value, skipping small subtrees
+func (d *Deduper) record(sum string, size int, occ occurrence) {
+	if occ.parent != "" && size < d.opts.MinSize {
+		return
+	}
+	d.occurrences[sum] = append(d.occurrences[sum], occ)
+	d.sizes[sum] = size
+}
+
+// Report returns the duplicate groups and near-duplicate pairs found so far.
+// A repeated subtree is left out when every occurrence of it is explained
+// by a repeated parent already in the report.
+func (d *Deduper) Report() DedupeReport {
+	report := DedupeReport{Documents: d.documents}
+	for sum, occs := range d.occurrences {
+		if len(occs) < 2 || d.implied(occs) {
+			continue
+		}
+		group := DuplicateGroup{Hash: sum, Size: d.sizes[sum]}
+		for _, occ := range occs {
+			group.Locations = append(group.Locations, occ.location)
+		}
+		report.Duplicates = append(report.Duplicates, group)
+	}
+	sort.Slice(report.Duplicates, func(i, j int) bool {
+		a, b := report.Duplicates[i], report.Duplicates[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Hash < b.Hash
+	})
+
+	if d.opts.Similarity > 0 {
+		report.NearDuplicates = d.nearDuplicates()
+	}
+	return report
+}
+
+// implied reports whether the occurrences all sit in copies of one parent
+// that repeats exactly as often, so that the parent's group covers them
+func (d *Deduper) implied(occs []occurrence) bool {
+	parent := occs[0].parent
+	if parent == "" {
+		return false
+	}
+	for _, occ := range occs[1:] {
+		if occ.parent != parent {
+			return false
+		}
+	}
+	return len(d.occurrences[parent]) == len(occs)
+}
+
+// nearDuplicates compares every pair of documents by their leaf values
+func (d *Deduper) nearDuplicates() []NearDuplicate {
+	var pairs []NearDuplicate
+	for i := 0; i < len(d.leaves); i++ {
+		for j := i + 1; j < len(d.leaves); j++ {
+			a, b := d.leaves[i], d.leaves[j]
+			shared := 0
+			for entry := range a.entries {
+				if b.entries[entry] {
+					shared++
+				}
+			}
+			union := len(a.entries) + len(b.entries) - shared
+			if union == 0 || shared == union {
+				continue // exact duplicates are reported as a group
+			}
+			similarity := float64(shared) / float64(union)
+			if similarity >= d.opts.Similarity {
+				pairs = append(pairs, NearDuplicate{A: a.location, B: b.location, Similarity: similarity})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Similarity > pairs[j].Similarity })
+	return pairs
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import "testing"
+
+func TestDeduper_Documents(t *testing.T) {
+	d := NewDeduper(DedupeOptions{})
+	d.Add("a.json", mustParse(t, `{"name": "api", "port": 80}`))
+	d.Add("b.json", mustParse(t, `{"port": 80, "name": "api"}`))
+	d.Add("c.json", mustParse(t, `{"name": "api", "port": 81}`))
+
+	report := d.Report()
+	if report.Documents != 3 || len(report.Duplicates) != 1 {
+		t.Fatalf("expected one duplicate group over 3 documents, got %+v", report)
+	}
+	group := report.Duplicates[0]
+	if len(group.Locations) != 2 || group.Locations[0] != (Location{Source: "a.json", Path: "$"}) || group.Locations[1] != (Location{Source: "b.json", Path: "$"}) {
+		t.Errorf("unexpected locations %+v", group.Locations)
+	}
+	if group.Size != len(`{"name":"api","port":80}`) {
+		t.Errorf("expected the compact size, got %d", group.Size)
+	}
+	if report.NearDuplicates != nil {
+		t.Errorf("expected no near-duplicates without Similarity, got %+v", report.NearDuplicates)
+	}
+}
+
+func TestDeduper_Subtrees(t *testing.T) {
+	tls := `{"cert": "/etc/tls.pem", "key": "/etc/tls.key"}`
+	d := NewDeduper(DedupeOptions{Subtrees: true, MinSize: 10})
+	d.Add("a.json", mustParse(t, `{"tls": `+tls+`, "port": 80}`))
+	d.Add("b.json", mustParse(t, `{"servers": [{"tls": `+tls+`}, {"tls": `+tls+`}]}`))
+
+	report := d.Report()
+	var locations []Location
+	for _, group := range report.Duplicates {
+		locations = append(locations, group.Locations...)
+	}
+	expected := []Location{
+		{Source: "b.json", Path: "$.servers[0]"},
+		{Source: "b.json", Path: "$.servers[1]"},
+		{Source: "a.json", Path: "$.tls"},
+		{Source: "b.json", Path: "$.servers[0].tls"},
+		{Source: "b.json", Path: "$.servers[1].tls"},
+	}
+	if len(locations) != len(expected) {
+		t.Fatalf("expected %v, got %+v", expected, report.Duplicates)
+	}
+	for i := range expected {
+		if locations[i] != expected[i] {
+			t.Errorf("location %d: expected %v, got %v", i, expected[i], locations[i])
+		}
+	}
+}
+
+func TestDeduper_ImpliedSubtreesAreOmitted(t *testing.T) {
+	doc := `{"limits": {"cpu": "500m", "memory": "1Gi"}}`
+	d := NewDeduper(DedupeOptions{Subtrees: true})
+	d.Add("a.json", mustParse(t, doc))
+	d.Add("b.json", mustParse(t, doc))
+
+	report := d.Report()
+	if len(report.Duplicates) != 1 || report.Duplicates[0].Locations[0].Path != "$" {
+		t.Errorf("expected only the whole documents to be reported, got %+v", report.Duplicates)
+	}
+}
+
+func TestDeduper_NearDuplicates(t *testing.T) {
+	d := NewDeduper(DedupeOptions{Similarity: 0.5})
+	d.Add("a.json", mustParse(t, `{"name": "api", "port": 80, "replicas": 3, "region": "eu"}`))
+	d.Add("b.json", mustParse(t, `{"name": "api", "port": 81, "replicas": 3, "region": "eu"}`))
+	d.Add("c.json", mustParse(t, `{"name": "web", "port": 443}`))
+
+	report := d.Report()
+	if len(report.NearDuplicates) != 1 {
+		t.Fatalf("expected one near-duplicate pair, got %+v", report.NearDuplicates)
+	}
+	pair := report.NearDuplicates[0]
+	if pair.A.Source != "a.json" || pair.B.Source != "b.json" || pair.Similarity != 0.6 {
+		t.Errorf("unexpected pair %+v", pair)
+	}
+}
@@ -0,0 +1,165 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"unicode"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// PathStat aggregates how a single path shows up across a corpus
+type PathStat struct {
+	Path      string // Path with array indices collapsed, e.g. $.items[*].price
+	Count     int    // Total occurrences across all documents
+	Documents int    // Number of documents containing the path at least once
+	Size      int    // Approximate encoded size in bytes, summed over occurrences
+}
+
+// Report is the result of profiling a corpus of documents
+type Report struct {
+	Documents int
+	Paths     []PathStat
+}
+
+// Profiler collects path statistics over a corpus of parsed documents
+type Profiler struct {
+	documents int
+	stats     map[string]*PathStat
+}
+
+// NewProfiler initializes an empty Profiler
+func NewProfiler() *Profiler {
+	return &Profiler{stats: make(map[string]*PathStat)}
+}
+
+// Add records every path of the given document
+func (p *Profiler) Add(doc ast.Value) {
+	p.documents++
+	seen := make(map[string]bool)
+	p.walk("$", doc, seen)
+}
+
+// walk records the value at path and descends into its children. It returns
+// the value's encoded size, which it builds from the sizes of the children so
+// every value is measured once.
+func (p *Profiler) walk(path string, value ast.Value, seen map[string]bool) int {
+	size := 0
+	switch v := value.(type) {
+	case *ast.Object:
+		size = 2 // braces
+		first := true
+		for key, child := range v.Pairs {
+			if !first {
+				size++ // comma
+			}
+			first = false
+			size += len(key) + 3 // quotes and colon
+			size += p.walk(childPath(path, key), child, seen)
+		}
+	case *ast.Array:
+		size = 2 // brackets
+		for i, child := range v.Elements {
+			if i > 0 {
+				size++ // comma
+			}
+			size += p.walk(path+"[*]", child, seen)
+		}
+	default:
+		size = scalarSize(value)
+	}
+
+	stat, ok := p.stats[path]
+	if !ok {
+		stat = &PathStat{Path: path}
+		p.stats[path] = stat
+	}
+	stat.Count++
+	stat.Size += size
+	if !seen[path] {
+		seen[path] = true
+		stat.Documents++
+	}
+	return size
+}
+
+// Report returns the collected statistics, most common paths first
+func (p *Profiler) Report() Report {
+	report := Report{Documents: p.documents, Paths: make([]PathStat, 0, len(p.stats))}
+	for _, stat := range p.stats {
+		report.Paths = append(report.Paths, *stat)
+	}
+	sortPaths(report.Paths, func(a, b PathStat) bool { return a.Count > b.Count })
+	return report
+}
+
+// TopByCount returns up to n paths ordered by number of occurrences
+func (r Report) TopByCount(n int) []PathStat {
+	return r.top(n, func(a, b PathStat) bool { return a.Count > b.Count })
+}
+
+// TopBySize returns up to n paths ordered by the amount of data they carry
+func (r Report) TopBySize(n int) []PathStat {
+	return r.top(n, func(a, b PathStat) bool { return a.Size > b.Size })
+}
+
+// top sorts a copy of the paths and truncates it to n entries; n <= 0 keeps all of them
+func (r Report) top(n int, less func(a, b PathStat) bool) []PathStat {
+	paths := append([]PathStat(nil), r.Paths...)
+	sortPaths(paths, less)
+	if n > 0 && n < len(paths) {
+		paths = paths[:n]
+	}
+	return paths
+}
+
+// sortPaths orders paths by less, falling back to the path itself for a stable result
+func sortPaths(paths []PathStat, less func(a, b PathStat) bool) {
+	sort.Slice(paths, func(i, j int) bool {
+		if less(paths[i], paths[j]) {
+			return true
+		}
+		if less(paths[j], paths[i]) {
+			return false
+		}
+		return paths[i].Path < paths[j].Path
+	})
+}
+
+// childPath appends an object key to path, using bracket notation when the key is not an identifier
+func childPath(path, key string) string {
+	if isIdentifier(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// isIdentifier checks if key can be written in dot notation
+func isIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// scalarSize returns the number of bytes a scalar takes in compact JSON
+func scalarSize(value ast.Value) int {
+	switch v := value.(type) {
+	case *ast.String:
+		return len(v.Value) + 2
+	case *ast.Number:
+		return len(v.Value)
+	case *ast.Boolean:
+		return len(v.Value)
+	case *ast.Null:
+		return len("null")
+	default:
+		return 0
+	}
+}
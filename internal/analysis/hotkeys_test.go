@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func findStat(paths []PathStat, path string) (PathStat, bool) {
+	for _, stat := range paths {
+		if stat.Path == path {
+			return stat, true
+		}
+	}
+	return PathStat{}, false
+}
+
+func TestProfiler_CountsPathsAcrossDocuments(t *testing.T) {
+	p := NewProfiler()
+	p.Add(mustParse(t, `{"id": 1, "items": [{"price": 10}, {"price": 20}]}`))
+	p.Add(mustParse(t, `{"id": 2, "items": [], "note": "x y"}`))
+
+	report := p.Report()
+	if report.Documents != 2 {
+		t.Fatalf("expected 2 documents, got %d", report.Documents)
+	}
+
+	tests := []struct {
+		path      string
+		count     int
+		documents int
+	}{
+		{"$", 2, 2},
+		{"$.id", 2, 2},
+		{"$.items", 2, 2},
+		{"$.items[*]", 2, 1},
+		{"$.items[*].price", 2, 1},
+		{"$.note", 1, 1},
+	}
+	for _, tt := range tests {
+		stat, ok := findStat(report.Paths, tt.path)
+		if !ok {
+			t.Errorf("path %s missing from report", tt.path)
+			continue
+		}
+		if stat.Count != tt.count || stat.Documents != tt.documents {
+			t.Errorf("path %s: expected count %d in %d documents, got %d in %d", tt.path, tt.count, tt.documents, stat.Count, stat.Documents)
+		}
+	}
+}
+
+func TestProfiler_QuotesNonIdentifierKeys(t *testing.T) {
+	p := NewProfiler()
+	p.Add(mustParse(t, `{"inner key": {"a-b": true}}`))
+
+	if _, ok := findStat(p.Report().Paths, `$["inner key"]["a-b"]`); !ok {
+		t.Errorf("expected bracket notation for non-identifier keys, got %v", p.Report().Paths)
+	}
+}
+
+func TestReport_TopBySize(t *testing.T) {
+	p := NewProfiler()
+	p.Add(mustParse(t, `{"small": 1, "large": "a fairly long string value"}`))
+
+	top := p.Report().TopBySize(2)
+	if len(top) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(top))
+	}
+	if top[0].Path != "$" || top[1].Path != "$.large" {
+		t.Errorf("expected $ then $.large, got %s then %s", top[0].Path, top[1].Path)
+	}
+	if want := len(`{"small":1,"large":"a fairly long string value"}`); top[0].Size != want {
+		t.Errorf("expected root size %d, got %d", want, top[0].Size)
+	}
+}
+
+func TestProfiler_SizesNestedValues(t *testing.T) {
+	p := NewProfiler()
+	p.Add(mustParse(t, `{"a": {"b": [1, {"c": null}]}}`))
+
+	report := p.Report()
+	for path, text := range map[string]string{
+		"$":          `{"a":{"b":[1,{"c":null}]}}`,
+		"$.a":        `{"b":[1,{"c":null}]}`,
+		"$.a.b":      `[1,{"c":null}]`,
+		"$.a.b[*]":   `1{"c":null}`,
+		"$.a.b[*].c": `null`,
+	} {
+		stat, ok := findStat(report.Paths, path)
+		if !ok || stat.Size != len(text) {
+			t.Errorf("path %s: expected size %d, got %d", path, len(text), stat.Size)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package ast
+
+// At returns the element at index i, counting from the end when i is
+// negative, so At(-1) is the last element. It reports false when i is out
+// of range.
+func (a *Array) At(i int) (Value, bool) {
+	if i < 0 {
+		i += len(a.Elements)
+	}
+	if i < 0 || i >= len(a.Elements) {
+		return nil, false
+	}
+	return a.Elements[i], true
+}
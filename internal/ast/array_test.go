@@ -0,0 +1,28 @@
+package ast
+
+import "testing"
+
+func TestArray_At(t *testing.T) {
+	a := &Array{Elements: []Value{&Number{Value: "1"}, &Number{Value: "2"}, &Number{Value: "3"}}}
+	tests := []struct {
+		index int
+		want  string
+		ok    bool
+	}{
+		{0, "1", true},
+		{2, "3", true},
+		{-1, "3", true},
+		{-3, "1", true},
+		{3, "", false},
+		{-4, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := a.At(tt.index)
+		if ok != tt.ok || ok && got.(*Number).Value != tt.want {
+			t.Errorf("At(%d): expected %q, %t, got %v, %t", tt.index, tt.want, tt.ok, got, ok)
+		}
+	}
+	if _, ok := (&Array{}).At(-1); ok {
+		t.Error("expected no last element in an empty array")
+	}
+}
@@ -1,25 +1,83 @@
 package ast
 
+import "fmt"
+
 type Value interface{}
 
 type Object struct {
-	Pairs map[string]Value
+	Pairs    map[string]Value
+	Keys     []string // Order of the keys in Pairs, each listed once; see OrderedKeys
+	Span     Span
+	Comments *Comments // Comments around the node, kept by the parser on request
+	Parent   Value     // Enclosing array or object, set by Link
+
+	frozen bool // set by Freeze
 }
 
 type Array struct {
 	Elements []Value
+	Span     Span
+	Comments *Comments
+	Parent   Value
+
+	frozen bool // set by Freeze
 }
 
 type String struct {
-	Value string
+	Value    string
+	Span     Span
+	Comments *Comments
+	Parent   Value
 }
 
 type Number struct {
-	Value string
+	Value    string
+	Span     Span
+	Comments *Comments
+	Parent   Value
 }
 
 type Boolean struct {
-	Value string
+	Value    string
+	Span     Span
+	Comments *Comments
+	Parent   Value
+}
+
+type Null struct {
+	Span     Span
+	Comments *Comments
+	Parent   Value
+}
+
+// BadValue stands where the parser, recovering from a syntax error, skipped
+// input it could not parse as a value. Its span is always recorded.
+type BadValue struct {
+	Err      error // The error recovered from
+	Span     Span
+	Comments *Comments
+	Parent   Value
 }
 
-type Null struct{}
+// TypeName names the JSON type of v, as error messages and schemas spell it:
+// "object", "array", "string", "number", "boolean" or "null". A nil Value is
+// null, and anything else is named by its Go type.
+func TypeName(v Value) string {
+	switch v.(type) {
+	case *Object:
+		return "object"
+	case *Array:
+		return "array"
+	case *String:
+		return "string"
+	case *Number:
+		return "number"
+	case *Boolean:
+		return "boolean"
+	case *Null, nil:
+		return "null"
+	case *BadValue:
+		return "bad value"
+	}
+	return fmt.Sprintf("%T", v)
+}
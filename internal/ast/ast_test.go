@@ -0,0 +1,25 @@
+package ast
+
+import "testing"
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		value Value
+		want  string
+	}{
+		{&Object{}, "object"},
+		{&Array{}, "array"},
+		{&String{}, "string"},
+		{number("1"), "number"},
+		{&Boolean{Value: "true"}, "boolean"},
+		{&Null{}, "null"},
+		{nil, "null"},
+		{&BadValue{}, "bad value"},
+		{42, "int"},
+	}
+	for _, tt := range tests {
+		if got := TypeName(tt.value); got != tt.want {
+			t.Errorf("TypeName(%#v): expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
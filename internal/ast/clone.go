@@ -0,0 +1,121 @@
+package ast
+
+// Clone returns a deep copy of v, which shares nothing with v and can be
+// modified freely. The copy is the root of its own tree: its Parent is nil,
+// and the nodes below it are linked to their new parents wherever the
+// originals were linked. Values of types other than the AST nodes are
+// returned as is.
+func Clone(v Value) Value {
+	switch n := v.(type) {
+	case *Object:
+		return n.Clone()
+	case *Array:
+		return n.Clone()
+	case *String:
+		return n.Clone()
+	case *Number:
+		return n.Clone()
+	case *Boolean:
+		return n.Clone()
+	case *Null:
+		return n.Clone()
+	case *BadValue:
+		return n.Clone()
+	}
+	return v
+}
+
+// cloneChild copies a child of parent for clone, linking the copy to clone
+// when the child was linked to parent
+func cloneChild(child, parent, clone Value) Value {
+	copied := Clone(child)
+	if ParentOf(child) == parent {
+		setParent(copied, clone)
+	}
+	return copied
+}
+
+// Clone returns a deep copy of the object, keeping its key order
+func (o *Object) Clone() *Object {
+	if o == nil {
+		return nil
+	}
+	clone := &Object{Pairs: make(map[string]Value, len(o.Pairs)), Span: o.Span, Comments: o.Comments.clone()}
+	if o.Keys != nil {
+		clone.Keys = append(make([]string, 0, len(o.Keys)), o.Keys...)
+	}
+	for key, value := range o.Pairs {
+		clone.Pairs[key] = cloneChild(value, o, clone)
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the array
+func (a *Array) Clone() *Array {
+	if a == nil {
+		return nil
+	}
+	clone := &Array{Span: a.Span, Comments: a.Comments.clone()}
+	if a.Elements != nil {
+		clone.Elements = make([]Value, len(a.Elements))
+		for i, element := range a.Elements {
+			clone.Elements[i] = cloneChild(element, a, clone)
+		}
+	}
+	return clone
+}
+
+// Clone returns a copy of the string
+func (s *String) Clone() *String {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.Parent = nil
+	clone.Comments = s.Comments.clone()
+	return &clone
+}
+
+// Clone returns a copy of the number
+func (n *Number) Clone() *Number {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	clone.Parent = nil
+	clone.Comments = n.Comments.clone()
+	return &clone
+}
+
+// Clone returns a copy of the boolean
+func (b *Boolean) Clone() *Boolean {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	clone.Parent = nil
+	clone.Comments = b.Comments.clone()
+	return &clone
+}
+
+// Clone returns a copy of the null
+func (n *Null) Clone() *Null {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	clone.Parent = nil
+	clone.Comments = n.Comments.clone()
+	return &clone
+}
+
+// Clone returns a copy of the bad value, sharing its error
+func (b *BadValue) Clone() *BadValue {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	clone.Parent = nil
+	clone.Comments = b.Comments.clone()
+	return &clone
+}
@@ -0,0 +1,71 @@
+package ast
+
+import "testing"
+
+func TestClone(t *testing.T) {
+	template := object(
+		"name", &String{Value: "api"},
+		"ports", &Array{Elements: []Value{number("80")}},
+		"tls", object("enabled", &Boolean{Value: "false"}, "ca", &Null{}),
+	)
+	want := template.String()
+
+	for _, env := range []string{"dev", "prod"} {
+		doc := template.Clone()
+		if !Equal(doc, template) {
+			t.Fatalf("expected the clone to equal the template, got %s", doc)
+		}
+		if err := doc.SetPath("/name", &String{Value: env}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := doc.AppendPath("/ports", number("443")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := doc.DeletePath("/tls/ca"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		doc.Pairs["tls"].(*Object).Pairs["enabled"].(*Boolean).Value = "true"
+		doc.Set("region", &String{Value: "eu"})
+	}
+
+	if got := template.String(); got != want {
+		t.Errorf("editing clones changed the template: expected %s, got %s", want, got)
+	}
+}
+
+func TestClone_Values(t *testing.T) {
+	values := []Value{&String{Value: "x"}, number("1"), &Boolean{Value: "true"}, &Null{}, &Array{}, &Object{}, nil}
+	for _, v := range values {
+		clone := Clone(v)
+		if !Equal(clone, v) && v != nil {
+			t.Errorf("expected a copy of %#v, got %#v", v, clone)
+		}
+		if v != nil && clone == v {
+			t.Errorf("expected a new node for %#v", v)
+		}
+	}
+	if (*Object)(nil).Clone() != nil {
+		t.Errorf("expected a nil object to clone to nil")
+	}
+}
+
+func TestClone_Parents(t *testing.T) {
+	doc := config()
+	Link(doc)
+
+	ports := doc.Pairs["ports"].(*Array).Clone()
+	if ports.Parent != nil {
+		t.Errorf("expected the clone to be a root, got parent %v", ports.Parent)
+	}
+	if ParentOf(ports.Elements[0]) != Value(ports) {
+		t.Errorf("expected cloned elements to link to the clone")
+	}
+	if got := PathOf(ports.Elements[1]); got != "/1" {
+		t.Errorf("expected /1, got %q", got)
+	}
+
+	unlinked := config().Clone()
+	if ParentOf(unlinked.Pairs["name"]) != nil {
+		t.Errorf("expected clones of unlinked trees to stay unlinked")
+	}
+}
@@ -0,0 +1,173 @@
+package ast
+
+import "strings"
+
+// Comments are the comments around a node in the source text. Each is the
+// whole text of one comment, with its // or /* */. They are only kept when
+// the parser is asked to, and are nil for nodes built in code.
+type Comments struct {
+	Leading  []string // Before the node, on its own lines or earlier on the line it starts
+	Trailing []string // After the node on the line it ends, including past a comma
+	Dangling []string // Inside an array or object, after its last member
+}
+
+// CommentsOf returns the comments of v, or nil when it has none or is a
+// value of another type
+func CommentsOf(v Value) *Comments {
+	if c := commentsField(v); c != nil {
+		return *c
+	}
+	return nil
+}
+
+// SetComments records c on v; values of other types are left alone
+func SetComments(v Value, c *Comments) {
+	if field := commentsField(v); field != nil {
+		*field = c
+	}
+}
+
+// AddComments adds leading comments before those v already has and
+// trailing comments after them
+func AddComments(v Value, leading, trailing []string) {
+	field := commentsField(v)
+	if field == nil || len(leading) == 0 && len(trailing) == 0 {
+		return
+	}
+	if *field == nil {
+		*field = &Comments{}
+	}
+	c := *field
+	c.Leading = append(append([]string(nil), leading...), c.Leading...)
+	c.Trailing = append(c.Trailing, trailing...)
+}
+
+// commentsField returns where v keeps its comments, or nil for values of other types
+func commentsField(v Value) **Comments {
+	switch n := v.(type) {
+	case *Object:
+		return &n.Comments
+	case *Array:
+		return &n.Comments
+	case *String:
+		return &n.Comments
+	case *Number:
+		return &n.Comments
+	case *Boolean:
+		return &n.Comments
+	case *Null:
+		return &n.Comments
+	case *BadValue:
+		return &n.Comments
+	}
+	return nil
+}
+
+// clone returns a copy of c sharing nothing with it
+func (c *Comments) clone() *Comments {
+	if c == nil {
+		return nil
+	}
+	return &Comments{
+		Leading:  append([]string(nil), c.Leading...),
+		Trailing: append([]string(nil), c.Trailing...),
+		Dangling: append([]string(nil), c.Dangling...),
+	}
+}
+
+// AppendIndent appends the JSON text of v to dst with every member and
+// element on a line of its own, indented by one indent per level, and
+// newline ending each line but the last. The comments of the nodes are
+// written where the parser found them: leading comments on the lines
+// before a node, trailing ones after it, and dangling ones before the
+// closing bracket.
+func AppendIndent(dst []byte, v Value, indent, newline string) ([]byte, error) {
+	w := &indentWriter{indent: indent, newline: newline}
+	c := CommentsOf(v)
+	if c != nil {
+		for _, text := range c.Leading {
+			dst = append(append(dst, text...), newline...)
+		}
+	}
+	dst, err := w.value(dst, v, 0)
+	if err != nil || c == nil {
+		return dst, err
+	}
+	return w.trailing(dst, c), nil
+}
+
+// indentWriter holds the layout of AppendIndent
+type indentWriter struct {
+	indent, newline string
+}
+
+// line starts a new line indented to depth
+func (w *indentWriter) line(dst []byte, depth int) []byte {
+	dst = append(dst, w.newline...)
+	return append(dst, strings.Repeat(w.indent, depth)...)
+}
+
+// trailing appends the trailing comments of c on the current line
+func (w *indentWriter) trailing(dst []byte, c *Comments) []byte {
+	for _, text := range c.Trailing {
+		dst = append(append(dst, ' '), text...)
+	}
+	return dst
+}
+
+// value appends v, whose leading and trailing comments the caller writes,
+// at the given depth
+func (w *indentWriter) value(dst []byte, v Value, depth int) ([]byte, error) {
+	var keys []string
+	var children []Value
+	var open, close byte
+	switch n := v.(type) {
+	case *Object:
+		keys = n.OrderedKeys()
+		for _, key := range keys {
+			children = append(children, n.Pairs[key])
+		}
+		open, close = '{', '}'
+	case *Array:
+		children = n.Elements
+		open, close = '[', ']'
+	default:
+		return AppendJSON(dst, v)
+	}
+
+	var dangling []string
+	if c := CommentsOf(v); c != nil {
+		dangling = c.Dangling
+	}
+	if len(children) == 0 && len(dangling) == 0 {
+		return append(dst, open, close), nil
+	}
+	dst = append(dst, open)
+	var err error
+	for i, child := range children {
+		c := CommentsOf(child)
+		if c != nil {
+			for _, text := range c.Leading {
+				dst = append(w.line(dst, depth+1), text...)
+			}
+		}
+		dst = w.line(dst, depth+1)
+		if keys != nil {
+			dst = append(AppendQuoted(dst, keys[i]), ": "...)
+		}
+		if dst, err = w.value(dst, child, depth+1); err != nil {
+			return nil, err
+		}
+		if i < len(children)-1 {
+			dst = append(dst, ',')
+		}
+		if c != nil {
+			dst = w.trailing(dst, c)
+		}
+	}
+	for _, text := range dangling {
+		dst = append(w.line(dst, depth+1), text...)
+	}
+	dst = w.line(dst, depth)
+	return append(dst, close), nil
+}
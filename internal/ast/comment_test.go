@@ -0,0 +1,65 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendIndent(t *testing.T) {
+	commented := func(v Value, c *Comments) Value {
+		SetComments(v, c)
+		return v
+	}
+	tests := []struct {
+		name     string
+		value    Value
+		expected string
+	}{
+		{"scalar", &Number{Value: "1"}, "1"},
+		{"empty", &Object{Pairs: map[string]Value{}}, "{}"},
+		{"nested", &Object{Pairs: map[string]Value{"a": &Array{Elements: []Value{&Number{Value: "1"}, &Null{}}}, "b": &Array{}}, Keys: []string{"a", "b"}},
+			"{\n\t\"a\": [\n\t\t1,\n\t\tnull\n\t],\n\t\"b\": []\n}"},
+		{"root comments", commented(&Array{Elements: []Value{&Boolean{Value: "true"}}}, &Comments{Leading: []string{"// one", "/* two */"}, Trailing: []string{"// end"}}),
+			"// one\n/* two */\n[\n\ttrue\n] // end"},
+		{"member comments", &Object{Pairs: map[string]Value{
+			"a": commented(&Number{Value: "1"}, &Comments{Leading: []string{"// a"}, Trailing: []string{"/* x */", "// y"}}),
+			"b": commented(&String{Value: "s"}, &Comments{Trailing: []string{"// last"}}),
+		}, Keys: []string{"a", "b"}}, "{\n\t// a\n\t\"a\": 1, /* x */ // y\n\t\"b\": \"s\" // last\n}"},
+		{"dangling", commented(&Array{}, &Comments{Dangling: []string{"// none yet"}}), "[\n\t// none yet\n]"},
+	}
+
+	for _, tt := range tests {
+		got, err := AppendIndent(nil, tt.value, "\t", "\n")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := AppendIndent(nil, &Array{Elements: []Value{&BadValue{}}}, "\t", "\n"); err == nil {
+		t.Error("expected an error for a bad value")
+	}
+}
+
+func TestAddComments(t *testing.T) {
+	n := &Number{Value: "1"}
+	AddComments(n, []string{"// b"}, []string{"// c"})
+	AddComments(n, []string{"// a"}, []string{"// d"})
+	AddComments(n, nil, nil)
+	expected := &Comments{Leading: []string{"// a", "// b"}, Trailing: []string{"// c", "// d"}}
+	if !reflect.DeepEqual(n.Comments, expected) {
+		t.Errorf("expected %+v, got %+v", expected, n.Comments)
+	}
+
+	clone := n.Clone()
+	clone.Comments.Leading[0] = "// changed"
+	if n.Comments.Leading[0] != "// a" {
+		t.Error("Clone shares the comments of the original")
+	}
+	if CommentsOf(&Null{}) != nil || CommentsOf("other") != nil {
+		t.Error("expected no comments on nodes without them")
+	}
+}
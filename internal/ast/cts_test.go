@@ -0,0 +1,81 @@
+package ast
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// ctsFile is a JSONPath Compliance Test Suite file. The suite's own cts.json
+// runs in place of testdata/cts.json when JSONPATH_CTS names it.
+type ctsFile struct {
+	Tests []struct {
+		Name            string              `json:"name"`
+		Selector        string              `json:"selector"`
+		Document        json.RawMessage     `json:"document"`
+		Result          []json.RawMessage   `json:"result"`
+		Results         [][]json.RawMessage `json:"results"`
+		InvalidSelector bool                `json:"invalid_selector"`
+	} `json:"tests"`
+}
+
+func TestQuery_ComplianceSuite(t *testing.T) {
+	path := "testdata/cts.json"
+	if env := os.Getenv("JSONPATH_CTS"); env != "" {
+		path = env
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite ctsFile
+	if err := json.Unmarshal(data, &suite); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	same := EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}
+	for _, tc := range suite.Tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			doc := Value(&Null{})
+			if tc.Document != nil {
+				doc = decode(t, string(tc.Document))
+			}
+			results, err := Query(doc, tc.Selector)
+			if tc.InvalidSelector {
+				if err == nil {
+					t.Fatalf("%s: expected an error", tc.Selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.Selector, err)
+			}
+
+			// Results lists every order the RFC allows, Result the only one
+			alternatives := tc.Results
+			if alternatives == nil {
+				alternatives = [][]json.RawMessage{tc.Result}
+			}
+			for _, want := range alternatives {
+				if len(want) != len(results) {
+					continue
+				}
+				matched := true
+				for i, w := range want {
+					if !same.Equal(decode(t, string(w)), results[i].Value) {
+						matched = false
+						break
+					}
+				}
+				if matched {
+					return
+				}
+			}
+			got := make([]string, len(results))
+			for i, r := range results {
+				got[i] = r.Pointer
+			}
+			t.Errorf("%s: got %q, expected one of %s", tc.Selector, got, alternatives)
+		})
+	}
+}
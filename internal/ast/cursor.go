@@ -0,0 +1,122 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Cursor is a value found in a document together with where it was found.
+// Set and Delete edit the document through the cursor, so a query result can
+// be changed in place.
+type Cursor struct {
+	Value   Value
+	Pointer string // JSON Pointer from the document root, "" for the root
+	Span    Span   // Source range of Value, valid when parsed with spans
+	Parent  Value  // Array or object holding Value, nil for the root
+	key     string // member name or index in Parent
+}
+
+// Find returns a cursor for every value in the tree rooted at root, root
+// included, for which match reports true, in depth-first document order
+func Find(root Value, match func(Value) bool) Cursors {
+	var found Cursors
+	var find func(c Cursor, segments []string)
+	find = func(c Cursor, segments []string) {
+		if match(c.Value) {
+			c.Pointer = FormatPointer(segments)
+			c.Span = SpanOf(c.Value)
+			found = append(found, c)
+		}
+		switch v := c.Value.(type) {
+		case *Object:
+			for _, key := range v.OrderedKeys() {
+				find(Cursor{Value: v.Pairs[key], Parent: v, key: key}, append(segments, key))
+			}
+		case *Array:
+			for i, element := range v.Elements {
+				key := strconv.Itoa(i)
+				find(Cursor{Value: element, Parent: v, key: key}, append(segments, key))
+			}
+		}
+	}
+	find(Cursor{Value: root}, nil)
+	return found
+}
+
+// CursorAt returns a cursor for the value at the JSON Pointer path in root
+func CursorAt(root Value, path string) (Cursor, error) {
+	segments, err := SplitPointer(path)
+	if err != nil {
+		return Cursor{}, err
+	}
+	c := Cursor{Value: root}
+	for i, segment := range segments {
+		next, err := child(c.Value, segment)
+		if err != nil {
+			return Cursor{}, fmt.Errorf("path %q: %v at %q", path, err, FormatPointer(segments[:i+1]))
+		}
+		c = Cursor{Value: next, Parent: c.Value, key: segment}
+	}
+	c.Pointer = FormatPointer(segments)
+	c.Span = SpanOf(c.Value)
+	return c, nil
+}
+
+// Set replaces the value under the cursor with v, which becomes the
+// cursor's Value. The root cannot be replaced in place. When the old value
+// was linked to its parent, v is linked in its stead.
+func (c *Cursor) Set(v Value) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	switch p := c.Parent.(type) {
+	case *Object:
+		p.Pairs[c.key] = v
+	case *Array:
+		i, _ := strconv.Atoi(c.key)
+		p.Elements[i] = v
+	}
+	if ParentOf(c.Value) == c.Parent {
+		setParent(v, c.Parent)
+	}
+	c.Value = v
+	return nil
+}
+
+// Delete removes the value under the cursor from its parent. Removing an
+// array element moves the later ones, so cursors to them go stale; delete
+// them in reverse document order.
+func (c *Cursor) Delete() error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	switch p := c.Parent.(type) {
+	case *Object:
+		p.Delete(c.key)
+	case *Array:
+		i, _ := strconv.Atoi(c.key)
+		p.Elements = append(p.Elements[:i:i], p.Elements[i+1:]...)
+	}
+	return nil
+}
+
+// check reports an error unless the parent still holds the value under the cursor's key
+func (c *Cursor) check() error {
+	if IsFrozen(c.Parent) {
+		return fmt.Errorf("path %q: %w", c.Pointer, ErrFrozen)
+	}
+	switch p := c.Parent.(type) {
+	case nil:
+		return fmt.Errorf("path %q: cannot change the root value in place", c.Pointer)
+	case *Object:
+		if v, ok := p.Pairs[c.key]; ok && v == c.Value {
+			return nil
+		}
+	case *Array:
+		i, err := strconv.Atoi(c.key)
+		if err == nil && i < len(p.Elements) && p.Elements[i] == c.Value {
+			return nil
+		}
+	}
+	return fmt.Errorf("path %q: cursor is stale, the value has moved or been removed", c.Pointer)
+}
@@ -0,0 +1,104 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	doc := config()
+	ports := doc.Pairs["ports"].(*Array)
+	SetSpan(ports.Elements[1], Span{Start: Pos{Offset: 30, Line: 1, Column: 31}, End: Pos{Offset: 33, Line: 1, Column: 34}})
+
+	found := Find(doc, func(v Value) bool {
+		_, ok := v.(*Number)
+		return ok
+	})
+	if len(found) != 2 {
+		t.Fatalf("expected 2 numbers, got %d", len(found))
+	}
+	for i, want := range []string{"/ports/0", "/ports/1"} {
+		if found[i].Pointer != want || found[i].Parent != ports || found[i].Value != ports.Elements[i] {
+			t.Errorf("expected %s in the ports array, got %+v", want, found[i])
+		}
+	}
+	if found[1].Span.Start.Offset != 30 || found[0].Span.IsValid() {
+		t.Errorf("expected the recorded span only on the second port, got %+v and %+v", found[0].Span, found[1].Span)
+	}
+
+	all := Find(doc, func(Value) bool { return true })
+	if len(all) != 6 || all[0].Pointer != "" || all[0].Parent != nil || all[5].Pointer != "/a~1b" {
+		t.Errorf("expected the root first and the escaped key last, got %d results", len(all))
+	}
+}
+
+func TestCursor_Set(t *testing.T) {
+	doc := config()
+	Link(doc)
+	c, err := CursorAt(doc, "/ports/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replacement := number("8443")
+	if err := c.Set(replacement); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stringOf(doc); got != `{"name":"api","ports":[80,8443],"a/b":null}` {
+		t.Errorf("unexpected document %s", got)
+	}
+	if c.Value != replacement || PathOf(replacement) != "/ports/1" {
+		t.Errorf("expected the replacement under the cursor and linked, got path %q", PathOf(replacement))
+	}
+
+	name, _ := CursorAt(doc, "/name")
+	if err := name.Set(&String{Value: "web"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stringOf(doc); got != `{"name":"web","ports":[80,8443],"a/b":null}` {
+		t.Errorf("expected the member replaced in place, got %s", got)
+	}
+}
+
+func TestCursor_Delete(t *testing.T) {
+	doc := config()
+	found := Find(doc, func(v Value) bool {
+		_, ok := v.(*Number)
+		return ok
+	})
+	for i := len(found) - 1; i >= 0; i-- {
+		if err := found[i].Delete(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", found[i].Pointer, err)
+		}
+	}
+	key, _ := CursorAt(doc, "/a~1b")
+	if err := key.Delete(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stringOf(doc); got != `{"name":"api","ports":[]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+}
+
+func TestCursor_Errors(t *testing.T) {
+	doc := config()
+	if _, err := CursorAt(doc, "/ports/2"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error, got %v", err)
+	}
+
+	root, _ := CursorAt(doc, "")
+	if err := root.Set(&Null{}); err == nil || !strings.Contains(err.Error(), "root") {
+		t.Errorf("expected the root to be refused, got %v", err)
+	}
+
+	first, _ := CursorAt(doc, "/ports/0")
+	second, _ := CursorAt(doc, "/ports/1")
+	if err := first.Delete(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := second.Set(&Null{}); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("expected a stale cursor after the element moved, got %v", err)
+	}
+	if err := first.Delete(); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("expected a stale cursor after deleting its value, got %v", err)
+	}
+}
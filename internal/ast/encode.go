@@ -0,0 +1,243 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// AppendJSON appends the compact JSON text of v to dst. Object keys are
+// written in the order of OrderedKeys, number literals exactly as stored,
+// and a nil Value as null.
+func AppendJSON(dst []byte, v Value) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		dst = append(dst, "null"...)
+	case *Object:
+		dst = append(dst, '{')
+		for i, key := range v.OrderedKeys() {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = AppendQuoted(dst, key)
+			dst = append(dst, ':')
+			if dst, err = AppendJSON(dst, v.Pairs[key]); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, '}')
+	case *Array:
+		dst = append(dst, '[')
+		for i, element := range v.Elements {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = AppendJSON(dst, element); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+	case *String:
+		dst = AppendQuoted(dst, v.Value)
+	case *Number:
+		dst = append(dst, v.Value...)
+	case *Boolean:
+		dst = append(dst, v.Value...)
+	case *Null:
+		dst = append(dst, "null"...)
+	case *BadValue:
+		return nil, fmt.Errorf("cannot encode a value that failed to parse: %v", v.Err)
+	default:
+		return nil, fmt.Errorf("unsupported AST node %T", v)
+	}
+	return dst, nil
+}
+
+// AppendQuoted appends s as a quoted JSON string, replacing invalid UTF-8 with U+FFFD
+func AppendQuoted(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"':
+			dst = append(dst, `\"`...)
+		case r == '\\':
+			dst = append(dst, `\\`...)
+		case r == '\n':
+			dst = append(dst, `\n`...)
+		case r == '\r':
+			dst = append(dst, `\r`...)
+		case r == '\t':
+			dst = append(dst, `\t`...)
+		case r == '\b':
+			dst = append(dst, `\b`...)
+		case r == '\f':
+			dst = append(dst, `\f`...)
+		case r < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hex[r>>4], hex[r&0xf])
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, `\ufffd`...)
+		default:
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
+
+// writeChunk is how much text WriteTo builds before passing it to its writer
+const writeChunk = 32 << 10
+
+// WriteTo writes the compact JSON text of v to w as AppendJSON builds it,
+// in chunks of a few kilobytes, so the text of a large tree is never held
+// in memory whole. It returns the number of bytes written; when v holds a
+// node that cannot be written, the text before it may already be out.
+func WriteTo(w io.Writer, v Value) (int64, error) {
+	s := &streamWriter{w: w}
+	if err := s.value(v); err != nil {
+		return s.n, err
+	}
+	return s.n, s.flush()
+}
+
+// streamWriter holds the text of WriteTo not yet written
+type streamWriter struct {
+	w   io.Writer
+	buf []byte
+	n   int64 // bytes written so far
+}
+
+func (s *streamWriter) value(v Value) error {
+	switch v := v.(type) {
+	case *Object:
+		s.buf = append(s.buf, '{')
+		for i, key := range v.OrderedKeys() {
+			if i > 0 {
+				s.buf = append(s.buf, ',')
+			}
+			s.buf = append(AppendQuoted(s.buf, key), ':')
+			if err := s.value(v.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		s.buf = append(s.buf, '}')
+	case *Array:
+		s.buf = append(s.buf, '[')
+		for i, element := range v.Elements {
+			if i > 0 {
+				s.buf = append(s.buf, ',')
+			}
+			if err := s.value(element); err != nil {
+				return err
+			}
+		}
+		s.buf = append(s.buf, ']')
+	default:
+		data, err := AppendJSON(s.buf, v)
+		if err != nil {
+			return err
+		}
+		s.buf = data
+	}
+	if len(s.buf) >= writeChunk {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *streamWriter) flush() error {
+	n, err := s.w.Write(s.buf)
+	s.n += int64(n)
+	s.buf = s.buf[:0]
+	return err
+}
+
+// encode writes the JSON text of v to w
+func encode(w io.Writer, v Value) error {
+	_, err := WriteTo(w, v)
+	return err
+}
+
+// stringOf returns the JSON text of v, or a description of why it cannot be written
+func stringOf(v Value) string {
+	data, err := AppendJSON(nil, v)
+	if err != nil {
+		return fmt.Sprintf("<invalid JSON: %v>", err)
+	}
+	return string(data)
+}
+
+// MarshalJSON returns the compact JSON text of the object
+func (o *Object) MarshalJSON() ([]byte, error) { return AppendJSON(nil, o) }
+
+// Encode writes the compact JSON text of the object to w
+func (o *Object) Encode(w io.Writer) error { return encode(w, o) }
+
+// WriteTo writes the compact JSON text of the object to w, returning the number of bytes written
+func (o *Object) WriteTo(w io.Writer) (int64, error) { return WriteTo(w, o) }
+
+// String returns the compact JSON text of the object
+func (o *Object) String() string { return stringOf(o) }
+
+// MarshalJSON returns the compact JSON text of the array
+func (a *Array) MarshalJSON() ([]byte, error) { return AppendJSON(nil, a) }
+
+// Encode writes the compact JSON text of the array to w
+func (a *Array) Encode(w io.Writer) error { return encode(w, a) }
+
+// WriteTo writes the compact JSON text of the array to w, returning the number of bytes written
+func (a *Array) WriteTo(w io.Writer) (int64, error) { return WriteTo(w, a) }
+
+// String returns the compact JSON text of the array
+func (a *Array) String() string { return stringOf(a) }
+
+// MarshalJSON returns the string as a quoted JSON string
+func (s *String) MarshalJSON() ([]byte, error) { return AppendJSON(nil, s) }
+
+// Encode writes the string to w as a quoted JSON string
+func (s *String) Encode(w io.Writer) error { return encode(w, s) }
+
+// WriteTo writes the string as a quoted JSON string to w, returning the number of bytes written
+func (s *String) WriteTo(w io.Writer) (int64, error) { return WriteTo(w, s) }
+
+// String returns the string as a quoted JSON string
+func (s *String) String() string { return stringOf(s) }
+
+// MarshalJSON returns the number literal
+func (n *Number) MarshalJSON() ([]byte, error) { return AppendJSON(nil, n) }
+
+// Encode writes the number literal to w
+func (n *Number) Encode(w io.Writer) error { return encode(w, n) }
+
+// WriteTo writes the number literal to w, returning the number of bytes written
+func (n *Number) WriteTo(w io.Writer) (int64, error) { return WriteTo(w, n) }
+
+// String returns the number literal
+func (n *Number) String() string { return stringOf(n) }
+
+// MarshalJSON returns true or false
+func (b *Boolean) MarshalJSON() ([]byte, error) { return AppendJSON(nil, b) }
+
+// Encode writes true or false to w
+func (b *Boolean) Encode(w io.Writer) error { return encode(w, b) }
+
+// WriteTo writes true or false to w, returning the number of bytes written
+func (b *Boolean) WriteTo(w io.Writer) (int64, error) { return WriteTo(w, b) }
+
+// String returns true or false
+func (b *Boolean) String() string { return stringOf(b) }
+
+// MarshalJSON returns null
+func (n *Null) MarshalJSON() ([]byte, error) { return AppendJSON(nil, n) }
+
+// Encode writes null to w
+func (n *Null) Encode(w io.Writer) error { return encode(w, n) }
+
+// WriteTo writes null to w, returning the number of bytes written
+func (n *Null) WriteTo(w io.Writer) (int64, error) { return WriteTo(w, n) }
+
+// String returns null
+func (n *Null) String() string { return stringOf(n) }
@@ -0,0 +1,129 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAppendJSON(t *testing.T) {
+	doc := &Object{Pairs: map[string]Value{
+		"name":  &String{Value: "Jöhn \"J\"\n\x01"},
+		"big":   &Number{Value: "12345678901234567890"},
+		"tags":  &Array{Elements: []Value{&Boolean{Value: "true"}, &Null{}, nil}},
+		"empty": &Object{},
+		"list":  &Array{},
+	}}
+
+	got, err := AppendJSON([]byte("prefix:"), doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `prefix:{"big":12345678901234567890,"empty":{},"list":[],"name":"Jöhn \"J\"\n\u0001","tags":[true,null,null]}`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	if _, err := AppendJSON(nil, &Array{Elements: []Value{map[string]int{}}}); err == nil {
+		t.Errorf("expected an error for a value that is not an AST node")
+	}
+}
+
+func TestAppendQuoted_InvalidUTF8(t *testing.T) {
+	if got := string(AppendQuoted(nil, "a\xffb")); got != `"a\ufffdb"` {
+		t.Errorf("expected invalid UTF-8 to be replaced, got %s", got)
+	}
+}
+
+func TestNodes_SerializeAfterModification(t *testing.T) {
+	doc := &Object{Pairs: map[string]Value{"count": &Number{Value: "1"}}}
+	doc.Pairs["count"] = &Number{Value: "2"}
+	doc.Pairs["added"] = &Array{Elements: []Value{&String{Value: "x"}}}
+
+	want := `{"added":["x"],"count":2}`
+	if doc.String() != want {
+		t.Errorf("String: expected %s, got %s", want, doc.String())
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Encode(&buf); err != nil || buf.String() != want {
+		t.Errorf("Encode: expected %s, got %s (%v)", want, buf.String(), err)
+	}
+
+	// encoding/json uses MarshalJSON and compacts the result
+	data, err := json.Marshal(map[string]interface{}{"doc": doc, "n": &Null{}, "b": &Boolean{Value: "false"}})
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	if string(data) != `{"b":false,"doc":`+want+`,"n":null}` {
+		t.Errorf("json.Marshal: unexpected output %s", data)
+	}
+}
+
+// writeRecorder records the size of every write
+type writeRecorder struct {
+	bytes.Buffer
+	writes []int
+	err    error
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriteTo(t *testing.T) {
+	var _ io.WriterTo = (*Object)(nil)
+
+	array := &Array{}
+	for i := range 20000 {
+		array.Elements = append(array.Elements, &Object{Pairs: map[string]Value{"n": &Number{Value: strconv.Itoa(i)}}})
+	}
+	want, err := AppendJSON(nil, array)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &writeRecorder{}
+	n, err := array.WriteTo(w)
+	if err != nil || n != int64(len(want)) || !bytes.Equal(w.Bytes(), want) {
+		t.Fatalf("WriteTo() = %d, %v, want %d bytes equal to AppendJSON", n, err, len(want))
+	}
+	if len(w.writes) < 2 {
+		t.Errorf("expected the text to be written in chunks, got %d writes", len(w.writes))
+	}
+	for _, size := range w.writes {
+		if size > 2*writeChunk {
+			t.Errorf("expected chunks of about %d bytes, got one of %d", writeChunk, size)
+		}
+	}
+
+	failing := &writeRecorder{err: errors.New("broken pipe")}
+	if _, err := array.WriteTo(failing); err == nil || len(failing.writes) != 1 {
+		t.Errorf("expected WriteTo to stop at the first failed write, got %v after %d writes", err, len(failing.writes))
+	}
+	if n, err := (&Array{Elements: []Value{&String{Value: "x"}, 42}}).WriteTo(&bytes.Buffer{}); err == nil || n != 0 {
+		t.Errorf("expected an unsupported node to fail before anything is written, got %d, %v", n, err)
+	}
+}
+
+func TestNodes_StringReportsInvalidTrees(t *testing.T) {
+	arr := &Array{Elements: []Value{42}}
+	if s := arr.String(); !strings.Contains(s, "unsupported AST node int") {
+		t.Errorf("expected String to describe the invalid node, got %s", s)
+	}
+	if err := arr.Encode(&bytes.Buffer{}); err == nil {
+		t.Errorf("expected Encode to fail")
+	}
+
+	bad := &Array{Elements: []Value{&BadValue{Err: errors.New("invalid literal tru")}}}
+	if s := bad.String(); !strings.Contains(s, "failed to parse: invalid literal tru") {
+		t.Errorf("expected String to describe the bad value, got %s", s)
+	}
+}
@@ -0,0 +1,188 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EqualOptions relaxes the comparison made by Equal
+type EqualOptions struct {
+	IgnoreKeyOrder     bool // Objects with the same members in a different order are equal
+	IgnoreNumberFormat bool // Numbers are equal by value, so 1, 1.0 and 1e0 match
+}
+
+// Equal reports whether a and b are the same document: the same types, the
+// same object keys in the same order, and identical literals
+func Equal(a, b Value) bool {
+	return EqualOptions{}.Equal(a, b)
+}
+
+// Equal reports whether a and b are the same document under the options
+func (o EqualOptions) Equal(a, b Value) bool {
+	switch av := a.(type) {
+	case *Object:
+		bv, ok := b.(*Object)
+		if !ok || len(av.Pairs) != len(bv.Pairs) {
+			return false
+		}
+		if !o.IgnoreKeyOrder {
+			ak, bk := av.OrderedKeys(), bv.OrderedKeys()
+			for i := range ak {
+				if ak[i] != bk[i] {
+					return false
+				}
+			}
+		}
+		for key, value := range av.Pairs {
+			other, ok := bv.Pairs[key]
+			if !ok || !o.Equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case *Array:
+		bv, ok := b.(*Array)
+		if !ok || len(av.Elements) != len(bv.Elements) {
+			return false
+		}
+		for i := range av.Elements {
+			if !o.Equal(av.Elements[i], bv.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *String:
+		bv, ok := b.(*String)
+		return ok && av.Value == bv.Value
+	case *Number:
+		bv, ok := b.(*Number)
+		if !ok {
+			return false
+		}
+		if av.Value == bv.Value {
+			return true
+		}
+		if !o.IgnoreNumberFormat {
+			return false
+		}
+		ad, aok := decimalOf(av.Value)
+		bd, bok := decimalOf(bv.Value)
+		return aok && bok && ad == bd
+	case *Boolean:
+		bv, ok := b.(*Boolean)
+		return ok && av.Value == bv.Value
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	}
+	return false
+}
+
+// decimal is a number literal reduced to sign, significant digits and
+// exponent, so that equal values have equal decimals
+type decimal struct {
+	negative bool
+	digits   string // no leading or trailing zeros; empty for zero
+	exponent int
+}
+
+// decimalOf reduces a number literal exactly, without rounding it to a float
+func decimalOf(literal string) (decimal, bool) {
+	var d decimal
+	mantissa := literal
+	if i := strings.IndexAny(literal, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(strings.TrimPrefix(literal[i+1:], "+"))
+		if err != nil {
+			return decimal{}, false
+		}
+		d.exponent, mantissa = exp, literal[:i]
+	}
+	d.negative = strings.HasPrefix(mantissa, "-")
+	mantissa = strings.TrimPrefix(mantissa, "-")
+
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		d.exponent -= len(mantissa) - i - 1
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	digits := strings.TrimLeft(mantissa, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	d.exponent += len(digits) - len(trimmed)
+	d.digits = trimmed
+
+	if d.digits == "" {
+		return decimal{}, true // -0 and 0e5 are zero too
+	}
+	return d, true
+}
+
+// String spells d as Number.Canonical describes
+func (d decimal) String() string {
+	if d.digits == "" {
+		return "0"
+	}
+	var b strings.Builder
+	if d.negative {
+		b.WriteByte('-')
+	}
+	// point is where the decimal point falls after the first point digits
+	k, point := len(d.digits), len(d.digits)+d.exponent
+	switch {
+	case k <= point && point <= 21:
+		b.WriteString(d.digits)
+		b.WriteString(strings.Repeat("0", point-k))
+	case 0 < point && point <= 21:
+		b.WriteString(d.digits[:point])
+		b.WriteByte('.')
+		b.WriteString(d.digits[point:])
+	case -6 < point && point <= 0:
+		b.WriteString("0.")
+		b.WriteString(strings.Repeat("0", -point))
+		b.WriteString(d.digits)
+	default:
+		b.WriteString(d.digits[:1])
+		if k > 1 {
+			b.WriteByte('.')
+			b.WriteString(d.digits[1:])
+		}
+		b.WriteByte('e')
+		if point > 0 {
+			b.WriteByte('+')
+		}
+		b.WriteString(strconv.Itoa(point - 1))
+	}
+	return b.String()
+}
+
+// cmp orders decimals by value, returning -1, 0 or +1
+func (d decimal) cmp(e decimal) int {
+	sign := func(x decimal) int {
+		switch {
+		case x.digits == "":
+			return 0
+		case x.negative:
+			return -1
+		}
+		return 1
+	}
+	ds, es := sign(d), sign(e)
+	if ds != es || ds == 0 {
+		return compareInts(ds, es)
+	}
+
+	// Same sign: compare magnitudes as 0.digits times ten to the point
+	magnitude := compareInts(len(d.digits)+d.exponent, len(e.digits)+e.exponent)
+	if magnitude == 0 {
+		magnitude = strings.Compare(d.digits, e.digits)
+	}
+	return ds * magnitude
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
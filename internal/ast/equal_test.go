@@ -0,0 +1,58 @@
+package ast
+
+import "testing"
+
+// object builds an object with the given keys and values, in order
+func object(pairs ...interface{}) *Object {
+	obj := &Object{}
+	for i := 0; i < len(pairs); i += 2 {
+		obj.Set(pairs[i].(string), pairs[i+1].(Value))
+	}
+	return obj
+}
+
+func number(literal string) *Number {
+	return &Number{Value: literal}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Value
+		opts     EqualOptions
+		expected bool
+	}{
+		{"same object", object("a", number("1"), "b", &Null{}), object("a", number("1"), "b", &Null{}), EqualOptions{}, true},
+		{"key order", object("a", number("1"), "b", &Null{}), object("b", &Null{}, "a", number("1")), EqualOptions{}, false},
+		{"ignored key order", object("a", number("1"), "b", &Null{}), object("b", &Null{}, "a", number("1")), EqualOptions{IgnoreKeyOrder: true}, true},
+		{"missing key", object("a", number("1")), object("b", number("1")), EqualOptions{IgnoreKeyOrder: true}, false},
+		{"nested difference", &Array{Elements: []Value{object("a", &String{Value: "x"})}}, &Array{Elements: []Value{object("a", &String{Value: "y"})}}, EqualOptions{}, false},
+		{"array order", &Array{Elements: []Value{number("1"), number("2")}}, &Array{Elements: []Value{number("2"), number("1")}}, EqualOptions{IgnoreKeyOrder: true}, false},
+		{"types differ", &String{Value: "1"}, number("1"), EqualOptions{IgnoreNumberFormat: true}, false},
+		{"number format", number("1.0"), number("1"), EqualOptions{}, false},
+		{"ignored number format", number("1.0"), number("1"), EqualOptions{IgnoreNumberFormat: true}, true},
+		{"exponent", number("12.5e1"), number("125"), EqualOptions{IgnoreNumberFormat: true}, true},
+		{"negative exponent", number("0.0015"), number("15E-4"), EqualOptions{IgnoreNumberFormat: true}, true},
+		{"zeros", number("-0.0"), number("0e10"), EqualOptions{IgnoreNumberFormat: true}, true},
+		{"sign", number("-1"), number("1"), EqualOptions{IgnoreNumberFormat: true}, false},
+		{"beyond float64", number("9007199254740993"), number("9007199254740992"), EqualOptions{IgnoreNumberFormat: true}, false},
+		{"booleans", &Boolean{Value: "true"}, &Boolean{Value: "false"}, EqualOptions{}, false},
+		{"nulls", &Null{}, &Null{}, EqualOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.opts.Equal(tt.a, tt.b); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+		if got := tt.opts.Equal(tt.b, tt.a); got != tt.expected {
+			t.Errorf("%s reversed: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestEqual_IgnoresSpans(t *testing.T) {
+	a := &String{Value: "x", Span: Span{Start: Pos{Line: 1, Column: 1}}}
+	if !Equal(a, &String{Value: "x"}) {
+		t.Errorf("expected spans to be ignored")
+	}
+}
@@ -0,0 +1,565 @@
+package ast
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// logical is the test of a filter selector, given the document root and
+// the child being filtered, @
+type logical interface {
+	test(root, current Value) bool
+}
+
+// operand is a value compared in a filter or passed to a function. ok is
+// false for Nothing, the value of a query that selects no node.
+type operand interface {
+	value(root, current Value) (v Value, ok bool)
+}
+
+// orExpr is a || b || ...
+type orExpr []logical
+
+func (e orExpr) test(root, current Value) bool {
+	for _, term := range e {
+		if term.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// andExpr is a && b && ...
+type andExpr []logical
+
+func (e andExpr) test(root, current Value) bool {
+	for _, term := range e {
+		if !term.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+// notExpr is !x
+type notExpr struct{ x logical }
+
+func (e notExpr) test(root, current Value) bool {
+	return !e.x.test(root, current)
+}
+
+// existence is a query used as a test, true when it selects any node
+type existence struct{ q *query }
+
+func (e existence) test(root, current Value) bool {
+	return len(e.q.nodes(root, current)) > 0
+}
+
+// nodes runs a query within a filter from @ or from the root
+func (q *query) nodes(root, current Value) []node {
+	if q.relative {
+		return q.run(root, node{value: current}, nil)
+	}
+	return q.run(root, node{value: root}, nil)
+}
+
+// literal is a number, string, true, false or null written in a filter
+type literal struct{ v Value }
+
+func (l literal) value(root, current Value) (Value, bool) {
+	return l.v, true
+}
+
+// singularQuery is a query selecting at most one node, used as a value
+type singularQuery struct{ q *query }
+
+func (s singularQuery) value(root, current Value) (Value, bool) {
+	nodes := s.q.nodes(root, current)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0].value, true
+}
+
+// comparison is left op right for one of == != < <= > >=
+type comparison struct {
+	op          string
+	left, right operand
+}
+
+func (c *comparison) test(root, current Value) bool {
+	l, lok := c.left.value(root, current)
+	r, rok := c.right.value(root, current)
+	switch c.op {
+	case "==":
+		return equalOperands(l, lok, r, rok)
+	case "!=":
+		return !equalOperands(l, lok, r, rok)
+	case "<":
+		return lessOperands(l, lok, r, rok)
+	case "<=":
+		return lessOperands(l, lok, r, rok) || equalOperands(l, lok, r, rok)
+	case ">":
+		return lessOperands(r, rok, l, lok)
+	default: // ">="
+		return lessOperands(r, rok, l, lok) || equalOperands(l, lok, r, rok)
+	}
+}
+
+// equalOperands compares as RFC 9535 section 2.3.5.2.2 does: Nothing equals
+// only Nothing, numbers compare by value and objects ignore member order
+func equalOperands(a Value, aok bool, b Value, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	return EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}.Equal(a, b)
+}
+
+// lessOperands orders two numbers or two strings; anything else is unordered
+func lessOperands(a Value, aok bool, b Value, bok bool) bool {
+	if !aok || !bok {
+		return false
+	}
+	switch a := a.(type) {
+	case *Number:
+		b, ok := b.(*Number)
+		if !ok {
+			return false
+		}
+		ad, aok := decimalOf(a.Value)
+		bd, bok := decimalOf(b.Value)
+		return aok && bok && ad.cmp(bd) < 0
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value < b.Value
+	}
+	return false
+}
+
+// funcType is the declared type of a function parameter or result
+type funcType int
+
+const (
+	valueType   funcType = iota // A JSON value or Nothing
+	logicalType                 // True or false
+	nodesType                   // The nodes a query selects
+)
+
+// functions are the function extensions of RFC 9535 section 2.4
+var functions = map[string]struct {
+	params []funcType
+	result funcType
+}{
+	"length": {[]funcType{valueType}, valueType},
+	"count":  {[]funcType{nodesType}, valueType},
+	"match":  {[]funcType{valueType, valueType}, logicalType},
+	"search": {[]funcType{valueType, valueType}, logicalType},
+	"value":  {[]funcType{nodesType}, valueType},
+}
+
+// call is a function expression; each argument is an operand or, for a
+// nodesType parameter, a query
+type call struct {
+	name string
+	args []argument
+
+	// A literal match or search pattern is compiled with the query; re is
+	// nil when the pattern is not an I-Regexp
+	fixed bool
+	re    *regexp.Regexp
+}
+
+type argument struct {
+	value operand
+	nodes *query
+}
+
+func (c *call) value(root, current Value) (Value, bool) {
+	switch c.name {
+	case "length":
+		v, ok := c.args[0].value.value(root, current)
+		if !ok {
+			return nil, false
+		}
+		switch v := v.(type) {
+		case *String:
+			return &Number{Value: strconv.Itoa(utf8.RuneCountInString(v.Value))}, true
+		case *Array:
+			return &Number{Value: strconv.Itoa(len(v.Elements))}, true
+		case *Object:
+			return &Number{Value: strconv.Itoa(len(v.Pairs))}, true
+		}
+		return nil, false
+	case "count":
+		return &Number{Value: strconv.Itoa(len(c.args[0].nodes.nodes(root, current)))}, true
+	default: // "value"
+		nodes := c.args[0].nodes.nodes(root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].value, true
+	}
+}
+
+func (c *call) test(root, current Value) bool {
+	v, ok := c.args[0].value.value(root, current)
+	s, isString := v.(*String)
+	if !ok || !isString {
+		return false
+	}
+	re := c.re
+	if !c.fixed {
+		p, ok := c.args[1].value.value(root, current)
+		pattern, isString := p.(*String)
+		if !ok || !isString {
+			return false
+		}
+		re, _ = iregexps.compile(pattern.Value, c.name == "match")
+	}
+	return re != nil && re.MatchString(s.Value)
+}
+
+// maxCachedRegexps bounds the cache of patterns compiled for match and search
+const maxCachedRegexps = 256
+
+// regexpCache holds compiled match and search patterns, so a pattern is
+// compiled once however many nodes a filter tests
+type regexpCache struct {
+	mu       sync.Mutex
+	compiled map[regexpKey]*regexp.Regexp
+}
+
+type regexpKey struct {
+	pattern  string
+	anchored bool
+}
+
+var iregexps = &regexpCache{compiled: make(map[regexpKey]*regexp.Regexp)}
+
+// compile translates an I-Regexp (RFC 9485) pattern, anchored at both ends
+// for match, into package regexp syntax. A pattern outside I-Regexp is an
+// error, which match and search take as not matching. A full cache is
+// emptied rather than grown.
+func (c *regexpCache) compile(pattern string, anchored bool) (*regexp.Regexp, error) {
+	key := regexpKey{pattern, anchored}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if re, ok := c.compiled[key]; ok {
+		return re, nil
+	}
+	expr, err := translateIRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if anchored {
+		expr = `\A(?:` + expr + `)\z`
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.compiled) >= maxCachedRegexps {
+		c.compiled = make(map[regexpKey]*regexp.Regexp)
+	}
+	c.compiled[key] = re
+	return re, nil
+}
+
+// logicalOr parses a filter: terms joined by ||
+func (p *queryParser) logicalOr() (logical, error) {
+	var terms orExpr
+	for {
+		term, err := p.logicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		p.skipBlank()
+		if !strings.HasPrefix(p.path[p.pos:], "||") {
+			break
+		}
+		p.pos += 2
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// logicalAnd parses terms joined by &&
+func (p *queryParser) logicalAnd() (logical, error) {
+	var terms andExpr
+	for {
+		term, err := p.basic()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		p.skipBlank()
+		if !strings.HasPrefix(p.path[p.pos:], "&&") {
+			break
+		}
+		p.pos += 2
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// basic parses a parenthesized filter, a comparison or a test, the last
+// two optionally negated with !
+func (p *queryParser) basic() (logical, error) {
+	p.skipBlank()
+	switch p.peek() {
+	case '!':
+		p.pos++
+		p.skipBlank()
+		if p.peek() == '(' {
+			x, err := p.paren()
+			return notExpr{x}, err
+		}
+		a, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		x, err := p.asTest(a)
+		return notExpr{x}, err
+	case '(':
+		return p.paren()
+	}
+
+	left, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	op := p.comparisonOp()
+	if op == "" {
+		return p.asTest(left)
+	}
+	right, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	l, err := p.asOperand(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.asOperand(right)
+	if err != nil {
+		return nil, err
+	}
+	return &comparison{op: op, left: l, right: r}, nil
+}
+
+// paren parses ( filter ) with p.pos on the '('
+func (p *queryParser) paren() (logical, error) {
+	p.pos++
+	x, err := p.logicalOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.peek() != ')' {
+		return nil, p.unexpected("')'")
+	}
+	p.pos++
+	return x, nil
+}
+
+// comparisonOp reads one of the comparison operators, or returns ""
+func (p *queryParser) comparisonOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.path[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// unexpected reports the character at p.pos, or the end of the path, where
+// want was expected
+func (p *queryParser) unexpected(want string) error {
+	if p.pos >= len(p.path) {
+		return p.errorf("expected %s, got end of path", want)
+	}
+	return p.errorf("expected %s, got %q", want, p.path[p.pos])
+}
+
+// atom is a literal, a query or a function call in a filter, before it is
+// known whether it is compared or tested
+type atom struct {
+	lit  Value
+	q    *query
+	call *call
+	src  string // as written, for error messages
+}
+
+// atom parses a literal, a query starting with @ or $, or a function call
+func (p *queryParser) atom() (atom, error) {
+	p.skipBlank()
+	start := p.pos
+	var a atom
+	var err error
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		a.q, err = p.segments()
+		if err == nil {
+			a.q.relative = c == '@'
+		}
+	case c == '\'' || c == '"':
+		var s string
+		s, err = p.quoted()
+		a.lit = &String{Value: s}
+	case c == '-' || c >= '0' && c <= '9':
+		a.lit, err = p.number()
+	case c >= 'a' && c <= 'z':
+		for p.pos < len(p.path) && (p.path[p.pos] >= 'a' && p.path[p.pos] <= 'z' || p.path[p.pos] == '_' || p.path[p.pos] >= '0' && p.path[p.pos] <= '9') {
+			p.pos++
+		}
+		name := p.path[start:p.pos]
+		if p.peek() == '(' {
+			a.call, err = p.call(name)
+			break
+		}
+		switch name {
+		case "true", "false":
+			a.lit = &Boolean{Value: name}
+		case "null":
+			a.lit = &Null{}
+		default:
+			err = p.errorf("unknown name %q in filter", name)
+		}
+	default:
+		err = p.unexpected("a filter")
+	}
+	a.src = p.path[start:p.pos]
+	return a, err
+}
+
+// number reads a number literal in the JSON syntax, where -0 is allowed
+func (p *queryParser) number() (Value, error) {
+	start := p.pos
+	digits := func() int {
+		from := p.pos
+		for p.pos < len(p.path) && p.path[p.pos] >= '0' && p.path[p.pos] <= '9' {
+			p.pos++
+		}
+		return p.pos - from
+	}
+	if p.peek() == '-' {
+		p.pos++
+	}
+	valid := true
+	if p.peek() == '0' {
+		p.pos++
+	} else if digits() == 0 {
+		valid = false
+	}
+	if valid && p.peek() == '.' {
+		p.pos++
+		valid = digits() > 0
+	}
+	if valid && (p.peek() == 'e' || p.peek() == 'E') {
+		p.pos++
+		if p.peek() == '+' || p.peek() == '-' {
+			p.pos++
+		}
+		valid = digits() > 0
+	}
+	if c := p.peek(); !valid || c >= '0' && c <= '9' || c == '.' {
+		return nil, p.errorf("invalid number at %q", p.path[start:])
+	}
+	return &Number{Value: p.path[start:p.pos]}, nil
+}
+
+// call parses the arguments of a function and checks them against its
+// declared parameters, with p.pos on the '('
+func (p *queryParser) call(name string) (*call, error) {
+	fn, ok := functions[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	p.pos++
+	c := &call{name: name}
+	for i := 0; ; i++ {
+		p.skipBlank()
+		if i == 0 && p.peek() == ')' {
+			break
+		}
+		a, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		if i >= len(fn.params) {
+			return nil, p.errorf("too many arguments to %s()", name)
+		}
+		var arg argument
+		if fn.params[i] == nodesType {
+			if a.q == nil {
+				return nil, p.errorf("argument %d of %s() must be a query, got %s", i+1, name, a.src)
+			}
+			arg.nodes = a.q
+		} else if arg.value, err = p.asOperand(a); err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+
+		p.skipBlank()
+		if p.peek() == ')' {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, p.unexpected("',' or ')'")
+		}
+		p.pos++
+	}
+	p.pos++
+	if len(c.args) != len(fn.params) {
+		return nil, p.errorf("%s() takes %d arguments, got %d", name, len(fn.params), len(c.args))
+	}
+	if lit, ok := c.args[len(c.args)-1].value.(literal); ok && (name == "match" || name == "search") {
+		c.fixed = true
+		if pattern, ok := lit.v.(*String); ok {
+			c.re, _ = iregexps.compile(pattern.Value, name == "match")
+		}
+	}
+	return c, nil
+}
+
+// asOperand checks that a can be compared: a literal, a singular query or a
+// function returning a value
+func (p *queryParser) asOperand(a atom) (operand, error) {
+	switch {
+	case a.lit != nil:
+		return literal{a.lit}, nil
+	case a.q != nil:
+		if !a.q.singular() {
+			return nil, p.errorf("%s selects more than one value and cannot be compared", a.src)
+		}
+		return singularQuery{a.q}, nil
+	case functions[a.call.name].result != valueType:
+		return nil, p.errorf("%s does not return a value and cannot be compared", a.src)
+	}
+	return a.call, nil
+}
+
+// asTest checks that a can be a test: a query, true when it selects
+// anything, or a function returning true or false
+func (p *queryParser) asTest(a atom) (logical, error) {
+	switch {
+	case a.lit != nil:
+		return nil, p.errorf("literal %s is not a test", a.src)
+	case a.q != nil:
+		return existence{a.q}, nil
+	case functions[a.call.name].result != logicalType:
+		return nil, p.errorf("%s returns a value, not a test; compare it instead", a.src)
+	}
+	return a.call, nil
+}
@@ -0,0 +1,189 @@
+package ast
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decode builds a tree from JSON text, keeping member order
+func decode(t *testing.T, text string) Value {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var value func() Value
+	value = func() Value {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("decode %s: %v", text, err)
+		}
+		switch tok := tok.(type) {
+		case json.Delim:
+			if tok == '[' {
+				a := &Array{}
+				for dec.More() {
+					a.Elements = append(a.Elements, value())
+				}
+				dec.Token()
+				return a
+			}
+			o := &Object{Pairs: map[string]Value{}}
+			for dec.More() {
+				key, _ := dec.Token()
+				o.Set(key.(string), value())
+			}
+			dec.Token()
+			return o
+		case string:
+			return &String{Value: tok}
+		case json.Number:
+			return &Number{Value: tok.String()}
+		case bool:
+			if tok {
+				return &Boolean{Value: "true"}
+			}
+			return &Boolean{Value: "false"}
+		}
+		return &Null{}
+	}
+	return value()
+}
+
+// pointersOf runs a query and returns the pointers of its results
+func pointersOf(t *testing.T, doc Value, path string) []string {
+	t.Helper()
+	results, err := Query(doc, path)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", path, err)
+	}
+	pointers := []string{}
+	for _, r := range results {
+		pointers = append(pointers, r.Pointer)
+	}
+	return pointers
+}
+
+func TestQuery_Filters(t *testing.T) {
+	// The filter examples of RFC 9535 section 2.3.5.3
+	doc := decode(t, `{
+		"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}],
+		"o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}},
+		"e": "f"
+	}`)
+
+	tests := []struct {
+		path     string
+		pointers []string
+	}{
+		{`$.a[?@.b == 'kilo']`, []string{"/a/9"}},
+		{`$.a[?(@.b == 'kilo')]`, []string{"/a/9"}},
+		{`$.a[?@>3.5]`, []string{"/a/1", "/a/4", "/a/5"}},
+		{`$.a[?@.b]`, []string{"/a/6", "/a/7", "/a/8", "/a/9"}},
+		{`$[?@.*]`, []string{"/a", "/o"}},
+		{`$[?@[?@.b]]`, []string{"/a"}},
+		{`$.o[?@<3, ?@<3]`, []string{"/o/p", "/o/q", "/o/p", "/o/q"}},
+		{`$.a[?@<2 || @.b == "k"]`, []string{"/a/2", "/a/7"}},
+		{`$.a[?match(@.b, "[jk]")]`, []string{"/a/6", "/a/7"}},
+		{`$.a[?search(@.b, "[jk]")]`, []string{"/a/6", "/a/7", "/a/9"}},
+		{`$.o[?@>1 && @<4]`, []string{"/o/q", "/o/r"}},
+		{`$.o[?@.u || @.x]`, []string{"/o/t"}},
+		{`$.a[?@.b == $.x]`, []string{"/a/0", "/a/1", "/a/2", "/a/3", "/a/4", "/a/5"}},
+		{`$.a[?@ == @]`, []string{"/a/0", "/a/1", "/a/2", "/a/3", "/a/4", "/a/5", "/a/6", "/a/7", "/a/8", "/a/9"}},
+		{`$.a[?!@.b]`, []string{"/a/0", "/a/1", "/a/2", "/a/3", "/a/4", "/a/5"}},
+		{`$.a[?!(@ < 5 && @ > 1)][?@ == 'j']`, []string{"/a/6/b"}},
+		{`$.a[?length(@.b) == 4]`, []string{"/a/9"}},
+		{`$[?count(@.*) == 5]`, []string{"/o"}},
+		{`$.a[?value(@..b) == 'k']`, []string{"/a/7"}},
+		{`$.a[?@ >= 5 && @ != 6]`, []string{"/a/1"}},
+		{`$.a[?@ <= 1.0e0]`, []string{"/a/2"}},
+		{`$[?@ == 'f' || @ == null]`, []string{"/e"}},
+		{`$.a[?@.b > 'j']`, []string{"/a/7", "/a/9"}},
+		{`$.a[?@ == true]`, nil},
+	}
+	for _, tt := range tests {
+		want := tt.pointers
+		if want == nil {
+			want = []string{}
+		}
+		if got := pointersOf(t, doc, tt.path); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %q, got %q", tt.path, want, got)
+		}
+	}
+}
+
+func TestQuery_FilterRegexps(t *testing.T) {
+	doc := decode(t, `["a\rb", "a\nb", "axb", "ab", "^ab$", "a1b"]`)
+	tests := []struct {
+		path     string
+		pointers []string
+	}{
+		{`$[?match(@, "a.b")]`, []string{"/2", "/5"}},
+		{`$[?match(@, "a")]`, []string{}},
+		{`$[?search(@, "^a")]`, []string{"/4"}},
+		{`$[?search(@, "b$")]`, []string{"/4"}},
+		{`$[?match(@, "a\\p{Nd}b")]`, []string{"/5"}},
+		{`$[?match(@, "a[^x\\p{Nd}]b")]`, []string{"/0", "/1"}},
+		{`$[?search(@, "\\d")]`, []string{}},
+		{`$[?match(@, "[.]")]`, []string{}},
+		{`$[?match(@, "a(")]`, []string{}},
+		{`$[?match(@, 1)]`, []string{}},
+	}
+	for _, tt := range tests {
+		if got := pointersOf(t, doc, tt.path); !reflect.DeepEqual(got, tt.pointers) {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.pointers, got)
+		}
+	}
+}
+
+func TestQuery_FilterErrors(t *testing.T) {
+	tests := map[string]string{
+		`$[?@.a == @.*]`:              "cannot be compared",
+		`$[?@..a == 1]`:               "cannot be compared",
+		`$[?1]`:                       "is not a test",
+		`$[?length(@.a)]`:             "returns a value",
+		`$[?match(@.a, 'x') == true]`: "cannot be compared",
+		`$[?match(@.a)]`:              "takes 2 arguments",
+		`$[?length(@.a, @.b)]`:        "too many arguments",
+		`$[?foo(@)]`:                  "unknown function",
+		`$[?count(1) == 1]`:           "must be a query",
+		`$[?@.a == ]`:                 "expected a filter",
+		`$[?(@.a]`:                    "expected ')'",
+		`$[?@.a = 1]`:                 "unexpected '='",
+		`$[?@.a == 01]`:               "invalid number",
+		`$[?@.a == 1.]`:               "invalid number",
+		`$[?!@.a == 1]`:               "unexpected '='",
+		`$[?@.a == nil]`:              "unknown name",
+		`$[?@.a == 'x]`:               "unclosed '['",
+		`$[?@.a == {}]`:               "expected a filter",
+	}
+	for path, want := range tests {
+		if _, err := Query(&Null{}, path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", path, want, err)
+		}
+	}
+}
+
+func TestDecimal_Cmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "2", -1},
+		{"10", "9", 1},
+		{"1.5", "15e-1", 0},
+		{"-3", "2", -1},
+		{"-3", "-20", 1},
+		{"0", "-0.0", 0},
+		{"0.001", "0", 1},
+		{"1e3", "999.9", 1},
+		{"12", "123e-1", -1},
+	}
+	for _, tt := range tests {
+		a, _ := decimalOf(tt.a)
+		b, _ := decimalOf(tt.b)
+		if got := a.cmp(b); got != tt.want {
+			t.Errorf("%s vs %s: expected %d, got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package ast
+
+import "errors"
+
+// ErrFrozen is returned, or panicked with by Object.Set and Object.Delete,
+// when a frozen array or object is changed
+var ErrFrozen = errors.New("value is frozen")
+
+// Freeze makes v and every array and object below it read-only, so the tree
+// can be shared between goroutines without locking. Object.Set and
+// Object.Delete then panic with ErrFrozen, while SetPath, InsertPath,
+// AppendPath, DeletePath and the methods of Cursor return it. Link leaves
+// frozen values as they are, so link a tree before freezing it. Fields can
+// still be assigned directly; Freeze guards the methods only. Clone returns
+// a copy that can be changed.
+func Freeze(v Value) {
+	switch n := v.(type) {
+	case *Object:
+		n.frozen = true
+		for _, child := range n.Pairs {
+			Freeze(child)
+		}
+	case *Array:
+		n.frozen = true
+		for _, child := range n.Elements {
+			Freeze(child)
+		}
+	}
+}
+
+// IsFrozen reports whether v is an array or object made read-only by Freeze
+func IsFrozen(v Value) bool {
+	switch n := v.(type) {
+	case *Object:
+		return n.frozen
+	case *Array:
+		return n.frozen
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package ast
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	doc := config()
+	Freeze(doc)
+	ports := doc.Pairs["ports"].(*Array)
+	if !IsFrozen(doc) || !IsFrozen(ports) || IsFrozen(doc.Pairs["name"]) {
+		t.Fatalf("expected arrays and objects to be frozen")
+	}
+
+	edits := map[string]error{
+		"SetPath":     SetPath(doc, "/name", &Null{}),
+		"SetPath new": SetPath(doc, "/tls/cert", &Null{}),
+		"InsertPath":  InsertPath(doc, "/ports/0", &Null{}),
+		"AppendPath":  AppendPath(doc, "/ports", &Null{}),
+		"DeletePath":  DeletePath(doc, "/ports/1"),
+	}
+	c, _ := CursorAt(doc, "/ports/0")
+	edits["Cursor.Set"] = c.Set(&Null{})
+	edits["Cursor.Delete"] = c.Delete()
+	results, _ := Query(doc, "$..*")
+	edits["Cursors.Set"] = results.Set(&Null{})
+	edits["Cursors.Delete"] = results.Delete()
+	for name, err := range edits {
+		if !errors.Is(err, ErrFrozen) {
+			t.Errorf("%s: expected ErrFrozen, got %v", name, err)
+		}
+	}
+	if got := doc.String(); got != `{"name":"api","ports":[80,443],"a/b":null}` {
+		t.Errorf("expected the document to be unchanged, got %s", got)
+	}
+
+	for name, edit := range map[string]func(){
+		"Set":    func() { doc.Set("name", &Null{}) },
+		"Delete": func() { doc.Delete("name") },
+	} {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrFrozen) {
+					t.Errorf("%s: expected a panic with ErrFrozen, got %v", name, err)
+				}
+			}()
+			edit()
+		}()
+	}
+
+	clone := Clone(doc).(*Object)
+	if IsFrozen(clone) || SetPath(clone, "/ports/0", &Null{}) != nil {
+		t.Errorf("expected a clone to be changeable")
+	}
+}
+
+func TestFreeze_Concurrent(t *testing.T) {
+	doc := config()
+	Link(doc)
+	Freeze(doc)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Link(doc)
+			if results, err := Query(doc, "$.ports[*]"); err != nil || len(results) != 2 {
+				t.Errorf("expected two ports, got %v, %v", results, err)
+			}
+			if got := PathOf(doc.Pairs["ports"].(*Array).Elements[1]); got != "/ports/1" {
+				t.Errorf("expected the links to be kept, got %q", got)
+			}
+		}()
+	}
+	wg.Wait()
+}
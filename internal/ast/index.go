@@ -0,0 +1,79 @@
+package ast
+
+import "sync"
+
+// Index holds lookup tables for one document, built the first time a query
+// needs them, so that queries searching the whole document, such as
+// $..price, stop walking it after the first. The tables describe the
+// document as it was when they were built; call Reset after changing it.
+// An Index can be used by any number of goroutines at once.
+type Index struct {
+	root Value
+
+	mu      sync.Mutex
+	built   bool
+	nodes   []node            // every value, the root first, in the order descendant segments visit them
+	members map[string][]node // object members by name, in that same order
+}
+
+// NewIndex returns an index over the document at root. Nothing is built
+// until a query needs it.
+func NewIndex(root Value) *Index {
+	return &Index{root: root}
+}
+
+// Query runs a JSONPath expression against the indexed document, as the
+// Query function does
+func (ix *Index) Query(path string) (Cursors, error) {
+	q, err := queries.compile(path)
+	if err != nil {
+		return nil, err
+	}
+	return q.QueryIndex(ix), nil
+}
+
+// Reset drops the tables, to be built again from the document as it is now
+func (ix *Index) Reset() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.built, ix.nodes, ix.members = false, nil, nil
+}
+
+// tables returns the tables, building them on first use. They are never
+// changed once built, only replaced, so callers may read them unlocked.
+func (ix *Index) tables() ([]node, map[string][]node) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.built {
+		return ix.nodes, ix.members
+	}
+	ix.nodes = nil
+	ix.members = make(map[string][]node)
+	var visit func(n node)
+	visit = func(n node) {
+		ix.nodes = append(ix.nodes, n)
+		if o, ok := n.value.(*Object); ok {
+			for _, key := range o.OrderedKeys() {
+				ix.members[key] = append(ix.members[key], n.child(o.Pairs[key], key))
+			}
+		}
+		n.children(visit)
+	}
+	visit(node{value: ix.root})
+	ix.built = true
+	return ix.nodes, ix.members
+}
+
+// descendants appends what the descendant segment seg selects from the
+// root: a single name is looked up in one step, and other selectors are
+// applied to every value without walking the tree again
+func (ix *Index) descendants(seg segment, out []node) []node {
+	nodes, members := ix.tables()
+	if len(seg.selectors) == 1 && seg.selectors[0].kind == selectName {
+		return append(out, members[seg.selectors[0].name]...)
+	}
+	for _, n := range nodes {
+		out = seg.apply(ix.root, n, out)
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIndex_Query(t *testing.T) {
+	doc := decode(t, `{
+		"store": {
+			"book": [
+				{"title": "a", "price": 8, "tags": {"price": 1}},
+				{"title": "b", "price": 12}
+			],
+			"bicycle": {"price": 19, "title": "c"}
+		},
+		"price": 0
+	}`)
+	ix := NewIndex(doc)
+	paths := []string{
+		"$..price",
+		"$..['price','title']",
+		"$..*",
+		"$..[0]",
+		"$..book[?@.price > 10].title",
+		"$.store..price",
+		"$..book..price",
+		"$.store.book[*].title",
+		"$..missing",
+		"$[?$..price]",
+	}
+	for _, path := range paths {
+		for pass := 0; pass < 2; pass++ {
+			want, _ := Query(doc, path)
+			got, err := ix.Query(path)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", path, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: expected %v, got %v", path, want, got)
+			}
+		}
+	}
+
+	if _, err := ix.Query("$..["); err == nil || !strings.Contains(err.Error(), "unclosed '['") {
+		t.Errorf("expected an unclosed '[' error, got %v", err)
+	}
+}
+
+func TestIndex_Reset(t *testing.T) {
+	doc := decode(t, `{"a": {"id": 1}, "b": [{"id": 2}]}`)
+	ix := NewIndex(doc)
+	if results, _ := ix.Query("$..id"); len(results) != 2 {
+		t.Fatalf("expected two ids, got %v", results)
+	}
+
+	doc.(*Object).Set("c", decode(t, `{"id": 3}`))
+	results, _ := ix.Query("$..id")
+	if len(results) != 2 {
+		t.Errorf("expected the tables to describe the document before the change, got %d results", len(results))
+	}
+	ix.Reset()
+	results, _ = ix.Query("$..id")
+	var pointers []string
+	for _, r := range results {
+		pointers = append(pointers, r.Pointer)
+	}
+	if want := []string{"/a/id", "/b/0/id", "/c/id"}; !reflect.DeepEqual(pointers, want) {
+		t.Errorf("expected %q after Reset, got %q", want, pointers)
+	}
+}
+
+func TestIndex_Concurrent(t *testing.T) {
+	ix := NewIndex(benchmarkDoc())
+	q := MustCompile("$..currency")
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if got := len(q.QueryIndex(ix)); got != 1000 {
+					t.Errorf("expected 1000 results, got %d", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkQuery_Descendant(b *testing.B) {
+	doc := benchmarkDoc()
+	q := MustCompile("$..currency")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.Query(doc)
+	}
+}
+
+func BenchmarkIndex_Descendant(b *testing.B) {
+	ix := NewIndex(benchmarkDoc())
+	q := MustCompile("$..currency")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.QueryIndex(ix)
+	}
+}
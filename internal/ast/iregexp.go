@@ -0,0 +1,238 @@
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// iregexpCategories are the Unicode categories an I-Regexp \p{...} may name
+var iregexpCategories = map[string]bool{
+	"L": true, "Ll": true, "Lm": true, "Lo": true, "Lt": true, "Lu": true,
+	"M": true, "Mc": true, "Me": true, "Mn": true,
+	"N": true, "Nd": true, "Nl": true, "No": true,
+	"P": true, "Pc": true, "Pd": true, "Pe": true, "Pf": true, "Pi": true, "Po": true, "Ps": true,
+	"Z": true, "Zl": true, "Zp": true, "Zs": true,
+	"S": true, "Sc": true, "Sk": true, "Sm": true, "So": true,
+	"C": true, "Cc": true, "Cf": true, "Cn": true, "Co": true,
+}
+
+// iregexp translates an I-Regexp (RFC 9485) pattern into package regexp
+// syntax, rejecting anything outside the I-Regexp grammar
+type iregexp struct {
+	pattern string
+	pos     int
+	out     strings.Builder
+}
+
+// translateIRegexp returns pattern in package regexp syntax. I-Regexp has no
+// anchors, so ^ and $ stand for themselves, and . matches anything but \n
+// and \r.
+func translateIRegexp(pattern string) (string, error) {
+	t := &iregexp{pattern: pattern}
+	if !utf8.ValidString(pattern) {
+		return "", fmt.Errorf("invalid I-Regexp %q: invalid UTF-8", pattern)
+	}
+	if err := t.branches(); err != nil {
+		return "", err
+	}
+	if t.pos < len(t.pattern) {
+		return "", t.errorf("unexpected %q", t.pattern[t.pos])
+	}
+	return t.out.String(), nil
+}
+
+func (t *iregexp) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid I-Regexp %q at offset %d: %s", t.pattern, t.pos, fmt.Sprintf(format, args...))
+}
+
+func (t *iregexp) peek() rune {
+	if t.pos >= len(t.pattern) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(t.pattern[t.pos:])
+	return r
+}
+
+func (t *iregexp) next() rune {
+	r, size := utf8.DecodeRuneInString(t.pattern[t.pos:])
+	t.pos += size
+	return r
+}
+
+// branches translates branches joined by |
+func (t *iregexp) branches() error {
+	for {
+		for r := t.peek(); r != -1 && r != '|' && r != ')'; r = t.peek() {
+			if err := t.piece(); err != nil {
+				return err
+			}
+		}
+		if t.peek() != '|' {
+			return nil
+		}
+		t.out.WriteRune(t.next())
+	}
+}
+
+// piece translates an atom and its quantifier, if any
+func (t *iregexp) piece() error {
+	if err := t.atom(); err != nil {
+		return err
+	}
+	switch t.peek() {
+	case '*', '+', '?':
+		t.out.WriteRune(t.next())
+	case '{':
+		return t.quantity()
+	}
+	return nil
+}
+
+// quantity translates {n}, {n,} or {n,m}
+func (t *iregexp) quantity() error {
+	start := t.pos
+	t.next()
+	if !t.digits() {
+		return t.errorf("expected a number in a quantifier")
+	}
+	if t.peek() == ',' {
+		t.next()
+		t.digits()
+	}
+	if t.peek() != '}' {
+		return t.errorf("expected '}' to close a quantifier")
+	}
+	t.next()
+	t.out.WriteString(t.pattern[start:t.pos])
+	return nil
+}
+
+func (t *iregexp) digits() bool {
+	start := t.pos
+	for r := t.peek(); r >= '0' && r <= '9'; r = t.peek() {
+		t.next()
+	}
+	return t.pos > start
+}
+
+func (t *iregexp) atom() error {
+	switch r := t.peek(); r {
+	case '(':
+		t.next()
+		t.out.WriteString("(?:")
+		if err := t.branches(); err != nil {
+			return err
+		}
+		if t.peek() != ')' {
+			return t.errorf("expected ')'")
+		}
+		t.next()
+		t.out.WriteByte(')')
+	case '.':
+		t.next()
+		t.out.WriteString(`[^\n\r]`)
+	case '[':
+		return t.class()
+	case '\\':
+		return t.escape()
+	case ')', '*', '+', '?', ']', '{', '}':
+		return t.errorf("unexpected %q", r)
+	default:
+		t.out.WriteString(regexp.QuoteMeta(string(t.next())))
+	}
+	return nil
+}
+
+// escape translates a single character escape or a category escape
+func (t *iregexp) escape() error {
+	t.next()
+	switch r := t.peek(); r {
+	case '(', ')', '*', '+', '-', '.', '?', '[', '\\', ']', '^', '{', '|', '}':
+		t.out.WriteByte('\\')
+		t.out.WriteRune(t.next())
+	case 'n', 'r', 't':
+		t.out.WriteByte('\\')
+		t.out.WriteRune(t.next())
+	case 'p', 'P':
+		t.next()
+		if t.peek() != '{' {
+			return t.errorf("expected '{' after \\%c", r)
+		}
+		t.next()
+		end := strings.IndexByte(t.pattern[t.pos:], '}')
+		if end < 0 || !iregexpCategories[t.pattern[t.pos:t.pos+end]] {
+			return t.errorf("unknown character category")
+		}
+		fmt.Fprintf(&t.out, `\%c{%s}`, r, t.pattern[t.pos:t.pos+end])
+		t.pos += end + 1
+	case -1:
+		return t.errorf("trailing backslash")
+	default:
+		return t.errorf("unsupported escape \\%c", r)
+	}
+	return nil
+}
+
+// class translates a character class expression such as [^a-z\p{Nd}-]
+func (t *iregexp) class() error {
+	t.next()
+	t.out.WriteByte('[')
+	if t.peek() == '^' {
+		t.next()
+		t.out.WriteByte('^')
+	}
+	// A - is literal first and last, and a range bound nowhere else
+	if t.peek() == '-' {
+		t.next()
+		t.out.WriteString(`\-`)
+	}
+	for {
+		switch r := t.peek(); {
+		case r == -1:
+			return t.errorf("expected ']'")
+		case r == ']':
+			t.next()
+			t.out.WriteByte(']')
+			return nil
+		case r == '-':
+			t.next()
+			if t.peek() != ']' {
+				return t.errorf("unexpected '-' in a character class")
+			}
+			t.out.WriteString(`\-`)
+		case r == '\\' && t.pos+1 < len(t.pattern) && strings.IndexByte("pP", t.pattern[t.pos+1]) >= 0:
+			if err := t.escape(); err != nil {
+				return err
+			}
+		default:
+			if err := t.classChar(); err != nil {
+				return err
+			}
+			if t.peek() == '-' && t.pos+1 < len(t.pattern) && t.pattern[t.pos+1] != ']' {
+				t.next()
+				t.out.WriteByte('-')
+				if err := t.classChar(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// classChar translates one character of a character class
+func (t *iregexp) classChar() error {
+	switch r := t.peek(); r {
+	case '\\':
+		if t.pos+1 < len(t.pattern) && strings.IndexByte("pP", t.pattern[t.pos+1]) >= 0 {
+			return t.errorf("unexpected category escape in a range")
+		}
+		return t.escape()
+	case '[', ']', '-':
+		return t.errorf("unexpected %q in a character class", r)
+	default:
+		t.out.WriteString(regexp.QuoteMeta(string(t.next())))
+	}
+	return nil
+}
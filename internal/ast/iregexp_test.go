@@ -0,0 +1,60 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslateIRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{`abc`, `abc`},
+		{`a.c`, `a[^\n\r]c`},
+		{`^a$`, `\^a\$`},
+		{`a|b|`, `a|b|`},
+		{`(ab)+c*d?`, `(?:ab)+c*d?`},
+		{`a{2}b{1,}c{1,3}`, `a{2}b{1,}c{1,3}`},
+		{`\.\n\\\p{Lu}\P{N}`, `\.\n\\\p{Lu}\P{N}`},
+		{`[a-z]`, `[a-z]`},
+		{`[^.^]`, `[^\.\^]`},
+		{`[-a\]-]`, `[\-a\]\-]`},
+		{`[\p{Nd}x]`, `[\p{Nd}x]`},
+		{`é+`, `é+`},
+	}
+	for _, tt := range tests {
+		got, err := translateIRegexp(tt.pattern)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.pattern, err)
+		} else if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.pattern, tt.want, got)
+		}
+	}
+}
+
+func TestTranslateIRegexp_Errors(t *testing.T) {
+	tests := map[string]string{
+		`\d`:      `unsupported escape \d`,
+		`\w+`:     `unsupported escape \w`,
+		`a*?`:     `unexpected '?'`,
+		`(?:a)`:   `unexpected '?'`,
+		`(a`:      `expected ')'`,
+		`a)`:      `unexpected ')'`,
+		`*a`:      `unexpected '*'`,
+		`a{,2}`:   "expected a number",
+		`a{2`:     "expected '}'",
+		`[a`:      "expected ']'",
+		`[a-b-c]`: "unexpected '-'",
+		`[[]`:     `unexpected '['`,
+		`[a-\pL]`: "category escape in a range",
+		`\p{Xx}`:  "unknown character category",
+		`\pL`:     `expected '{'`,
+		`a\`:      "trailing backslash",
+		"\xff":    "invalid UTF-8",
+	}
+	for pattern, want := range tests {
+		if _, err := translateIRegexp(pattern); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", pattern, want, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ast
+
+import "fmt"
+
+// ToInterface converts v into plain Go data, as encoding/json decodes into
+// interface{}: map[string]interface{} for objects, []interface{} for arrays,
+// float64, string, bool, and nil for null. It fails on numbers beyond the
+// range of float64 and on values that are not AST nodes.
+func ToInterface(v Value) (interface{}, error) {
+	switch n := v.(type) {
+	case *Object:
+		m := make(map[string]interface{}, len(n.Pairs))
+		for key, child := range n.Pairs {
+			native, err := ToInterface(child)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = native
+		}
+		return m, nil
+	case *Array:
+		s := make([]interface{}, len(n.Elements))
+		for i, child := range n.Elements {
+			native, err := ToInterface(child)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = native
+		}
+		return s, nil
+	case *String:
+		return n.Value, nil
+	case *Number:
+		f, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("number %s does not fit in a float64", n.Value)
+		}
+		return f, nil
+	case *Boolean:
+		return n.Value == "true", nil
+	case *Null:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported AST node %T", v)
+}
@@ -0,0 +1,44 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToInterface(t *testing.T) {
+	doc := object(
+		"name", &String{Value: "api"},
+		"ports", &Array{Elements: []Value{number("80"), number("4.5e2")}},
+		"tls", object("enabled", &Boolean{Value: "true"}, "ca", &Null{}),
+		"tags", &Array{},
+	)
+	expected := map[string]interface{}{
+		"name":  "api",
+		"ports": []interface{}{80.0, 450.0},
+		"tls":   map[string]interface{}{"enabled": true, "ca": nil},
+		"tags":  []interface{}{},
+	}
+
+	got, err := ToInterface(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestToInterface_Errors(t *testing.T) {
+	tests := []struct {
+		value Value
+		err   string
+	}{
+		{&Array{Elements: []Value{number("1e400")}}, "number 1e400 does not fit in a float64"},
+		{42, "unsupported AST node int"},
+	}
+	for _, tt := range tests {
+		if _, err := ToInterface(tt.value); err == nil || err.Error() != tt.err {
+			t.Errorf("expected %q, got %v", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ast
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExactCheckExponent bounds the exponents ExactFloat64 compares digit by digit
+const maxExactCheckExponent = 400
+
+// Int64 converts an integer literal to an int64
+func (n *Number) Int64() (int64, error) {
+	return strconv.ParseInt(n.Value, 10, 64)
+}
+
+// Float64 converts the literal to the nearest float64
+func (n *Number) Float64() (float64, error) {
+	return strconv.ParseFloat(n.Value, 64)
+}
+
+// Canonical returns the literal spelled the one way every literal of the
+// same value is, exactly and without rounding to a float: as ECMAScript
+// writes a number, plain from 1e-7 up to 1e21 and in exponent form beyond,
+// so 1, 1.0 and 1e0 are all "1" and 1E30 is "1e+30". A literal that is not
+// a standard JSON number is returned as is.
+func (n *Number) Canonical() string {
+	d, ok := decimalOf(n.Value)
+	if !ok {
+		return n.Value
+	}
+	return d.String()
+}
+
+// ExactFloat64 reports whether Float64 keeps every significant digit of the literal,
+// e.g. it is false for integers beyond 2^53 that have no exact float64 representation.
+func (n *Number) ExactFloat64() bool {
+	return n.exactFloat(64)
+}
+
+// ExactFloat32 reports whether converting the literal to a float32 keeps every significant digit
+func (n *Number) ExactFloat32() bool {
+	return n.exactFloat(32)
+}
+
+// exactFloat compares the literal with the shortest form of its nearest float of the given size
+func (n *Number) exactFloat(bitSize int) bool {
+	f, err := strconv.ParseFloat(n.Value, bitSize)
+	if err != nil {
+		return false
+	}
+
+	if i := strings.IndexAny(n.Value, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(strings.TrimPrefix(n.Value[i+1:], "+"))
+		if err != nil || exp > maxExactCheckExponent || exp < -maxExactCheckExponent {
+			return false
+		}
+	}
+
+	literal, ok := new(big.Rat).SetString(n.Value)
+	if !ok {
+		return false
+	}
+	shortest, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	if !ok {
+		return false
+	}
+	return literal.Cmp(shortest) == 0
+}
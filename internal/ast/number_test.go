@@ -0,0 +1,78 @@
+package ast
+
+import "testing"
+
+func TestNumber_ExactFloat64(t *testing.T) {
+	tests := []struct {
+		literal string
+		exact   bool
+	}{
+		{"0", true},
+		{"-12", true},
+		{"0.1", true},
+		{"1.5e3", true},
+		{"9007199254740992", true},
+		{"9007199254740993", false},
+		{"12345678901234567890123", false},
+		{"0.30000000000000001", false},
+		{"1e-500", false},
+		{"1e500", false},
+	}
+
+	for _, tt := range tests {
+		n := &Number{Value: tt.literal}
+		if got := n.ExactFloat64(); got != tt.exact {
+			t.Errorf("%s: expected exact=%v, got %v", tt.literal, tt.exact, got)
+		}
+	}
+}
+
+func TestNumber_Canonical(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    string
+	}{
+		{"1", "1"},
+		{"1.0", "1"},
+		{"1e0", "1"},
+		{"10E-1", "1"},
+		{"-0", "0"},
+		{"0.0e5", "0"},
+		{"-12.50", "-12.5"},
+		{"1.5e1", "15"},
+		{"1e20", "100000000000000000000"},
+		{"1e21", "1e+21"},
+		{"123e30", "1.23e+32"},
+		{"0.000001", "0.000001"},
+		{"1e-7", "1e-7"},
+		{"-0.00000012", "-1.2e-7"},
+		{"9007199254740993", "9007199254740993"},
+		{"1e99999999999999999999", "1e99999999999999999999"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Number{Value: tt.literal}).Canonical(); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.literal, tt.want, got)
+		}
+	}
+}
+
+func TestNumber_ExactFloat32(t *testing.T) {
+	if !(&Number{Value: "0.1"}).ExactFloat32() {
+		t.Errorf("expected 0.1 to be exact as float32")
+	}
+	if (&Number{Value: "16777217"}).ExactFloat32() {
+		t.Errorf("expected 2^24+1 to lose precision as float32")
+	}
+}
+
+func TestNumber_Int64(t *testing.T) {
+	n := &Number{Value: "9007199254740993"}
+	i, err := n.Int64()
+	if err != nil || i != 9007199254740993 {
+		t.Errorf("expected 9007199254740993, got %d (%v)", i, err)
+	}
+	if _, err := (&Number{Value: "1.5"}).Int64(); err == nil {
+		t.Errorf("expected error for a fractional literal")
+	}
+}
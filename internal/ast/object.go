@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Set stores value under key, appending key to Keys when it is new. It
+// panics with ErrFrozen when the object is frozen.
+func (o *Object) Set(key string, value Value) {
+	if o.frozen {
+		panic(fmt.Errorf("cannot set member %q: %w", key, ErrFrozen))
+	}
+	if o.Pairs == nil {
+		o.Pairs = make(map[string]Value)
+	}
+	if _, ok := o.Pairs[key]; !ok {
+		o.Keys = append(o.Keys, key)
+	}
+	o.Pairs[key] = value
+}
+
+// Delete removes key from Pairs and Keys. It panics with ErrFrozen when the
+// object is frozen.
+func (o *Object) Delete(key string) {
+	if o.frozen {
+		panic(fmt.Errorf("cannot delete member %q: %w", key, ErrFrozen))
+	}
+	if _, ok := o.Pairs[key]; !ok {
+		return
+	}
+	delete(o.Pairs, key)
+	for i, k := range o.Keys {
+		if k == key {
+			o.Keys = append(o.Keys[:i:i], o.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// OrderedKeys returns the keys of Pairs in order: the keys listed in Keys
+// first, then any keys added to Pairs directly, sorted. Parsed objects list
+// their keys in input order. The result must not be modified.
+func (o *Object) OrderedKeys() []string {
+	if len(o.Keys) == len(o.Pairs) && o.keysMatch() {
+		return o.Keys
+	}
+
+	keys := make([]string, 0, len(o.Pairs))
+	listed := make(map[string]bool, len(o.Keys))
+	for _, key := range o.Keys {
+		if _, ok := o.Pairs[key]; ok && !listed[key] {
+			listed[key] = true
+			keys = append(keys, key)
+		}
+	}
+	extra := len(keys)
+	for key := range o.Pairs {
+		if !listed[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys[extra:])
+	return keys
+}
+
+// keysMatch reports whether every key in Keys is present in Pairs
+func (o *Object) keysMatch() bool {
+	for _, key := range o.Keys {
+		if _, ok := o.Pairs[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestObject_SetAndDelete(t *testing.T) {
+	obj := &Object{}
+	obj.Set("b", &Number{Value: "1"})
+	obj.Set("a", &Number{Value: "2"})
+	obj.Set("b", &Number{Value: "3"}) // replacing keeps the position
+	obj.Set("c", &Null{})
+	obj.Delete("a")
+	obj.Delete("missing")
+
+	if got := obj.String(); got != `{"b":3,"c":null}` {
+		t.Errorf("expected {\"b\":3,\"c\":null}, got %s", got)
+	}
+	if !reflect.DeepEqual(obj.Keys, []string{"b", "c"}) {
+		t.Errorf("expected Keys [b c], got %v", obj.Keys)
+	}
+}
+
+func TestObject_OrderedKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      *Object
+		expected []string
+	}{
+		{
+			name:     "keys in order",
+			obj:      &Object{Pairs: map[string]Value{"z": nil, "a": nil}, Keys: []string{"z", "a"}},
+			expected: []string{"z", "a"},
+		},
+		{
+			name:     "no keys recorded",
+			obj:      &Object{Pairs: map[string]Value{"z": nil, "a": nil}},
+			expected: []string{"a", "z"},
+		},
+		{
+			name:     "pairs changed directly",
+			obj:      &Object{Pairs: map[string]Value{"z": nil, "m": nil, "b": nil}, Keys: []string{"z", "gone", "z"}},
+			expected: []string{"z", "b", "m"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.obj.OrderedKeys(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
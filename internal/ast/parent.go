@@ -0,0 +1,173 @@
+package ast
+
+import "strconv"
+
+// Link sets the Parent of every node below root to the array or object
+// holding it, and clears the Parent of root itself. Edits made afterwards
+// do not update the links; call Link again once the tree has changed.
+// Frozen arrays and objects, and the values below them, are left as they are.
+func Link(root Value) {
+	setParent(root, nil)
+	link(root)
+}
+
+func link(v Value) {
+	if IsFrozen(v) {
+		return
+	}
+	switch n := v.(type) {
+	case *Object:
+		for _, child := range n.Pairs {
+			setParent(child, n)
+			link(child)
+		}
+	case *Array:
+		for _, child := range n.Elements {
+			setParent(child, n)
+			link(child)
+		}
+	}
+}
+
+// ParentOf returns the Parent of v, or nil for values of other types
+func ParentOf(v Value) Value {
+	switch n := v.(type) {
+	case *Object:
+		return n.Parent
+	case *Array:
+		return n.Parent
+	case *String:
+		return n.Parent
+	case *Number:
+		return n.Parent
+	case *Boolean:
+		return n.Parent
+	case *Null:
+		return n.Parent
+	case *BadValue:
+		return n.Parent
+	}
+	return nil
+}
+
+// setParent records parent on v; frozen values and values of other types are left alone
+func setParent(v, parent Value) {
+	if IsFrozen(v) {
+		return
+	}
+	switch n := v.(type) {
+	case *Object:
+		n.Parent = parent
+	case *Array:
+		n.Parent = parent
+	case *String:
+		n.Parent = parent
+	case *Number:
+		n.Parent = parent
+	case *Boolean:
+		n.Parent = parent
+	case *Null:
+		n.Parent = parent
+	case *BadValue:
+		n.Parent = parent
+	}
+}
+
+// PathOf returns the JSON Pointer of v within its linked document, found by
+// following Parent links up to the root: "" for the root itself. The walk
+// stops early at a parent that no longer holds the node, so the result is
+// only exact while the links are current.
+func PathOf(v Value) string {
+	var segments []string
+	for parent := ParentOf(v); parent != nil; v, parent = parent, ParentOf(parent) {
+		segment, ok := segmentOf(parent, v)
+		if !ok {
+			break
+		}
+		segments = append(segments, segment)
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return FormatPointer(segments)
+}
+
+// SiblingOf returns the value its parent holds beside v: the member named
+// key when the parent is an object, and the element at index key, counting
+// from the end when negative, when it is an array. It reports false for an
+// unlinked node, the root, or a missing sibling.
+func SiblingOf(v Value, key string) (Value, bool) {
+	switch p := ParentOf(v).(type) {
+	case *Object:
+		sibling, ok := p.Pairs[key]
+		return sibling, ok
+	case *Array:
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, false
+		}
+		return p.At(i)
+	}
+	return nil, false
+}
+
+// segmentOf finds the key or index under which parent holds child
+func segmentOf(parent, child Value) (string, bool) {
+	switch p := parent.(type) {
+	case *Object:
+		for _, key := range p.OrderedKeys() {
+			if p.Pairs[key] == child {
+				return key, true
+			}
+		}
+	case *Array:
+		for i, element := range p.Elements {
+			if element == child {
+				return strconv.Itoa(i), true
+			}
+		}
+	}
+	return "", false
+}
+
+// Path returns the JSON Pointer of the object within its linked document; see PathOf
+func (o *Object) Path() string { return PathOf(o) }
+
+// Path returns the JSON Pointer of the array within its linked document; see PathOf
+func (a *Array) Path() string { return PathOf(a) }
+
+// Path returns the JSON Pointer of the string within its linked document; see PathOf
+func (s *String) Path() string { return PathOf(s) }
+
+// Path returns the JSON Pointer of the number within its linked document; see PathOf
+func (n *Number) Path() string { return PathOf(n) }
+
+// Path returns the JSON Pointer of the boolean within its linked document; see PathOf
+func (b *Boolean) Path() string { return PathOf(b) }
+
+// Path returns the JSON Pointer of the null within its linked document; see PathOf
+func (n *Null) Path() string { return PathOf(n) }
+
+// Path returns the JSON Pointer of the bad value within its linked document; see PathOf
+func (b *BadValue) Path() string { return PathOf(b) }
+
+// Sibling returns the value beside the object in its parent; see SiblingOf
+func (o *Object) Sibling(key string) (Value, bool) { return SiblingOf(o, key) }
+
+// Sibling returns the value beside the array in its parent; see SiblingOf
+func (a *Array) Sibling(key string) (Value, bool) { return SiblingOf(a, key) }
+
+// Sibling returns the value beside the string in its parent; see SiblingOf
+func (s *String) Sibling(key string) (Value, bool) { return SiblingOf(s, key) }
+
+// Sibling returns the value beside the number in its parent; see SiblingOf
+func (n *Number) Sibling(key string) (Value, bool) { return SiblingOf(n, key) }
+
+// Sibling returns the value beside the boolean in its parent; see SiblingOf
+func (b *Boolean) Sibling(key string) (Value, bool) { return SiblingOf(b, key) }
+
+// Sibling returns the value beside the null in its parent; see SiblingOf
+func (n *Null) Sibling(key string) (Value, bool) { return SiblingOf(n, key) }
+
+// Sibling returns the value beside the bad value in its parent; see SiblingOf
+func (b *BadValue) Sibling(key string) (Value, bool) { return SiblingOf(b, key) }
@@ -0,0 +1,68 @@
+package ast
+
+import "testing"
+
+func TestLink_Path(t *testing.T) {
+	doc := config()
+	doc.Set("tls", object("cert", &String{Value: "/etc/a~b.pem"}))
+	Link(doc)
+
+	tests := []struct {
+		path string
+		node Value
+	}{
+		{"", doc},
+		{"/name", doc.Pairs["name"]},
+		{"/ports/1", doc.Pairs["ports"].(*Array).Elements[1]},
+		{"/a~1b", doc.Pairs["a/b"]},
+		{"/tls/cert", doc.Pairs["tls"].(*Object).Pairs["cert"]},
+	}
+	for _, tt := range tests {
+		if got := PathOf(tt.node); got != tt.path {
+			t.Errorf("expected %q, got %q", tt.path, got)
+		}
+		if found, err := Lookup(doc, tt.path); err != nil || found != tt.node {
+			t.Errorf("%q: expected Lookup to find the node again, got %v, %v", tt.path, found, err)
+		}
+	}
+	if ParentOf(doc.Pairs["ports"].(*Array).Elements[0]) != doc.Pairs["ports"] {
+		t.Errorf("expected elements to link to their array")
+	}
+}
+
+func TestLink_Unlinked(t *testing.T) {
+	doc := config()
+	ports := doc.Pairs["ports"].(*Array)
+	if got := ports.Elements[0].(*Number).Path(); got != "" {
+		t.Errorf("expected an unlinked node to have the empty path, got %q", got)
+	}
+
+	Link(doc)
+	elem := ports.Elements[1]
+	doc.Delete("ports")
+	if got := PathOf(elem); got != "/1" {
+		t.Errorf("expected the walk to stop at the detached array, got %q", got)
+	}
+}
+
+func TestSiblingOf(t *testing.T) {
+	doc := config()
+	Link(doc)
+	ports := doc.Pairs["ports"].(*Array)
+
+	if got, ok := doc.Pairs["name"].(*String).Sibling("ports"); !ok || got != ports {
+		t.Errorf("expected the ports member, got %v, %t", got, ok)
+	}
+	if got, ok := ports.Elements[0].(*Number).Sibling("-1"); !ok || got != ports.Elements[len(ports.Elements)-1] {
+		t.Errorf("expected the last port, got %v, %t", got, ok)
+	}
+	if _, ok := SiblingOf(doc.Pairs["name"], "missing"); ok {
+		t.Error("expected no sibling for a missing member")
+	}
+	if _, ok := SiblingOf(ports.Elements[0], "name"); ok {
+		t.Error("expected no sibling for a name in an array")
+	}
+	if _, ok := doc.Sibling("name"); ok {
+		t.Error("expected the root to have no siblings")
+	}
+}
@@ -0,0 +1,238 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Escaping of '~' and '/' in JSON Pointer segments
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// SplitPointer splits a JSON Pointer such as "/a/b~1c/0" into unescaped segments
+func SplitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q: must be empty or start with '/'", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, s := range segments {
+		segments[i] = pointerUnescaper.Replace(s)
+	}
+	return segments, nil
+}
+
+// FormatPointer joins segments into a JSON Pointer, escaping '~' and '/'
+func FormatPointer(segments []string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(s))
+	}
+	return b.String()
+}
+
+// Lookup returns the value at the JSON Pointer path in root
+func Lookup(root Value, path string) (Value, error) {
+	segments, err := SplitPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	v := root
+	for i, segment := range segments {
+		if v, err = child(v, segment); err != nil {
+			return nil, fmt.Errorf("path %q: %v at %q", path, err, FormatPointer(segments[:i+1]))
+		}
+	}
+	return v, nil
+}
+
+// SetPath stores value at path in root. Object members are added or
+// replaced, and missing objects along the way are created. Array elements
+// are replaced, or appended when the last segment is "-" or the array length.
+func SetPath(root Value, path string, value Value) error {
+	return edit(root, path, true, func(parent Value, key string) error {
+		switch p := parent.(type) {
+		case *Object:
+			p.Set(key, value)
+		case *Array:
+			i, err := index(key, len(p.Elements), true)
+			if err != nil {
+				return err
+			}
+			if i == len(p.Elements) {
+				p.Elements = append(p.Elements, value)
+			} else {
+				p.Elements[i] = value
+			}
+		default:
+			return fmt.Errorf("cannot set a member of %s", TypeName(parent))
+		}
+		return nil
+	})
+}
+
+// InsertPath inserts value into the array holding path, before the element
+// path names, or at the end when the last segment is "-" or the array length.
+// For an object parent it adds the member like SetPath.
+func InsertPath(root Value, path string, value Value) error {
+	return edit(root, path, false, func(parent Value, key string) error {
+		switch p := parent.(type) {
+		case *Object:
+			p.Set(key, value)
+		case *Array:
+			i, err := index(key, len(p.Elements), true)
+			if err != nil {
+				return err
+			}
+			p.Elements = append(p.Elements, nil)
+			copy(p.Elements[i+1:], p.Elements[i:])
+			p.Elements[i] = value
+		default:
+			return fmt.Errorf("cannot insert into %s", TypeName(parent))
+		}
+		return nil
+	})
+}
+
+// AppendPath appends value to the array at path
+func AppendPath(root Value, path string, value Value) error {
+	target, err := Lookup(root, path)
+	if err != nil {
+		return err
+	}
+	array, ok := target.(*Array)
+	if !ok {
+		return fmt.Errorf("path %q: cannot append to %s", path, TypeName(target))
+	}
+	if array.frozen {
+		return fmt.Errorf("path %q: %w", path, ErrFrozen)
+	}
+	array.Elements = append(array.Elements, value)
+	return nil
+}
+
+// DeletePath removes the object member or array element at path
+func DeletePath(root Value, path string) error {
+	return edit(root, path, false, func(parent Value, key string) error {
+		switch p := parent.(type) {
+		case *Object:
+			if _, ok := p.Pairs[key]; !ok {
+				return fmt.Errorf("no member %q", key)
+			}
+			p.Delete(key)
+		case *Array:
+			i, err := index(key, len(p.Elements), false)
+			if err != nil {
+				return err
+			}
+			p.Elements = append(p.Elements[:i], p.Elements[i+1:]...)
+		default:
+			return fmt.Errorf("cannot delete from %s", TypeName(parent))
+		}
+		return nil
+	})
+}
+
+// edit resolves the parent of path and applies fn to it and the last segment.
+// With create, missing object members on the way are added as empty objects.
+func edit(root Value, path string, create bool, fn func(parent Value, key string) error) error {
+	segments, err := SplitPointer(path)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("path %q: cannot change the root value in place", path)
+	}
+
+	parent := root
+	for i, segment := range segments[:len(segments)-1] {
+		next, err := child(parent, segment)
+		if err != nil && create {
+			if IsFrozen(parent) {
+				return fmt.Errorf("path %q: %w at %q", path, ErrFrozen, FormatPointer(segments[:i]))
+			}
+			if obj, ok := parent.(*Object); ok {
+				next = &Object{Pairs: make(map[string]Value)}
+				obj.Set(segment, next)
+				err = nil
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("path %q: %v at %q", path, err, FormatPointer(segments[:i+1]))
+		}
+		parent = next
+	}
+	if IsFrozen(parent) {
+		return fmt.Errorf("path %q: %w", path, ErrFrozen)
+	}
+	if err := fn(parent, segments[len(segments)-1]); err != nil {
+		return fmt.Errorf("path %q: %v", path, err)
+	}
+	return nil
+}
+
+// child returns the member or element of v named by segment
+func child(v Value, segment string) (Value, error) {
+	switch v := v.(type) {
+	case *Object:
+		value, ok := v.Pairs[segment]
+		if !ok {
+			return nil, fmt.Errorf("no member %q", segment)
+		}
+		return value, nil
+	case *Array:
+		i, err := index(segment, len(v.Elements), false)
+		if err != nil {
+			return nil, err
+		}
+		return v.Elements[i], nil
+	default:
+		return nil, fmt.Errorf("%s has no members", TypeName(v))
+	}
+}
+
+// index parses an array index segment. With end, "-" and length itself are
+// accepted and name the position after the last element.
+func index(segment string, length int, end bool) (int, error) {
+	if end && segment == "-" {
+		return length, nil
+	}
+	i, err := strconv.Atoi(segment)
+	if err != nil || i < 0 || strconv.Itoa(i) != segment {
+		return 0, fmt.Errorf("invalid array index %q", segment)
+	}
+	if i > length || i == length && !end {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// SetPath stores value at the JSON Pointer path below the object; see SetPath
+func (o *Object) SetPath(path string, value Value) error { return SetPath(o, path, value) }
+
+// InsertPath inserts value at the JSON Pointer path below the object; see InsertPath
+func (o *Object) InsertPath(path string, value Value) error { return InsertPath(o, path, value) }
+
+// AppendPath appends value to the array at the JSON Pointer path below the object
+func (o *Object) AppendPath(path string, value Value) error { return AppendPath(o, path, value) }
+
+// DeletePath removes the value at the JSON Pointer path below the object
+func (o *Object) DeletePath(path string) error { return DeletePath(o, path) }
+
+// SetPath stores value at the JSON Pointer path below the array; see SetPath
+func (a *Array) SetPath(path string, value Value) error { return SetPath(a, path, value) }
+
+// InsertPath inserts value at the JSON Pointer path below the array; see InsertPath
+func (a *Array) InsertPath(path string, value Value) error { return InsertPath(a, path, value) }
+
+// AppendPath appends value to the array at the JSON Pointer path below the array
+func (a *Array) AppendPath(path string, value Value) error { return AppendPath(a, path, value) }
+
+// DeletePath removes the value at the JSON Pointer path below the array
+func (a *Array) DeletePath(path string) error { return DeletePath(a, path) }
@@ -0,0 +1,96 @@
+package ast
+
+import "testing"
+
+// config builds a fresh document for each edit
+func config() *Object {
+	doc := &Object{}
+	doc.Set("name", &String{Value: "api"})
+	doc.Set("ports", &Array{Elements: []Value{&Number{Value: "80"}, &Number{Value: "443"}}})
+	doc.Set("a/b", &Null{})
+	return doc
+}
+
+func TestSplitPointer_Escapes(t *testing.T) {
+	segments, err := SplitPointer("/a~1b/c~0d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) != 2 || segments[0] != "a/b" || segments[1] != "c~d" {
+		t.Errorf("expected [a/b c~d], got %v", segments)
+	}
+	if FormatPointer(segments) != "/a~1b/c~0d" {
+		t.Errorf("expected round trip, got %s", FormatPointer(segments))
+	}
+}
+
+func TestPathEdits(t *testing.T) {
+	one := &Number{Value: "1"}
+	tests := []struct {
+		name     string
+		edit     func(doc *Object) error
+		expected string
+	}{
+		{"set member", func(d *Object) error { return d.SetPath("/name", one) }, `{"name":1,"ports":[80,443],"a/b":null}`},
+		{"set escaped member", func(d *Object) error { return d.SetPath("/a~1b", one) }, `{"name":"api","ports":[80,443],"a/b":1}`},
+		{"set creates objects", func(d *Object) error { return d.SetPath("/tls/cert/path", one) }, `{"name":"api","ports":[80,443],"a/b":null,"tls":{"cert":{"path":1}}}`},
+		{"set element", func(d *Object) error { return d.SetPath("/ports/0", one) }, `{"name":"api","ports":[1,443],"a/b":null}`},
+		{"set past end", func(d *Object) error { return d.SetPath("/ports/-", one) }, `{"name":"api","ports":[80,443,1],"a/b":null}`},
+		{"insert element", func(d *Object) error { return d.InsertPath("/ports/1", one) }, `{"name":"api","ports":[80,1,443],"a/b":null}`},
+		{"insert at length", func(d *Object) error { return d.InsertPath("/ports/2", one) }, `{"name":"api","ports":[80,443,1],"a/b":null}`},
+		{"append", func(d *Object) error { return d.AppendPath("/ports", one) }, `{"name":"api","ports":[80,443,1],"a/b":null}`},
+		{"delete member", func(d *Object) error { return d.DeletePath("/name") }, `{"ports":[80,443],"a/b":null}`},
+		{"delete element", func(d *Object) error { return d.DeletePath("/ports/0") }, `{"name":"api","ports":[443],"a/b":null}`},
+	}
+
+	for _, tt := range tests {
+		doc := config()
+		if err := tt.edit(doc); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got := doc.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestPathEdits_Errors(t *testing.T) {
+	one := &Number{Value: "1"}
+	tests := []struct {
+		name string
+		edit func(doc *Object) error
+		err  string
+	}{
+		{"root", func(d *Object) error { return d.SetPath("", one) }, `path "": cannot change the root value in place`},
+		{"no slash", func(d *Object) error { return d.SetPath("name", one) }, `invalid path "name": must be empty or start with '/'`},
+		{"through scalar", func(d *Object) error { return d.SetPath("/name/x", one) }, `path "/name/x": cannot set a member of string`},
+		{"missing element", func(d *Object) error { return d.SetPath("/ports/5/x", one) }, `path "/ports/5/x": array index 5 out of range at "/ports/5"`},
+		{"bad index", func(d *Object) error { return d.SetPath("/ports/01", one) }, `path "/ports/01": invalid array index "01"`},
+		{"delete missing", func(d *Object) error { return d.DeletePath("/nope") }, `path "/nope": no member "nope"`},
+		{"delete past end", func(d *Object) error { return d.DeletePath("/ports/2") }, `path "/ports/2": array index 2 out of range`},
+		{"append to object", func(d *Object) error { return d.AppendPath("", one) }, `path "": cannot append to object`},
+		{"append missing", func(d *Object) error { return d.AppendPath("/tags", one) }, `path "/tags": no member "tags" at "/tags"`},
+	}
+
+	for _, tt := range tests {
+		err := tt.edit(config())
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	doc := config()
+	v, err := Lookup(doc, "/ports/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, ok := v.(*Number); !ok || n.Value != "443" {
+		t.Errorf("expected 443, got %v", v)
+	}
+	if v, err := Lookup(doc, ""); err != nil || v != Value(doc) {
+		t.Errorf("expected the root, got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,661 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Cursors is a set of query results. Set and Delete apply to every match in
+// the original document at once.
+type Cursors []Cursor
+
+// Values returns the matched values in order
+func (cs Cursors) Values() []Value {
+	values := make([]Value, len(cs))
+	for i, c := range cs {
+		values[i] = c.Value
+	}
+	return values
+}
+
+// Set replaces every matched value with its own deep copy of v, so that
+// later edits to one match do not show in the others. A value matched more
+// than once is replaced once. Nothing is changed unless every cursor is
+// still current.
+func (cs Cursors) Set(v Value) error {
+	for i := range cs {
+		if err := cs[i].check(); err != nil {
+			return err
+		}
+	}
+	type slot struct {
+		parent Value
+		key    string
+	}
+	done := make(map[slot]bool, len(cs))
+	// Later matches first, so replacing a value leaves the cursors inside it current until done
+	for i := len(cs) - 1; i >= 0; i-- {
+		s := slot{cs[i].Parent, cs[i].key}
+		if done[s] {
+			continue
+		}
+		done[s] = true
+		if err := cs[i].Set(Clone(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes every matched value from its parent. Array elements are
+// removed together once all cursors are checked, so neither the order of the
+// matches nor one matched twice moves an element still to go.
+func (cs Cursors) Delete() error {
+	for i := range cs {
+		if err := cs[i].check(); err != nil {
+			return err
+		}
+	}
+	removed := make(map[*Array]map[Value]bool)
+	for _, c := range cs {
+		switch p := c.Parent.(type) {
+		case *Object:
+			p.Delete(c.key)
+		case *Array:
+			if removed[p] == nil {
+				removed[p] = make(map[Value]bool)
+			}
+			removed[p][c.Value] = true
+		}
+	}
+	for array, values := range removed {
+		kept := array.Elements[:0]
+		for _, element := range array.Elements {
+			if !values[element] {
+				kept = append(kept, element)
+			}
+		}
+		clear(array.Elements[len(kept):])
+		array.Elements = kept
+	}
+	return nil
+}
+
+// Query returns a cursor for every value matching an RFC 9535 JSONPath
+// expression such as "$.items[?@.qty > 1].currency", in the order the RFC
+// gives. Members and indices that do not exist match nothing; a value
+// selected more than once is returned once per selection. Recently used
+// expressions are kept compiled, so repeating one skips parsing it.
+func Query(root Value, path string) (Cursors, error) {
+	q, err := queries.compile(path)
+	if err != nil {
+		return nil, err
+	}
+	return q.Query(root), nil
+}
+
+// CompiledQuery is a JSONPath expression parsed once to be run against many
+// documents. Running it changes nothing in it, so one CompiledQuery can be
+// used by any number of goroutines at once.
+type CompiledQuery struct {
+	src string
+	q   *query
+}
+
+// Compile parses an RFC 9535 JSONPath expression for running with Query
+func Compile(path string) (*CompiledQuery, error) {
+	q, err := compileQuery(path)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledQuery{src: path, q: q}, nil
+}
+
+// MustCompile is like Compile but panics when path is invalid, for queries
+// held in package variables
+func MustCompile(path string) *CompiledQuery {
+	q, err := Compile(path)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// String returns the expression the query was compiled from
+func (c *CompiledQuery) String() string {
+	return c.src
+}
+
+// Query returns a cursor for every value in root matching the query, as the
+// Query function does
+func (c *CompiledQuery) Query(root Value) Cursors {
+	return cursors(c.q.run(root, node{value: root}, nil))
+}
+
+// QueryIndex is Query on the document of ix, using its tables
+func (c *CompiledQuery) QueryIndex(ix *Index) Cursors {
+	return cursors(c.q.run(ix.root, node{value: ix.root}, ix))
+}
+
+// cursors returns a cursor for each node a query reached
+func cursors(nodes []node) Cursors {
+	results := make(Cursors, len(nodes))
+	for i, n := range nodes {
+		results[i] = Cursor{Value: n.value, Pointer: FormatPointer(n.path), Span: SpanOf(n.value), Parent: n.parent}
+		if len(n.path) > 0 {
+			results[i].key = n.path[len(n.path)-1]
+		}
+	}
+	return results
+}
+
+// maxCachedQueries bounds the cache of expressions compiled for Query
+const maxCachedQueries = 256
+
+// queryCache holds the expressions Query compiled most recently. A full
+// cache is emptied rather than grown.
+type queryCache struct {
+	mu       sync.Mutex
+	compiled map[string]*CompiledQuery
+}
+
+var queries = &queryCache{compiled: make(map[string]*CompiledQuery)}
+
+func (c *queryCache) compile(path string) (*CompiledQuery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if q, ok := c.compiled[path]; ok {
+		return q, nil
+	}
+	q, err := Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.compiled) >= maxCachedQueries {
+		c.compiled = make(map[string]*CompiledQuery)
+	}
+	c.compiled[path] = q
+	return q, nil
+}
+
+// node is a value reached by a query, with the path it was reached by
+type node struct {
+	value  Value
+	parent Value
+	path   []string // member names and indices from the root
+}
+
+// child returns the node for value, found under key in n
+func (n node) child(value Value, key string) node {
+	return node{value: value, parent: n.value, path: append(n.path[:len(n.path):len(n.path)], key)}
+}
+
+// children calls fn for the members of an object or the elements of an
+// array, in document order
+func (n node) children(fn func(node)) {
+	switch v := n.value.(type) {
+	case *Object:
+		for _, key := range v.OrderedKeys() {
+			fn(n.child(v.Pairs[key], key))
+		}
+	case *Array:
+		for i, element := range v.Elements {
+			fn(n.child(element, strconv.Itoa(i)))
+		}
+	}
+}
+
+// query is a compiled JSONPath expression, or a query within a filter
+type query struct {
+	relative bool // starts at the current node @ rather than the root $
+	segments []segment
+}
+
+// segment is .name, [selectors] or, when descendant, ..name and ..[selectors]
+type segment struct {
+	selectors  []selector
+	descendant bool
+}
+
+// selectorKind tells which fields of a selector are in use
+type selectorKind int
+
+const (
+	selectName selectorKind = iota
+	selectWildcard
+	selectIndex
+	selectSlice
+	selectFilter
+)
+
+// selector picks children of a node
+type selector struct {
+	kind             selectorKind
+	name             string  // member of a name selector
+	index            int     // element of an index selector, from the end when negative
+	start, end, step int     // bounds of a slice selector
+	hasStart, hasEnd bool    // whether start and end were given
+	filter           logical // test of a filter selector
+}
+
+// run applies the query to the start node: @ for a relative query and the
+// root otherwise. Descendant segments at the root of an index's document
+// are answered from the index when ix is not nil.
+func (q *query) run(root Value, start node, ix *Index) []node {
+	nodes := []node{start}
+	for _, seg := range q.segments {
+		var next []node
+		for _, n := range nodes {
+			if !seg.descendant {
+				next = seg.apply(root, n, next)
+				continue
+			}
+			if ix != nil && len(n.path) == 0 && n.value == ix.root {
+				next = ix.descendants(seg, next)
+				continue
+			}
+			var descend func(n node)
+			descend = func(n node) {
+				next = seg.apply(root, n, next)
+				n.children(descend)
+			}
+			descend(n)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// singular reports whether the query selects at most one node, as
+// comparisons in filters need
+func (q *query) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		if kind := seg.selectors[0].kind; kind != selectName && kind != selectIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// apply appends the children of n picked by each selector in turn
+func (seg segment) apply(root Value, n node, out []node) []node {
+	for i := range seg.selectors {
+		out = seg.selectors[i].apply(root, n, out)
+	}
+	return out
+}
+
+func (sel *selector) apply(root Value, n node, out []node) []node {
+	switch sel.kind {
+	case selectName:
+		if o, ok := n.value.(*Object); ok {
+			if value, ok := o.Pairs[sel.name]; ok {
+				out = append(out, n.child(value, sel.name))
+			}
+		}
+	case selectWildcard:
+		n.children(func(c node) { out = append(out, c) })
+	case selectIndex:
+		if a, ok := n.value.(*Array); ok {
+			i := sel.index
+			if i < 0 {
+				i += len(a.Elements)
+			}
+			if i >= 0 && i < len(a.Elements) {
+				out = append(out, n.child(a.Elements[i], strconv.Itoa(i)))
+			}
+		}
+	case selectSlice:
+		if a, ok := n.value.(*Array); ok {
+			for _, i := range sel.indices(len(a.Elements)) {
+				out = append(out, n.child(a.Elements[i], strconv.Itoa(i)))
+			}
+		}
+	case selectFilter:
+		n.children(func(c node) {
+			if sel.filter.test(root, c.value) {
+				out = append(out, c)
+			}
+		})
+	}
+	return out
+}
+
+// indices returns the elements of an array of the given length picked by a
+// slice, following RFC 9535 section 2.3.4.2
+func (sel *selector) indices(length int) []int {
+	normalize := func(i, lo, hi int) int {
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, lo), hi)
+	}
+
+	var indices []int
+	switch {
+	case sel.step > 0:
+		start, end := 0, length
+		if sel.hasStart {
+			start = normalize(sel.start, 0, length)
+		}
+		if sel.hasEnd {
+			end = normalize(sel.end, 0, length)
+		}
+		for i := start; i < end; i += sel.step {
+			indices = append(indices, i)
+		}
+	case sel.step < 0:
+		start, end := length-1, -1
+		if sel.hasStart {
+			start = normalize(sel.start, -1, length-1)
+		}
+		if sel.hasEnd {
+			end = normalize(sel.end, -1, length-1)
+		}
+		for i := start; i > end; i += sel.step {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// queryParser compiles a JSONPath expression, filters included
+type queryParser struct {
+	path string
+	pos  int
+}
+
+// compileQuery parses a complete JSONPath expression
+func compileQuery(path string) (*query, error) {
+	p := &queryParser{path: path}
+	if !strings.HasPrefix(path, "$") {
+		return nil, p.errorf("must start with '$'")
+	}
+	p.pos++
+	q, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.path) {
+		return nil, p.errorf("unexpected %q", p.path[p.pos])
+	}
+	return q, nil
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid path %q: %s", p.path, fmt.Sprintf(format, args...))
+}
+
+// peek returns the byte at the current position, or 0 at the end
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.path) {
+		return p.path[p.pos]
+	}
+	return 0
+}
+
+// skipBlank moves past spaces, tabs and line breaks
+func (p *queryParser) skipBlank() {
+	for p.pos < len(p.path) && strings.IndexByte(" \t\n\r", p.path[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// segments parses the segments after $ or @, stopping before anything that
+// does not start one
+func (p *queryParser) segments() (*query, error) {
+	q := &query{}
+	for {
+		before := p.pos
+		p.skipBlank()
+		var seg segment
+		var err error
+		switch {
+		case strings.HasPrefix(p.path[p.pos:], ".."):
+			p.pos += 2
+			seg, err = p.dotted(true)
+		case p.peek() == '.':
+			p.pos++
+			seg, err = p.dotted(false)
+		case p.peek() == '[':
+			seg.selectors, err = p.bracketed()
+		default:
+			p.pos = before
+			return q, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		q.segments = append(q.segments, seg)
+	}
+}
+
+// dotted parses what follows . or ..: *, a member name or, after .., a
+// bracketed selection
+func (p *queryParser) dotted(descendant bool) (segment, error) {
+	seg := segment{descendant: descendant}
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		seg.selectors = []selector{{kind: selectWildcard}}
+		return seg, nil
+	case c == '[' && descendant:
+		selectors, err := p.bracketed()
+		seg.selectors = selectors
+		return seg, err
+	}
+	name := p.memberName()
+	if name == "" {
+		if p.pos < len(p.path) {
+			return seg, p.errorf("invalid member name at %q", p.path[p.pos:])
+		}
+		return seg, p.errorf("empty member name")
+	}
+	seg.selectors = []selector{{kind: selectName, name: name}}
+	return seg, nil
+}
+
+// memberName reads a name of letters, digits, underscores and non-ASCII
+// characters that does not start with a digit
+func (p *queryParser) memberName() string {
+	start := p.pos
+	for p.pos < len(p.path) {
+		r, size := utf8.DecodeRuneInString(p.path[p.pos:])
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || r >= 0x80 || p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	return p.path[start:p.pos]
+}
+
+// bracketed parses [selector, ...] with p.pos on the '['
+func (p *queryParser) bracketed() ([]selector, error) {
+	p.pos++
+	var selectors []selector
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.path) {
+			return nil, p.errorf("unclosed '['")
+		}
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		case 0:
+			return nil, p.errorf("unclosed '['")
+		default:
+			return nil, p.errorf("unexpected %q", p.path[p.pos])
+		}
+	}
+}
+
+// selector parses one selector between brackets: a quoted name, *, ?filter,
+// an index or a slice
+func (p *queryParser) selector() (selector, error) {
+	switch p.peek() {
+	case '\'', '"':
+		name, err := p.quoted()
+		return selector{kind: selectName, name: name}, err
+	case '*':
+		p.pos++
+		return selector{kind: selectWildcard}, nil
+	case '?':
+		p.pos++
+		filter, err := p.logicalOr()
+		return selector{kind: selectFilter, filter: filter}, err
+	}
+
+	start := p.pos
+	for p.pos < len(p.path) && strings.IndexByte(",]", p.path[p.pos]) < 0 {
+		p.pos++
+	}
+	text := strings.TrimRight(p.path[start:p.pos], " \t\n\r")
+	if !strings.Contains(text, ":") {
+		i, ok := parseQueryInt(text)
+		if !ok {
+			return selector{}, p.errorf("invalid array index %q", text)
+		}
+		return selector{kind: selectIndex, index: i}, nil
+	}
+
+	parts := strings.Split(text, ":")
+	if len(parts) > 3 {
+		return selector{}, p.errorf("invalid slice %q", text)
+	}
+	sel := selector{kind: selectSlice, step: 1}
+	for i, part := range parts {
+		part = strings.Trim(part, " \t\n\r")
+		if part == "" {
+			continue
+		}
+		n, ok := parseQueryInt(part)
+		if !ok {
+			return selector{}, p.errorf("invalid slice %q", text)
+		}
+		switch i {
+		case 0:
+			sel.start, sel.hasStart = n, true
+		case 1:
+			sel.end, sel.hasEnd = n, true
+		case 2:
+			sel.step = n
+		}
+	}
+	return sel, nil
+}
+
+// maxQueryInt is the largest index or bound, the largest integer exact in
+// a float64 as I-JSON requires
+const maxQueryInt = 1<<53 - 1
+
+// parseQueryInt parses an index without leading zeros; -0 is not allowed
+func parseQueryInt(s string) (int, bool) {
+	i, err := strconv.Atoi(s)
+	if err != nil || strconv.Itoa(i) != s || s == "-0" || i > maxQueryInt || i < -maxQueryInt {
+		return 0, false
+	}
+	return i, true
+}
+
+// quoted parses a string in single or double quotes with p.pos on the
+// opening quote. The escapes are those of JSON, with \' in single quotes.
+func (p *queryParser) quoted() (string, error) {
+	quote := p.path[p.pos]
+	start := p.pos
+	p.pos++
+	var s strings.Builder
+	for {
+		c := p.peek()
+		switch {
+		case p.pos >= len(p.path):
+			return "", p.errorf("unclosed '['")
+		case c == quote:
+			p.pos++
+			return s.String(), nil
+		case c < 0x20:
+			return "", p.errorf("invalid member name: control character %q", c)
+		case c == '\\':
+			r, ok := p.escape(quote)
+			if !ok {
+				return "", p.errorf("invalid member name %s", p.path[start:min(p.pos, len(p.path))])
+			}
+			s.WriteRune(r)
+		default:
+			r, size := utf8.DecodeRuneInString(p.path[p.pos:])
+			s.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// escape decodes the escape sequence at p.pos, joining a surrogate pair
+// written as two \u escapes
+func (p *queryParser) escape(quote byte) (rune, bool) {
+	if p.pos+1 >= len(p.path) {
+		return 0, false
+	}
+	c := p.path[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		return '\b', true
+	case 'f':
+		return '\f', true
+	case 'n':
+		return '\n', true
+	case 'r':
+		return '\r', true
+	case 't':
+		return '\t', true
+	case '/', '\\', quote:
+		return rune(c), true
+	case 'u':
+		r, ok := p.hex4()
+		if !ok || r >= 0xDC00 && r <= 0xDFFF {
+			return 0, false
+		}
+		if !utf16.IsSurrogate(r) {
+			return r, true
+		}
+		if !strings.HasPrefix(p.path[p.pos:], `\u`) {
+			return 0, false
+		}
+		p.pos += 2
+		low, ok := p.hex4()
+		if r = utf16.DecodeRune(r, low); !ok || r == utf8.RuneError {
+			return 0, false
+		}
+		return r, true
+	}
+	return 0, false
+}
+
+// hex4 reads the four hex digits of a \u escape
+func (p *queryParser) hex4() (rune, bool) {
+	if p.pos+4 > len(p.path) {
+		return 0, false
+	}
+	digits := p.path[p.pos : p.pos+4]
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || strings.ContainsAny(digits, "+-_") {
+		return 0, false
+	}
+	p.pos += 4
+	return rune(n), true
+}
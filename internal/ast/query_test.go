@@ -0,0 +1,296 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// order builds {"items": [...]} with an item per currency, plus an id
+func order(currencies ...string) *Object {
+	items := &Array{}
+	for _, currency := range currencies {
+		items.Elements = append(items.Elements, object("currency", &String{Value: currency}, "qty", number("1")))
+	}
+	return object("id", number("7"), "items", items)
+}
+
+func TestQuery(t *testing.T) {
+	doc := order("USD", "GBP")
+	doc.Set("a'b", &Null{})
+
+	tests := []struct {
+		path     string
+		pointers []string
+	}{
+		{"$", []string{""}},
+		{"$.items[*].currency", []string{"/items/0/currency", "/items/1/currency"}},
+		{"$['items'][1]", []string{"/items/1"}},
+		{"$.items.*.qty", []string{"/items/0/qty", "/items/1/qty"}},
+		{`$["a'b"]`, []string{"/a'b"}},
+		{`$['a\'b']`, []string{"/a'b"}},
+		{"$.*", []string{"/id", "/items", "/a'b"}},
+		{"$.items[2]", nil},
+		{"$.missing.currency", nil},
+		{"$.id.currency", nil},
+	}
+	for _, tt := range tests {
+		results, err := Query(doc, tt.path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.path, err)
+		}
+		var pointers []string
+		for _, r := range results {
+			pointers = append(pointers, r.Pointer)
+			if found, err := Lookup(doc, r.Pointer); err != nil || found != r.Value {
+				t.Errorf("%s: expected %s to point at the match", tt.path, r.Pointer)
+			}
+		}
+		if !reflect.DeepEqual(pointers, tt.pointers) {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.pointers, pointers)
+		}
+	}
+}
+
+func TestQuery_Selectors(t *testing.T) {
+	// Examples of RFC 9535 sections 2.3.3 to 2.5.2
+	letters := decode(t, `["a", "b", "c", "d", "e", "f", "g"]`)
+	tree := decode(t, `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`)
+
+	tests := []struct {
+		doc      Value
+		path     string
+		pointers []string
+	}{
+		{letters, "$[1]", []string{"/1"}},
+		{letters, "$[-2]", []string{"/5"}},
+		{letters, "$[-8]", []string{}},
+		{letters, "$[1:3]", []string{"/1", "/2"}},
+		{letters, "$[5:]", []string{"/5", "/6"}},
+		{letters, "$[1:5:2]", []string{"/1", "/3"}},
+		{letters, "$[5:1:-2]", []string{"/5", "/3"}},
+		{letters, "$[::-1]", []string{"/6", "/5", "/4", "/3", "/2", "/1", "/0"}},
+		{letters, "$[-2:]", []string{"/5", "/6"}},
+		{letters, "$[ 0 : 10 : 3 ]", []string{"/0", "/3", "/6"}},
+		{letters, "$[::0]", []string{}},
+		{letters, "$[0, 3]", []string{"/0", "/3"}},
+		{letters, "$[0:2, 5]", []string{"/0", "/1", "/5"}},
+		{letters, "$[0,0]", []string{"/0", "/0"}},
+		{tree, "$['o', 'a'][0]", []string{"/a/0"}},
+		{tree, "$..j", []string{"/o/j", "/a/2/0/j"}},
+		{tree, "$..[0]", []string{"/a/0", "/a/2/0"}},
+		{tree, "$..*", []string{"/o", "/a", "/o/j", "/o/k", "/a/0", "/a/1", "/a/2", "/a/2/0", "/a/2/1", "/a/2/0/j", "/a/2/1/k"}},
+		{tree, "$.o..[*, *]", []string{"/o/j", "/o/k", "/o/j", "/o/k"}},
+		{tree, "$.a..[0, 1]", []string{"/a/0", "/a/1", "/a/2/0", "/a/2/1"}},
+		{tree, "$ .o ['j']", []string{"/o/j"}},
+		{tree, "$.o.é", []string{}},
+	}
+	for _, tt := range tests {
+		if got := pointersOf(t, tt.doc, tt.path); !reflect.DeepEqual(got, tt.pointers) {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.pointers, got)
+		}
+	}
+}
+
+func TestQuery_Names(t *testing.T) {
+	doc := object("a\tb", number("1"), "\U0001D11E", number("2"), "/", number("3"))
+	tests := map[string]string{
+		`$['a\tb']`:         "/a\tb",
+		`$["\uD834\uDD1E"]`: "/\U0001D11E",
+		`$['\/']`:           "/~1",
+		`$["\u002f"]`:       "/~1",
+	}
+	for path, want := range tests {
+		if got := pointersOf(t, doc, path); len(got) != 1 || got[0] != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	tests := map[string]string{
+		"items":               "must start with '$'",
+		"$.":                  "empty member name",
+		"$.items[":            "unclosed '['",
+		"$[01]":               "invalid array index",
+		"$['a]":               "unclosed '['",
+		`$['\q']`:             "invalid member name",
+		"$items":              "unexpected 'i'",
+		"$.items[-0]":         "invalid array index",
+		"$.items[*]x":         "unexpected 'x'",
+		"$.items[1.5]":        "invalid array index",
+		"$[1 2]":              "invalid array index",
+		"$[1:2:3:4]":          "invalid slice",
+		"$[1:x]":              "invalid slice",
+		"$[9007199254740992]": "invalid array index",
+		"$..":                 "empty member name",
+		"$.1a":                "invalid member name",
+		"$.a ":                "unexpected ' '",
+		`$["\uDD1E"]`:         "invalid member name",
+		`$["\x41"]`:           "invalid member name",
+		`$["a\'b"]`:           "invalid member name",
+		"$[0,]":               "invalid array index",
+	}
+	for path, want := range tests {
+		if _, err := Query(order(), path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", path, want, err)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	doc := order("USD", "GBP", "USD")
+	q, err := Compile(`$.items[?@.currency == 'USD' && match(@.qty, '1')]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := q.String(), `$.items[?@.currency == 'USD' && match(@.qty, '1')]`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	for _, doc := range []Value{doc, order("EUR"), &Null{}} {
+		want, _ := Query(doc, q.String())
+		if got := q.Query(doc); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+
+	if _, err := Compile("$.items["); err == nil || !strings.Contains(err.Error(), "unclosed '['") {
+		t.Errorf("expected an unclosed '[' error, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustCompile to panic")
+		}
+	}()
+	MustCompile("items")
+}
+
+func TestCompiledQuery_Concurrent(t *testing.T) {
+	q := MustCompile(`$..[?search(@.currency, $.id) || @.currency == 'GBP'].qty`)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				doc := order("USD", "GBP", "EUR")
+				if got := len(q.Query(doc)); got != 1 {
+					t.Errorf("expected 1 result, got %d", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// benchmarkDoc has a thousand items to filter
+func benchmarkDoc() *Object {
+	currencies := make([]string, 1000)
+	for i := range currencies {
+		currencies[i] = []string{"USD", "GBP", "EUR"}[i%3]
+	}
+	return order(currencies...)
+}
+
+const benchmarkPath = `$.items[?@.currency == 'GBP' || match(@.currency, 'E.R')].qty`
+
+func BenchmarkQuery(b *testing.B) {
+	doc := benchmarkDoc()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Query(doc, benchmarkPath); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledQuery(b *testing.B) {
+	doc := benchmarkDoc()
+	q := MustCompile(benchmarkPath)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.Query(doc)
+	}
+}
+
+func BenchmarkCompiledQuery_Parallel(b *testing.B) {
+	doc := benchmarkDoc()
+	q := MustCompile(benchmarkPath)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Query(doc)
+		}
+	})
+}
+
+func BenchmarkCompile(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Compile(benchmarkPath); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCursors_Set(t *testing.T) {
+	doc := order("USD", "GBP", "USD")
+	results, err := Query(doc, "$.items[*].currency")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := results.Set(&String{Value: "EUR"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"id":7,"items":[{"currency":"EUR","qty":1},{"currency":"EUR","qty":1},{"currency":"EUR","qty":1}]}`
+	if got := stringOf(doc); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	values := results.Values()
+	if values[0] == values[1] {
+		t.Errorf("expected every match to get its own copy")
+	}
+
+	// A stale cursor leaves the document untouched
+	items := doc.Pairs["items"].(*Array)
+	results, _ = Query(doc, "$.items[*].qty")
+	items.Elements[2].(*Object).Set("qty", number("5"))
+	if err := results.Set(number("0")); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("expected a stale cursor error, got %v", err)
+	}
+	if got := stringOf(items.Elements[0].(*Object).Pairs["qty"]); got != "1" {
+		t.Errorf("expected no match to change, got qty %s", got)
+	}
+
+	root, _ := Query(doc, "$")
+	if err := root.Set(&Null{}); err == nil {
+		t.Errorf("expected the root to be refused")
+	}
+}
+
+func TestCursors_Delete(t *testing.T) {
+	doc := order("USD", "GBP", "USD")
+	results, _ := Query(doc, "$.items[*]")
+	if err := results[:2].Delete(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stringOf(doc); got != `{"id":7,"items":[{"currency":"USD","qty":1}]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+
+	// Out of document order and with a repeat, each element still goes once
+	doc = order("USD", "GBP", "EUR", "JPY")
+	results, _ = Query(doc, "$.items[3, 0, 3, 1].currency")
+	if err := results.Set(&String{Value: "CHF"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, _ = Query(doc, "$.items[2, 0, 2]")
+	if err := results.Delete(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stringOf(doc); got != `{"id":7,"items":[{"currency":"CHF","qty":1},{"currency":"CHF","qty":1}]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package ast
+
+// Pos is a position in the source text
+type Pos struct {
+	Offset int64 // Byte offset, starting at 0
+	Line   int   // Line number, starting at 1
+	Column int   // Column number in characters, starting at 1
+}
+
+// Span is the source text a node was parsed from. End is the position just
+// past its last character. Spans are only recorded when the parser is asked
+// to, and are zero for nodes built in code.
+type Span struct {
+	Start, End Pos
+}
+
+// IsValid reports whether the span was recorded
+func (s Span) IsValid() bool {
+	return s.Start.Line > 0
+}
+
+// SpanOf returns the span of v, or the zero Span for values of other types
+func SpanOf(v Value) Span {
+	switch n := v.(type) {
+	case *Object:
+		return n.Span
+	case *Array:
+		return n.Span
+	case *String:
+		return n.Span
+	case *Number:
+		return n.Span
+	case *Boolean:
+		return n.Span
+	case *Null:
+		return n.Span
+	case *BadValue:
+		return n.Span
+	}
+	return Span{}
+}
+
+// SetSpan records span on v; values of other types are left alone
+func SetSpan(v Value, span Span) {
+	switch n := v.(type) {
+	case *Object:
+		n.Span = span
+	case *Array:
+		n.Span = span
+	case *String:
+		n.Span = span
+	case *Number:
+		n.Span = span
+	case *Boolean:
+		n.Span = span
+	case *Null:
+		n.Span = span
+	case *BadValue:
+		n.Span = span
+	}
+}
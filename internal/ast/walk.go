@@ -0,0 +1,47 @@
+package ast
+
+// Visitor is called by Walk for every node. If Visit returns a non-nil
+// Visitor w, Walk visits each child of the node with w, then calls
+// w.Visit(nil).
+type Visitor interface {
+	Visit(node Value) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order: it calls
+// v.Visit(node), then walks array elements and object values in order with
+// the returned visitor.
+func Walk(v Visitor, node Value) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Object:
+		for _, key := range n.OrderedKeys() {
+			Walk(v, n.Pairs[key])
+		}
+	case *Array:
+		for _, element := range n.Elements {
+			Walk(v, element)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+// inspector adapts a function to the Visitor interface
+type inspector func(Value) bool
+
+func (f inspector) Visit(node Value) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node like Walk, calling f(node) for
+// every node. If f returns true, Inspect visits the children of the node and
+// then calls f(nil).
+func Inspect(node Value, f func(Value) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+// recorder logs the nodes it visits, skipping the children of objects with a "skip" key
+type recorder struct {
+	log *[]string
+}
+
+func (r recorder) Visit(node Value) Visitor {
+	if node == nil {
+		*r.log = append(*r.log, "end")
+		return nil
+	}
+	*r.log = append(*r.log, stringOf(node))
+	if obj, ok := node.(*Object); ok {
+		if _, skip := obj.Pairs["skip"]; skip {
+			return nil
+		}
+	}
+	return r
+}
+
+func TestWalk(t *testing.T) {
+	doc := &Object{Pairs: map[string]Value{
+		"b": &Array{Elements: []Value{&Number{Value: "1"}, &Null{}}},
+		"a": &String{Value: "x"},
+		"c": &Object{Pairs: map[string]Value{"skip": &Boolean{Value: "true"}}},
+	}}
+
+	var log []string
+	Walk(recorder{&log}, doc)
+	expected := []string{
+		`{"a":"x","b":[1,null],"c":{"skip":true}}`,
+		`"x"`, "end",
+		`[1,null]`, `1`, "end", `null`, "end", "end",
+		`{"skip":true}`,
+		"end",
+	}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected visits %q, got %q", expected, log)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	doc := &Array{Elements: []Value{
+		&Number{Value: "1"},
+		&Object{Pairs: map[string]Value{"n": &Number{Value: "2"}}},
+		&Array{Elements: []Value{&Number{Value: "3"}}},
+	}}
+
+	var numbers []string
+	ends := 0
+	Inspect(doc, func(node Value) bool {
+		switch n := node.(type) {
+		case nil:
+			ends++
+		case *Number:
+			numbers = append(numbers, n.Value)
+		case *Array:
+			return len(n.Elements) != 1 // prune the single-element array
+		}
+		return true
+	})
+
+	if !reflect.DeepEqual(numbers, []string{"1", "2"}) {
+		t.Errorf("expected numbers [1 2], got %v", numbers)
+	}
+	if ends != 4 {
+		t.Errorf("expected 4 end calls, got %d", ends)
+	}
+}
@@ -0,0 +1,143 @@
+// Package chaos corrupts and fragments JSON input to check that decoders fail
+// cleanly: they must return errors instead of panicking or hanging.
+package chaos
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// interestingBytes are substituted into inputs because they change how the lexer branches
+var interestingBytes = []byte{'"', '\\', '{', '}', '[', ']', ',', ':', '-', '.', 'e', 'u', '0', 0x00, 0x1f, 0x7f, 0xc3, 0xff}
+
+// Mutation is one corrupted variant of an input
+type Mutation struct {
+	Name string
+	Data []byte
+}
+
+// Options controls how many variants Check generates
+type Options struct {
+	Seed      int64         // Seed for the pseudo-random generator, so failures are reproducible
+	Mutations int           // Byte flips and substitutions per input; 0 selects 32
+	Timeout   time.Duration // Longest a single decode may take before it counts as a hang; 0 selects 5s
+	// CheckError, when set, is called with every error returned by the decoder
+	// and fails the test when it returns a non-nil error
+	CheckError func(err error) error
+}
+
+// Mutations returns every truncation of input plus random byte flips and substitutions
+func Mutations(input []byte, rng *rand.Rand, n int) []Mutation {
+	var mutations []Mutation
+	for i := 0; i < len(input); i++ {
+		mutations = append(mutations, Mutation{Name: fmt.Sprintf("truncate@%d", i), Data: input[:i]})
+	}
+	if len(input) == 0 {
+		return mutations
+	}
+
+	for i := 0; i < n; i++ {
+		data := append([]byte(nil), input...)
+		offset := rng.Intn(len(data))
+		if i%2 == 0 {
+			bit := byte(1) << uint(rng.Intn(8))
+			data[offset] ^= bit
+			mutations = append(mutations, Mutation{Name: fmt.Sprintf("flip@%d^%#x", offset, bit), Data: data})
+		} else {
+			b := interestingBytes[rng.Intn(len(interestingBytes))]
+			data[offset] = b
+			mutations = append(mutations, Mutation{Name: fmt.Sprintf("set@%d=%#x", offset, b), Data: data})
+		}
+	}
+	return mutations
+}
+
+// SplitReader returns a reader that hands out data in chunks of pseudo-random sizes,
+// including zero-length reads, to exercise buffer refill boundaries
+func SplitReader(data []byte, rng *rand.Rand) io.Reader {
+	return &splitReader{data: data, rng: rng}
+}
+
+type splitReader struct {
+	data []byte
+	rng  *rand.Rand
+}
+
+// Read returns between 0 and 7 bytes
+func (r *splitReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := r.rng.Intn(8)
+	if n > len(p) {
+		n = len(p)
+	}
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Check feeds every mutation of every corpus entry to decode through a SplitReader.
+// The test fails if decode panics, runs longer than the timeout, or returns an error rejected by CheckError.
+func Check(t testing.TB, corpus [][]byte, opts Options, decode func(r io.Reader) error) {
+	t.Helper()
+
+	n := opts.Mutations
+	if n == 0 {
+		n = 32
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	for i, input := range corpus {
+		variants := append([]Mutation{{Name: "original", Data: input}}, Mutations(input, rng, n)...)
+		for _, m := range variants {
+			failure, err := run(SplitReader(m.Data, rand.New(rand.NewSource(rng.Int63()))), timeout, decode)
+			if failure != "" {
+				t.Fatalf("corpus[%d] %s: %s\ninput: %q", i, m.Name, failure, m.Data)
+			}
+			if err != nil && opts.CheckError != nil {
+				if cerr := opts.CheckError(err); cerr != nil {
+					t.Errorf("corpus[%d] %s: unexpected error shape: %v\ninput: %q", i, m.Name, cerr, m.Data)
+				}
+			}
+		}
+	}
+}
+
+// run calls decode in its own goroutine and reports a panic or a timeout as failure
+func run(r io.Reader, timeout time.Duration, decode func(r io.Reader) error) (failure string, err error) {
+	type result struct {
+		err      error
+		panicked interface{}
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{panicked: p}
+			}
+		}()
+		done <- result{err: decode(r)}
+	}()
+
+	select {
+	case res := <-done:
+		if res.panicked != nil {
+			return fmt.Sprintf("decoder panicked: %v", res.panicked), nil
+		}
+		return "", res.err
+	case <-time.After(timeout):
+		return fmt.Sprintf("decoder did not return within %v", timeout), nil
+	}
+}
@@ -0,0 +1,77 @@
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTB captures failures instead of failing the surrounding test
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestSplitReader_ReassemblesInput(t *testing.T) {
+	input := []byte(`{"key": "value", "list": [1, 2, 3]}`)
+	got, err := io.ReadAll(SplitReader(input, rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != string(input) {
+		t.Errorf("expected %q, got %q", input, got)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	input := []byte(`[1,2]`)
+	mutations := Mutations(input, rand.New(rand.NewSource(1)), 10)
+	if len(mutations) != len(input)+10 {
+		t.Fatalf("expected %d mutations, got %d", len(input)+10, len(mutations))
+	}
+	if string(mutations[0].Data) != "" || string(mutations[4].Data) != "[1,2" {
+		t.Errorf("expected truncations first, got %q and %q", mutations[0].Data, mutations[4].Data)
+	}
+	if string(input) != `[1,2]` {
+		t.Errorf("expected input to be left unmodified, got %q", input)
+	}
+}
+
+func TestCheck_ReportsPanicsHangsAndErrors(t *testing.T) {
+	corpus := [][]byte{[]byte(`{}`)}
+
+	panicking := &recordingTB{TB: t}
+	Check(panicking, corpus, Options{}, func(r io.Reader) error { panic("boom") })
+	if len(panicking.failures) == 0 || !strings.Contains(panicking.failures[0], "panicked: boom") {
+		t.Errorf("expected a panic to be reported, got %v", panicking.failures)
+	}
+
+	hanging := &recordingTB{TB: t}
+	Check(hanging, corpus, Options{Timeout: 10 * time.Millisecond}, func(r io.Reader) error {
+		select {}
+	})
+	if len(hanging.failures) == 0 || !strings.Contains(hanging.failures[0], "did not return") {
+		t.Errorf("expected a hang to be reported, got %v", hanging.failures)
+	}
+
+	untyped := &recordingTB{TB: t}
+	opts := Options{CheckError: func(err error) error { return errors.New("not typed") }}
+	Check(untyped, corpus, opts, func(r io.Reader) error { return errors.New("plain") })
+	if len(untyped.failures) == 0 || !strings.Contains(untyped.failures[0], "not typed") {
+		t.Errorf("expected the error check to be reported, got %v", untyped.failures)
+	}
+}
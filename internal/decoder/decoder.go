@@ -0,0 +1,364 @@
+package decoder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/keydict"
+)
+
+// numberType is decoded from number literals verbatim, preserving digits float64 would lose
+var numberType = reflect.TypeOf(ast.Number{})
+
+// Options controls how parsed values are stored into Go values
+type Options struct {
+	// UseNumber decodes numbers into interface{} as *ast.Number instead of float64
+	UseNumber bool
+	// OnPrecisionLoss, when set, is called for every number that is rounded while
+	// being stored in a float or in interface{}
+	OnPrecisionLoss func(path, literal string)
+	// KeyDictionary expands a document written in the keydict form before decoding it
+	KeyDictionary bool
+	// Path locates the value in error messages and OnPrecisionLoss calls, "$" when empty
+	Path string
+}
+
+// decodeState carries the options through a single Decode call
+type decodeState struct {
+	opts Options
+}
+
+// Decode stores the parsed value in the Go value pointed to by v using the default options.
+// Struct fields are matched by their `json` tag name, or by field name ignoring case.
+func Decode(value ast.Value, v interface{}) error {
+	return Options{}.Decode(value, v)
+}
+
+// Decode stores the parsed value in the Go value pointed to by v
+func (o Options) Decode(value ast.Value, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", v)
+	}
+	if o.KeyDictionary {
+		expanded, err := keydict.Expand(value)
+		if err != nil {
+			return err
+		}
+		value = expanded
+	}
+	path := o.Path
+	if path == "" {
+		path = "$"
+	}
+	d := &decodeState{opts: o}
+	return d.decodeValue(path, value, rv.Elem())
+}
+
+// decodeValue stores value into dst, path locates value in the document for error messages
+func (d *decodeState) decodeValue(path string, value ast.Value, dst reflect.Value) error {
+	if _, ok := value.(*ast.Null); ok {
+		switch dst.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		// Like encoding/json, null leaves other kinds untouched
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.decodeValue(path, value, dst.Elem())
+	}
+
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		native, err := d.toInterface(path, value)
+		if err != nil {
+			return fmt.Errorf("cannot decode %s: %v", path, err)
+		}
+		dst.Set(reflect.ValueOf(native))
+		return nil
+	}
+
+	if dst.Type() == numberType {
+		n, ok := value.(*ast.Number)
+		if !ok {
+			return typeError(path, ast.TypeName(value), dst.Type())
+		}
+		dst.Set(reflect.ValueOf(*n))
+		return nil
+	}
+
+	switch v := value.(type) {
+	case *ast.Object:
+		return d.decodeObject(path, v, dst)
+	case *ast.Array:
+		return d.decodeArray(path, v, dst)
+	case *ast.String:
+		if dst.Kind() != reflect.String {
+			return typeError(path, "string", dst.Type())
+		}
+		dst.SetString(v.Value)
+		return nil
+	case *ast.Boolean:
+		if dst.Kind() != reflect.Bool {
+			return typeError(path, "boolean", dst.Type())
+		}
+		dst.SetBool(v.Value == "true")
+		return nil
+	case *ast.Number:
+		return d.decodeNumber(path, v, dst)
+	default:
+		return fmt.Errorf("cannot decode %s: unsupported AST node %T", path, value)
+	}
+}
+
+// decodeNumber converts a number literal into a numeric Go value
+func (d *decodeState) decodeNumber(path string, n *ast.Number, dst reflect.Value) error {
+	literal := n.Value
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(literal, 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot decode number %s at %s into Go value of type %s", literal, path, dst.Type())
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(literal, 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot decode number %s at %s into Go value of type %s", literal, path, dst.Type())
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(literal, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot decode number %s at %s into Go value of type %s", literal, path, dst.Type())
+		}
+		d.checkPrecision(path, n, dst.Type().Bits())
+		dst.SetFloat(f)
+	default:
+		return typeError(path, "number", dst.Type())
+	}
+	return nil
+}
+
+// decodeArray fills a slice or array from the array elements
+func (d *decodeState) decodeArray(path string, array *ast.Array, dst reflect.Value) error {
+	switch dst.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(dst.Type(), len(array.Elements), len(array.Elements))
+		for i, element := range array.Elements {
+			if err := d.decodeValue(fmt.Sprintf("%s[%d]", path, i), element, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Array:
+		for i := 0; i < dst.Len(); i++ {
+			if i >= len(array.Elements) {
+				// Like encoding/json, missing elements are zeroed
+				dst.Index(i).Set(reflect.Zero(dst.Type().Elem()))
+				continue
+			}
+			if err := d.decodeValue(fmt.Sprintf("%s[%d]", path, i), array.Elements[i], dst.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return typeError(path, "array", dst.Type())
+	}
+	return nil
+}
+
+// decodeObject fills a struct or a string-keyed map from the object pairs
+func (d *decodeState) decodeObject(path string, obj *ast.Object, dst reflect.Value) error {
+	switch dst.Kind() {
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot decode object at %s into map with %s keys", path, dst.Type().Key())
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(obj.Pairs)))
+		}
+		elemType := dst.Type().Elem()
+		for key, value := range obj.Pairs {
+			elem := reflect.New(elemType).Elem()
+			if err := d.decodeValue(path+"."+key, value, elem); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+	case reflect.Struct:
+		fields := structFields(dst.Type())
+		for _, key := range obj.OrderedKeys() {
+			value := obj.Pairs[key]
+			field, ok := fields.lookup(key)
+			if !ok {
+				continue // Unknown keys are ignored
+			}
+			if err := d.decodeValue(path+"."+key, value, fieldByIndex(dst, field.index)); err != nil {
+				return err
+			}
+		}
+	default:
+		return typeError(path, "object", dst.Type())
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field, allocating nil embedded struct pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// checkPrecision reports numbers that do not survive conversion to a float of the given size
+func (d *decodeState) checkPrecision(path string, n *ast.Number, bits int) {
+	if d.opts.OnPrecisionLoss == nil {
+		return
+	}
+	exact := n.ExactFloat64()
+	if bits == 32 {
+		exact = n.ExactFloat32()
+	}
+	if !exact {
+		d.opts.OnPrecisionLoss(path, n.Value)
+	}
+}
+
+// typeError reports a JSON value that cannot be stored in the Go type
+func typeError(path, kind string, t reflect.Type) error {
+	return fmt.Errorf("cannot decode %s at %s into Go value of type %s", kind, path, t)
+}
+
+// toInterface converts a value into plain Go data: map[string]interface{}, []interface{},
+// float64 (or *ast.Number with UseNumber), string, bool or nil
+func (d *decodeState) toInterface(path string, value ast.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case *ast.Object:
+		m := make(map[string]interface{}, len(v.Pairs))
+		for key, child := range v.Pairs {
+			native, err := d.toInterface(path+"."+key, child)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = native
+		}
+		return m, nil
+	case *ast.Array:
+		s := make([]interface{}, len(v.Elements))
+		for i, child := range v.Elements {
+			native, err := d.toInterface(fmt.Sprintf("%s[%d]", path, i), child)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = native
+		}
+		return s, nil
+	case *ast.String:
+		return v.Value, nil
+	case *ast.Number:
+		if d.opts.UseNumber {
+			return &ast.Number{Value: v.Value}, nil
+		}
+		d.checkPrecision(path, v, 64)
+		return v.Float64()
+	case *ast.Boolean:
+		return v.Value == "true", nil
+	case *ast.Null:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported AST node %T", value)
+	}
+}
+
+// field describes a struct field that can receive an object key
+type field struct {
+	name  string
+	index []int
+}
+
+// fieldSet holds the decodable fields of a struct type
+type fieldSet struct {
+	exact map[string]field
+	fold  map[string]field
+}
+
+// lookup finds the field for key, preferring an exact match over a case-insensitive one
+func (fs fieldSet) lookup(key string) (field, bool) {
+	if f, ok := fs.exact[key]; ok {
+		return f, true
+	}
+	f, ok := fs.fold[strings.ToLower(key)]
+	return f, ok
+}
+
+// structFields collects the exported fields of t, promoting fields of embedded structs
+func structFields(t reflect.Type) fieldSet {
+	fs := fieldSet{exact: make(map[string]field), fold: make(map[string]field)}
+	collectFields(t, nil, fs)
+	return fs
+}
+
+// collectFields adds the fields of t to fs; outer fields win over promoted ones
+func collectFields(t reflect.Type, index []int, fs fieldSet) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				if !sf.IsExported() {
+					continue // An unexported pointer cannot be allocated through reflection
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, sf)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{name: name, index: append(append([]int(nil), index...), i)}
+		if _, ok := fs.exact[name]; !ok {
+			fs.exact[name] = f
+		}
+		if _, ok := fs.fold[strings.ToLower(name)]; !ok {
+			fs.fold[strings.ToLower(name)] = f
+		}
+	}
+
+	for _, sf := range embedded {
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		collectFields(ft, append(append([]int(nil), index...), sf.Index...), fs)
+	}
+}
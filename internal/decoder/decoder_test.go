@@ -0,0 +1,174 @@
+package decoder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+type Base struct {
+	ID int `json:"id"`
+}
+
+type person struct {
+	Base
+	Name     string            `json:"name"`
+	Age      uint8             `json:"age"`
+	Score    float64           `json:"score"`
+	Admin    bool              `json:"admin"`
+	Tags     []string          `json:"tags"`
+	Pair     [2]int            `json:"pair"`
+	Labels   map[string]string `json:"labels"`
+	Spouse   *person           `json:"spouse"`
+	Extra    interface{}       `json:"extra"`
+	Nickname string
+	Ignored  string `json:"-"`
+	private  string
+}
+
+func TestDecode_Struct(t *testing.T) {
+	input := `{
+		"id": 7,
+		"name": "Ada",
+		"age": 36,
+		"score": 9.5,
+		"admin": true,
+		"tags": ["math", "code"],
+		"pair": [1],
+		"labels": {"team": "engines"},
+		"spouse": {"name": "William"},
+		"extra": {"list": [1, "two", null, false]},
+		"NICKNAME": "Countess",
+		"Ignored": "nope",
+		"private": "nope",
+		"unknown": "skipped"
+	}`
+
+	var got person
+	if err := Decode(mustParse(t, input), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := person{
+		Base:     Base{ID: 7},
+		Name:     "Ada",
+		Age:      36,
+		Score:    9.5,
+		Admin:    true,
+		Tags:     []string{"math", "code"},
+		Pair:     [2]int{1, 0},
+		Labels:   map[string]string{"team": "engines"},
+		Spouse:   &person{Name: "William"},
+		Extra:    map[string]interface{}{"list": []interface{}{1.0, "two", nil, false}},
+		Nickname: "Countess",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDecode_NullResetsReferences(t *testing.T) {
+	got := struct {
+		Ptr   *int
+		Slice []int
+		Num   int
+	}{Ptr: new(int), Slice: []int{1}, Num: 5}
+
+	if err := Decode(mustParse(t, `{"Ptr": null, "Slice": null, "Num": null}`), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Ptr != nil || got.Slice != nil || got.Num != 5 {
+		t.Errorf("expected nil pointer, nil slice and untouched int, got %+v", got)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		input  string
+		target interface{}
+		want   string
+	}{
+		{`"x"`, new(int), "cannot decode string at $"},
+		{`{"a": [1, "b"]}`, new(map[string][]int), "at $.a[1]"},
+		{`300`, new(uint8), "cannot decode number 300"},
+		{`1.5`, new(int), "cannot decode number 1.5"},
+		{`{}`, new(map[int]string), "map with int keys"},
+		{`[]`, new(struct{}), "cannot decode array"},
+	}
+
+	for _, tt := range tests {
+		err := Decode(mustParse(t, tt.input), tt.target)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+
+	opts := Options{Path: "$.items[2]"}
+	if err := opts.Decode(mustParse(t, `{"qty": "x"}`), new(struct{ Qty int })); err == nil || !strings.Contains(err.Error(), "at $.items[2].qty") {
+		t.Errorf("expected an error located under Path, got %v", err)
+	}
+
+	var n int
+	if err := Decode(mustParse(t, `1`), n); err == nil {
+		t.Errorf("expected error for non-pointer target")
+	}
+}
+
+func TestDecode_NumberTargets(t *testing.T) {
+	var got struct {
+		Exact ast.Number  `json:"exact"`
+		Ptr   *ast.Number `json:"ptr"`
+	}
+	if err := Decode(mustParse(t, `{"exact": 18446744073709551617, "ptr": -1.50}`), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Exact.Value != "18446744073709551617" || got.Ptr == nil || got.Ptr.Value != "-1.50" {
+		t.Errorf("expected literals to be kept verbatim, got %+v", got)
+	}
+
+	if err := Decode(mustParse(t, `{"exact": "1"}`), &got); err == nil {
+		t.Errorf("expected error decoding a string into ast.Number")
+	}
+}
+
+func TestOptions_OnPrecisionLoss(t *testing.T) {
+	var paths []string
+	opts := Options{OnPrecisionLoss: func(path, literal string) { paths = append(paths, path) }}
+
+	var got struct {
+		F64 float64 `json:"f64"`
+		F32 float32 `json:"f32"`
+		Any interface{}
+	}
+	input := `{"f64": 9007199254740993, "f32": 16777217, "any": {"n": [1, 12345678901234567890]}}`
+	if err := opts.Decode(mustParse(t, input), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]bool{"$.f64": true, "$.f32": true, "$.any.n[1]": true}
+	if len(paths) != len(want) {
+		t.Fatalf("expected warnings for %v, got %v", want, paths)
+	}
+	for _, p := range paths {
+		if !want[p] {
+			t.Errorf("unexpected precision warning at %s", p)
+		}
+	}
+}
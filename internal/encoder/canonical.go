@@ -0,0 +1,85 @@
+package encoder
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// canonicalize rewrites encoded JSON in the JSON Canonicalization Scheme of
+// RFC 8785: no whitespace, object keys sorted by their UTF-16 code units,
+// numbers written as ECMAScript writes doubles, and strings escaped only
+// where JSON requires it
+func canonicalize(data []byte) ([]byte, error) {
+	tokens, err := lexer.NewBytesLexer(data).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	p := parser.NewParser(tokens)
+	p.SetOptions(parser.Options{MaxDepth: -1}) // the depth was already bounded by the Go value
+	value, err := p.ParseDocument()
+	if err != nil {
+		return nil, err
+	}
+	return appendCanonical(nil, value)
+}
+
+func appendCanonical(dst []byte, v ast.Value) ([]byte, error) {
+	switch v := v.(type) {
+	case *ast.Object:
+		keys := v.OrderedKeys()
+		units := make(map[string][]uint16, len(keys))
+		for _, key := range keys {
+			units[key] = utf16.Encode([]rune(key))
+		}
+		sort.Slice(keys, func(i, j int) bool { return slices.Compare(units[keys[i]], units[keys[j]]) < 0 })
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(ast.AppendQuoted(dst, key), ':')
+			var err error
+			if dst, err = appendCanonical(dst, v.Pairs[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case *ast.Array:
+		dst = append(dst, '[')
+		for i, elem := range v.Elements {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			var err error
+			if dst, err = appendCanonical(dst, elem); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case *ast.Number:
+		// RFC 8785 writes numbers as IEEE doubles, which not every literal fits
+		f, err := strconv.ParseFloat(v.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("canonical form: number %s is out of range of a float64", v.Value)
+		}
+		if f == 0 {
+			f = 0 // ECMAScript writes negative zero as 0
+		}
+		// The default format is ECMAScript's, which RFC 8785 adopts
+		s, err := FormatFloat(f, FloatFormat{})
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, s...), nil
+	case *ast.String:
+		return ast.AppendQuoted(dst, v.Value), nil
+	}
+	return ast.AppendJSON(dst, v)
+}
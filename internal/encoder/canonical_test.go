@@ -0,0 +1,78 @@
+package encoder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func TestOptions_Canonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			// RFC 8785 section 3.2.2
+			"rfc example",
+			`{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			  "literals": [null, true, false]}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 section 3.2.3: keys are sorted by UTF-16 code units
+			"key order",
+			`{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh",
+			  "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{"nested", `{"b": [{"z": 1, "a": 2}], "a": {}}`, `{"a":{},"b":[{"a":2,"z":1}]}`},
+		{"numbers", `[-0, 1.0, 100, 1e21, 1e20, 0.000001, 1e-7, -1.5e300, 9007199254740993]`, `[0,1,100,1e+21,100000000000000000000,0.000001,1e-7,-1.5e+300,9007199254740992]`},
+		{"control characters", `" \u0001\u001F"`, `" \u0001\u001f"`},
+		{"line separators stay literal", `"\u2028"`, "\"\u2028\""},
+	}
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.input).Tokenize()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		value, err := parser.ParseValue(tokens)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got, err := Options{Canonical: true}.Marshal(value)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOptions_CanonicalGoValues(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	got, err := Options{Canonical: true}.Marshal(map[string]any{"items": []item{{"b", 2.50}}, "ID": 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"ID":7,"items":[{"name":"b","price":2.5}]}`; string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestOptions_CanonicalErrors(t *testing.T) {
+	value := &ast.Array{Elements: []ast.Value{&ast.Number{Value: "1e400"}}}
+	_, err := Options{Canonical: true}.Marshal(value)
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error, got %v", err)
+	}
+}
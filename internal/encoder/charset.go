@@ -0,0 +1,55 @@
+package encoder
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Charset selects the characters the output is written in
+type Charset int
+
+// Charsets
+const (
+	CharsetUTF8   Charset = iota // UTF-8, with non-ASCII characters written as they are
+	CharsetASCII                 // ASCII, escaping every other character in UTF-16 as \uXXXX, astral ones as a surrogate pair
+	CharsetLatin1                // ISO-8859-1, writing U+0080 to U+00FF as single bytes and escaping the rest as CharsetASCII does
+)
+
+// transcode rewrites UTF-8 JSON text in charset. Outside strings JSON text
+// is ASCII, so only string contents change.
+func transcode(data []byte, charset Charset) []byte {
+	if charset == CharsetUTF8 {
+		return data
+	}
+	i := 0
+	for i < len(data) && data[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(data) {
+		return data
+	}
+
+	const hex = "0123456789abcdef"
+	out := make([]byte, i, len(data)+len(data)/2)
+	copy(out, data)
+	for i < len(data) {
+		r, size := utf8.DecodeRune(data[i:])
+		i += size
+		switch {
+		case r < utf8.RuneSelf:
+			out = append(out, byte(r))
+		case charset == CharsetLatin1 && r <= 0xff:
+			out = append(out, byte(r))
+		default:
+			units := []rune{r}
+			if r > 0xffff {
+				hi, lo := utf16.EncodeRune(r)
+				units = []rune{hi, lo}
+			}
+			for _, u := range units {
+				out = append(out, '\\', 'u', hex[u>>12&0xf], hex[u>>8&0xf], hex[u>>4&0xf], hex[u&0xf])
+			}
+		}
+	}
+	return out
+}
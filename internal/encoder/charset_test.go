@@ -0,0 +1,48 @@
+package encoder
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTranscode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		charset Charset
+		want    string
+	}{
+		{"ascii unchanged", `{"a":"b"}`, CharsetASCII, `{"a":"b"}`},
+		{"utf8 unchanged", `"é😀"`, CharsetUTF8, `"é😀"`},
+		{"ascii latin", `{"café":"naïve"}`, CharsetASCII, `{"caf\u00e9":"na\u00efve"}`},
+		{"ascii bmp", `"€ 中"`, CharsetASCII, `"\u20ac \u4e2d"`},
+		{"ascii astral", `"😀𝄞"`, CharsetASCII, `"\ud83d\ude00\ud834\udd1e"`},
+		{"ascii last bmp", "\"\uffff\U00010000\"", CharsetASCII, `"\uffff\ud800\udc00"`},
+		{"latin1", `"é€"`, CharsetLatin1, "\"\xe9\\u20ac\""},
+		{"latin1 astral", `["ÿ","😀"]`, CharsetLatin1, "[\"\xff\",\"\\ud83d\\ude00\"]"},
+	}
+	for _, tt := range tests {
+		if got := string(transcode([]byte(tt.input), tt.charset)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarshal_Charset(t *testing.T) {
+	v := map[string]string{"name": "Zoë 😀"}
+	got, err := Options{Charset: CharsetASCII}.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != `{"name":"Zo\u00eb \ud83d\ude00"}` {
+		t.Errorf("got %s", got)
+	}
+
+	got, err = Options{Charset: CharsetLatin1}.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "{\"name\":\"Zo\xeb \\ud83d\\ude00\"}" || utf8.Valid(got) {
+		t.Errorf("got %q, want Latin-1 bytes", got)
+	}
+}
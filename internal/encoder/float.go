@@ -0,0 +1,97 @@
+package encoder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FloatMode selects how many digits a float is written with
+type FloatMode int
+
+// Float modes
+const (
+	FloatShortest FloatMode = iota // Fewest digits that parse back to the same float64
+	FloatFixed                     // Exactly Precision digits after the decimal point
+)
+
+// Default exponent thresholds, matching ECMAScript and encoding/json
+const (
+	DefaultExponentAbove = 1e21
+	DefaultExponentBelow = 1e-6
+)
+
+// FloatFormat controls the text produced for floating point numbers.
+// The zero value writes the shortest round-trip form with the default exponent thresholds.
+type FloatFormat struct {
+	Mode          FloatMode
+	Precision     int     // Digits after the decimal point in FloatFixed mode
+	ExponentAbove float64 // Use exponent notation when |f| >= ExponentAbove; 0 selects DefaultExponentAbove
+	ExponentBelow float64 // Use exponent notation when 0 < |f| < ExponentBelow; 0 selects DefaultExponentBelow
+	TrailingZero  bool    // Write integral floats as "1.0" instead of "1"
+	NonFinite     bool    // Write NaN, Infinity and -Infinity, which JSON has no numbers for, instead of failing
+}
+
+// FormatFloat renders f as a JSON number according to format
+func FormatFloat(f float64, format FloatFormat) (string, error) {
+	switch {
+	case format.NonFinite && math.IsNaN(f):
+		return "NaN", nil
+	case format.NonFinite && math.IsInf(f, 1):
+		return "Infinity", nil
+	case format.NonFinite && math.IsInf(f, -1):
+		return "-Infinity", nil
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		return "", fmt.Errorf("unsupported float value: %v", f)
+	}
+	if format.Precision < 0 {
+		return "", fmt.Errorf("invalid float precision: %d", format.Precision)
+	}
+
+	above := format.ExponentAbove
+	if above == 0 {
+		above = DefaultExponentAbove
+	}
+	below := format.ExponentBelow
+	if below == 0 {
+		below = DefaultExponentBelow
+	}
+
+	precision := -1
+	if format.Mode == FloatFixed {
+		precision = format.Precision
+	}
+
+	abs := math.Abs(f)
+	verb := byte('f')
+	if abs != 0 && (abs >= above || abs < below) {
+		verb = 'e'
+	}
+
+	s := strconv.FormatFloat(f, verb, precision, 64)
+	if verb == 'e' {
+		s = trimExponent(s)
+	}
+
+	if format.TrailingZero && !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	if f != 0 && (s == "-0" || strings.HasPrefix(s, "-0.") && strings.Trim(s[3:], "0") == "") {
+		s = s[1:] // a negative number rounded to zero is written as zero; only -0 itself keeps its sign
+	}
+
+	return s, nil
+}
+
+// trimExponent shortens strconv's exponent form, e.g. "1e-07" becomes "1e-7" and "1e+21" stays as is
+func trimExponent(s string) string {
+	i := strings.IndexByte(s, 'e')
+	if i < 0 || len(s) < i+4 {
+		return s
+	}
+	if s[i+2] == '0' {
+		return s[:i+2] + s[i+3:]
+	}
+	return s
+}
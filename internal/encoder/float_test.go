@@ -0,0 +1,67 @@
+package encoder
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		format FloatFormat
+		want   string
+	}{
+		{"shortest", 0.1, FloatFormat{}, "0.1"},
+		{"shortest integral", 3, FloatFormat{}, "3"},
+		{"shortest round trip", 1.0 / 3, FloatFormat{}, "0.3333333333333333"},
+		{"large uses exponent", 1e21, FloatFormat{}, "1e+21"},
+		{"below threshold stays plain", 1e20, FloatFormat{}, "100000000000000000000"},
+		{"small uses exponent", 1e-7, FloatFormat{}, "1e-7"},
+		{"zero", 0, FloatFormat{}, "0"},
+		{"negative zero", math.Copysign(0, -1), FloatFormat{}, "-0"},
+		{"negative zero trailing zero", math.Copysign(0, -1), FloatFormat{TrailingZero: true}, "-0.0"},
+		{"rounded to zero", -0.001, FloatFormat{Mode: FloatFixed, Precision: 2}, "0.00"},
+		{"trailing zero", 3, FloatFormat{TrailingZero: true}, "3.0"},
+		{"trailing zero leaves fractions", 2.5, FloatFormat{TrailingZero: true}, "2.5"},
+		{"fixed precision", math.Pi, FloatFormat{Mode: FloatFixed, Precision: 3}, "3.142"},
+		{"fixed zero precision", 2.5, FloatFormat{Mode: FloatFixed, Precision: 0}, "2"},
+		{"fixed zero precision trailing zero", 2.5, FloatFormat{Mode: FloatFixed, TrailingZero: true}, "2.0"},
+		{"fixed exponent", 12345.678, FloatFormat{Mode: FloatFixed, Precision: 2, ExponentAbove: 1e4}, "1.23e+4"},
+		{"custom lower threshold", 0.001, FloatFormat{ExponentBelow: 0.01}, "1e-3"},
+	}
+
+	for _, tt := range tests {
+		got, err := FormatFloat(tt.value, tt.format)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFormatFloat_NonFinite(t *testing.T) {
+	tests := map[float64]string{math.Inf(1): "Infinity", math.Inf(-1): "-Infinity", 1.5: "1.5"}
+	for f, want := range tests {
+		if got, err := FormatFloat(f, FloatFormat{NonFinite: true}); err != nil || got != want {
+			t.Errorf("FormatFloat(%v) = %q, %v, want %q", f, got, err, want)
+		}
+	}
+	if got, err := FormatFloat(math.NaN(), FloatFormat{NonFinite: true}); err != nil || got != "NaN" {
+		t.Errorf("FormatFloat(NaN) = %q, %v, want NaN", got, err)
+	}
+}
+
+func TestFormatFloat_Errors(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := FormatFloat(f, FloatFormat{}); err == nil {
+			t.Errorf("expected error for %v", f)
+		}
+	}
+	if _, err := FormatFloat(1, FloatFormat{Mode: FloatFixed, Precision: -1}); err == nil {
+		t.Errorf("expected error for negative precision")
+	}
+}
@@ -0,0 +1,481 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/keydict"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// Marshaler is implemented by types that encode themselves as JSON.
+// It has the same shape as encoding/json's Marshaler, so existing implementations work unchanged.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// astNodeTypes are the AST node struct types, which are written as the JSON they represent
+var astNodeTypes = map[reflect.Type]bool{
+	reflect.TypeOf(ast.Object{}):   true,
+	reflect.TypeOf(ast.Array{}):    true,
+	reflect.TypeOf(ast.String{}):   true,
+	reflect.TypeOf(ast.Number{}):   true,
+	reflect.TypeOf(ast.Boolean{}):  true,
+	reflect.TypeOf(ast.Null{}):     true,
+	reflect.TypeOf(ast.BadValue{}): true,
+}
+
+// Options controls how Go values are written as JSON
+type Options struct {
+	Float         FloatFormat // Formatting of float32 and float64 values
+	KeyDictionary bool        // Write the keydict form, moving repeated object keys into a dictionary
+	Canonical     bool        // Write the RFC 8785 canonical form, for hashing and signing
+	Charset       Charset     // Characters to write the output in, escaping the others
+}
+
+// encodeState accumulates the output of a single Marshal call
+type encodeState struct {
+	bytes.Buffer
+	opts Options
+	seen map[seenKey]bool // pointers, maps and slices currently being encoded, to detect cycles
+}
+
+// seenKey identifies a pointer, map or slice being encoded. A slice is
+// known by its length as well as its data, as encoding/json does, since a
+// shorter slice of the same array is a different value.
+type seenKey struct {
+	ptr uintptr
+	len int
+}
+
+// Marshal returns the JSON encoding of v using the default options
+func Marshal(v interface{}) ([]byte, error) {
+	return Options{}.Marshal(v)
+}
+
+// Marshal returns the JSON encoding of v.
+//
+// Structs are written as objects using their `json` tags (supporting "-" and
+// omitempty), maps with string or integer keys are written with sorted keys,
+// []byte is written as a base64 string, and AST nodes are written as the JSON
+// they represent with number literals kept verbatim.
+func (o Options) Marshal(v interface{}) ([]byte, error) {
+	e := &encodeState{opts: o, seen: make(map[seenKey]bool)}
+	if err := e.encode("$", reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	data := e.Bytes()
+	var err error
+	if o.KeyDictionary {
+		if data, err = compressKeys(data); err != nil {
+			return nil, err
+		}
+	}
+	if o.Canonical {
+		if data, err = canonicalize(data); err != nil {
+			return nil, err
+		}
+	}
+	return transcode(data, o.Charset), nil
+}
+
+// compressKeys rewrites encoded JSON into its key dictionary form
+func compressKeys(data []byte) ([]byte, error) {
+	tokens, err := lexer.NewBytesLexer(data).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	p := parser.NewParser(tokens)
+	p.SetOptions(parser.Options{MaxDepth: -1}) // the depth was already bounded by the Go value
+	value, err := p.ParseDocument()
+	if err != nil {
+		return nil, err
+	}
+	return ast.AppendJSON(nil, keydict.Compress(value))
+}
+
+// encode writes v, path locates v in the output for error messages
+func (e *encodeState) encode(path string, v reflect.Value) error {
+	if !v.IsValid() {
+		e.WriteString("null")
+		return nil
+	}
+
+	if node, ok := astNode(v); ok {
+		return e.encodeAST(path, node)
+	}
+
+	if v.Type().Implements(marshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.encodeMarshaler(path, v.Interface().(Marshaler))
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(marshalerType) {
+		return e.encodeMarshaler(path, v.Addr().Interface().(Marshaler))
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Errorf("cannot encode %s: %v", path, err)
+		}
+		writeString(&e.Buffer, string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return e.encodeFloat(path, v.Float(), v.Type().Bits())
+	case reflect.String:
+		writeString(&e.Buffer, v.String())
+	case reflect.Interface:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.encode(path, v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if err := e.enter(path, seenKey{ptr: v.Pointer()}); err != nil {
+			return err
+		}
+		defer e.leave(seenKey{ptr: v.Pointer()})
+		return e.encode(path, v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if err := e.enter(path, seenKey{ptr: v.Pointer()}); err != nil {
+			return err
+		}
+		defer e.leave(seenKey{ptr: v.Pointer()})
+		return e.encodeMap(path, v)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			writeString(&e.Buffer, base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		// A slice can hold itself through an interface, as in s[0] = s
+		key := seenKey{ptr: v.Pointer(), len: v.Len()}
+		if err := e.enter(path, key); err != nil {
+			return err
+		}
+		defer e.leave(key)
+		return e.encodeArray(path, v)
+	case reflect.Array:
+		return e.encodeArray(path, v)
+	case reflect.Struct:
+		return e.encodeStruct(path, v)
+	default:
+		return fmt.Errorf("cannot encode %s: unsupported type %s", path, v.Type())
+	}
+	return nil
+}
+
+// enter marks a value as being encoded and fails if it is already on the stack
+func (e *encodeState) enter(path string, key seenKey) error {
+	if e.seen[key] {
+		return fmt.Errorf("cannot encode %s: cycle detected", path)
+	}
+	e.seen[key] = true
+	return nil
+}
+
+// leave removes a value from the stack of values being encoded
+func (e *encodeState) leave(key seenKey) {
+	delete(e.seen, key)
+}
+
+// encodeFloat writes a float using the configured format
+func (e *encodeState) encodeFloat(path string, f float64, bits int) error {
+	if bits == 32 {
+		// Format from the shortest float32 text so 0.1f is written as 0.1, not 0.10000000149011612
+		f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+	}
+	s, err := FormatFloat(f, e.opts.Float)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s: %v", path, err)
+	}
+	e.WriteString(s)
+	return nil
+}
+
+// encodeMarshaler writes the output of a custom MarshalJSON after checking that it is valid JSON
+func (e *encodeState) encodeMarshaler(path string, m Marshaler) error {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("cannot encode %s: %v", path, err)
+	}
+	tokens, err := lexer.NewBytesLexer(data).Tokenize()
+	if err == nil {
+		_, err = parser.ParseValue(tokens)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot encode %s: MarshalJSON returned invalid JSON: %v", path, err)
+	}
+	e.Write(bytes.TrimSpace(data))
+	return nil
+}
+
+// encodeArray writes a slice or array
+func (e *encodeState) encodeArray(path string, v reflect.Value) error {
+	e.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		if err := e.encode(fmt.Sprintf("%s[%d]", path, i), v.Index(i)); err != nil {
+			return err
+		}
+	}
+	e.WriteByte(']')
+	return nil
+}
+
+// encodeMap writes a map as an object with its keys sorted
+func (e *encodeState) encodeMap(path string, v reflect.Value) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return fmt.Errorf("cannot encode %s: %v", path, err)
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	e.WriteByte('{')
+	for i, en := range entries {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		writeString(&e.Buffer, en.key)
+		e.WriteByte(':')
+		if err := e.encode(path+"."+en.key, en.value); err != nil {
+			return err
+		}
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+// mapKey converts a map key to its object key text
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", k.Type())
+}
+
+// encodeStruct writes the exported fields of a struct as an object
+func (e *encodeState) encodeStruct(path string, v reflect.Value) error {
+	e.WriteByte('{')
+	first := true
+	for _, f := range structFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		if !first {
+			e.WriteByte(',')
+		}
+		first = false
+		writeString(&e.Buffer, f.name)
+		e.WriteByte(':')
+		if err := e.encode(path+"."+f.name, fv); err != nil {
+			return err
+		}
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+// encodeAST writes a parsed value with its literals unchanged
+func (e *encodeState) encodeAST(path string, value ast.Value) error {
+	data, err := ast.AppendJSON(e.AvailableBuffer(), value)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s: %v", path, err)
+	}
+	e.Write(data)
+	return nil
+}
+
+// astNode returns the AST node held by v, either as a non-nil node pointer or as a node struct
+func astNode(v reflect.Value) (ast.Value, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() || !astNodeTypes[v.Type().Elem()] {
+			return nil, false
+		}
+		return v.Interface().(ast.Value), true
+	}
+	if !astNodeTypes[v.Type()] {
+		return nil, false
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr.Interface().(ast.Value), true
+}
+
+// isEmptyValue reports whether v is skipped by omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// writeString writes s as a quoted JSON string, replacing invalid UTF-8 with U+FFFD
+func writeString(buf *bytes.Buffer, s string) {
+	buf.Write(ast.AppendQuoted(buf.AvailableBuffer(), s))
+}
+
+// field describes an exported struct field that is written as an object key
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the fields of t in declaration order, promoting fields of embedded structs.
+// Fields declared closer to the outer struct win over promoted fields with the same name.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	names := make(map[string]bool)
+	collectFields(t, nil, names, &fields)
+	return fields
+}
+
+// collectFields appends the fields of t, then the fields promoted from its embedded structs
+func collectFields(t reflect.Type, index []int, names map[string]bool, fields *[]field) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, sf)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if names[name] {
+			continue
+		}
+		names[name] = true
+		*fields = append(*fields, field{
+			name:      name,
+			index:     append(append([]int(nil), index...), i),
+			omitEmpty: hasOption(opts, "omitempty"),
+		})
+	}
+
+	for _, sf := range embedded {
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		collectFields(ft, append(append([]int(nil), index...), sf.Index...), names, fields)
+	}
+}
+
+// hasOption checks a comma separated tag option list for name
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the nested field, or false when an embedded pointer on the way is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
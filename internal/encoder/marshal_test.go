@@ -0,0 +1,139 @@
+package encoder
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+type celsius float64
+
+func (c celsius) MarshalJSON() ([]byte, error) {
+	return []byte(` {"celsius": 21} `), nil
+}
+
+type broken struct{}
+
+func (broken) MarshalJSON() ([]byte, error) {
+	return []byte(`{oops`), nil
+}
+
+type failing struct{}
+
+func (failing) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("boom")
+}
+
+type Inner struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type outer struct {
+	Inner
+	Name  string `json:"name"`
+	Empty string `json:",omitempty"`
+}
+
+func TestMarshal(t *testing.T) {
+	var nilMap map[string]int
+	var nilPtr *int
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, `null`},
+		{"bool", true, `true`},
+		{"int", -42, `-42`},
+		{"uint", uint64(math.MaxUint64), `18446744073709551615`},
+		{"float", 3.25, `3.25`},
+		{"float32", float32(0.1), `0.1`},
+		{"negative zero", math.Copysign(0, -1), `-0`},
+		{"negative zero float32", float32(math.Copysign(0, -1)), `-0`},
+		{"string escapes", "a\"b\\c\n\t\x01<", `"a\"b\\c\n\t\u0001<"`},
+		{"invalid utf8", "a\xffb", `"a\ufffdb"`},
+		{"unicode", "héllo 😀", `"héllo 😀"`},
+		{"bytes", []byte("hi"), `"aGk="`},
+		{"nil slice", []int(nil), `null`},
+		{"empty slice", []int{}, `[]`},
+		{"array", [2]string{"a", "b"}, `["a","b"]`},
+		{"nil map", nilMap, `null`},
+		{"int keys", map[int]bool{10: true, 2: false}, `{"10":true,"2":false}`},
+		{"nil pointer", nilPtr, `null`},
+		{"interface slice", []interface{}{1, "x", nil}, `[1,"x",null]`},
+		{"embedded", outer{Inner: Inner{ID: 1, Name: "inner"}, Name: "outer"}, `{"name":"outer","id":1}`},
+		{"marshaler", celsius(21), `{"celsius": 21}`},
+		{"text marshaler", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), `"2024-01-02T03:04:05Z"`},
+		{"ast number", ast.Number{Value: "1.50"}, `1.50`},
+		{"ast tree", &ast.Array{Elements: []ast.Value{&ast.Null{}, &ast.Boolean{Value: "false"}, &ast.String{Value: "s"}}}, `[null,false,"s"]`},
+	}
+
+	for _, tt := range tests {
+		got, err := Marshal(tt.value)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	cycle := &node{}
+	cycle.Next = cycle
+	sliceCycle := []interface{}{nil}
+	sliceCycle[0] = sliceCycle
+	mapCycle := map[string]interface{}{}
+	mapCycle["self"] = []interface{}{mapCycle}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nan", math.NaN(), "unsupported float value"},
+		{"channel", make(chan int), "unsupported type chan int"},
+		{"map key", map[[2]int]int{{1, 2}: 3}, "unsupported map key type"},
+		{"cycle", cycle, "cycle detected"},
+		{"slice cycle", sliceCycle, "cannot encode $[0]: cycle detected"},
+		{"cycle through a map", mapCycle, "cycle detected"},
+		{"invalid marshaler output", broken{}, "returned invalid JSON"},
+		{"marshaler error", map[string]failing{"x": {}}, "cannot encode $.x: boom"},
+	}
+
+	for _, tt := range tests {
+		_, err := Marshal(tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestMarshal_SharedSlices(t *testing.T) {
+	shared := []interface{}{1, 2}
+	got, err := Marshal([]interface{}{shared, shared, shared[:1]})
+	if err != nil || string(got) != "[[1,2],[1,2],[1]]" {
+		t.Errorf("expected a slice repeated without a cycle to encode, got %s, %v", got, err)
+	}
+}
+
+func TestOptions_Float(t *testing.T) {
+	got, err := Options{Float: FloatFormat{TrailingZero: true}}.Marshal(map[string]float64{"a": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != `{"a":2.0}` {
+		t.Errorf(`expected {"a":2.0}, got %s`, got)
+	}
+}
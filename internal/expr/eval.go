@@ -0,0 +1,435 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+)
+
+// node is one operation of a compiled expression
+type node interface {
+	eval(x ast.Value) (ast.Value, error)
+}
+
+// document evaluates to the value bound to x
+type document struct{}
+
+func (document) eval(x ast.Value) (ast.Value, error) {
+	return x, nil
+}
+
+type literal struct {
+	value ast.Value
+}
+
+func (l *literal) eval(ast.Value) (ast.Value, error) {
+	return l.value, nil
+}
+
+// member looks up an object key or an array index; missing members are null
+type member struct {
+	target node
+	key    node
+}
+
+func (m *member) eval(x ast.Value) (ast.Value, error) {
+	target, err := m.target.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	key, err := m.key.eval(x)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := target.(type) {
+	case *ast.Object:
+		k, ok := key.(*ast.String)
+		if !ok {
+			return nil, fmt.Errorf("expr: object member name must be a string, got %s", ast.TypeName(key))
+		}
+		if v, ok := t.Pairs[k.Value]; ok {
+			return v, nil
+		}
+	case *ast.Array:
+		k, ok := key.(*ast.Number)
+		if !ok {
+			return nil, fmt.Errorf("expr: array index must be a number, got %s", ast.TypeName(key))
+		}
+		i, err := k.Int64()
+		if err == nil && i >= 0 && i < int64(len(t.Elements)) {
+			return t.Elements[i], nil
+		}
+	}
+	return &ast.Null{}, nil
+}
+
+type unaryOp struct {
+	op      string
+	operand node
+}
+
+func (u *unaryOp) eval(x ast.Value) (ast.Value, error) {
+	v, err := u.operand.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	if u.op == "!" {
+		return boolean(!truthy(v)), nil
+	}
+	f, ok := toFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("expr: cannot negate %s", ast.TypeName(v))
+	}
+	return number(-f)
+}
+
+type binaryOp struct {
+	op          string
+	left, right node
+}
+
+func (b *binaryOp) eval(x ast.Value) (ast.Value, error) {
+	left, err := b.left.eval(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit
+	switch b.op {
+	case "&&":
+		if !truthy(left) {
+			return boolean(false), nil
+		}
+		right, err := b.right.eval(x)
+		if err != nil {
+			return nil, err
+		}
+		return boolean(truthy(right)), nil
+	case "||":
+		if truthy(left) {
+			return boolean(true), nil
+		}
+		right, err := b.right.eval(x)
+		if err != nil {
+			return nil, err
+		}
+		return boolean(truthy(right)), nil
+	}
+
+	right, err := b.right.eval(x)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "==":
+		return boolean(equal(left, right)), nil
+	case "!=":
+		return boolean(!equal(left, right)), nil
+	case "<", "<=", ">", ">=":
+		cmp, err := compare(left, right)
+		if err != nil {
+			return nil, fmt.Errorf("expr: cannot compare %s %s %s", ast.TypeName(left), b.op, ast.TypeName(right))
+		}
+		switch b.op {
+		case "<":
+			return boolean(cmp < 0), nil
+		case "<=":
+			return boolean(cmp <= 0), nil
+		case ">":
+			return boolean(cmp > 0), nil
+		default:
+			return boolean(cmp >= 0), nil
+		}
+	case "contains":
+		return contains(left, right)
+	case "startsWith", "endsWith":
+		l, lok := left.(*ast.String)
+		r, rok := right.(*ast.String)
+		if !lok || !rok {
+			return nil, fmt.Errorf("expr: %s needs two strings, got %s and %s", b.op, ast.TypeName(left), ast.TypeName(right))
+		}
+		if b.op == "startsWith" {
+			return boolean(strings.HasPrefix(l.Value, r.Value)), nil
+		}
+		return boolean(strings.HasSuffix(l.Value, r.Value)), nil
+	}
+
+	if b.op == "+" {
+		_, ls := left.(*ast.String)
+		_, rs := right.(*ast.String)
+		if ls || rs {
+			l, lok := text(left)
+			r, rok := text(right)
+			if lok && rok {
+				return &ast.String{Value: l + r}, nil
+			}
+		}
+	}
+
+	l, lok := toFloat(left)
+	r, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("expr: cannot apply %s to %s and %s", b.op, ast.TypeName(left), ast.TypeName(right))
+	}
+	switch b.op {
+	case "+":
+		return number(l + r)
+	case "-":
+		return number(l - r)
+	case "*":
+		return number(l * r)
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("expr: division by zero")
+		}
+		return number(l / r)
+	default:
+		if r == 0 {
+			return nil, fmt.Errorf("expr: division by zero")
+		}
+		return number(math.Mod(l, r))
+	}
+}
+
+type conditional struct {
+	cond, then, otherwise node
+}
+
+func (c *conditional) eval(x ast.Value) (ast.Value, error) {
+	cond, err := c.cond.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(cond) {
+		return c.then.eval(x)
+	}
+	return c.otherwise.eval(x)
+}
+
+type arrayLiteral struct {
+	elements []node
+}
+
+func (a *arrayLiteral) eval(x ast.Value) (ast.Value, error) {
+	array := &ast.Array{}
+	for _, elem := range a.elements {
+		v, err := elem.eval(x)
+		if err != nil {
+			return nil, err
+		}
+		array.Elements = append(array.Elements, v)
+	}
+	return array, nil
+}
+
+type objectLiteral struct {
+	keys   []string
+	values []node
+}
+
+func (o *objectLiteral) eval(x ast.Value) (ast.Value, error) {
+	obj := &ast.Object{Pairs: make(map[string]ast.Value, len(o.keys))}
+	for i, key := range o.keys {
+		v, err := o.values[i].eval(x)
+		if err != nil {
+			return nil, err
+		}
+		obj.Set(key, v)
+	}
+	return obj, nil
+}
+
+type call struct {
+	name string
+	fn   func(ast.Value) (ast.Value, error)
+	arg  node
+}
+
+func (c *call) eval(x ast.Value) (ast.Value, error) {
+	arg, err := c.arg.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	v, err := c.fn(arg)
+	if err != nil {
+		return nil, fmt.Errorf("expr: %s: %v", c.name, err)
+	}
+	return v, nil
+}
+
+// functions callable from expressions, each taking one argument
+var functions = map[string]func(ast.Value) (ast.Value, error){
+	"len": func(v ast.Value) (ast.Value, error) {
+		switch v := v.(type) {
+		case *ast.String:
+			return number(float64(utf8.RuneCountInString(v.Value)))
+		case *ast.Array:
+			return number(float64(len(v.Elements)))
+		case *ast.Object:
+			return number(float64(len(v.Pairs)))
+		}
+		return nil, fmt.Errorf("no length for %s", ast.TypeName(v))
+	},
+	"keys": func(v ast.Value) (ast.Value, error) {
+		o, ok := v.(*ast.Object)
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %s", ast.TypeName(v))
+		}
+		keys := &ast.Array{}
+		for _, key := range o.OrderedKeys() {
+			keys.Elements = append(keys.Elements, &ast.String{Value: key})
+		}
+		return keys, nil
+	},
+	"upper": stringFunc(strings.ToUpper),
+	"lower": stringFunc(strings.ToLower),
+	"string": func(v ast.Value) (ast.Value, error) {
+		s, ok := text(v)
+		if !ok {
+			data, err := encoder.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			s = string(data)
+		}
+		return &ast.String{Value: s}, nil
+	},
+	"number": func(v ast.Value) (ast.Value, error) {
+		switch v := v.(type) {
+		case *ast.Number:
+			return v, nil
+		case *ast.String:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v.Value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v.Value)
+			}
+			return number(f)
+		}
+		return nil, fmt.Errorf("cannot convert %s to a number", ast.TypeName(v))
+	},
+	"round": func(v ast.Value) (ast.Value, error) {
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot round %s", ast.TypeName(v))
+		}
+		return number(math.Round(f))
+	},
+}
+
+func stringFunc(fn func(string) string) func(ast.Value) (ast.Value, error) {
+	return func(v ast.Value) (ast.Value, error) {
+		s, ok := v.(*ast.String)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", ast.TypeName(v))
+		}
+		return &ast.String{Value: fn(s.Value)}, nil
+	}
+}
+
+// number formats f as the shortest literal that parses back to it
+func number(f float64) (ast.Value, error) {
+	s, err := encoder.FormatFloat(f, encoder.FloatFormat{})
+	if err != nil {
+		return nil, fmt.Errorf("expr: result %v is not a valid JSON number", f)
+	}
+	return &ast.Number{Value: s}, nil
+}
+
+func boolean(b bool) ast.Value {
+	if b {
+		return &ast.Boolean{Value: "true"}
+	}
+	return &ast.Boolean{Value: "false"}
+}
+
+func toFloat(v ast.Value) (float64, bool) {
+	n, ok := v.(*ast.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// text returns the text of a scalar for string concatenation
+func text(v ast.Value) (string, bool) {
+	switch v := v.(type) {
+	case *ast.String:
+		return v.Value, true
+	case *ast.Number:
+		return v.Value, true
+	case *ast.Boolean:
+		return v.Value, true
+	case *ast.Null:
+		return "null", true
+	}
+	return "", false
+}
+
+// truthy treats false and null as false and every other value as true
+func truthy(v ast.Value) bool {
+	switch v := v.(type) {
+	case *ast.Boolean:
+		return v.Value == "true"
+	case *ast.Null, nil:
+		return false
+	}
+	return true
+}
+
+// compare orders two numbers or two strings
+func compare(a, b ast.Value) (int, error) {
+	if as, ok := a.(*ast.String); ok {
+		if bs, ok := b.(*ast.String); ok {
+			return strings.Compare(as.Value, bs.Value), nil
+		}
+	}
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if !aok || !bok {
+		return 0, fmt.Errorf("not comparable")
+	}
+	switch {
+	case af < bf:
+		return -1, nil
+	case af > bf:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// contains tests for a substring of a string, an element of an array or a key of an object
+func contains(container, item ast.Value) (ast.Value, error) {
+	switch c := container.(type) {
+	case *ast.String:
+		if s, ok := item.(*ast.String); ok {
+			return boolean(strings.Contains(c.Value, s.Value)), nil
+		}
+	case *ast.Array:
+		for _, element := range c.Elements {
+			if equal(element, item) {
+				return boolean(true), nil
+			}
+		}
+		return boolean(false), nil
+	case *ast.Object:
+		if s, ok := item.(*ast.String); ok {
+			_, found := c.Pairs[s.Value]
+			return boolean(found), nil
+		}
+	}
+	return nil, fmt.Errorf("expr: cannot test whether %s contains %s", ast.TypeName(container), ast.TypeName(item))
+}
+
+// equal compares values structurally, numbers by value and objects
+// without regard to the order of their members
+func equal(a, b ast.Value) bool {
+	return ast.EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}.Equal(a, b)
+}
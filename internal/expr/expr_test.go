@@ -0,0 +1,152 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func TestEval(t *testing.T) {
+	doc := mustParse(t, `{"name": "Widget", "price": 10, "qty": 3, "tags": ["a", "b"], "big": 12345678901234567890, "meta": {"on sale": true}, "none": null}`)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`x.price * 1.2`, `12`},
+		{`x.price * x.qty - 5`, `25`},
+		{`-x.price + 2 * (3 + 1)`, `-2`},
+		{`x.price % 3`, `1`},
+		{`x.price / 4`, `2.5`},
+		{`x.big`, `12345678901234567890`},
+		{`x.tags[1]`, `"b"`},
+		{`x.tags[5]`, `null`},
+		{`x.missing.deeper`, `null`},
+		{`x.meta["on sale"]`, `true`},
+		{`x.name + " x" + x.qty`, `"Widget x3"`},
+		{`'single' + "double"`, `"singledouble"`},
+		{`"tab\tand é"`, `"tab\tand é"`},
+		{`x.price > 5 && x.qty <= 3`, `true`},
+		{`x.none || x.price == 10.0`, `true`},
+		{`x.big == 12345678901234567891`, `false`},
+		{`{a: 1, b: [2]} == {b: [2.0], a: 1e0}`, `true`},
+		{`!x.none`, `true`},
+		{`x.name < "Wz"`, `true`},
+		{`x.qty > 2 ? "many" : "few"`, `"many"`},
+		{`x.tags contains "b" && !(x.tags contains "c")`, `true`},
+		{`x.name contains "dge" || x.meta contains "on sale"`, `true`},
+		{`[1, 2.0] contains 2`, `true`},
+		{`x.name startsWith "Wid" && x.name endsWith "get"`, `true`},
+		{`x.price >= 5 && x.price < 20 && x.name startsWith "W"`, `true`},
+		{`x.name startsWith "w"`, `false`},
+		{`x.name =~ /^wid/i && x.name !~ /^wid/`, `true`},
+		{`x.tags =~ /^b$/ && !(x.tags =~ /c/)`, `true`},
+		{`keys(x.meta) =~ /^on /`, `true`},
+		{`string(x.price / 2) =~ /^5$/ || x.name =~ "g" + "et$"`, `true`},
+		{`"a/b" =~ /a\/b/ && "1.5" =~ /^\d\.\d$/`, `true`},
+		{`keys(x.meta)`, `["on sale"]`},
+		{`{name: upper(x.name), total: x.price * x.qty, "n tags": len(x.tags)}`, `{"name":"WIDGET","total":30,"n tags":2}`},
+		{`[x.qty, lower("AB"), round(2.5), number("1.5"), string(x.tags)]`, `[3,"ab",3,1.5,"[\"a\",\"b\"]"]`},
+		{`x`, `{"name":"Widget","price":10,"qty":3,"tags":["a","b"],"big":12345678901234567890,"meta":{"on sale":true},"none":null}`},
+	}
+
+	for _, tt := range tests {
+		e, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("%s: compile error: %v", tt.expr, err)
+			continue
+		}
+		v, err := e.Eval(doc)
+		if err != nil {
+			t.Errorf("%s: eval error: %v", tt.expr, err)
+			continue
+		}
+		got, err := encoder.Marshal(v)
+		if err != nil {
+			t.Fatalf("%s: Marshal error: %v", tt.expr, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := map[string]string{
+		`x.price *`:       "column 10",
+		`y.price`:         `unknown identifier "y"`,
+		`x.price )`:       `unexpected ")"`,
+		`"open`:           "unterminated string",
+		`x # 1`:           "unexpected character",
+		`len(1, 2)`:       "takes one argument",
+		`{a 1}`:           `expected ":"`,
+		`01`:              "invalid number",
+		`[1, 2`:           `expected "]"`,
+		`x.`:              "expected a member name",
+		`x ? 1`:           `expected ":"`,
+		`"bad \q escape"`: "invalid escape",
+		`x =~ /open`:      "unterminated pattern",
+		`x =~ /a/g`:       "unknown pattern flag",
+		`x =~ /(/`:        "column 6",
+	}
+
+	for src, want := range tests {
+		_, err := Compile(src)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", src, want, err)
+		}
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	doc := mustParse(t, `{"s": "text", "n": 0, "list": [1]}`)
+
+	for _, src := range []string{`x.s * 2`, `1 / x.n`, `x.list < 2`, `upper(x.n)`, `-x.s`, `x.list["a"]`, `x[0]`, `1e308 * 10`, `x.n contains 0`, `x.s contains 1`, `x.n startsWith "0"`, `x.n =~ /0/`, `x.list =~ /1/`, `x.s =~ x.n`, `x.s =~ "("`, `keys(x.list)`} {
+		e, err := Compile(src)
+		if err != nil {
+			t.Fatalf("%s: compile error: %v", src, err)
+		}
+		if _, err := e.Eval(doc); err == nil {
+			t.Errorf("%s: expected an evaluation error", src)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	doc := mustParse(t, `{"users": [{"name": "ann", "tags": ["admin"]}, {"name": "bob", "tags": []}], "owner": {"name": "ann"}}`)
+	e, err := Compile(`x.name == "ann"`)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	results := e.Select(doc)
+	if len(results) != 2 || results[0].Pointer != "/users/0" || results[1].Pointer != "/owner" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[1].Parent != doc {
+		t.Errorf("expected the owner to have the root as parent")
+	}
+
+	// x.name is null on values without a name, so startsWith fails on them,
+	// which leaves them out rather than ending the search
+	e, _ = Compile(`x.name startsWith "b"`)
+	if results := e.Select(doc); len(results) != 1 || results[0].Pointer != "/users/1" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
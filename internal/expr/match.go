@@ -0,0 +1,171 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// match is x =~ pattern or x !~ pattern. A /regex/ literal is compiled with
+// the expression; any other pattern is evaluated to a string and compiled
+// through the shared cache.
+type match struct {
+	target  node
+	pattern node           // nil when re is compiled
+	re      *regexp.Regexp // pattern of a /regex/ literal
+	negate  bool
+}
+
+func (m *match) eval(x ast.Value) (ast.Value, error) {
+	target, err := m.target.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	re := m.re
+	if re == nil {
+		p, err := m.pattern.eval(x)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := p.(*ast.String)
+		if !ok {
+			return nil, fmt.Errorf("expr: pattern must be a string, got %s", ast.TypeName(p))
+		}
+		if re, err = patterns.compile(s.Value); err != nil {
+			return nil, fmt.Errorf("expr: %v", err)
+		}
+	}
+
+	matched, err := matches(re, target)
+	if err != nil {
+		return nil, err
+	}
+	return boolean(matched != m.negate), nil
+}
+
+// matches tests a string, or each string of an array until one matches
+func matches(re *regexp.Regexp, v ast.Value) (bool, error) {
+	switch v := v.(type) {
+	case *ast.String:
+		return re.MatchString(v.Value), nil
+	case *ast.Array:
+		for _, element := range v.Elements {
+			s, ok := element.(*ast.String)
+			if !ok {
+				return false, fmt.Errorf("expr: cannot match %s against a pattern", ast.TypeName(element))
+			}
+			if re.MatchString(s.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("expr: cannot match %s against a pattern", ast.TypeName(v))
+}
+
+// pattern parses the right operand of =~ or !~, with p.tok still on the
+// operator so a / after it has not been scanned as division
+func (p *exprParser) pattern(negate bool, target node) (node, error) {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n\r", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '/' {
+		p.next()
+		right, err := p.binary(precedence["=~"])
+		if err != nil {
+			return nil, err
+		}
+		return &match{target: target, pattern: right, negate: negate}, nil
+	}
+
+	start := p.pos
+	src, err := p.scanRegex()
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(src)
+	if err != nil {
+		return nil, fmt.Errorf("expr error at column %d: %v", start+1, err)
+	}
+	p.next()
+	return &match{target: target, re: re, negate: negate}, nil
+}
+
+// scanRegex reads /pattern/flags into the syntax of package regexp. Inside
+// the slashes \/ stands for a slash; the flags i, m and s are supported.
+func (p *exprParser) scanRegex() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", fmt.Errorf("expr error at column %d: unterminated pattern", start+1)
+		}
+		c := p.src[p.pos]
+		if c == '/' {
+			p.pos++
+			break
+		}
+		if c == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/' {
+			c = '/'
+			p.pos++
+		} else if c == '\\' && p.pos+1 < len(p.src) {
+			b.WriteByte(c)
+			p.pos++
+			c = p.src[p.pos]
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+
+	flags := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("ims", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] < 0x80 && (p.src[p.pos] == '_' || isAlnum(p.src[p.pos])) {
+		return "", fmt.Errorf("expr error at column %d: unknown pattern flag %q", p.pos+1, p.src[p.pos])
+	}
+	if p.pos > flags {
+		return "(?" + p.src[flags:p.pos] + ")" + b.String(), nil
+	}
+	return b.String(), nil
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// maxCachedPatterns bounds the cache of patterns computed at evaluation time
+const maxCachedPatterns = 256
+
+// patternCache holds compiled patterns by source, so a pattern taken from
+// the document is compiled once however many values it is tested on
+type patternCache struct {
+	mu       sync.Mutex
+	compiled map[string]*regexp.Regexp
+}
+
+var patterns = &patternCache{compiled: make(map[string]*regexp.Regexp)}
+
+// compile returns the cached pattern for src, compiling it on first use. A
+// full cache is emptied rather than grown.
+func (c *patternCache) compile(src string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if re, ok := c.compiled[src]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.compiled) >= maxCachedPatterns {
+		c.compiled = make(map[string]*regexp.Regexp)
+	}
+	c.compiled[src] = re
+	return re, nil
+}
@@ -0,0 +1,422 @@
+// Package expr compiles small expressions over a JSON document, such as
+// `x.price * 1.2` or `{name: x.name, total: x.qty * x.price}`, for use in
+// scriptable transforms.
+//
+// The document is bound to the identifier x. Expressions support member
+// access (x.a, x["a b"]), indexing (x.items[0]), arithmetic (+ - * / %),
+// comparisons (== != < <= > >=), the tests contains, startsWith and
+// endsWith (x.tags contains "a", x.name startsWith "api"), regular
+// expression matching (x.name =~ /^api-/i, x.id !~ x.pattern), logic
+// (&& || !), the conditional operator (c ? a : b), array and object
+// literals, and the functions len, keys, upper, lower, string, number and
+// round.
+//
+// A pattern matches a string, or an array with a matching string, so object
+// keys are matched with keys(x.headers) =~ /^x-/. Patterns written as
+// /regex/ literals are compiled with the expression; patterns computed from
+// the document are compiled once each and cached.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// Expr is a compiled expression
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses src into an expression
+func Compile(src string) (*Expr, error) {
+	p := &exprParser{src: src}
+	p.next()
+	root, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokEnd {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with x bound to doc
+func (e *Expr) Eval(doc ast.Value) (ast.Value, error) {
+	return e.root.eval(doc)
+}
+
+// Test evaluates the expression as a condition, which holds unless the
+// result is false or null
+func (e *Expr) Test(doc ast.Value) (bool, error) {
+	v, err := e.root.eval(doc)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// Select tests the expression as a condition against doc and every value
+// inside it, and returns a cursor for each value it holds for, in document
+// order. Values the condition cannot be evaluated on, such as a string for
+// x.price > 10, do not match.
+func (e *Expr) Select(doc ast.Value) ast.Cursors {
+	return ast.Find(doc, func(v ast.Value) bool {
+		ok, err := e.Test(v)
+		return err == nil && ok
+	})
+}
+
+// tokenKind classifies expression tokens
+type tokenKind int
+
+const (
+	tokEnd tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp // punctuation and operators, spelled in text
+)
+
+type token struct {
+	kind tokenKind
+	text string // operator spelling, identifier, number literal or decoded string
+	pos  int    // byte offset in the source
+}
+
+// String describes the token for error messages
+func (t token) String() string {
+	switch t.kind {
+	case tokEnd:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// operators lists the multi-character operators before their prefixes
+var operators = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", ".", ",", "(", ")", "[", "]", "{", "}"}
+
+type exprParser struct {
+	src string
+	pos int
+	tok token
+	err error // scan error, reported when the token is used
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expr error at column %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next scans the following token into p.tok
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEnd, pos: start}
+		return
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9':
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE", p.src[p.pos]) >= 0 {
+			if (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') && p.pos+1 < len(p.src) && (p.src[p.pos+1] == '+' || p.src[p.pos+1] == '-') {
+				p.pos++
+			}
+			p.pos++
+		}
+		p.tok = token{kind: tokNumber, text: p.src[start:p.pos], pos: start}
+	case c == '"' || c == '\'':
+		p.tok = token{kind: tokString, pos: start}
+		p.tok.text, p.err = p.scanString(c)
+	case c == '_' || c < utf8.RuneSelf && unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || p.src[p.pos] < utf8.RuneSelf && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos])))) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	default:
+		for _, op := range operators {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				p.pos += len(op)
+				p.tok = token{kind: tokOp, text: op, pos: start}
+				return
+			}
+		}
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.tok = token{kind: tokOp, text: string(r), pos: start}
+		p.err = fmt.Errorf("expr error at column %d: unexpected character %q", start+1, r)
+		p.pos = len(p.src)
+	}
+}
+
+// scanString reads a quoted string with Go-style escapes
+func (p *exprParser) scanString(quote byte) (string, error) {
+	start := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", fmt.Errorf("expr error at column %d: unterminated string", start+1)
+		}
+		if p.src[p.pos] == quote {
+			p.pos++
+			return b.String(), nil
+		}
+		r, _, tail, err := strconv.UnquoteChar(p.src[p.pos:], quote)
+		if err != nil {
+			return "", fmt.Errorf("expr error at column %d: invalid escape in string", p.pos+1)
+		}
+		b.WriteRune(r)
+		p.pos = len(p.src) - len(tail)
+	}
+}
+
+// is reports whether the current token is the operator op
+func (p *exprParser) is(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+// expect consumes the operator op
+func (p *exprParser) expect(op string) error {
+	if p.err != nil {
+		return p.err
+	}
+	if !p.is(op) {
+		return p.errorf("expected %q, got %s", op, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) expression() (node, error) {
+	cond, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("?") {
+		return cond, nil
+	}
+	p.next()
+	then, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	return &conditional{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// precedence of binary operators; higher binds tighter
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"contains": 3, "startsWith": 3, "endsWith": 3, "=~": 3, "!~": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+// binary parses operators binding tighter than minPrec, left to right
+func (p *exprParser) binary(minPrec int) (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp || p.tok.kind == tokIdent {
+		op := p.tok.text
+		prec, ok := precedence[op]
+		if !ok || prec <= minPrec {
+			break
+		}
+		if op == "=~" || op == "!~" {
+			if left, err = p.pattern(op == "!~", left); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		p.next()
+		right, err := p.binary(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryOp{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) unary() (node, error) {
+	if p.is("-") || p.is("!") {
+		op := p.tok.text
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryOp{op: op, operand: operand}, nil
+	}
+	return p.postfix()
+}
+
+func (p *exprParser) postfix() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.is("."):
+			p.next()
+			if p.tok.kind != tokIdent {
+				return nil, p.errorf("expected a member name, got %s", p.tok)
+			}
+			n = &member{target: n, key: &literal{value: &ast.String{Value: p.tok.text}}}
+			p.next()
+		case p.is("["):
+			p.next()
+			key, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &member{target: n, key: key}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *exprParser) primary() (node, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		if tokens, err := lexer.NewLexer(tok.text).Tokenize(); err != nil || len(tokens) != 2 {
+			return nil, p.errorf("invalid number %s", tok.text)
+		}
+		p.next()
+		return &literal{value: &ast.Number{Value: tok.text}}, nil
+	case tokString:
+		p.next()
+		return &literal{value: &ast.String{Value: tok.text}}, nil
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "x":
+			return document{}, nil
+		case "true", "false":
+			return &literal{value: &ast.Boolean{Value: tok.text}}, nil
+		case "null":
+			return &literal{value: &ast.Null{}}, nil
+		}
+		fn, ok := functions[tok.text]
+		if !ok || !p.is("(") {
+			return nil, fmt.Errorf("expr error at column %d: unknown identifier %q", tok.pos+1, tok.text)
+		}
+		p.next()
+		args, err := p.list(")")
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expr error at column %d: %s takes one argument, got %d", tok.pos+1, tok.text, len(args))
+		}
+		return &call{name: tok.text, fn: fn, arg: args[0]}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			p.next()
+			n, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			p.next()
+			elems, err := p.list("]")
+			if err != nil {
+				return nil, err
+			}
+			return &arrayLiteral{elements: elems}, nil
+		case "{":
+			p.next()
+			return p.object()
+		}
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+// list parses comma-separated expressions up to the closing operator
+func (p *exprParser) list(closing string) ([]node, error) {
+	var nodes []node
+	for !p.is(closing) {
+		n, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+	return nodes, p.expect(closing)
+}
+
+// object parses the members of an object literal after '{'
+func (p *exprParser) object() (node, error) {
+	obj := &objectLiteral{}
+	for !p.is("}") {
+		if p.err != nil {
+			return nil, p.err
+		}
+		if p.tok.kind != tokIdent && p.tok.kind != tokString {
+			return nil, p.errorf("expected a member name, got %s", p.tok)
+		}
+		key := p.tok.text
+		p.next()
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		obj.keys = append(obj.keys, key)
+		obj.values = append(obj.values, value)
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+	return obj, p.expect("}")
+}
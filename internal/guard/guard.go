@@ -0,0 +1,70 @@
+// Package guard converts panics inside the library into errors, so a bug in the
+// parser cannot crash the program embedding it.
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrInternal marks errors that stem from a bug in this library rather than from bad input
+var ErrInternal = errors.New("jsonparser: internal error")
+
+// Position locates the input being processed when a panic happened.
+// Offset is -1 when the byte offset is not known; Line and Column are 0 when unknown.
+type Position struct {
+	Offset int64
+	Line   int
+	Column int
+}
+
+// Error is returned in place of a recovered panic
+type Error struct {
+	Op       string      // Operation that failed, e.g. "parse" or "marshal"
+	Position Position    // Input position at the time of the panic
+	Value    interface{} // Value passed to panic
+	Stack    []byte      // Stack trace of the panicking goroutine
+}
+
+// Error describes the failure, flagging it as a bug to report
+func (e *Error) Error() string {
+	where := ""
+	if e.Position.Offset >= 0 {
+		where = fmt.Sprintf(" at offset %d", e.Position.Offset)
+	}
+	if e.Position.Line > 0 {
+		where += fmt.Sprintf(" (line %d, column %d)", e.Position.Line, e.Position.Column)
+	}
+	return fmt.Sprintf("%v (bug) during %s%s: %v", ErrInternal, e.Op, where, e.Value)
+}
+
+// Is reports whether target is ErrInternal, so errors.Is(err, ErrInternal) identifies bugs
+func (e *Error) Is(target error) bool {
+	return target == ErrInternal
+}
+
+// Recover must be deferred directly. It turns a panic into an *Error stored in *errp,
+// calling where to find out how far into the input processing had got.
+func Recover(op string, errp *error, where func() Position) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	pos := Position{Offset: -1}
+	if where != nil {
+		pos = safePosition(where)
+	}
+	*errp = &Error{Op: op, Position: pos, Value: p, Stack: debug.Stack()}
+}
+
+// safePosition calls where, falling back to an unknown position if it panics too
+func safePosition(where func() Position) (pos Position) {
+	defer func() {
+		if recover() != nil {
+			pos = Position{Offset: -1}
+		}
+	}()
+	return where()
+}
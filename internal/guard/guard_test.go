@@ -0,0 +1,48 @@
+package guard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func guarded(where func() Position, fn func()) (err error) {
+	defer Recover("parse", &err, where)
+	fn()
+	return nil
+}
+
+func TestRecover_ConvertsPanic(t *testing.T) {
+	err := guarded(func() Position { return Position{Offset: 42, Line: 3, Column: 7} }, func() {
+		var m map[string]int
+		m["boom"] = 1
+	})
+
+	var ierr *Error
+	if !errors.As(err, &ierr) {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if !errors.Is(err, ErrInternal) {
+		t.Errorf("expected errors.Is(err, ErrInternal)")
+	}
+	if ierr.Position.Offset != 42 || ierr.Op != "parse" || len(ierr.Stack) == 0 {
+		t.Errorf("unexpected error details: %+v", ierr)
+	}
+	want := "jsonparser: internal error (bug) during parse at offset 42 (line 3, column 7): assignment to entry in nil map"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestRecover_UnknownPosition(t *testing.T) {
+	err := guarded(func() Position { panic("where failed") }, func() { panic("first") })
+	if err == nil || strings.Contains(err.Error(), "offset") {
+		t.Errorf("expected an error without an offset, got %v", err)
+	}
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	if err := guarded(nil, func() {}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Package keydict shrinks documents whose objects repeat the same keys, such
+// as arrays of records, by moving the keys into a dictionary.
+//
+// A compressed document is an object with two members: "dict", an array of
+// the replaced keys, most frequent first, and "body", the original document
+// with every replaced key written as "~" followed by its dictionary index.
+// Keys that already start with "~" are escaped with a second "~". A key is
+// only replaced when that makes the output smaller.
+package keydict
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Envelope member names
+const (
+	dictKey = "dict"
+	bodyKey = "body"
+)
+
+// Compress returns the dictionary-compressed form of v. v is not modified.
+func Compress(v ast.Value) ast.Value {
+	counts := make(map[string]int)
+	ast.Inspect(v, func(node ast.Value) bool {
+		if obj, ok := node.(*ast.Object); ok {
+			for key := range obj.Pairs {
+				counts[key]++
+			}
+		}
+		return true
+	})
+
+	type candidate struct {
+		key   string
+		count int
+	}
+	candidates := make([]candidate, 0, len(counts))
+	for key, count := range counts {
+		if count > 1 {
+			candidates = append(candidates, candidate{key, count})
+		}
+	}
+	// Most saved bytes first, so the biggest wins get the shortest references
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.count*len(a.key) != b.count*len(b.key) {
+			return a.count*len(a.key) > b.count*len(b.key)
+		}
+		return a.key < b.key
+	})
+
+	dict := &ast.Array{Elements: []ast.Value{}}
+	refs := make(map[string]string)
+	for _, c := range candidates {
+		ref := reference(len(dict.Elements))
+		// The dictionary entry costs the quoted key and a comma
+		saved := c.count*(len(escape(c.key))-len(ref)) - (len(c.key) + 3)
+		if saved <= 0 {
+			continue
+		}
+		refs[c.key] = ref
+		dict.Elements = append(dict.Elements, &ast.String{Value: c.key})
+	}
+
+	return &ast.Object{Pairs: map[string]ast.Value{
+		dictKey: dict,
+		bodyKey: rewrite(v, refs),
+	}}
+}
+
+// rewrite copies the containers of v with keys replaced by their references
+func rewrite(v ast.Value, refs map[string]string) ast.Value {
+	switch v := v.(type) {
+	case *ast.Object:
+		obj := &ast.Object{Pairs: make(map[string]ast.Value, len(v.Pairs))}
+		for _, key := range v.OrderedKeys() {
+			name, ok := refs[key]
+			if !ok {
+				name = escape(key)
+			}
+			obj.Set(name, rewrite(v.Pairs[key], refs))
+		}
+		return obj
+	case *ast.Array:
+		array := &ast.Array{Elements: make([]ast.Value, len(v.Elements))}
+		for i, elem := range v.Elements {
+			array.Elements[i] = rewrite(elem, refs)
+		}
+		return array
+	default:
+		return v
+	}
+}
+
+// reference returns the body key that stands for dictionary entry i
+func reference(i int) string {
+	return "~" + strconv.Itoa(i)
+}
+
+// escape protects keys that could be mistaken for references
+func escape(key string) string {
+	if strings.HasPrefix(key, "~") {
+		return "~" + key
+	}
+	return key
+}
+
+// Expand reverses Compress, returning the original document
+func Expand(v ast.Value) (ast.Value, error) {
+	envelope, ok := v.(*ast.Object)
+	if !ok || len(envelope.Pairs) != 2 {
+		return nil, fmt.Errorf("keydict: expected an object with %q and %q members", dictKey, bodyKey)
+	}
+	dictValue, ok := envelope.Pairs[dictKey].(*ast.Array)
+	if !ok {
+		return nil, fmt.Errorf("keydict: %q must be an array of strings", dictKey)
+	}
+	body, ok := envelope.Pairs[bodyKey]
+	if !ok {
+		return nil, fmt.Errorf("keydict: missing %q member", bodyKey)
+	}
+
+	dict := make([]string, len(dictValue.Elements))
+	for i, elem := range dictValue.Elements {
+		s, ok := elem.(*ast.String)
+		if !ok {
+			return nil, fmt.Errorf("keydict: %q must be an array of strings", dictKey)
+		}
+		dict[i] = s.Value
+	}
+	return restore(body, dict)
+}
+
+// restore copies the containers of v with references replaced by their keys
+func restore(v ast.Value, dict []string) (ast.Value, error) {
+	switch v := v.(type) {
+	case *ast.Object:
+		obj := &ast.Object{Pairs: make(map[string]ast.Value, len(v.Pairs))}
+		for _, name := range v.OrderedKeys() {
+			key, err := lookup(name, dict)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := obj.Pairs[key]; ok {
+				return nil, fmt.Errorf("keydict: key %q occurs twice in one object", key)
+			}
+			value, err := restore(v.Pairs[name], dict)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key, value)
+		}
+		return obj, nil
+	case *ast.Array:
+		array := &ast.Array{Elements: make([]ast.Value, len(v.Elements))}
+		for i, elem := range v.Elements {
+			restored, err := restore(elem, dict)
+			if err != nil {
+				return nil, err
+			}
+			array.Elements[i] = restored
+		}
+		return array, nil
+	default:
+		return v, nil
+	}
+}
+
+// lookup resolves a body key to the original key
+func lookup(name string, dict []string) (string, error) {
+	if !strings.HasPrefix(name, "~") {
+		return name, nil
+	}
+	if strings.HasPrefix(name, "~~") {
+		return name[1:], nil
+	}
+	i, err := strconv.Atoi(name[1:])
+	if err != nil || i < 0 || i >= len(dict) || reference(i) != name {
+		return "", fmt.Errorf("keydict: invalid key reference %q", name)
+	}
+	return dict[i], nil
+}
@@ -0,0 +1,115 @@
+package keydict
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func mustMarshal(t *testing.T, v ast.Value) string {
+	t.Helper()
+	data, err := ast.AppendJSON(nil, v)
+	if err != nil {
+		t.Fatalf("AppendJSON error: %v", err)
+	}
+	return string(data)
+}
+
+func TestCompress(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "repeated keys",
+			input:    `[{"identifier":1,"description":"a"},{"identifier":2,"description":"b"},{"identifier":3,"description":"c"}]`,
+			expected: `{"body":[{"~1":1,"~0":"a"},{"~1":2,"~0":"b"},{"~1":3,"~0":"c"}],"dict":["description","identifier"]}`,
+		},
+		{
+			name:     "short keys stay inline",
+			input:    `[{"a":1},{"a":2}]`,
+			expected: `{"body":[{"a":1},{"a":2}],"dict":[]}`,
+		},
+		{
+			name:     "tilde keys are escaped",
+			input:    `{"~0":true}`,
+			expected: `{"body":{"~~0":true},"dict":[]}`,
+		},
+		{
+			name:     "scalar",
+			input:    `42`,
+			expected: `{"body":42,"dict":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := mustParse(t, tt.input)
+			compressed := Compress(input)
+			if got := mustMarshal(t, compressed); got != tt.expected {
+				t.Errorf("Compress(%s) = %s, want %s", tt.input, got, tt.expected)
+			}
+			if got := mustMarshal(t, input); got != mustMarshal(t, mustParse(t, tt.input)) {
+				t.Errorf("Compress modified its input: %s", got)
+			}
+
+			expanded, err := Expand(compressed)
+			if err != nil {
+				t.Fatalf("Expand error: %v", err)
+			}
+			if got, want := mustMarshal(t, expanded), mustMarshal(t, input); got != want {
+				t.Errorf("Expand(Compress(%s)) = %s, want %s", tt.input, got, want)
+			}
+		})
+	}
+}
+
+func TestCompress_Shrinks(t *testing.T) {
+	record := `{"customer_name":"x","customer_email":"y","~note":null}`
+	input := "[" + strings.Repeat(record+",", 99) + record + "]"
+
+	compressed := mustMarshal(t, Compress(mustParse(t, input)))
+	if len(compressed) >= len(input) {
+		t.Errorf("compressed size %d, want less than %d", len(compressed), len(input))
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`[1]`, `keydict: expected an object with "dict" and "body" members`},
+		{`{"dict":[],"rest":1}`, `keydict: missing "body" member`},
+		{`{"dict":{},"body":1}`, `keydict: "dict" must be an array of strings`},
+		{`{"dict":[1],"body":1}`, `keydict: "dict" must be an array of strings`},
+		{`{"dict":["a"],"body":{"~1":1}}`, `keydict: invalid key reference "~1"`},
+		{`{"dict":["a"],"body":{"~x":1}}`, `keydict: invalid key reference "~x"`},
+		{`{"dict":["a"],"body":{"~00":1}}`, `keydict: invalid key reference "~00"`},
+		{`{"dict":["a"],"body":{"~0":1,"a":2}}`, `keydict: key "a" occurs twice in one object`},
+	}
+
+	for _, tt := range tests {
+		_, err := Expand(mustParse(t, tt.input))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Expand(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies the kind of an error, so tools can branch on it
+// without matching messages. A code keeps its value from release to release.
+type ErrorCode string
+
+// Error codes of the lexer and parser
+const (
+	CodeUnexpectedCharacter ErrorCode = "E_UNEXPECTED_CHARACTER" // A character no token starts with
+	CodeInvalidLiteral      ErrorCode = "E_INVALID_LITERAL"      // A misspelt true, false or null, or another language's, such as True or None
+	CodeSingleQuotes        ErrorCode = "E_SINGLE_QUOTES"        // A string in single quotes
+	CodeUnquotedString      ErrorCode = "E_UNQUOTED_STRING"      // A key or string without quotes
+	CodeUnterminatedString  ErrorCode = "E_UNTERMINATED_STRING"  // A string whose closing quote is missing
+	CodeUnterminatedComment ErrorCode = "E_UNTERMINATED_COMMENT" // A block comment that is never closed
+	CodeBadEscape           ErrorCode = "E_BAD_ESCAPE"           // An unknown escape, or a malformed \u escape or surrogate pair
+	CodeInvalidUTF8         ErrorCode = "E_INVALID_UTF8"         // Invalid UTF-8 in a string under RejectInvalidUTF8
+	CodeControlCharacter    ErrorCode = "E_CONTROL_CHARACTER"    // A raw control character in a string, unless AllowControlCharacters is set
+	CodeBadNumber           ErrorCode = "E_BAD_NUMBER"           // A malformed number, such as 01, 1. or 1e
+	CodeRead                ErrorCode = "E_READ"                 // The reader the input came from failed
+	CodeDialect             ErrorCode = "E_DIALECT"              // A dialect hook rejected the input
+	CodeBadHook             ErrorCode = "E_BAD_HOOK"             // A dialect hook broke its contract
+	CodeUnexpectedToken     ErrorCode = "E_UNEXPECTED_TOKEN"     // A token out of place
+	CodeUnexpectedEOF       ErrorCode = "E_UNEXPECTED_EOF"       // The input ended inside a value
+	CodeTrailingData        ErrorCode = "E_TRAILING_DATA"        // More input after the document
+	CodeTrailingComma       ErrorCode = "E_TRAILING_COMMA"       // A comma before a closing bracket or brace
+	CodeMissingComma        ErrorCode = "E_MISSING_COMMA"        // Two elements or members with no comma between
+	CodeMissingColon        ErrorCode = "E_MISSING_COLON"        // A key and value with no colon between
+	CodeDuplicateKey        ErrorCode = "E_DUPLICATE_KEY"        // A key repeated within one object
+	CodeTooDeep             ErrorCode = "E_TOO_DEEP"             // Nesting deeper than MaxDepth
+	CodeTooManyKeys         ErrorCode = "E_TOO_MANY_KEYS"        // An object with more than MaxObjectKeys members
+	CodeTooManyElements     ErrorCode = "E_TOO_MANY_ELEMENTS"    // An array with more than MaxArrayElements elements
+	CodeTooManyErrors       ErrorCode = "E_TOO_MANY_ERRORS"      // Recovery stopped after MaxErrors errors
+	CodeInvalidValue        ErrorCode = "E_INVALID_VALUE"        // A dialect value rejected through Parser.Errorf
+)
+
+// CodeOf returns the code of the first error in err's tree that has one,
+// and "" when none does
+func CodeOf(err error) ErrorCode {
+	var coded interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return ""
+}
+
+// scanError is an error found while scanning a token, before errorAt locates it
+type scanError struct {
+	code ErrorCode
+	msg  string
+	hint string
+	// at is set when the error is at a character inside the token, found at
+	// line, column and offset, rather than at the token's start
+	at           bool
+	line, column int
+	offset       int64
+}
+
+func (e *scanError) Error() string {
+	return e.msg
+}
+
+// scanErrorf returns a scanError with a formatted message
+func scanErrorf(code ErrorCode, format string, args ...any) error {
+	return &scanError{code: code, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,59 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{&SyntaxError{Msg: "x"}, CodeUnexpectedCharacter},
+		{&SyntaxError{Msg: "x", Code: CodeBadNumber}, CodeBadNumber},
+		{&UnterminatedStringError{}, CodeUnterminatedString},
+		{&UnexpectedTokenError{Token: Token{Type: TokenEOF}, Expected: TokenRightBracket}, CodeUnexpectedEOF},
+		{&UnexpectedTokenError{Token: Token{Type: TokenNumber}, Expected: TokenEOF}, CodeTrailingData},
+		{&UnexpectedTokenError{Token: Token{Type: TokenColon}, Expected: "a valid value"}, CodeUnexpectedToken},
+		{&UnexpectedTokenError{Token: Token{Type: TokenRightBracket}, Code: CodeTrailingComma}, CodeTrailingComma},
+		{&ReadError{Err: errors.New("reset")}, CodeRead},
+		{ErrorList{errors.New("plain"), &UnterminatedStringError{}, &SyntaxError{}}, CodeUnterminatedString},
+		{fmt.Errorf("wrapped: %w", &SyntaxError{Code: CodeBadEscape}), CodeBadEscape},
+		{errors.New("plain"), ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.code {
+			t.Errorf("%v: expected %s, got %s", tt.err, tt.code, got)
+		}
+	}
+}
+
+func TestLexer_ErrorCodes(t *testing.T) {
+	tests := map[string]ErrorCode{
+		`[1, @]`:     CodeUnexpectedCharacter,
+		`[nul]`:      CodeInvalidLiteral,
+		`['a']`:      CodeSingleQuotes,
+		`[abc]`:      CodeUnquotedString,
+		`["a`:        CodeUnterminatedString,
+		`["\u12"]`:   CodeBadEscape,
+		`[-]`:        CodeBadNumber,
+		`[1e+]`:      CodeBadNumber,
+		`[12a]`:      CodeBadNumber,
+		`["\x"]`:     CodeBadEscape,
+		`["\udc00"]`: CodeBadEscape,
+	}
+	for input, want := range tests {
+		if _, err := NewLexer(input).Tokenize(); CodeOf(err) != want {
+			t.Errorf("%s: expected %s, got %s from %v", input, want, CodeOf(err), err)
+		}
+	}
+
+	lex := NewLexer("[1] /* open")
+	lex.SetOptions(Options{AllowComments: true})
+	if _, err := lex.Tokenize(); CodeOf(err) != CodeUnterminatedComment {
+		t.Errorf("expected %s, got %v", CodeUnterminatedComment, err)
+	}
+}
@@ -0,0 +1,245 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSyntax is matched by errors.Is for every error reporting malformed JSON,
+// whichever type describes it
+var ErrSyntax = errors.New("syntax error")
+
+// errUnterminatedString is returned by readString and located by errorAt
+var errUnterminatedString = errors.New("unterminated string literal")
+
+// SyntaxError reports malformed input found by the lexer
+type SyntaxError struct {
+	Msg    string // Description, such as "invalid escape character: '\q'"
+	Code   ErrorCode
+	Hint   string // Suggested fix for a common mistake, such as "did you mean true?"; "" for none
+	Line   int    // Line of the token the error was found in, or of the character for a control character in a string
+	Column int    // Column of the token or character
+	Offset int64  // Byte offset of the token or character
+	Err    error  // Error returned by a dialect hook, if it caused this one
+
+	Snippet *Snippet // Source line of the error, nil when it was not available
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Lexer error at line %d, column %d: %s%s", e.Line, e.Column, e.Msg, hint(e.Hint))
+}
+
+// Is reports whether target is ErrSyntax
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+// Format adds the source snippet to the message under %+v
+func (e *SyntaxError) Format(f fmt.State, verb rune) {
+	FormatError(f, verb, e, e.Snippet)
+}
+
+// Unwrap returns the hook error behind the syntax error, if any
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns Code, or CodeUnexpectedCharacter when it is not set
+func (e *SyntaxError) ErrorCode() ErrorCode {
+	if e.Code == "" {
+		return CodeUnexpectedCharacter
+	}
+	return e.Code
+}
+
+// UnterminatedStringError reports a string whose closing quote is missing
+type UnterminatedStringError struct {
+	Line   int   // Line of the opening quote
+	Column int   // Column of the opening quote
+	Offset int64 // Byte offset of the opening quote
+
+	Snippet *Snippet // Source line of the opening quote, nil when it was not available
+}
+
+func (e *UnterminatedStringError) Error() string {
+	return fmt.Sprintf("Lexer error at line %d, column %d: %v", e.Line, e.Column, errUnterminatedString)
+}
+
+// Format adds the source snippet to the message under %+v
+func (e *UnterminatedStringError) Format(f fmt.State, verb rune) {
+	FormatError(f, verb, e, e.Snippet)
+}
+
+// Is reports whether target is ErrSyntax
+func (e *UnterminatedStringError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+// ErrorCode returns CodeUnterminatedString
+func (e *UnterminatedStringError) ErrorCode() ErrorCode {
+	return CodeUnterminatedString
+}
+
+// UnexpectedTokenError reports a token that does not match what the parser expected
+type UnexpectedTokenError struct {
+	Token    Token     // Token found; its type is TokenEOF at the end of input
+	Expected TokenType // Token type wanted, or a description such as "a valid value"
+	Path     string    // Where in the document the token was, such as $.items[3]; "" outside any array or object
+	Code     ErrorCode // Set for a common mistake, such as CodeTrailingComma; see ErrorCode
+	Hint     string    // Suggested fix for a common mistake, such as "did you forget a comma?"; "" for none
+
+	Snippet *Snippet // Source line of the token, filled in by parser.WithSnippets
+}
+
+func (e *UnexpectedTokenError) Error() string {
+	tok := e.Token
+	var at string
+	if e.Path != "" {
+		at = " at " + e.Path
+	}
+	if tok.Type == TokenEOF {
+		return fmt.Sprintf("Parser error at line %d, column %d: expected %s, got end of input%s%s", tok.Line, tok.Column, e.Expected, at, hint(e.Hint))
+	}
+	return fmt.Sprintf("Parser error at line %d, column %d: expected %s, got %s %q%s%s", tok.Line, tok.Column, e.Expected, tok.Type, tok.Literal, at, hint(e.Hint))
+}
+
+// Format adds the source snippet to the message under %+v
+func (e *UnexpectedTokenError) Format(f fmt.State, verb rune) {
+	FormatError(f, verb, e, e.Snippet)
+}
+
+// Is reports whether target is ErrSyntax
+func (e *UnexpectedTokenError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+// ErrorCode returns Code when it is set, and otherwise CodeUnexpectedEOF,
+// CodeTrailingData or CodeUnexpectedToken from the token and expectation
+func (e *UnexpectedTokenError) ErrorCode() ErrorCode {
+	switch {
+	case e.Code != "":
+		return e.Code
+	case e.Token.Type == TokenEOF:
+		return CodeUnexpectedEOF
+	case e.Expected == TokenEOF:
+		return CodeTrailingData
+	}
+	return CodeUnexpectedToken
+}
+
+// NewUnexpectedTokenError reports a token that does not match what the parser expected
+func NewUnexpectedTokenError(tok Token, expected TokenType) error {
+	return &UnexpectedTokenError{Token: tok, Expected: expected}
+}
+
+// ReadError reports a failure of the reader a Lexer reads its input from.
+// Line, Column and Offset locate where the input stopped.
+type ReadError struct {
+	Line   int
+	Column int
+	Offset int64
+	Err    error // Error returned by the reader
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("Lexer error at line %d, column %d: reading input: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the reader's error
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns CodeRead
+func (e *ReadError) ErrorCode() ErrorCode {
+	return CodeRead
+}
+
+// ErrorList holds every error found in one pass over a document, in input
+// order. errors.Is and errors.As look through all of them.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more errors)", l[0], len(l)-1)
+}
+
+// Format writes the errors one per line under %+v, each with its snippet,
+// and the summary of Error otherwise
+func (l ErrorList) Format(f fmt.State, verb rune) {
+	if verb != 'v' || !f.Flag('+') {
+		FormatError(f, verb, l, nil)
+		return
+	}
+	for i, err := range l {
+		if i > 0 {
+			io.WriteString(f, "\n")
+		}
+		fmt.Fprintf(f, "%+v", err)
+	}
+}
+
+// Unwrap returns the errors in the list
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+// hint returns the suffix of a message suggesting a fix, "" when there is none
+func hint(h string) string {
+	if h == "" {
+		return ""
+	}
+	return "; " + h
+}
+
+// controlCharacterError reports the raw control character at the current
+// position in a string, with the escape to write in its place
+func (l *Lexer) controlCharacterError() error {
+	line, column := l.line, l.column
+	if l.ch == '\n' {
+		// readChar has already moved past the end of the line
+		line, column = l.lastLine, l.lastColumn+1
+	}
+	escape := fmt.Sprintf(`\u%04x`, l.ch)
+	switch l.ch {
+	case '\b':
+		escape = `\b`
+	case '\f':
+		escape = `\f`
+	case '\n':
+		escape = `\n`
+	case '\r':
+		escape = `\r`
+	case '\t':
+		escape = `\t`
+	}
+	return &scanError{
+		code: CodeControlCharacter,
+		msg:  fmt.Sprintf("unescaped control character %U in string", l.ch),
+		hint: "write it as " + escape,
+		at:   true, line: line, column: column, offset: l.offset + int64(l.position),
+	}
+}
+
+// errorAt locates err, found in the token starting at line and column, as a
+// syntax error; an error that knows the character it is at is placed there
+func (l *Lexer) errorAt(line, column int, err error) error {
+	if err == errUnterminatedString {
+		return &UnterminatedStringError{Line: line, Column: column, Offset: l.start, Snippet: l.Snippet(line, column)}
+	}
+	code, hint, offset := CodeUnexpectedCharacter, "", l.start
+	var scanErr *scanError
+	if errors.As(err, &scanErr) {
+		code, hint = scanErr.code, scanErr.hint
+		if scanErr.at {
+			line, column, offset = scanErr.line, scanErr.column, scanErr.offset
+		}
+	}
+	return &SyntaxError{Msg: err.Error(), Code: code, Hint: hint, Line: line, Column: column, Offset: offset, Snippet: l.Snippet(line, column)}
+}
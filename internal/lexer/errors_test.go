@@ -0,0 +1,85 @@
+package lexer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		input  string
+		msg    string
+		line   int
+		column int
+		offset int64
+	}{
+		{`[tru]`, "invalid literal tru", 1, 2, 1},
+		{"{\n  \"a\": 01}", "invalid number format: leading zeros are not allowed", 2, 8, 9},
+		{`["\q"]`, `invalid escape character: '\q'`, 1, 2, 1},
+		{`[1, @]`, `unexpected character: '@'`, 1, 5, 4},
+	}
+
+	for _, tt := range tests {
+		_, err := NewLexer(tt.input).Tokenize()
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected a *SyntaxError, got %T: %v", tt.input, err, err)
+		}
+		if syntaxErr.Msg != tt.msg || syntaxErr.Line != tt.line || syntaxErr.Column != tt.column || syntaxErr.Offset != tt.offset {
+			t.Errorf("%q: unexpected error %+v", tt.input, *syntaxErr)
+		}
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: expected errors.Is(err, ErrSyntax)", tt.input)
+		}
+	}
+}
+
+func TestUnterminatedStringError(t *testing.T) {
+	_, err := NewLexer(`{"a": "open}`).Tokenize()
+	var unterminated *UnterminatedStringError
+	if !errors.As(err, &unterminated) {
+		t.Fatalf("expected an *UnterminatedStringError, got %T: %v", err, err)
+	}
+	if got := *unterminated; got.Snippet == nil || got.Snippet.Column != 7 {
+		t.Errorf("expected a snippet at the opening quote, got %+v", got.Snippet)
+	} else if got.Snippet = nil; got != (UnterminatedStringError{Line: 1, Column: 7, Offset: 6}) {
+		t.Errorf("unexpected error %+v", got)
+	}
+	if !errors.Is(err, ErrSyntax) || err.Error() != "Lexer error at line 1, column 7: unterminated string literal" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestUnexpectedTokenError(t *testing.T) {
+	tok := Token{Type: TokenComma, Literal: ",", Line: 3, Column: 4}
+	err := NewUnexpectedTokenError(tok, TokenColon)
+	var unexpected *UnexpectedTokenError
+	if !errors.As(err, &unexpected) || unexpected.Token != tok || unexpected.Expected != TokenColon {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if !errors.Is(err, ErrSyntax) || err.Error() != `Parser error at line 3, column 4: expected :, got , ","` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSyntaxError_WrapsCauses(t *testing.T) {
+	errHook := errors.New("bad word")
+	lexer := NewLexer(`[x]`)
+	lexer.SetOptions(Options{Hooks: []TokenHook{func(s Scanner) (Token, bool, error) {
+		if s.Peek(0) == 'x' {
+			return Token{}, false, errHook
+		}
+		return Token{}, false, nil
+	}}})
+	if _, err := lexer.Tokenize(); !errors.Is(err, errHook) || !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected the hook error wrapped in a syntax error, got %v", err)
+	}
+
+	_, err := NewReaderLexer(io.MultiReader(strings.NewReader(`[`), iotest.ErrReader(io.ErrUnexpectedEOF))).Tokenize()
+	if !errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, ErrSyntax) {
+		t.Errorf("expected the read error wrapped but not flagged as a syntax error, got %v", err)
+	}
+}
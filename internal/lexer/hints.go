@@ -0,0 +1,103 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxHintLength is how many bytes are looked at for the word or string a hint quotes
+const maxHintLength = 64
+
+// keywordMistakes maps literals from other languages, lower-cased, to their JSON spelling
+var keywordMistakes = map[string]string{
+	"none":      "null",
+	"nil":       "null",
+	"undefined": "null",
+}
+
+// mistakeAt is errorAt for input no token starts with. When the input starts
+// with a common mistake, such as a single-quoted string or Python's True, the
+// error describes it and suggests the fix.
+func (l *Lexer) mistakeAt(line, column int, err error) error {
+	located := l.errorAt(line, column, err)
+	if e, ok := located.(*SyntaxError); ok {
+		if code, msg, hint := l.mistake(); hint != "" {
+			e.Code, e.Msg, e.Hint = code, msg, hint
+		}
+	}
+	return located
+}
+
+// mistake describes the common mistake at the current character and
+// suggests a fix; hint is "" when there is none to suggest
+func (l *Lexer) mistake() (code ErrorCode, msg, hint string) {
+	l.fill(maxHintLength)
+	rest := l.buf[l.position:min(len(l.buf), l.position+maxHintLength)]
+	if l.ch == '\'' {
+		if text, ok := singleQuoted(rest); ok {
+			return CodeSingleQuotes, "strings must be in double quotes", fmt.Sprintf("did you mean %s?", text)
+		}
+		return CodeSingleQuotes, "strings must be in double quotes", `did you mean '"'?`
+	}
+
+	word := leadingWord(rest)
+	if word == "" {
+		return "", "", ""
+	}
+	lower := strings.ToLower(word)
+	for _, keyword := range []string{"true", "false", "null"} {
+		if lower == keyword || len(word) > 1 && strings.HasPrefix(keyword, lower) {
+			return CodeInvalidLiteral, "invalid literal " + word, "did you mean " + keyword + "?"
+		}
+	}
+	if keyword, ok := keywordMistakes[lower]; ok {
+		return CodeInvalidLiteral, "invalid literal " + word, "did you mean " + keyword + "?"
+	}
+	if lower == "nan" || lower == "infinity" {
+		return "", "", "" // no JSON value stands in for them
+	}
+	return CodeUnquotedString, "unquoted text " + word, fmt.Sprintf("did you mean %q?", word)
+}
+
+// singleQuoted returns the single-quoted string at the start of text as a
+// double-quoted one, or false when it does not close on the same line
+func singleQuoted(text []byte) (string, bool) {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\'':
+			b.WriteByte('"')
+			return b.String(), true
+		case '\n', '\r':
+			return "", false
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			if i+1 < len(text) && text[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// leadingWord returns the identifier at the start of text, "" when there is none
+func leadingWord(text []byte) string {
+	end := 0
+	for end < len(text) {
+		r, size := utf8.DecodeRune(text[end:])
+		if !unicode.IsLetter(r) && r != '_' && r != '$' && (end == 0 || !unicode.IsDigit(r)) {
+			break
+		}
+		end += size
+	}
+	return string(text[:end])
+}
@@ -0,0 +1,49 @@
+package lexer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLexer_Hints(t *testing.T) {
+	tests := []struct {
+		input string
+		msg   string
+		hint  string
+	}{
+		{`{'a': 1}`, "strings must be in double quotes", `did you mean "a"?`},
+		{`['it\'s "x"']`, "strings must be in double quotes", `did you mean "it's \"x\""?`},
+		{"['open\n']", "strings must be in double quotes", `did you mean '"'?`},
+		{`{a: 1}`, "unquoted text a", `did you mean "a"?`},
+		{`{user_id$2: 1}`, "unquoted text user_id$2", `did you mean "user_id$2"?`},
+		{`{name: 1}`, "unquoted text name", `did you mean "name"?`},
+		{`[True]`, "invalid literal True", "did you mean true?"},
+		{`[FALSE]`, "invalid literal FALSE", "did you mean false?"},
+		{`[None]`, "invalid literal None", "did you mean null?"},
+		{`[nil]`, "invalid literal nil", "did you mean null?"},
+		{`[undefined]`, "invalid literal undefined", "did you mean null?"},
+		{`[fals]`, "invalid literal fals", "did you mean false?"},
+		{`[nul]`, "invalid literal nul", "did you mean null?"},
+		{`[NaN]`, "unexpected character: 'N'", ""},
+		{`[@]`, "unexpected character: '@'", ""},
+		{`[1, ~]`, "unexpected character: '~'", ""},
+	}
+	for _, tt := range tests {
+		for _, lex := range []*Lexer{NewLexer(tt.input), NewReaderLexer(strings.NewReader(tt.input))} {
+			_, err := lex.Tokenize()
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("%q: expected a *SyntaxError, got %T: %v", tt.input, err, err)
+			}
+			if syntaxErr.Msg != tt.msg || syntaxErr.Hint != tt.hint {
+				t.Errorf("%q: expected %q with hint %q, got %q with hint %q", tt.input, tt.msg, tt.hint, syntaxErr.Msg, syntaxErr.Hint)
+			}
+		}
+	}
+
+	_, err := NewLexer(`[True]`).Tokenize()
+	if want := "Lexer error at line 1, column 2: invalid literal True; did you mean true?"; err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+}
@@ -1,7 +1,10 @@
 package lexer
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"io"
+	"math/big"
 	"strconv"
 	"strings"
 	"unicode"
@@ -26,6 +29,9 @@ const (
 	TokenFalse        TokenType = "FALSE"
 	TokenNull         TokenType = "NULL"
 	TokenEOF          TokenType = "EOF"
+	TokenSkip         TokenType = "SKIP"       // Returned by a TokenHook for input to ignore, such as a dialect's comments
+	TokenIdentifier   TokenType = "IDENTIFIER" // Returned by a TokenHook for an unquoted name, which the parser accepts as an object key
+	TokenInvalid      TokenType = "INVALID"    // Input that failed to scan, returned in place of the error under Options.Recover
 )
 
 // Token represents a lexical token with type and literal value
@@ -33,148 +39,600 @@ type Token struct {
 	Type    TokenType
 	Literal string
 	Line    int // Line number in input
-	Column  int // Column number in input
+	Column  int // Column number in input, counting characters rather than bytes
+
+	Offset    int64 // Byte offset of the first character in input
+	End       int64 // Byte offset just past the last character
+	EndLine   int   // Line number just past the last character
+	EndColumn int   // Column number just past the last character
+
+	Err error // Why a TokenInvalid token failed to scan
+}
+
+// Comment is a comment the lexer skipped, recorded under Options.KeepComments
+type Comment struct {
+	Text   string // The whole comment, with its // or /* */
+	Line   int    // Line number of its first character
+	Column int    // Column number of its first character
+	Offset int64  // Byte offset of its first character
 }
 
+// Len returns the length of the token's source text in bytes, so that
+// input[tok.Offset:tok.Offset+tok.Len()] is the text it was scanned from
+func (t Token) Len() int {
+	return int(t.End - t.Offset)
+}
+
+// Options enables extensions to, and restrictions on, the standard grammar
+type Options struct {
+	AllowComments          bool        // Skip // line and /* block */ comments between tokens
+	RejectInvalidUTF8      bool        // Fail on invalid UTF-8 in strings instead of substituting U+FFFD
+	AllowSingleQuotes      bool        // Accept 'single-quoted' strings, in which \' escapes a quote
+	AllowUnquotedKeys      bool        // Scan a bare word followed by a colon, as in {foo: 1}, as a string
+	AllowNonFinite         bool        // Scan NaN, Infinity and -Infinity as numbers
+	AllowHexNumbers        bool        // Scan hexadecimal integers such as 0x1F as numbers, written in decimal
+	AllowLineContinuations bool        // Drop a backslash and the line break after it inside strings
+	AllowRelaxedNumbers    bool        // Scan +1, 007, .5 and 5. as numbers, written as JSON numbers
+	AllowControlCharacters bool        // Accept raw U+0000 to U+001F in strings, which RFC 8259 requires escaped
+	KeepComments           bool        // Under AllowComments, record the comments skipped; see Comments
+	Hooks                  []TokenHook // Dialect tokens, tried in order before the standard ones
+	Recover                bool        // Return syntax errors as TokenInvalid tokens and carry on after them
+}
+
+// Scanner is the view of the input given to a TokenHook
+type Scanner interface {
+	// Peek returns the character i positions after the current one, or 0 past the end of input
+	Peek(i int) rune
+	// Advance consumes n characters
+	Advance(n int)
+}
+
+// TokenHook recognizes a dialect token at the current character. When the
+// input does not start with its token it returns ok false without advancing;
+// otherwise it advances past the token and returns it. Its position fields
+// are filled in by the lexer.
+type TokenHook func(s Scanner) (tok Token, ok bool, err error)
+
+// readChunkSize is how many bytes a reader-backed Lexer requests per refill
+const readChunkSize = 4096
+
 // Lexer represents a lexical scanner
 type Lexer struct {
-	input        string
-	position     int  // current position in input (points to current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           rune // current char under examination
-	line         int  // current line number
-	column       int  // current column number
+	buf          []byte      // buffered input; the whole input unless reading from a reader
+	reader       io.Reader   // source of further input, nil once exhausted or for in-memory input
+	readErr      error       // error returned by reader, other than io.EOF
+	offset       int64       // absolute input offset of buf[0]
+	start        int64       // absolute input offset of the token being scanned
+	mark         int         // start of the token being scanned; bytes before it may be discarded
+	position     int         // current position in buf (points to current char)
+	readPosition int         // current reading position in buf (after current char)
+	ch           rune        // current char under examination
+	eof          bool        // whether the input is exhausted
+	started      bool        // whether the first char has been read
+	discard      bool        // whether string literals are checked without being built
+	opts         Options     // grammar extensions and restrictions
+	line         int         // current line number
+	column       int         // current column number
+	lastLine     int         // line number of the previous char
+	lastColumn   int         // column number of the previous char
+	lineStarts   []int64     // absolute offset of each line's first byte; only the current line's when streaming
+	streaming    bool        // whether the input comes from a reader
+	comments     []Comment   // comments skipped so far, under Options.KeepComments
+	snippetMark  snippetMark // where the last snippet's column was found
 }
 
 // NewLexer initializes a new Lexer with the given input
 func NewLexer(input string) *Lexer {
-	l := &Lexer{
-		input:  input,
-		line:   1,
-		column: 0,
+	return &Lexer{
+		buf:        []byte(input),
+		line:       1,
+		column:     0,
+		lineStarts: []int64{0},
+	}
+}
+
+// NewBytesLexer initializes a new Lexer that scans data in place, without copying it.
+// data must not be modified until lexing is finished. Token literals are
+// always copied out of data, so keeping a token alive never pins the input.
+func NewBytesLexer(data []byte) *Lexer {
+	return &Lexer{
+		buf:        data,
+		line:       1,
+		column:     0,
+		lineStarts: []int64{0},
+	}
+}
+
+// NewReaderLexer initializes a new Lexer that reads its input from r incrementally.
+// Only the token being scanned is buffered, so arbitrarily large inputs can be tokenized.
+func NewReaderLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		buf:        make([]byte, 0, readChunkSize),
+		reader:     r,
+		line:       1,
+		column:     0,
+		lineStarts: []int64{0},
+		streaming:  true,
+	}
+}
+
+// fill reads from the reader until at least n bytes are buffered after readPosition
+// or the input is exhausted, discarding bytes before the current token first
+func (l *Lexer) fill(n int) {
+	if l.reader == nil || len(l.buf)-l.readPosition >= n {
+		return
+	}
+
+	if l.mark > 0 {
+		l.offset += int64(l.mark)
+		kept := copy(l.buf, l.buf[l.mark:])
+		l.buf = l.buf[:kept]
+		l.position -= l.mark
+		l.readPosition -= l.mark
+		l.mark = 0
+	}
+
+	for len(l.buf)-l.readPosition < n {
+		if len(l.buf) == cap(l.buf) {
+			grown := make([]byte, len(l.buf), 2*cap(l.buf)+readChunkSize)
+			copy(grown, l.buf)
+			l.buf = grown
+		}
+		read, err := l.reader.Read(l.buf[len(l.buf):cap(l.buf)])
+		l.buf = l.buf[:len(l.buf)+read]
+		if err != nil {
+			if err != io.EOF {
+				l.readErr = err
+			}
+			l.reader = nil
+			return
+		}
 	}
-	l.readChar()
-	return l
 }
 
 // readChar reads the next character and updates positions
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
+	l.started = true
+	l.lastLine, l.lastColumn = l.line, l.column
+	if !utf8.FullRune(l.buf[l.readPosition:]) {
+		l.fill(utf8.UTFMax)
+	}
+
+	l.position = l.readPosition
+	if l.readPosition >= len(l.buf) {
 		l.ch = 0 // EOF
+		l.eof = true
+		return
+	}
+
+	r, size := utf8.DecodeRune(l.buf[l.readPosition:])
+	l.ch = r
+	l.readPosition += size
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+		if next := l.offset + int64(l.readPosition); l.streaming {
+			l.lineStarts[0] = next
+		} else {
+			l.lineStarts = append(l.lineStarts, next)
+		}
 	} else {
-		r, size := utf8.DecodeLastRuneInString(l.input[l.readPosition:])
-		l.ch = r
-		l.readPosition += size
-		l.position = l.readPosition
 		l.column++
-		if l.ch == '\n' {
-			l.line++
-			l.column = 0
-		}
 	}
-	l.position = l.readPosition
-	l.readPosition++
+}
+
+// SetOptions changes the grammar options used for the tokens that follow
+func (l *Lexer) SetOptions(opts Options) {
+	l.opts = opts
+}
+
+// Position returns the absolute byte offset, line and column of the current character
+func (l *Lexer) Position() (offset int64, line, column int) {
+	return l.offset + int64(l.position), l.line, l.column
+}
+
+// TokenOffset returns the absolute byte offset at which the token most
+// recently scanned by NextToken begins, or at which it failed to scan
+func (l *Lexer) TokenOffset() int64 {
+	return l.start
+}
+
+// Literal returns the literal of a token scanned by SkipToken, by scanning
+// its text again. ok is false once the text is no longer buffered, as
+// happens with earlier tokens when reading from a reader.
+func (l *Lexer) Literal(tok Token) (literal string, ok bool) {
+	start, end := tok.Offset-l.offset, tok.End-l.offset
+	if start < 0 || end > int64(len(l.buf)) || start >= end {
+		return "", false
+	}
+	again := NewBytesLexer(l.buf[start:end])
+	again.SetOptions(Options{Hooks: l.opts.Hooks, RejectInvalidUTF8: l.opts.RejectInvalidUTF8})
+	rescanned, err := again.NextToken()
+	if err != nil || rescanned.Type != tok.Type {
+		return "", false
+	}
+	return rescanned.Literal, true
 }
 
 // peekChar peeks ahead to the next character without advancing the lexer
 func (l *Lexer) peekChar() rune {
-	if l.readPosition >= len(l.input) {
+	if !utf8.FullRune(l.buf[l.readPosition:]) {
+		l.fill(utf8.UTFMax)
+	}
+	if l.readPosition >= len(l.buf) {
 		return 0
 	}
-	r, _ := utf8.DecodeLastRuneInString(l.input[l.readPosition:])
+	r, _ := utf8.DecodeRune(l.buf[l.readPosition:])
 	return r
 }
 
 // skipWhiteSpace skips over any whitespace characters
 func (l *Lexer) skipWhitespace() {
-	for unicode.IsSpace(l.ch) {
+	for !l.eof && isWhitespace(l.ch) {
 		l.readChar()
 	}
 }
 
-// Tokenize converts the input string into a slice of Tokens
+// isWhitespace reports whether ch is one of the four characters RFC 8259
+// allows between tokens. Other Unicode spaces, such as '\v' or U+00A0, are
+// unexpected characters; dialects that allow them skip them with a hook.
+func isWhitespace(ch rune) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// skipComment skips the comment starting at the current '/'
+func (l *Lexer) skipComment() error {
+	comment := Comment{Line: l.line, Column: l.column, Offset: l.offset + int64(l.position)}
+	var text strings.Builder
+	keep := func() {
+		if l.opts.KeepComments {
+			text.WriteRune(l.ch)
+		}
+	}
+	switch l.peekChar() {
+	case '/':
+		for !l.eof && l.ch != '\n' {
+			l.mark = l.position // comments need not stay buffered
+			keep()
+			l.readChar()
+		}
+	case '*':
+		for range 2 { // "/*"
+			keep()
+			l.readChar()
+		}
+		for !(l.ch == '*' && l.peekChar() == '/') {
+			if l.eof {
+				return scanErrorf(CodeUnterminatedComment, "unterminated comment")
+			}
+			l.mark = l.position
+			keep()
+			l.readChar()
+		}
+		for range 2 { // "*/"
+			keep()
+			l.readChar()
+		}
+	default:
+		return scanErrorf(CodeUnexpectedCharacter, "unexpected character: '/'")
+	}
+	if l.opts.KeepComments {
+		comment.Text = strings.TrimSuffix(text.String(), "\r")
+		l.comments = append(l.comments, comment)
+	}
+	return nil
+}
+
+// contextCheckInterval is how many tokens TokenizeContext scans between checks of its context
+const contextCheckInterval = 1024
+
+// Tokenize converts the whole input into a slice of Tokens, ending with an EOF token
 func (l *Lexer) Tokenize() ([]Token, error) {
-	var tokens []Token
+	return l.TokenizeContext(context.Background())
+}
 
-	for l.ch != 0 {
-		l.skipWhitespace() // Skip any whitespace characters
+// TokenizeContext is like Tokenize but stops with ctx.Err() once ctx is done.
+// The context is checked every few hundred tokens, so cancellation is prompt but not immediate.
+func (l *Lexer) TokenizeContext(ctx context.Context) ([]Token, error) {
+	var tokens []Token
 
-		var tok Token
-		tok.Line = l.line
-		tok.Column = l.column
-
-		switch l.ch {
-		case '{':
-			tok = Token{Type: TokenLeftBrace, Literal: "{", Line: l.line, Column: l.column}
-		case '}':
-			tok = Token{Type: TokenRightBrace, Literal: "}", Line: l.line, Column: l.column}
-		case '[':
-			tok = Token{Type: TokenLeftBracket, Literal: "[", Line: l.line, Column: l.column}
-		case ']':
-			tok = Token{Type: TokenRightBracket, Literal: "]", Line: l.line, Column: l.column}
-		case ':':
-			tok = Token{Type: TokenColon, Literal: ":", Line: l.line, Column: l.column}
-		case ',':
-			tok = Token{Type: TokenComma, Literal: ",", Line: l.line, Column: l.column} // Create token for comma
-		case '"':
-			str, err := l.readString()
-			if err != nil {
+	for {
+		if len(tokens)%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
 				return nil, err
 			}
-			tok = Token{Type: TokenString, Literal: str, Line: l.line, Column: l.column}
-			tokens = append(tokens, tok)
-			continue
-		case 't':
-			if l.peekKeyWord("true") {
-				tok = Token{Type: TokenTrue, Literal: "true", Line: l.line, Column: l.column}
-				l.advanceBy(len("true"))
-			} else {
-				return nil, fmt.Errorf("Lexer error at line %d, column %d: invalid token starting with 't'", l.line, l.column)
-			}
-		case 'f':
-			if l.peekKeyWord("false") {
-				tok = Token{Type: TokenFalse, Literal: "false", Line: l.line, Column: l.column}
-				l.advanceBy(len("false"))
-			} else {
-				return nil, fmt.Errorf("Lexer error at line %d, column %d: invalid token starting with 'f'", l.line, l.column)
+		}
+		tok, err := l.NextToken()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok) // Append the created token to the tokens slice
+		if tok.Type == TokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// NextToken scans and returns the next token. Once the input is exhausted it
+// keeps returning an EOF token. Under Options.Recover a syntax error is
+// returned as a TokenInvalid token holding it instead, and scanning resumes
+// after the bad text.
+func (l *Lexer) NextToken() (Token, error) {
+	tok, err := l.scan()
+	if err != nil && l.opts.Recover && errors.Is(err, ErrSyntax) {
+		return l.resync(err), nil
+	}
+	return tok, err
+}
+
+// Comments returns the comments skipped so far under Options.KeepComments,
+// in input order
+func (l *Lexer) Comments() []Comment {
+	return l.comments
+}
+
+// DropComments forgets the comments recorded so far, so that reading a long
+// stream does not hold on to all of them
+func (l *Lexer) DropComments() {
+	l.comments = nil
+}
+
+// scan scans the next token, stopping at the first syntax error
+func (l *Lexer) scan() (Token, error) {
+	if !l.started {
+		l.readChar()
+	}
+
+	for {
+		l.mark = l.position
+		l.skipWhitespace() // Skip any whitespace characters
+		for l.opts.AllowComments && l.ch == '/' && !l.eof {
+			line, column := l.line, l.column
+			l.start = l.offset + int64(l.position)
+			if err := l.skipComment(); err != nil {
+				return Token{}, l.errorAt(line, column, err)
 			}
-		case 'n':
-			if l.peekKeyWord("null") {
-				tok = Token{Type: TokenNull, Literal: "null", Line: l.line, Column: l.column}
-				l.advanceBy(len("null"))
-			} else {
-				return nil, fmt.Errorf("Lexer error at line %d, column %d: invalid token starting with 'n'", l.line, l.column)
+			l.skipWhitespace()
+		}
+		l.mark = l.position
+		l.start = l.offset + int64(l.position)
+		if l.readErr != nil {
+			return Token{}, &ReadError{Line: l.line, Column: l.column, Offset: l.offset + int64(l.position), Err: l.readErr}
+		}
+		if l.eof {
+			tok := Token{Type: TokenEOF, Literal: "", Line: l.line, Column: l.column + 1, Offset: l.start, End: l.start}
+			tok.EndLine, tok.EndColumn = tok.Line, tok.Column
+			return tok, nil
+		}
+
+		tok, ok, err := l.scanHook()
+		if err != nil {
+			return Token{}, err
+		}
+		if !ok {
+			break
+		}
+		if tok.Type != TokenSkip {
+			return tok, nil
+		}
+	}
+
+	line, column := l.line, l.column
+	if l.opts.AllowUnquotedKeys {
+		if n := l.unquotedKeyLength(); n > 0 {
+			key := string(l.buf[l.position : l.position+n])
+			l.advanceBy(utf8.RuneCountInString(key))
+			return l.locate(Token{Type: TokenString, Literal: key}, line, column), nil
+		}
+	}
+	if l.opts.AllowNonFinite {
+		for _, word := range []string{"NaN", "Infinity", "-Infinity"} {
+			if l.peekKeyWord(word) {
+				l.advanceBy(len(word))
+				return l.locate(Token{Type: TokenNumber, Literal: word}, line, column), nil
 			}
-		default:
-			if l.isStartOfNumber(l.ch) {
-				num, err := l.readNumber()
-				if err != nil {
-					return nil, fmt.Errorf("Lexer error at line %d, column %d: %v", l.line, l.column, err)
-				}
-				tok = Token{Type: TokenNumber, Literal: num, Line: l.line, Column: l.column}
-			} else {
-				return nil, fmt.Errorf("Lexer error at line %d, column %d: unexpected character: %c", l.ch, l.line, l.column)
+		}
+	}
+	var tok Token
+
+	switch l.ch {
+	case '{':
+		tok = Token{Type: TokenLeftBrace, Literal: "{"}
+	case '}':
+		tok = Token{Type: TokenRightBrace, Literal: "}"}
+	case '[':
+		tok = Token{Type: TokenLeftBracket, Literal: "["}
+	case ']':
+		tok = Token{Type: TokenRightBracket, Literal: "]"}
+	case ':':
+		tok = Token{Type: TokenColon, Literal: ":"}
+	case ',':
+		tok = Token{Type: TokenComma, Literal: ","} // Create token for comma
+	case '\'':
+		if !l.opts.AllowSingleQuotes {
+			return Token{}, l.mistakeAt(line, column, scanErrorf(CodeUnexpectedCharacter, "unexpected character: %q", l.ch))
+		}
+		fallthrough
+	case '"':
+		str, err := l.readString()
+		if err != nil {
+			return Token{}, l.errorAt(line, column, err)
+		}
+		tok = Token{Type: TokenString, Literal: str}
+	case 't':
+		if !l.peekKeyWord("true") {
+			return Token{}, l.mistakeAt(line, column, scanErrorf(CodeInvalidLiteral, "invalid token starting with 't'"))
+		}
+		tok = Token{Type: TokenTrue, Literal: "true"}
+		l.advanceBy(len("true") - 1)
+	case 'f':
+		if !l.peekKeyWord("false") {
+			return Token{}, l.mistakeAt(line, column, scanErrorf(CodeInvalidLiteral, "invalid token starting with 'f'"))
+		}
+		tok = Token{Type: TokenFalse, Literal: "false"}
+		l.advanceBy(len("false") - 1)
+	case 'n':
+		if !l.peekKeyWord("null") {
+			return Token{}, l.mistakeAt(line, column, scanErrorf(CodeInvalidLiteral, "invalid token starting with 'n'"))
+		}
+		tok = Token{Type: TokenNull, Literal: "null"}
+		l.advanceBy(len("null") - 1)
+	default:
+		if !l.isStartOfNumber(l.ch) {
+			return Token{}, l.mistakeAt(line, column, scanErrorf(CodeUnexpectedCharacter, "unexpected character: %q", l.ch))
+		}
+		num, err := l.readNumber()
+		if err != nil {
+			return Token{}, l.errorAt(line, column, err)
+		}
+		// readNumber stops on the first character after the number
+		return l.locate(Token{Type: TokenNumber, Literal: num}, line, column), nil
+	}
+
+	l.readChar() // Move to the next character for the next call
+	return l.locate(tok, line, column), nil
+}
+
+// locate fills in the position of tok, which starts at line and column and
+// ends just before the current character
+func (l *Lexer) locate(tok Token, line, column int) Token {
+	tok.Line, tok.Column = line, column
+	tok.Offset, tok.End = l.start, l.offset+int64(l.position)
+	tok.EndLine, tok.EndColumn = l.lastLine, l.lastColumn+1
+	return tok
+}
+
+// resync skips the rest of the token that failed with err and returns it as
+// a TokenInvalid token: a string up to its closing quote, anything else up to
+// the next whitespace or punctuation
+func (l *Lexer) resync(err error) Token {
+	var line, column int
+	switch e := err.(type) {
+	case *SyntaxError:
+		line, column = e.Line, e.Column
+	case *UnterminatedStringError:
+		line, column = e.Line, e.Column
+	default:
+		line, column = l.line, l.column
+	}
+
+	first := max(int(l.start-l.offset), 0) // comments may have let their start go
+	if l.offset+int64(l.position) == l.start {
+		l.readChar() // always make progress
+	}
+	if first < len(l.buf) && (l.buf[first] == '"' || l.buf[first] == '\'' && l.opts.AllowSingleQuotes) {
+		quote := rune(l.buf[first])
+		for !l.eof && l.ch != quote {
+			if l.ch == '\\' {
+				l.readChar()
 			}
+			l.readChar()
 		}
+		l.readChar() // the closing quote
+	} else {
+		for !l.eof && !unicode.IsSpace(l.ch) && !strings.ContainsRune(`{}[]:,"`, l.ch) {
+			l.readChar()
+		}
+	}
 
-		tokens = append(tokens, tok) // Append the created token to the tokens slice
-		l.readChar()                 // Move to the next character for the next iteration
+	literal := string(l.buf[first:l.position])
+	return l.locate(Token{Type: TokenInvalid, Literal: literal, Err: err}, line, column)
+}
+
+// scanHook offers the current character to the dialect token hooks
+func (l *Lexer) scanHook() (Token, bool, error) {
+	line, column := l.line, l.column
+	start := l.offset + int64(l.position)
+	for _, hook := range l.opts.Hooks {
+		tok, ok, err := hook(hookScanner{l})
+		if err != nil {
+			return Token{}, false, &SyntaxError{Msg: err.Error(), Code: CodeDialect, Line: line, Column: column, Offset: start, Err: err, Snippet: l.Snippet(line, column)}
+		}
+		consumed := l.offset+int64(l.position) != start
+		if ok != consumed {
+			return Token{}, false, &SyntaxError{Msg: "token hook must advance exactly when it matches", Code: CodeBadHook, Line: line, Column: column, Offset: start, Snippet: l.Snippet(line, column)}
+		}
+		if ok {
+			return l.locate(tok, line, column), true, nil
+		}
 	}
+	return Token{}, false, nil
+}
+
+// hookScanner implements Scanner over the lexer's input
+type hookScanner struct {
+	l *Lexer
+}
+
+func (s hookScanner) Peek(i int) rune {
+	l := s.l
+	l.fill(utf8.UTFMax * (i + 1))
+	pos := l.position
+	for ; i > 0 && pos < len(l.buf); i-- {
+		_, size := utf8.DecodeRune(l.buf[pos:])
+		pos += size
+	}
+	if l.eof || pos >= len(l.buf) {
+		return 0
+	}
+	r, _ := utf8.DecodeRune(l.buf[pos:])
+	return r
+}
 
-	// Append EOF token
-	tokens = append(tokens, Token{Type: TokenEOF, Literal: "", Line: l.line, Column: l.column})
+func (s hookScanner) Advance(n int) {
+	for i := 0; i < n && !s.l.eof; i++ {
+		s.l.readChar()
+	}
+}
 
-	return tokens, nil
+// SkipToken scans the next token like NextToken but leaves the literal of
+// strings and numbers empty, so input can be checked without allocating them
+func (l *Lexer) SkipToken() (Token, error) {
+	l.discard = true
+	tok, err := l.NextToken()
+	l.discard = false
+	return tok, err
 }
 
-// peekKeyword checks if the upcoming characters match the expected keyword
+// peekKeyword checks if the input at the current character matches the expected keyword
 func (l *Lexer) peekKeyWord(expected string) bool {
-	end := l.readPosition + len(expected)
-	if end > len(l.input) {
+	l.fill(len(expected) - (l.readPosition - l.position))
+	end := l.position + len(expected)
+	if end > len(l.buf) {
 		return false
 	}
 
-	return l.input[l.readPosition:end] == expected
+	return string(l.buf[l.position:end]) == expected
+}
+
+// unquotedKeyLength returns the length in bytes of the word of letters,
+// digits, _ and $ at the current character when a colon follows it, and 0
+// when none does. The word may not start with a digit.
+func (l *Lexer) unquotedKeyLength() int {
+	n := 0
+	for {
+		l.fill(n + utf8.UTFMax - (l.readPosition - l.position))
+		r, size := utf8.DecodeRune(l.buf[l.position+n:])
+		if !unicode.IsLetter(r) && r != '_' && r != '$' && (n == 0 || !unicode.IsDigit(r)) {
+			break
+		}
+		n += size
+	}
+	if n == 0 {
+		return 0
+	}
+	for i := n; ; i++ {
+		l.fill(i + 1 - (l.readPosition - l.position))
+		if l.position+i >= len(l.buf) {
+			return 0
+		}
+		if ch := rune(l.buf[l.position+i]); isWhitespace(ch) {
+			continue
+		} else if ch == ':' {
+			return n
+		}
+		return 0
+	}
 }
 
 // advanceBy advances the lexer by n characters
@@ -186,17 +644,24 @@ func (l *Lexer) advanceBy(n int) {
 
 // isStartOfNumber checks if the rune can start a number
 func (l *Lexer) isStartOfNumber(r rune) bool {
+	if l.opts.AllowRelaxedNumbers && (r == '+' || r == '.') {
+		return true
+	}
 	return r == '-' || unicode.IsDigit(r)
 }
 
 // readNumber reads a number token from the input, including exponents
 func (l *Lexer) readNumber() (string, error) {
-	startPos := l.position
+	l.mark = l.position // keep the digits buffered while reading from a reader
 
-	if err := l.consumeMinus(); err != nil {
+	if err := l.consumeSign(); err != nil {
 		return "", err
 	}
 
+	if l.opts.AllowHexNumbers && l.ch == '0' && (l.peekChar() == 'x' || l.peekChar() == 'X') {
+		return l.readHexNumber()
+	}
+
 	if err := l.consumeInteger(); err != nil {
 		return "", err
 	}
@@ -209,54 +674,124 @@ func (l *Lexer) readNumber() (string, error) {
 		return "", err
 	}
 
-	numStr := l.input[startPos:l.position]
+	// The relaxed grammar lets a lone point through, which ParseFloat
+	// catches. A number too large for a float64 is still a number: the
+	// literal is kept, and whether it fits is up to whoever decodes it.
+	if _, err := strconv.ParseFloat(string(l.buf[l.mark:l.position]), 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+		return "", scanErrorf(CodeBadNumber, "invalid number format: %v", err)
+	}
+
+	// Ensurr that the number is not followed by a letter or digit, or by a
+	// point that would start another relaxed number
+	if unicode.IsLetter(l.ch) || isDigit(l.ch) || l.ch == '.' && l.opts.AllowRelaxedNumbers {
+		return "", scanErrorf(CodeBadNumber, "invalid character following number")
+	}
+
+	if l.discard {
+		return "", nil
+	}
+	if l.opts.AllowRelaxedNumbers {
+		return normalizeNumber(string(l.buf[l.mark:l.position])), nil
+	}
+	return string(l.buf[l.mark:l.position]), nil
+}
 
-	// Validate number using strconv
-	if _, err := strconv.ParseFloat(numStr, 64); err != nil {
-		return "", fmt.Errorf("invalid number format: %v", err)
+// normalizeNumber rewrites a number scanned under AllowRelaxedNumbers as a
+// JSON number, without a plus sign or leading zeros and with digits on both
+// sides of its decimal point, dropping the point when none follow it
+func normalizeNumber(text string) string {
+	sign := ""
+	switch text[0] {
+	case '-':
+		sign, text = "-", text[1:]
+	case '+':
+		text = text[1:]
+	}
+	end := strings.IndexAny(text, ".eE")
+	if end < 0 {
+		end = len(text)
+	}
+	integer, rest := strings.TrimLeft(text[:end], "0"), text[end:]
+	if integer == "" {
+		integer = "0"
 	}
+	if rest == "." || strings.HasPrefix(rest, ".e") || strings.HasPrefix(rest, ".E") {
+		rest = rest[1:]
+	}
+	return sign + integer + rest
+}
 
-	// Ensurr that the number is not followed by a letter or digit
+// readHexNumber reads the rest of a hexadecimal integer from its leading 0,
+// returning it in decimal as JSON5 does, so 0x1F reads as 31
+func (l *Lexer) readHexNumber() (string, error) {
+	l.advanceBy(len("0x"))
+	// Measured from l.mark, which stays at the start of the number as the buffer refills
+	start := l.position - l.mark
+	for isHexDigit(l.ch) {
+		l.readChar()
+	}
+	if l.position-l.mark == start {
+		return "", scanErrorf(CodeBadNumber, "expected hex digit in number")
+	}
 	if unicode.IsLetter(l.ch) || isDigit(l.ch) {
-		return "", fmt.Errorf("invalid character following number")
+		return "", scanErrorf(CodeBadNumber, "invalid character following number")
 	}
 
-	return numStr, nil
+	if l.discard {
+		return "", nil
+	}
+	n, _ := new(big.Int).SetString(string(l.buf[l.mark+start:l.position]), 16)
+	if l.buf[l.mark] == '-' {
+		n.Neg(n)
+	}
+	return n.String(), nil
 }
 
-// consumeMinus handles the optional minus sign
-func (l *Lexer) consumeMinus() error {
-	if l.ch == '-' {
+// consumeSign handles the optional minus sign, or plus sign under AllowRelaxedNumbers
+func (l *Lexer) consumeSign() error {
+	if l.ch == '-' || l.ch == '+' && l.opts.AllowRelaxedNumbers {
 		l.readChar()
 	}
 	return nil
 }
 
-// consumeInteger parses the integer part and enforces no leading zeros
+// consumeInteger parses the integer part and enforces no leading zeros.
+// Under AllowRelaxedNumbers it accepts leading zeros, and no digits
+// before a decimal point.
 func (l *Lexer) consumeInteger() error {
+	if l.opts.AllowRelaxedNumbers {
+		if !isDigit(l.ch) && l.ch != '.' {
+			return scanErrorf(CodeBadNumber, "expected digit in number")
+		}
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+		return nil
+	}
 	if l.ch == '0' {
 		l.readChar()
 		// Leading zeros are not allowed unless the number is exactly '0'
 		if isDigit(l.ch) {
-			return fmt.Errorf("invalid number format: leading zeros are not allowed")
+			return scanErrorf(CodeBadNumber, "invalid number format: leading zeros are not allowed")
 		}
 	} else if isDigitOneToNine(l.ch) {
 		for isDigit(l.ch) {
 			l.readChar()
 		}
 	} else {
-		return fmt.Errorf("expected digit in number")
+		return scanErrorf(CodeBadNumber, "expected digit in number")
 	}
 
 	return nil
 }
 
-// consumeFraction parses the fractional part of the number
+// consumeFraction parses the fractional part of the number, which may have
+// no digits under AllowRelaxedNumbers; the number is checked as a whole later
 func (l *Lexer) consumeFraction() error {
 	if l.ch == '.' {
 		l.readChar()
-		if !isDigit(l.ch) {
-			return fmt.Errorf("expected digit after decimal point")
+		if !isDigit(l.ch) && !l.opts.AllowRelaxedNumbers {
+			return scanErrorf(CodeBadNumber, "expected digit after decimal point")
 		}
 		for isDigit(l.ch) {
 			l.readChar()
@@ -273,7 +808,7 @@ func (l *Lexer) consumeExponent() error {
 			l.readChar()
 		}
 		if !isDigit(l.ch) {
-			return fmt.Errorf("expected digit after exponent")
+			return scanErrorf(CodeBadNumber, "expected digit after exponent")
 		}
 		for isDigit(l.ch) {
 			l.readChar()
@@ -293,50 +828,65 @@ func isDigitOneToNine(ch rune) bool {
 	return ch >= '1' && ch <= '9'
 }
 
-// readString reads a string token, handling escape sequences and Unicode
+// readString reads a string token, handling escape sequences and Unicode.
+// The string ends at the quote it starts with, ' or ".
 func (l *Lexer) readString() (string, error) {
 	var strBuilder strings.Builder
 
+	quote := l.ch
 	l.readChar() // Skip the opening quote
 
-	for l.ch != '"' && l.ch != 0 {
+	for l.ch != quote && !l.eof {
+		r := l.ch
+		if l.ch < 0x20 && !l.opts.AllowControlCharacters {
+			return "", l.controlCharacterError()
+		}
 		if l.ch == '\\' {
 			l.readChar()
 			switch l.ch {
-			case '"':
-				strBuilder.WriteRune('"')
-			case '\\':
-				strBuilder.WriteRune('\\')
-			case '/':
-				strBuilder.WriteRune('/')
+			case '"', '\\', '/', quote:
+				r = l.ch
 			case 'b':
-				strBuilder.WriteRune('\b')
+				r = '\b'
 			case 'f':
-				strBuilder.WriteRune('\f')
+				r = '\f'
 			case 'n':
-				strBuilder.WriteRune('\n')
+				r = '\n'
 			case 'r':
-				strBuilder.WriteRune('\r')
+				r = '\r'
 			case 't':
-				strBuilder.WriteRune('\t')
+				r = '\t'
 			case 'u':
 				// Handle Unicode escape sequence
-				r, err := l.readUnicode()
-				if err != nil {
+				var err error
+				if r, err = l.readUnicode(); err != nil {
 					return "", err
 				}
-				strBuilder.WriteRune(r)
+			case '\r', '\n', '\u2028', '\u2029':
+				if !l.opts.AllowLineContinuations {
+					return "", scanErrorf(CodeBadEscape, "invalid escape character: '\\%c'", l.ch)
+				}
+				// A line continuation stands for nothing
+				if l.ch == '\r' && l.peekChar() == '\n' {
+					l.readChar()
+				}
+				l.readChar()
+				continue
 			default:
-				return "", fmt.Errorf("invalid escape character: '\\%c'", l.ch)
+				return "", scanErrorf(CodeBadEscape, "invalid escape character: '\\%c'", l.ch)
 			}
-		} else {
-			strBuilder.WriteRune(l.ch)
+		}
+		if l.opts.RejectInvalidUTF8 && l.ch == utf8.RuneError && l.readPosition-l.position == 1 {
+			return "", scanErrorf(CodeInvalidUTF8, "invalid UTF-8 encoding in string")
+		}
+		if !l.discard {
+			strBuilder.WriteRune(r)
 		}
 		l.readChar()
 	}
 
-	if l.ch != '"' {
-		return "", fmt.Errorf("unterminated string literal")
+	if l.ch != quote {
+		return "", errUnterminatedString
 	}
 
 	return strBuilder.String(), nil
@@ -348,7 +898,7 @@ func (l *Lexer) readUnicode() (rune, error) {
 	for i := 0; i < 4; i++ {
 		l.readChar()
 		if !isHexDigit(l.ch) {
-			return 0, fmt.Errorf("invalid unicode escape sequence")
+			return 0, scanErrorf(CodeBadEscape, "invalid unicode escape sequence")
 		}
 		hexDigits[i] = l.ch
 	}
@@ -356,61 +906,39 @@ func (l *Lexer) readUnicode() (rune, error) {
 	codePoint := hexToInt(hexDigits)
 	r := rune(codePoint)
 
-	if unicode.IsSurrogate(r) {
+	if utf16.IsSurrogate(r) {
 		if !l.peekUnicodeSurrogatePair() {
-			return 0, fmt.Errorf("invalid surrogate pair in Unicode escape")
+			return 0, scanErrorf(CodeBadEscape, "invalid surrogate pair in Unicode escape")
 		}
 		// Read the low surrogate
-		l.readChar() // Skip 'u'
-		l.readChar() // Move to low surrogate
+		l.readChar() // Move to the backslash
+		l.readChar() // Move to 'u'
 		lexHexDigits, err := l.readUnicodeSurrogate()
 		if err != nil {
 			return 0, err
 		}
 		r = utf16.DecodeRune(r, lexHexDigits)
 		if r == utf8.RuneError {
-			return 0, fmt.Errorf("invalid surrogate pair")
+			return 0, scanErrorf(CodeBadEscape, "invalid surrogate pair")
 		}
 	}
 
 	return r, nil
 }
 
-// peekUnicodeSurrogatePair checks if the next sequence is a low surrogate pair
+// peekUnicodeSurrogatePair checks if the next sequence is a '\u' escape that
+// can carry the low half of a surrogate pair
 func (l *Lexer) peekUnicodeSurrogatePair() bool {
-	backupPosition := l.readPosition
-	backupLine := l.line
-	backupColumn := l.column
-
-	// Expecting '\', 'u' followed by four hex digits
-	if l.peekChar() != '\\' {
-		return false
-	}
-	l.readChar()
-	if l.peekChar() != 'u' {
-		l.readPosition = backupPosition
-		l.line = backupLine
-		l.column = backupColumn
+	l.fill(6)
+	rest := l.buf[l.readPosition:]
+	if len(rest) < 6 || rest[0] != '\\' || rest[1] != 'u' {
 		return false
 	}
-	for i := 0; i < 5; i++ { // Skip '\', 'u', and four hex digits
-		l.readChar()
-		if i < 2 && (i == 0 && l.ch != '\\' || i == 1 && l.ch != 'u') {
-			l.readPosition = backupPosition
-			l.line = backupLine
-			l.column = backupColumn
-			return false
-		}
-		if i >= 2 && !isHexDigit(l.ch) {
-			l.readPosition = backupPosition
-			l.line = backupLine
-			l.column = backupColumn
+	for _, b := range rest[2:6] {
+		if !isHexDigit(rune(b)) {
 			return false
 		}
 	}
-	l.readPosition = backupPosition
-	l.line = backupLine
-	l.column = backupColumn
 	return true
 }
 
@@ -420,20 +948,25 @@ func (l *Lexer) readUnicodeSurrogate() (rune, error) {
 	for i := 0; i < 4; i++ {
 		l.readChar()
 		if !isHexDigit(l.ch) {
-			return 0, fmt.Errorf("invalid Unicode low surrogate escape sequence")
+			return 0, scanErrorf(CodeBadEscape, "invalid Unicode low surrogate escape sequence")
 		}
 		hexDigits[i] = l.ch
 	}
 
 	codePoint := hexToInt(hexDigits)
 	r := rune(codePoint)
-	if !unicode.IsLowSurrogate(r) {
-		return 0, fmt.Errorf("invalid low surrogate in Unicode escape")
+	if !isLowSurrogate(r) {
+		return 0, scanErrorf(CodeBadEscape, "invalid low surrogate in Unicode escape")
 	}
 
 	return r, nil
 }
 
+// isLowSurrogate checks if the rune is in the low surrogate range (U+DC00-U+DFFF)
+func isLowSurrogate(r rune) bool {
+	return 0xDC00 <= r && r <= 0xDFFF
+}
+
 // isHexDigit checks if the rune is a valid hexadecimal digit
 func isHexDigit(r rune) bool {
 	return ('0' <= r && r <= '9') || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
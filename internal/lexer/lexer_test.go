@@ -1,133 +1,149 @@
 package lexer
 
 import (
-    "reflect"
-    "testing"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
 )
 
+// startsOnly clears the offsets and end positions of tokens, so tests can
+// spell out the expected tokens by their line and column
+func startsOnly(tokens []Token) []Token {
+	stripped := make([]Token, len(tokens))
+	for i, tok := range tokens {
+		stripped[i] = Token{Type: tok.Type, Literal: tok.Literal, Line: tok.Line, Column: tok.Column}
+	}
+	return stripped
+}
+
 func TestLexer_EmptyObject(t *testing.T) {
-    input := "{}"
-    expectedTokens := []Token{
-        {Type: TokenLeftBrace, Literal: "{"},
-        {Type: TokenRightBrace, Literal: "}"},
-        {Type: TokenEOF, Literal: ""},
-    }
+	input := "{}"
+	expectedTokens := []Token{
+		{Type: TokenLeftBrace, Literal: "{", Line: 1, Column: 1},
+		{Type: TokenRightBrace, Literal: "}", Line: 1, Column: 2},
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 3},
+	}
 
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-    }
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+	}
 }
 
 func TestLexer_SimpleStrings(t *testing.T) {
-    input := `"hello" "world"`
-    expectedTokens := []Token{
-        {Type: TokenString, Literal: "hello"},
-        {Type: TokenString, Literal: "world"},
-        {Type: TokenEOF, Literal: ""},
-    }
+	input := `"hello" "world"`
+	expectedTokens := []Token{
+		{Type: TokenString, Literal: "hello", Line: 1, Column: 1},
+		{Type: TokenString, Literal: "world", Line: 1, Column: 9},
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 16},
+	}
 
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-    }
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+	}
 }
 
 func TestLexer_StringsWithEscapes(t *testing.T) {
-    input := `"hello\nworld" "escaped \"quote\""`
-    expectedTokens := []Token{
-        {Type: TokenString, Literal: "hello\nworld"},
-        {Type: TokenString, Literal: `escaped "quote"`},
-        {Type: TokenEOF, Literal: ""},
-    }
+	input := `"hello\nworld" "escaped \"quote\""`
+	expectedTokens := []Token{
+		{Type: TokenString, Literal: "hello\nworld", Line: 1, Column: 1},
+		{Type: TokenString, Literal: `escaped "quote"`, Line: 1, Column: 16},
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 35},
+	}
 
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-    }
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+	}
 }
 
 func TestLexer_UnicodeStrings(t *testing.T) {
-    // 😀 is represented by the surrogate pair \uD83D\uDE00
-    input := `"unicode \u0041" "emoji \uD83D\uDE00"`
-    expectedTokens := []Token{
-        {Type: TokenString, Literal: "unicode A"},
-        {Type: TokenString, Literal: "emoji 😀"}, // \uD83D\uDE00 represents 😀
-        {Type: TokenEOF, Literal: ""},
-    }
+	// 😀 is represented by the surrogate pair \uD83D\uDE00
+	input := `"unicode \u0041" "emoji \uD83D\uDE00"`
+	expectedTokens := []Token{
+		{Type: TokenString, Literal: "unicode A", Line: 1, Column: 1},
+		{Type: TokenString, Literal: "emoji 😀", Line: 1, Column: 18}, // \uD83D\uDE00 represents 😀
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 38},
+	}
 
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-    }
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+	}
 }
 
 func TestLexer_Numbers(t *testing.T) {
-    input := `123 -456 78.90 -0.12`
-    expectedTokens := []Token{
-        {Type: TokenNumber, Literal: "123"},
-        {Type: TokenNumber, Literal: "-456"},
-        {Type: TokenNumber, Literal: "78.90"},
-        {Type: TokenNumber, Literal: "-0.12"},
-        {Type: TokenEOF, Literal: ""},
-    }
-
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
-
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-        for i, tok := range tokens {
-            t.Logf("Token %d: Type=%s, Literal=%s", i, tok.Type, tok.Literal)
-        }
-    }
+	input := `123 -456 78.90 -0.12`
+	expectedTokens := []Token{
+		{Type: TokenNumber, Literal: "123", Line: 1, Column: 1},
+		{Type: TokenNumber, Literal: "-456", Line: 1, Column: 5},
+		{Type: TokenNumber, Literal: "78.90", Line: 1, Column: 10},
+		{Type: TokenNumber, Literal: "-0.12", Line: 1, Column: 16},
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 21},
+	}
+
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+		for i, tok := range tokens {
+			t.Logf("Token %d: Type=%s, Literal=%s", i, tok.Type, tok.Literal)
+		}
+	}
 }
 
 func TestLexer_Literals(t *testing.T) {
-    input := `true false null`
-    expectedTokens := []Token{
-        {Type: TokenTrue, Literal: "true"},
-        {Type: TokenFalse, Literal: "false"},
-        {Type: TokenNull, Literal: "null"},
-        {Type: TokenEOF, Literal: ""},
-    }
+	input := `true false null`
+	expectedTokens := []Token{
+		{Type: TokenTrue, Literal: "true", Line: 1, Column: 1},
+		{Type: TokenFalse, Literal: "false", Line: 1, Column: 6},
+		{Type: TokenNull, Literal: "null", Line: 1, Column: 12},
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 16},
+	}
 
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-    }
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+	}
 }
 
 func TestLexer_ComplexStructure(t *testing.T) {
-    input := `{
+	input := `{
         "name": "John Doe",
         "age": 30,
         "isStudent": false,
@@ -137,55 +153,736 @@ func TestLexer_ComplexStructure(t *testing.T) {
             "city": "Anytown"
         }
     }`
-    expectedTokens := []Token{
-        {Type: TokenLeftBrace, Literal: "{"},
-        {Type: TokenString, Literal: "name"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenString, Literal: "John Doe"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenString, Literal: "age"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenNumber, Literal: "30"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenString, Literal: "isStudent"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenFalse, Literal: "false"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenString, Literal: "scores"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenLeftBracket, Literal: "["},
-        {Type: TokenNumber, Literal: "85"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenNumber, Literal: "90"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenNumber, Literal: "92.5"},
-        {Type: TokenRightBracket, Literal: "]"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenString, Literal: "address"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenLeftBrace, Literal: "{"},
-        {Type: TokenString, Literal: "street"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenString, Literal: "123 Main St"},
-        {Type: TokenComma, Literal: ","},
-        {Type: TokenString, Literal: "city"},
-        {Type: TokenColon, Literal: ":"},
-        {Type: TokenString, Literal: "Anytown"},
-        {Type: TokenRightBrace, Literal: "}"},
-        {Type: TokenRightBrace, Literal: "}"},
-        {Type: TokenEOF, Literal: ""},
-    }
-
-    lexer := NewLexer(input)
-    tokens, err := lexer.Tokenize()
-    if err != nil {
-        t.Fatalf("unexpected error: %v", err)
-    }
-
-    if !reflect.DeepEqual(tokens, expectedTokens) {
-        t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
-        for i, tok := range tokens {
-            t.Logf("Token %d: Type=%s, Literal=%s", i, tok.Type, tok.Literal)
-        }
-    }
+	expectedTokens := []Token{
+		{Type: TokenLeftBrace, Literal: "{", Line: 1, Column: 1},
+		{Type: TokenString, Literal: "name", Line: 2, Column: 9},
+		{Type: TokenColon, Literal: ":", Line: 2, Column: 15},
+		{Type: TokenString, Literal: "John Doe", Line: 2, Column: 17},
+		{Type: TokenComma, Literal: ",", Line: 2, Column: 27},
+		{Type: TokenString, Literal: "age", Line: 3, Column: 9},
+		{Type: TokenColon, Literal: ":", Line: 3, Column: 14},
+		{Type: TokenNumber, Literal: "30", Line: 3, Column: 16},
+		{Type: TokenComma, Literal: ",", Line: 3, Column: 18},
+		{Type: TokenString, Literal: "isStudent", Line: 4, Column: 9},
+		{Type: TokenColon, Literal: ":", Line: 4, Column: 20},
+		{Type: TokenFalse, Literal: "false", Line: 4, Column: 22},
+		{Type: TokenComma, Literal: ",", Line: 4, Column: 27},
+		{Type: TokenString, Literal: "scores", Line: 5, Column: 9},
+		{Type: TokenColon, Literal: ":", Line: 5, Column: 17},
+		{Type: TokenLeftBracket, Literal: "[", Line: 5, Column: 19},
+		{Type: TokenNumber, Literal: "85", Line: 5, Column: 20},
+		{Type: TokenComma, Literal: ",", Line: 5, Column: 22},
+		{Type: TokenNumber, Literal: "90", Line: 5, Column: 24},
+		{Type: TokenComma, Literal: ",", Line: 5, Column: 26},
+		{Type: TokenNumber, Literal: "92.5", Line: 5, Column: 28},
+		{Type: TokenRightBracket, Literal: "]", Line: 5, Column: 32},
+		{Type: TokenComma, Literal: ",", Line: 5, Column: 33},
+		{Type: TokenString, Literal: "address", Line: 6, Column: 9},
+		{Type: TokenColon, Literal: ":", Line: 6, Column: 18},
+		{Type: TokenLeftBrace, Literal: "{", Line: 6, Column: 20},
+		{Type: TokenString, Literal: "street", Line: 7, Column: 13},
+		{Type: TokenColon, Literal: ":", Line: 7, Column: 21},
+		{Type: TokenString, Literal: "123 Main St", Line: 7, Column: 23},
+		{Type: TokenComma, Literal: ",", Line: 7, Column: 36},
+		{Type: TokenString, Literal: "city", Line: 8, Column: 13},
+		{Type: TokenColon, Literal: ":", Line: 8, Column: 19},
+		{Type: TokenString, Literal: "Anytown", Line: 8, Column: 21},
+		{Type: TokenRightBrace, Literal: "}", Line: 9, Column: 9},
+		{Type: TokenRightBrace, Literal: "}", Line: 10, Column: 5},
+		{Type: TokenEOF, Literal: "", Line: 10, Column: 6},
+	}
+
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(startsOnly(tokens), expectedTokens) {
+		t.Errorf("expected tokens %v, got %v", expectedTokens, tokens)
+		for i, tok := range tokens {
+			t.Logf("Token %d: Type=%s, Literal=%s", i, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestLexer_TokenPositions(t *testing.T) {
+	input := "{\"é\": [1, true]}\n null"
+	expected := []Token{
+		{Type: TokenLeftBrace, Literal: "{", Line: 1, Column: 1, Offset: 0, End: 1, EndLine: 1, EndColumn: 2},
+		{Type: TokenString, Literal: "é", Line: 1, Column: 2, Offset: 1, End: 5, EndLine: 1, EndColumn: 5},
+		{Type: TokenColon, Literal: ":", Line: 1, Column: 5, Offset: 5, End: 6, EndLine: 1, EndColumn: 6},
+		{Type: TokenLeftBracket, Literal: "[", Line: 1, Column: 7, Offset: 7, End: 8, EndLine: 1, EndColumn: 8},
+		{Type: TokenNumber, Literal: "1", Line: 1, Column: 8, Offset: 8, End: 9, EndLine: 1, EndColumn: 9},
+		{Type: TokenComma, Literal: ",", Line: 1, Column: 9, Offset: 9, End: 10, EndLine: 1, EndColumn: 10},
+		{Type: TokenTrue, Literal: "true", Line: 1, Column: 11, Offset: 11, End: 15, EndLine: 1, EndColumn: 15},
+		{Type: TokenRightBracket, Literal: "]", Line: 1, Column: 15, Offset: 15, End: 16, EndLine: 1, EndColumn: 16},
+		{Type: TokenRightBrace, Literal: "}", Line: 1, Column: 16, Offset: 16, End: 17, EndLine: 1, EndColumn: 17},
+		{Type: TokenNull, Literal: "null", Line: 2, Column: 2, Offset: 19, End: 23, EndLine: 2, EndColumn: 6},
+		{Type: TokenEOF, Literal: "", Line: 2, Column: 6, Offset: 23, End: 23, EndLine: 2, EndColumn: 6},
+	}
+
+	lexers := map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	}
+	for name, lexer := range lexers {
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(tokens, expected) {
+			t.Errorf("%s: expected tokens %v, got %v", name, expected, tokens)
+		}
+	}
+
+	if tok := expected[1]; input[tok.Offset:tok.Offset+int64(tok.Len())] != `"é"` {
+		t.Errorf("expected the token to slice its source text, got %q", input[tok.Offset:tok.Offset+int64(tok.Len())])
+	}
+}
+
+func TestReaderLexer_MatchesStringLexer(t *testing.T) {
+	input := `{
+        "name": "Jöhn é 😀 😀",
+        "age": -30.5e+2,
+        "flags": [true, false, null],
+        "nested": {"empty": [], "zero": 0}
+    }`
+
+	want, err := NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// OneByteReader forces a refill at every byte, splitting multi-byte runes,
+	// keywords, numbers and escapes across reads
+	got, err := NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))).Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tokens %v, got %v", want, got)
+	}
+}
+
+func TestReaderLexer_BuffersOnlyCurrentToken(t *testing.T) {
+	const count = 100000
+	input := "[" + strings.Repeat("12345,", count-1) + "12345]"
+
+	lexer := NewReaderLexer(strings.NewReader(input))
+	numbers := 0
+	for {
+		tok, err := lexer.NextToken()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tok.Type == TokenEOF {
+			break
+		}
+		if tok.Type == TokenNumber {
+			numbers++
+		}
+	}
+
+	if numbers != count {
+		t.Errorf("expected %d numbers, got %d", count, numbers)
+	}
+	if cap(lexer.buf) > 4*readChunkSize {
+		t.Errorf("expected a bounded buffer, got %d bytes for %d bytes of input", cap(lexer.buf), len(input))
+	}
+}
+
+func TestReaderLexer_ReadError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader(`{"a": `), iotest.ErrReader(errors.New("connection reset")))
+	_, err := NewReaderLexer(r).Tokenize()
+	if err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("expected read error, got %v", err)
+	}
+	var readErr *ReadError
+	if !errors.As(err, &readErr) || readErr.Err.Error() != "connection reset" || readErr.Offset != 6 || CodeOf(err) != CodeRead {
+		t.Errorf("expected a *ReadError with code %s, got %v", CodeRead, err)
+	}
+}
+
+func TestLexer_NextTokenRepeatsEOF(t *testing.T) {
+	lexer := NewLexer(`1`)
+	for _, want := range []TokenType{TokenNumber, TokenEOF, TokenEOF} {
+		tok, err := lexer.NextToken()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tok.Type != want {
+			t.Errorf("expected %s, got %s", want, tok.Type)
+		}
+	}
+}
+
+func TestLexer_RejectsNULOutsideStrings(t *testing.T) {
+	if _, err := NewLexer("[1]\x00[2]").Tokenize(); err == nil {
+		t.Errorf("expected an error for a NUL byte")
+	}
+}
+
+func TestLexer_SkipTokenOmitsLiterals(t *testing.T) {
+	input := `{"key": "café", "n": -1.5e3, "ok": true}`
+
+	tokens, err := NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lexer := NewLexer(input)
+	for i, want := range tokens {
+		tok, err := lexer.SkipToken()
+		if err != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, err)
+		}
+		if want.Type == TokenString || want.Type == TokenNumber {
+			want.Literal = ""
+		}
+		if tok != want {
+			t.Errorf("token %d: expected %+v, got %+v", i, want, tok)
+		}
+	}
+
+	if _, err := NewLexer(`"bad \x"`).SkipToken(); err == nil {
+		t.Errorf("expected SkipToken to reject an invalid escape")
+	}
+	if _, err := NewLexer(`1e`).SkipToken(); err == nil {
+		t.Errorf("expected SkipToken to reject a malformed number")
+	}
+	if _, err := NewLexer(`1e400`).SkipToken(); err != nil {
+		t.Errorf("expected SkipToken to accept a number too large for a float64, got %v", err)
+	}
+}
+
+func TestBytesLexer_MatchesStringLexer(t *testing.T) {
+	input := `{"name": "Jöhn é 😀", "list": [1, -2.5e3, true, false, null], "nested": {"a": []}}`
+
+	want, err := NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := NewBytesLexer([]byte(input)).Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBytesLexer_LiteralsDoNotAliasInput(t *testing.T) {
+	data := []byte(`{"id": "abc", "n": 12345}`)
+	tokens, err := NewBytesLexer(data).Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A literal sharing memory with data would keep all of it alive and
+	// change along with it
+	for i := range data {
+		data[i] = ' '
+	}
+	if tokens[3].Literal != "abc" || tokens[7].Literal != "12345" {
+		t.Errorf("expected literals to be copies, got %q and %q", tokens[3].Literal, tokens[7].Literal)
+	}
+}
+
+func TestLexer_Comments(t *testing.T) {
+	input := "// leading\n{\"a\": /* inline */ 1, /* multi\nline */ \"b\": 2 // trailing"
+
+	if _, err := NewLexer(input).Tokenize(); err == nil {
+		t.Fatalf("expected comments to be rejected by default")
+	}
+
+	lexer := NewLexer(input)
+	lexer.SetOptions(Options{AllowComments: true})
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var types []string
+	for _, tok := range tokens {
+		types = append(types, string(tok.Type))
+	}
+	if got := strings.Join(types, " "); got != "{ STRING : NUMBER , STRING : NUMBER EOF" {
+		t.Errorf("unexpected tokens: %s", got)
+	}
+	if tokens[5].Line != 3 || tokens[5].Column != 9 {
+		t.Errorf("expected the key after the block comment at line 3, column 9, got %d:%d", tokens[5].Line, tokens[5].Column)
+	}
+
+	for _, bad := range []string{"[1 /* open", "[1 / 2]"} {
+		lexer := NewLexer(bad)
+		lexer.SetOptions(Options{AllowComments: true})
+		if _, err := lexer.Tokenize(); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestLexer_KeepComments(t *testing.T) {
+	input := "// leading\r\n[1, /* multi\nline */ 2] // trailing"
+	for name, lexer := range map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	} {
+		lexer.SetOptions(Options{AllowComments: true, KeepComments: true})
+		if _, err := lexer.Tokenize(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		expected := []Comment{
+			{Text: "// leading", Line: 1, Column: 1, Offset: 0},
+			{Text: "/* multi\nline */", Line: 2, Column: 5, Offset: 16},
+			{Text: "// trailing", Line: 3, Column: 12, Offset: 36},
+		}
+		if got := lexer.Comments(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected comments %+v, got %+v", name, expected, got)
+		}
+	}
+
+	lexer := NewLexer(input)
+	lexer.SetOptions(Options{AllowComments: true})
+	if _, err := lexer.Tokenize(); err != nil || lexer.Comments() != nil {
+		t.Errorf("expected comments to be dropped unless KeepComments is set, got %v", err)
+	}
+}
+
+func TestLexer_AllowSingleQuotes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`'abc'`, "abc"},
+		{`'it\'s "quoted"'`, `it's "quoted"`},
+		{`'a\n\u00e9\/'`, "a\né/"},
+		{`"double"`, "double"},
+	}
+	for _, tt := range tests {
+		lexer := NewLexer(tt.input)
+		lexer.SetOptions(Options{AllowSingleQuotes: true})
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if tokens[0].Type != TokenString || tokens[0].Literal != tt.want {
+			t.Errorf("%s: got %s %q, want STRING %q", tt.input, tokens[0].Type, tokens[0].Literal, tt.want)
+		}
+	}
+
+	for _, bad := range []string{`'open`, `"it\'s"`, `'a" `} {
+		lexer := NewLexer(bad)
+		lexer.SetOptions(Options{AllowSingleQuotes: true})
+		if _, err := lexer.Tokenize(); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+	if _, err := NewLexer(`'abc'`).Tokenize(); err == nil {
+		t.Error("expected single quotes to be rejected by default")
+	}
+}
+
+func TestLexer_AllowUnquotedKeys(t *testing.T) {
+	input := "{foo: 1, _bar2 :true, $é\n: null, \"q\": [true, null], true: false}"
+	want := `{ STRING(foo) : NUMBER , STRING(_bar2) : TRUE , STRING($é) : NULL , STRING(q) : [ TRUE , NULL ] , STRING(true) : FALSE } EOF`
+	for name, lexer := range map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	} {
+		lexer.SetOptions(Options{AllowUnquotedKeys: true})
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		var got []string
+		for _, tok := range tokens {
+			if tok.Type == TokenString {
+				got = append(got, fmt.Sprintf("STRING(%s)", tok.Literal))
+			} else {
+				got = append(got, string(tok.Type))
+			}
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("%s: got %s, want %s", name, strings.Join(got, " "), want)
+		}
+		if tokens[5].Column != 10 || tokens[5].EndColumn != 15 {
+			t.Errorf("%s: expected _bar2 at columns 10 to 15, got %d to %d", name, tokens[5].Column, tokens[5].EndColumn)
+		}
+	}
+
+	// Only keys may go unquoted
+	for _, bad := range []string{`{"a": foo}`, `[foo]`, `{2a: 1}`, `{foo}`} {
+		lexer := NewLexer(bad)
+		lexer.SetOptions(Options{AllowUnquotedKeys: true})
+		if _, err := lexer.Tokenize(); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestLexer_AllowNonFinite(t *testing.T) {
+	lexer := NewLexer(`[NaN, Infinity, -Infinity, -1]`)
+	lexer.SetOptions(Options{AllowNonFinite: true})
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var numbers []string
+	for _, tok := range tokens {
+		if tok.Type == TokenNumber {
+			numbers = append(numbers, tok.Literal)
+		}
+	}
+	if got := strings.Join(numbers, " "); got != "NaN Infinity -Infinity -1" {
+		t.Errorf("unexpected numbers: %s", got)
+	}
+	if tokens[5].Column != 17 || tokens[5].EndColumn != 26 {
+		t.Errorf("expected -Infinity at columns 17 to 26, got %d to %d", tokens[5].Column, tokens[5].EndColumn)
+	}
+
+	for _, bad := range []string{`[NaN]`, `[nan]`, `[-Inf]`} {
+		lexer := NewLexer(bad)
+		if bad != `[NaN]` {
+			lexer.SetOptions(Options{AllowNonFinite: true})
+		}
+		if _, err := lexer.Tokenize(); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestLexer_AllowHexNumbers(t *testing.T) {
+	input := `[0x1F, -0X10, 0xdeadBEEF, 0, 0.5, 0xFFFFFFFFFFFFFFFFFF]`
+	for name, lexer := range map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	} {
+		lexer.SetOptions(Options{AllowHexNumbers: true})
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		var numbers []string
+		for _, tok := range tokens {
+			if tok.Type == TokenNumber {
+				numbers = append(numbers, tok.Literal)
+			}
+		}
+		if got := strings.Join(numbers, " "); got != "31 -16 3735928559 0 0.5 4722366482869645213695" {
+			t.Errorf("%s: unexpected numbers: %s", name, got)
+		}
+		if tokens[3].Column != 8 || tokens[3].EndColumn != 13 {
+			t.Errorf("%s: expected -0X10 at columns 8 to 13, got %d to %d", name, tokens[3].Column, tokens[3].EndColumn)
+		}
+	}
+
+	for _, bad := range []string{`[0x]`, `[0x1G]`, `[0x1.5]`, `[-0x]`} {
+		lexer := NewLexer(bad)
+		lexer.SetOptions(Options{AllowHexNumbers: true})
+		if _, err := lexer.Tokenize(); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+	if _, err := NewLexer(`[0x1F]`).Tokenize(); err == nil {
+		t.Error("expected hex numbers to be rejected by default")
+	}
+}
+
+func TestLexer_AllowRelaxedNumbers(t *testing.T) {
+	input := `[+1, 007, .5, 5., -.5, +.5e3, 5.E2, -007.50, 000, 0, -0, 1.5e-3, +0x10]`
+	for name, lexer := range map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	} {
+		lexer.SetOptions(Options{AllowRelaxedNumbers: true, AllowHexNumbers: true})
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		var numbers []string
+		for _, tok := range tokens {
+			if tok.Type == TokenNumber {
+				numbers = append(numbers, tok.Literal)
+			}
+		}
+		if got := strings.Join(numbers, " "); got != "1 7 0.5 5 -0.5 0.5e3 5E2 -7.50 0 0 -0 1.5e-3 16" {
+			t.Errorf("%s: unexpected numbers: %s", name, got)
+		}
+		if tokens[3].Column != 6 || tokens[3].EndColumn != 9 {
+			t.Errorf("%s: expected 007 at columns 6 to 9, got %d to %d", name, tokens[3].Column, tokens[3].EndColumn)
+		}
+	}
+
+	for _, bad := range []string{`[+]`, `[.]`, `[-.]`, `[+-1]`, `[.e5]`, `[5.5.5]`, `[++1]`} {
+		lexer := NewLexer(bad)
+		lexer.SetOptions(Options{AllowRelaxedNumbers: true})
+		if _, err := lexer.Tokenize(); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+	for _, relaxed := range []string{`[+1]`, `[007]`, `[.5]`, `[5.]`} {
+		if _, err := NewLexer(relaxed).Tokenize(); err == nil {
+			t.Errorf("%s: expected relaxed numbers to be rejected by default", relaxed)
+		}
+	}
+}
+
+func TestLexer_AllowLineContinuations(t *testing.T) {
+	input := "[\"one \\\ntwo \\\r\nthree \\\rfour \\\u2028five\", 'a\\\nb', 1]"
+	for name, lexer := range map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	} {
+		lexer.SetOptions(Options{AllowLineContinuations: true, AllowSingleQuotes: true})
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if tokens[1].Literal != "one two three four five" || tokens[3].Literal != "ab" {
+			t.Errorf("%s: unexpected strings: %q and %q", name, tokens[1].Literal, tokens[3].Literal)
+		}
+		if tokens[5].Line != 4 {
+			t.Errorf("%s: expected the number on line 4, got %d", name, tokens[5].Line)
+		}
+	}
+
+	if _, err := NewLexer("[\"one \\\ntwo\"]").Tokenize(); err == nil {
+		t.Error("expected line continuations to be rejected by default")
+	}
+}
+
+func TestLexer_ControlCharacters(t *testing.T) {
+	tests := []struct {
+		input        string
+		line, column int
+		offset       int64
+		hint         string
+	}{
+		{"[\"a\tb\"]", 1, 4, 3, `write it as \t`},
+		{"[1,\n \"ab\ncd\"]", 2, 5, 8, `write it as \n`},
+		{"\"\x00\"", 1, 2, 1, `write it as \u0000`},
+		{"{\"k\x1f\": 1}", 1, 4, 3, `write it as \u001f`},
+	}
+	for _, tt := range tests {
+		_, err := NewLexer(tt.input).Tokenize()
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected a syntax error, got %v", tt.input, err)
+			continue
+		}
+		got := [...]any{syntaxErr.Code, syntaxErr.Line, syntaxErr.Column, syntaxErr.Offset, syntaxErr.Hint}
+		want := [...]any{CodeControlCharacter, tt.line, tt.column, tt.offset, tt.hint}
+		if got != want {
+			t.Errorf("%q: got %v, want %v", tt.input, got, want)
+		}
+	}
+
+	input := "[\"a\tb\r\nc\", \"\\t\"]"
+	lexer := NewReaderLexer(iotest.OneByteReader(strings.NewReader(input)))
+	lexer.SetOptions(Options{AllowControlCharacters: true})
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("expected control characters to be accepted, got %v", err)
+	}
+	if tokens[1].Literal != "a\tb\r\nc" || tokens[3].Literal != "\t" {
+		t.Errorf("unexpected strings: %q and %q", tokens[1].Literal, tokens[3].Literal)
+	}
+	if _, err := NewLexer(`"a\tb\u0001"`).Tokenize(); err != nil {
+		t.Errorf("expected escaped control characters to be accepted, got %v", err)
+	}
+}
+
+func TestLexer_RejectInvalidUTF8(t *testing.T) {
+	input := "\"a\xffb\""
+
+	if _, err := NewLexer(input).Tokenize(); err != nil {
+		t.Fatalf("expected invalid UTF-8 to be accepted by default, got %v", err)
+	}
+
+	lexer := NewLexer(input)
+	lexer.SetOptions(Options{RejectInvalidUTF8: true})
+	if _, err := lexer.Tokenize(); err == nil {
+		t.Errorf("expected invalid UTF-8 to be rejected")
+	}
+
+	lexer = NewLexer(`"� é"`)
+	lexer.SetOptions(Options{RejectInvalidUTF8: true})
+	if _, err := lexer.Tokenize(); err != nil {
+		t.Errorf("expected valid text to be accepted, got %v", err)
+	}
+}
+
+func TestLexer_TokenizeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewLexer(`[1, 2, 3]`).TokenizeContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// wordHook reads runs of letters other than keywords as IDENT tokens
+func wordHook(s Scanner) (Token, bool, error) {
+	n := 0
+	for r := s.Peek(n); r >= 'a' && r <= 'z' || r == 'é'; r = s.Peek(n) {
+		n++
+	}
+	var word []rune
+	for i := 0; i < n; i++ {
+		word = append(word, s.Peek(i))
+	}
+	if n == 0 || string(word) == "true" {
+		return Token{}, false, nil
+	}
+	s.Advance(n)
+	return Token{Type: "IDENT", Literal: string(word)}, true, nil
+}
+
+// hashCommentHook skips # comments to the end of the line
+func hashCommentHook(s Scanner) (Token, bool, error) {
+	if s.Peek(0) != '#' {
+		return Token{}, false, nil
+	}
+	for s.Peek(0) != '\n' && s.Peek(0) != 0 {
+		s.Advance(1)
+	}
+	return Token{Type: TokenSkip}, true, nil
+}
+
+func TestLexer_TokenHooks(t *testing.T) {
+	input := "[café, true, # note\n \"x\" # end"
+	opts := Options{Hooks: []TokenHook{hashCommentHook, wordHook}}
+	expected := []Token{
+		{Type: TokenLeftBracket, Literal: "[", Line: 1, Column: 1},
+		{Type: "IDENT", Literal: "café", Line: 1, Column: 2},
+		{Type: TokenComma, Literal: ",", Line: 1, Column: 6},
+		{Type: TokenTrue, Literal: "true", Line: 1, Column: 8},
+		{Type: TokenComma, Literal: ",", Line: 1, Column: 12},
+		{Type: TokenString, Literal: "x", Line: 2, Column: 2},
+		{Type: TokenEOF, Literal: "", Line: 2, Column: 11},
+	}
+
+	lexers := map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	}
+	for name, lexer := range lexers {
+		lexer.SetOptions(opts)
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(startsOnly(tokens), expected) {
+			t.Errorf("%s: expected tokens %v, got %v", name, expected, tokens)
+		}
+	}
+}
+
+func TestLexer_TokenHookMustAdvanceWhenMatching(t *testing.T) {
+	hooks := map[string]TokenHook{
+		"matched without advancing": func(s Scanner) (Token, bool, error) {
+			return Token{Type: "IDENT"}, true, nil
+		},
+		"advanced without matching": func(s Scanner) (Token, bool, error) {
+			s.Advance(1)
+			return Token{}, false, nil
+		},
+	}
+
+	for name, hook := range hooks {
+		lexer := NewLexer(`[1]`)
+		lexer.SetOptions(Options{Hooks: []TokenHook{hook}})
+		_, err := lexer.Tokenize()
+		expected := "Lexer error at line 1, column 1: token hook must advance exactly when it matches"
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s: expected %q, got %v", name, expected, err)
+		}
+	}
+}
+
+func TestLexer_Recover(t *testing.T) {
+	input := "[tru, \"a\\qb\" @@, 01]"
+	expected := []Token{
+		{Type: TokenLeftBracket, Literal: "[", Line: 1, Column: 1},
+		{Type: TokenInvalid, Literal: "tru", Line: 1, Column: 2},
+		{Type: TokenComma, Literal: ",", Line: 1, Column: 5},
+		{Type: TokenInvalid, Literal: `"a\qb"`, Line: 1, Column: 7},
+		{Type: TokenInvalid, Literal: "@@", Line: 1, Column: 14},
+		{Type: TokenComma, Literal: ",", Line: 1, Column: 16},
+		{Type: TokenInvalid, Literal: "01", Line: 1, Column: 18},
+		{Type: TokenRightBracket, Literal: "]", Line: 1, Column: 20},
+		{Type: TokenEOF, Literal: "", Line: 1, Column: 21},
+	}
+
+	lexers := map[string]*Lexer{
+		"string": NewLexer(input),
+		"reader": NewReaderLexer(iotest.OneByteReader(strings.NewReader(input))),
+	}
+	for name, lexer := range lexers {
+		lexer.SetOptions(Options{Recover: true})
+		tokens, err := lexer.Tokenize()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(startsOnly(tokens), expected) {
+			t.Errorf("%s: expected tokens %v, got %v", name, expected, tokens)
+		}
+		for _, tok := range tokens {
+			if (tok.Type == TokenInvalid) != errors.Is(tok.Err, ErrSyntax) {
+				t.Errorf("%s: expected a syntax error on invalid tokens only, got %v on %v", name, tok.Err, tok)
+			}
+			if tok.Type == TokenInvalid && tok.End-tok.Offset != int64(len(tok.Literal)) {
+				t.Errorf("%s: expected %v to span its literal", name, tok)
+			}
+		}
+	}
+
+	lexer := NewLexer(`"open`)
+	lexer.SetOptions(Options{Recover: true})
+	tokens, err := lexer.Tokenize()
+	var unterminated *UnterminatedStringError
+	if err != nil || len(tokens) != 2 || !errors.As(tokens[0].Err, &unterminated) {
+		t.Errorf("expected an unterminated string token then EOF, got %v, %v", tokens, err)
+	}
+}
+
+func TestLexer_Literal(t *testing.T) {
+	lexer := NewLexer(`["aé", 12.5, true]`)
+	var tokens []Token
+	for {
+		tok, err := lexer.SkipToken()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tok.Type == TokenEOF {
+			break
+		}
+		tokens = append(tokens, tok)
+	}
+	for i, want := range map[int]string{1: "aé", 3: "12.5", 5: "true"} {
+		if got, ok := lexer.Literal(tokens[i]); !ok || got != want {
+			t.Errorf("token %d: expected %q, got %q, %v", i, want, got, ok)
+		}
+	}
+
+	// Scanning the long string discards the short one before it
+	streaming := NewReaderLexer(strings.NewReader(`["y", "` + strings.Repeat("x", 2*readChunkSize) + `"]`))
+	streaming.SkipToken()
+	short, _ := streaming.SkipToken()
+	streaming.SkipToken()
+	long, _ := streaming.SkipToken()
+	if _, ok := streaming.Literal(short); ok {
+		t.Errorf("expected no literal for a token no longer buffered")
+	}
+	if got, ok := streaming.Literal(long); !ok || len(got) != 2*readChunkSize {
+		t.Errorf("expected the literal of the current token, got %d bytes, %v", len(got), ok)
+	}
 }
@@ -0,0 +1,139 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetWidth is the most characters of a line shown around an error
+const snippetWidth = 80
+
+// Snippet is the source line an error was found on, shown with a caret
+// under the error's column
+type Snippet struct {
+	Line   int    // Line number in input
+	Text   string // The line without its line break, cut to snippetWidth characters around Column
+	Column int    // Column of the error within Text
+}
+
+// String returns the line after a line number gutter, and a caret under
+// Column on the line below
+//
+//	3 |   "port": 80a
+//	  |             ^
+func (s *Snippet) String() string {
+	var pad strings.Builder
+	column := 1
+	for _, r := range s.Text {
+		if column >= s.Column {
+			break
+		}
+		if r == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+		column++
+	}
+	for ; column < s.Column; column++ {
+		pad.WriteByte(' ')
+	}
+	gutter := strconv.Itoa(s.Line)
+	return fmt.Sprintf("%s | %s\n%s | %s^", gutter, s.Text, strings.Repeat(" ", len(gutter)), pad.String())
+}
+
+// newSnippet cuts the line starting text down to the characters around the
+// byte at, which is past characters short of the error's column when the
+// column lies beyond the end of the line
+func newSnippet(line int, text []byte, at, past int) *Snippet {
+	from, to := at, at
+	for i := 0; i < snippetWidth/2 && from > 0; i++ {
+		_, size := utf8.DecodeLastRune(text[:from])
+		from -= size
+	}
+	for i := 0; i < snippetWidth/2 && !lineEnds(text, to); i++ {
+		_, size := utf8.DecodeRune(text[to:])
+		to += size
+	}
+
+	var prefix, suffix string
+	if from > 0 {
+		prefix = "..."
+	}
+	if !lineEnds(text, to) {
+		suffix = "..."
+	}
+	return &Snippet{
+		Line:   line,
+		Text:   prefix + string(text[from:to]) + suffix,
+		Column: len(prefix) + utf8.RuneCount(text[from:at]) + past + 1,
+	}
+}
+
+// lineEnds reports whether the line in text ends at byte at: at its line
+// break, at the "\r" of a CRLF, or at the end of what is held
+func lineEnds(text []byte, at int) bool {
+	if at >= len(text) || text[at] == '\n' {
+		return true
+	}
+	return text[at] == '\r' && (at+1 == len(text) || text[at+1] == '\n')
+}
+
+// snippetMark is where Snippet last found a column, so the snippets of later
+// errors on the same line walk on from there instead of from the line start
+type snippetMark struct {
+	line, column int
+	offset       int64 // absolute input offset of the column's first byte
+}
+
+// Snippet returns the source line for an error at line and column, or nil
+// when the line is no longer held. An in-memory Lexer holds every line; one
+// reading from a reader only holds what is buffered of the current line.
+// Only the characters shown are copied, so a snippet on a long line costs
+// no more than one on a short line.
+func (l *Lexer) Snippet(line, column int) *Snippet {
+	i := line - 1
+	if l.streaming {
+		if line != l.line {
+			return nil
+		}
+		i = 0
+	}
+	if i < 0 || i >= len(l.lineStarts) {
+		return nil
+	}
+	start := l.lineStarts[i] - l.offset
+	if start < 0 || start > int64(len(l.buf)) {
+		return nil
+	}
+	text := l.buf[start:]
+
+	at, c := 0, 1
+	if m := l.snippetMark; m.line == line && m.column <= column && m.offset >= l.lineStarts[i] && m.offset-l.lineStarts[i] <= int64(len(text)) {
+		at, c = int(m.offset-l.lineStarts[i]), m.column
+	}
+	for ; c < column && !lineEnds(text, at); c++ {
+		_, size := utf8.DecodeRune(text[at:])
+		at += size
+	}
+	l.snippetMark = snippetMark{line: line, column: c, offset: l.lineStarts[i] + int64(at)}
+	// Past the end of the line, as at the end of input, the caret goes after it
+	return newSnippet(line, text, at, column-c)
+}
+
+// FormatError writes err for fmt. The %+v verb adds the snippet, when there
+// is one, on the lines after the message; every other verb writes the
+// message alone.
+func FormatError(f fmt.State, verb rune, err error, snippet *Snippet) {
+	switch {
+	case verb == 'v' && f.Flag('+') && snippet != nil:
+		fmt.Fprintf(f, "%s\n%s", err.Error(), snippet)
+	case verb == 'q':
+		fmt.Fprintf(f, "%q", err.Error())
+	default:
+		io.WriteString(f, err.Error())
+	}
+}
@@ -0,0 +1,131 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSnippet_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet Snippet
+		want    string
+	}{
+		{"column", Snippet{Line: 3, Text: `  "port": 80a`, Column: 13}, "3 |   \"port\": 80a\n  |             ^"},
+		{"first column", Snippet{Line: 12, Text: "x", Column: 1}, "12 | x\n   | ^"},
+		{"tabs", Snippet{Line: 1, Text: "\t\"a\": @", Column: 7}, "1 | \t\"a\": @\n  | \t     ^"},
+		{"past the end", Snippet{Line: 1, Text: "[1,", Column: 4}, "1 | [1,\n  |    ^"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.snippet.String(); got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestLexer_Snippet(t *testing.T) {
+	lexer := NewLexer("{\r\n  \"é\": true,\n  \"b\": 2\n}")
+	lexer.Tokenize()
+
+	tests := []struct {
+		line, column int
+		text         string
+		at           int
+	}{
+		{1, 1, "{", 1},
+		{2, 8, `  "é": true,`, 8},
+		{4, 2, "}", 2},
+	}
+	for _, tt := range tests {
+		s := lexer.Snippet(tt.line, tt.column)
+		if s == nil || s.Line != tt.line || s.Text != tt.text || s.Column != tt.at {
+			t.Errorf("line %d: unexpected snippet %+v", tt.line, s)
+		}
+	}
+	if s := lexer.Snippet(5, 1); s != nil {
+		t.Errorf("expected no snippet past the last line, got %+v", s)
+	}
+}
+
+func TestLexer_SnippetOfLongLine(t *testing.T) {
+	line := strings.Repeat("1,", 100) + "@," + strings.Repeat("2,", 100)
+	_, err := NewLexer(line).Tokenize()
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok || syntaxErr.Snippet == nil {
+		t.Fatalf("expected a syntax error with a snippet, got %v", err)
+	}
+	s := syntaxErr.Snippet
+	if want := "..." + strings.Repeat("1,", 20) + "@," + strings.Repeat("2,", 19) + "..."; s.Text != want {
+		t.Errorf("expected the line cut around the error, got %q", s.Text)
+	}
+	if s.Column != 44 || []rune(s.Text)[s.Column-1] != '@' {
+		t.Errorf("expected the column moved with the cut, got %d", s.Column)
+	}
+}
+
+func TestLexer_SnippetsOnOneLine(t *testing.T) {
+	line := strings.Repeat("é", 300)
+	lexer := NewLexer(line + "\r\n")
+	lexer.Tokenize()
+
+	// Later snippets walk on from earlier ones; going back starts over
+	for _, column := range []int{200, 250, 100, 301, 310} {
+		s := lexer.Snippet(1, column)
+		if s == nil {
+			t.Fatalf("column %d: expected a snippet", column)
+		}
+		runes := []rune(line)
+		at := min(column-1, len(runes))
+		from, to := max(0, at-snippetWidth/2), min(len(runes), at+snippetWidth/2)
+		want := string(runes[from:to])
+		if from > 0 {
+			want = "..." + want
+		}
+		if to < len(runes) {
+			want += "..."
+		}
+		if s.Text != want {
+			t.Errorf("column %d: expected %q, got %q", column, want, s.Text)
+		}
+		if caret := len("...") + column - from; s.Column != caret {
+			t.Errorf("column %d: expected the caret at %d, got %d", column, caret, s.Column)
+		}
+	}
+}
+
+func TestLexer_SnippetFromReader(t *testing.T) {
+	_, err := NewReaderLexer(strings.NewReader("[1,\n 2,\n $]")).Tokenize()
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok || syntaxErr.Snippet == nil || syntaxErr.Snippet.Text != " $]" || syntaxErr.Snippet.Line != 3 {
+		t.Fatalf("expected a snippet of the current line, got %#v", err)
+	}
+
+	lexer := NewReaderLexer(strings.NewReader("[1,\n 2]"))
+	lexer.Tokenize()
+	if s := lexer.Snippet(1, 1); s != nil {
+		t.Errorf("expected no snippet of an earlier line, got %+v", s)
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	_, err := NewLexer("[1,\n @]").Tokenize()
+	if got, want := fmt.Sprintf("%+v", err), "Lexer error at line 2, column 2: unexpected character: '@'\n2 |  @]\n  |  ^"; got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	if got := fmt.Sprintf("%v", err); got != err.Error() {
+		t.Errorf("expected the message alone under %%v, got %q", got)
+	}
+	if got := fmt.Sprintf("%q", err); got != fmt.Sprintf("%q", err.Error()) {
+		t.Errorf("expected the quoted message under %%q, got %s", got)
+	}
+
+	list := ErrorList{err, &UnexpectedTokenError{Token: Token{Type: TokenEOF, Line: 2, Column: 4}, Expected: "]"}}
+	if got, want := fmt.Sprintf("%+v", list), fmt.Sprintf("%+v\n%s", err, list[1]); got != want {
+		t.Errorf("expected every error on its own lines, got\n%s", got)
+	}
+	if got := fmt.Sprint(list); got != list.Error() {
+		t.Errorf("expected the summary under %%v, got %q", got)
+	}
+}
@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/chaos"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+func TestChaos_ReaderLexerAndParser(t *testing.T) {
+	corpus := [][]byte{
+		[]byte(`{"name": "Jöhn 😀", "age": -30.5e+2, "tags": ["a", "b"], "ok": true, "none": null}`),
+		[]byte(`[[], {}, [{"a": [0, 1.5, -0.0e0]}], "esc\"aped\\\/\b\f\n\r\t"]`),
+	}
+	files, err := filepath.Glob(filepath.Join("..", "..", "tests", "*", "*.json"))
+	if err != nil {
+		t.Fatalf("listing fixtures: %v", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		corpus = append(corpus, data)
+	}
+
+	chaos.Check(t, corpus, chaos.Options{Seed: 1, Mutations: 64}, func(r io.Reader) error {
+		tokens, err := lexer.NewReaderLexer(r).Tokenize()
+		if err != nil {
+			return err
+		}
+		_, err = ParseValue(tokens)
+		return err
+	})
+}
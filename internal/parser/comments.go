@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// pendingComments returns the comments between the previous token and the
+// current one that are not yet attached to a node
+func (p *Parser) pendingComments() []lexer.Comment {
+	if len(p.comments) == 0 || p.current >= len(p.tokens) {
+		return nil
+	}
+	if p.current > 0 {
+		after := p.tokens[p.current-1].End
+		for p.nextComment < len(p.comments) && p.comments[p.nextComment].Offset < after {
+			p.nextComment++ // left behind by tokens skipped in recovery
+		}
+	}
+	end, before := p.nextComment, p.tokens[p.current].Offset
+	for end < len(p.comments) && p.comments[end].Offset < before {
+		end++
+	}
+	return p.comments[p.nextComment:end]
+}
+
+// takeComments takes the first n pending comments and returns their text
+func (p *Parser) takeComments(n int) []string {
+	if n == 0 {
+		return nil
+	}
+	comments := p.pendingComments()[:n]
+	p.nextComment += n
+	texts := make([]string, n)
+	for i, c := range comments {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+// leadingComments takes every pending comment, for the node starting at
+// the current token
+func (p *Parser) leadingComments() []string {
+	return p.takeComments(len(p.pendingComments()))
+}
+
+// trailingComments gives prev the pending comments that start on the line
+// the previous token ends on, such as one after the comma following prev
+func (p *Parser) trailingComments(prev ast.Value) {
+	if prev == nil || p.current == 0 {
+		return
+	}
+	comments := p.pendingComments()
+	line := p.tokens[p.current-1].EndLine
+	n := 0
+	for n < len(comments) && comments[n].Line == line {
+		n++
+	}
+	ast.AddComments(prev, nil, p.takeComments(n))
+}
+
+// closeComments attaches the comments before the closing bracket of
+// container, whose last member is last: those on the line of the token
+// before trail last, and the rest dangle inside container
+func (p *Parser) closeComments(container, last ast.Value) {
+	p.trailingComments(last)
+	dangling := p.leadingComments()
+	if len(dangling) == 0 {
+		return
+	}
+	c := ast.CommentsOf(container)
+	if c == nil {
+		c = &ast.Comments{}
+		ast.SetComments(container, c)
+	}
+	c.Dangling = append(c.Dangling, dangling...)
+}
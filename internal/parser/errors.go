@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// Limit names the Options field a document exceeded
+type Limit string
+
+// Limits reported by LimitError
+const (
+	LimitDepth         Limit = "MaxDepth"
+	LimitObjectKeys    Limit = "MaxObjectKeys"
+	LimitArrayElements Limit = "MaxArrayElements"
+	LimitErrors        Limit = "MaxErrors"
+)
+
+// LimitError reports a document that exceeds one of the size limits in Options.
+// Line, Column and Offset locate the token that went over the limit, and
+// Path the array or object it is in.
+type LimitError struct {
+	Limit  Limit
+	Max    int
+	Line   int
+	Column int
+	Offset int64
+	Path   string
+
+	Snippet *lexer.Snippet // Source line of the token, filled in by WithSnippets
+}
+
+func (e *LimitError) Error() string {
+	var what string
+	switch e.Limit {
+	case LimitDepth:
+		what = fmt.Sprintf("maximum nesting depth of %d exceeded", e.Max)
+	case LimitObjectKeys:
+		what = fmt.Sprintf("object has more than %d keys", e.Max)
+	case LimitArrayElements:
+		what = fmt.Sprintf("array has more than %d elements", e.Max)
+	case LimitErrors:
+		what = fmt.Sprintf("too many errors, stopped after %d", e.Max)
+	default:
+		what = fmt.Sprintf("%s of %d exceeded", e.Limit, e.Max)
+	}
+	return fmt.Sprintf("Parser error at line %d, column %d: %s%s", e.Line, e.Column, what, at(e.Path))
+}
+
+// Format adds the source snippet to the message under %+v
+func (e *LimitError) Format(f fmt.State, verb rune) {
+	lexer.FormatError(f, verb, e, e.Snippet)
+}
+
+// ErrorCode returns the code of the limit exceeded
+func (e *LimitError) ErrorCode() lexer.ErrorCode {
+	switch e.Limit {
+	case LimitObjectKeys:
+		return lexer.CodeTooManyKeys
+	case LimitArrayElements:
+		return lexer.CodeTooManyElements
+	case LimitErrors:
+		return lexer.CodeTooManyErrors
+	}
+	return lexer.CodeTooDeep
+}
+
+// DuplicateKeyError reports a key repeated within one object while
+// RejectDuplicateKeys is set. Line, Column and Offset locate the repeat, and
+// Path the object.
+type DuplicateKeyError struct {
+	Key    string
+	Line   int
+	Column int
+	Offset int64
+	Path   string
+
+	Snippet *lexer.Snippet // Source line of the repeat, filled in by WithSnippets
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("Parser error at line %d, column %d: duplicate key %q%s", e.Line, e.Column, e.Key, at(e.Path))
+}
+
+// Format adds the source snippet to the message under %+v
+func (e *DuplicateKeyError) Format(f fmt.State, verb rune) {
+	lexer.FormatError(f, verb, e, e.Snippet)
+}
+
+// ErrorCode returns lexer.CodeDuplicateKey
+func (e *DuplicateKeyError) ErrorCode() lexer.ErrorCode {
+	return lexer.CodeDuplicateKey
+}
+
+// ValueError reports a dialect value rejected by its value hook through
+// Parser.Errorf, or a value hook that broke its contract. Line, Column and
+// Offset locate the token the parser was at, and Path the value.
+type ValueError struct {
+	Msg    string
+	Code   lexer.ErrorCode // lexer.CodeInvalidValue, or lexer.CodeBadHook for a broken hook
+	Line   int
+	Column int
+	Offset int64
+	Path   string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("Parser error at line %d, column %d: %s%s", e.Line, e.Column, e.Msg, at(e.Path))
+}
+
+// ErrorCode returns Code
+func (e *ValueError) ErrorCode() lexer.ErrorCode {
+	return e.Code
+}
+
+// WithSnippets fills in the source snippet of err, or of every error in a
+// lexer.ErrorList, from the input lex still holds, and returns err. Errors
+// whose line lex no longer holds are left without one.
+func WithSnippets(err error, lex *lexer.Lexer) error {
+	var list lexer.ErrorList
+	if errors.As(err, &list) {
+		for _, err := range list {
+			WithSnippets(err, lex)
+		}
+		return err
+	}
+
+	switch e := err.(type) {
+	case *lexer.UnexpectedTokenError:
+		if e.Snippet == nil {
+			e.Snippet = lex.Snippet(e.Token.Line, e.Token.Column)
+		}
+	case *lexer.SyntaxError:
+		if e.Snippet == nil {
+			e.Snippet = lex.Snippet(e.Line, e.Column)
+		}
+	case *lexer.UnterminatedStringError:
+		if e.Snippet == nil {
+			e.Snippet = lex.Snippet(e.Line, e.Column)
+		}
+	case *LimitError:
+		if e.Snippet == nil {
+			e.Snippet = lex.Snippet(e.Line, e.Column)
+		}
+	case *DuplicateKeyError:
+		if e.Snippet == nil {
+			e.Snippet = lex.Snippet(e.Line, e.Column)
+		}
+	}
+	return err
+}
+
+// pathSegment is a member or element on the way from the root to the value
+// being parsed
+type pathSegment struct {
+	key   string
+	index int         // element index, or -1 for a member
+	token lexer.Token // key token, whose literal Validate leaves empty
+}
+
+// formatPath writes segments as a path such as $.items[3].price, or "" when
+// there are none. keyOf returns the name of a member segment.
+func formatPath(segments []pathSegment, keyOf func(pathSegment) string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, s := range segments {
+		if s.index >= 0 {
+			b.WriteString("[" + strconv.Itoa(s.index) + "]")
+			continue
+		}
+		key := keyOf(s)
+		if isIdentifier(key) {
+			b.WriteString("." + key)
+		} else {
+			b.WriteString("[" + strconv.Quote(key) + "]")
+		}
+	}
+	return b.String()
+}
+
+// isIdentifier checks if key can be written in dot notation
+func isIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// at is the suffix naming where in the document an error was found
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " at " + path
+}
+
+// hintFor names the common mistake, if any, that tok looks like when found
+// after a token of type prev where expected was wanted, and suggests a fix.
+// It returns "" for both otherwise.
+func hintFor(prev lexer.TokenType, tok lexer.Token, expected lexer.TokenType) (lexer.ErrorCode, string) {
+	closes := tok.Type == lexer.TokenRightBrace || tok.Type == lexer.TokenRightBracket
+	switch {
+	case prev == lexer.TokenComma && closes:
+		return lexer.CodeTrailingComma, "remove the trailing comma"
+	case !beginsValue(tok.Type):
+		return "", ""
+	case expected == lexer.TokenComma || expected == lexer.TokenRightBrace || expected == lexer.TokenRightBracket:
+		return lexer.CodeMissingComma, "did you forget a comma?"
+	case expected == lexer.TokenColon:
+		return lexer.CodeMissingColon, "did you forget a colon?"
+	}
+	return "", ""
+}
+
+// beginsValue reports whether a token of type t starts a standard value
+func beginsValue(t lexer.TokenType) bool {
+	switch t {
+	case lexer.TokenString, lexer.TokenNumber, lexer.TokenTrue, lexer.TokenFalse, lexer.TokenNull,
+		lexer.TokenLeftBrace, lexer.TokenLeftBracket:
+		return true
+	}
+	return false
+}
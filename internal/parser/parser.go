@@ -1,68 +1,466 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/letsmakecakes/jsonparser/internal/ast"
 	"github.com/letsmakecakes/jsonparser/internal/lexer"
 )
 
+// DefaultMaxDepth is the nesting limit used when Options.MaxDepth is 0
+const DefaultMaxDepth = 10000
+
+// Options restricts, or extends, the documents a Parser accepts
+type Options struct {
+	MaxDepth            int                           // Deepest nesting of arrays and objects; 0 selects DefaultMaxDepth, negative disables the limit
+	MaxObjectKeys       int                           // Most members in one object; 0 means no limit
+	MaxArrayElements    int                           // Most elements in one array; 0 means no limit
+	RejectDuplicateKeys bool                          // Fail on objects that repeat a key instead of keeping the last value
+	AllowTrailingCommas bool                          // Accept a comma before a closing bracket or brace
+	RecordSpans         bool                          // Record the source span of every node
+	LinkParents         bool                          // Set the Parent of every node; see ast.Link
+	Recover             bool                          // Carry on after syntax errors and return them all as a lexer.ErrorList
+	BadValues           bool                          // Under Recover, put an ast.BadValue wherever a value was skipped
+	MaxErrors           int                           // Under Recover, stop with a LimitError after this many errors; 0 means no limit
+	Values              map[lexer.TokenType]ValueHook // Dialect values, by the type of the token they start with
+}
+
+// HookParser is the view of the parser given to a ValueHook. Parser
+// implements it.
+type HookParser interface {
+	// Current returns the token being parsed
+	Current() lexer.Token
+	// Next consumes the current token and returns it
+	Next() lexer.Token
+	// ParseValue parses the value starting at the current token
+	ParseValue() (ast.Value, error)
+	// Errorf returns an error located at the current token and path
+	Errorf(format string, args ...interface{}) error
+}
+
+// ValueHook parses a dialect value starting at the current token, which has a
+// type the standard grammar does not know. It must consume every token of the
+// value, and counts as one level of nesting towards MaxDepth.
+type ValueHook func(p HookParser) (ast.Value, error)
+
+// contextCheckInterval is how many values ParseDocumentContext parses between checks of its context
+const contextCheckInterval = 1024
+
 type Parser struct {
-	tokens  []lexer.Token
-	current int
+	tokens   []lexer.Token
+	current  int
+	depth    int
+	opts     Options
+	ctx      context.Context // nil unless parsing through ParseDocumentContext
+	values   int             // values parsed, to pace context checks
+	errs     []error         // errors recovered from under Options.Recover
+	reported int             // token at which the last error was recorded
+	path     []pathSegment   // members and elements from the root to the value being parsed
+
+	comments    []lexer.Comment // comments to attach to the nodes around them; see SetComments
+	nextComment int             // first comment not yet attached
 }
 
+// Parse parses a document whose root must be an object
 func Parse(tokens []lexer.Token) (*ast.Object, error) {
 	p := &Parser{tokens: tokens, current: 0}
-	return p.parseObject()
+	obj, err := p.parseObject()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ParseValue parses a document whose root may be any JSON value
+func ParseValue(tokens []lexer.Token) (ast.Value, error) {
+	return NewParser(tokens).ParseDocument()
+}
+
+// NewParser initializes a Parser over the given tokens
+func NewParser(tokens []lexer.Token) *Parser {
+	return &Parser{tokens: tokens, current: 0}
+}
+
+// SetOptions changes the restrictions applied by the Parser
+func (p *Parser) SetOptions(opts Options) {
+	p.opts = opts
+}
+
+// SetComments gives the Parser the comments skipped between its tokens, as
+// lexer.Lexer.Comments returns them, to attach to the nodes around them as
+// ast.Comments
+func (p *Parser) SetComments(comments []lexer.Comment) {
+	p.comments, p.nextComment = comments, 0
+}
+
+// ParseDocumentContext is like ParseDocument but stops with ctx.Err() once ctx is done
+func (p *Parser) ParseDocumentContext(ctx context.Context) (ast.Value, error) {
+	p.ctx = ctx
+	defer func() { p.ctx = nil }()
+	return p.ParseDocument()
+}
+
+// ParseDocument parses the tokens as a document whose root may be any JSON
+// value. Under Options.Recover it skips past syntax errors, resuming at the
+// next comma or closing bracket, and returns what it could parse together
+// with a lexer.ErrorList of every error; the value is nil when not even the
+// root could be parsed, or an ast.BadValue under Options.BadValues. Tokens of
+// type lexer.TokenInvalid are reported with the error they hold.
+func (p *Parser) ParseDocument() (ast.Value, error) {
+	start := p.current
+	value, err := p.parseValue()
+	if err != nil {
+		if value, err = p.recoverValue(err, start); err != nil {
+			return nil, p.errors(err)
+		}
+	}
+	if err := p.expectEOF(); err != nil {
+		if !p.opts.Recover {
+			return nil, err
+		}
+		if p.peekTypeIs(lexer.TokenInvalid) {
+			err = p.peek().Err
+		}
+		if err := p.report(err); err != nil {
+			return nil, p.errors(err)
+		}
+		for p.nextToken(); !p.peekTypeIs(lexer.TokenEOF); p.nextToken() {
+			if !p.peekTypeIs(lexer.TokenInvalid) {
+				continue
+			}
+			if err := p.report(p.peek().Err); err != nil {
+				return nil, p.errors(err)
+			}
+		}
+	}
+	if value != nil {
+		ast.AddComments(value, nil, p.leadingComments())
+	}
+	if p.opts.LinkParents && value != nil {
+		ast.Link(value)
+	}
+	if len(p.errs) > 0 {
+		return value, p.errors(nil)
+	}
+	return value, nil
+}
+
+// errors returns the recovered errors followed by err, which ended parsing,
+// or just err when there were none
+func (p *Parser) errors(err error) error {
+	if len(p.errs) == 0 {
+		return err
+	}
+	errs := lexer.ErrorList(p.errs)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
 }
 
 func (p *Parser) parseObject() (*ast.Object, error) {
-	obj := &ast.Object{}
+	obj := &ast.Object{Pairs: make(map[string]ast.Value)}
 
 	if !p.expectCurrent(lexer.TokenLeftBrace) {
-		return nil, lexer.NewUnexpectedTokenError(p.peek(), lexer.TokenLeftBrace)
+		return nil, p.unexpected(p.peek(), lexer.TokenLeftBrace)
 	}
-
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
 	p.nextToken()
 
-	for !p.peekTypeIs(lexer.TokenRightBrace) && !p.peekTypeIs(lexer.TokenEOF) {
-		keyToken := p.peek()
-		if keyToken.Type != lexer.TokenString {
-			return nil, lexer.NewUnexpectedTokenError(keyToken, lexer.TokenString)
+	// Handle empty object case
+	if p.peekTypeIs(lexer.TokenRightBrace) {
+		p.closeComments(obj, nil)
+		p.nextToken() // consume the closing brace
+		return obj, nil
+	}
+
+	var last ast.Value // the value of the last member, for its trailing comments
+	for members := 1; ; members++ {
+		p.trailingComments(last)
+		if p.opts.AllowTrailingCommas && p.peekTypeIs(lexer.TokenRightBrace) {
+			break
+		}
+		value, err := p.parseMember(obj, members)
+		if err != nil {
+			if err := p.resync(err); err != nil {
+				return nil, err
+			}
+		}
+		if value != nil {
+			last = value
 		}
-		key := keyToken.Literal
-		p.nextToken()
 
-		if !p.expectCurrent(lexer.TokenColon) {
-			return nil, lexer.NewUnexpectedTokenError(p.peek(), lexer.TokenColon)
+		if p.peekTypeIs(lexer.TokenComma) {
+			ast.AddComments(last, nil, p.leadingComments())
+			p.nextToken() // skip the comma, a key must follow
+			continue
 		}
-		p.nextToken()
+		if p.peekTypeIs(lexer.TokenRightBrace) {
+			break
+		}
+		if p.opts.Recover && (p.peekTypeIs(lexer.TokenString) || p.peekTypeIs(lexer.TokenIdentifier)) {
+			// A key here starts the next member after a missing comma
+			if err := p.report(p.unexpected(p.peek(), lexer.TokenComma)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := p.resync(p.unexpected(p.peek(), lexer.TokenRightBrace)); err != nil {
+			return nil, err
+		}
+		if p.peekTypeIs(lexer.TokenComma) {
+			p.nextToken()
+			continue
+		}
+		if !p.peekTypeIs(lexer.TokenRightBrace) {
+			return obj, nil // cut short by the end of input or an enclosing closer
+		}
+		break
+	}
+	p.closeComments(obj, last)
+	p.nextToken()
 
-		value, err := p.parseValue()
-		if err != nil {
+	return obj, nil
+}
+
+// parseMember parses one key and value into obj, returning the value
+func (p *Parser) parseMember(obj *ast.Object, members int) (ast.Value, error) {
+	keyToken := p.peek()
+	if keyToken.Type != lexer.TokenString && keyToken.Type != lexer.TokenIdentifier {
+		return nil, p.unexpected(keyToken, lexer.TokenString)
+	}
+	if err := checkCount(keyToken, LimitObjectKeys, members, p.opts.MaxObjectKeys, p); err != nil {
+		return nil, err
+	}
+	key := keyToken.Literal
+	if _, ok := obj.Pairs[key]; ok && p.opts.RejectDuplicateKeys {
+		if !p.opts.Recover {
+			return nil, newDuplicateKeyError(keyToken, p.where())
+		}
+		if err := p.report(newDuplicateKeyError(keyToken, p.errorPath())); err != nil {
 			return nil, err
 		}
+	}
+	leading := p.leadingComments()
+	p.nextToken()
+	p.path = append(p.path, pathSegment{key: key, index: -1})
+	defer p.leavePath()
 
-		obj.Pairs[key] = value
+	start := p.current
+	if p.expectCurrent(lexer.TokenColon) {
+		leading = append(leading, p.leadingComments()...)
+		start++
+	}
+	value, err := p.parseMemberValue()
+	if err != nil && p.opts.BadValues {
+		value, err = p.recoverValue(err, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ast.AddComments(value, leading, nil)
+	obj.Set(key, value)
+	return value, nil
+}
 
-		if p.peekTypeIs(lexer.TokenComma) {
-			p.nextToken()
-		} else {
-			break
+// parseMemberValue parses the colon and value that follow a key
+func (p *Parser) parseMemberValue() (ast.Value, error) {
+	if !p.expectCurrent(lexer.TokenColon) {
+		return nil, p.unexpected(p.peek(), lexer.TokenColon)
+	}
+	p.nextToken()
+	return p.parseValue()
+}
+
+// recoverValue resyncs after err, a failure to parse the value that starts
+// at token start. Under Options.BadValues it returns an ast.BadValue holding
+// the first error reported and the span of the tokens skipped, and otherwise
+// nil. Errors resync cannot recover from are returned.
+func (p *Parser) recoverValue(err error, start int) (ast.Value, error) {
+	reported := len(p.errs)
+	if err := p.resync(err); err != nil {
+		return nil, err
+	}
+	if !p.opts.BadValues {
+		return nil, nil
+	}
+	bad := &ast.BadValue{Err: p.errs[min(reported, len(p.errs)-1)]}
+	if p.current > start {
+		bad.Span = p.span(start)
+	} else {
+		tok := p.peek()
+		at := ast.Pos{Offset: tok.Offset, Line: tok.Line, Column: tok.Column}
+		bad.Span = ast.Span{Start: at, End: at}
+	}
+	return bad, nil
+}
+
+// resync records a syntax error under Options.Recover and skips to the next
+// comma or closer of the enclosing array or object, so parsing can go on.
+// Other errors, and every error without Recover, are returned unchanged.
+func (p *Parser) resync(err error) error {
+	if !p.opts.Recover || !errors.Is(err, lexer.ErrSyntax) {
+		return err
+	}
+	var unexpected *lexer.UnexpectedTokenError
+	if errors.As(err, &unexpected) && unexpected.Token.Type == lexer.TokenInvalid && p.peekTypeIs(lexer.TokenInvalid) {
+		// The token's own error says more
+		if err := p.report(unexpected.Token.Err); err != nil {
+			return err
 		}
+		p.nextToken()
+	} else if err := p.report(err); err != nil {
+		return err
 	}
+	return p.skip()
+}
 
-	if !p.expectCurrent(lexer.TokenRightBrace) {
-		return nil, lexer.NewUnexpectedTokenError(p.peek(), lexer.TokenRightBrace)
+// report records an error found at the current token. Only the first error
+// at a token is kept, as the others follow from it. Once Options.MaxErrors
+// errors are kept, it returns a LimitError for the next one instead.
+func (p *Parser) report(err error) error {
+	if len(p.errs) > 0 && p.reported == p.current {
+		return nil
 	}
+	if p.opts.MaxErrors > 0 && len(p.errs) >= p.opts.MaxErrors {
+		tok := p.peek()
+		return &LimitError{Limit: LimitErrors, Max: p.opts.MaxErrors, Line: tok.Line, Column: tok.Column, Offset: tok.Offset, Path: p.where()}
+	}
+	p.errs = append(p.errs, err)
+	p.reported = p.current
+	return nil
+}
 
-	return obj, nil
+// skip advances to the next comma or closer outside nested arrays and
+// objects, or to the end of input, recording the errors of invalid tokens
+// passed on the way
+func (p *Parser) skip() error {
+	depth := 0
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case lexer.TokenEOF:
+			return nil
+		case lexer.TokenLeftBrace, lexer.TokenLeftBracket:
+			depth++
+		case lexer.TokenRightBrace, lexer.TokenRightBracket:
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		case lexer.TokenComma:
+			if depth == 0 {
+				return nil
+			}
+		case lexer.TokenInvalid:
+			if err := p.report(tok.Err); err != nil {
+				return err
+			}
+		}
+		p.nextToken()
+	}
 }
 
 func (p *Parser) expectCurrent(tokenType lexer.TokenType) bool {
-	return p.tokens[p.current].Type == tokenType
+	return p.peek().Type == tokenType
+}
+
+// expectEOF ensures nothing follows the root value
+func (p *Parser) expectEOF() error {
+	if !p.expectCurrent(lexer.TokenEOF) {
+		return p.unexpected(p.peek(), lexer.TokenEOF)
+	}
+	return nil
+}
+
+// enter records that the current token opens a container, failing past the depth limit
+func (p *Parser) enter() error {
+	p.depth++
+	return checkDepth(p.peek(), p.depth, p.opts.MaxDepth, p)
+}
+
+func (p *Parser) leave() {
+	p.depth--
+}
+
+func (p *Parser) leavePath() {
+	p.path = p.path[:len(p.path)-1]
+}
+
+// where returns the path of the value being parsed, "" for the root
+func (p *Parser) where() string {
+	return formatPath(p.path, func(s pathSegment) string { return s.key })
+}
+
+// unexpected reports tok, the current token, found where expected was
+// wanted, at the current path
+func (p *Parser) unexpected(tok lexer.Token, expected lexer.TokenType) error {
+	var prev lexer.TokenType
+	if p.current > 0 && p.current <= len(p.tokens) {
+		prev = p.tokens[p.current-1].Type
+	}
+	code, hint := hintFor(prev, tok, expected)
+	return &lexer.UnexpectedTokenError{Token: tok, Expected: expected, Path: p.errorPath(), Code: code, Hint: hint}
+}
+
+// errorPath returns the path for a syntax error found at the current token,
+// or "" when recovery has already kept an error at this token, so report is
+// sure to drop the new one. Formatting the path costs as much as the
+// nesting is deep, and an unclosed document fails at its end once per level.
+func (p *Parser) errorPath() string {
+	if p.opts.Recover && len(p.errs) > 0 && p.reported == p.current {
+		return ""
+	}
+	return p.where()
+}
+
+// locator is the Parser or validator, asked for the current path only once
+// an error needs it
+type locator interface {
+	where() string
+}
+
+// checkDepth fails when depth exceeds the limit selected by maxDepth
+func checkDepth(tok lexer.Token, depth, maxDepth int, loc locator) error {
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if maxDepth > 0 && depth > maxDepth {
+		return &LimitError{Limit: LimitDepth, Max: maxDepth, Line: tok.Line, Column: tok.Column, Offset: tok.Offset, Path: loc.where()}
+	}
+	return nil
+}
+
+// checkCount fails when the count-th member of a container exceeds max, unless max is 0
+func checkCount(tok lexer.Token, limit Limit, count, max int, loc locator) error {
+	if max > 0 && count > max {
+		return &LimitError{Limit: limit, Max: max, Line: tok.Line, Column: tok.Column, Offset: tok.Offset, Path: loc.where()}
+	}
+	return nil
+}
+
+// newDuplicateKeyError reports a key that appears twice in the object at path
+func newDuplicateKeyError(tok lexer.Token, path string) error {
+	return &DuplicateKeyError{Key: tok.Literal, Line: tok.Line, Column: tok.Column, Offset: tok.Offset, Path: path}
+}
+
+// Current returns the token being parsed
+func (p *Parser) Current() lexer.Token {
+	return p.peek()
 }
 
 func (p *Parser) peek() lexer.Token {
+	if p.current >= len(p.tokens) {
+		return lexer.Token{Type: lexer.TokenEOF}
+	}
 	return p.tokens[p.current]
 }
 
@@ -71,10 +469,41 @@ func (p *Parser) nextToken() {
 }
 
 func (p *Parser) peekTypeIs(tokenType lexer.TokenType) bool {
-	return p.tokens[p.current].Type == tokenType
+	return p.peek().Type == tokenType
 }
 
 func (p *Parser) parseValue() (ast.Value, error) {
+	p.values++
+	if p.ctx != nil && p.values%contextCheckInterval == 0 {
+		if err := p.ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	start := p.current
+	leading := p.leadingComments()
+	value, err := p.parseToken()
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.RecordSpans {
+		ast.SetSpan(value, p.span(start))
+	}
+	ast.AddComments(value, leading, nil)
+	return value, nil
+}
+
+// span returns the source span of the tokens from start up to the current one
+func (p *Parser) span(start int) ast.Span {
+	first, last := p.tokens[start], p.tokens[p.current-1]
+	return ast.Span{
+		Start: ast.Pos{Offset: first.Offset, Line: first.Line, Column: first.Column},
+		End:   ast.Pos{Offset: last.End, Line: last.EndLine, Column: last.EndColumn},
+	}
+}
+
+// parseToken parses the value the current token starts
+func (p *Parser) parseToken() (ast.Value, error) {
 	tok := p.peek()
 	switch tok.Type {
 	case lexer.TokenString:
@@ -83,6 +512,9 @@ func (p *Parser) parseValue() (ast.Value, error) {
 	case lexer.TokenNumber:
 		p.nextToken()
 		return &ast.Number{Value: tok.Literal}, nil
+	case lexer.TokenTrue, lexer.TokenFalse:
+		p.nextToken()
+		return &ast.Boolean{Value: tok.Literal}, nil
 	case lexer.TokenNull:
 		p.nextToken()
 		return &ast.Null{}, nil
@@ -91,39 +523,132 @@ func (p *Parser) parseValue() (ast.Value, error) {
 	case lexer.TokenLeftBracket:
 		return p.parseArray()
 	default:
-		return nil, lexer.NewUnexpectedTokenError(tok, "a valid value")
+		if hook, ok := p.opts.Values[tok.Type]; ok {
+			return p.parseHook(hook)
+		}
+		return nil, p.unexpected(tok, "a valid value")
+	}
+}
+
+// startsValue reports whether the current token can begin a value
+func (p *Parser) startsValue() bool {
+	switch tok := p.peek(); tok.Type {
+	case lexer.TokenString, lexer.TokenNumber, lexer.TokenTrue, lexer.TokenFalse, lexer.TokenNull,
+		lexer.TokenLeftBrace, lexer.TokenLeftBracket, lexer.TokenInvalid:
+		return true
+	default:
+		_, ok := p.opts.Values[tok.Type]
+		return ok
 	}
 }
 
+// parseHook parses a dialect value, checking that the hook made progress
+func (p *Parser) parseHook(hook ValueHook) (ast.Value, error) {
+	tok := p.peek()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	start := p.current
+	value, err := hook(p)
+	if err != nil {
+		return nil, err
+	}
+	if p.current == start || value == nil {
+		msg := fmt.Sprintf("value hook for %s must consume its tokens and return a value", tok.Type)
+		return nil, &ValueError{Msg: msg, Code: lexer.CodeBadHook, Line: tok.Line, Column: tok.Column, Offset: tok.Offset, Path: p.where()}
+	}
+	return value, nil
+}
+
+// Next consumes the current token and returns it
+func (p *Parser) Next() lexer.Token {
+	tok := p.peek()
+	p.nextToken()
+	return tok
+}
+
+// ParseValue parses the value starting at the current token, for use by value hooks
+func (p *Parser) ParseValue() (ast.Value, error) {
+	return p.parseValue()
+}
+
+// Errorf returns a parser error located at the current token and path
+func (p *Parser) Errorf(format string, args ...interface{}) error {
+	tok := p.peek()
+	return &ValueError{Msg: fmt.Sprintf(format, args...), Code: lexer.CodeInvalidValue, Line: tok.Line, Column: tok.Column, Offset: tok.Offset, Path: p.where()}
+}
+
 func (p *Parser) parseArray() (*ast.Array, error) {
 	array := &ast.Array{}
 
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
 	p.nextToken() // skip the opening bracket
 
 	// Handle empty array case
 	if p.peekTypeIs(lexer.TokenRightBracket) {
+		p.closeComments(array, nil)
 		p.nextToken() // consume the closing bracket
 		return array, nil
 	}
 
-	for !p.peekTypeIs(lexer.TokenRightBracket) && !p.peekTypeIs(lexer.TokenEOF) {
+	var last ast.Value // the last element, for its trailing comments
+	for elements := 1; ; elements++ {
+		p.trailingComments(last)
+		if p.opts.AllowTrailingCommas && p.peekTypeIs(lexer.TokenRightBracket) {
+			break
+		}
+		if err := checkCount(p.peek(), LimitArrayElements, elements, p.opts.MaxArrayElements, p); err != nil {
+			return nil, err
+		}
+		p.path = append(p.path, pathSegment{index: elements - 1})
+		start := p.current
 		value, err := p.parseValue()
+		if err != nil {
+			value, err = p.recoverValue(err, start)
+		}
+		p.leavePath()
 		if err != nil {
 			return nil, err
 		}
-
-		array.Elements = append(array.Elements, value)
+		if value != nil {
+			array.Elements = append(array.Elements, value)
+			last = value
+		}
 
 		if p.peekTypeIs(lexer.TokenComma) {
-			p.nextToken() // skip the comma
-		} else {
+			ast.AddComments(last, nil, p.leadingComments())
+			p.nextToken() // skip the comma, a value must follow
+			continue
+		}
+		if p.peekTypeIs(lexer.TokenRightBracket) {
 			break
 		}
+		if p.opts.Recover && p.startsValue() {
+			// A value here is the next element after a missing comma
+			if err := p.report(p.unexpected(p.peek(), lexer.TokenComma)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := p.resync(p.unexpected(p.peek(), lexer.TokenRightBracket)); err != nil {
+			return nil, err
+		}
+		if p.peekTypeIs(lexer.TokenComma) {
+			p.nextToken()
+			continue
+		}
+		if !p.peekTypeIs(lexer.TokenRightBracket) {
+			return array, nil // cut short by the end of input or an enclosing closer
+		}
+		break
 	}
+	p.closeComments(array, last)
+	p.nextToken()
 
-	if !p.expectCurrent(lexer.TokenRightBracket) {
-		return nil, lexer.NewUnexpectedTokenError(p.peek(), lexer.TokenRightBracket)
-	}
-
-	return nil, nil
+	return array, nil
 }
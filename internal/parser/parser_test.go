@@ -1,8 +1,16 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/letsmakecakes/jsonparser/internal/ast"
 	"github.com/letsmakecakes/jsonparser/internal/lexer"
 )
 
@@ -23,3 +31,675 @@ func TestParse_EmptyObject(t *testing.T) {
 		t.Errorf("expected empty object, got %v", obj.Pairs)
 	}
 }
+
+func TestParse_Fixtures(t *testing.T) {
+	tests := []struct {
+		file  string
+		valid bool
+	}{
+		{"step1/valid.json", true},
+		{"step1/invalid.json", false},
+		{"step2/valid.json", true},
+		{"step2/valid2.json", true},
+		{"step2/invalid.json", false},
+		{"step2/invalid2.json", false},
+		{"step3/valid.json", true},
+		{"step3/invalid.json", false},
+		{"step4/valid.json", true},
+		{"step4/valid2.json", true},
+		{"step4/invalid.json", false},
+	}
+
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join("..", "..", "tests", tt.file))
+		if err != nil {
+			t.Fatalf("reading %s: %v", tt.file, err)
+		}
+
+		tokens, err := lexer.NewLexer(string(data)).Tokenize()
+		if err == nil {
+			_, err = Parse(tokens)
+		}
+
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid JSON, got error: %v", tt.file, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error, got none", tt.file)
+		}
+	}
+}
+
+func TestParseValue_ScalarsAndArrays(t *testing.T) {
+	input := `[true, false, null, "s", 1.5, [], {}]`
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	value, err := ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+
+	array, ok := value.(*ast.Array)
+	if !ok {
+		t.Fatalf("expected *ast.Array, got %T", value)
+	}
+	if len(array.Elements) != 7 {
+		t.Fatalf("expected 7 elements, got %d", len(array.Elements))
+	}
+	if b, ok := array.Elements[0].(*ast.Boolean); !ok || b.Value != "true" {
+		t.Errorf("expected boolean true, got %#v", array.Elements[0])
+	}
+}
+
+func TestParseValue_RejectsTrailingTokens(t *testing.T) {
+	tokens, err := lexer.NewLexer(`{} {}`).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	if _, err := ParseValue(tokens); err == nil {
+		t.Errorf("expected an error for trailing tokens")
+	}
+}
+
+func TestParser_Options(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		valid bool
+	}{
+		{"within depth", `[[{"a": [1]}]]`, Options{MaxDepth: 4}, true},
+		{"beyond depth", `[[{"a": [1]}]]`, Options{MaxDepth: 3}, false},
+		{"unlimited depth", strings.Repeat("[", 20000) + strings.Repeat("]", 20000), Options{MaxDepth: -1}, true},
+		{"default depth", strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1), Options{}, false},
+		{"duplicate keys allowed", `{"a": 1, "a": 2}`, Options{}, true},
+		{"duplicate keys rejected", `{"a": 1, "b": {"a": 2}, "a": 3}`, Options{RejectDuplicateKeys: true}, false},
+		{"nested keys are separate", `{"a": {"a": 1}, "b": [{"a": 2}]}`, Options{RejectDuplicateKeys: true}, true},
+		{"within key limit", `{"a": 1, "b": {"c": 2, "d": 3}}`, Options{MaxObjectKeys: 2}, true},
+		{"beyond key limit", `{"a": 1, "b": 2, "c": 3}`, Options{MaxObjectKeys: 2}, false},
+		{"duplicates count towards key limit", `{"a": 1, "a": 2, "a": 3}`, Options{MaxObjectKeys: 2}, false},
+		{"within element limit", `[1, [2, 3], 4]`, Options{MaxArrayElements: 3}, true},
+		{"beyond element limit", `[[1, 2, 3, 4]]`, Options{MaxArrayElements: 3}, false},
+		{"trailing commas rejected", `[1, {"a": 2,},]`, Options{}, false},
+		{"trailing commas allowed", `[1, {"a": 2,},]`, Options{AllowTrailingCommas: true}, true},
+		{"lone comma rejected", `[,]`, Options{AllowTrailingCommas: true}, false},
+		{"double comma rejected", `{"a": 1,,}`, Options{AllowTrailingCommas: true}, false},
+	}
+
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.input).Tokenize()
+		if err != nil {
+			t.Fatalf("%s: Lexer error: %v", tt.name, err)
+		}
+		p := NewParser(tokens)
+		p.SetOptions(tt.opts)
+		_, parseErr := p.ParseDocument()
+		validateErr := Validate(lexer.NewLexer(tt.input), tt.opts)
+
+		if (parseErr == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, ParseDocument returned %v", tt.name, tt.valid, parseErr)
+		}
+		if (validateErr == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, Validate returned %v", tt.name, tt.valid, validateErr)
+		}
+	}
+}
+
+func TestParser_ParseDocumentContext(t *testing.T) {
+	input := "[" + strings.Repeat("1,", 5000) + "1]"
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewParser(tokens).ParseDocumentContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if _, err := NewParser(tokens).ParseDocumentContext(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParser_TypedErrors(t *testing.T) {
+	input := `{"a": 1, "b" 2, "a": 3}`
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	_, parseErr := NewParser(tokens).ParseDocument()
+	validateErr := Validate(lexer.NewLexer(input), Options{})
+	for _, err := range []error{parseErr, validateErr} {
+		var unexpected *lexer.UnexpectedTokenError
+		if !errors.As(err, &unexpected) || unexpected.Expected != lexer.TokenColon || unexpected.Token.Offset != 13 {
+			t.Errorf("expected a missing colon at offset 13, got %v", err)
+		}
+		if !errors.Is(err, lexer.ErrSyntax) {
+			t.Errorf("expected errors.Is(err, ErrSyntax) for %v", err)
+		}
+	}
+
+	input = `{"a": 1, "b": 2, "a": 3}`
+	tokens, _ = lexer.NewLexer(input).Tokenize()
+	p := NewParser(tokens)
+	p.SetOptions(Options{RejectDuplicateKeys: true})
+	_, parseErr = p.ParseDocument()
+	validateErr = Validate(lexer.NewLexer(input), Options{RejectDuplicateKeys: true})
+	expected := DuplicateKeyError{Key: "a", Line: 1, Column: 18, Offset: 17}
+	for _, err := range []error{parseErr, validateErr} {
+		var duplicate *DuplicateKeyError
+		if !errors.As(err, &duplicate) || *duplicate != expected {
+			t.Errorf("expected %+v, got %v", expected, err)
+		}
+	}
+}
+
+func TestWithSnippets(t *testing.T) {
+	lex := lexer.NewLexer("[\n  [1]]")
+	tokens, _ := lex.Tokenize()
+	p := NewParser(tokens)
+	p.SetOptions(Options{MaxDepth: 1})
+	_, err := p.ParseDocument()
+	var limitErr *LimitError
+	if !errors.As(WithSnippets(err, lex), &limitErr) || limitErr.Snippet == nil {
+		t.Fatalf("expected a limit error with a snippet, got %v", err)
+	}
+	if got := limitErr.Snippet.String(); got != "2 |   [1]]\n  |   ^" {
+		t.Errorf("unexpected snippet\n%s", got)
+	}
+
+	if err := WithSnippets(nil, lex); err != nil {
+		t.Errorf("expected nil to stay nil, got %v", err)
+	}
+}
+
+func TestParser_ErrorPaths(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		path  string
+	}{
+		{`{"items": [{"price": 1}, {"price": }]}`, Options{}, "$.items[1].price"},
+		{`{"items": [1, 2 3]}`, Options{}, "$.items"},
+		{`{"a b": {"c" 1}}`, Options{}, `$["a b"].c`},
+		{`{"a": [[], {"b": 1, "b": 2}]}`, Options{RejectDuplicateKeys: true}, "$.a[1]"},
+		{`{"a": {"b": [1, 2, 3]}}`, Options{MaxArrayElements: 2}, "$.a.b"},
+		{`{"a": 1,}`, Options{}, ""},
+		{`[1] 2`, Options{}, ""},
+	}
+
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.input).Tokenize()
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		p := NewParser(tokens)
+		p.SetOptions(tt.opts)
+		_, parseErr := p.ParseDocument()
+		validateErr := Validate(lexer.NewLexer(tt.input), tt.opts)
+		for _, err := range []error{parseErr, validateErr} {
+			if err == nil {
+				t.Fatalf("%s: expected an error", tt.input)
+			}
+			var path string
+			var unexpected *lexer.UnexpectedTokenError
+			var duplicate *DuplicateKeyError
+			var limitErr *LimitError
+			switch {
+			case errors.As(err, &unexpected):
+				path = unexpected.Path
+			case errors.As(err, &duplicate):
+				path = duplicate.Path
+			case errors.As(err, &limitErr):
+				path = limitErr.Path
+			}
+			if path != tt.path {
+				t.Errorf("%s: expected path %q, got %q in %v", tt.input, tt.path, path, err)
+			}
+			message := err.Error()
+			if unexpected != nil && unexpected.Hint != "" {
+				message = strings.TrimSuffix(message, "; "+unexpected.Hint)
+			}
+			if hasPath := strings.HasSuffix(message, " at "+tt.path); hasPath != (tt.path != "") {
+				t.Errorf("%s: expected the path at the end of %q", tt.input, err)
+			}
+		}
+	}
+
+	// Paths from the streaming lexer read keys back while they are buffered
+	err := Validate(lexer.NewReaderLexer(strings.NewReader(`{"key": {"x\u0041": tru}}`)), Options{})
+	if err == nil || !strings.Contains(err.Error(), "invalid literal tru") {
+		t.Errorf("expected the lexer error, got %v", err)
+	}
+	err = Validate(lexer.NewReaderLexer(strings.NewReader(`{"key": {"x\u0041": ]}}`)), Options{})
+	if err == nil || !strings.HasSuffix(err.Error(), " at $.key.xA") {
+		t.Errorf("expected the path with decoded keys, got %v", err)
+	}
+}
+
+func TestParser_Hints(t *testing.T) {
+	tests := []struct {
+		input string
+		hint  string
+	}{
+		{`[1, 2,]`, "remove the trailing comma"},
+		{`{"a": 1,}`, "remove the trailing comma"},
+		{`{"a": [1,],}`, "remove the trailing comma"},
+		{`[1 2]`, "did you forget a comma?"},
+		{`{"a": 1 "b": 2}`, "did you forget a comma?"},
+		{`[{} {}]`, "did you forget a comma?"},
+		{`{"a" 1}`, "did you forget a colon?"},
+		{`[1,,2]`, ""},
+		{`[1] 2`, ""},
+		{`{"a": }`, ""},
+	}
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.input).Tokenize()
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		_, parseErr := NewParser(tokens).ParseDocument()
+		validateErr := Validate(lexer.NewLexer(tt.input), Options{})
+		for _, err := range []error{parseErr, validateErr} {
+			var unexpected *lexer.UnexpectedTokenError
+			if !errors.As(err, &unexpected) {
+				t.Fatalf("%s: expected an *UnexpectedTokenError, got %v", tt.input, err)
+			}
+			if unexpected.Hint != tt.hint {
+				t.Errorf("%s: expected hint %q, got %q", tt.input, tt.hint, unexpected.Hint)
+			}
+			if tt.hint != "" && !strings.HasSuffix(err.Error(), "; "+tt.hint) {
+				t.Errorf("%s: expected the hint at the end of %q", tt.input, err)
+			}
+		}
+	}
+}
+
+func TestParser_LimitErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     Options
+		expected LimitError
+		message  string
+	}{
+		{`[[1]]`, Options{MaxDepth: 1}, LimitError{Limit: LimitDepth, Max: 1, Line: 1, Column: 2, Offset: 1, Path: "$[0]"}, "maximum nesting depth of 1 exceeded at $[0]"},
+		{`{"a": 1, "b": 2}`, Options{MaxObjectKeys: 1}, LimitError{Limit: LimitObjectKeys, Max: 1, Line: 1, Column: 10, Offset: 9}, "object has more than 1 keys"},
+		{`[1, 2, 3]`, Options{MaxArrayElements: 2}, LimitError{Limit: LimitArrayElements, Max: 2, Line: 1, Column: 8, Offset: 7}, "array has more than 2 elements"},
+	}
+
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.input).Tokenize()
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		p := NewParser(tokens)
+		p.SetOptions(tt.opts)
+		_, parseErr := p.ParseDocument()
+		validateErr := Validate(lexer.NewLexer(tt.input), tt.opts)
+
+		for _, err := range []error{parseErr, validateErr} {
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || *limitErr != tt.expected {
+				t.Errorf("%s: expected %+v, got %v", tt.input, tt.expected, err)
+				continue
+			}
+			if !strings.HasSuffix(err.Error(), tt.message) {
+				t.Errorf("%s: expected message ending %q, got %q", tt.input, tt.message, err)
+			}
+		}
+	}
+}
+
+func TestParser_RecordSpans(t *testing.T) {
+	input := "{\"a\": [1,\n  null]}"
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	p := NewParser(tokens)
+	p.SetOptions(Options{RecordSpans: true})
+	value, err := p.ParseDocument()
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+
+	array := value.(*ast.Object).Pairs["a"].(*ast.Array)
+	tests := []struct {
+		name     string
+		node     ast.Value
+		expected ast.Span
+	}{
+		{"object", value, ast.Span{Start: ast.Pos{Offset: 0, Line: 1, Column: 1}, End: ast.Pos{Offset: 18, Line: 2, Column: 9}}},
+		{"array", array, ast.Span{Start: ast.Pos{Offset: 6, Line: 1, Column: 7}, End: ast.Pos{Offset: 17, Line: 2, Column: 8}}},
+		{"number", array.Elements[0], ast.Span{Start: ast.Pos{Offset: 7, Line: 1, Column: 8}, End: ast.Pos{Offset: 8, Line: 1, Column: 9}}},
+		{"null", array.Elements[1], ast.Span{Start: ast.Pos{Offset: 12, Line: 2, Column: 3}, End: ast.Pos{Offset: 16, Line: 2, Column: 7}}},
+	}
+	for _, tt := range tests {
+		if got := ast.SpanOf(tt.node); got != tt.expected {
+			t.Errorf("%s: expected span %+v, got %+v", tt.name, tt.expected, got)
+		}
+	}
+
+	if value, err := ParseValue(tokens); err != nil || ast.SpanOf(value).IsValid() {
+		t.Errorf("expected no spans unless RecordSpans is set, got %v", err)
+	}
+}
+
+func TestParser_Comments(t *testing.T) {
+	input := `// config
+{
+  // listen here
+  "port": /* tcp */ 8080, // default
+  "hosts": [
+    "a", /* primary */
+    "b" // backup
+    // more later
+  ],
+  "empty": {
+    // nothing yet
+  }
+} // end`
+	lex := lexer.NewLexer(input)
+	lex.SetOptions(lexer.Options{AllowComments: true, KeepComments: true})
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	p := NewParser(tokens)
+	p.SetComments(lex.Comments())
+	value, err := p.ParseDocument()
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+
+	obj := value.(*ast.Object)
+	hosts := obj.Pairs["hosts"].(*ast.Array)
+	tests := []struct {
+		name     string
+		node     ast.Value
+		expected *ast.Comments
+	}{
+		{"root", obj, &ast.Comments{Leading: []string{"// config"}, Trailing: []string{"// end"}}},
+		{"port", obj.Pairs["port"], &ast.Comments{Leading: []string{"// listen here", "/* tcp */"}, Trailing: []string{"// default"}}},
+		{"hosts", hosts, &ast.Comments{Dangling: []string{"// more later"}}},
+		{"first host", hosts.Elements[0], &ast.Comments{Trailing: []string{"/* primary */"}}},
+		{"second host", hosts.Elements[1], &ast.Comments{Trailing: []string{"// backup"}}},
+		{"empty", obj.Pairs["empty"], &ast.Comments{Dangling: []string{"// nothing yet"}}},
+	}
+	for _, tt := range tests {
+		if got := ast.CommentsOf(tt.node); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected comments %+v, got %+v", tt.name, tt.expected, got)
+		}
+	}
+
+	if value, err := ParseValue(tokens); err != nil || ast.CommentsOf(value) != nil {
+		t.Errorf("expected no comments unless SetComments is called, got %v", err)
+	}
+}
+
+func TestParser_Recover(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string   // compact JSON of what was recovered, empty for nothing
+		errors []string // start of each error message, in order
+	}{
+		{`{"a": tru, "b": 2}`, `{"b":2}`, []string{"Lexer error at line 1, column 7: invalid literal tru"}},
+		{`{"a": 1 "b": 2}`, `{"a":1,"b":2}`, []string{`Parser error at line 1, column 9: expected ,`}},
+		{`[1 2, @, {"k" 1}, 3`, `[1,2,{},3]`, []string{
+			"Parser error at line 1, column 4: expected ,",
+			"Lexer error at line 1, column 7: unexpected character",
+			"Parser error at line 1, column 15: expected :",
+			"Parser error at line 1, column 20: expected ], got end of input",
+		}},
+		{`{"a": 1, "a": 2, "b": }`, `{"a":2}`, []string{
+			`Parser error at line 1, column 10: duplicate key "a"`,
+			"Parser error at line 1, column 23: expected a valid value",
+		}},
+		{`[1, 2]] "x`, `[1,2]`, []string{
+			"Parser error at line 1, column 7: expected EOF",
+			"Lexer error at line 1, column 9: unterminated string literal",
+		}},
+		{`tru`, ``, []string{"Lexer error at line 1, column 1: invalid literal tru"}},
+		{`{"a": [1, 2]}`, `{"a":[1,2]}`, nil},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.NewLexer(tt.input)
+		lex.SetOptions(lexer.Options{Recover: true})
+		tokens, err := lex.Tokenize()
+		if err != nil {
+			t.Fatalf("%s: Lexer error: %v", tt.input, err)
+		}
+		p := NewParser(tokens)
+		p.SetOptions(Options{Recover: true, RejectDuplicateKeys: true})
+		value, err := p.ParseDocument()
+
+		got := ""
+		if value != nil {
+			data, _ := ast.AppendJSON(nil, value)
+			got = string(data)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s to be recovered, got %s", tt.input, tt.want, got)
+		}
+		if tt.errors == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.input, err)
+			}
+			continue
+		}
+		var list lexer.ErrorList
+		if !errors.As(err, &list) || len(list) != len(tt.errors) {
+			t.Errorf("%s: expected %d errors, got %v", tt.input, len(tt.errors), err)
+			continue
+		}
+		for i, want := range tt.errors {
+			if !strings.HasPrefix(list[i].Error(), want) {
+				t.Errorf("%s: expected error %d to start %q, got %q", tt.input, i, want, list[i])
+			}
+		}
+	}
+}
+
+func TestParser_BadValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // the tree with ? for each bad value
+		bad   string // source text of the first bad value
+		err   string // start of its error
+	}{
+		{`{"a": tru, "b": 2}`, `{"a":?,"b":2}`, `tru`, "Lexer error at line 1, column 7: invalid literal tru"},
+		{`[1, @ x, 3]`, `[1,?,3]`, `@ x`, "Lexer error at line 1, column 5: unexpected character"},
+		{`{"k" 1}`, `{"k":?}`, `1`, "Parser error at line 1, column 6: expected :"},
+		{`[1,,2]`, `[1,?,2]`, ``, "Parser error at line 1, column 4: expected a valid value"},
+		{`[[1, ], 2]`, `[[1,?],2]`, ``, "Parser error at line 1, column 6: expected a valid value"},
+		{`tru`, `?`, `tru`, "Lexer error at line 1, column 1: invalid literal tru"},
+		{`[1 2]`, `[1,2]`, ``, ""},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.NewLexer(tt.input)
+		lex.SetOptions(lexer.Options{Recover: true})
+		tokens, _ := lex.Tokenize()
+		p := NewParser(tokens)
+		p.SetOptions(Options{Recover: true, BadValues: true})
+		value, err := p.ParseDocument()
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.input)
+		}
+
+		var first *ast.BadValue
+		var outline func(v ast.Value) string
+		outline = func(v ast.Value) string {
+			switch n := v.(type) {
+			case *ast.BadValue:
+				if first == nil {
+					first = n
+				}
+				return "?"
+			case *ast.Object:
+				parts := []string{}
+				for _, key := range n.OrderedKeys() {
+					parts = append(parts, strconv.Quote(key)+":"+outline(n.Pairs[key]))
+				}
+				return "{" + strings.Join(parts, ",") + "}"
+			case *ast.Array:
+				parts := []string{}
+				for _, element := range n.Elements {
+					parts = append(parts, outline(element))
+				}
+				return "[" + strings.Join(parts, ",") + "]"
+			}
+			data, _ := ast.AppendJSON(nil, v)
+			return string(data)
+		}
+		if got := outline(value); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.want, got)
+		}
+		if tt.err == "" {
+			continue
+		}
+		if first == nil {
+			t.Errorf("%s: expected a bad value", tt.input)
+			continue
+		}
+		if got := tt.input[first.Span.Start.Offset:first.Span.End.Offset]; got != tt.bad {
+			t.Errorf("%s: expected the bad value to span %q, got %q", tt.input, tt.bad, got)
+		}
+		if !strings.HasPrefix(first.Err.Error(), tt.err) {
+			t.Errorf("%s: expected the bad value to hold %q, got %q", tt.input, tt.err, first.Err)
+		}
+	}
+}
+
+func TestParser_RecoverMaxErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		max    int
+		errors int  // errors kept before the limit
+		limit  bool // whether the limit was hit
+	}{
+		{`[x, x, x, x]`, 2, 2, true},
+		{`[x, x]`, 2, 2, false},
+		{`[1 2 3 4]`, 1, 1, true},
+		{`{"a": 1, "a": 2, "a": 3}`, 1, 1, true},
+		{`[1] x x`, 1, 1, true},
+		{`[x, x, x]`, 0, 3, false},
+	}
+	for _, tt := range tests {
+		lex := lexer.NewLexer(tt.input)
+		lex.SetOptions(lexer.Options{Recover: true})
+		tokens, _ := lex.Tokenize()
+		p := NewParser(tokens)
+		p.SetOptions(Options{Recover: true, RejectDuplicateKeys: true, MaxErrors: tt.max})
+		_, err := p.ParseDocument()
+
+		var list lexer.ErrorList
+		if !errors.As(err, &list) {
+			t.Errorf("%s: expected an error list, got %v", tt.input, err)
+			continue
+		}
+		var limitErr *LimitError
+		hit := errors.As(list[len(list)-1], &limitErr) && limitErr.Limit == LimitErrors
+		kept := len(list)
+		if hit {
+			kept--
+		}
+		if kept != tt.errors || hit != tt.limit {
+			t.Errorf("%s: expected %d errors and limit %t, got %v", tt.input, tt.errors, tt.limit, err)
+		}
+		if hit && limitErr.Max != tt.max {
+			t.Errorf("%s: expected the limit to record %d, got %d", tt.input, tt.max, limitErr.Max)
+		}
+	}
+}
+
+func TestParser_RecoverStopsAtLimits(t *testing.T) {
+	lex := lexer.NewLexer(`[x, [[1]]]`)
+	lex.SetOptions(lexer.Options{Recover: true})
+	tokens, _ := lex.Tokenize()
+	p := NewParser(tokens)
+	p.SetOptions(Options{Recover: true, MaxDepth: 2})
+	value, err := p.ParseDocument()
+
+	var list lexer.ErrorList
+	var limitErr *LimitError
+	if value != nil || !errors.As(err, &list) || len(list) != 2 || !errors.As(list[1], &limitErr) {
+		t.Errorf("expected the syntax error followed by the limit error, got %v, %v", value, err)
+	}
+}
+
+func TestParser_RecoverUnclosedDeepDocument(t *testing.T) {
+	// every level fails at the end of input, where only the first error is
+	// kept; the others must not each format the whole path
+	const depth = 50000
+	tokens, err := lexer.NewLexer(strings.Repeat("[", depth)).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser(tokens)
+	p.SetOptions(Options{Recover: true, MaxDepth: -1})
+	_, err = p.ParseDocument()
+
+	var list lexer.ErrorList
+	var unexpected *lexer.UnexpectedTokenError
+	if !errors.As(err, &list) || len(list) != 1 || !errors.As(list[0], &unexpected) {
+		t.Fatalf("expected one unexpected token error, got %v", err)
+	}
+	if want := "$" + strings.Repeat("[0]", depth); unexpected.Path != want {
+		t.Errorf("expected the path inside the innermost array, got one of %d bytes", len(unexpected.Path))
+	}
+}
+
+func TestParser_IdentifierKeys(t *testing.T) {
+	// A dialect hook scanning bare words as identifiers
+	word := func(s lexer.Scanner) (lexer.Token, bool, error) {
+		var name []rune
+		for c := s.Peek(0); c >= 'a' && c <= 'z'; c = s.Peek(0) {
+			name = append(name, c)
+			s.Advance(1)
+		}
+		if len(name) == 0 {
+			return lexer.Token{}, false, nil
+		}
+		return lexer.Token{Type: lexer.TokenIdentifier, Literal: string(name)}, true, nil
+	}
+	scan := func(input string) *lexer.Lexer {
+		lex := lexer.NewLexer(input)
+		lex.SetOptions(lexer.Options{Hooks: []lexer.TokenHook{word}})
+		return lex
+	}
+
+	tokens, err := scan(`{name: 1, "quoted": {inner: 2}}`).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := v.(*ast.Object).String(); got != `{"name":1,"quoted":{"inner":2}}` {
+		t.Errorf("got %s", got)
+	}
+	if err := Validate(scan(`{name: 1, "quoted": {inner: 2}}`), Options{}); err != nil {
+		t.Errorf("Validate: unexpected error: %v", err)
+	}
+
+	// An identifier is not a value without a value hook
+	tokens, err = scan(`{name: value}`).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseValue(tokens); err == nil {
+		t.Error("expected an identifier value to be rejected")
+	}
+	if err := Validate(scan(`{name: value}`), Options{}); err == nil {
+		t.Error("Validate: expected an identifier value to be rejected")
+	}
+}
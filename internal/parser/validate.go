@@ -0,0 +1,185 @@
+package parser
+
+import "github.com/letsmakecakes/jsonparser/internal/lexer"
+
+// validator checks the grammar one token at a time, keeping only the current token
+type validator struct {
+	lex   *lexer.Lexer
+	tok   lexer.Token
+	prev  lexer.TokenType // type of the token before tok
+	depth int
+	opts  Options
+	path  []pathSegment // members and elements from the root, keys kept as tokens
+}
+
+// Validate checks that the lexer's input holds exactly one JSON value.
+// It accepts exactly the documents a Parser with the same options accepts,
+// without building a token slice or AST nodes. Value hooks need a Parser, so
+// opts.Values is ignored and dialect values are rejected.
+func Validate(lex *lexer.Lexer, opts Options) error {
+	v := &validator{lex: lex, opts: opts}
+	if err := v.next(); err != nil {
+		return err
+	}
+	if err := v.value(); err != nil {
+		return err
+	}
+	if v.tok.Type != lexer.TokenEOF {
+		return v.unexpected(v.tok, lexer.TokenEOF)
+	}
+	return nil
+}
+
+func (v *validator) next() error {
+	next := v.lex.SkipToken
+	if v.opts.RejectDuplicateKeys {
+		next = v.lex.NextToken // keys are needed to spot duplicates
+	}
+	tok, err := next()
+	if err != nil {
+		return err
+	}
+	v.prev, v.tok = v.tok.Type, tok
+	return nil
+}
+
+// expect checks the current token's type and moves past it
+func (v *validator) expect(tokenType lexer.TokenType) error {
+	if v.tok.Type != tokenType {
+		return v.unexpected(v.tok, tokenType)
+	}
+	return v.next()
+}
+
+func (v *validator) value() error {
+	switch v.tok.Type {
+	case lexer.TokenString, lexer.TokenNumber, lexer.TokenTrue, lexer.TokenFalse, lexer.TokenNull:
+		return v.next()
+	case lexer.TokenLeftBrace:
+		return v.object()
+	case lexer.TokenLeftBracket:
+		return v.array()
+	default:
+		return v.unexpected(v.tok, "a valid value")
+	}
+}
+
+func (v *validator) object() error {
+	v.depth++
+	defer func() { v.depth-- }()
+	if err := checkDepth(v.tok, v.depth, v.opts.MaxDepth, v); err != nil {
+		return err
+	}
+	if err := v.next(); err != nil { // skip the opening brace
+		return err
+	}
+
+	// Handle empty object case
+	if v.tok.Type == lexer.TokenRightBrace {
+		return v.next()
+	}
+
+	var seen map[string]bool
+	if v.opts.RejectDuplicateKeys {
+		seen = make(map[string]bool)
+	}
+
+	for members := 1; ; members++ {
+		if v.opts.AllowTrailingCommas && v.tok.Type == lexer.TokenRightBrace {
+			break
+		}
+		isKey := v.tok.Type == lexer.TokenString || v.tok.Type == lexer.TokenIdentifier
+		if isKey {
+			if err := checkCount(v.tok, LimitObjectKeys, members, v.opts.MaxObjectKeys, v); err != nil {
+				return err
+			}
+		}
+		if seen != nil && isKey {
+			if seen[v.tok.Literal] {
+				return newDuplicateKeyError(v.tok, v.where())
+			}
+			seen[v.tok.Literal] = true
+		}
+		key := v.tok
+		if !isKey {
+			return v.unexpected(v.tok, lexer.TokenString)
+		}
+		if err := v.next(); err != nil {
+			return err
+		}
+		v.path = append(v.path, pathSegment{index: -1, token: key})
+		if err := v.expect(lexer.TokenColon); err != nil {
+			return err
+		}
+		if err := v.value(); err != nil {
+			return err
+		}
+		v.path = v.path[:len(v.path)-1]
+
+		if v.tok.Type != lexer.TokenComma {
+			break
+		}
+		if err := v.next(); err != nil { // skip the comma, a key must follow
+			return err
+		}
+	}
+
+	return v.expect(lexer.TokenRightBrace)
+}
+
+func (v *validator) array() error {
+	v.depth++
+	defer func() { v.depth-- }()
+	if err := checkDepth(v.tok, v.depth, v.opts.MaxDepth, v); err != nil {
+		return err
+	}
+	if err := v.next(); err != nil { // skip the opening bracket
+		return err
+	}
+
+	// Handle empty array case
+	if v.tok.Type == lexer.TokenRightBracket {
+		return v.next()
+	}
+
+	for elements := 1; ; elements++ {
+		if v.opts.AllowTrailingCommas && v.tok.Type == lexer.TokenRightBracket {
+			break
+		}
+		if err := checkCount(v.tok, LimitArrayElements, elements, v.opts.MaxArrayElements, v); err != nil {
+			return err
+		}
+		v.path = append(v.path, pathSegment{index: elements - 1})
+		if err := v.value(); err != nil {
+			return err
+		}
+		v.path = v.path[:len(v.path)-1]
+
+		if v.tok.Type != lexer.TokenComma {
+			break
+		}
+		if err := v.next(); err != nil { // skip the comma, a value must follow
+			return err
+		}
+	}
+
+	return v.expect(lexer.TokenRightBracket)
+}
+
+// where returns the path of the value being checked, "" for the root. Keys
+// scanned without their literal are read back from the lexer.
+func (v *validator) where() string {
+	return formatPath(v.path, func(s pathSegment) string {
+		if s.token.Literal != "" {
+			return s.token.Literal
+		}
+		key, _ := v.lex.Literal(s.token)
+		return key
+	})
+}
+
+// unexpected reports tok, found where expected was wanted, at the current path
+func (v *validator) unexpected(tok lexer.Token, expected lexer.TokenType) error {
+	code, hint := hintFor(v.prev, tok, expected)
+	return &lexer.UnexpectedTokenError{Token: tok, Expected: expected, Path: v.where(), Code: code, Hint: hint}
+}
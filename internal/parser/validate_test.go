@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+func TestValidate_AgreesWithParseValue(t *testing.T) {
+	inputs := []string{
+		`{}`,
+		`[]`,
+		`"text"`,
+		`-12.5e3`,
+		`{"a": [1, {"b": null}], "c": true, "d": "é😀"}`,
+		`[1, 2, [3, [4, []]]]`,
+		`{"a": 1,}`,
+		`[1,]`,
+		`[1 2]`,
+		`{"a" 1}`,
+		`{1: 2}`,
+		`{"a": 1} {}`,
+		`[`,
+		`{"a":`,
+		`]`,
+		``,
+		`01`,
+		`1e400`,
+		`"unterminated`,
+		`"bad \x escape"`,
+		`tru`,
+	}
+
+	matches, err := filepath.Glob(filepath.Join("..", "..", "tests", "step*", "*.json"))
+	if err != nil {
+		t.Fatalf("listing fixtures: %v", err)
+	}
+	for _, file := range matches {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		inputs = append(inputs, string(data))
+	}
+
+	for _, input := range inputs {
+		tokens, parseErr := lexer.NewLexer(input).Tokenize()
+		if parseErr == nil {
+			_, parseErr = ParseValue(tokens)
+		}
+		validateErr := Validate(lexer.NewLexer(input), Options{})
+
+		if (parseErr == nil) != (validateErr == nil) {
+			t.Errorf("%q: ParseValue returned %v but Validate returned %v", input, parseErr, validateErr)
+		}
+	}
+}
+
+func TestValidate_ReportsPosition(t *testing.T) {
+	err := Validate(lexer.NewLexer("{\n  \"a\": [1, 2,]\n}"), Options{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := "Parser error at line 2, column 14: expected a valid value, got ] \"]\" at $.a[2]; remove the trailing comma"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,268 @@
+// Package jsonparser is the public entry point to the hand-written JSON lexer and parser.
+//
+// It parses JSON text into a tree of AST nodes. The node types are aliases of
+// the internal AST package, so values returned here can be used directly with
+// type switches on *Object, *Array, *String, *Number, *Boolean and *Null.
+package jsonparser
+
+import (
+	"context"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// AST node types
+type (
+	Value   = ast.Value
+	Object  = ast.Object
+	Array   = ast.Array
+	String  = ast.String
+	Number  = ast.Number
+	Boolean = ast.Boolean
+	Null    = ast.Null
+)
+
+// BadValue stands in the tree where Parse WithBadValues skipped input it
+// could not parse, holding the error and the span of the skipped text
+type BadValue = ast.BadValue
+
+// Pos is a byte offset, line and column in the source text
+type Pos = ast.Pos
+
+// Span is the source text a node was parsed from, recorded with WithSpans
+type Span = ast.Span
+
+// SpanOf returns the span of node, which is zero unless it was parsed with WithSpans
+func SpanOf(node Value) Span {
+	return ast.SpanOf(node)
+}
+
+// Comments are the comments around a node in the source text, kept with
+// WithKeepComments
+type Comments = ast.Comments
+
+// CommentsOf returns the comments around node, or nil when it has none
+func CommentsOf(node Value) *Comments {
+	return ast.CommentsOf(node)
+}
+
+// Clone returns a deep copy of node that can be modified without affecting
+// node; each node type also has a Clone method returning its own type
+func Clone(node Value) Value {
+	return ast.Clone(node)
+}
+
+// EqualOptions relaxes the comparison made by Equal; see ast.EqualOptions
+type EqualOptions = ast.EqualOptions
+
+// Equal reports whether a and b are the same document, with the same object
+// keys in the same order and identical number literals
+func Equal(a, b Value) bool {
+	return ast.Equal(a, b)
+}
+
+// Link sets the Parent of every node below root, as WithParents does while
+// parsing. Call it again after editing the tree.
+func Link(root Value) {
+	ast.Link(root)
+}
+
+// PathOf returns the JSON Pointer of node within its linked document
+func PathOf(node Value) string {
+	return ast.PathOf(node)
+}
+
+// ErrFrozen is returned, or panicked with by Object.Set and Object.Delete,
+// when a frozen array or object is changed
+var ErrFrozen = ast.ErrFrozen
+
+// Freeze makes root and every array and object below it read-only, so a
+// document parsed once can be shared between goroutines. Editing methods
+// then fail with ErrFrozen; Clone returns a copy that can be changed.
+func Freeze(root Value) {
+	ast.Freeze(root)
+}
+
+// IsFrozen reports whether node is an array or object made read-only by Freeze
+func IsFrozen(node Value) bool {
+	return ast.IsFrozen(node)
+}
+
+// SiblingOf returns the value beside node in its linked parent: a member by
+// name, or an element by index, counting from the end when negative
+func SiblingOf(node Value, key string) (Value, bool) {
+	return ast.SiblingOf(node, key)
+}
+
+// ToInterface converts node into plain Go data: map[string]interface{},
+// []interface{}, float64, string, bool or nil, as encoding/json decodes
+// into interface{}
+func ToInterface(node Value) (interface{}, error) {
+	return ast.ToInterface(node)
+}
+
+// Visitor is called by Walk for every node; see ast.Visitor
+type Visitor = ast.Visitor
+
+// Walk traverses the tree rooted at node depth-first, calling v.Visit for
+// every node and v.Visit(nil) after the children of a node it descended
+// into. Array elements and object values are visited in order.
+func Walk(v Visitor, node Value) {
+	ast.Walk(v, node)
+}
+
+// Inspect traverses the tree rooted at node like Walk, calling f for every
+// node and descending into its children only when f returns true
+func Inspect(node Value, f func(Value) bool) {
+	ast.Inspect(node, f)
+}
+
+// Lookup returns the value at a JSON Pointer path such as "/servers/0/host"
+func Lookup(root Value, path string) (Value, error) {
+	return ast.Lookup(root, path)
+}
+
+// Cursor is a value found in a document with its JSON Pointer, source span
+// and parent; its Set and Delete methods edit the document in place
+type Cursor = ast.Cursor
+
+// Cursors is a set of query results; its Set and Delete methods apply to
+// every match at once
+type Cursors = ast.Cursors
+
+// Find returns a cursor for every value in root, at any depth, for which
+// match reports true, in document order
+func Find(root Value, match func(Value) bool) Cursors {
+	return ast.Find(root, match)
+}
+
+// Query returns a cursor for every value matching an RFC 9535 JSONPath
+// expression such as "$.items[?@.qty > 1].currency"
+func Query(root Value, path string) (Cursors, error) {
+	return ast.Query(root, path)
+}
+
+// CompiledQuery is a JSONPath expression parsed once, safe to run against
+// many documents from many goroutines
+type CompiledQuery = ast.CompiledQuery
+
+// Compile parses a JSONPath expression for repeated use
+func Compile(path string) (*CompiledQuery, error) {
+	return ast.Compile(path)
+}
+
+// MustCompile is like Compile but panics when path is invalid
+func MustCompile(path string) *CompiledQuery {
+	return ast.MustCompile(path)
+}
+
+// Index speeds up repeated queries on one document, such as $..price, with
+// tables built on first use; call Reset after changing the document
+type Index = ast.Index
+
+// NewIndex returns an index over the document at root
+func NewIndex(root Value) *Index {
+	return ast.NewIndex(root)
+}
+
+// CursorAt returns a cursor for the value at a JSON Pointer path
+func CursorAt(root Value, path string) (Cursor, error) {
+	return ast.CursorAt(root, path)
+}
+
+// ErrSyntax is matched by errors.Is for every error reporting malformed JSON
+var ErrSyntax = lexer.ErrSyntax
+
+// Errors describing malformed input. Use errors.As to get at their positions
+// and, for UnexpectedTokenError, the token found and the one expected.
+type (
+	SyntaxError             = lexer.SyntaxError
+	UnterminatedStringError = lexer.UnterminatedStringError
+	UnexpectedTokenError    = lexer.UnexpectedTokenError
+)
+
+// ErrorList is returned by Parse WithRecovery, holding every error found in
+// input order; errors.Is and errors.As look through all of them
+type ErrorList = lexer.ErrorList
+
+// DuplicateKeyError is returned for a key repeated within one object under
+// WithStrictMode
+type DuplicateKeyError = parser.DuplicateKeyError
+
+// Snippet is the source line a syntax error was found on. Parse, ParseBytes
+// and Validate attach one to their errors; printing an error with %+v shows
+// it below the message with a caret under the column.
+type Snippet = lexer.Snippet
+
+// ErrInternal is matched by errors.Is for every error caused by a bug in this package
+var ErrInternal = guard.ErrInternal
+
+// InternalError is returned in place of a panic inside the package.
+// It records the operation, the input position reached and the stack trace.
+type InternalError = guard.Error
+
+// Parse parses a JSON document of any root type. WithRecovery, a malformed
+// document yields both what could be parsed and an ErrorList.
+func Parse(input string, opts ...Option) (Value, error) {
+	return parse(context.Background(), lexer.NewLexer(input), newConfig(opts))
+}
+
+// ParseBytes parses a JSON document held in a byte slice without copying it to a string first.
+// The values returned hold copies of their text, so they never keep data alive.
+func ParseBytes(data []byte, opts ...Option) (Value, error) {
+	return parse(context.Background(), lexer.NewBytesLexer(data), newConfig(opts))
+}
+
+// ParseContext is like Parse but gives up with ctx.Err() once ctx is cancelled or its deadline passes
+func ParseContext(ctx context.Context, input string, opts ...Option) (Value, error) {
+	return parse(ctx, lexer.NewLexer(input), newConfig(opts))
+}
+
+// ParseBytesContext is like ParseBytes but gives up with ctx.Err() once ctx is cancelled or its deadline passes
+func ParseBytesContext(ctx context.Context, data []byte, opts ...Option) (Value, error) {
+	return parse(ctx, lexer.NewBytesLexer(data), newConfig(opts))
+}
+
+// MustParse is like Parse but panics when input is not valid JSON. It is
+// meant for documents known to be valid, such as literals in programs and tests.
+func MustParse(input string, opts ...Option) Value {
+	v, err := Parse(input, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// parse reads a single document from lex
+func parse(ctx context.Context, lex *lexer.Lexer, config ParserConfig) (value Value, err error) {
+	if config.StableErrors {
+		defer func() {
+			if err != nil {
+				err = stabilize(err)
+			}
+		}()
+	}
+	var p *parser.Parser
+	defer guard.Recover("parse", &err, func() guard.Position {
+		if p != nil {
+			tok := p.Current()
+			return guard.Position{Offset: tok.Offset, Line: tok.Line, Column: tok.Column}
+		}
+		offset, line, column := lex.Position()
+		return guard.Position{Offset: offset, Line: line, Column: column}
+	})
+
+	lex.SetOptions(config.lexerOptions())
+	tokens, err := lex.TokenizeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p = parser.NewParser(tokens)
+	p.SetOptions(config.parserOptions())
+	p.SetComments(lex.Comments())
+	value, err = p.ParseDocumentContext(ctx)
+	return value, parser.WithSnippets(err, lex)
+}
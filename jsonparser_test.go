@@ -0,0 +1,282 @@
+package jsonparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/letsmakecakes/jsonparser/dialect"
+)
+
+func TestParse_Object(t *testing.T) {
+	value, err := Parse(`{"name": "John", "tags": ["a", "b"], "admin": false, "age": 30, "spouse": null}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	obj, ok := value.(*Object)
+	if !ok {
+		t.Fatalf("expected *Object, got %T", value)
+	}
+	if name, ok := obj.Pairs["name"].(*String); !ok || name.Value != "John" {
+		t.Errorf("expected name John, got %#v", obj.Pairs["name"])
+	}
+	if tags, ok := obj.Pairs["tags"].(*Array); !ok || len(tags.Elements) != 2 {
+		t.Errorf("expected two tags, got %#v", obj.Pairs["tags"])
+	}
+	if admin, ok := obj.Pairs["admin"].(*Boolean); !ok || admin.Value != "false" {
+		t.Errorf("expected admin false, got %#v", obj.Pairs["admin"])
+	}
+	if age, ok := obj.Pairs["age"].(*Number); !ok || age.Value != "30" {
+		t.Errorf("expected age 30, got %#v", obj.Pairs["age"])
+	}
+	if _, ok := obj.Pairs["spouse"].(*Null); !ok {
+		t.Errorf("expected spouse null, got %#v", obj.Pairs["spouse"])
+	}
+}
+
+func TestParse_ScalarRoot(t *testing.T) {
+	value, err := Parse(`"just a string"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s, ok := value.(*String); !ok || s.Value != "just a string" {
+		t.Errorf("expected string root, got %#v", value)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	inputs := []string{``, `{"a": 1,}`, `[1 2]`, `{"a" 1}`, `tru`, `{} []`}
+	for _, input := range inputs {
+		if _, err := Parse(input); !errors.Is(err, ErrSyntax) {
+			t.Errorf("expected a syntax error for %q, got %v", input, err)
+		}
+	}
+
+	_, err := Parse(`{"a": "open`)
+	var unterminated *UnterminatedStringError
+	if !errors.As(err, &unterminated) || unterminated.Column != 7 {
+		t.Errorf("expected an unterminated string at column 7, got %v", err)
+	}
+	_, err = Parse(`{"items": [{"price": 1}, {"price": 2,}]}`)
+	var unexpected *UnexpectedTokenError
+	if !errors.As(err, &unexpected) || unexpected.Path != "$.items[1]" || !strings.Contains(err.Error(), " at $.items[1]") {
+		t.Errorf("expected the error located at $.items[1], got %v", err)
+	}
+	_, err = Parse(`{'a': True}`)
+	if want := `Lexer error at line 1, column 2: strings must be in double quotes; did you mean "a"?`; err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+	_, err = Parse(`{"a": 1, "a": 2}`, WithStrictMode(true))
+	var duplicate *DuplicateKeyError
+	if !errors.As(err, &duplicate) || duplicate.Key != "a" {
+		t.Errorf("expected a duplicate key error, got %v", err)
+	}
+}
+
+func TestParse_ErrorSnippets(t *testing.T) {
+	input := "{\n  \"a\": 1,\n  \"b\" 2\n}"
+	want := "\n3 |   \"b\" 2\n  |       ^"
+	_, parseErr := Parse(input)
+	validateErr := Validate([]byte(input))
+	for _, err := range []error{parseErr, validateErr} {
+		if got := fmt.Sprintf("%+v", err); got != err.Error()+want {
+			t.Errorf("expected the message and%s\ngot\n%s", want, got)
+		}
+	}
+
+	_, err := Parse("[1, 2,\n 3 4, @]", WithRecovery(true))
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("expected two errors, got %v", err)
+	}
+	for _, err := range list {
+		var unexpected *UnexpectedTokenError
+		var syntaxErr *SyntaxError
+		if errors.As(err, &unexpected) && unexpected.Snippet == nil || errors.As(err, &syntaxErr) && syntaxErr.Snippet == nil {
+			t.Errorf("expected a snippet for %v", err)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	value, err := ParseBytes([]byte(`[1, 2, 3]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if arr, ok := value.(*Array); !ok || len(arr.Elements) != 3 {
+		t.Errorf("expected three elements, got %#v", value)
+	}
+}
+
+func TestParseBytes_DoesNotCopyInput(t *testing.T) {
+	data := []byte(`{"payload": "` + strings.Repeat("x", 64<<10) + `"}`)
+
+	var stats runtime.MemStats
+	measure := func(parse func()) int64 {
+		runtime.ReadMemStats(&stats)
+		before := stats.TotalAlloc
+		parse()
+		runtime.ReadMemStats(&stats)
+		return int64(stats.TotalAlloc - before)
+	}
+	viaString := measure(func() { Parse(string(data)) })
+	viaBytes := measure(func() { ParseBytes(data) })
+
+	// Parse copies the input twice: once into a string and once into the lexer
+	if viaString-viaBytes < int64(len(data)) {
+		t.Errorf("expected ParseBytes to avoid copying the %d byte input, allocated %d bytes vs %d for Parse", len(data), viaBytes, viaString)
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	input := `{"items": [` + strings.Repeat(`{"id": 1, "tags": ["a", "b"]},`, 10000) + `{}]}`
+
+	if _, err := ParseContext(context.Background(), input); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(ctx, input); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := ParseBytesContext(ctx, []byte(input)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if v := MustParse(`[1, 2]`); len(v.(*Array).Elements) != 2 {
+		t.Errorf("MustParse returned %v", v)
+	}
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, ErrSyntax) {
+			t.Errorf("expected a panic with a syntax error, got %v", err)
+		}
+	}()
+	MustParse(`[1,`)
+}
+
+func TestParse_RecoversPanics(t *testing.T) {
+	exploding := &dialect.Dialect{
+		Tokens: []dialect.TokenHook{func(s dialect.Scanner) (dialect.Token, bool, error) {
+			if s.Peek(0) != '@' {
+				return dialect.Token{}, false, nil
+			}
+			s.Advance(1)
+			return dialect.Token{Type: dialect.TokenIdentifier, Literal: "@"}, true, nil
+		}},
+		Values: map[dialect.TokenType]dialect.ValueHook{
+			dialect.TokenIdentifier: func(dialect.Parser) (Value, error) { panic("hook exploded") },
+		},
+	}
+
+	_, err := Parse(`[1, @]`, WithDialect(exploding))
+	var internal *InternalError
+	if !errors.As(err, &internal) || !errors.Is(err, ErrInternal) {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+	if pos := internal.Position; internal.Op != "parse" || pos.Offset != 4 || pos.Line != 1 || pos.Column != 5 {
+		t.Errorf("expected the parse op at offset 4 (line 1, column 5), got %q at %+v", internal.Op, pos)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, err := Parse(`{"id": 1, "tags": ["x"]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := Parse(`{"tags": ["x"], "id": 1.0}`, WithSpans(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if Equal(a, b) {
+		t.Errorf("expected key order and number format to matter by default")
+	}
+	if !(EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}).Equal(a, b) {
+		t.Errorf("expected the documents to be equal ignoring key order and number format")
+	}
+}
+
+func TestFind(t *testing.T) {
+	root, err := Parse(`{"a": "http://x", "b": ["https://y", "http://z"]}`, WithSpans(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := Find(root, func(node Value) bool {
+		s, ok := node.(*String)
+		return ok && strings.HasPrefix(s.Value, "http://")
+	})
+	if len(found) != 2 || found[1].Pointer != "/b/1" || found[1].Span.Start.Column != 38 {
+		t.Fatalf("unexpected results %+v", found)
+	}
+	for _, c := range found {
+		if err := c.Set(&String{Value: "https://" + strings.TrimPrefix(c.Value.(*String).Value, "http://")}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := root.(*Object).String(); got != `{"a":"https://x","b":["https://y","https://z"]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	root, err := Parse(`{"items": [{"currency": "USD"}, {"currency": "GBP"}, {"sku": "x"}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	matches, err := Query(root, "$.items[*].currency")
+	if err != nil || len(matches) != 2 {
+		t.Fatalf("expected two matches, got %v, %v", matches, err)
+	}
+	if err := matches.Set(&String{Value: "EUR"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := root.(*Object).String(); got != `{"items":[{"currency":"EUR"},{"currency":"EUR"},{"sku":"x"}]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+
+	matches, err = Query(root, "$..[?@.sku]")
+	if err == nil {
+		err = matches.Delete()
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := root.(*Object).String(); got != `{"items":[{"currency":"EUR"},{"currency":"EUR"}]}` {
+		t.Errorf("expected the item with a sku deleted, got %s", got)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	skus := MustCompile("$.items[?@.qty > 1].sku")
+	for _, input := range []string{`{"items": [{"sku": "a", "qty": 2}]}`, `{"items": [{"sku": "b", "qty": 1}]}`} {
+		root, err := Parse(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want, _ := Query(root, skus.String())
+		if got := skus.Query(root); len(got) != len(want) {
+			t.Errorf("%s: expected %d matches, got %d", input, len(want), len(got))
+		}
+	}
+	root, _ := Parse(`{"a": {"sku": "x"}, "b": [{"sku": "y"}]}`)
+	ix := NewIndex(root)
+	if got, err := ix.Query("$..sku"); err != nil || len(got) != 2 || len(skus.QueryIndex(ix)) != 0 {
+		t.Errorf("expected two skus from the index, got %v, %v", got, err)
+	}
+	if _, err := Compile("$.items[?@.qty >]"); err == nil {
+		t.Error("expected an error for an invalid path")
+	}
+}
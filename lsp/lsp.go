@@ -0,0 +1,172 @@
+// Package lsp describes parse errors as Language Server Protocol
+// diagnostics, so an editor integration can publish them as they are.
+package lsp
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+)
+
+// Source names this package in the diagnostics it returns
+const Source = "jsonparser"
+
+// Severity is how serious a diagnostic is
+type Severity int
+
+// Severities, with the values the protocol gives them
+const (
+	SeverityError       Severity = 1
+	SeverityWarning     Severity = 2
+	SeverityInformation Severity = 3
+	SeverityHint        Severity = 4
+)
+
+// Position is a place in a text document. Line and Character count from 0,
+// and Character counts UTF-16 code units, as the protocol does by default.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is the text between two positions, End being just past the last
+// character
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is one problem in a document, in the shape of the protocol's
+// Diagnostic. Code is the error's jsonparser.ErrorCode.
+type Diagnostic struct {
+	Range    Range    `json:"range"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// Diagnostics returns a diagnostic for err, or for each error of an
+// ErrorList, found while parsing text. Errors of other kinds, which carry no
+// position, are placed at the start of the document. It returns nil for a
+// nil error.
+func Diagnostics(text []byte, err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	var list jsonparser.ErrorList
+	if errors.As(err, &list) {
+		errs = list
+	}
+	d := &document{text: text, lines: []int{0}}
+	for i, b := range text {
+		if b == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	diagnostics := make([]Diagnostic, 0, len(errs))
+	for _, e := range errs {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.rangeOf(e),
+			Severity: SeverityError,
+			Code:     string(jsonparser.ErrorCodeOf(e)),
+			Source:   Source,
+			Message:  message(e),
+		})
+	}
+	return diagnostics
+}
+
+// message returns the error message without the line and column it starts
+// with, since the range gives them
+func message(err error) string {
+	msg := err.Error()
+	for _, prefix := range []string{"Lexer error at line ", "Parser error at line "} {
+		if strings.HasPrefix(msg, prefix) {
+			if _, rest, ok := strings.Cut(msg, ": "); ok {
+				return rest
+			}
+		}
+	}
+	return msg
+}
+
+// document is the text diagnostics are computed for
+type document struct {
+	text  []byte
+	lines []int // byte offset at which each line starts
+}
+
+// rangeOf returns the range of text err is about
+func (d *document) rangeOf(err error) Range {
+	offset, ok := jsonparser.ErrorOffset(err)
+	if !ok {
+		return Range{}
+	}
+	start := d.clamp(int(offset))
+	end := start
+	var (
+		unexpected   *jsonparser.UnexpectedTokenError
+		unterminated *jsonparser.UnterminatedStringError
+	)
+	switch {
+	case errors.As(err, &unexpected):
+		end = d.clamp(int(unexpected.Token.End))
+	case errors.As(err, &unterminated):
+		end = d.lineEnd(start)
+	default:
+		end = d.tokenEnd(start)
+	}
+	return Range{Start: d.position(start), End: d.position(max(start, end))}
+}
+
+// position converts a byte offset into a line and UTF-16 character
+func (d *document) position(offset int) Position {
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+	character := 0
+	for _, r := range string(d.text[d.lines[line]:offset]) {
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// next returns the offset just past the character at offset, which stays
+// at offset at a line break or the end of the text
+func (d *document) next(offset int) int {
+	if offset >= len(d.text) || d.text[offset] == '\n' || d.text[offset] == '\r' {
+		return offset
+	}
+	_, size := utf8.DecodeRune(d.text[offset:])
+	return offset + size
+}
+
+// lineEnd returns the offset of the line break ending the line offset is on
+func (d *document) lineEnd(offset int) int {
+	for offset < len(d.text) && d.text[offset] != '\n' && d.text[offset] != '\r' {
+		offset++
+	}
+	return offset
+}
+
+// tokenEnd returns the offset just past the token starting at offset, the
+// text a recovering lexer would skip when it is malformed, or past its first
+// character when no token starts there
+func (d *document) tokenEnd(offset int) int {
+	lex := lexer.NewBytesLexer(d.text[offset:])
+	lex.SetOptions(lexer.Options{Recover: true})
+	tok, err := lex.NextToken()
+	if err != nil || tok.Type == lexer.TokenEOF || tok.Offset != 0 {
+		return d.next(offset)
+	}
+	return offset + int(tok.End)
+}
+
+func (d *document) clamp(offset int) int {
+	return min(max(offset, 0), len(d.text))
+}
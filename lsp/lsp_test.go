@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []jsonparser.Option
+		want  []Diagnostic
+	}{
+		{"unexpected token", "{\n  \"a\": 1 \"b\": 2\n}", nil, []Diagnostic{
+			{Range{Position{1, 9}, Position{1, 12}}, SeverityError, "E_MISSING_COMMA", Source, `expected }, got STRING "b"; did you forget a comma?`},
+		}},
+		{"characters count UTF-16", `["😀é", @]`, nil, []Diagnostic{
+			{Range{Position{0, 8}, Position{0, 9}}, SeverityError, "E_UNEXPECTED_CHARACTER", Source, "unexpected character: '@'"},
+		}},
+		{"bad escape", `["ab\q"]`, nil, []Diagnostic{
+			{Range{Position{0, 1}, Position{0, 7}}, SeverityError, "E_BAD_ESCAPE", Source, `invalid escape character: '\q'`},
+		}},
+		{"unterminated string", "[\"open", nil, []Diagnostic{
+			{Range{Position{0, 1}, Position{0, 6}}, SeverityError, "E_UNTERMINATED_STRING", Source, "unterminated string literal"},
+		}},
+		{"control character", "[\"open\n]", nil, []Diagnostic{
+			{Range{Position{0, 6}, Position{0, 6}}, SeverityError, "E_CONTROL_CHARACTER", Source, `unescaped control character U+000A in string; write it as \n`},
+		}},
+		{"duplicate key", `{"key": 1, "key": 2}`, []jsonparser.Option{jsonparser.WithStrictMode(true)}, []Diagnostic{
+			{Range{Position{0, 11}, Position{0, 16}}, SeverityError, "E_DUPLICATE_KEY", Source, `duplicate key "key"`},
+		}},
+		{"end of input", `[1`, nil, []Diagnostic{
+			{Range{Position{0, 2}, Position{0, 2}}, SeverityError, "E_UNEXPECTED_EOF", Source, "expected ], got end of input"},
+		}},
+		{"every recovered error", "[tru,\n @]", []jsonparser.Option{jsonparser.WithRecovery(true)}, []Diagnostic{
+			{Range{Position{0, 1}, Position{0, 4}}, SeverityError, "E_INVALID_LITERAL", Source, "invalid literal tru; did you mean true?"},
+			{Range{Position{1, 1}, Position{1, 2}}, SeverityError, "E_UNEXPECTED_CHARACTER", Source, "unexpected character: '@'"},
+		}},
+	}
+	for _, tt := range tests {
+		_, err := jsonparser.Parse(tt.input, tt.opts...)
+		if got := Diagnostics([]byte(tt.input), err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+
+	if got := Diagnostics(nil, nil); got != nil {
+		t.Errorf("expected no diagnostics for no error, got %+v", got)
+	}
+	other := Diagnostics([]byte(`{}`), errors.New("disk full"))
+	if len(other) != 1 || other[0].Range != (Range{}) || other[0].Message != "disk full" || other[0].Code != "" {
+		t.Errorf("expected an error without a position at the start, got %+v", other)
+	}
+}
+
+func TestDiagnostic_JSON(t *testing.T) {
+	d := Diagnostic{Range{Position{1, 2}, Position{1, 3}}, SeverityError, "E_BAD_NUMBER", Source, "bad"}
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"range":{"start":{"line":1,"character":2},"end":{"line":1,"character":3}},"severity":1,"code":"E_BAD_NUMBER","source":"jsonparser","message":"bad"}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+}
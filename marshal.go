@@ -0,0 +1,103 @@
+package jsonparser
+
+import (
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+)
+
+// FloatFormat controls the text produced for floating point numbers.
+// The zero value writes the shortest text that parses back to the same float64.
+type FloatFormat = encoder.FloatFormat
+
+// FloatMode selects how many digits a float is written with
+type FloatMode = encoder.FloatMode
+
+// Float modes
+const (
+	FloatShortest = encoder.FloatShortest // Fewest digits that round-trip
+	FloatFixed    = encoder.FloatFixed    // Exactly FloatFormat.Precision digits after the decimal point
+)
+
+// Charset selects the characters Marshal writes, for sinks that cannot take
+// raw UTF-8
+type Charset = encoder.Charset
+
+// Charsets
+const (
+	CharsetUTF8   = encoder.CharsetUTF8   // UTF-8, the default
+	CharsetASCII  = encoder.CharsetASCII  // ASCII, escaping other characters as \uXXXX in UTF-16, with surrogate pairs above U+FFFF
+	CharsetLatin1 = encoder.CharsetLatin1 // ISO-8859-1 bytes, escaping characters above U+00FF as CharsetASCII does
+)
+
+// MarshalOptions configures how Marshal writes values
+type MarshalOptions struct {
+	Float FloatFormat // Formatting of float32 and float64 values
+	// KeyDictionary writes {"dict":[...],"body":...}, where body is the value
+	// with repeated object keys replaced by "~N" references into dict
+	KeyDictionary bool
+	// Canonical writes the JSON Canonicalization Scheme of RFC 8785, so the
+	// same value always gives the same bytes to hash or sign: object keys
+	// sorted, no whitespace, and every number written as the shortest text
+	// of the nearest double, which rounds integers beyond 2^53
+	Canonical bool
+	// Charset is the character set of the output. Strings are the only part
+	// of JSON text that can hold other than ASCII, so only they change. It
+	// is applied last, so Canonical output stays canonical only in UTF-8.
+	Charset Charset
+	// BOM starts the output with a UTF-8 byte order mark, which some
+	// Windows tools expect; LineEncoder and SequenceEncoder write it once,
+	// before the first document
+	BOM bool
+}
+
+// utf8BOM is the byte order mark written by the BOM options
+const utf8BOM = "\xef\xbb\xbf"
+
+// LineEnding selects the bytes that end each line of output
+type LineEnding int
+
+// Line endings
+const (
+	LF   LineEnding = iota // "\n", the default
+	CRLF                   // "\r\n", as Windows tools expect
+)
+
+// String returns the bytes of the line ending
+func (l LineEnding) String() string {
+	if l == CRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Marshal returns the JSON encoding of v.
+//
+// Structs are written as objects using their `json` tags (supporting "-" and
+// omitempty), maps with string or integer keys are written with sorted keys,
+// []byte is written as a base64 string, and types implementing
+// MarshalJSON() ([]byte, error) encode themselves. Parsed AST values are
+// written back with their number literals unchanged, so big integers
+// survive a Parse and Marshal round trip byte for byte.
+func Marshal(v interface{}) ([]byte, error) {
+	return MarshalOptions{}.Marshal(v)
+}
+
+// MustMarshal is like Marshal but panics when v cannot be encoded, for
+// fixtures and values known to encode
+func MustMarshal(v interface{}) []byte {
+	data, err := Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// Marshal returns the JSON encoding of v using the options
+func (o MarshalOptions) Marshal(v interface{}) (data []byte, err error) {
+	defer guard.Recover("marshal", &err, nil)
+	data, err = encoder.Options{Float: o.Float, KeyDictionary: o.KeyDictionary, Canonical: o.Canonical, Charset: o.Charset}.Marshal(v)
+	if err != nil || !o.BOM {
+		return data, err
+	}
+	return append([]byte(utf8BOM), data...), nil
+}
@@ -0,0 +1,183 @@
+package jsonparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMarshal_Struct(t *testing.T) {
+	type server struct {
+		Host    string            `json:"host"`
+		Port    int               `json:"port"`
+		Weight  float64           `json:"weight"`
+		Tags    []string          `json:"tags,omitempty"`
+		Labels  map[string]string `json:"labels"`
+		Backup  *server           `json:"backup,omitempty"`
+		Secret  string            `json:"-"`
+		Enabled bool
+	}
+
+	v := server{Host: "a", Port: 80, Weight: 0.5, Labels: map[string]string{"z": "1", "a": "2"}, Secret: "x", Enabled: true}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"host":"a","port":80,"weight":0.5,"labels":{"a":"2","z":"1"},"Enabled":true}`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestMarshal_RoundTripsBigIntegers(t *testing.T) {
+	input := `{"id":123456789012345678901234567890,"ratio":1.50,"list":[1e300,-0]}`
+	value, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := Marshal(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"id":123456789012345678901234567890,"ratio":1.50,"list":[1e300,-0]}`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestMarshal_RoundTripsNumbersBeyondFloat64(t *testing.T) {
+	for _, input := range []string{`[1e400,-1E+999]`, "[" + strings.Repeat("9", 400) + "]"} {
+		if !Valid([]byte(input)) {
+			t.Errorf("Valid(%.20s...) = false, want numbers out of float64 range accepted", input)
+		}
+		got, err := Marshal(MustParse(input))
+		if err != nil || string(got) != input {
+			t.Errorf("expected %.20s... to round-trip, got %.20s..., %v", input, got, err)
+		}
+	}
+}
+
+func TestMarshalOptions_Float(t *testing.T) {
+	opts := MarshalOptions{Float: FloatFormat{Mode: FloatFixed, Precision: 2}}
+	got, err := opts.Marshal([]float64{1, 2.345})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != `[1.00,2.35]` {
+		t.Errorf("expected [1.00,2.35], got %s", got)
+	}
+}
+
+func TestMarshal_NonFinite(t *testing.T) {
+	input := `{"a":NaN,"b":[Infinity,-Infinity]}`
+	value, err := Parse(input, WithAllowNonFinite(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := Marshal(value); err != nil || string(got) != input {
+		t.Errorf("expected %s, got %s, %v", input, got, err)
+	}
+
+	var decoded struct {
+		A float64   `json:"a"`
+		B []float64 `json:"b"`
+	}
+	if err := (UnmarshalOptions{NonFinite: true}).Unmarshal([]byte(input), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := MarshalOptions{Float: FloatFormat{NonFinite: true}}.Marshal(decoded)
+	if err != nil || string(got) != `{"a":NaN,"b":[Infinity,-Infinity]}` {
+		t.Errorf("expected the floats written back, got %s, %v", got, err)
+	}
+	if _, err := Marshal(decoded); err == nil {
+		t.Error("expected non-finite floats to fail by default")
+	}
+}
+
+func TestMarshalOptions_Charset(t *testing.T) {
+	value := MustParse(`{"city": "Zürich", "mood": "😀"}`)
+	got, err := MarshalOptions{Charset: CharsetASCII}.Marshal(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"city":"Z\u00fcrich","mood":"\ud83d\ude00"}`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	// The escapes read back as the same document
+	if back, err := ParseBytes(got); err != nil || !Equal(back, value) {
+		t.Errorf("expected the ASCII output to parse back to the input, got %v, %v", back, err)
+	}
+
+	got, err = MarshalOptions{Charset: CharsetLatin1}.Marshal(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "{\"city\":\"Z\xfcrich\",\"mood\":\"\\ud83d\\ude00\"}"; string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMarshalOptions_BOM(t *testing.T) {
+	got, err := MarshalOptions{BOM: true}.Marshal([]int{1})
+	if err != nil || string(got) != "\xef\xbb\xbf[1]" {
+		t.Errorf("expected [1] after a byte order mark, got %q, %v", got, err)
+	}
+	if CRLF.String() != "\r\n" || LF.String() != "\n" {
+		t.Errorf("unexpected line endings %q and %q", CRLF.String(), LF.String())
+	}
+}
+
+func TestMarshalOptions_Canonical(t *testing.T) {
+	a, err := Parse(`{"b": 4.50, "a": [1E30, "\u00e9"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse(`{"a":[1e+30,"é"],"b":4.5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := MarshalOptions{Canonical: true}
+	first, err := opts.Marshal(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := opts.Marshal(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"a":[1e+30,"é"],"b":4.5}`; string(first) != want || string(second) != want {
+		t.Errorf("expected both to be %s, got %s and %s", want, first, second)
+	}
+}
+
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) {
+	panic("boom")
+}
+
+func TestMustMarshal(t *testing.T) {
+	if got := string(MustMarshal([]int{1, 2})); got != `[1,2]` {
+		t.Errorf("MustMarshal returned %s", got)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected MustMarshal to panic on a value it cannot encode")
+		}
+	}()
+	MustMarshal(make(chan int))
+}
+
+func TestMarshal_RecoversPanics(t *testing.T) {
+	_, err := Marshal(map[string]interface{}{"a": panickingMarshaler{}})
+	if !errors.Is(err, ErrInternal) {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+	var internal *InternalError
+	if !errors.As(err, &internal) || internal.Op != "marshal" || internal.Value != "boom" {
+		t.Errorf("expected the marshal panic to be recorded, got %#v", internal)
+	}
+}
@@ -0,0 +1,199 @@
+// Package merge combines parsed JSON documents, with the strategy for
+// conflicting values configurable per path.
+package merge
+
+import (
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Strategy decides what happens when two documents both define a path
+type Strategy int
+
+// Merge strategies
+const (
+	Deep      Strategy = iota // Objects merge key by key, any other value is overwritten
+	Overwrite                 // The later value replaces the earlier one
+	Keep                      // The earlier value is kept
+	Concat                    // Arrays are concatenated, anything else is an error
+	Error                     // Differing values are an error
+)
+
+// String returns the name of the strategy
+func (s Strategy) String() string {
+	switch s {
+	case Deep:
+		return "deep"
+	case Overwrite:
+		return "overwrite"
+	case Keep:
+		return "keep"
+	case Concat:
+		return "concat"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// Rules selects a strategy per path.
+//
+// Paths are JSON Pointers such as "/servers" or "/services/*/ports", where a
+// "*" segment matches any key or index. When several paths match, the one
+// with the most literal segments wins. Paths that match nothing use Default,
+// except below a path merged with Error, whose whole subtree must agree:
+// there they use Error too, until a more specific rule says otherwise.
+type Rules struct {
+	Default Strategy
+	Paths   map[string]Strategy
+}
+
+// Merge combines overlay into base and returns the result.
+// The inputs are not modified, but the result shares subtrees that did not need merging.
+func Merge(base, overlay ast.Value, rules Rules) (ast.Value, error) {
+	m, err := newMerger(rules)
+	if err != nil {
+		return nil, err
+	}
+	return m.merge(nil, base, overlay, m.defaultStrategy)
+}
+
+// MergeAll merges the documents from left to right
+func MergeAll(rules Rules, docs ...ast.Value) (ast.Value, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("merge: no documents")
+	}
+
+	m, err := newMerger(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	result := docs[0]
+	for _, doc := range docs[1:] {
+		if result, err = m.merge(nil, result, doc, m.defaultStrategy); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// rule is a compiled path pattern
+type rule struct {
+	segments []string
+	literals int
+	strategy Strategy
+}
+
+// merger holds the compiled rules for a merge
+type merger struct {
+	defaultStrategy Strategy
+	rules           []rule
+}
+
+// newMerger compiles the rule paths
+func newMerger(rules Rules) (*merger, error) {
+	m := &merger{defaultStrategy: rules.Default}
+	for path, strategy := range rules.Paths {
+		segments, err := ast.SplitPointer(path)
+		if err != nil {
+			return nil, fmt.Errorf("merge: %v", err)
+		}
+		r := rule{segments: segments, strategy: strategy}
+		for _, s := range segments {
+			if s != "*" {
+				r.literals++
+			}
+		}
+		m.rules = append(m.rules, r)
+	}
+	return m, nil
+}
+
+// strategyFor returns the strategy of the most specific rule matching path,
+// or fallback when none does
+func (m *merger) strategyFor(path []string, fallback Strategy) Strategy {
+	best := -1
+	strategy := fallback
+	for _, r := range m.rules {
+		if r.literals > best && r.matches(path) {
+			best = r.literals
+			strategy = r.strategy
+		}
+	}
+	return strategy
+}
+
+// matches checks the path against the pattern segment by segment
+func (r rule) matches(path []string) bool {
+	if len(r.segments) != len(path) {
+		return false
+	}
+	for i, s := range r.segments {
+		if s != "*" && s != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// merge combines two values found at path, using fallback when no rule matches it
+func (m *merger) merge(path []string, base, overlay ast.Value, fallback Strategy) (ast.Value, error) {
+	switch m.strategyFor(path, fallback) {
+	case Overwrite:
+		return overlay, nil
+	case Keep:
+		return base, nil
+	case Concat:
+		baseArray, ok1 := base.(*ast.Array)
+		overlayArray, ok2 := overlay.(*ast.Array)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("merge: concat needs two arrays at %q", ast.FormatPointer(path))
+		}
+		elements := make([]ast.Value, 0, len(baseArray.Elements)+len(overlayArray.Elements))
+		elements = append(elements, baseArray.Elements...)
+		elements = append(elements, overlayArray.Elements...)
+		return &ast.Array{Elements: elements}, nil
+	case Error:
+		if baseObj, ok := base.(*ast.Object); ok {
+			if overlayObj, ok := overlay.(*ast.Object); ok {
+				return m.mergeObjects(path, baseObj, overlayObj, Error)
+			}
+		}
+		if !(ast.EqualOptions{IgnoreKeyOrder: true}).Equal(base, overlay) {
+			return nil, fmt.Errorf("merge: conflicting values at %q", ast.FormatPointer(path))
+		}
+		return base, nil
+	default:
+		baseObj, ok1 := base.(*ast.Object)
+		overlayObj, ok2 := overlay.(*ast.Object)
+		if !ok1 || !ok2 {
+			return overlay, nil
+		}
+		return m.mergeObjects(path, baseObj, overlayObj, m.defaultStrategy)
+	}
+}
+
+// mergeObjects merges the keys of two objects, using fallback for keys no rule matches
+func (m *merger) mergeObjects(path []string, base, overlay *ast.Object, fallback Strategy) (*ast.Object, error) {
+	result := &ast.Object{Pairs: make(map[string]ast.Value, len(base.Pairs)+len(overlay.Pairs))}
+	for _, key := range base.OrderedKeys() {
+		result.Set(key, base.Pairs[key])
+	}
+	for _, key := range overlay.OrderedKeys() {
+		value := overlay.Pairs[key]
+		existing, ok := result.Pairs[key]
+		if !ok {
+			result.Set(key, value)
+			continue
+		}
+		merged, err := m.merge(append(path[:len(path):len(path)], key), existing, value, fallback)
+		if err != nil {
+			return nil, err
+		}
+		result.Set(key, merged)
+	}
+	return result, nil
+}
@@ -0,0 +1,165 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func mustMarshal(t *testing.T, value ast.Value) string {
+	t.Helper()
+	data, err := encoder.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	return string(data)
+}
+
+func TestMerge_Strategies(t *testing.T) {
+	base := `{"name": "api", "db": {"host": "localhost", "port": 5432}, "tags": ["a"], "owners": ["x"], "limits": {"cpu": 1}}`
+	overlay := `{"name": "api-prod", "db": {"host": "db.internal"}, "tags": ["b"], "owners": ["y"], "limits": {"mem": 2}, "new": true}`
+
+	tests := []struct {
+		name  string
+		rules Rules
+		want  string
+	}{
+		{
+			name:  "default deep merge",
+			rules: Rules{},
+			want:  `{"name":"api-prod","db":{"host":"db.internal","port":5432},"tags":["b"],"owners":["y"],"limits":{"cpu":1,"mem":2},"new":true}`,
+		},
+		{
+			name:  "per path strategies",
+			rules: Rules{Paths: map[string]Strategy{"/name": Keep, "/tags": Concat, "/limits": Overwrite}},
+			want:  `{"name":"api","db":{"host":"db.internal","port":5432},"tags":["a","b"],"owners":["y"],"limits":{"mem":2},"new":true}`,
+		},
+		{
+			name:  "default keep with deep override",
+			rules: Rules{Default: Keep, Paths: map[string]Strategy{"": Deep, "/db": Deep}},
+			want:  `{"name":"api","db":{"host":"localhost","port":5432},"tags":["a"],"owners":["x"],"limits":{"cpu":1},"new":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Merge(mustParse(t, base), mustParse(t, overlay), tt.rules)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if s := mustMarshal(t, got); s != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, s)
+		}
+	}
+}
+
+func TestMerge_WildcardsPreferSpecificRules(t *testing.T) {
+	base := `{"services": {"web": {"ports": [80]}, "api": {"ports": [8080]}}}`
+	overlay := `{"services": {"web": {"ports": [443]}, "api": {"ports": [9090]}}}`
+	rules := Rules{Paths: map[string]Strategy{
+		"/services/*/ports":   Concat,
+		"/services/api/ports": Overwrite,
+	}}
+
+	got, err := Merge(mustParse(t, base), mustParse(t, overlay), rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"services":{"web":{"ports":[80,443]},"api":{"ports":[9090]}}}`
+	if s := mustMarshal(t, got); s != want {
+		t.Errorf("expected %s, got %s", want, s)
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		overlay string
+		rules   Rules
+		want    string
+	}{
+		{"conflict", `{"a": {"b": 1}}`, `{"a": {"b": 2}}`, Rules{Default: Error}, `conflicting values at "/a/b"`},
+		{"conflict below an error path", `{"db": {"pool": {"size": 5}}}`, `{"db": {"pool": {"size": 10}}}`, Rules{Paths: map[string]Strategy{"/db": Error}}, `conflicting values at "/db/pool/size"`},
+		{"concat non arrays", `{"a": 1}`, `{"a": [2]}`, Rules{Paths: map[string]Strategy{"/a": Concat}}, `concat needs two arrays at "/a"`},
+		{"bad pointer", `{}`, `{}`, Rules{Paths: map[string]Strategy{"a": Keep}}, "invalid path"},
+	}
+
+	for _, tt := range tests {
+		_, err := Merge(mustParse(t, tt.base), mustParse(t, tt.overlay), tt.rules)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := Merge(mustParse(t, `{"a": [1, {"b": null}]}`), mustParse(t, `{"a": [1, {"b": null}]}`), Rules{Default: Error}); err != nil {
+		t.Errorf("expected equal values not to conflict, got %v", err)
+	}
+}
+
+func TestMerge_ErrorCoversSubtree(t *testing.T) {
+	base := `{"db": {"host": "localhost", "pool": {"size": 5}}, "name": "api"}`
+	overlay := `{"db": {"host": "localhost", "pool": {"size": 5, "idle": 2}}, "name": "api-prod"}`
+	rules := Rules{Paths: map[string]Strategy{"/db": Error}}
+
+	got, err := Merge(mustParse(t, base), mustParse(t, overlay), rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"db":{"host":"localhost","pool":{"size":5,"idle":2}},"name":"api-prod"}`
+	if s := mustMarshal(t, got); s != want {
+		t.Errorf("expected %s, got %s", want, s)
+	}
+
+	rules.Paths["/db/pool/size"] = Overwrite
+	overlay = `{"db": {"pool": {"size": 10}}}`
+	if got, err = Merge(mustParse(t, base), mustParse(t, overlay), rules); err != nil {
+		t.Fatalf("expected a more specific rule to win below /db, got %v", err)
+	}
+	want = `{"db":{"host":"localhost","pool":{"size":10}},"name":"api"}`
+	if s := mustMarshal(t, got); s != want {
+		t.Errorf("expected %s, got %s", want, s)
+	}
+}
+
+func TestMergeAll(t *testing.T) {
+	docs := []ast.Value{
+		mustParse(t, `{"a": 1, "b": {"c": 1}}`),
+		mustParse(t, `{"b": {"d": 2}}`),
+		mustParse(t, `{"a": 3, "b~/": 4}`),
+	}
+
+	got, err := MergeAll(Rules{}, docs...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"a":3,"b":{"c":1,"d":2},"b~/":4}`
+	if s := mustMarshal(t, got); s != want {
+		t.Errorf("expected %s, got %s", want, s)
+	}
+
+	if mustMarshal(t, docs[0]) != `{"a":1,"b":{"c":1}}` {
+		t.Errorf("expected inputs to be left unmodified, got %s", mustMarshal(t, docs[0]))
+	}
+
+	if _, err := MergeAll(Rules{}); err == nil {
+		t.Errorf("expected error for no documents")
+	}
+}
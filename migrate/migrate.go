@@ -0,0 +1,133 @@
+// Package migrate upgrades stored JSON documents from one version of their
+// schema to the next, and back again, through declared, reversible steps.
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/patch"
+)
+
+// Migration upgrades documents from version From to From+1, by running its
+// steps in order. Downgrading undoes them in reverse order.
+type Migration struct {
+	From  int
+	Steps []Step
+}
+
+// Migrator moves documents between versions. The version is an integer
+// stored in each document, at a JSON Pointer such as "/version".
+type Migrator struct {
+	versionPath string
+	migrations  map[int]Migration
+	latest      int
+}
+
+// New returns a Migrator for documents holding their version at versionPath.
+// The migrations must join up, each starting where another ends, and the
+// highest version they reach is Latest.
+func New(versionPath string, migrations ...Migration) (*Migrator, error) {
+	if _, err := ast.SplitPointer(versionPath); err != nil || versionPath == "" {
+		return nil, fmt.Errorf("migrate: invalid version path %q", versionPath)
+	}
+	m := &Migrator{versionPath: versionPath, migrations: make(map[int]Migration)}
+	froms := make([]int, 0, len(migrations))
+	for _, mig := range migrations {
+		if _, ok := m.migrations[mig.From]; ok {
+			return nil, fmt.Errorf("migrate: two migrations from version %d", mig.From)
+		}
+		m.migrations[mig.From] = mig
+		froms = append(froms, mig.From)
+	}
+	sort.Ints(froms)
+	for i := 1; i < len(froms); i++ {
+		if froms[i] != froms[i-1]+1 {
+			return nil, fmt.Errorf("migrate: no migration from version %d", froms[i-1]+1)
+		}
+	}
+	if len(froms) > 0 {
+		m.latest = froms[len(froms)-1] + 1
+	}
+	return m, nil
+}
+
+// Latest returns the highest version the migrations reach
+func (m *Migrator) Latest() int {
+	return m.latest
+}
+
+// Version returns the version stored in doc
+func (m *Migrator) Version(doc ast.Value) (int, error) {
+	v, err := ast.Lookup(doc, m.versionPath)
+	if err != nil {
+		return 0, fmt.Errorf("migrate: no version: %v", err)
+	}
+	n, ok := v.(*ast.Number)
+	if !ok {
+		return 0, fmt.Errorf("migrate: the version at %s is not a number", m.versionPath)
+	}
+	version, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return 0, fmt.Errorf("migrate: the version at %s is not an integer: %s", m.versionPath, n.Value)
+	}
+	return version, nil
+}
+
+// Migrate returns a copy of doc upgraded or downgraded to version, one
+// migration at a time, with its version updated after each. doc itself is
+// left alone, so it may be frozen, and a migration that fails part way
+// changes nothing.
+func (m *Migrator) Migrate(doc ast.Value, version int) (ast.Value, error) {
+	current, err := m.Version(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc = ast.Clone(doc)
+	for current != version {
+		up := current < version
+		from := current
+		if !up {
+			from = current - 1
+		}
+		mig, ok := m.migrations[from]
+		if !ok {
+			return nil, fmt.Errorf("migrate: no migration from version %d to %d", current, version)
+		}
+		steps := mig.Steps
+		for i := range steps {
+			step, run := steps[i], steps[i].Up
+			if !up {
+				step = steps[len(steps)-1-i]
+				run = step.Down
+			}
+			if err := run(doc); err != nil {
+				return nil, fmt.Errorf("migrate: version %d to %d: %s: %w", current, current+direction(up), step, err)
+			}
+		}
+		current += direction(up)
+		if err := ast.SetPath(doc, m.versionPath, &ast.Number{Value: strconv.Itoa(current)}); err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+	}
+	return doc, nil
+}
+
+// DryRun reports what Migrate would change in doc, as a patch, without
+// changing anything
+func (m *Migrator) DryRun(doc ast.Value, version int) (patch.Patch, error) {
+	migrated, err := m.Migrate(doc, version)
+	if err != nil {
+		return nil, err
+	}
+	return patch.DiffOptions{Arrays: patch.LCS}.Diff(doc, migrated), nil
+}
+
+func direction(up bool) int {
+	if up {
+		return 1
+	}
+	return -1
+}
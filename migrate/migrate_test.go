@@ -0,0 +1,164 @@
+package migrate
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func mustMarshal(t *testing.T, value ast.Value) string {
+	t.Helper()
+	data, err := encoder.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	return string(data)
+}
+
+// priceToNumber turns a price written as a string into a number
+func priceToNumber(v ast.Value) (ast.Value, error) {
+	s, ok := v.(*ast.String)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+	if _, err := strconv.ParseFloat(s.Value, 64); err != nil {
+		return nil, err
+	}
+	return &ast.Number{Value: s.Value}, nil
+}
+
+func priceToString(v ast.Value) (ast.Value, error) {
+	return &ast.String{Value: v.(*ast.Number).Value}, nil
+}
+
+func newMigrator(t *testing.T) *Migrator {
+	t.Helper()
+	m, err := New("/version",
+		Migration{From: 1, Steps: []Step{
+			Rename("/items/*/cost", "price"),
+			Convert("/items/*/price", priceToNumber, priceToString),
+		}},
+		Migration{From: 2, Steps: []Step{
+			Move("/owner", "/meta/owner"),
+			Add("/meta/tags", &ast.Array{Elements: []ast.Value{}}),
+		}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return m
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	m := newMigrator(t)
+	if m.Latest() != 3 {
+		t.Errorf("expected the latest version to be 3, got %d", m.Latest())
+	}
+	v1 := `{"version":1,"owner":"ann","items":[{"name":"a","cost":"1.50"},{"name":"b"}]}`
+	v3 := `{"version":3,"items":[{"name":"a","price":1.50},{"name":"b"}],"meta":{"owner":"ann","tags":[]}}`
+	doc := mustParse(t, v1)
+	ast.Freeze(doc)
+
+	up, err := m.Migrate(doc, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mustMarshal(t, up); got != v3 {
+		t.Errorf("expected %s, got %s", v3, got)
+	}
+	if got := mustMarshal(t, doc); got != v1 {
+		t.Errorf("expected the input to be left alone, got %s", got)
+	}
+
+	down, err := m.Migrate(up, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The moved member comes back last, and the object made for it stays
+	want := `{"version":1,"items":[{"name":"a","cost":"1.50"},{"name":"b"}],"meta":{},"owner":"ann"}`
+	if got := mustMarshal(t, down); got != want {
+		t.Errorf("expected a downgrade to give %s, got %s", want, got)
+	}
+
+	same, err := m.Migrate(up, 3)
+	if err != nil || mustMarshal(t, same) != v3 {
+		t.Errorf("expected no change at the same version, got %v, %v", same, err)
+	}
+}
+
+func TestMigrator_MigrateErrors(t *testing.T) {
+	m := newMigrator(t)
+	tests := []struct {
+		doc     string
+		version int
+		want    string
+	}{
+		{`{"items": []}`, 2, "migrate: no version"},
+		{`{"version": "1"}`, 2, "is not a number"},
+		{`{"version": 1.5}`, 2, "is not an integer"},
+		{`{"version": 3}`, 4, "no migration from version 3 to 4"},
+		{`{"version": 1}`, 0, "no migration from version 1 to 0"},
+		{`{"version": 1, "items": [{"cost": "cheap"}]}`, 2, `migrate: version 1 to 2: convert /items/*/price: /items/0/price: strconv.ParseFloat`},
+		{`{"version": 1, "items": [{"cost": "1", "price": 2}]}`, 2, `/items/0 already has a member "price"`},
+	}
+	for _, tt := range tests {
+		if _, err := m.Migrate(mustParse(t, tt.doc), tt.version); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s to %d: expected an error containing %q, got %v", tt.doc, tt.version, tt.want, err)
+		}
+	}
+
+	oneWay, _ := New("/v", Migration{From: 0, Steps: []Step{Convert("/a", priceToNumber, nil)}})
+	if _, err := oneWay.Migrate(mustParse(t, `{"v": 1, "a": 1}`), 0); !errors.Is(err, ErrIrreversible) {
+		t.Errorf("expected ErrIrreversible, got %v", err)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := New("version"); err == nil {
+		t.Error("expected an error for an invalid version path")
+	}
+	if _, err := New(""); err == nil {
+		t.Error("expected an error for the root as version path")
+	}
+	if _, err := New("/v", Migration{From: 1}, Migration{From: 1}); err == nil {
+		t.Error("expected an error for two migrations from one version")
+	}
+	if _, err := New("/v", Migration{From: 1}, Migration{From: 3}); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("expected an error for a gap, got %v", err)
+	}
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	m := newMigrator(t)
+	doc := mustParse(t, `{"version": 2, "owner": "ann"}`)
+	p, err := m.DryRun(doc, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `[{"op":"replace","path":"/version","value":3},{"op":"remove","path":"/owner"},{"op":"add","path":"/meta","value":{"owner":"ann","tags":[]}}]`
+	if got := mustMarshal(t, p.Value()); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if got := mustMarshal(t, doc); got != `{"version":2,"owner":"ann"}` {
+		t.Errorf("expected a dry run to change nothing, got %s", got)
+	}
+}
@@ -0,0 +1,280 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// ErrIrreversible is returned when downgrading through a step that cannot
+// be undone
+var ErrIrreversible = errors.New("the step cannot be undone")
+
+// Step is one change of a migration. Up makes it in a document and Down
+// undoes it; both edit the document in place. Paths are JSON Pointers in
+// which a "*" segment matches every member or element, and a step leaves a
+// document alone where its path finds nothing, since stored documents often
+// leave out optional values.
+type Step interface {
+	Up(doc ast.Value) error
+	Down(doc ast.Value) error
+	String() string // describes the step in errors
+}
+
+// Rename returns a step renaming the member at path to name, keeping its
+// place among the members. The last segment of path must be a name.
+func Rename(path, name string) Step {
+	return rename{path: path, name: name}
+}
+
+type rename struct {
+	path, name string
+}
+
+func (r rename) Up(doc ast.Value) error {
+	parent, key, err := splitLast(r.path)
+	if err != nil {
+		return err
+	}
+	return renameAll(doc, parent, key, r.name)
+}
+
+func (r rename) Down(doc ast.Value) error {
+	parent, key, err := splitLast(r.path)
+	if err != nil {
+		return err
+	}
+	return renameAll(doc, parent, r.name, key)
+}
+
+func (r rename) String() string {
+	return fmt.Sprintf("rename %s to %q", r.path, r.name)
+}
+
+// renameAll renames the member old to name in every object parent matches
+func renameAll(doc ast.Value, parent, old, name string) error {
+	pointers, err := expand(doc, parent)
+	if err != nil {
+		return err
+	}
+	for _, pointer := range pointers {
+		v, _ := ast.Lookup(doc, pointer)
+		obj, ok := v.(*ast.Object)
+		if !ok {
+			continue
+		}
+		if _, ok := obj.Pairs[old]; !ok {
+			continue
+		}
+		if _, ok := obj.Pairs[name]; ok {
+			return fmt.Errorf("%s already has a member %q", display(pointer), name)
+		}
+		// Set the members again in order, so the new name takes the old one's place
+		keys := obj.OrderedKeys()
+		values := make([]ast.Value, len(keys))
+		for i, key := range keys {
+			values[i] = obj.Pairs[key]
+			obj.Delete(key)
+		}
+		for i, key := range keys {
+			if key == old {
+				key = name
+			}
+			obj.Set(key, values[i])
+		}
+	}
+	return nil
+}
+
+// Move returns a step moving the value at from to to, creating the objects
+// on the way there; moving it back leaves them behind, empty. Neither path
+// may hold a "*" segment.
+func Move(from, to string) Step {
+	return move{from: from, to: to}
+}
+
+type move struct {
+	from, to string
+}
+
+func (m move) Up(doc ast.Value) error   { return moveValue(doc, m.from, m.to) }
+func (m move) Down(doc ast.Value) error { return moveValue(doc, m.to, m.from) }
+func (m move) String() string           { return fmt.Sprintf("move %s to %s", m.from, m.to) }
+
+func moveValue(doc ast.Value, from, to string) error {
+	if strings.Contains(from+"/", "/*/") || strings.Contains(to+"/", "/*/") {
+		return fmt.Errorf("a move cannot have a \"*\" segment")
+	}
+	for _, path := range []string{from, to} {
+		if _, err := ast.SplitPointer(path); err != nil {
+			return err
+		}
+	}
+	v, err := ast.Lookup(doc, from)
+	if err != nil {
+		return nil // nothing to move
+	}
+	if err := ast.DeletePath(doc, from); err != nil {
+		return err
+	}
+	return ast.SetPath(doc, to, v)
+}
+
+// Convert returns a step replacing every value at path with what up returns
+// for it, such as a number in place of a numeric string. down converts back
+// when downgrading; a nil down makes the step irreversible.
+func Convert(path string, up, down func(ast.Value) (ast.Value, error)) Step {
+	return convert{path: path, up: up, down: down}
+}
+
+type convert struct {
+	path     string
+	up, down func(ast.Value) (ast.Value, error)
+}
+
+func (c convert) Up(doc ast.Value) error { return convertAll(doc, c.path, c.up) }
+
+func (c convert) Down(doc ast.Value) error {
+	if c.down == nil {
+		return ErrIrreversible
+	}
+	return convertAll(doc, c.path, c.down)
+}
+
+func (c convert) String() string { return "convert " + c.path }
+
+func convertAll(doc ast.Value, path string, fn func(ast.Value) (ast.Value, error)) error {
+	if path == "" {
+		return fmt.Errorf("cannot convert the root value")
+	}
+	pointers, err := expand(doc, path)
+	if err != nil {
+		return err
+	}
+	for _, pointer := range pointers {
+		v, _ := ast.Lookup(doc, pointer)
+		converted, err := fn(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", display(pointer), err)
+		}
+		if err := ast.SetPath(doc, pointer, converted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add returns a step setting the member at path to value in every object
+// that lacks it, such as a new field with a default. Downgrading removes it.
+func Add(path string, value ast.Value) Step {
+	return add{path: path, value: value}
+}
+
+type add struct {
+	path  string
+	value ast.Value
+}
+
+func (a add) Up(doc ast.Value) error {
+	parent, key, err := splitLast(a.path)
+	if err != nil {
+		return err
+	}
+	pointers, err := expand(doc, parent)
+	if err != nil {
+		return err
+	}
+	for _, pointer := range pointers {
+		v, _ := ast.Lookup(doc, pointer)
+		if obj, ok := v.(*ast.Object); ok {
+			if _, ok := obj.Pairs[key]; !ok {
+				obj.Set(key, ast.Clone(a.value))
+			}
+		}
+	}
+	return nil
+}
+
+func (a add) Down(doc ast.Value) error {
+	parent, key, err := splitLast(a.path)
+	if err != nil {
+		return err
+	}
+	pointers, err := expand(doc, parent)
+	if err != nil {
+		return err
+	}
+	for _, pointer := range pointers {
+		v, _ := ast.Lookup(doc, pointer)
+		if obj, ok := v.(*ast.Object); ok {
+			obj.Delete(key)
+		}
+	}
+	return nil
+}
+
+func (a add) String() string { return "add " + a.path }
+
+// splitLast splits a path into the pattern of its parent and its last
+// segment, which must be a member name
+func splitLast(path string) (parent, key string, err error) {
+	segments, err := ast.SplitPointer(path)
+	if err != nil {
+		return "", "", err
+	}
+	if len(segments) == 0 || segments[len(segments)-1] == "*" {
+		return "", "", fmt.Errorf("path %q must end in a member name", path)
+	}
+	return ast.FormatPointer(segments[:len(segments)-1]), segments[len(segments)-1], nil
+}
+
+// expand returns the pointers of the values in doc that pattern matches, in
+// document order
+func expand(doc ast.Value, pattern string) ([]string, error) {
+	segments, err := ast.SplitPointer(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var pointers []string
+	var walk func(v ast.Value, done []string, rest []string)
+	walk = func(v ast.Value, done []string, rest []string) {
+		if len(rest) == 0 {
+			pointers = append(pointers, ast.FormatPointer(done))
+			return
+		}
+		step := func(key string, child ast.Value) {
+			walk(child, append(done[:len(done):len(done)], key), rest[1:])
+		}
+		switch v := v.(type) {
+		case *ast.Object:
+			if rest[0] != "*" {
+				if child, ok := v.Pairs[rest[0]]; ok {
+					step(rest[0], child)
+				}
+				return
+			}
+			for _, key := range v.OrderedKeys() {
+				step(key, v.Pairs[key])
+			}
+		case *ast.Array:
+			for i, child := range v.Elements {
+				if key := strconv.Itoa(i); rest[0] == "*" || rest[0] == key {
+					step(key, child)
+				}
+			}
+		}
+	}
+	walk(doc, nil, segments)
+	return pointers, nil
+}
+
+// display returns a pointer for a message, naming the root
+func display(pointer string) string {
+	if pointer == "" {
+		return "the root"
+	}
+	return pointer
+}
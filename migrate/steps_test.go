@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		step     Step
+		doc, up  string
+		describe string
+	}{
+		{Rename("/b", "x"), `{"a":1,"b":2,"c":3}`, `{"a":1,"x":2,"c":3}`, `rename /b to "x"`},
+		{Rename("/users/*/mail", "email"), `{"users":[{"mail":"a"},{"name":"b"}]}`, `{"users":[{"email":"a"},{"name":"b"}]}`, `rename /users/*/mail to "email"`},
+		{Rename("/*/id", "key"), `{"a":{"id":1},"b":{"id":2}}`, `{"a":{"key":1},"b":{"key":2}}`, `rename /*/id to "key"`},
+		{Rename("/missing", "x"), `{"a":1}`, `{"a":1}`, `rename /missing to "x"`},
+		{Move("/a/b", "/c/d/e"), `{"a":{"b":1}}`, `{"a":{},"c":{"d":{"e":1}}}`, "move /a/b to /c/d/e"},
+		{Move("/gone", "/x"), `{"a":1}`, `{"a":1}`, "move /gone to /x"},
+		{Add("/items/*/qty", &ast.Number{Value: "1"}), `{"items":[{"qty":3},{}]}`, `{"items":[{"qty":3},{"qty":1}]}`, "add /items/*/qty"},
+	}
+	for _, tt := range tests {
+		doc := mustParse(t, tt.doc)
+		if err := tt.step.Up(doc); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.step, err)
+			continue
+		}
+		if got := mustMarshal(t, doc); got != tt.up {
+			t.Errorf("%s: expected %s, got %s", tt.step, tt.up, got)
+		}
+		if tt.step.String() != tt.describe {
+			t.Errorf("expected %q, got %q", tt.describe, tt.step.String())
+		}
+	}
+
+	// Down undoes Up, except that Add removes values it did not add
+	downs := []struct {
+		step Step
+		doc  string
+	}{
+		{Rename("/b", "x"), `{"a":1,"b":2,"c":3}`},
+		{Rename("/users/*/mail", "email"), `{"users":[{"mail":"a"},{"name":"b"}]}`},
+		{Move("/a/b", "/c"), `{"a":{"b":1}}`},
+		{Add("/items/*/qty", &ast.Number{Value: "1"}), `{"items":[{},{"n":2}]}`},
+	}
+	for _, tt := range downs {
+		doc := mustParse(t, tt.doc)
+		if err := tt.step.Up(doc); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.step, err)
+		}
+		if err := tt.step.Down(doc); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.step, err)
+		}
+		if got := mustMarshal(t, doc); got != tt.doc {
+			t.Errorf("%s: expected Down to give back %s, got %s", tt.step, tt.doc, got)
+		}
+	}
+}
+
+func TestSteps_Errors(t *testing.T) {
+	tests := []struct {
+		step Step
+		want string
+	}{
+		{Rename("/a/*", "x"), "must end in a member name"},
+		{Rename("a", "x"), "invalid"},
+		{Move("/*/a", "/b"), `cannot have a "*" segment`},
+		{Move("/a", "b"), "invalid"},
+		{Convert("", priceToNumber, nil), "cannot convert the root value"},
+		{Convert("x", priceToNumber, nil), "invalid"},
+		{Add("", &ast.Null{}), "must end in a member name"},
+	}
+	for _, tt := range tests {
+		if err := tt.step.Up(mustParse(t, `{"a": {"b": 1}}`)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.step, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package jsonparser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// minimalSizeBudget is the largest stripped binary testdata/minimal may build to
+const minimalSizeBudget = 4 << 20
+
+// goCommand runs the go tool in the module root, skipping the test when it is unavailable
+func goCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	out, err := exec.Command(goTool, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func TestMinimalBuild_Dependencies(t *testing.T) {
+	const module = "github.com/letsmakecakes/jsonparser"
+	out := goCommand(t, "list", "-tags", "jsonparser_minimal", "-deps",
+		"-f", "{{if .Standard}}std {{end}}{{.ImportPath}}", "./testdata/minimal")
+
+	// The core is the root package and the internals it needs; the optional
+	// sub-packages and anything that talks to the network stay out
+	excluded := []string{module + "/transform", module + "/binast", module + "/merge", module + "/cmd/", "std plugin", "std net", "std os/exec"}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if !strings.HasPrefix(line, "std ") && !strings.HasPrefix(line, module) {
+			t.Errorf("core depends on third-party package %s", line)
+		}
+		for _, prefix := range excluded {
+			if strings.HasPrefix(line, prefix) {
+				t.Errorf("core depends on %s", strings.TrimPrefix(line, "std "))
+			}
+		}
+	}
+}
+
+func TestMinimalBuild_Size(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "minimal")
+	goCommand(t, "build", "-tags", "jsonparser_minimal", "-trimpath", "-ldflags", "-s -w", "-o", binary, "./testdata/minimal")
+
+	info, err := os.Stat(binary)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Size() > minimalSizeBudget {
+		t.Errorf("minimal binary is %d bytes, over the budget of %d", info.Size(), minimalSizeBudget)
+	}
+}
@@ -0,0 +1,98 @@
+package jsonparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LineError is a line of an NDJSON stream that could not be parsed. The
+// decoder has moved past it, so the next Decode reads the next line.
+type LineError struct {
+	Line int // the line's number, counting from 1
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the parse error
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineDecoder reads newline-delimited JSON (NDJSON, JSON Lines): one
+// document per line
+type LineDecoder struct {
+	r    *bufio.Reader
+	opts []Option
+	line int
+}
+
+// NewLineDecoder returns a LineDecoder reading from r, parsing each line
+// with the options
+func NewLineDecoder(r io.Reader, opts ...Option) *LineDecoder {
+	return &LineDecoder{r: bufio.NewReader(r), opts: opts}
+}
+
+// Decode returns the document on the next line, skipping blank ones. It
+// returns io.EOF after the last line. A line that does not parse yields a
+// *LineError, and Decode can be called again to carry on after it.
+func (d *LineDecoder) Decode() (Value, error) {
+	for {
+		data, err := d.r.ReadBytes('\n')
+		if err == io.EOF && len(data) == 0 {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		d.line++
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		v, err := ParseBytes(data, d.opts...)
+		if err != nil {
+			return nil, &LineError{Line: d.line, Err: err}
+		}
+		return v, nil
+	}
+}
+
+// LineEncoder writes newline-delimited JSON, one compact document per line
+type LineEncoder struct {
+	w       io.Writer
+	opts    MarshalOptions
+	eol     LineEnding
+	started bool // whether a line has been written, after which the BOM option is ignored
+}
+
+// NewLineEncoder returns a LineEncoder writing to w
+func NewLineEncoder(w io.Writer) *LineEncoder {
+	return &LineEncoder{w: w}
+}
+
+// SetOptions sets how values are marshaled
+func (e *LineEncoder) SetOptions(o MarshalOptions) {
+	e.opts = o
+}
+
+// SetLineEnding sets the bytes written after each line; the default is LF
+func (e *LineEncoder) SetLineEnding(eol LineEnding) {
+	e.eol = eol
+}
+
+// Encode writes v as one line, in a single Write
+func (e *LineEncoder) Encode(v interface{}) error {
+	opts := e.opts
+	opts.BOM = opts.BOM && !e.started
+	data, err := opts.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.started = true
+	_, err = e.w.Write(append(data, e.eol.String()...))
+	return err
+}
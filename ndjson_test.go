@@ -0,0 +1,63 @@
+package jsonparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineDecoder(t *testing.T) {
+	dec := NewLineDecoder(strings.NewReader("{\"a\":1}\n\n  [true]\r\n{\"a\":\n\"s\""))
+	want := []string{`{"a":1}`, `[true]`, "error", `"s"`}
+	for i, w := range want {
+		v, err := dec.Decode()
+		if w == "error" {
+			var lineErr *LineError
+			if !errors.As(err, &lineErr) || lineErr.Line != 4 {
+				t.Fatalf("Decode %d: error = %v, want a *LineError on line 4", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Decode %d: %v", i, err)
+		}
+		data, err := Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != w {
+			t.Errorf("Decode %d = %s, want %s", i, data, w)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode at end: error = %v, want io.EOF", err)
+	}
+}
+
+func TestLineEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewLineEncoder(&buf)
+	for _, v := range []interface{}{map[string]interface{}{"a": 1}, "s"} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "{\"a\":1}\n\"s\"\n"; buf.String() != want {
+		t.Errorf("Encode wrote %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	enc = NewLineEncoder(&buf)
+	enc.SetOptions(MarshalOptions{BOM: true})
+	enc.SetLineEnding(CRLF)
+	for _, v := range []interface{}{1, 2} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "\xef\xbb\xbf1\r\n2\r\n"; buf.String() != want {
+		t.Errorf("Encode wrote %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,61 @@
+package jsonparser
+
+import (
+	"errors"
+
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// ErrorOffset returns the byte offset in the input at which err, or the
+// first error of an ErrorList, was found. Unlike the column in an error
+// message, which counts characters, the offset can be used to slice the
+// input. It reports false for an error that carries no offset.
+func ErrorOffset(err error) (int64, bool) {
+	var list ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		err = list[0]
+	}
+	pos, ok := errorPosition(err)
+	return pos.Offset, ok && pos.Offset >= 0
+}
+
+// errorPosition returns where err was found. The offset is -1 when only
+// the line and column are known, and the line and column are 0 when only
+// the offset is.
+func errorPosition(err error) (guard.Position, bool) {
+	var (
+		syntax       *lexer.SyntaxError
+		unterminated *lexer.UnterminatedStringError
+		unexpected   *lexer.UnexpectedTokenError
+		read         *lexer.ReadError
+		duplicate    *parser.DuplicateKeyError
+		limit        *parser.LimitError
+		value        *parser.ValueError
+		internal     *guard.Error
+		decode       *DecodeError
+	)
+	switch {
+	case errors.As(err, &syntax):
+		return guard.Position{Offset: syntax.Offset, Line: syntax.Line, Column: syntax.Column}, true
+	case errors.As(err, &unterminated):
+		return guard.Position{Offset: unterminated.Offset, Line: unterminated.Line, Column: unterminated.Column}, true
+	case errors.As(err, &unexpected):
+		tok := unexpected.Token
+		return guard.Position{Offset: tok.Offset, Line: tok.Line, Column: tok.Column}, true
+	case errors.As(err, &read):
+		return guard.Position{Offset: read.Offset, Line: read.Line, Column: read.Column}, true
+	case errors.As(err, &duplicate):
+		return guard.Position{Offset: duplicate.Offset, Line: duplicate.Line, Column: duplicate.Column}, true
+	case errors.As(err, &limit):
+		return guard.Position{Offset: limit.Offset, Line: limit.Line, Column: limit.Column}, true
+	case errors.As(err, &value):
+		return guard.Position{Offset: value.Offset, Line: value.Line, Column: value.Column}, true
+	case errors.As(err, &internal):
+		return internal.Position, true
+	case errors.As(err, &decode):
+		return guard.Position{Offset: decode.Offset}, true
+	}
+	return guard.Position{}, false
+}
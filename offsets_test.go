@@ -0,0 +1,41 @@
+package jsonparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorOffset(t *testing.T) {
+	// The multi-byte text before each failure makes byte offsets and
+	// columns differ; at is the input from where the error is
+	tests := []struct {
+		input string
+		opts  []Option
+		at    string
+	}{
+		{`["héllo", @]`, nil, `@]`},
+		{`{"ключ": 1 "b": 2}`, nil, `"b"`},
+		{`["日本", "open`, nil, `"open`},
+		{`["é", 1`, nil, ``},
+		{`{"é": 1, "é": 2}`, []Option{WithStrictMode(true)}, `"é": 2`},
+		{`["é", [1]]`, []Option{WithMaxDepth(1)}, `[1]]`},
+		{`["é", 2, 3]`, []Option{WithMaxArrayElements(2)}, `3]`},
+		{`["é", 1,, @]`, []Option{WithRecovery(true)}, `, @]`},
+	}
+	for _, tt := range tests {
+		want := int64(strings.LastIndex(tt.input, tt.at))
+		_, parseErr := Parse(tt.input, tt.opts...)
+		validateErr := Validate([]byte(tt.input), tt.opts...)
+		for _, err := range []error{parseErr, validateErr} {
+			got, ok := ErrorOffset(err)
+			if !ok || got != want {
+				t.Errorf("%s: expected offset %d, got %d, %t from %v", tt.input, want, got, ok, err)
+			}
+		}
+	}
+
+	if _, ok := ErrorOffset(errors.New("other")); ok {
+		t.Error("expected no offset for an error from outside the package")
+	}
+}
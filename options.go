@@ -0,0 +1,276 @@
+package jsonparser
+
+import (
+	"github.com/letsmakecakes/jsonparser/dialect"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// DefaultMaxDepth is the nesting limit applied unless WithMaxDepth sets another
+const DefaultMaxDepth = parser.DefaultMaxDepth
+
+// LimitError is returned when a document exceeds MaxDepth, MaxObjectKeys or
+// MaxArrayElements, or has more than MaxErrors errors. Limit names the
+// setting and Max its value.
+type LimitError = parser.LimitError
+
+// Limit names the ParserConfig setting a LimitError reports
+type Limit = parser.Limit
+
+// Limits reported by LimitError
+const (
+	LimitDepth         = parser.LimitDepth
+	LimitObjectKeys    = parser.LimitObjectKeys
+	LimitArrayElements = parser.LimitArrayElements
+	LimitErrors        = parser.LimitErrors
+)
+
+// ParserConfig holds the settings that Options apply to Parse, ParseBytes and Validate
+type ParserConfig struct {
+	// MaxDepth is the deepest nesting of arrays and objects accepted;
+	// 0 selects DefaultMaxDepth and a negative value disables the limit
+	MaxDepth int
+	// MaxObjectKeys is the most members accepted in one object, counting
+	// repeated keys; 0 disables the limit
+	MaxObjectKeys int
+	// MaxArrayElements is the most elements accepted in one array; 0 disables the limit
+	MaxArrayElements int
+	// StrictMode rejects objects with duplicate keys and strings holding
+	// invalid UTF-8, both of which are otherwise accepted, and holds the
+	// input to RFC 8259 by overriding every Allow setting below, KeepComments
+	// and the comments and trailing commas a Dialect allows. A Dialect's
+	// token and value hooks still apply.
+	StrictMode bool
+	// AllowComments skips // line and /* block */ comments between tokens
+	AllowComments bool
+	// AllowTrailingCommas accepts a comma after the last member of an
+	// object or element of an array
+	AllowTrailingCommas bool
+	// AllowSingleQuotes accepts strings in single quotes, as in JavaScript
+	// object literals, with the same escapes as double-quoted ones and \'
+	// for a quote
+	AllowSingleQuotes bool
+	// AllowUnquotedKeys accepts object keys without quotes, as in {foo: 1}:
+	// words of letters, digits, _ and $ not starting with a digit. Values
+	// still need quotes.
+	AllowUnquotedKeys bool
+	// AllowNonFinite accepts NaN, Infinity and -Infinity as numbers, as
+	// Python's json module writes them; the Number keeps the literal
+	AllowNonFinite bool
+	// AllowHexNumbers accepts hexadecimal integers such as 0x1F and -0x10,
+	// as JSON5 does; the Number holds the value in decimal
+	AllowHexNumbers bool
+	// AllowLineContinuations accepts a backslash at the end of a line inside
+	// a string, as JSON5 does, so long values can be wrapped; the backslash
+	// and the line break stand for nothing
+	AllowLineContinuations bool
+	// Dialect, when set, extends the grammar with a dialect's tokens and values
+	Dialect *dialect.Dialect
+	// RecordSpans stores on every node the span of source text it was parsed
+	// from; see SpanOf
+	RecordSpans bool
+	// AllowRelaxedNumbers accepts numbers with a plus sign, leading zeros,
+	// or no digits on one side of the decimal point, as in +1, 007, .5 and
+	// 5., which the Number holds as the JSON numbers 1, 7, 0.5 and 5
+	AllowRelaxedNumbers bool
+	// AllowControlCharacters accepts tabs, raw line breaks and the other
+	// characters from U+0000 to U+001F inside strings, which RFC 8259
+	// requires escaped and the parser otherwise rejects, for reading files
+	// written by tools that do not escape them
+	AllowControlCharacters bool
+	// KeepComments attaches the comments in the source to the nodes around
+	// them, so a JSONC file can be edited and written back with them; see
+	// CommentsOf. It turns on AllowComments.
+	KeepComments bool
+	// LinkParents sets the Parent of every node, so that a node's Path can
+	// be found; see Link
+	LinkParents bool
+	// Recover carries on past syntax errors, resuming at the next comma or
+	// closing bracket, and reports them all in an ErrorList along with the
+	// part of the document that could be parsed
+	Recover bool
+	// BadValues puts a BadValue wherever Recover skipped a value, so the
+	// tree keeps the shape of the input and Parse always returns one for a
+	// syntax error. It implies Recover.
+	BadValues bool
+	// MaxErrors is the most errors Recover collects before it gives up,
+	// ending the ErrorList with a LimitError; 0 disables the limit
+	MaxErrors int
+	// StableErrors reports errors as *StableError, whose messages keep the
+	// same wording from release to release; see StableErrorsVersion
+	StableErrors bool
+}
+
+// Option changes one setting of a ParserConfig
+type Option func(*ParserConfig)
+
+// WithMaxDepth limits how deeply arrays and objects may nest
+func WithMaxDepth(depth int) Option {
+	return func(c *ParserConfig) { c.MaxDepth = depth }
+}
+
+// WithMaxObjectKeys limits how many members a single object may have
+func WithMaxObjectKeys(n int) Option {
+	return func(c *ParserConfig) { c.MaxObjectKeys = n }
+}
+
+// WithMaxArrayElements limits how many elements a single array may have
+func WithMaxArrayElements(n int) Option {
+	return func(c *ParserConfig) { c.MaxArrayElements = n }
+}
+
+// WithStrictMode enables or disables StrictMode
+func WithStrictMode(strict bool) Option {
+	return func(c *ParserConfig) { c.StrictMode = strict }
+}
+
+// WithAllowComments enables or disables AllowComments
+func WithAllowComments(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowComments = allow }
+}
+
+// WithAllowTrailingCommas enables or disables AllowTrailingCommas
+func WithAllowTrailingCommas(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowTrailingCommas = allow }
+}
+
+// WithAllowSingleQuotes enables or disables AllowSingleQuotes
+func WithAllowSingleQuotes(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowSingleQuotes = allow }
+}
+
+// WithAllowUnquotedKeys enables or disables AllowUnquotedKeys
+func WithAllowUnquotedKeys(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowUnquotedKeys = allow }
+}
+
+// WithAllowNonFinite enables or disables AllowNonFinite
+func WithAllowNonFinite(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowNonFinite = allow }
+}
+
+// WithAllowHexNumbers enables or disables AllowHexNumbers
+func WithAllowHexNumbers(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowHexNumbers = allow }
+}
+
+// WithAllowLineContinuations enables or disables AllowLineContinuations
+func WithAllowLineContinuations(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowLineContinuations = allow }
+}
+
+// WithDialect parses documents in the given dialect; nil selects standard JSON
+func WithDialect(d *dialect.Dialect) Option {
+	return func(c *ParserConfig) { c.Dialect = d }
+}
+
+// WithJSON5 parses documents as JSON5, selecting dialect.JSON5, or turns
+// that dialect off again
+func WithJSON5(enable bool) Option {
+	return func(c *ParserConfig) {
+		if enable {
+			c.Dialect = dialect.JSON5
+		} else if c.Dialect == dialect.JSON5 {
+			c.Dialect = nil
+		}
+	}
+}
+
+// WithSpans enables or disables RecordSpans
+func WithSpans(record bool) Option {
+	return func(c *ParserConfig) { c.RecordSpans = record }
+}
+
+// WithAllowControlCharacters enables or disables AllowControlCharacters
+func WithAllowControlCharacters(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowControlCharacters = allow }
+}
+
+// WithAllowRelaxedNumbers enables or disables AllowRelaxedNumbers
+func WithAllowRelaxedNumbers(allow bool) Option {
+	return func(c *ParserConfig) { c.AllowRelaxedNumbers = allow }
+}
+
+// WithKeepComments enables or disables KeepComments
+func WithKeepComments(keep bool) Option {
+	return func(c *ParserConfig) { c.KeepComments = keep }
+}
+
+// WithParents enables or disables LinkParents
+func WithParents(link bool) Option {
+	return func(c *ParserConfig) { c.LinkParents = link }
+}
+
+// WithRecovery enables or disables Recover
+func WithRecovery(recovery bool) Option {
+	return func(c *ParserConfig) { c.Recover = recovery }
+}
+
+// WithMaxErrors limits how many errors recovery collects before it gives up
+func WithMaxErrors(n int) Option {
+	return func(c *ParserConfig) { c.MaxErrors = n }
+}
+
+// WithBadValues enables or disables BadValues
+func WithBadValues(bad bool) Option {
+	return func(c *ParserConfig) { c.BadValues = bad }
+}
+
+// WithStableErrors enables or disables StableErrors
+func WithStableErrors(stable bool) Option {
+	return func(c *ParserConfig) { c.StableErrors = stable }
+}
+
+// newConfig applies opts to the default configuration
+func newConfig(opts []Option) ParserConfig {
+	var c ParserConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	c.Recover = c.Recover || c.BadValues
+	return c
+}
+
+// lexerOptions returns the settings that belong to the lexer
+func (c ParserConfig) lexerOptions() lexer.Options {
+	lenient := !c.StrictMode
+	opts := lexer.Options{
+		AllowComments:          (c.AllowComments || c.KeepComments) && lenient,
+		KeepComments:           c.KeepComments && lenient,
+		RejectInvalidUTF8:      c.StrictMode,
+		AllowSingleQuotes:      c.AllowSingleQuotes && lenient,
+		AllowUnquotedKeys:      c.AllowUnquotedKeys && lenient,
+		AllowNonFinite:         c.AllowNonFinite && lenient,
+		AllowHexNumbers:        c.AllowHexNumbers && lenient,
+		AllowLineContinuations: c.AllowLineContinuations && lenient,
+		AllowRelaxedNumbers:    c.AllowRelaxedNumbers && lenient,
+		AllowControlCharacters: c.AllowControlCharacters && lenient,
+		Recover:                c.Recover,
+	}
+	if c.Dialect != nil {
+		opts.AllowComments = opts.AllowComments || c.Dialect.AllowComments && lenient
+		opts.Hooks = c.Dialect.Tokens
+	}
+	return opts
+}
+
+// parserOptions returns the settings that belong to the parser
+func (c ParserConfig) parserOptions() parser.Options {
+	opts := parser.Options{
+		MaxDepth:            c.MaxDepth,
+		MaxObjectKeys:       c.MaxObjectKeys,
+		MaxArrayElements:    c.MaxArrayElements,
+		RejectDuplicateKeys: c.StrictMode,
+		RecordSpans:         c.RecordSpans,
+		LinkParents:         c.LinkParents,
+		Recover:             c.Recover,
+		BadValues:           c.BadValues,
+		MaxErrors:           c.MaxErrors,
+		AllowTrailingCommas: c.AllowTrailingCommas && !c.StrictMode,
+	}
+	if c.Dialect != nil {
+		opts.AllowTrailingCommas = opts.AllowTrailingCommas || c.Dialect.AllowTrailingCommas && !c.StrictMode
+		opts.Values = c.Dialect.Values
+	}
+	return opts
+}
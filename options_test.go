@@ -0,0 +1,222 @@
+package jsonparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/dialect"
+)
+
+func TestParse_Options(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		valid bool
+	}{
+		{"comments rejected by default", `{"a": 1 /* note */}`, nil, false},
+		{"comments allowed", "// config\n{\"a\": 1 /* note */}", []Option{WithAllowComments(true)}, true},
+		{"depth limit", `[[[1]]]`, []Option{WithMaxDepth(2)}, false},
+		{"within depth limit", `[[[1]]]`, []Option{WithMaxDepth(3)}, true},
+		{"duplicate keys", `{"a": 1, "a": 2}`, nil, true},
+		{"strict duplicate keys", `{"a": 1, "a": 2}`, []Option{WithStrictMode(true)}, false},
+		{"strict invalid UTF-8", "[\"\xff\"]", []Option{WithStrictMode(true)}, false},
+		{"Unicode spaces rejected", "[1,\v2,\u00a03]", nil, false},
+		{"strict Unicode spaces", "\v[1]", []Option{WithStrictMode(true)}, false},
+		{"key limit", `{"a": 1, "b": 2, "c": 3}`, []Option{WithMaxObjectKeys(2)}, false},
+		{"within key limit", `{"a": 1, "b": {"c": 3}}`, []Option{WithMaxObjectKeys(2)}, true},
+		{"element limit", `[1, 2, 3]`, []Option{WithMaxArrayElements(2)}, false},
+		{"within element limit", `[[1, 2], 3]`, []Option{WithMaxArrayElements(2)}, true},
+		{"later options win", `[[1]]`, []Option{WithMaxDepth(1), WithMaxDepth(0)}, true},
+		{"JSON5 rejected by default", `{a: 'b', c: 0x10,}`, nil, false},
+		{"trailing commas rejected by default", `{"a": [1, 2,],}`, nil, false},
+		{"trailing commas allowed", `{"a": [1, 2,],}`, []Option{WithAllowTrailingCommas(true)}, true},
+		{"trailing comma in empty array", `[,]`, []Option{WithAllowTrailingCommas(true)}, false},
+		{"trailing commas with comments", "{\"a\": 1, // last\n}", []Option{WithAllowTrailingCommas(true), WithAllowComments(true)}, true},
+		{"strict trailing commas", `[1, 2,]`, []Option{WithAllowTrailingCommas(true), WithStrictMode(true)}, false},
+		{"single quotes rejected by default", `{'a': 'b'}`, nil, false},
+		{"single quotes allowed", `{'a': 'it\'s', "b": "c"}`, []Option{WithAllowSingleQuotes(true)}, true},
+		{"unquoted keys rejected by default", `{foo: 1}`, nil, false},
+		{"unquoted keys allowed", `{foo: 1, "bar": {baz_2: [true]}}`, []Option{WithAllowUnquotedKeys(true)}, true},
+		{"unquoted values rejected", `{"foo": bar}`, []Option{WithAllowUnquotedKeys(true)}, false},
+		{"non-finite numbers rejected by default", `[NaN]`, nil, false},
+		{"non-finite numbers allowed", `{"a": NaN, "b": [Infinity, -Infinity]}`, []Option{WithAllowNonFinite(true)}, true},
+		{"strict non-finite numbers", `[NaN]`, []Option{WithAllowNonFinite(true), WithStrictMode(true)}, false},
+		{"hex numbers rejected by default", `[0x1F]`, nil, false},
+		{"hex numbers allowed", `{"mask": 0xFF, "offset": -0x10}`, []Option{WithAllowHexNumbers(true)}, true},
+		{"line continuations rejected by default", "[\"one \\\ntwo\"]", nil, false},
+		{"line continuations allowed", "{\"a\": \"one \\\ntwo \\\r\nthree\"}", []Option{WithAllowLineContinuations(true)}, true},
+		{"relaxed numbers rejected by default", `[+1]`, nil, false},
+		{"relaxed numbers allowed", `{"a": +1, "b": [007, .5, 5.]}`, []Option{WithAllowRelaxedNumbers(true)}, true},
+		{"strict relaxed numbers", `[.5]`, []Option{WithAllowRelaxedNumbers(true), WithStrictMode(true)}, false},
+		{"control characters rejected", "[\"a\tb\"]", nil, false},
+		{"control characters allowed", "[\"a\tb\nc\"]", []Option{WithAllowControlCharacters(true)}, true},
+		{"strict control characters", "[\"a\tb\"]", []Option{WithAllowControlCharacters(true), WithStrictMode(true)}, false},
+		{"comments kept", "// note\n[1]", []Option{WithKeepComments(true)}, true},
+		{"strict comments", "// note\n[1]", []Option{WithAllowComments(true), WithStrictMode(true)}, false},
+		{"strict kept comments", "// note\n[1]", []Option{WithKeepComments(true), WithStrictMode(true)}, false},
+		{"strict single quotes", `['a']`, []Option{WithAllowSingleQuotes(true), WithStrictMode(true)}, false},
+		{"strict unquoted keys", `{foo: 1}`, []Option{WithAllowUnquotedKeys(true), WithStrictMode(true)}, false},
+		{"strict hex numbers", `[0x1F]`, []Option{WithAllowHexNumbers(true), WithStrictMode(true)}, false},
+		{"strict line continuations", "[\"one \\\ntwo\"]", []Option{WithAllowLineContinuations(true), WithStrictMode(true)}, false},
+		{"strict dialect trailing commas", `[1, 2,]`, []Option{WithDialect(dialect.JSONC), WithStrictMode(true)}, false},
+		{"strict dialect comments", "// note\n[1]", []Option{WithDialect(dialect.JSONC), WithStrictMode(true)}, false},
+		{"JSON5", `{a: 'b', c: 0x10,}`, []Option{WithJSON5(true)}, true},
+		{"JSON5 turned off", `{a: 'b'}`, []Option{WithJSON5(true), WithJSON5(false)}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.input, tt.opts...)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: Parse expected valid=%v, got %v", tt.name, tt.valid, err)
+		}
+		if got := Valid([]byte(tt.input), tt.opts...); got != tt.valid {
+			t.Errorf("%s: Valid expected %v, got %v", tt.name, tt.valid, got)
+		}
+	}
+}
+
+func TestParse_DefaultMaxDepth(t *testing.T) {
+	deep := strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1)
+	if _, err := Parse(deep); err == nil || !strings.Contains(err.Error(), "maximum nesting depth") {
+		t.Errorf("expected the default depth limit to apply, got %v", err)
+	}
+	if _, err := Parse(deep, WithMaxDepth(-1)); err != nil {
+		t.Errorf("expected no limit with a negative depth, got %v", err)
+	}
+}
+
+func TestParse_LimitError(t *testing.T) {
+	flood := "{" + strings.Repeat(`"k": 0, `, 100) + `"k": 0}`
+	_, err := Parse(flood, WithMaxObjectKeys(100))
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected a *LimitError, got %v", err)
+	}
+	if limitErr.Limit != LimitObjectKeys || limitErr.Max != 100 || limitErr.Column != 802 {
+		t.Errorf("unexpected limit error %+v", limitErr)
+	}
+	if err := Validate([]byte(flood), WithMaxObjectKeys(100)); !errors.As(err, &limitErr) {
+		t.Errorf("expected Validate to return a *LimitError, got %v", err)
+	}
+}
+
+func TestParse_WithSpans(t *testing.T) {
+	input := `{"servers": [{"host": "a"}, {"host": "b"}]}`
+	root, err := Parse(input, WithSpans(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	host, err := Lookup(root, "/servers/1/host")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	span := SpanOf(host)
+	if got := input[span.Start.Offset:span.End.Offset]; got != `"b"` {
+		t.Errorf("expected the span to cover \"b\", got %q", got)
+	}
+	if span.Start.Line != 1 || span.Start.Column != 38 {
+		t.Errorf("unexpected start %+v", span.Start)
+	}
+}
+
+func TestParse_WithParents(t *testing.T) {
+	root, err := Parse(`{"users": [{"name": "ann"}, {"name": "bob", "admin": true}]}`, WithParents(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var paths []string
+	Inspect(root, func(node Value) bool {
+		if b, ok := node.(*Boolean); ok && b.Value == "true" {
+			paths = append(paths, b.Path())
+		}
+		return true
+	})
+	if len(paths) != 1 || paths[0] != "/users/1/admin" {
+		t.Errorf("expected [/users/1/admin], got %v", paths)
+	}
+}
+
+func TestParse_WithRecovery(t *testing.T) {
+	input := `{"name": "api", "port": 80 "tags": [tru, "b"], "debug": }`
+	root, err := Parse(input, WithRecovery(true))
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 3 {
+		t.Fatalf("expected three errors, got %v", err)
+	}
+	if !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected errors.Is(err, ErrSyntax)")
+	}
+	var syntaxErr *SyntaxError
+	if !errors.As(list[1], &syntaxErr) || syntaxErr.Column != 37 {
+		t.Errorf("expected the bad literal second, got %v", list[1])
+	}
+	if got := root.(*Object).String(); got != `{"name":"api","port":80,"tags":["b"]}` {
+		t.Errorf("unexpected recovered document %s", got)
+	}
+
+	if err := Validate([]byte(input), WithRecovery(true)); !errors.As(err, &list) || len(list) != 3 {
+		t.Errorf("expected Validate to report all three errors, got %v", err)
+	}
+	if _, err := Parse(input); errors.As(err, &list) {
+		t.Errorf("expected a single error without recovery, got %v", err)
+	}
+}
+
+func TestParse_WithMaxErrors(t *testing.T) {
+	input := "[" + strings.Repeat("x, ", 1000) + "1]"
+	_, err := Parse(input, WithRecovery(true), WithMaxErrors(10))
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 11 {
+		t.Fatalf("expected ten errors and the limit, got %d errors", len(list))
+	}
+	var limitErr *LimitError
+	if !errors.As(list[10], &limitErr) || limitErr.Limit != LimitErrors || ErrorCodeOf(limitErr) != CodeTooManyErrors {
+		t.Errorf("expected the list to end with the error limit, got %v", list[10])
+	}
+	if !strings.Contains(limitErr.Error(), "too many errors, stopped after 10") {
+		t.Errorf("unexpected message %q", limitErr.Error())
+	}
+	if err := Validate([]byte(input), WithRecovery(true), WithMaxErrors(10)); !errors.As(err, &list) || len(list) != 11 {
+		t.Errorf("expected Validate to stop at the limit too, got %d errors", len(list))
+	}
+}
+
+func TestParse_WithBadValues(t *testing.T) {
+	input := `{"name": "api", "port": 8O, "tags": ["a", @]}`
+	root, err := Parse(input, WithBadValues(true), WithParents(true))
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("expected two errors, got %v", err)
+	}
+
+	obj := root.(*Object)
+	port, ok := obj.Pairs["port"].(*BadValue)
+	if !ok {
+		t.Fatalf("expected a bad value for port, got %v", obj.Pairs["port"])
+	}
+	if got := input[port.Span.Start.Offset:port.Span.End.Offset]; got != "8O" {
+		t.Errorf("expected the bad value to span 8O, got %q", got)
+	}
+	if port.Err != list[0] || port.Path() != "/port" {
+		t.Errorf("expected the first error at /port, got %v at %q", port.Err, port.Path())
+	}
+	tags := obj.Pairs["tags"].(*Array)
+	if _, ok := tags.Elements[1].(*BadValue); !ok || len(tags.Elements) != 2 {
+		t.Errorf("expected the second tag to be a bad value, got %v", tags.Elements)
+	}
+	if _, err := Marshal(root); err == nil {
+		t.Error("expected a tree with bad values not to marshal")
+	}
+
+	if root, err := Parse(`tru`, WithBadValues(true)); err == nil || root == nil {
+		t.Errorf("expected a bad root value, got %v, %v", root, err)
+	}
+	if err := Validate([]byte(input), WithBadValues(true)); !errors.As(err, &list) || len(list) != 2 {
+		t.Errorf("expected Validate to recover too, got %v", err)
+	}
+}
@@ -0,0 +1,373 @@
+// Package paginate reads the items of a paginated JSON API as one stream,
+// following the link each page holds to the next.
+package paginate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Options says how to fetch pages and where to find the items and the link
+// to the next page in each. Items and Next are JSON Pointers into a page.
+type Options struct {
+	Client   *http.Client // nil selects http.DefaultClient
+	Header   http.Header  // sent with every request, such as an Authorization header
+	Items    string       // the array of items, such as "/data"; empty when the page is the array
+	Next     string       // the URL of the next page, such as "/links/next"; a page without one, or with null or "", is the last
+	MaxPages int          // stop after this many pages; 0 for no limit
+}
+
+// Stream reads the items of the pages one at a time, decoding each page as
+// it arrives so that only the current item is held in memory. Next links
+// may come before or after the items, and relative ones are resolved
+// against the page's URL.
+type Stream struct {
+	ctx   context.Context
+	opts  Options
+	url   *url.URL // the page being read, or the one to fetch next
+	seen  map[string]bool
+	pages int
+
+	body  io.ReadCloser
+	dec   *jsonparser.Decoder
+	stack []frame
+	next  string // the next link of the page being read
+
+	item ast.Value
+	err  error
+}
+
+// frame is an array or object open in the page being read
+type frame struct {
+	object    bool
+	items     bool   // the array holds the items
+	key       string // the key of the member being read
+	expectKey bool
+	index     int // the index of the element being read
+}
+
+// Open returns a stream of the items of the pages starting at rawURL. The
+// first page is fetched by the first call to Next.
+func (o Options) Open(ctx context.Context, rawURL string) *Stream {
+	s := &Stream{ctx: ctx, opts: o, seen: make(map[string]bool)}
+	s.url, s.err = url.Parse(rawURL)
+	if s.err == nil {
+		s.err = o.check()
+	}
+	if s.err != nil {
+		s.err = fmt.Errorf("paginate: %v", s.err)
+	}
+	return s
+}
+
+// check validates the pointers
+func (o Options) check() error {
+	for _, pointer := range []string{o.Items, o.Next} {
+		if _, err := ast.SplitPointer(pointer); err != nil {
+			return err
+		}
+	}
+	if o.Next == "" || o.Next == o.Items {
+		return fmt.Errorf("the next page link needs a pointer of its own")
+	}
+	return nil
+}
+
+// Next reads the next item, fetching pages as needed, and reports whether
+// there was one. It returns false at the end of the last page or on an
+// error, which Err returns.
+func (s *Stream) Next() bool {
+	s.item = nil
+	for s.err == nil {
+		if s.dec == nil && !s.fetch() {
+			return false
+		}
+		tok, err := s.dec.Token()
+		if err == io.EOF {
+			if !s.endPage() {
+				return false
+			}
+			continue
+		}
+		if err != nil {
+			s.fail(err)
+			return false
+		}
+		if s.token(tok) {
+			return true
+		}
+	}
+	return false
+}
+
+// Item returns the item Next read
+func (s *Stream) Item() ast.Value {
+	return s.item
+}
+
+// Err returns the error that stopped the stream, if any
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Pages returns how many pages have been fetched
+func (s *Stream) Pages() int {
+	return s.pages
+}
+
+// Close releases the page being read. It is needed only when the stream
+// is abandoned before Next returns false.
+func (s *Stream) Close() error {
+	if s.body == nil {
+		return nil
+	}
+	err := s.body.Close()
+	s.body, s.dec = nil, nil
+	return err
+}
+
+// fetch requests the page at s.url and starts decoding it
+func (s *Stream) fetch() bool {
+	if s.url == nil || s.opts.MaxPages > 0 && s.pages >= s.opts.MaxPages {
+		return false
+	}
+	link := s.url.String()
+	if s.seen[link] {
+		s.err = fmt.Errorf("paginate: %s was already fetched; the pages link in a loop", link)
+		return false
+	}
+	s.seen[link] = true
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, link, nil)
+	if err != nil {
+		s.err = fmt.Errorf("paginate: %v", err)
+		return false
+	}
+	for key, values := range s.opts.Header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	client := s.opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		s.err = fmt.Errorf("paginate: %v", err)
+		return false
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		s.err = fmt.Errorf("paginate: GET %s: %s", link, resp.Status)
+		return false
+	}
+	s.pages++
+	s.body, s.dec, s.stack, s.next = resp.Body, jsonparser.NewDecoder(resp.Body), nil, ""
+	return true
+}
+
+// endPage finishes the page just read and moves on to the next one
+func (s *Stream) endPage() bool {
+	if err := s.Close(); err != nil {
+		s.err = fmt.Errorf("paginate: %v", err)
+		return false
+	}
+	if s.next == "" {
+		s.url = nil
+		return false
+	}
+	next, err := s.url.Parse(s.next)
+	if err != nil {
+		s.err = fmt.Errorf("paginate: next page %q: %v", s.next, err)
+		return false
+	}
+	s.url = next
+	return true
+}
+
+func (s *Stream) fail(err error) {
+	s.err = fmt.Errorf("paginate: %s: %w", s.url, err)
+	s.Close()
+}
+
+// token handles one token of the page, and reports whether it began an item,
+// which it then reads whole
+func (s *Stream) token(tok jsonparser.Token) bool {
+	top := len(s.stack) - 1
+	if top >= 0 {
+		f := &s.stack[top]
+		switch {
+		case tok.Type == jsonparser.TokenRightBrace || tok.Type == jsonparser.TokenRightBracket:
+			s.stack = s.stack[:top]
+			s.valueDone()
+			return false
+		case f.object && f.expectKey:
+			f.key, f.expectKey = tok.Literal, false
+			return false
+		case f.items:
+			item, err := s.build(tok)
+			if err != nil {
+				s.fail(err)
+				return false
+			}
+			ast.Link(item)
+			s.item = item
+			s.valueDone()
+			return true
+		}
+	}
+
+	pointer := s.pointer()
+	switch {
+	case pointer == s.opts.Next:
+		if err := s.link(tok); err != nil {
+			s.fail(err)
+			return false
+		}
+		s.valueDone()
+	case tok.Type == jsonparser.TokenLeftBracket && pointer == s.opts.Items:
+		s.stack = append(s.stack, frame{items: true})
+	case pointer == s.opts.Items && tok.Type == jsonparser.TokenNull:
+		s.valueDone() // a page with no items
+	case pointer == s.opts.Items:
+		s.fail(fmt.Errorf("the items at %s are not an array", display(pointer)))
+	case tok.Type == jsonparser.TokenLeftBrace || tok.Type == jsonparser.TokenLeftBracket:
+		if !within(pointer, s.opts.Items) && !within(pointer, s.opts.Next) {
+			if err := s.skip(); err != nil {
+				s.fail(err)
+			}
+			s.valueDone()
+			return false
+		}
+		s.stack = append(s.stack, frame{object: tok.Type == jsonparser.TokenLeftBrace, expectKey: true})
+	default:
+		s.valueDone()
+	}
+	return false
+}
+
+// pointer returns the JSON Pointer of the value about to be read
+func (s *Stream) pointer() string {
+	segments := make([]string, len(s.stack))
+	for i, f := range s.stack {
+		if f.object {
+			segments[i] = f.key
+		} else {
+			segments[i] = strconv.Itoa(f.index)
+		}
+	}
+	return ast.FormatPointer(segments)
+}
+
+// valueDone moves the innermost open container past the value just read
+func (s *Stream) valueDone() {
+	if top := len(s.stack) - 1; top >= 0 {
+		if s.stack[top].object {
+			s.stack[top].expectKey = true
+		} else {
+			s.stack[top].index++
+		}
+	}
+}
+
+// link records the next page's URL from the token at the Next pointer
+func (s *Stream) link(tok jsonparser.Token) error {
+	switch tok.Type {
+	case jsonparser.TokenString:
+		s.next = tok.Literal
+	case jsonparser.TokenNull:
+		s.next = ""
+	default:
+		return fmt.Errorf("the next page link at %s is not a string", display(s.opts.Next))
+	}
+	return nil
+}
+
+// build reads the value that begins with tok
+func (s *Stream) build(tok jsonparser.Token) (ast.Value, error) {
+	switch tok.Type {
+	case jsonparser.TokenLeftBrace:
+		obj := &ast.Object{}
+		for {
+			key, err := s.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if key.Type == jsonparser.TokenRightBrace {
+				return obj, nil
+			}
+			first, err := s.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.build(first)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key.Literal, v)
+		}
+	case jsonparser.TokenLeftBracket:
+		array := &ast.Array{Elements: []ast.Value{}}
+		for {
+			first, err := s.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if first.Type == jsonparser.TokenRightBracket {
+				return array, nil
+			}
+			v, err := s.build(first)
+			if err != nil {
+				return nil, err
+			}
+			array.Elements = append(array.Elements, v)
+		}
+	case jsonparser.TokenString:
+		return &ast.String{Value: tok.Literal}, nil
+	case jsonparser.TokenNumber:
+		return &ast.Number{Value: tok.Literal}, nil
+	case jsonparser.TokenTrue, jsonparser.TokenFalse:
+		return &ast.Boolean{Value: tok.Literal}, nil
+	}
+	return &ast.Null{}, nil
+}
+
+// skip reads past the rest of the array or object just opened
+func (s *Stream) skip() error {
+	for depth := 1; depth > 0; {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok.Type {
+		case jsonparser.TokenLeftBrace, jsonparser.TokenLeftBracket:
+			depth++
+		case jsonparser.TokenRightBrace, jsonparser.TokenRightBracket:
+			depth--
+		}
+	}
+	return nil
+}
+
+// within reports whether the value at pointer holds the one at target
+func within(pointer, target string) bool {
+	return strings.HasPrefix(target, pointer+"/")
+}
+
+// display returns a pointer for a message, quoting the root's empty one
+func display(pointer string) string {
+	if pointer == "" {
+		return `""`
+	}
+	return pointer
+}
@@ -0,0 +1,133 @@
+package paginate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+)
+
+// serve starts a server answering each path with its page
+func serve(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		page, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, strings.ReplaceAll(page, "SERVER", "http://"+r.Host))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// collect reads the whole stream, returning the items as compact JSON
+func collect(t *testing.T, s *Stream) ([]string, error) {
+	t.Helper()
+	var items []string
+	for s.Next() {
+		data, err := encoder.Marshal(s.Item())
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, string(data))
+	}
+	return items, s.Err()
+}
+
+var auth = http.Header{"Authorization": {"Bearer token"}}
+
+func TestStream(t *testing.T) {
+	srv := serve(t, map[string]string{
+		// The link comes after the items, is relative, and skipped values hold arrays
+		"/items":         `{"data": [{"id": 1, "tags": ["a"]}, {"id": 2}], "meta": {"counts": [1, 2]}, "links": {"next": "/items?page=2"}}`,
+		"/items?page=2":  `{"links": {"self": "x", "next": "SERVER/items?page=3"}, "data": [3, "four"]}`,
+		"/items?page=3":  `{"data": [], "links": {"next": null}}`,
+		"/array":         `[1, 2]`,
+		"/nested":        `{"result": {"page": {"items": [{"a": [1, {"b": null}]}]}, "cursor": [{"next": "/nested2"}]}}`,
+		"/nested2":       `{"result": {"page": {"items": null}, "cursor": [{"next": ""}]}}`,
+		"/loop":          `{"data": [1], "next": "/loop"}`,
+		"/bad":           `{"data": {"id": 1}}`,
+		"/truncated":     `{"data": [1, 2`,
+		"/missing-items": `{"next": "/array"}`,
+	})
+
+	s := Options{Header: auth, Items: "/data", Next: "/links/next"}.Open(context.Background(), srv.URL+"/items")
+	items, err := collect(t, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`{"id":1,"tags":["a"]}`, `{"id":2}`, `3`, `"four"`}; fmt.Sprint(items) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, items)
+	}
+	if s.Pages() != 3 {
+		t.Errorf("expected 3 pages, got %d", s.Pages())
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		path    string
+		want    string
+		wantErr string
+	}{
+		{"root array", Options{Next: "/next"}, "/array", "[1 2]", ""},
+		{"nested", Options{Items: "/result/page/items", Next: "/result/cursor/0/next"}, "/nested", `[{"a":[1,{"b":null}]}]`, ""},
+		{"page limit", Options{Items: "/data", Next: "/links/next", MaxPages: 1}, "/items", `[{"id":1,"tags":["a"]} {"id":2}]`, ""},
+		{"missing items", Options{Items: "/data", Next: "/next"}, "/missing-items", "[]", ""},
+		{"loop", Options{Items: "/data", Next: "/next"}, "/loop", "[1]", "was already fetched; the pages link in a loop"},
+		{"not an array", Options{Items: "/data", Next: "/next"}, "/bad", "[]", "the items at /data are not an array"},
+		{"truncated", Options{Items: "/data", Next: "/next"}, "/truncated", "[1 2]", "got end of input"},
+		{"status", Options{Items: "/data", Next: "/next"}, "/gone", "[]", "404 Not Found"},
+		{"same pointers", Options{Items: "/data", Next: "/data"}, "/items", "[]", "paginate: the next page link needs a pointer of its own"},
+		{"bad pointer", Options{Items: "data", Next: "/next"}, "/items", "[]", `paginate: invalid path "data"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Header = auth
+			items, err := collect(t, tt.opts.Open(context.Background(), srv.URL+tt.path))
+			if got := fmt.Sprint(items); got != tt.want {
+				t.Errorf("expected items %s, got %s", tt.want, got)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStream_Context(t *testing.T) {
+	srv := serve(t, map[string]string{"/": `[1]`})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := Options{Header: auth, Next: "/next"}.Open(ctx, srv.URL+"/")
+	if s.Next() {
+		t.Fatal("expected no items from a cancelled request")
+	}
+	if err := s.Err(); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+}
+
+func TestStream_Close(t *testing.T) {
+	srv := serve(t, map[string]string{"/": `[1, 2, 3]`})
+	s := Options{Header: auth, Next: "/next"}.Open(context.Background(), srv.URL+"/")
+	if !s.Next() {
+		t.Fatalf("expected an item, got error %v", s.Err())
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
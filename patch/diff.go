@@ -0,0 +1,162 @@
+package patch
+
+import (
+	"strconv"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// ArrayStrategy decides how Diff matches up the elements of two arrays
+type ArrayStrategy int
+
+// Array strategies
+const (
+	ByIndex ArrayStrategy = iota // Elements at the same index are compared, and the longer array's extra ones added or removed at the end
+	LCS                          // The longest common subsequence is kept, and other elements removed, added or replaced where they are
+)
+
+// DiffOptions configures Diff
+type DiffOptions struct {
+	Arrays ArrayStrategy
+}
+
+// Diff returns a patch that turns from into to, comparing arrays by index;
+// see DiffOptions.Diff
+func Diff(from, to ast.Value) Patch {
+	return DiffOptions{}.Diff(from, to)
+}
+
+// Diff returns a patch of add, remove and replace operations that turns from
+// into to, descending into the objects and arrays both hold so that only
+// what changed is replaced. Key order and the format of numbers are not
+// differences. The values in the patch are copies, so neither document is
+// shared with it. LCS takes time and memory proportional to the product of
+// the lengths of the arrays it compares.
+func (o DiffOptions) Diff(from, to ast.Value) Patch {
+	d := differ{opts: o, patch: Patch{}}
+	d.diff("", from, to)
+	return d.patch
+}
+
+// differ collects the operations of a diff
+type differ struct {
+	opts  DiffOptions
+	patch Patch
+}
+
+// same is the equality a patch does not need to change
+var same = ast.EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}
+
+func (d *differ) diff(pointer string, from, to ast.Value) {
+	switch from := from.(type) {
+	case *ast.Object:
+		if to, ok := to.(*ast.Object); ok {
+			d.objects(pointer, from, to)
+			return
+		}
+	case *ast.Array:
+		if to, ok := to.(*ast.Array); ok {
+			if d.opts.Arrays == LCS {
+				d.lcs(pointer, from.Elements, to.Elements)
+			} else {
+				d.byIndex(pointer, from.Elements, to.Elements)
+			}
+			return
+		}
+	}
+	if !same.Equal(from, to) {
+		d.add("replace", pointer, to)
+	}
+}
+
+func (d *differ) add(op, pointer string, v ast.Value) {
+	d.patch = append(d.patch, Operation{Op: op, Path: pointer, Value: ast.Clone(v)})
+}
+
+func (d *differ) remove(pointer string) {
+	d.patch = append(d.patch, Operation{Op: "remove", Path: pointer})
+}
+
+func (d *differ) objects(pointer string, from, to *ast.Object) {
+	for _, key := range from.OrderedKeys() {
+		child := pointer + ast.FormatPointer([]string{key})
+		if value, ok := to.Pairs[key]; ok {
+			d.diff(child, from.Pairs[key], value)
+		} else {
+			d.remove(child)
+		}
+	}
+	for _, key := range to.OrderedKeys() {
+		if _, ok := from.Pairs[key]; !ok {
+			d.add("add", pointer+ast.FormatPointer([]string{key}), to.Pairs[key])
+		}
+	}
+}
+
+func (d *differ) byIndex(pointer string, from, to []ast.Value) {
+	n := min(len(from), len(to))
+	for i := 0; i < n; i++ {
+		d.diff(pointer+"/"+strconv.Itoa(i), from[i], to[i])
+	}
+	for i := n; i < len(to); i++ {
+		d.add("add", pointer+"/"+strconv.Itoa(i), to[i])
+	}
+	// From the end, so the indexes of those still to go stay the same
+	for i := len(from) - 1; i >= n; i-- {
+		d.remove(pointer + "/" + strconv.Itoa(i))
+	}
+}
+
+func (d *differ) lcs(pointer string, from, to []ast.Value) {
+	// common[i][j] is the length of the longest common subsequence of
+	// from[i:] and to[j:]
+	common := make([][]int, len(from)+1)
+	for i := range common {
+		common[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if same.Equal(from[i], to[j]) {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table, collecting the elements between common ones and
+	// turning each run of them into replacements, then removals or
+	// additions. at is the index in the array as the operations so far have
+	// left it.
+	var removed, added []int
+	at := 0
+	flush := func() {
+		n := min(len(removed), len(added))
+		for k := 0; k < n; k++ {
+			d.diff(pointer+"/"+strconv.Itoa(at), from[removed[k]], to[added[k]])
+			at++
+		}
+		for range removed[n:] {
+			d.remove(pointer + "/" + strconv.Itoa(at))
+		}
+		for _, j := range added[n:] {
+			d.add("add", pointer+"/"+strconv.Itoa(at), to[j])
+			at++
+		}
+		removed, added = removed[:0], added[:0]
+	}
+	for i, j := 0, 0; i < len(from) || j < len(to); {
+		switch {
+		case i < len(from) && j < len(to) && same.Equal(from[i], to[j]):
+			flush()
+			i, j, at = i+1, j+1, at+1
+		case j == len(to) || i < len(from) && common[i+1][j] >= common[i][j+1]:
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+}
@@ -0,0 +1,84 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		from, to string
+		opts     DiffOptions
+		want     string
+	}{
+		{`{"a": 1, "b": 2}`, `{"b": 2.0, "a": 1}`, DiffOptions{}, `[]`},
+		{`{"a": 1, "b": {"c": 2, "d": 3}}`, `{"b": {"c": 4}, "e": [1]}`, DiffOptions{},
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/c","value":4},{"op":"remove","path":"/b/d"},{"op":"add","path":"/e","value":[1]}]`},
+		{`{"a/b": 1, "m~n": 2}`, `{"a/b": 3}`, DiffOptions{},
+			`[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`},
+		{`[1, 2, 3]`, `[1, 5]`, DiffOptions{},
+			`[{"op":"replace","path":"/1","value":5},{"op":"remove","path":"/2"}]`},
+		{`[1, 2]`, `[1, 2, 3, 4]`, DiffOptions{},
+			`[{"op":"add","path":"/2","value":3},{"op":"add","path":"/3","value":4}]`},
+		{`[1, 2, 3]`, `[0, 1, 2, 3]`, DiffOptions{},
+			`[{"op":"replace","path":"/0","value":0},{"op":"replace","path":"/1","value":1},{"op":"replace","path":"/2","value":2},{"op":"add","path":"/3","value":3}]`},
+		{`[1, 2, 3]`, `[0, 1, 2, 3]`, DiffOptions{Arrays: LCS}, `[{"op":"add","path":"/0","value":0}]`},
+		{`[1, 2, 3, 4]`, `[1, 4]`, DiffOptions{Arrays: LCS},
+			`[{"op":"remove","path":"/1"},{"op":"remove","path":"/1"}]`},
+		{`["a", {"x": 1}, "c"]`, `["a", {"x": 2}, "c", "d"]`, DiffOptions{Arrays: LCS},
+			`[{"op":"replace","path":"/1/x","value":2},{"op":"add","path":"/3","value":"d"}]`},
+		{`{"a": [1]}`, `{"a": {"0": 1}}`, DiffOptions{}, `[{"op":"replace","path":"/a","value":{"0":1}}]`},
+		{`1`, `"one"`, DiffOptions{}, `[{"op":"replace","path":"","value":"one"}]`},
+	}
+	for _, tt := range tests {
+		p := tt.opts.Diff(mustParse(t, tt.from), mustParse(t, tt.to))
+		if got := mustMarshal(t, p.Value()); got != tt.want {
+			t.Errorf("%s to %s: expected %s, got %s", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+func TestDiff_RoundTrip(t *testing.T) {
+	tests := []struct{ from, to string }{
+		{`{"a": [1, 2, 3, 4, 5], "b": {"c": [true]}}`, `{"a": [2, 9, 4, 6, 5, 7], "b": {"c": [false, true]}, "d": null}`},
+		{`[[1, 2], [3], 4]`, `[[1], 4, [3, 5]]`},
+		{`["x", "y", "x", "y"]`, `["y", "x", "y", "x"]`},
+		{`[]`, `[{"a": 1}]`},
+		{`[1, 2, 3]`, `[]`},
+	}
+	for _, opts := range []DiffOptions{{Arrays: ByIndex}, {Arrays: LCS}} {
+		for _, tt := range tests {
+			from, to := mustParse(t, tt.from), mustParse(t, tt.to)
+			p := opts.Diff(from, to)
+			got, err := p.Apply(from)
+			if err != nil {
+				t.Errorf("%v, %s to %s: unexpected error: %v", opts.Arrays, tt.from, tt.to, err)
+				continue
+			}
+			if !ast.Equal(got, to) {
+				t.Errorf("%v, %s to %s: patch %s gave %s", opts.Arrays, tt.from, tt.to, mustMarshal(t, p.Value()), mustMarshal(t, got))
+			}
+		}
+	}
+}
+
+func TestPatch_MarshalJSON(t *testing.T) {
+	p := Patch{
+		{Op: "move", From: "/a", Path: "/b"},
+		{Op: "add", Path: "/c", Value: &ast.Null{}},
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `[{"op":"move","from":"/a","path":"/b"},{"op":"add","path":"/c","value":null}]`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+	parsed, err := Parse(mustParse(t, want))
+	if err != nil || len(parsed) != 2 || parsed[0].From != "/a" {
+		t.Errorf("expected the encoded patch to parse back, got %v, %v", parsed, err)
+	}
+}
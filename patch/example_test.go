@@ -0,0 +1,33 @@
+package patch_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/patch"
+)
+
+func ExamplePatch_Apply() {
+	p := patch.MustParse(jsonparser.MustParse(`[
+		{"op": "replace", "path": "/name", "value": "Grace"},
+		{"op": "add", "path": "/tags/-", "value": "navy"}
+	]`))
+	doc, err := p.Apply(jsonparser.MustParse(`{"name": "Ada", "tags": ["math"]}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(doc)
+	// Output: {"name":"Grace","tags":["math","navy"]}
+}
+
+func ExampleDiff() {
+	from := jsonparser.MustParse(`{"a": 1, "b": [1, 2]}`)
+	to := jsonparser.MustParse(`{"a": 2, "b": [1]}`)
+	data, err := patch.Diff(from, to).MarshalJSON()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+	// Output: [{"op":"replace","path":"/a","value":2},{"op":"remove","path":"/b/1"}]
+}
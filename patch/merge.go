@@ -0,0 +1,33 @@
+package patch
+
+import "github.com/letsmakecakes/jsonparser/internal/ast"
+
+// Merge applies a JSON Merge Patch (RFC 7386) to a copy of target and
+// returns it, as most REST PATCH endpoints take them: members of an object
+// patch are merged into the target recursively, a null member removes the
+// member of that name, and any other patch value replaces the target
+// outright. target itself is left alone, so it may be frozen.
+func Merge(target, patch ast.Value) ast.Value {
+	return mergeInto(ast.Clone(target), patch)
+}
+
+// mergeInto merges patch into target, which it may change
+func mergeInto(target, patch ast.Value) ast.Value {
+	obj, ok := patch.(*ast.Object)
+	if !ok {
+		return ast.Clone(patch)
+	}
+	result, ok := target.(*ast.Object)
+	if !ok {
+		result = &ast.Object{Pairs: make(map[string]ast.Value)}
+	}
+	for _, key := range obj.OrderedKeys() {
+		value := obj.Pairs[key]
+		if _, ok := value.(*ast.Null); ok {
+			result.Delete(key)
+			continue
+		}
+		result.Set(key, mergeInto(result.Pairs[key], value))
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+func TestMerge(t *testing.T) {
+	// The examples of RFC 7386 appendix A
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		target := mustParse(t, tt.target)
+		ast.Freeze(target)
+		got := Merge(target, mustParse(t, tt.patch))
+		if mustMarshal(t, got) != tt.want {
+			t.Errorf("%s with %s: expected %s, got %s", tt.target, tt.patch, tt.want, mustMarshal(t, got))
+		}
+		if mustMarshal(t, target) != mustMarshal(t, mustParse(t, tt.target)) {
+			t.Errorf("%s with %s: expected the target to be left alone", tt.target, tt.patch)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Package patch applies JSON Patch (RFC 6902) documents to parsed JSON.
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+)
+
+// ErrTestFailed is wrapped by the error of a test operation whose value did
+// not match
+var ErrTestFailed = errors.New("test failed")
+
+// Operation is one step of a patch. Path and From are JSON Pointers.
+type Operation struct {
+	Op    string    // "add", "remove", "replace", "move", "copy" or "test"
+	Path  string    // the location the operation changes or tests
+	From  string    // the location move and copy take the value from
+	Value ast.Value // the value add, replace and test use
+}
+
+// Patch is a sequence of operations, applied in order
+type Patch []Operation
+
+// Error is an operation that is malformed or could not be applied
+type Error struct {
+	Index int    // position of the operation in the patch
+	Op    string // the operation, when it has a valid one
+	Err   error
+}
+
+func (e *Error) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("patch: operation %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("patch: operation %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Parse reads a patch from a parsed JSON Patch document: an array of
+// objects such as {"op": "add", "path": "/a", "value": 1}. Members that
+// RFC 6902 does not define are ignored.
+func Parse(doc ast.Value) (Patch, error) {
+	array, ok := doc.(*ast.Array)
+	if !ok {
+		return nil, fmt.Errorf("patch: a patch must be an array of operations")
+	}
+	p := make(Patch, len(array.Elements))
+	for i, elem := range array.Elements {
+		obj, ok := elem.(*ast.Object)
+		if !ok {
+			return nil, &Error{Index: i, Err: fmt.Errorf("an operation must be an object")}
+		}
+		op, err := stringMember(obj, "op")
+		if err != nil {
+			return nil, &Error{Index: i, Err: err}
+		}
+		p[i] = Operation{Op: op, Value: obj.Pairs["value"]}
+		if p[i].Path, err = stringMember(obj, "path"); err != nil {
+			return nil, &Error{Index: i, Op: op, Err: err}
+		}
+		if _, ok := obj.Pairs["from"]; ok || op == "move" || op == "copy" {
+			if p[i].From, err = stringMember(obj, "from"); err != nil {
+				return nil, &Error{Index: i, Op: op, Err: err}
+			}
+		}
+		if err := p[i].validate(); err != nil {
+			return nil, &Error{Index: i, Op: p[i].errorOp(), Err: err}
+		}
+	}
+	return p, nil
+}
+
+// MustParse is like Parse but panics when doc is not a valid patch
+func MustParse(doc ast.Value) Patch {
+	p, err := Parse(doc)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// stringMember returns the string member key of obj
+func stringMember(obj *ast.Object, key string) (string, error) {
+	v, ok := obj.Pairs[key]
+	if !ok {
+		return "", fmt.Errorf("missing %q", key)
+	}
+	s, ok := v.(*ast.String)
+	if !ok {
+		return "", fmt.Errorf("%q must be a string", key)
+	}
+	return s.Value, nil
+}
+
+// Value returns the patch as a JSON Patch document, the form Parse reads
+func (p Patch) Value() ast.Value {
+	doc := &ast.Array{Elements: make([]ast.Value, len(p))}
+	for i, op := range p {
+		obj := &ast.Object{}
+		obj.Set("op", &ast.String{Value: op.Op})
+		if op.Op == "move" || op.Op == "copy" {
+			obj.Set("from", &ast.String{Value: op.From})
+		}
+		obj.Set("path", &ast.String{Value: op.Path})
+		if op.Value != nil {
+			obj.Set("value", op.Value)
+		}
+		doc.Elements[i] = obj
+	}
+	return doc
+}
+
+// MarshalJSON encodes the patch as a JSON Patch document
+func (p Patch) MarshalJSON() ([]byte, error) {
+	return ast.AppendJSON(nil, p.Value())
+}
+
+// Apply applies the operations in order to a copy of doc and returns it.
+// doc itself is left alone, so it may be frozen, and a patch that fails
+// part way changes nothing.
+func (p Patch) Apply(doc ast.Value) (ast.Value, error) {
+	doc = ast.Clone(doc)
+	for i, op := range p {
+		if err := op.validate(); err != nil {
+			return nil, &Error{Index: i, Op: op.errorOp(), Err: err}
+		}
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, &Error{Index: i, Op: op.Op, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+// validate checks that the operation is complete and its pointers well formed
+func (op Operation) validate() error {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("missing \"value\"")
+		}
+	case "move", "copy":
+		if _, err := ast.SplitPointer(op.From); err != nil {
+			return fmt.Errorf("from: %v", err)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	if _, err := ast.SplitPointer(op.Path); err != nil {
+		return fmt.Errorf("path: %v", err)
+	}
+	return nil
+}
+
+// errorOp returns the operation's name for an error, when it is a known one
+func (op Operation) errorOp() string {
+	switch op.Op {
+	case "add", "remove", "replace", "move", "copy", "test":
+		return op.Op
+	}
+	return ""
+}
+
+// apply runs the operation against doc, returning the document, which is a
+// new value when the root was replaced
+func (op Operation) apply(doc ast.Value) (ast.Value, error) {
+	switch op.Op {
+	case "add":
+		return add(doc, op.Path, ast.Clone(op.Value))
+	case "remove":
+		if op.Path == "" {
+			return nil, fmt.Errorf("cannot remove the root value")
+		}
+		return doc, ast.DeletePath(doc, op.Path)
+	case "replace":
+		if _, err := ast.Lookup(doc, op.Path); err != nil {
+			return nil, err
+		}
+		if op.Path == "" {
+			return ast.Clone(op.Value), nil
+		}
+		return doc, ast.SetPath(doc, op.Path, ast.Clone(op.Value))
+	case "move":
+		v, err := ast.Lookup(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Path == op.From {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %q into itself at %q", op.From, op.Path)
+		}
+		if err := ast.DeletePath(doc, op.From); err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, v)
+	case "copy":
+		v, err := ast.Lookup(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, ast.Clone(v))
+	default: // test
+		v, err := ast.Lookup(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !(ast.EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}).Equal(v, op.Value) {
+			return nil, fmt.Errorf("%w: the value at %q is %s, not %s", ErrTestFailed, op.Path, describe(v), describe(op.Value))
+		}
+		return doc, nil
+	}
+}
+
+// add inserts v at path as the add operation does, replacing the whole
+// document when path is the root
+func add(doc ast.Value, path string, v ast.Value) (ast.Value, error) {
+	if path == "" {
+		return v, nil
+	}
+	return doc, ast.InsertPath(doc, path, v)
+}
+
+// describe returns v as compact JSON for an error message, cut short when long
+func describe(v ast.Value) string {
+	data, err := encoder.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%T", v)
+	}
+	if text := []rune(string(data)); len(text) > 40 {
+		return string(text[:37]) + "..."
+	}
+	return string(data)
+}
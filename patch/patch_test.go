@@ -0,0 +1,169 @@
+package patch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func mustMarshal(t *testing.T, value ast.Value) string {
+	t.Helper()
+	data, err := encoder.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	return string(data)
+}
+
+func TestPatch_Apply(t *testing.T) {
+	// Mostly the examples of RFC 6902 appendix A
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`, `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz": "qux", "foo": ["a", 2, "c"]}`, `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/":9,"~1":10}`},
+		{`{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{`{"a": {"x": 1, "y": 2}}`, `[{"op": "test", "path": "/a", "value": {"y": 2, "x": 1}}]`, `{"a":{"x":1,"y":2}}`},
+		{`{"a": 1}`, `[{"op": "move", "from": "/a", "path": "/a"}]`, `{"a":1}`},
+		{`{"a": 1}`, `[{"op": "replace", "path": "", "value": [1]}, {"op": "add", "path": "/-", "value": 2}]`, `[1,2]`},
+		{`{"a": {"b": 1}}`, `[{"op": "move", "from": "/a", "path": ""}]`, `{"b":1}`},
+		{`[1, 2]`, `[]`, `[1,2]`},
+	}
+	for _, tt := range tests {
+		p, err := Parse(mustParse(t, tt.patch))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.patch, err)
+			continue
+		}
+		doc := mustParse(t, tt.doc)
+		before := mustMarshal(t, doc)
+		got, err := p.Apply(doc)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.patch, err)
+			continue
+		}
+		if mustMarshal(t, got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.patch, tt.want, mustMarshal(t, got))
+		}
+		if mustMarshal(t, doc) != before {
+			t.Errorf("%s: expected the input to be left alone, got %s", tt.patch, mustMarshal(t, doc))
+		}
+	}
+}
+
+func TestPatch_ApplyErrors(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, `patch: operation 0 (test): test failed: the value at "/baz" is "qux", not "bar"`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, `patch: operation 0 (add): path "/baz/bat": no member "baz" at "/baz"`},
+		{`{"foo": "bar"}`, `[{"op": "remove", "path": "/qux"}]`, `no member "qux"`},
+		{`{"foo": "bar"}`, `[{"op": "replace", "path": "/qux", "value": 1}]`, `no member "qux"`},
+		{`{"foo": [1]}`, `[{"op": "add", "path": "/foo/2", "value": 1}]`, `array index 2 out of range`},
+		{`{"foo": [1]}`, `[{"op": "add", "path": "/foo/01", "value": 1}]`, `invalid array index "01"`},
+		{`{"a": {"b": 1}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`, `cannot move "/a" into itself`},
+		{`{"a": 1}`, `[{"op": "copy", "from": "/b", "path": "/c"}]`, `no member "b"`},
+		{`{"a": 1}`, `[{"op": "remove", "path": ""}]`, `cannot remove the root value`},
+		{`{"a": 1}`, `[{"op": "add", "path": "/b", "value": 1}, {"op": "test", "path": "/a", "value": 2}]`, `patch: operation 1 (test)`},
+	}
+	for _, tt := range tests {
+		p, err := Parse(mustParse(t, tt.patch))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.patch, err)
+			continue
+		}
+		doc := mustParse(t, tt.doc)
+		before := mustMarshal(t, doc)
+		if _, err := p.Apply(doc); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.patch, tt.want, err)
+		}
+		if mustMarshal(t, doc) != before {
+			t.Errorf("%s: expected a failed patch to change nothing, got %s", tt.patch, mustMarshal(t, doc))
+		}
+	}
+
+	p, _ := Parse(mustParse(t, `[{"op": "test", "path": "/a", "value": 2}]`))
+	_, err := p.Apply(mustParse(t, `{"a": 1}`))
+	var perr *Error
+	if !errors.Is(err, ErrTestFailed) || !errors.As(err, &perr) || perr.Index != 0 {
+		t.Errorf("expected a test failure of operation 0, got %v", err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		`{"op": "add"}`:                               "a patch must be an array of operations",
+		`[1]`:                                         "patch: operation 0: an operation must be an object",
+		`[{"path": "/a"}]`:                            `missing "op"`,
+		`[{"op": "jump", "path": "/a"}]`:              `patch: operation 0: unknown op "jump"`,
+		`[{"op": "add", "path": "/a"}]`:               `patch: operation 0 (add): missing "value"`,
+		`[{"op": "add", "value": 1}]`:                 `missing "path"`,
+		`[{"op": "remove", "path": 1}]`:               `"path" must be a string`,
+		`[{"op": "remove", "path": "a"}]`:             "path:",
+		`[{"op": "move", "path": "/a"}]`:              `missing "from"`,
+		`[{"op": "copy", "from": "b", "path": "/a"}]`: "from:",
+	}
+	for input, want := range tests {
+		if _, err := Parse(mustParse(t, input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", input, want, err)
+		}
+	}
+
+	p := Patch{{Op: "replace", Path: "/a"}}
+	if _, err := p.Apply(mustParse(t, `{"a": 1}`)); err == nil || !strings.Contains(err.Error(), `missing "value"`) {
+		t.Errorf("expected operations built in code to be validated too, got %v", err)
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if p := MustParse(mustParse(t, `[{"op":"remove","path":"/a"}]`)); len(p) != 1 {
+		t.Errorf("MustParse returned %d operations", len(p))
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected MustParse to panic on an invalid patch")
+		}
+	}()
+	MustParse(mustParse(t, `{}`))
+}
+
+func TestPatch_ApplyFrozen(t *testing.T) {
+	doc := mustParse(t, `{"a": 1}`)
+	ast.Freeze(doc)
+	p, _ := Parse(mustParse(t, `[{"op": "add", "path": "/b", "value": 2}]`))
+	got, err := p.Apply(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"a":1,"b":2}`; mustMarshal(t, got) != want {
+		t.Errorf("expected %s, got %s", want, mustMarshal(t, got))
+	}
+}
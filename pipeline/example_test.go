@@ -0,0 +1,27 @@
+package pipeline_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/pipeline"
+	"github.com/letsmakecakes/jsonparser/transform"
+)
+
+func ExampleOptions_Run() {
+	input := `{"level": "info", "msg": "started"}
+{"level": "error", "msg": "disk full", "retry": null}
+`
+	errorsOnly := pipeline.Must(pipeline.Filter(`x.level == "error"`))
+	strip, _ := transform.Lookup("strip-nulls")
+	err := pipeline.Options{Buffer: 16}.Run(context.Background(),
+		jsonparser.NewLineDecoder(strings.NewReader(input)), jsonparser.NewLineEncoder(os.Stdout),
+		errorsOnly, pipeline.Transform(strip))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output: {"level":"error","msg":"disk full"}
+}
@@ -0,0 +1,182 @@
+// Package pipeline runs streams of documents through stages connected by
+// channels, each stage in its own goroutine: a source decoding documents,
+// stages filtering and transforming them, and a sink encoding the results.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/expr"
+	"github.com/letsmakecakes/jsonparser/transform"
+)
+
+// Stage reads documents from in until it is closed and sends its results
+// to out, which the pipeline closes when the stage returns. A stage must
+// stop when ctx is done, so sends should go through Send.
+type Stage func(ctx context.Context, in <-chan ast.Value, out chan<- ast.Value) error
+
+// Sink receives the documents leaving a pipeline, such as a
+// jsonparser.LineEncoder or SequenceEncoder
+type Sink interface {
+	Encode(v interface{}) error
+}
+
+// Options configures a pipeline
+type Options struct {
+	// Buffer is the capacity of each channel between stages; 0 leaves them
+	// unbuffered. A stage that falls behind fills its input channel and so
+	// holds back the stages before it, down to the source.
+	Buffer int
+}
+
+// Send sends v to out, and reports false without sending when ctx is done
+func Send(ctx context.Context, out chan<- ast.Value, v ast.Value) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Map returns a stage calling fn on each document. A nil result drops the
+// document.
+func Map(fn func(v ast.Value) (ast.Value, error)) Stage {
+	return func(ctx context.Context, in <-chan ast.Value, out chan<- ast.Value) error {
+		for v := range in {
+			result, err := fn(v)
+			if err != nil {
+				return err
+			}
+			if result != nil && !Send(ctx, out, result) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+}
+
+// Transform returns a stage applying t to each document
+func Transform(t transform.Transform) Stage {
+	return Map(t.Apply)
+}
+
+// Filter compiles a condition such as `x.level == "error"` into a stage
+// passing on the documents for which it holds. Conditions are true unless
+// they evaluate to false or null.
+func Filter(src string) (Stage, error) {
+	e, err := expr.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	return Map(func(v ast.Value) (ast.Value, error) {
+		keep, err := e.Test(v)
+		if err != nil || !keep {
+			return nil, err
+		}
+		return v, nil
+	}), nil
+}
+
+// Must returns s, panicking if err is not nil, as in
+// pipeline.Must(pipeline.Filter("x.ok"))
+func Must(s Stage, err error) Stage {
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Stream starts the stages on the documents of src and returns the results
+// in order, with the first error from the source or a stage as the last
+// pair, after which the pipeline has stopped. Ending the loop early stops
+// the pipeline too; either way every goroutine has returned when the loop
+// is done.
+func (o Options) Stream(ctx context.Context, src jsonparser.ValueDecoder, stages ...Stage) iter.Seq2[ast.Value, error] {
+	return func(yield func(ast.Value, error) bool) {
+		parent := ctx
+		ctx, cancel := context.WithCancel(ctx)
+		var (
+			wg       sync.WaitGroup
+			once     sync.Once
+			firstErr error
+		)
+		fail := func(err error) {
+			once.Do(func() { firstErr = err })
+			cancel()
+		}
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		docs := make(chan ast.Value, o.Buffer)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(docs)
+			for {
+				v, err := src.Decode()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					fail(fmt.Errorf("pipeline: source: %w", err))
+					return
+				}
+				if !Send(ctx, docs, v) {
+					return
+				}
+			}
+		}()
+		out := docs
+		for i, stage := range stages {
+			in, results := out, make(chan ast.Value, o.Buffer)
+			out = results
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(results)
+				if err := stage(ctx, in, results); err != nil && ctx.Err() == nil {
+					fail(fmt.Errorf("pipeline: stage %d: %w", i+1, err))
+				}
+				// Let the stage before this one finish if this one stopped early
+				for range in {
+				}
+			}()
+		}
+
+		for v := range out {
+			if !yield(v, nil) {
+				return
+			}
+		}
+		wg.Wait()
+		if firstErr == nil {
+			firstErr = parent.Err()
+		}
+		if firstErr != nil {
+			yield(nil, firstErr)
+		}
+	}
+}
+
+// Run sends the results of the stages on the documents of src to sink,
+// returning the first error from any of them
+func (o Options) Run(ctx context.Context, src jsonparser.ValueDecoder, sink Sink, stages ...Stage) error {
+	for v, err := range o.Stream(ctx, src, stages...) {
+		if err != nil {
+			return err
+		}
+		if err := sink.Encode(v); err != nil {
+			return fmt.Errorf("pipeline: sink: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/transform"
+)
+
+func lines(input string) jsonparser.ValueDecoder {
+	return jsonparser.NewLineDecoder(strings.NewReader(input))
+}
+
+func TestRun(t *testing.T) {
+	input := `{"level":"info","msg":"a","debug":null}
+{"level":"error","msg":"b","debug":null}
+{"level":"error","msg":"c"}
+`
+	filter, err := Filter(`x.level == "error"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strip, ok := transform.Lookup("strip-nulls")
+	if !ok {
+		t.Fatal("strip-nulls is not registered")
+	}
+
+	var buf bytes.Buffer
+	for _, buffer := range []int{0, 1, 64} {
+		buf.Reset()
+		err := Options{Buffer: buffer}.Run(context.Background(), lines(input), jsonparser.NewLineEncoder(&buf), filter, Transform(strip))
+		if err != nil {
+			t.Fatalf("Buffer %d: %v", buffer, err)
+		}
+		want := `{"level":"error","msg":"b"}` + "\n" + `{"level":"error","msg":"c"}` + "\n"
+		if buf.String() != want {
+			t.Errorf("Buffer %d: wrote %q, want %q", buffer, buf.String(), want)
+		}
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	failing := Map(func(v ast.Value) (ast.Value, error) {
+		if n, ok := v.(*ast.Number); ok && n.Value == "2" {
+			return nil, errors.New("cannot handle 2")
+		}
+		return v, nil
+	})
+	badSink := sinkFunc(func(interface{}) error { return errors.New("disk full") })
+	failingPass := Map(func(ast.Value) (ast.Value, error) { return nil, errors.New("no") })
+
+	tests := []struct {
+		name    string
+		input   string
+		sink    Sink
+		stages  []Stage
+		wantErr string
+	}{
+		{"stage", "1\n2\n3\n", nil, []Stage{Map(pass), failing}, "pipeline: stage 2: cannot handle 2"},
+		{"source", "1\n{\n3\n", nil, []Stage{Map(pass)}, "pipeline: source: line 2: "},
+		{"sink", "1\n", badSink, nil, "pipeline: sink: disk full"},
+		{"first stage of many", "1\n", nil, []Stage{failingPass, Map(pass), Map(pass)}, "pipeline: stage 1: no"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := tt.sink
+			if sink == nil {
+				sink = jsonparser.NewLineEncoder(io.Discard)
+			}
+			err := Options{}.Run(context.Background(), lines(tt.input), sink, tt.stages...)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func pass(v ast.Value) (ast.Value, error) { return v, nil }
+
+type sinkFunc func(v interface{}) error
+
+func (f sinkFunc) Encode(v interface{}) error { return f(v) }
+
+// countingSource yields n numbers, counting how many were decoded
+type countingSource struct {
+	n       int
+	decoded atomic.Int64
+}
+
+func (s *countingSource) Decode() (jsonparser.Value, error) {
+	i := s.decoded.Load()
+	if int(i) == s.n {
+		return nil, io.EOF
+	}
+	s.decoded.Add(1)
+	return &ast.Number{Value: fmt.Sprint(i)}, nil
+}
+
+func TestStream_Backpressure(t *testing.T) {
+	src := &countingSource{n: 1000}
+	count := 0
+	for _, err := range (Options{Buffer: 4}).Stream(context.Background(), src, Map(pass), Map(pass)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	// Three documents were taken, and each of the three channels holds at
+	// most four more, with one more in the hands of each goroutine
+	if decoded := src.decoded.Load(); decoded > 3+3*4+3 {
+		t.Errorf("decoded %d documents for 3 read, want the buffers to bound it", decoded)
+	}
+}
+
+func TestStream_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := &countingSource{n: 1 << 30}
+	var last error
+	count := 0
+	for _, err := range (Options{}).Stream(ctx, src, Map(pass)) {
+		if err != nil {
+			last = err
+			continue
+		}
+		if count++; count == 10 {
+			cancel()
+		}
+	}
+	if !errors.Is(last, context.Canceled) {
+		t.Errorf("last error = %v, want context.Canceled", last)
+	}
+}
+
+func TestMust(t *testing.T) {
+	if Must(Filter("x.ok")) == nil {
+		t.Error("Must returned a nil stage")
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected Must to panic on an error")
+		}
+	}()
+	Must(Filter("x.ok =="))
+}
+
+func TestFilter_Error(t *testing.T) {
+	if _, err := Filter("x.level =="); err == nil {
+		t.Error("Filter accepted an incomplete condition")
+	}
+}
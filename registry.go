@@ -0,0 +1,192 @@
+package jsonparser
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RegistryOptions configures a DocumentRegistry
+type RegistryOptions struct {
+	// Load returns the document for a name. It is called the first time the
+	// name is asked for, and again after the document expires or is
+	// invalidated. The default reads the name as a file path and parses it
+	// with Options.
+	Load func(name string) (Value, error)
+	// Options are passed to Parse by the default Load
+	Options []Option
+	// TTL is how long a document is kept after it was loaded; 0 keeps it
+	// until it is invalidated
+	TTL time.Duration
+}
+
+// DocumentRegistry caches parsed documents by name, so the parts of a
+// program that need the same schema or configuration share one tree
+// instead of each parsing its own. Documents are frozen before they are
+// handed out, and a registry can be used from any number of goroutines.
+type DocumentRegistry struct {
+	opts RegistryOptions
+	now  func() time.Time
+
+	mu      sync.Mutex // guards entries, hooks and next
+	entries map[string]*registryEntry
+	hooks   map[int]func(name string)
+	next    int
+}
+
+// registryEntry is a document that is loaded or being loaded
+type registryEntry struct {
+	ready  chan struct{} // closed once value and err are set
+	value  Value
+	err    error
+	loaded time.Time
+}
+
+// NewDocumentRegistry returns an empty registry
+func NewDocumentRegistry(opts RegistryOptions) *DocumentRegistry {
+	if opts.Load == nil {
+		parseOpts := opts.Options
+		opts.Load = func(name string) (Value, error) {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			v, err := ParseBytes(data, parseOpts...)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			return v, nil
+		}
+	}
+	return &DocumentRegistry{
+		opts:    opts,
+		now:     time.Now,
+		entries: make(map[string]*registryEntry),
+		hooks:   make(map[int]func(string)),
+	}
+}
+
+// Get returns the document for name, loading it if it is not cached or has
+// expired. Concurrent calls for a name share one load. A failed load is not
+// cached, so the next Get tries again; its error is the one Load returned.
+func (r *DocumentRegistry) Get(name string) (Value, error) {
+	r.mu.Lock()
+	e, ok := r.entries[name]
+	expired := ok && r.expired(e)
+	if expired {
+		delete(r.entries, name)
+	}
+	if !ok || expired {
+		e = &registryEntry{ready: make(chan struct{})}
+		r.entries[name] = e
+		r.mu.Unlock()
+		if expired {
+			r.notify(name)
+		}
+		r.load(name, e)
+	} else {
+		r.mu.Unlock()
+	}
+
+	<-e.ready
+	return e.value, e.err
+}
+
+// expired reports whether e was loaded more than TTL ago; r.mu must be held
+func (r *DocumentRegistry) expired(e *registryEntry) bool {
+	select {
+	case <-e.ready:
+		return r.opts.TTL > 0 && r.now().Sub(e.loaded) >= r.opts.TTL
+	default:
+		return false // still loading
+	}
+}
+
+// load runs Load for e, which no other goroutine loads
+func (r *DocumentRegistry) load(name string, e *registryEntry) {
+	defer close(e.ready)
+	v, err := r.opts.Load(name)
+	if err != nil {
+		e.err = err
+		r.mu.Lock()
+		if r.entries[name] == e {
+			delete(r.entries, name)
+		}
+		r.mu.Unlock()
+		return
+	}
+	Freeze(v)
+	e.value, e.loaded = v, r.now()
+}
+
+// Put stores v as the document for name, freezing it, in place of any
+// document already there, which is invalidated
+func (r *DocumentRegistry) Put(name string, v Value) {
+	Freeze(v)
+	e := &registryEntry{ready: make(chan struct{}), value: v, loaded: r.now()}
+	close(e.ready)
+	r.mu.Lock()
+	_, replaced := r.entries[name]
+	r.entries[name] = e
+	r.mu.Unlock()
+	if replaced {
+		r.notify(name)
+	}
+}
+
+// Invalidate drops the document for name, so the next Get loads it again,
+// and reports whether there was one
+func (r *DocumentRegistry) Invalidate(name string) bool {
+	r.mu.Lock()
+	_, ok := r.entries[name]
+	delete(r.entries, name)
+	r.mu.Unlock()
+	if ok {
+		r.notify(name)
+	}
+	return ok
+}
+
+// Names returns the names of the documents held, in sorted order
+func (r *DocumentRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OnInvalidate calls fn with the name of every document that is
+// invalidated, replaced by Put or found expired, until the returned
+// function is called. Calls are made in the goroutine that dropped the
+// document, after the registry has let go of it.
+func (r *DocumentRegistry) OnInvalidate(fn func(name string)) (unsubscribe func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.next
+	r.next++
+	r.hooks[id] = fn
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.hooks, id)
+	}
+}
+
+// notify calls the invalidation hooks for name
+func (r *DocumentRegistry) notify(name string) {
+	r.mu.Lock()
+	hooks := make([]func(string), 0, len(r.hooks))
+	for _, fn := range r.hooks {
+		hooks = append(hooks, fn)
+	}
+	r.mu.Unlock()
+	for _, fn := range hooks {
+		fn(name)
+	}
+}
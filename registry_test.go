@@ -0,0 +1,146 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDocumentRegistry_Get(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	r := NewDocumentRegistry(RegistryOptions{Load: func(name string) (Value, error) {
+		loads.Add(1)
+		<-release
+		return Parse(`{"name": "` + name + `"}`)
+	}})
+
+	var wg sync.WaitGroup
+	values := make([]Value, 8)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := r.Get("orders")
+			if err != nil {
+				t.Error(err)
+			}
+			values[i] = v
+		}()
+	}
+	time.Sleep(10 * time.Millisecond) // let the goroutines queue up behind one load
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loaded %d times, want 1", n)
+	}
+	for _, v := range values {
+		if v != values[0] {
+			t.Fatal("Get returned different trees for one name")
+		}
+	}
+	if !IsFrozen(values[0]) {
+		t.Error("expected the shared document to be frozen")
+	}
+}
+
+func TestDocumentRegistry_TTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	var loads int
+	r := NewDocumentRegistry(RegistryOptions{TTL: time.Minute, Load: func(string) (Value, error) {
+		loads++
+		return Parse(`[]`)
+	}})
+	r.now = func() time.Time { return now }
+	var expired []string
+	r.OnInvalidate(func(name string) { expired = append(expired, name) })
+
+	r.Get("a")
+	now = now.Add(59 * time.Second)
+	r.Get("a")
+	if loads != 1 {
+		t.Errorf("loaded %d times within the TTL, want 1", loads)
+	}
+	now = now.Add(time.Second)
+	r.Get("a")
+	if loads != 2 || !reflect.DeepEqual(expired, []string{"a"}) {
+		t.Errorf("after the TTL: loaded %d times and expired %q, want 2 and [a]", loads, expired)
+	}
+}
+
+func TestDocumentRegistry_Invalidate(t *testing.T) {
+	var loads int
+	r := NewDocumentRegistry(RegistryOptions{Load: func(string) (Value, error) {
+		loads++
+		return Parse(`{}`)
+	}})
+	var invalidated []string
+	unsubscribe := r.OnInvalidate(func(name string) { invalidated = append(invalidated, name) })
+
+	r.Get("a")
+	r.Put("b", MustParse(`{"put": true}`))
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Names() = %q, want [a b]", got)
+	}
+	if !r.Invalidate("a") || r.Invalidate("missing") {
+		t.Error("Invalidate reported the wrong documents")
+	}
+	r.Put("b", MustParse(`{"put": 2}`))
+	if b, _ := r.Get("b"); fmt.Sprint(b) != `{"put":2}` {
+		t.Errorf("Get(b) = %v, want the document put last", b)
+	}
+	r.Get("a")
+	if loads != 2 || !reflect.DeepEqual(invalidated, []string{"a", "b"}) {
+		t.Errorf("loaded %d times and invalidated %q, want 2 and [a b]", loads, invalidated)
+	}
+
+	unsubscribe()
+	r.Invalidate("a")
+	if len(invalidated) != 2 {
+		t.Errorf("hook called after unsubscribing: %q", invalidated)
+	}
+}
+
+func TestDocumentRegistry_Errors(t *testing.T) {
+	fail := true
+	r := NewDocumentRegistry(RegistryOptions{Load: func(string) (Value, error) {
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+		return Parse(`1`)
+	}})
+	if _, err := r.Get("a"); err == nil || err.Error() != "unavailable" {
+		t.Errorf("Get() error = %v, want unavailable", err)
+	}
+	fail = false
+	if v, err := r.Get("a"); err != nil || fmt.Sprint(v) != "1" {
+		t.Errorf("Get() = %v, %v, want the failed load retried", v, err)
+	}
+}
+
+func TestDocumentRegistry_Files(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(path, []byte("// comment\n{\"type\": \"object\"}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewDocumentRegistry(RegistryOptions{Options: []Option{WithAllowComments(true)}})
+	if v, err := r.Get(path); err != nil || fmt.Sprint(v) != `{"type":"object"}` {
+		t.Errorf("Get(%s) = %v, %v", path, v, err)
+	}
+	if _, err := r.Get(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing file to fail with os.ErrNotExist, got %v", err)
+	}
+	os.WriteFile(path, []byte(`{"type": }`), 0o644)
+	r.Invalidate(path)
+	if _, err := r.Get(path); err == nil || !errors.Is(err, ErrSyntax) || err.Error()[:len(path)] != path {
+		t.Errorf("expected a syntax error naming the file, got %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+// Package schema validates parsed documents against JSON Schemas and
+// compares versions of a schema.
+package schema
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+)
+
+// Change is a difference between two versions of a schema. A breaking
+// change makes the new version reject documents the old one accepted.
+type Change struct {
+	Path     string // JSON Pointer to the schema keyword that changed
+	Breaking bool
+	Message  string
+}
+
+// String returns the change as "breaking: /properties/id: property removed"
+func (c Change) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return fmt.Sprintf("%s: %s: %s", kind, display(c.Path), c.Message)
+}
+
+// HasBreaking reports whether any of the changes is breaking
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare reports how the schema new differs from old, descending through
+// properties, additionalProperties and items. Breaking changes include
+// removed properties, newly required ones, narrowed types and enums,
+// tightened bounds, and new or changed patterns; the matching widenings are
+// reported as compatible. References are compared by their text and not
+// followed.
+func Compare(old, new ast.Value) []Change {
+	c := &comparer{}
+	c.compare("", old, new)
+	return c.changes
+}
+
+type comparer struct {
+	changes []Change
+}
+
+func (c *comparer) report(path string, breaking bool, format string, args ...any) {
+	c.changes = append(c.changes, Change{Path: path, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *comparer) compare(path string, old, new ast.Value) {
+	oldObj, oldFalse := schemaObject(old)
+	newObj, newFalse := schemaObject(new)
+	switch {
+	case oldFalse && newFalse:
+		return
+	case oldFalse:
+		c.report(path, false, "now accepts values, where it accepted none")
+		return
+	case newFalse:
+		c.report(path, true, "no longer accepts any value")
+		return
+	}
+
+	if ref, newRef := stringKeyword(oldObj, "$ref"), stringKeyword(newObj, "$ref"); ref != newRef {
+		c.report(join(path, "$ref"), true, "reference changed from %q to %q", ref, newRef)
+	}
+	c.types(path, oldObj, newObj)
+	c.enums(path, oldObj, newObj)
+	c.bounds(path, oldObj, newObj)
+	if pattern, newPattern := stringKeyword(oldObj, "pattern"), stringKeyword(newObj, "pattern"); pattern != newPattern {
+		if newPattern == "" {
+			c.report(join(path, "pattern"), false, "pattern %q removed", pattern)
+		} else if pattern == "" {
+			c.report(join(path, "pattern"), true, "pattern %q added", newPattern)
+		} else {
+			c.report(join(path, "pattern"), true, "pattern changed from %q to %q", pattern, newPattern)
+		}
+	}
+	c.properties(path, oldObj, newObj)
+	for _, keyword := range []string{"additionalProperties", "items"} {
+		old, inOld := oldObj.Pairs[keyword]
+		new, inNew := newObj.Pairs[keyword]
+		if inOld || inNew {
+			c.compare(join(path, keyword), old, new)
+		}
+	}
+}
+
+// schemaObject returns a schema as an object, treating true and a missing
+// schema as the empty schema, and reports whether it is false
+func schemaObject(v ast.Value) (*ast.Object, bool) {
+	switch v := v.(type) {
+	case *ast.Object:
+		return v, false
+	case *ast.Boolean:
+		return &ast.Object{}, v.Value == "false"
+	}
+	return &ast.Object{}, false
+}
+
+func (c *comparer) types(path string, old, new *ast.Object) {
+	oldTypes, newTypes := typesOf(old), typesOf(new)
+	if newTypes == nil {
+		if oldTypes != nil {
+			c.report(join(path, "type"), false, "any type is now accepted")
+		}
+		return
+	}
+	if oldTypes == nil {
+		c.report(join(path, "type"), true, "type restricted to %s", list(newTypes))
+		return
+	}
+	for _, t := range oldTypes {
+		if !accepts(newTypes, t) {
+			c.report(join(path, "type"), true, "type %q no longer accepted", t)
+		}
+	}
+	for _, t := range newTypes {
+		if !accepts(oldTypes, t) {
+			c.report(join(path, "type"), false, "type %q now accepted", t)
+		}
+	}
+}
+
+// typesOf returns the types a schema's type keyword names, or nil for any
+func typesOf(obj *ast.Object) []string {
+	switch t := obj.Pairs["type"].(type) {
+	case *ast.String:
+		return []string{t.Value}
+	case *ast.Array:
+		types := []string{}
+		for _, e := range t.Elements {
+			if s, ok := e.(*ast.String); ok {
+				types = append(types, s.Value)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// accepts reports whether a value of type t passes a type keyword listing
+// types, where "number" covers "integer"
+func accepts(types []string, t string) bool {
+	for _, u := range types {
+		if u == t || u == "number" && t == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparer) enums(path string, old, new *ast.Object) {
+	oldValues, oldKeyword := allowed(old)
+	newValues, newKeyword := allowed(new)
+	if newValues == nil {
+		if oldValues != nil {
+			c.report(join(path, oldKeyword), false, "%s removed", oldKeyword)
+		}
+		return
+	}
+	if oldValues == nil {
+		c.report(join(path, newKeyword), true, "%s added, allowing only %s", newKeyword, values(newValues))
+		return
+	}
+	for _, v := range oldValues {
+		if !contains(newValues, v) {
+			c.report(join(path, newKeyword), true, "value %s no longer allowed", describe(v))
+		}
+	}
+	for _, v := range newValues {
+		if !contains(oldValues, v) {
+			c.report(join(path, newKeyword), false, "value %s now allowed", describe(v))
+		}
+	}
+}
+
+// allowed returns the values enum or const allows, and which keyword it was,
+// or nil when the schema has neither
+func allowed(obj *ast.Object) ([]ast.Value, string) {
+	if v, ok := obj.Pairs["const"]; ok {
+		return []ast.Value{v}, "const"
+	}
+	if enum, ok := obj.Pairs["enum"].(*ast.Array); ok {
+		return enum.Elements, "enum"
+	}
+	return nil, ""
+}
+
+func contains(values []ast.Value, v ast.Value) bool {
+	for _, w := range values {
+		if (ast.EqualOptions{IgnoreKeyOrder: true, IgnoreNumberFormat: true}).Equal(v, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// lowerBounds and upperBounds are the keywords that narrow what a schema
+// accepts when raised or lowered
+var (
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+func (c *comparer) bounds(path string, old, new *ast.Object) {
+	for _, keyword := range lowerBounds {
+		c.bound(join(path, keyword), keyword, old, new, 1)
+	}
+	for _, keyword := range upperBounds {
+		c.bound(join(path, keyword), keyword, old, new, -1)
+	}
+}
+
+// bound compares one bound, where sign is 1 when a higher value narrows and
+// -1 when a lower one does
+func (c *comparer) bound(path, keyword string, old, new *ast.Object, sign int) {
+	o, oldOK := number(old, keyword)
+	n, newOK := number(new, keyword)
+	switch {
+	case !oldOK && !newOK:
+	case !oldOK:
+		c.report(path, true, "%s %s added", keyword, format(n))
+	case !newOK:
+		c.report(path, false, "%s %s removed", keyword, format(o))
+	case float64(sign)*(n-o) > 0:
+		c.report(path, true, "%s tightened from %s to %s", keyword, format(o), format(n))
+	case n != o:
+		c.report(path, false, "%s relaxed from %s to %s", keyword, format(o), format(n))
+	}
+}
+
+func number(obj *ast.Object, keyword string) (float64, bool) {
+	n, ok := obj.Pairs[keyword].(*ast.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(n.Value, 64)
+	return f, err == nil
+}
+
+func format(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func (c *comparer) properties(path string, old, new *ast.Object) {
+	oldProps, _ := old.Pairs["properties"].(*ast.Object)
+	newProps, _ := new.Pairs["properties"].(*ast.Object)
+	if oldProps == nil {
+		oldProps = &ast.Object{}
+	}
+	if newProps == nil {
+		newProps = &ast.Object{}
+	}
+	for _, name := range oldProps.OrderedKeys() {
+		at := join(join(path, "properties"), name)
+		if schema, ok := newProps.Pairs[name]; ok {
+			c.compare(at, oldProps.Pairs[name], schema)
+		} else {
+			c.report(at, true, "property %q removed", name)
+		}
+	}
+	for _, name := range newProps.OrderedKeys() {
+		if _, ok := oldProps.Pairs[name]; !ok {
+			c.report(join(join(path, "properties"), name), false, "property %q added", name)
+		}
+	}
+
+	oldRequired, newRequired := required(old), required(new)
+	for _, name := range newRequired {
+		if !containsString(oldRequired, name) {
+			c.report(join(path, "required"), true, "property %q now required", name)
+		}
+	}
+	for _, name := range oldRequired {
+		if !containsString(newRequired, name) {
+			c.report(join(path, "required"), false, "property %q no longer required", name)
+		}
+	}
+}
+
+func required(obj *ast.Object) []string {
+	array, _ := obj.Pairs["required"].(*ast.Array)
+	if array == nil {
+		return nil
+	}
+	names := make([]string, 0, len(array.Elements))
+	for _, e := range array.Elements {
+		if s, ok := e.(*ast.String); ok {
+			names = append(names, s.Value)
+		}
+	}
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+func stringKeyword(obj *ast.Object, keyword string) string {
+	s, _ := obj.Pairs[keyword].(*ast.String)
+	if s == nil {
+		return ""
+	}
+	return s.Value
+}
+
+// display returns a pointer for a message, quoting the root's empty one
+func display(pointer string) string {
+	if pointer == "" {
+		return `""`
+	}
+	return pointer
+}
+
+// join appends a segment to a JSON Pointer
+func join(pointer, segment string) string {
+	return pointer + ast.FormatPointer([]string{segment})
+}
+
+func list(types []string) string {
+	text := ""
+	for i, t := range types {
+		if i > 0 {
+			text += ", "
+		}
+		text += strconv.Quote(t)
+	}
+	return text
+}
+
+func values(vs []ast.Value) string {
+	text := ""
+	for i, v := range vs {
+		if i > 0 {
+			text += ", "
+		}
+		text += describe(v)
+	}
+	return text
+}
+
+func describe(v ast.Value) string {
+	data, err := encoder.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%T", v)
+	}
+	return string(data)
+}
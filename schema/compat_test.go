@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("lexing %s: %v", input, err)
+	}
+	v, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", input, err)
+	}
+	return v
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{"unchanged", `{"type":"object","properties":{"id":{"type":"integer"}}}`, `{"properties":{"id":{"type":"integer"}},"type":"object"}`, nil},
+		{
+			"removed property",
+			`{"properties":{"id":{"type":"string"},"name":{}}}`,
+			`{"properties":{"id":{"type":"string"}}}`,
+			[]string{`breaking: /properties/name: property "name" removed`},
+		},
+		{
+			"added optional property",
+			`{"properties":{}}`,
+			`{"properties":{"tags":{"type":"array"}}}`,
+			[]string{`compatible: /properties/tags: property "tags" added`},
+		},
+		{
+			"newly required",
+			`{"required":["id"],"properties":{"id":{}}}`,
+			`{"required":["name"],"properties":{"id":{},"name":{}}}`,
+			[]string{
+				`compatible: /properties/name: property "name" added`,
+				`breaking: /required: property "name" now required`,
+				`compatible: /required: property "id" no longer required`,
+			},
+		},
+		{
+			"narrowed type",
+			`{"properties":{"n":{"type":["number","string"]}}}`,
+			`{"properties":{"n":{"type":"integer"}}}`,
+			[]string{
+				`breaking: /properties/n/type: type "number" no longer accepted`,
+				`breaking: /properties/n/type: type "string" no longer accepted`,
+			},
+		},
+		{"integer widened to number", `{"type":"integer"}`, `{"type":"number"}`, []string{`compatible: /type: type "number" now accepted`}},
+		{"type added", `{}`, `{"type":["string","null"]}`, []string{`breaking: /type: type restricted to "string", "null"`}},
+		{"type removed", `{"type":"string"}`, `true`, []string{`compatible: /type: any type is now accepted`}},
+		{
+			"enum changed",
+			`{"enum":["a","b"]}`,
+			`{"enum":["b","c"]}`,
+			[]string{`breaking: /enum: value "a" no longer allowed`, `compatible: /enum: value "c" now allowed`},
+		},
+		{"enum added", `{"type":"string"}`, `{"type":"string","enum":["x"]}`, []string{`breaking: /enum: enum added, allowing only "x"`}},
+		{"const widened to enum", `{"const":1}`, `{"enum":[1.0,2]}`, []string{`compatible: /enum: value 2 now allowed`}},
+		{
+			"bounds",
+			`{"minimum":0,"maximum":10,"maxLength":5}`,
+			`{"minimum":1,"maximum":20,"minItems":1}`,
+			[]string{
+				`breaking: /minimum: minimum tightened from 0 to 1`,
+				`breaking: /minItems: minItems 1 added`,
+				`compatible: /maximum: maximum relaxed from 10 to 20`,
+				`compatible: /maxLength: maxLength 5 removed`,
+			},
+		},
+		{"pattern changed", `{"pattern":"^a"}`, `{"pattern":"^ab"}`, []string{`breaking: /pattern: pattern changed from "^a" to "^ab"`}},
+		{"pattern removed", `{"pattern":"^a"}`, `{}`, []string{`compatible: /pattern: pattern "^a" removed`}},
+		{
+			"additional properties closed",
+			`{"properties":{"a":{}}}`,
+			`{"properties":{"a":{}},"additionalProperties":false}`,
+			[]string{`breaking: /additionalProperties: no longer accepts any value`},
+		},
+		{"additional properties opened", `{"additionalProperties":false}`, `{}`, []string{`compatible: /additionalProperties: now accepts values, where it accepted none`}},
+		{
+			"items",
+			`{"type":"array","items":{"properties":{"sku":{"type":"string"}}}}`,
+			`{"type":"array","items":{"properties":{"sku":{"type":"string","maxLength":12}}}}`,
+			[]string{`breaking: /items/properties/sku/maxLength: maxLength 12 added`},
+		},
+		{"reference", `{"$ref":"#/$defs/a"}`, `{"$ref":"#/$defs/b"}`, []string{`breaking: /$ref: reference changed from "#/$defs/a" to "#/$defs/b"`}},
+		{"escaped name", `{"properties":{"a/b":{}}}`, `{}`, []string{`breaking: /properties/a~1b: property "a/b" removed`}},
+		{"false root", `{}`, `false`, []string{`breaking: "": no longer accepts any value`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Compare(mustParse(t, tt.old), mustParse(t, tt.new))
+			var got []string
+			for _, c := range changes {
+				got = append(got, fmt.Sprint(c))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHasBreaking(t *testing.T) {
+	if HasBreaking([]Change{{Path: "/a", Message: "added"}}) {
+		t.Error("expected compatible changes not to be breaking")
+	}
+	if !HasBreaking([]Change{{Path: "/a"}, {Path: "/b", Breaking: true}}) {
+		t.Error("expected a breaking change to be reported")
+	}
+}
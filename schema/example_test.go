@@ -0,0 +1,40 @@
+package schema_test
+
+import (
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser"
+	"github.com/letsmakecakes/jsonparser/schema"
+)
+
+func ExampleSchema_Validate() {
+	s := schema.MustCompile(jsonparser.MustParse(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {"id": {"type": "integer"}, "tags": {"type": "array", "items": {"type": "string"}}}
+	}`))
+	for _, v := range s.Validate(jsonparser.MustParse(`{"tags": ["a", 1]}`)) {
+		fmt.Println(v)
+	}
+	// Output:
+	// "": missing required property "id" (schema /required)
+	// /tags/1: expected string, got number (schema /properties/tags/items/type)
+}
+
+func ExampleInfer() {
+	inferred := schema.Infer(jsonparser.MustParse(`{"id": 1, "name": "a"}`), jsonparser.MustParse(`{"id": 2}`))
+	fmt.Println(inferred)
+	// Output: {"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"}},"required":["id"]}
+}
+
+func ExampleCompare() {
+	old := jsonparser.MustParse(`{"properties": {"id": {"type": "integer"}}}`)
+	changed := jsonparser.MustParse(`{"properties": {"id": {"type": "string"}}, "required": ["id"]}`)
+	for _, c := range schema.Compare(old, changed) {
+		fmt.Println(c)
+	}
+	// Output:
+	// breaking: /properties/id/type: type "integer" no longer accepted
+	// compatible: /properties/id/type: type "string" now accepted
+	// breaking: /required: property "id" now required
+}
@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Draft is the $schema URI of the JSON Schema draft this package reads and
+// Infer writes
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Infer returns a JSON Schema describing every one of the sample documents:
+// the types seen at each place, the properties of objects in the order they
+// were first seen, which of them every object has, and the shape of array
+// elements. A type of "integer" is given only where every number was whole,
+// and a value seen as null and as something else is given both types, such
+// as ["string", "null"]. Values never seen, such as the elements of arrays
+// that were always empty, are left unconstrained.
+func Infer(docs ...ast.Value) ast.Value {
+	s := &shape{}
+	for _, doc := range docs {
+		s.add(doc)
+	}
+	root := &ast.Object{}
+	root.Set("$schema", &ast.String{Value: Draft})
+	return s.schema(root)
+}
+
+// shape accumulates the values seen at one place in the samples
+type shape struct {
+	types      map[string]bool
+	objects    int               // how many objects were seen
+	names      []string          // property names in the order first seen
+	properties map[string]*shape // the values of each property
+	seen       map[string]int    // how many objects had each property
+	items      *shape            // the elements of every array seen
+}
+
+func (s *shape) add(v ast.Value) {
+	if s.types == nil {
+		s.types = make(map[string]bool)
+	}
+	t := ast.TypeName(v)
+	if t == "number" && hasType(v, "integer") {
+		t = "integer"
+	}
+	s.types[t] = true
+	switch v := v.(type) {
+	case *ast.Object:
+		if s.properties == nil {
+			s.properties, s.seen = make(map[string]*shape), make(map[string]int)
+		}
+		s.objects++
+		for _, name := range v.OrderedKeys() {
+			p, ok := s.properties[name]
+			if !ok {
+				p = &shape{}
+				s.properties[name] = p
+				s.names = append(s.names, name)
+			}
+			p.add(v.Pairs[name])
+			s.seen[name]++
+		}
+	case *ast.Array:
+		if s.items == nil {
+			s.items = &shape{}
+		}
+		for _, elem := range v.Elements {
+			s.items.add(elem)
+		}
+	}
+}
+
+// typeOrder is the order types are listed in, with null last
+var typeOrder = []string{"object", "array", "string", "integer", "number", "boolean", "null"}
+
+// schema adds the keywords describing the shape to schema and returns it
+func (s *shape) schema(schema *ast.Object) *ast.Object {
+	if len(s.types) == 0 {
+		return schema
+	}
+	var types []ast.Value
+	for _, t := range typeOrder {
+		if s.types[t] && !(t == "integer" && s.types["number"]) {
+			types = append(types, &ast.String{Value: t})
+		}
+	}
+	if len(types) == 1 {
+		schema.Set("type", types[0])
+	} else {
+		schema.Set("type", &ast.Array{Elements: types})
+	}
+
+	if s.types["object"] {
+		properties := &ast.Object{}
+		required := &ast.Array{Elements: []ast.Value{}}
+		for _, name := range s.names {
+			properties.Set(name, s.properties[name].schema(&ast.Object{}))
+			if s.seen[name] == s.objects {
+				required.Elements = append(required.Elements, &ast.String{Value: name})
+			}
+		}
+		schema.Set("properties", properties)
+		if len(required.Elements) > 0 {
+			schema.Set("required", required)
+		}
+	}
+	if s.types["array"] && len(s.items.types) > 0 {
+		schema.Set("items", s.items.schema(&ast.Object{}))
+	}
+	return schema
+}
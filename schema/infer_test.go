@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+)
+
+func mustMarshal(t *testing.T, v ast.Value) string {
+	t.Helper()
+	data, err := encoder.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestInfer(t *testing.T) {
+	const draft = `"$schema":"https://json-schema.org/draft/2020-12/schema",`
+	tests := []struct {
+		name    string
+		samples []string
+		want    string
+	}{
+		{"none", nil, `{"$schema":"https://json-schema.org/draft/2020-12/schema"}`},
+		{"scalar", []string{`"a"`}, `{` + draft + `"type":"string"}`},
+		{"integers", []string{`1`, `2.0`}, `{` + draft + `"type":"integer"}`},
+		{"integer widened to number", []string{`1`, `2.5`}, `{` + draft + `"type":"number"}`},
+		{"nullable", []string{`null`, `"a"`}, `{` + draft + `"type":["string","null"]}`},
+		{
+			"required properties",
+			[]string{`{"id":1,"name":"a"}`, `{"id":2,"tags":[]}`},
+			`{` + draft + `"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"},"tags":{"type":"array"}},"required":["id"]}`,
+		},
+		{
+			"nullable property",
+			[]string{`{"email":null}`, `{"email":"a@b"}`},
+			`{` + draft + `"type":"object","properties":{"email":{"type":["string","null"]}},"required":["email"]}`,
+		},
+		{
+			"array items",
+			[]string{`[{"x":1},{"x":2,"y":true}]`, `[]`},
+			`{` + draft + `"type":"array","items":{"type":"object","properties":{"x":{"type":"integer"},"y":{"type":"boolean"}},"required":["x"]}}`,
+		},
+		{"mixed items", []string{`[1,"a",null]`}, `{` + draft + `"type":"array","items":{"type":["string","integer","null"]}}`},
+		{
+			"object or array",
+			[]string{`{"a":1}`, `[1]`},
+			`{` + draft + `"type":["object","array"],"properties":{"a":{"type":"integer"}},"required":["a"],"items":{"type":"integer"}}`,
+		},
+		{"empty object", []string{`{}`}, `{` + draft + `"type":"object","properties":{}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var docs []ast.Value
+			for _, sample := range tt.samples {
+				docs = append(docs, mustParse(t, sample))
+			}
+			inferred := Infer(docs...)
+			if got := mustMarshal(t, inferred); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			// Every sample is valid against the schema inferred from it
+			s, err := Compile(inferred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, doc := range docs {
+				if violations := s.Validate(doc); violations != nil {
+					t.Errorf("sample %d: expected no violations, got %v", i, violations)
+				}
+			}
+		})
+	}
+}
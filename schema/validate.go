@@ -0,0 +1,663 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Violation is a way a document fails a schema
+type Violation struct {
+	InstancePath string // JSON Pointer to the value in the document
+	SchemaPath   string // JSON Pointer to the keyword in the schema it fails
+	Message      string
+}
+
+// String returns the violation as "/age: must be at least 0 (schema /properties/age/minimum)"
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s (schema %s)", display(v.InstancePath), v.Message, display(v.SchemaPath))
+}
+
+// Schema is a compiled JSON Schema. It is safe for concurrent use.
+type Schema struct {
+	root *node
+}
+
+// Compile compiles a JSON Schema of draft 2020-12. It knows the keywords
+// that check types and values (type, enum, const, the numeric, length and
+// size bounds, multipleOf, pattern, required and uniqueItems), those that
+// apply subschemas (properties, patternProperties, additionalProperties,
+// prefixItems, items, allOf, anyOf, oneOf, not, and if with then and else),
+// and $ref to a JSON Pointer within the schema, such as "#/$defs/address".
+// Other keywords, such as format, are ignored as annotations. Patterns use
+// Go's regexp syntax.
+func Compile(doc ast.Value) (*Schema, error) {
+	c := &compiler{doc: doc, nodes: make(map[string]*node)}
+	root, err := c.compile(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile but panics when the schema is invalid, for
+// schemas fixed at compile time
+func MustCompile(doc ast.Value) *Schema {
+	s, err := Compile(doc)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate checks doc against the schema and returns the violations found,
+// in the order of the schema's keywords, or nil when doc is valid
+func (s *Schema) Validate(doc ast.Value) []Violation {
+	st := &state{active: make(map[string]bool)}
+	s.root.validate(st, doc, "")
+	return st.violations
+}
+
+// node is a compiled schema, found at pointer in the schema document
+type node struct {
+	pointer string
+	reject  bool // the schema is false
+	checks  []check
+}
+
+// check applies one keyword to the value at instance
+type check func(s *state, v ast.Value, instance string)
+
+// state collects the violations of one validation. active holds the
+// references being followed, so that a cycle is reported, not followed
+// forever.
+type state struct {
+	violations []Violation
+	active     map[string]bool
+}
+
+func (s *state) report(instance, keyword, format string, args ...any) {
+	s.violations = append(s.violations, Violation{InstancePath: instance, SchemaPath: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+func (n *node) validate(s *state, v ast.Value, instance string) {
+	if n.reject {
+		s.report(instance, n.pointer, "not allowed")
+		return
+	}
+	for _, check := range n.checks {
+		check(s, v, instance)
+	}
+}
+
+// valid reports whether v passes the schema, without reporting how it fails
+func (n *node) valid(s *state, v ast.Value, instance string) bool {
+	sub := &state{active: s.active}
+	n.validate(sub, v, instance)
+	return len(sub.violations) == 0
+}
+
+// compiler compiles the schemas of one document, each once, so that
+// references share them and may be recursive
+type compiler struct {
+	doc   ast.Value
+	nodes map[string]*node
+}
+
+func (c *compiler) errorf(pointer, format string, args ...any) error {
+	return fmt.Errorf("schema: %s: %s", display(pointer), fmt.Sprintf(format, args...))
+}
+
+func (c *compiler) compile(v ast.Value, pointer string) (*node, error) {
+	if n, ok := c.nodes[pointer]; ok {
+		return n, nil
+	}
+	n := &node{pointer: pointer}
+	c.nodes[pointer] = n
+	switch v := v.(type) {
+	case *ast.Boolean:
+		n.reject = v.Value == "false"
+	case *ast.Object:
+		for _, key := range v.OrderedKeys() {
+			check, err := c.keyword(v, key, pointer)
+			if err != nil {
+				return nil, err
+			}
+			if check != nil {
+				n.checks = append(n.checks, check)
+			}
+		}
+	default:
+		return nil, c.errorf(pointer, "a schema must be an object or a boolean")
+	}
+	return n, nil
+}
+
+// keyword compiles the keyword key of the schema obj at pointer, returning
+// nil for keywords that check nothing by themselves
+func (c *compiler) keyword(obj *ast.Object, key, pointer string) (check, error) {
+	value, at := obj.Pairs[key], join(pointer, key)
+	switch key {
+	case "type":
+		return c.typeKeyword(value, at)
+	case "enum":
+		enum, ok := value.(*ast.Array)
+		if !ok {
+			return nil, c.errorf(at, "must be an array")
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if !contains(enum.Elements, v) {
+				s.report(instance, at, "must be one of %s", values(enum.Elements))
+			}
+		}, nil
+	case "const":
+		return func(s *state, v ast.Value, instance string) {
+			if !contains([]ast.Value{value}, v) {
+				s.report(instance, at, "must be %s", describe(value))
+			}
+		}, nil
+	case "minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum":
+		return c.limit(key, value, at)
+	case "multipleOf":
+		factor, ok := ratOf(value)
+		if !ok || factor.Sign() <= 0 {
+			return nil, c.errorf(at, "must be a number greater than 0")
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if n, ok := ratOf(v); ok && !new(big.Rat).Quo(n, factor).IsInt() {
+				s.report(instance, at, "must be a multiple of %s", value.(*ast.Number).Value)
+			}
+		}, nil
+	case "minLength", "maxLength":
+		limit, err := c.count(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if str, ok := v.(*ast.String); ok {
+				c.bound(s, instance, at, key, utf8.RuneCountInString(str.Value), limit, "characters")
+			}
+		}, nil
+	case "minItems", "maxItems":
+		limit, err := c.count(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if array, ok := v.(*ast.Array); ok {
+				c.bound(s, instance, at, key, len(array.Elements), limit, "items")
+			}
+		}, nil
+	case "minProperties", "maxProperties":
+		limit, err := c.count(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if obj, ok := v.(*ast.Object); ok {
+				c.bound(s, instance, at, key, len(obj.Pairs), limit, "properties")
+			}
+		}, nil
+	case "pattern":
+		re, err := c.compilePattern(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if str, ok := v.(*ast.String); ok && !re.MatchString(str.Value) {
+				s.report(instance, at, "must match %q", re.String())
+			}
+		}, nil
+	case "uniqueItems":
+		unique, ok := value.(*ast.Boolean)
+		if !ok {
+			return nil, c.errorf(at, "must be a boolean")
+		}
+		if unique.Value == "false" {
+			return nil, nil
+		}
+		return func(s *state, v ast.Value, instance string) {
+			array, ok := v.(*ast.Array)
+			if !ok {
+				return
+			}
+			for i := range array.Elements {
+				for j := i + 1; j < len(array.Elements); j++ {
+					if contains(array.Elements[i:i+1], array.Elements[j]) {
+						s.report(instance, at, "items %d and %d are equal", i, j)
+						return
+					}
+				}
+			}
+		}, nil
+	case "required":
+		names, ok := value.(*ast.Array)
+		if !ok {
+			return nil, c.errorf(at, "must be an array of strings")
+		}
+		required := make([]string, len(names.Elements))
+		for i, e := range names.Elements {
+			name, ok := e.(*ast.String)
+			if !ok {
+				return nil, c.errorf(at, "must be an array of strings")
+			}
+			required[i] = name.Value
+		}
+		return func(s *state, v ast.Value, instance string) {
+			obj, ok := v.(*ast.Object)
+			if !ok {
+				return
+			}
+			for _, name := range required {
+				if _, ok := obj.Pairs[name]; !ok {
+					s.report(instance, at, "missing required property %q", name)
+				}
+			}
+		}, nil
+	case "properties":
+		properties, err := c.schemaMap(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			obj, ok := v.(*ast.Object)
+			if !ok {
+				return
+			}
+			for _, name := range obj.OrderedKeys() {
+				if n, ok := properties[name]; ok {
+					n.validate(s, obj.Pairs[name], join(instance, name))
+				}
+			}
+		}, nil
+	case "patternProperties":
+		patterns, err := c.patterns(obj, pointer)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			obj, ok := v.(*ast.Object)
+			if !ok {
+				return
+			}
+			for _, name := range obj.OrderedKeys() {
+				for _, p := range patterns {
+					if p.re.MatchString(name) {
+						p.node.validate(s, obj.Pairs[name], join(instance, name))
+					}
+				}
+			}
+		}, nil
+	case "additionalProperties":
+		additional, err := c.compile(value, at)
+		if err != nil {
+			return nil, err
+		}
+		// Properties that properties or patternProperties cover are not additional
+		named, _ := obj.Pairs["properties"].(*ast.Object)
+		patterns, err := c.patterns(obj, pointer)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			obj, ok := v.(*ast.Object)
+			if !ok {
+				return
+			}
+		members:
+			for _, name := range obj.OrderedKeys() {
+				if named != nil {
+					if _, ok := named.Pairs[name]; ok {
+						continue
+					}
+				}
+				for _, p := range patterns {
+					if p.re.MatchString(name) {
+						continue members
+					}
+				}
+				additional.validate(s, obj.Pairs[name], join(instance, name))
+			}
+		}, nil
+	case "prefixItems":
+		prefix, err := c.schemaList(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			array, ok := v.(*ast.Array)
+			if !ok {
+				return
+			}
+			for i, e := range array.Elements[:min(len(prefix), len(array.Elements))] {
+				prefix[i].validate(s, e, instance+"/"+strconv.Itoa(i))
+			}
+		}, nil
+	case "items":
+		items, err := c.compile(value, at)
+		if err != nil {
+			return nil, err
+		}
+		// items applies to the elements after those prefixItems covers
+		skip := 0
+		if prefix, ok := obj.Pairs["prefixItems"].(*ast.Array); ok {
+			skip = len(prefix.Elements)
+		}
+		return func(s *state, v ast.Value, instance string) {
+			array, ok := v.(*ast.Array)
+			if !ok {
+				return
+			}
+			for i := skip; i < len(array.Elements); i++ {
+				items.validate(s, array.Elements[i], instance+"/"+strconv.Itoa(i))
+			}
+		}, nil
+	case "allOf":
+		schemas, err := c.schemaList(value, at)
+		if err != nil {
+			return nil, err
+		}
+		if len(schemas) == 0 {
+			return nil, c.errorf(at, "must be a non-empty array of schemas")
+		}
+		return func(s *state, v ast.Value, instance string) {
+			for _, n := range schemas {
+				n.validate(s, v, instance)
+			}
+		}, nil
+	case "anyOf":
+		schemas, err := c.schemaList(value, at)
+		if err != nil {
+			return nil, err
+		}
+		if len(schemas) == 0 {
+			return nil, c.errorf(at, "must be a non-empty array of schemas")
+		}
+		return func(s *state, v ast.Value, instance string) {
+			for _, n := range schemas {
+				if n.valid(s, v, instance) {
+					return
+				}
+			}
+			s.report(instance, at, "must match at least one of the schemas")
+		}, nil
+	case "oneOf":
+		schemas, err := c.schemaList(value, at)
+		if err != nil {
+			return nil, err
+		}
+		if len(schemas) == 0 {
+			return nil, c.errorf(at, "must be a non-empty array of schemas")
+		}
+		return func(s *state, v ast.Value, instance string) {
+			matched := 0
+			for _, n := range schemas {
+				if n.valid(s, v, instance) {
+					matched++
+				}
+			}
+			if matched != 1 {
+				s.report(instance, at, "must match exactly one of the schemas, not %d", matched)
+			}
+		}, nil
+	case "not":
+		not, err := c.compile(value, at)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if not.valid(s, v, instance) {
+				s.report(instance, at, "must not match the schema")
+			}
+		}, nil
+	case "if":
+		cond, err := c.compile(value, at)
+		if err != nil {
+			return nil, err
+		}
+		then, err := c.optional(obj, "then", pointer)
+		if err != nil {
+			return nil, err
+		}
+		otherwise, err := c.optional(obj, "else", pointer)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *state, v ast.Value, instance string) {
+			if cond.valid(s, v, instance) {
+				then.validate(s, v, instance)
+			} else {
+				otherwise.validate(s, v, instance)
+			}
+		}, nil
+	case "$ref":
+		return c.ref(value, at)
+	case "$defs":
+		// Compiled for their errors; they are used only through references
+		_, err := c.schemaMap(value, at)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// typeNames are the types the type keyword may name
+var typeNames = map[string]bool{"null": true, "boolean": true, "object": true, "array": true, "number": true, "string": true, "integer": true}
+
+func (c *compiler) typeKeyword(value ast.Value, at string) (check, error) {
+	var types []string
+	switch value := value.(type) {
+	case *ast.String:
+		types = []string{value.Value}
+	case *ast.Array:
+		for _, e := range value.Elements {
+			s, ok := e.(*ast.String)
+			if !ok {
+				return nil, c.errorf(at, "must be a string or an array of strings")
+			}
+			types = append(types, s.Value)
+		}
+	default:
+		return nil, c.errorf(at, "must be a string or an array of strings")
+	}
+	for _, t := range types {
+		if !typeNames[t] {
+			return nil, c.errorf(at, "unknown type %q", t)
+		}
+	}
+	return func(s *state, v ast.Value, instance string) {
+		for _, t := range types {
+			if hasType(v, t) {
+				return
+			}
+		}
+		s.report(instance, at, "expected %s, got %s", strings.Join(types, " or "), ast.TypeName(v))
+	}, nil
+}
+
+// hasType reports whether v is of the schema type t, where a number with no
+// fraction, such as 1.0, is an integer
+func hasType(v ast.Value, t string) bool {
+	if t == "integer" {
+		n, ok := ratOf(v)
+		return ok && n.IsInt()
+	}
+	return ast.TypeName(v) == t
+}
+
+// ratOf returns the exact value of a number, so that bounds and multiples
+// are checked without rounding
+func ratOf(v ast.Value) (*big.Rat, bool) {
+	n, ok := v.(*ast.Number)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetString(n.Value)
+}
+
+func (c *compiler) limit(key string, value ast.Value, at string) (check, error) {
+	limit, ok := ratOf(value)
+	if !ok {
+		return nil, c.errorf(at, "must be a number")
+	}
+	text := value.(*ast.Number).Value
+	return func(s *state, v ast.Value, instance string) {
+		n, ok := ratOf(v)
+		if !ok {
+			return
+		}
+		cmp := n.Cmp(limit)
+		switch {
+		case key == "minimum" && cmp < 0:
+			s.report(instance, at, "must be at least %s", text)
+		case key == "exclusiveMinimum" && cmp <= 0:
+			s.report(instance, at, "must be greater than %s", text)
+		case key == "maximum" && cmp > 0:
+			s.report(instance, at, "must be at most %s", text)
+		case key == "exclusiveMaximum" && cmp >= 0:
+			s.report(instance, at, "must be less than %s", text)
+		}
+	}, nil
+}
+
+// count returns the value of a keyword that must be a non-negative integer
+func (c *compiler) count(value ast.Value, at string) (int, error) {
+	if n, ok := value.(*ast.Number); ok {
+		if i, err := strconv.Atoi(n.Value); err == nil && i >= 0 {
+			return i, nil
+		}
+	}
+	return 0, c.errorf(at, "must be a non-negative integer")
+}
+
+// bound reports a size beyond a min or max keyword
+func (c *compiler) bound(s *state, instance, at, key string, size, limit int, unit string) {
+	if strings.HasPrefix(key, "min") && size < limit {
+		s.report(instance, at, "must have at least %d %s", limit, unit)
+	} else if strings.HasPrefix(key, "max") && size > limit {
+		s.report(instance, at, "must have at most %d %s", limit, unit)
+	}
+}
+
+func (c *compiler) compilePattern(value ast.Value, at string) (*regexp.Regexp, error) {
+	s, ok := value.(*ast.String)
+	if !ok {
+		return nil, c.errorf(at, "must be a string")
+	}
+	re, err := regexp.Compile(s.Value)
+	if err != nil {
+		return nil, c.errorf(at, "invalid pattern: %v", err)
+	}
+	return re, nil
+}
+
+// schemaMap compiles an object whose members are schemas
+func (c *compiler) schemaMap(value ast.Value, at string) (map[string]*node, error) {
+	obj, ok := value.(*ast.Object)
+	if !ok {
+		return nil, c.errorf(at, "must be an object of schemas")
+	}
+	nodes := make(map[string]*node, len(obj.Pairs))
+	for _, name := range obj.OrderedKeys() {
+		n, err := c.compile(obj.Pairs[name], join(at, name))
+		if err != nil {
+			return nil, err
+		}
+		nodes[name] = n
+	}
+	return nodes, nil
+}
+
+// schemaList compiles an array of schemas
+func (c *compiler) schemaList(value ast.Value, at string) ([]*node, error) {
+	array, ok := value.(*ast.Array)
+	if !ok {
+		return nil, c.errorf(at, "must be an array of schemas")
+	}
+	nodes := make([]*node, len(array.Elements))
+	for i, e := range array.Elements {
+		n, err := c.compile(e, at+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
+
+// pattern is a member of patternProperties
+type pattern struct {
+	re   *regexp.Regexp
+	node *node
+}
+
+// patterns compiles the patternProperties of the schema obj at pointer
+func (c *compiler) patterns(obj *ast.Object, pointer string) ([]pattern, error) {
+	value, ok := obj.Pairs["patternProperties"]
+	if !ok {
+		return nil, nil
+	}
+	at := join(pointer, "patternProperties")
+	nodes, err := c.schemaMap(value, at)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []pattern
+	for _, key := range value.(*ast.Object).OrderedKeys() {
+		re, err := c.compilePattern(&ast.String{Value: key}, join(at, key))
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern{re: re, node: nodes[key]})
+	}
+	return patterns, nil
+}
+
+// optional compiles the member key of the schema obj, or returns the schema
+// that accepts everything when it has none
+func (c *compiler) optional(obj *ast.Object, key, pointer string) (*node, error) {
+	value, ok := obj.Pairs[key]
+	if !ok {
+		return &node{pointer: join(pointer, key)}, nil
+	}
+	return c.compile(value, join(pointer, key))
+}
+
+func (c *compiler) ref(value ast.Value, at string) (check, error) {
+	ref, ok := value.(*ast.String)
+	if !ok {
+		return nil, c.errorf(at, "must be a string")
+	}
+	fragment, found := strings.CutPrefix(ref.Value, "#")
+	if !found {
+		return nil, c.errorf(at, "only references within the schema, starting with \"#\", are supported")
+	}
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, c.errorf(at, "invalid reference %q: %v", ref.Value, err)
+	}
+	v, err := ast.Lookup(c.doc, pointer)
+	if err != nil {
+		return nil, c.errorf(at, "reference %q not found", ref.Value)
+	}
+	target, err := c.compile(v, pointer)
+	if err != nil {
+		return nil, err
+	}
+	return func(s *state, v ast.Value, instance string) {
+		// Following the same reference for the same value again would never end
+		key := target.pointer + "\x00" + instance
+		if s.active[key] {
+			s.report(instance, at, "circular reference")
+			return
+		}
+		s.active[key] = true
+		target.validate(s, v, instance)
+		delete(s.active, key)
+	}, nil
+}
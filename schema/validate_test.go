@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	const person = `{
+		"type": "object",
+		"required": ["name", "age"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "maxLength": 5},
+			"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+			"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "maxItems": 2, "uniqueItems": true}
+		},
+		"additionalProperties": false
+	}`
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		want   []string
+	}{
+		{"valid", person, `{"name":"Ann","age":30.0,"tags":["a","b"]}`, nil},
+		{
+			"wrong types",
+			person,
+			`{"name":1,"age":1.5}`,
+			[]string{
+				`/name: expected string, got number (schema /properties/name/type)`,
+				`/age: expected integer, got number (schema /properties/age/type)`,
+			},
+		},
+		{"not an object", person, `[]`, []string{`"": expected object, got array (schema /type)`}},
+		{
+			"missing required",
+			person,
+			`{"email":"x@y"}`,
+			[]string{
+				`"": missing required property "name" (schema /required)`,
+				`"": missing required property "age" (schema /required)`,
+			},
+		},
+		{
+			"bounds",
+			person,
+			`{"name":"","age":150}`,
+			[]string{
+				`/name: must have at least 2 characters (schema /properties/name/minLength)`,
+				`/age: must be less than 150 (schema /properties/age/exclusiveMaximum)`,
+			},
+		},
+		{"length counts characters", person, `{"name":"Zoë🙂x","age":0}`, nil},
+		{"pattern", person, `{"name":"ab","age":1,"email":"nope"}`, []string{`/email: must match "^[^@]+@[^@]+$" (schema /properties/email/pattern)`}},
+		{
+			"items",
+			person,
+			`{"name":"ab","age":1,"tags":["a","c","a"]}`,
+			[]string{
+				`/tags/1: must be one of "a", "b" (schema /properties/tags/items/enum)`,
+				`/tags: must have at most 2 items (schema /properties/tags/maxItems)`,
+				`/tags: items 0 and 2 are equal (schema /properties/tags/uniqueItems)`,
+			},
+		},
+		{"additional property", person, `{"name":"ab","age":1,"x":1}`, []string{`/x: not allowed (schema /additionalProperties)`}},
+		{"false schema", `false`, `1`, []string{`"": not allowed (schema "")`}},
+		{"true schema", `true`, `{"a":[1]}`, nil},
+		{"type list", `{"type":["string","null"]}`, `true`, []string{`"": expected string or null, got boolean (schema /type)`}},
+		{"const", `{"const":{"a":[1,2]}}`, `{"a":[1,2.0]}`, nil},
+		{"const mismatch", `{"const":"x"}`, `"y"`, []string{`"": must be "x" (schema /const)`}},
+		{"multipleOf", `{"multipleOf":0.1}`, `0.3`, nil},
+		{"not a multiple", `{"multipleOf":0.1}`, `0.35`, []string{`"": must be a multiple of 0.1 (schema /multipleOf)`}},
+		{"exact bounds", `{"minimum":0.1,"maximum":1e2}`, `100`, nil},
+		{"below minimum", `{"minimum":0.1}`, `0.09999999999999999999`, []string{`"": must be at least 0.1 (schema /minimum)`}},
+		{"exclusive minimum", `{"exclusiveMinimum":0}`, `0`, []string{`"": must be greater than 0 (schema /exclusiveMinimum)`}},
+		{"properties bounds", `{"minProperties":2}`, `{"a":1}`, []string{`"": must have at least 2 properties (schema /minProperties)`}},
+		{
+			"pattern properties",
+			`{"patternProperties":{"^x-":{"type":"string"}},"properties":{"id":{}},"additionalProperties":{"type":"number"}}`,
+			`{"id":true,"x-a":"s","x-b":1,"n":2,"o":"p"}`,
+			[]string{
+				`/x-b: expected string, got number (schema /patternProperties/^x-/type)`,
+				`/o: expected number, got string (schema /additionalProperties/type)`,
+			},
+		},
+		{
+			"prefix items",
+			`{"prefixItems":[{"type":"string"},{"type":"number"}],"items":false}`,
+			`["a",1,null]`,
+			[]string{`/2: not allowed (schema /items)`},
+		},
+		{"allOf", `{"allOf":[{"minimum":1},{"maximum":2}]}`, `3`, []string{`"": must be at most 2 (schema /allOf/1/maximum)`}},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"minimum":5}]}`, `1`, []string{`"": must match at least one of the schemas (schema /anyOf)`}},
+		{"anyOf matched", `{"anyOf":[{"type":"string"},{"minimum":5}]}`, `6`, nil},
+		{"oneOf", `{"oneOf":[{"type":"number"},{"minimum":5}]}`, `6`, []string{`"": must match exactly one of the schemas, not 2 (schema /oneOf)`}},
+		{"not", `{"not":{"type":"null"}}`, `null`, []string{`"": must not match the schema (schema /not)`}},
+		{
+			"if then else",
+			`{"if":{"properties":{"kind":{"const":"a"}}},"then":{"required":["a"]},"else":{"required":["b"]}}`,
+			`[{"kind":"a"},{"kind":"c"},{"kind":"a","a":1}]`,
+			nil,
+		},
+		{
+			"if then else applied",
+			`{"items":{"if":{"properties":{"kind":{"const":"a"}}},"then":{"required":["a"]},"else":{"required":["b"]}}}`,
+			`[{"kind":"a"},{"kind":"c"},{"kind":"a","a":1}]`,
+			[]string{
+				`/0: missing required property "a" (schema /items/then/required)`,
+				`/1: missing required property "b" (schema /items/else/required)`,
+			},
+		},
+		{
+			"references",
+			`{"$defs":{"node":{"type":"object","properties":{"value":{"type":"integer"},"next":{"$ref":"#/$defs/node"}}}},"$ref":"#/$defs/node"}`,
+			`{"value":1,"next":{"value":2,"next":{"value":"3"}}}`,
+			[]string{`/next/next/value: expected integer, got string (schema /$defs/node/properties/value/type)`},
+		},
+		{"escaped reference", `{"$defs":{"a b":{"type":"null"}},"$ref":"#/$defs/a%20b"}`, `1`, []string{`"": expected null, got number (schema /$defs/a b/type)`}},
+		{"circular reference", `{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"$ref":"#/$defs/a"}},"$ref":"#/$defs/a"}`, `1`, []string{`"": circular reference (schema /$defs/b/$ref)`}},
+		{"ignored keywords", `{"title":"x","format":"email","description":"y"}`, `"z"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile(mustParse(t, tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range s.Validate(mustParse(t, tt.doc)) {
+				got = append(got, fmt.Sprint(v))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`1`, `schema: "": a schema must be an object or a boolean`},
+		{`{"type":"strin"}`, `schema: /type: unknown type "strin"`},
+		{`{"type":1}`, `schema: /type: must be a string or an array of strings`},
+		{`{"properties":{"a":{"minLength":-1}}}`, `schema: /properties/a/minLength: must be a non-negative integer`},
+		{`{"pattern":"("}`, "schema: /pattern: invalid pattern: error parsing regexp: missing closing ): `(`"},
+		{`{"patternProperties":{"[":{}}}`, "schema: /patternProperties/[: invalid pattern: error parsing regexp: missing closing ]: `[`"},
+		{`{"required":[1]}`, `schema: /required: must be an array of strings`},
+		{`{"multipleOf":0}`, `schema: /multipleOf: must be a number greater than 0`},
+		{`{"anyOf":[]}`, `schema: /anyOf: must be a non-empty array of schemas`},
+		{`{"allOf":[{"items":3}]}`, `schema: /allOf/0/items: a schema must be an object or a boolean`},
+		{`{"$ref":"#/$defs/missing"}`, `schema: /$ref: reference "#/$defs/missing" not found`},
+		{`{"$ref":"other.json#/a"}`, `schema: /$ref: only references within the schema, starting with "#", are supported`},
+	}
+	for _, tt := range tests {
+		_, err := Compile(mustParse(t, tt.schema))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: expected error %q, got %v", tt.schema, tt.want, err)
+		}
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	if s := MustCompile(mustParse(t, `{"type":"string"}`)); len(s.Validate(mustParse(t, `1`))) != 1 {
+		t.Error("expected the compiled schema to reject a number")
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected MustCompile to panic on an invalid schema")
+		}
+	}()
+	MustCompile(mustParse(t, `{"type":"strin"}`))
+}
+
+func TestValidateConcurrently(t *testing.T) {
+	s, err := Compile(mustParse(t, `{"items":{"type":"integer"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := mustParse(t, `[1,2,"3"]`)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := s.Validate(doc); len(got) != 1 || !strings.HasPrefix(got[0].InstancePath, "/2") {
+				t.Errorf("expected one violation at /2, got %v", got)
+			}
+		}()
+	}
+	wg.Wait()
+}
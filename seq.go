@@ -0,0 +1,141 @@
+package jsonparser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// recordSeparator begins every record of a JSON text sequence
+const recordSeparator = 0x1E
+
+// ErrTruncatedRecord is wrapped by the SequenceError of a record holding a
+// number, true, false or null not followed by whitespace, which RFC 7464
+// treats as cut short
+var ErrTruncatedRecord = errors.New("the record may be truncated")
+
+// errLeadingData is the error of data before the first record separator
+var errLeadingData = errors.New("data before the first record separator")
+
+// SequenceError is a record of a JSON text sequence that could not be used.
+// The decoder has moved past it, so the next Decode reads the next record,
+// as RFC 7464 asks of readers.
+type SequenceError struct {
+	Record int   // the record's number, counting from 1; 0 for data before the first record
+	Offset int64 // byte offset of the record's separator, or 0 for data before the first record
+	Err    error
+}
+
+func (e *SequenceError) Error() string {
+	if e.Record == 0 {
+		return fmt.Sprintf("%v (byte offset %d)", e.Err, e.Offset)
+	}
+	return fmt.Sprintf("record %d: %v (byte offset %d)", e.Record, e.Err, e.Offset)
+}
+
+// Unwrap returns the parse error, ErrTruncatedRecord, or the error for data
+// before the first record
+func (e *SequenceError) Unwrap() error {
+	return e.Err
+}
+
+// SequenceDecoder reads a JSON text sequence (RFC 7464, media type
+// application/json-seq): records that each begin with the byte 0x1E and hold
+// one JSON document, usually followed by a newline.
+type SequenceDecoder struct {
+	r       *bufio.Reader
+	opts    []Option
+	offset  int64 // stream offset of the next byte to read
+	records int   // records read so far
+	started bool  // the first record separator has been read
+}
+
+// NewSequenceDecoder returns a SequenceDecoder reading from r, parsing each
+// record with the options
+func NewSequenceDecoder(r io.Reader, opts ...Option) *SequenceDecoder {
+	return &SequenceDecoder{r: bufio.NewReader(r), opts: opts}
+}
+
+// Decode returns the document of the next record, skipping empty ones. It
+// returns io.EOF after the last record. A record that does not parse, or
+// looks truncated, yields a *SequenceError, and Decode can be called again
+// to carry on with the records after it.
+func (d *SequenceDecoder) Decode() (Value, error) {
+	for {
+		start := d.offset
+		data, err := d.r.ReadBytes(recordSeparator)
+		d.offset += int64(len(data))
+		if err == io.EOF && len(data) == 0 {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = bytes.TrimSuffix(data, []byte{recordSeparator})
+		blank := len(bytes.TrimSpace(data)) == 0
+
+		if !d.started {
+			d.started = true
+			if !blank {
+				return nil, &SequenceError{Offset: start, Err: errLeadingData}
+			}
+			continue
+		}
+		if blank {
+			continue // consecutive separators do not make empty records
+		}
+		d.records++
+		separator := start - 1
+		v, err := ParseBytes(data, d.opts...)
+		if err != nil {
+			return nil, &SequenceError{Record: d.records, Offset: separator, Err: err}
+		}
+		switch v.(type) {
+		case *Number, *Boolean, *Null:
+			if last := data[len(data)-1]; last != ' ' && last != '\t' && last != '\n' && last != '\r' {
+				return nil, &SequenceError{Record: d.records, Offset: separator, Err: ErrTruncatedRecord}
+			}
+		}
+		return v, nil
+	}
+}
+
+// SequenceEncoder writes a JSON text sequence, one record per value
+type SequenceEncoder struct {
+	w       io.Writer
+	opts    MarshalOptions
+	started bool // whether a record has been written, after which the BOM option is ignored
+}
+
+// NewSequenceEncoder returns a SequenceEncoder writing to w
+func NewSequenceEncoder(w io.Writer) *SequenceEncoder {
+	return &SequenceEncoder{w: w}
+}
+
+// SetOptions sets how values are marshaled
+func (e *SequenceEncoder) SetOptions(o MarshalOptions) {
+	e.opts = o
+}
+
+// Encode writes v as one record: the separator 0x1E, the compact JSON of v
+// and a newline, in a single Write so that records from several writers
+// sharing w do not interleave
+func (e *SequenceEncoder) Encode(v interface{}) error {
+	opts := e.opts
+	opts.BOM = false
+	data, err := opts.Marshal(v)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, 0, len(data)+len(utf8BOM)+2)
+	if e.opts.BOM && !e.started {
+		record = append(record, utf8BOM...)
+	}
+	e.started = true
+	record = append(record, recordSeparator)
+	record = append(record, data...)
+	_, err = e.w.Write(append(record, '\n'))
+	return err
+}
@@ -0,0 +1,133 @@
+package jsonparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// decodeAll reads every record, writing documents as compact JSON and
+// errors as "error: ..." lines
+func decodeAll(t *testing.T, input string) []string {
+	t.Helper()
+	dec := NewSequenceDecoder(strings.NewReader(input))
+	var got []string
+	for {
+		v, err := dec.Decode()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			var seqErr *SequenceError
+			if !errors.As(err, &seqErr) {
+				t.Fatalf("Decode() error = %v, want a *SequenceError", err)
+			}
+			got = append(got, "error: "+err.Error())
+			continue
+		}
+		data, err := Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+}
+
+func TestSequenceDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"records", "\x1e{\"a\":1}\n\x1e[true]\n\x1e\"s\"\n", []string{`{"a":1}`, `[true]`, `"s"`}},
+		{"no trailing newline", "\x1e{}\x1e[]", []string{`{}`, `[]`}},
+		{"empty records skipped", "\x1e\x1e\n\x1e{}\n\x1e", []string{`{}`}},
+		{"whitespace before first record", " \n\x1e1\n", []string{`1`}},
+		{"scalars followed by whitespace", "\x1e1\n\x1etrue \x1enull\t", []string{`1`, `true`, `null`}},
+		{"multi-line record", "\x1e{\n  \"a\": [1,\n 2]\n}\n", []string{`{"a":[1,2]}`}},
+		{
+			"truncated number",
+			"\x1e12\x1e{}\n",
+			[]string{"error: record 1: the record may be truncated (byte offset 0)", `{}`},
+		},
+		{
+			"bad record skipped",
+			"\x1e{}\n\x1e{\"a\":\n\x1e[1]\n",
+			[]string{`{}`, "error: record 2: Parser error at line 2, column 1: expected a valid value, got end of input at $.a (byte offset 4)", `[1]`},
+		},
+		{
+			"data before first record",
+			"{}\n\x1e[]\n",
+			[]string{"error: data before the first record separator (byte offset 0)", `[]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeAll(t, tt.input)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("decoded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequenceDecoder_Options(t *testing.T) {
+	dec := NewSequenceDecoder(strings.NewReader("\x1e[[[1]]]\n\x1e[1]\n"), WithMaxDepth(2))
+
+	_, err := dec.Decode()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("first record: error = %v, want a *LimitError", err)
+	}
+	if _, err := dec.Decode(); err != nil {
+		t.Fatalf("second record: error = %v", err)
+	}
+}
+
+func TestSequenceEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewSequenceEncoder(&buf)
+	for _, v := range []interface{}{map[string]interface{}{"a": 1}, 2, "s"} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "\x1e{\"a\":1}\n\x1e2\n\x1e\"s\"\n"
+	if buf.String() != want {
+		t.Fatalf("Encode wrote %q, want %q", buf.String(), want)
+	}
+
+	got := decodeAll(t, buf.String())
+	if strings.Join(got, "|") != `{"a":1}|2|"s"` {
+		t.Errorf("round trip decoded %q", got)
+	}
+}
+
+func TestSequenceEncoder_BOM(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewSequenceEncoder(&buf)
+	enc.SetOptions(MarshalOptions{BOM: true})
+	for _, v := range []interface{}{1, 2} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "\xef\xbb\xbf\x1e1\n\x1e2\n"; buf.String() != want {
+		t.Errorf("Encode wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSequenceEncoder_Error(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewSequenceEncoder(&buf)
+	if err := enc.Encode(make(chan int)); err == nil {
+		t.Fatal("Encode(chan) succeeded, want an error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Encode wrote %q after failing", buf.String())
+	}
+}
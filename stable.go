@@ -0,0 +1,96 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StableErrorsVersion is the version of the messages WithStableErrors
+// produces, which they start with. Within a version a code's message never
+// changes; a release that has to change one moves to the next version.
+const StableErrorsVersion = 1
+
+// stableMessages holds the fixed message of each code under WithStableErrors
+var stableMessages = map[ErrorCode]string{
+	CodeUnexpectedCharacter: "unexpected character",
+	CodeInvalidLiteral:      "invalid literal",
+	CodeSingleQuotes:        "string in single quotes",
+	CodeUnquotedString:      "string without quotes",
+	CodeUnterminatedString:  "unterminated string",
+	CodeUnterminatedComment: "unterminated comment",
+	CodeBadEscape:           "invalid escape in string",
+	CodeInvalidUTF8:         "invalid UTF-8 in string",
+	CodeControlCharacter:    "control character in string",
+	CodeBadNumber:           "invalid number",
+	CodeRead:                "reading the input failed",
+	CodeDialect:             "rejected by the dialect",
+	CodeBadHook:             "dialect hook failed",
+	CodeUnexpectedToken:     "unexpected token",
+	CodeUnexpectedEOF:       "unexpected end of input",
+	CodeTrailingData:        "data after the document",
+	CodeTrailingComma:       "trailing comma",
+	CodeMissingComma:        "missing comma",
+	CodeMissingColon:        "missing colon",
+	CodeDuplicateKey:        "duplicate key",
+	CodeTooDeep:             "nesting too deep",
+	CodeTooManyKeys:         "too many object members",
+	CodeTooManyElements:     "too many array elements",
+	CodeTooManyErrors:       "too many errors",
+	CodeInvalidValue:        "invalid value",
+	CodeCanceled:            "canceled",
+	CodeInternal:            "internal error",
+}
+
+// StableError is an error reported WithStableErrors. Its message is made
+// of the version, the code, the position and the code's fixed message,
+// such as "v1 E_TRAILING_COMMA at line 1, column 16: trailing comma", and
+// stays the same from release to release, so golden files can hold it.
+// The error it stands for, with the full description, is Err.
+type StableError struct {
+	Code   ErrorCode
+	Line   int // 0 when the error has no position
+	Column int
+	Err    error
+}
+
+func (e *StableError) Error() string {
+	msg, ok := stableMessages[e.Code]
+	if !ok {
+		msg = "error"
+	}
+	if e.Line == 0 {
+		return fmt.Sprintf("v%d %s: %s", StableErrorsVersion, e.Code, msg)
+	}
+	return fmt.Sprintf("v%d %s at line %d, column %d: %s", StableErrorsVersion, e.Code, e.Line, e.Column, msg)
+}
+
+// Unwrap returns the error the StableError stands for
+func (e *StableError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns Code
+func (e *StableError) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+// stabilize replaces err with a StableError, and each error of an
+// ErrorList with one. Errors without a code, which come from outside the
+// package, are left as they are.
+func stabilize(err error) error {
+	var list ErrorList
+	if errors.As(err, &list) {
+		stable := make(ErrorList, len(list))
+		for i, e := range list {
+			stable[i] = stabilize(e)
+		}
+		return stable
+	}
+	var stable *StableError
+	code := ErrorCodeOf(err)
+	if code == "" || errors.As(err, &stable) {
+		return err
+	}
+	pos, _ := errorPosition(err)
+	return &StableError{Code: code, Line: pos.Line, Column: pos.Column, Err: err}
+}
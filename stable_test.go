@@ -0,0 +1,89 @@
+package jsonparser
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithStableErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  []Option
+		want  string
+	}{
+		{`{"a": 1,}`, nil, "v1 E_TRAILING_COMMA at line 1, column 9: trailing comma"},
+		{`{"a" 1}`, nil, "v1 E_MISSING_COLON at line 1, column 6: missing colon"},
+		{`[1 2]`, nil, "v1 E_MISSING_COMMA at line 1, column 4: missing comma"},
+		{`[tru]`, nil, "v1 E_INVALID_LITERAL at line 1, column 2: invalid literal"},
+		{"\n  \"open", nil, "v1 E_UNTERMINATED_STRING at line 2, column 3: unterminated string"},
+		{`[1] 2`, nil, "v1 E_TRAILING_DATA at line 1, column 5: data after the document"},
+		{`[[1]]`, []Option{WithMaxDepth(1)}, "v1 E_TOO_DEEP at line 1, column 2: nesting too deep"},
+		{`{"a": 1, "a": 2}`, []Option{WithStrictMode(true)}, "v1 E_DUPLICATE_KEY at line 1, column 10: duplicate key"},
+		{`[1,, 2,]`, []Option{WithRecovery(true)}, "v1 E_UNEXPECTED_TOKEN at line 1, column 4: unexpected token (and 1 more errors)"},
+		{`{a: 1}`, []Option{WithJSON5(true)}, ""},
+	}
+
+	for _, tt := range tests {
+		opts := append([]Option{WithStableErrors(true)}, tt.opts...)
+		_, err := Parse(tt.input, opts...)
+		if got := errString(err); got != tt.want {
+			t.Errorf("Parse(%q) error = %q, want %q", tt.input, got, tt.want)
+		}
+		if got := errString(Validate([]byte(tt.input), opts...)); got != tt.want {
+			t.Errorf("Validate(%q) error = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestStableError_Unwrap(t *testing.T) {
+	_, err := Parse(`{"a": 1,}`, WithStableErrors(true))
+	var stable *StableError
+	if !errors.As(err, &stable) {
+		t.Fatalf("expected a *StableError, got %T", err)
+	}
+	var tokenErr *UnexpectedTokenError
+	if !errors.As(err, &tokenErr) || !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected the StableError to wrap the syntax error, got %#v", stable.Err)
+	}
+	if code := ErrorCodeOf(err); code != CodeTrailingComma {
+		t.Errorf("ErrorCodeOf() = %s, want %s", code, CodeTrailingComma)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(ctx, `[1]`, WithStableErrors(true)); errString(err) != "v1 E_CANCELED: canceled" {
+		t.Errorf("ParseContext() error = %v, want the stable cancellation message", err)
+	}
+}
+
+func TestStableMessages(t *testing.T) {
+	// Every code has a fixed message, and no two codes share one
+	seen := make(map[string]ErrorCode)
+	for code, msg := range stableMessages {
+		if other, ok := seen[msg]; ok {
+			t.Errorf("%s and %s share the message %q", code, other, msg)
+		}
+		seen[msg] = code
+	}
+	codes := []ErrorCode{
+		CodeUnexpectedCharacter, CodeInvalidLiteral, CodeSingleQuotes, CodeUnquotedString,
+		CodeUnterminatedString, CodeUnterminatedComment, CodeBadEscape, CodeInvalidUTF8,
+		CodeControlCharacter, CodeBadNumber, CodeRead, CodeDialect, CodeBadHook, CodeUnexpectedToken,
+		CodeUnexpectedEOF, CodeTrailingData, CodeTrailingComma, CodeMissingComma,
+		CodeMissingColon, CodeDuplicateKey, CodeTooDeep, CodeTooManyKeys,
+		CodeTooManyElements, CodeTooManyErrors, CodeInvalidValue, CodeCanceled, CodeInternal,
+	}
+	for _, code := range codes {
+		if stableMessages[code] == "" {
+			t.Errorf("%s has no stable message", code)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Command minimal is the smallest program embedding the parser. The build
+// matrix compiles it with the jsonparser_minimal tag to track the size and
+// dependencies of the core.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func main() {
+	data, err := io.ReadAll(os.Stdin)
+	if err == nil {
+		err = jsonparser.Validate(data)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,344 @@
+package tiny
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Lookup errors
+var (
+	ErrNotFound = errors.New("tiny: value not found")
+	ErrKind     = errors.New("tiny: value is of another kind")
+	ErrRange    = errors.New("tiny: number out of range")
+)
+
+// Get returns the raw text of the value at path in data, and its kind. Each
+// path element is an object key, or the decimal index of an array element.
+// Only the values on the way to it are checked; the rest of data is
+// skipped as quickly as it can be. When an object repeats a key, the first
+// one is used. The returned slice shares data.
+func Get(data []byte, path ...string) ([]byte, Kind, error) {
+	s := scanner{data: data}
+	for _, elem := range path {
+		s.skipSpace()
+		var err error
+		switch s.peek() {
+		case '{':
+			err = s.member(elem)
+		case '[':
+			err = s.element(elem)
+		default:
+			if _, err = s.value(); err == nil {
+				err = ErrNotFound
+			}
+		}
+		if err != nil {
+			return nil, Invalid, err
+		}
+	}
+	s.skipSpace()
+	start := s.pos
+	kind, err := s.value()
+	if err != nil {
+		return nil, Invalid, err
+	}
+	return data[start:s.pos], kind, nil
+}
+
+// member moves from the opening brace at pos to the value of key
+func (s *scanner) member(key string) error {
+	s.pos++
+	s.skipSpace()
+	if s.peek() == '}' {
+		return ErrNotFound
+	}
+	for {
+		start := s.pos
+		end, err := s.key()
+		if err != nil {
+			return err
+		}
+		if keyEqual(s.data[start+1:end-1], key) {
+			s.skipSpace()
+			return nil
+		}
+		if err := s.next('}'); err != nil {
+			return err
+		}
+	}
+}
+
+// element moves from the opening bracket at pos to the element at index
+func (s *scanner) element(index string) error {
+	n, ok := parseIndex(index)
+	if !ok {
+		return ErrNotFound
+	}
+	s.pos++
+	s.skipSpace()
+	if s.peek() == ']' {
+		return ErrNotFound
+	}
+	for i := 0; i < n; i++ {
+		if err := s.next(']'); err != nil {
+			return err
+		}
+	}
+	s.skipSpace()
+	return nil
+}
+
+// next skips the value at pos and the comma after it, returning
+// ErrNotFound when close ends the container instead
+func (s *scanner) next(close byte) error {
+	if _, err := s.value(); err != nil {
+		return err
+	}
+	s.skipSpace()
+	switch s.peek() {
+	case ',':
+		s.pos++
+		s.skipSpace()
+		return nil
+	case close:
+		return ErrNotFound
+	}
+	return s.fail("expected ',' or a closing bracket")
+}
+
+// parseIndex parses a path element as an array index
+func parseIndex(s string) (int, bool) {
+	if s == "" || len(s) > 1 && s[0] == '0' {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) || n > (math.MaxInt32-9)/10 {
+			return 0, false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n, true
+}
+
+// keyEqual reports whether the raw text of a key, between its quotes,
+// spells key once its escapes are decoded
+func keyEqual(raw []byte, key string) bool {
+	for i := 0; i < len(raw); {
+		if raw[i] != '\\' {
+			if key == "" || raw[i] != key[0] {
+				return false
+			}
+			key = key[1:]
+			i++
+			continue
+		}
+		r, n := decodeEscape(raw, i)
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], r)
+		if len(key) < size || key[:size] != string(buf[:size]) {
+			return false
+		}
+		key = key[size:]
+		i += n
+	}
+	return key == ""
+}
+
+// decodeEscape decodes the escape at i in the raw text of a valid string,
+// returning the character and how many bytes the escape took. A \u escape
+// of half a surrogate pair decodes to U+FFFD unless the other half follows.
+func decodeEscape(raw []byte, i int) (rune, int) {
+	switch raw[i+1] {
+	case 'b':
+		return '\b', 2
+	case 'f':
+		return '\f', 2
+	case 'n':
+		return '\n', 2
+	case 'r':
+		return '\r', 2
+	case 't':
+		return '\t', 2
+	case 'u':
+		r := hex4(raw[i+2 : i+6])
+		if r >= 0xD800 && r < 0xDC00 && i+12 <= len(raw) && raw[i+6] == '\\' && raw[i+7] == 'u' {
+			if lo := hex4(raw[i+8 : i+12]); lo >= 0xDC00 && lo < 0xE000 {
+				return (r-0xD800)<<10 | (lo - 0xDC00) + 0x10000, 12
+			}
+		}
+		if r >= 0xD800 && r < 0xE000 {
+			return utf8.RuneError, 6
+		}
+		return r, 6
+	}
+	return rune(raw[i+1]), 2 // ", \ or /
+}
+
+// hex4 decodes four hex digits, which the scanner has checked
+func hex4(digits []byte) rune {
+	var r rune
+	for _, c := range digits {
+		switch {
+		case isDigit(c):
+			r = r<<4 | rune(c-'0')
+		case c >= 'a' && c <= 'f':
+			r = r<<4 | rune(c-'a'+10)
+		default:
+			r = r<<4 | rune(c-'A'+10)
+		}
+	}
+	return r
+}
+
+// AppendString appends the string at path in data to dst with its escapes
+// decoded, so a caller can reuse one buffer for every string it reads
+func AppendString(dst, data []byte, path ...string) ([]byte, error) {
+	raw, kind, err := Get(data, path...)
+	if err != nil {
+		return dst, err
+	}
+	if kind != String {
+		return dst, ErrKind
+	}
+	return AppendUnquoted(dst, raw), nil
+}
+
+// AppendUnquoted appends the JSON string raw, quotes included, to dst with
+// its escapes decoded. raw must be a valid string, such as one Get returned.
+func AppendUnquoted(dst, raw []byte) []byte {
+	raw = raw[1 : len(raw)-1]
+	for i := 0; i < len(raw); {
+		if raw[i] != '\\' {
+			start := i
+			for i < len(raw) && raw[i] != '\\' {
+				i++
+			}
+			dst = append(dst, raw[start:i]...)
+			continue
+		}
+		r, n := decodeEscape(raw, i)
+		dst = utf8.AppendRune(dst, r)
+		i += n
+	}
+	return dst
+}
+
+// GetInt returns the integer at path in data. Numbers with a fraction or an
+// exponent are of another kind, and ones that overflow an int64 are out of
+// range.
+func GetInt(data []byte, path ...string) (int64, error) {
+	raw, kind, err := Get(data, path...)
+	if err != nil {
+		return 0, err
+	}
+	if kind != Number {
+		return 0, ErrKind
+	}
+	neg := raw[0] == '-'
+	if neg {
+		raw = raw[1:]
+	}
+	var n uint64
+	for _, c := range raw {
+		if !isDigit(c) {
+			return 0, ErrKind
+		}
+		if n > (math.MaxUint64-9)/10 {
+			return 0, ErrRange
+		}
+		n = n*10 + uint64(c-'0')
+	}
+	switch {
+	case neg && n <= 1<<63:
+		return -int64(n), nil
+	case !neg && n < 1<<63:
+		return int64(n), nil
+	}
+	return 0, ErrRange
+}
+
+// GetFloat returns the number at path in data as a float64
+func GetFloat(data []byte, path ...string) (float64, error) {
+	raw, kind, err := Get(data, path...)
+	if err != nil {
+		return 0, err
+	}
+	if kind != Number {
+		return 0, ErrKind
+	}
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return 0, ErrRange
+	}
+	return f, nil
+}
+
+// GetBool returns the boolean at path in data
+func GetBool(data []byte, path ...string) (bool, error) {
+	raw, kind, err := Get(data, path...)
+	if err != nil {
+		return false, err
+	}
+	if kind != Bool {
+		return false, ErrKind
+	}
+	return raw[0] == 't', nil
+}
+
+// Each calls fn for every member of the object or element of the array in
+// data, in order, until fn returns false. key is the raw text of a member's
+// key, quotes included, and nil for array elements; value is the raw text
+// of the member or element.
+func Each(data []byte, fn func(key, value []byte, kind Kind) bool) error {
+	s := scanner{data: data}
+	s.skipSpace()
+	close := byte(']')
+	switch s.peek() {
+	case '{':
+		close = '}'
+	case '[':
+	default:
+		if _, err := s.value(); err != nil {
+			return err
+		}
+		return ErrKind
+	}
+	s.pos++
+	s.skipSpace()
+	if s.peek() == close {
+		return nil
+	}
+	for {
+		var key []byte
+		if close == '}' {
+			start := s.pos
+			end, err := s.key()
+			if err != nil {
+				return err
+			}
+			key = data[start:end]
+			s.skipSpace()
+		}
+		start := s.pos
+		kind, err := s.value()
+		if err != nil {
+			return err
+		}
+		if !fn(key, data[start:s.pos], kind) {
+			return nil
+		}
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+			s.skipSpace()
+		case close:
+			return nil
+		default:
+			return s.fail("expected ',' or a closing bracket")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package tiny
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var reading = []byte(`{
+	"device": "node-7",
+	"battery": {"volts": 3.71, "low": false},
+	"samples": [{"t": 0, "temp": 20.5}, {"t": 60, "temp": -1.25e1}],
+	"note": "café \"ok\"\n😀",
+	"déjà": 1,
+	"k\u00e9y": 2,
+	"device": "ignored"
+}`)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		path     []string
+		want     string
+		wantKind Kind
+		wantErr  error
+	}{
+		{nil, string(reading), Object, nil},
+		{[]string{"device"}, `"node-7"`, String, nil},
+		{[]string{"battery", "volts"}, `3.71`, Number, nil},
+		{[]string{"battery"}, `{"volts": 3.71, "low": false}`, Object, nil},
+		{[]string{"samples", "1", "temp"}, `-1.25e1`, Number, nil},
+		{[]string{"samples", "0"}, `{"t": 0, "temp": 20.5}`, Object, nil},
+		{[]string{"déjà"}, `1`, Number, nil},
+		{[]string{"kéy"}, `2`, Number, nil},
+		{[]string{"missing"}, "", Invalid, ErrNotFound},
+		{[]string{"samples", "2"}, "", Invalid, ErrNotFound},
+		{[]string{"samples", "01"}, "", Invalid, ErrNotFound},
+		{[]string{"samples", "x"}, "", Invalid, ErrNotFound},
+		{[]string{"device", "x"}, "", Invalid, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		got, kind, err := Get(reading, tt.path...)
+		if !errors.Is(err, tt.wantErr) || string(got) != tt.want || kind != tt.wantKind {
+			t.Errorf("Get(%q) = %q, %v, %v, want %q, %v, %v", tt.path, got, kind, err, tt.want, tt.wantKind, tt.wantErr)
+		}
+	}
+
+	var syntaxErr *SyntaxError
+	if _, _, err := Get([]byte(`{"a": [1,, 2], "b": 1}`), "b"); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected a syntax error on the way to the value, got %v", err)
+	}
+	if _, _, err := Get([]byte(`{"a": 1, "b": 2`), "a"); err != nil {
+		t.Errorf("expected the rest of the data to be skipped, got %v", err)
+	}
+}
+
+func TestGetTyped(t *testing.T) {
+	if got, err := AppendString([]byte("note: "), reading, "note"); err != nil || string(got) != "note: café \"ok\"\n😀" {
+		t.Errorf("AppendString() = %q, %v", got, err)
+	}
+	if _, err := AppendString(nil, reading, "battery"); err != ErrKind {
+		t.Errorf("AppendString(battery) error = %v, want ErrKind", err)
+	}
+	if got := AppendUnquoted(nil, []byte(`"\ud800 \/"`)); string(got) != "� /" {
+		t.Errorf("AppendUnquoted() = %q, want a lone surrogate replaced", got)
+	}
+	if got, err := GetFloat(reading, "samples", "1", "temp"); err != nil || got != -12.5 {
+		t.Errorf("GetFloat() = %v, %v", got, err)
+	}
+	if got, err := GetBool(reading, "battery", "low"); err != nil || got {
+		t.Errorf("GetBool() = %v, %v", got, err)
+	}
+
+	ints := []struct {
+		input   string
+		want    int64
+		wantErr error
+	}{
+		{`60`, 60, nil},
+		{`-9223372036854775808`, math.MinInt64, nil},
+		{`9223372036854775807`, math.MaxInt64, nil},
+		{`9223372036854775808`, 0, ErrRange},
+		{`99999999999999999999`, 0, ErrRange},
+		{`1.5`, 0, ErrKind},
+		{`"1"`, 0, ErrKind},
+	}
+	for _, tt := range ints {
+		if got, err := GetInt([]byte(tt.input)); got != tt.want || err != tt.wantErr {
+			t.Errorf("GetInt(%s) = %d, %v, want %d, %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGet_Allocations(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	n := testing.AllocsPerRun(100, func() {
+		GetInt(reading, "samples", "1", "t")
+		GetBool(reading, "battery", "low")
+		AppendString(buf[:0], reading, "note")
+	})
+	if n != 0 {
+		t.Errorf("lookups allocated %v times, want 0", n)
+	}
+}
+
+func TestEach(t *testing.T) {
+	var got []string
+	err := Each([]byte(`{"a": 1, "b\n": [2], "c": {}}`), func(key, value []byte, kind Kind) bool {
+		got = append(got, string(key)+"="+string(value)+":"+kind.String())
+		return true
+	})
+	want := []string{`"a"=1:number`, `"b\n"=[2]:array`, `"c"={}:object`}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Each() = %q, %v, want %q", got, err, want)
+	}
+
+	got = nil
+	err = Each([]byte(` [1, "x", null, 4] `), func(key, value []byte, kind Kind) bool {
+		got = append(got, string(value))
+		return len(got) < 3
+	})
+	if err != nil || !reflect.DeepEqual(got, []string{"1", `"x"`, "null"}) {
+		t.Errorf("Each() on an array = %q, %v, want it to stop after three", got, err)
+	}
+
+	if err := Each([]byte(`[]`), func(key, value []byte, kind Kind) bool { return true }); err != nil {
+		t.Errorf("Each() on an empty array = %v", err)
+	}
+	if err := Each([]byte(`1`), nil); err != ErrKind {
+		t.Errorf("Each() on a number = %v, want ErrKind", err)
+	}
+	var syntaxErr *SyntaxError
+	if err := Each([]byte(`[1 2]`), func(key, value []byte, kind Kind) bool { return true }); !errors.As(err, &syntaxErr) {
+		t.Errorf("Each() on bad data = %v, want a syntax error", err)
+	}
+}
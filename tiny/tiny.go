@@ -0,0 +1,325 @@
+// Package tiny is a small JSON core for microcontrollers and other TinyGo
+// targets. It validates JSON held in a byte slice, extracts values from it
+// by path and writes JSON into a caller's buffer, without building a tree.
+// It uses no reflection and no fmt, nests at most MaxDepth levels without
+// recursion, and allocates only for errors and for buffers the caller
+// lets grow.
+package tiny
+
+import "strconv"
+
+// MaxDepth is how deeply arrays and objects may nest
+const MaxDepth = 64
+
+// Kind is the type of a JSON value
+type Kind uint8
+
+// Value kinds
+const (
+	Invalid Kind = iota
+	Null
+	Bool
+	Number
+	String
+	Array
+	Object
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case Null:
+		return "null"
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Array:
+		return "array"
+	case Object:
+		return "object"
+	}
+	return "invalid"
+}
+
+// SyntaxError is malformed JSON text, found at a byte offset
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return "tiny: " + e.Msg + " at byte " + strconv.Itoa(e.Offset)
+}
+
+// Valid reports whether data is one JSON value, with optional whitespace
+// around it
+func Valid(data []byte) bool {
+	return Validate(data) == nil
+}
+
+// Validate returns a *SyntaxError for the first problem in data, or nil
+// when it is one JSON value with optional whitespace around it
+func Validate(data []byte) error {
+	s := scanner{data: data}
+	if _, err := s.value(); err != nil {
+		return err
+	}
+	s.skipSpace()
+	if s.pos < len(data) {
+		return s.fail("unexpected data after the value")
+	}
+	return nil
+}
+
+// scanner walks JSON text, checking it as it goes
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *scanner) fail(msg string) error {
+	return &SyntaxError{Offset: s.pos, Msg: msg}
+}
+
+// peek returns the byte at pos, or 0 at the end of the data
+func (s *scanner) peek() byte {
+	if s.pos < len(s.data) {
+		return s.data[s.pos]
+	}
+	return 0
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value skips the value after any whitespace at pos and returns its kind
+func (s *scanner) value() (Kind, error) {
+	s.skipSpace()
+	switch c := s.peek(); {
+	case c == '{':
+		return Object, s.container()
+	case c == '[':
+		return Array, s.container()
+	}
+	return s.scalar()
+}
+
+// scalar skips the string, number or literal at pos
+func (s *scanner) scalar() (Kind, error) {
+	switch c := s.peek(); {
+	case c == '"':
+		return String, s.str()
+	case c == 't':
+		return Bool, s.literal("true")
+	case c == 'f':
+		return Bool, s.literal("false")
+	case c == 'n':
+		return Null, s.literal("null")
+	case c == '-' || isDigit(c):
+		return Number, s.number()
+	case s.pos == len(s.data):
+		return Invalid, s.fail("unexpected end of data")
+	}
+	return Invalid, s.fail("unexpected character")
+}
+
+// Container states, for the walk in container
+const (
+	stateOpen   = iota // At an opening bracket
+	stateMember        // Expecting a member or element
+	stateAfter         // After a member or element
+)
+
+// container skips the array or object opening at pos. It keeps whether
+// each open container is an object in the bits of one word instead of
+// recursing, which is what bounds the nesting to MaxDepth.
+func (s *scanner) container() error {
+	var objects uint64 // Bit d is set when the container at depth d is an object
+	depth := 0
+	state := stateOpen
+	for {
+		switch state {
+		case stateOpen:
+			if depth == MaxDepth {
+				return s.fail("nesting too deep")
+			}
+			if s.data[s.pos] == '{' {
+				objects |= 1 << depth
+			} else {
+				objects &^= 1 << depth
+			}
+			depth++
+			s.pos++
+			s.skipSpace()
+			if s.peek() == closer(objects, depth) {
+				s.pos++
+				depth--
+				state = stateAfter
+			} else {
+				state = stateMember
+			}
+		case stateMember:
+			if objects&(1<<(depth-1)) != 0 {
+				if _, err := s.key(); err != nil {
+					return err
+				}
+			}
+			s.skipSpace()
+			if c := s.peek(); c == '{' || c == '[' {
+				state = stateOpen
+				continue
+			}
+			if _, err := s.scalar(); err != nil {
+				return err
+			}
+			state = stateAfter
+		case stateAfter:
+			if depth == 0 {
+				return nil
+			}
+			s.skipSpace()
+			switch s.peek() {
+			case ',':
+				s.pos++
+				s.skipSpace()
+				state = stateMember
+			case closer(objects, depth):
+				s.pos++
+				depth--
+			default:
+				if s.pos == len(s.data) {
+					return s.fail("unexpected end of data")
+				}
+				return s.fail("expected ',' or a closing bracket")
+			}
+		}
+	}
+}
+
+// closer returns the closing bracket of the container at depth-1
+func closer(objects uint64, depth int) byte {
+	if objects&(1<<(depth-1)) != 0 {
+		return '}'
+	}
+	return ']'
+}
+
+// key skips an object key and the colon after it, returning the offset
+// just past the key's closing quote
+func (s *scanner) key() (int, error) {
+	if s.peek() != '"' {
+		return 0, s.fail("expected a string key")
+	}
+	if err := s.str(); err != nil {
+		return 0, err
+	}
+	end := s.pos
+	s.skipSpace()
+	if s.peek() != ':' {
+		return 0, s.fail("expected ':' after the key")
+	}
+	s.pos++
+	return end, nil
+}
+
+// str skips the string opening at pos, checking its escapes and that it
+// holds no raw control characters
+func (s *scanner) str() error {
+	s.pos++
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return nil
+		case c == '\\':
+			s.pos++
+			switch s.peek() {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				s.pos++
+			case 'u':
+				s.pos++
+				for i := 0; i < 4; i++ {
+					if !isHex(s.peek()) {
+						return s.fail("invalid unicode escape")
+					}
+					s.pos++
+				}
+			default:
+				return s.fail("invalid escape")
+			}
+		case c < 0x20:
+			return s.fail("control character in string")
+		default:
+			s.pos++
+		}
+	}
+	return s.fail("unterminated string")
+}
+
+// number skips the number at pos, in the grammar of RFC 8259
+func (s *scanner) number() error {
+	if s.peek() == '-' {
+		s.pos++
+	}
+	switch c := s.peek(); {
+	case c == '0':
+		s.pos++
+	case isDigit(c):
+		s.digits()
+	default:
+		return s.fail("expected a digit")
+	}
+	if s.peek() == '.' {
+		s.pos++
+		if !isDigit(s.peek()) {
+			return s.fail("expected a digit after the decimal point")
+		}
+		s.digits()
+	}
+	if c := s.peek(); c == 'e' || c == 'E' {
+		s.pos++
+		if c := s.peek(); c == '+' || c == '-' {
+			s.pos++
+		}
+		if !isDigit(s.peek()) {
+			return s.fail("expected a digit in the exponent")
+		}
+		s.digits()
+	}
+	return nil
+}
+
+func (s *scanner) digits() {
+	for isDigit(s.peek()) {
+		s.pos++
+	}
+}
+
+// literal skips word, which must be at pos
+func (s *scanner) literal(word string) error {
+	if len(s.data)-s.pos < len(word) || string(s.data[s.pos:s.pos+len(word)]) != word {
+		return s.fail("invalid literal")
+	}
+	s.pos += len(word)
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
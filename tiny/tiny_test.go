@@ -0,0 +1,99 @@
+package tiny
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantOffset int // -1 when the input is valid
+	}{
+		{`{"temp": 21.5, "ok": true, "tags": ["a", null], "n": -0.1e+3}`, -1},
+		{" [ ] ", -1},
+		{`"é\n"`, -1},
+		{"[" + strings.Repeat("[", MaxDepth-1) + strings.Repeat("]", MaxDepth), -1},
+		{"", 0},
+		{"[1,]", 3},
+		{`{"a" 1}`, 5},
+		{`{"a":1,}`, 7},
+		{`{1:2}`, 1},
+		{"[01]", 2},
+		{"[1.]", 3},
+		{"-", 1},
+		{"1e", 2},
+		{"tru", 0},
+		{"nul1", 0},
+		{`"a` + "\n" + `"`, 2},
+		{`"\x"`, 2},
+		{`"\u12"`, 5},
+		{`"abc`, 4},
+		{"[1 2]", 3},
+		{"1 2", 2},
+		{"[" + strings.Repeat("[", MaxDepth) + strings.Repeat("]", MaxDepth+1), MaxDepth},
+	}
+
+	for _, tt := range tests {
+		err := Validate([]byte(tt.input))
+		if tt.wantOffset < 0 {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.input, err)
+			}
+			continue
+		}
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Offset != tt.wantOffset {
+			t.Errorf("Validate(%q) = %v, want a syntax error at byte %d", tt.input, err, tt.wantOffset)
+		}
+		if Valid([]byte(tt.input)) {
+			t.Errorf("Valid(%q) = true", tt.input)
+		}
+	}
+}
+
+func TestValid_MatchesParser(t *testing.T) {
+	inputs := []string{
+		`{}`, `[]`, `0`, `-0`, `1E9`, `"x"`, `[true, false, null]`, `{"a": {"b": [1, {"c": "d"}]}}`,
+		`[`, `]`, `{"a"}`, `[,1]`, `01`, `+1`, `.5`, `[1,,2]`, `{"a":1 "b":2}`, `"\t"`, "\"\t\"", `nan`,
+	}
+	for _, input := range inputs {
+		if got, want := Valid([]byte(input)), jsonparser.Valid([]byte(input)); got != want {
+			t.Errorf("Valid(%q) = %v, the parser says %v", input, got, want)
+		}
+	}
+}
+
+func TestValid_Allocations(t *testing.T) {
+	data := []byte(`{"sensor": "t1", "readings": [20.5, 20.7, 21], "meta": {"unit": "C"}}`)
+	if n := testing.AllocsPerRun(100, func() { Valid(data) }); n != 0 {
+		t.Errorf("Valid allocated %v times, want 0", n)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	out, err := exec.Command(goTool, "list", "-deps", ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list: %v\n%s", err, out)
+	}
+
+	// TinyGo supports reflection and fmt only in part, and both are large,
+	// so the package keeps to those of the standard library it handles well
+	for _, pkg := range strings.Fields(string(out)) {
+		switch {
+		case pkg == "reflect", pkg == "fmt", strings.HasPrefix(pkg, "github.com/") && pkg != "github.com/letsmakecakes/jsonparser/tiny":
+			t.Errorf("tiny depends on %s", pkg)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package tiny
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Writer errors
+var (
+	ErrNesting = errors.New("tiny: unbalanced or too deeply nested containers")
+	ErrKey     = errors.New("tiny: key outside an object or value without a key")
+	ErrFloat   = errors.New("tiny: NaN and infinities are not JSON numbers")
+)
+
+// Writer builds compact JSON text in a buffer, placing the commas and
+// colons itself. Give it a buffer with room for the largest document and
+// it never allocates; a smaller one grows as append grows it. A zero
+// Writer is ready to use. A misuse, such as a value in an object without a
+// key first, is kept as the Writer's error and stops further writes.
+type Writer struct {
+	buf     []byte
+	depth   int
+	objects uint64 // Bit d is set when the container at depth d is an object
+	more    uint64 // Bit d is set when the container at depth d has a member
+	keyed   bool   // A key was written and its value has not been
+	err     error
+}
+
+// Reset empties w and has it write to buf from its start, reusing its
+// memory
+func (w *Writer) Reset(buf []byte) {
+	*w = Writer{buf: buf[:0]}
+}
+
+// Bytes returns the text written so far
+func (w *Writer) Bytes() []byte {
+	return w.buf
+}
+
+// Err returns the first misuse of w, or ErrNesting when a container is
+// still open
+func (w *Writer) Err() error {
+	if w.err == nil && w.depth > 0 {
+		return ErrNesting
+	}
+	return w.err
+}
+
+// BeginObject opens an object
+func (w *Writer) BeginObject() {
+	w.begin('{', true)
+}
+
+// EndObject closes the object opened last
+func (w *Writer) EndObject() {
+	w.end('}', true)
+}
+
+// BeginArray opens an array
+func (w *Writer) BeginArray() {
+	w.begin('[', false)
+}
+
+// EndArray closes the array opened last
+func (w *Writer) EndArray() {
+	w.end(']', false)
+}
+
+// Key writes the key of the next member of the open object
+func (w *Writer) Key(key string) {
+	if w.err != nil {
+		return
+	}
+	if w.depth == 0 || w.objects&(1<<(w.depth-1)) == 0 || w.keyed {
+		w.err = ErrKey
+		return
+	}
+	w.comma()
+	w.buf = append(AppendQuoted(w.buf, key), ':')
+	w.keyed = true
+}
+
+// String writes a string value
+func (w *Writer) String(s string) {
+	if w.value() {
+		w.buf = AppendQuoted(w.buf, s)
+	}
+}
+
+// Int writes an integer value
+func (w *Writer) Int(n int64) {
+	if w.value() {
+		w.buf = strconv.AppendInt(w.buf, n, 10)
+	}
+}
+
+// Float writes a number value in the shortest form that reads back as f
+func (w *Writer) Float(f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if w.err == nil {
+			w.err = ErrFloat
+		}
+		return
+	}
+	if w.value() {
+		w.buf = strconv.AppendFloat(w.buf, f, 'g', -1, 64)
+	}
+}
+
+// Bool writes a boolean value
+func (w *Writer) Bool(b bool) {
+	if w.value() {
+		w.buf = strconv.AppendBool(w.buf, b)
+	}
+}
+
+// Null writes null
+func (w *Writer) Null() {
+	if w.value() {
+		w.buf = append(w.buf, "null"...)
+	}
+}
+
+// Raw writes value as it is; it must be valid JSON, such as text Get
+// returned
+func (w *Writer) Raw(value []byte) {
+	if w.value() {
+		w.buf = append(w.buf, value...)
+	}
+}
+
+func (w *Writer) begin(open byte, object bool) {
+	if !w.value() {
+		return
+	}
+	if w.depth == MaxDepth {
+		w.err = ErrNesting
+		return
+	}
+	if object {
+		w.objects |= 1 << w.depth
+	} else {
+		w.objects &^= 1 << w.depth
+	}
+	w.more &^= 1 << w.depth
+	w.depth++
+	w.buf = append(w.buf, open)
+}
+
+func (w *Writer) end(close byte, object bool) {
+	if w.err != nil {
+		return
+	}
+	if w.depth == 0 || (w.objects&(1<<(w.depth-1)) != 0) != object || w.keyed {
+		w.err = ErrNesting
+		return
+	}
+	w.depth--
+	w.buf = append(w.buf, close)
+}
+
+// value reports whether a value may be written now, writing the comma
+// before it when it is an array element
+func (w *Writer) value() bool {
+	if w.err != nil {
+		return false
+	}
+	if w.depth > 0 && w.objects&(1<<(w.depth-1)) != 0 {
+		if !w.keyed {
+			w.err = ErrKey
+			return false
+		}
+		w.keyed = false
+		return true
+	}
+	w.comma()
+	return true
+}
+
+// comma writes a comma when the open container already has a member, and
+// records that it has one
+func (w *Writer) comma() {
+	if w.depth == 0 {
+		return
+	}
+	bit := uint64(1) << (w.depth - 1)
+	if w.more&bit != 0 {
+		w.buf = append(w.buf, ',')
+	}
+	w.more |= bit
+}
+
+// AppendQuoted appends s to dst as a JSON string, escaping quotes,
+// backslashes and control characters, and writing invalid UTF-8 as U+FFFD
+func AppendQuoted(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r != utf8.RuneError || size != 1 {
+				i += size
+				continue
+			}
+		}
+		dst = append(dst, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < 0x20 {
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			} else {
+				dst = utf8.AppendRune(dst, utf8.RuneError)
+			}
+		}
+		i++
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
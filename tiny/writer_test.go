@@ -0,0 +1,107 @@
+package tiny
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var w Writer
+	w.BeginObject()
+	w.Key("device")
+	w.String("node-7")
+	w.Key("samples")
+	w.BeginArray()
+	for i, temp := range []float64{20.5, -12.5, 1e21} {
+		w.BeginObject()
+		w.Key("t")
+		w.Int(int64(i * 60))
+		w.Key("temp")
+		w.Float(temp)
+		w.EndObject()
+	}
+	w.EndArray()
+	w.Key("ok")
+	w.Bool(true)
+	w.Key("error")
+	w.Null()
+	w.Key("raw")
+	w.Raw([]byte(`[1,2]`))
+	w.Key("empty")
+	w.BeginArray()
+	w.EndArray()
+	w.EndObject()
+
+	want := `{"device":"node-7","samples":[{"t":0,"temp":20.5},{"t":60,"temp":-12.5},{"t":120,"temp":1e+21}],"ok":true,"error":null,"raw":[1,2],"empty":[]}`
+	if err := w.Err(); err != nil || string(w.Bytes()) != want {
+		t.Fatalf("Writer wrote %s, %v, want %s", w.Bytes(), err, want)
+	}
+	if !Valid(w.Bytes()) {
+		t.Error("Writer wrote invalid JSON")
+	}
+}
+
+func TestWriter_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w *Writer)
+		want  error
+	}{
+		{"value without a key", func(w *Writer) { w.BeginObject(); w.Int(1) }, ErrKey},
+		{"key in an array", func(w *Writer) { w.BeginArray(); w.Key("a") }, ErrKey},
+		{"two keys", func(w *Writer) { w.BeginObject(); w.Key("a"); w.Key("b") }, ErrKey},
+		{"unclosed", func(w *Writer) { w.BeginArray() }, ErrNesting},
+		{"mismatched", func(w *Writer) { w.BeginArray(); w.EndObject() }, ErrNesting},
+		{"key without a value", func(w *Writer) { w.BeginObject(); w.Key("a"); w.EndObject() }, ErrNesting},
+		{"too deep", func(w *Writer) {
+			for i := 0; i <= MaxDepth; i++ {
+				w.BeginArray()
+			}
+		}, ErrNesting},
+		{"NaN", func(w *Writer) { w.Float(math.NaN()) }, ErrFloat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w Writer
+			tt.write(&w)
+			if err := w.Err(); err != tt.want {
+				t.Errorf("Err() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter_Allocations(t *testing.T) {
+	buf := make([]byte, 0, 128)
+	var w Writer
+	n := testing.AllocsPerRun(100, func() {
+		w.Reset(buf)
+		w.BeginObject()
+		w.Key("temp")
+		w.Float(20.5)
+		w.Key("id")
+		w.String("node-7")
+		w.EndObject()
+	})
+	if n != 0 {
+		t.Errorf("Writer allocated %v times with room in its buffer, want 0", n)
+	}
+}
+
+func TestAppendQuoted(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"plain", `"plain"`},
+		{"a\"b\\c", `"a\"b\\c"`},
+		{"\n\r\t\x01\x1f", `"\n\r\t\u0001\u001f"`},
+		{"café 😀", `"café 😀"`},
+		{"bad \xff", "\"bad �\""},
+	}
+	for _, tt := range tests {
+		if got := AppendQuoted(nil, tt.input); string(got) != tt.want {
+			t.Errorf("AppendQuoted(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
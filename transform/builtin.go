@@ -0,0 +1,38 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+func init() {
+	Register(Func{ID: "strip-nulls", Fn: stripNulls})
+}
+
+// stripNulls removes object members whose value is null, at every level.
+// It edits the document in place, so fails on a frozen one.
+func stripNulls(v ast.Value) (ast.Value, error) {
+	if ast.IsFrozen(v) {
+		return nil, fmt.Errorf("strip-nulls: %w", ast.ErrFrozen)
+	}
+	switch v := v.(type) {
+	case *ast.Object:
+		for key, value := range v.Pairs {
+			if _, ok := value.(*ast.Null); ok {
+				v.Delete(key)
+				continue
+			}
+			if _, err := stripNulls(value); err != nil {
+				return nil, err
+			}
+		}
+	case *ast.Array:
+		for _, elem := range v.Elements {
+			if _, err := stripNulls(elem); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
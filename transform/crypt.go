@@ -0,0 +1,309 @@
+package transform
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// MetadataKey is the root member in which Encrypt records how a document was
+// encrypted, and which Decrypt removes
+const MetadataKey = "_encryption"
+
+// KeyProvider supplies the AES keys of Encrypt and Decrypt, 16, 24 or 32
+// bytes long, so that they can come from a file, a key management service or
+// anywhere else
+type KeyProvider interface {
+	// EncryptionKey returns the key to encrypt with and the ID to find it by
+	EncryptionKey() (id string, key []byte, err error)
+	// DecryptionKey returns the key with the given ID
+	DecryptionKey(id string) ([]byte, error)
+}
+
+// StaticKey is a KeyProvider holding one key in memory
+type StaticKey struct {
+	ID  string
+	Key []byte
+}
+
+// EncryptionKey returns the key
+func (k StaticKey) EncryptionKey() (string, []byte, error) {
+	return k.ID, k.Key, nil
+}
+
+// DecryptionKey returns the key if id is its ID
+func (k StaticKey) DecryptionKey(id string) ([]byte, error) {
+	if id != k.ID {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	return k.Key, nil
+}
+
+// Encrypt returns a transform that encrypts the values the JSONPath
+// expressions select with AES-GCM, in the manner of SOPS: every string,
+// number, boolean and null at or below a selected value is replaced by a
+// string such as
+//
+//	ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]
+//
+// while object keys and the shape of arrays stay readable. The JSON Pointer
+// and type of each value are authenticated with it, so a value moved
+// elsewhere or given another type fails to decrypt. The key's ID, the paths
+// and the JSON Pointer of every sealed value are recorded in the root member
+// MetadataKey. The document must be an object; it is edited in place, so
+// fails if frozen.
+func Encrypt(keys KeyProvider, paths ...string) (Transform, error) {
+	queries := make([]*ast.CompiledQuery, len(paths))
+	for i, path := range paths {
+		q, err := ast.Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = q
+	}
+	return Func{ID: "encrypt", Fn: func(v ast.Value) (ast.Value, error) {
+		root, err := editableRoot(v)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt: %w", err)
+		}
+		if _, ok := root.Pairs[MetadataKey]; ok {
+			return nil, fmt.Errorf("encrypt: the document is already encrypted")
+		}
+		id, key, err := keys.EncryptionKey()
+		if err != nil {
+			return nil, fmt.Errorf("encrypt: %w", err)
+		}
+		aead, label, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt: %w", err)
+		}
+		done := make(map[string]bool) // values already sealed, for paths that overlap
+		sealed := &ast.Array{}
+		for _, q := range queries {
+			for _, c := range q.Query(root) {
+				err := replaceLeaves(c, func(pointer string, v ast.Value) (ast.Value, error) {
+					if done[pointer] {
+						return nil, nil
+					}
+					done[pointer] = true
+					sealed.Elements = append(sealed.Elements, &ast.String{Value: pointer})
+					return seal(aead, label, pointer, v)
+				})
+				if err != nil {
+					return nil, fmt.Errorf("encrypt: %s: %w", q, err)
+				}
+			}
+		}
+		recorded := &ast.Array{Elements: make([]ast.Value, len(paths))}
+		for i, path := range paths {
+			recorded.Elements[i] = &ast.String{Value: path}
+		}
+		metadata := &ast.Object{}
+		metadata.Set("key_id", &ast.String{Value: id})
+		metadata.Set("paths", recorded)
+		metadata.Set("pointers", sealed)
+		root.Set(MetadataKey, metadata)
+		return root, nil
+	}}, nil
+}
+
+// Decrypt returns a transform that reverses Encrypt: it decrypts the values
+// at the pointers MetadataKey records, with the key it names, and removes
+// that member. Other strings are left alone, even ones that look encrypted. It edits the document in place, so fails on a frozen one, but
+// only once every value has decrypted, so a wrong key or a changed value
+// leaves the document as it was.
+func Decrypt(keys KeyProvider) Transform {
+	return Func{ID: "decrypt", Fn: func(v ast.Value) (ast.Value, error) {
+		root, err := editableRoot(v)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt: %w", err)
+		}
+		metadata, ok := root.Pairs[MetadataKey].(*ast.Object)
+		if !ok {
+			return nil, fmt.Errorf("decrypt: the document has no %s member", MetadataKey)
+		}
+		id, ok := metadata.Pairs["key_id"].(*ast.String)
+		if !ok {
+			return nil, fmt.Errorf("decrypt: %s has no key_id", MetadataKey)
+		}
+		key, err := keys.DecryptionKey(id.Value)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt: %w", err)
+		}
+		aead, _, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt: %w", err)
+		}
+		pointers, ok := metadata.Pairs["pointers"].(*ast.Array)
+		if !ok {
+			return nil, fmt.Errorf("decrypt: %s has no pointers", MetadataKey)
+		}
+		encrypted := make([]ast.Cursor, 0, len(pointers.Elements))
+		plain := make([]ast.Value, 0, len(pointers.Elements))
+		metadataPrefix := "/" + MetadataKey + "/"
+		for _, elem := range pointers.Elements {
+			pointer, ok := elem.(*ast.String)
+			if !ok || strings.HasPrefix(pointer.Value, metadataPrefix) {
+				return nil, fmt.Errorf("decrypt: %s holds an invalid pointer", MetadataKey)
+			}
+			c, err := ast.CursorAt(root, pointer.Value)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt: %s: %w", pointer.Value, err)
+			}
+			s, ok := c.Value.(*ast.String)
+			if !ok {
+				return nil, fmt.Errorf("decrypt: %s: expected an encrypted value, got %s", pointer.Value, ast.TypeName(c.Value))
+			}
+			v, err := open(aead, c.Pointer, s.Value)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt: %s: %w", c.Pointer, err)
+			}
+			encrypted = append(encrypted, c)
+			plain = append(plain, v)
+		}
+		for i, c := range encrypted {
+			if err := c.Set(plain[i]); err != nil {
+				return nil, fmt.Errorf("decrypt: %s: %w", c.Pointer, err)
+			}
+		}
+		root.Delete(MetadataKey)
+		return root, nil
+	}}
+}
+
+// editableRoot returns v as an object that may be edited
+func editableRoot(v ast.Value) (*ast.Object, error) {
+	if ast.IsFrozen(v) {
+		return nil, ast.ErrFrozen
+	}
+	root, ok := v.(*ast.Object)
+	if !ok {
+		return nil, fmt.Errorf("the document must be an object")
+	}
+	return root, nil
+}
+
+// newAEAD returns AES-GCM with key, and its name for ENC[...] strings
+func newAEAD(key []byte) (cipher.AEAD, string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, "", err
+	}
+	return aead, fmt.Sprintf("AES%d_GCM", len(key)*8), nil
+}
+
+// replaceLeaves calls fn with every string, number, boolean and null at or
+// below the cursor, and its JSON Pointer, and puts what fn returns in its
+// place. When fn returns nil the value is left alone.
+func replaceLeaves(c ast.Cursor, fn func(pointer string, v ast.Value) (ast.Value, error)) error {
+	leaves := ast.Find(c.Value, func(v ast.Value) bool {
+		switch v.(type) {
+		case *ast.Object, *ast.Array:
+			return false
+		}
+		return true
+	})
+	for _, leaf := range leaves {
+		replacement, err := fn(c.Pointer+leaf.Pointer, leaf.Value)
+		if err != nil {
+			return err
+		}
+		if replacement == nil {
+			continue
+		}
+		if leaf.Parent == nil {
+			err = c.Set(replacement) // the cursor's own value is the leaf
+		} else {
+			err = leaf.Set(replacement)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seal encrypts v, found at pointer, into an ENC[...] string
+func seal(aead cipher.AEAD, label, pointer string, v ast.Value) (ast.Value, error) {
+	var typ, plain string
+	switch v := v.(type) {
+	case *ast.String:
+		typ, plain = "str", v.Value
+	case *ast.Number:
+		typ, plain = "number", v.Value
+	case *ast.Boolean:
+		typ, plain = "bool", v.Value
+	case *ast.Null:
+		typ = "null"
+	default:
+		return nil, fmt.Errorf("cannot encrypt a %T", v)
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nil, iv, []byte(plain), additionalData(typ, pointer))
+	data, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return &ast.String{Value: fmt.Sprintf("ENC[%s,data:%s,iv:%s,tag:%s,type:%s]", label, enc(data), enc(iv), enc(tag), typ)}, nil
+}
+
+// open decrypts an ENC[...] string found at pointer
+func open(aead cipher.AEAD, pointer, text string) (ast.Value, error) {
+	fields := map[string]string{}
+	body, ok := strings.CutSuffix(strings.TrimPrefix(text, "ENC["), "]")
+	parts := strings.Split(body, ",")
+	if !ok || len(parts) != 5 {
+		return nil, fmt.Errorf("malformed encrypted value")
+	}
+	for _, part := range parts[1:] {
+		name, value, _ := strings.Cut(part, ":")
+		fields[name] = value
+	}
+	var data, iv, tag []byte
+	for name, dst := range map[string]*[]byte{"data": &data, "iv": &iv, "tag": &tag} {
+		b, err := base64.StdEncoding.DecodeString(fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("malformed encrypted value: %s: %w", name, err)
+		}
+		*dst = b
+	}
+	if len(iv) != aead.NonceSize() {
+		return nil, fmt.Errorf("malformed encrypted value: iv: wrong length")
+	}
+	typ := fields["type"]
+	switch typ {
+	case "str", "number", "bool", "null":
+	default:
+		return nil, fmt.Errorf("malformed encrypted value: unknown type %q", typ)
+	}
+	plain, err := aead.Open(nil, iv, append(data, tag...), additionalData(typ, pointer))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt: wrong key, or the value was changed or moved")
+	}
+	switch typ {
+	case "str":
+		return &ast.String{Value: string(plain)}, nil
+	case "number":
+		return &ast.Number{Value: string(plain)}, nil
+	case "bool":
+		return &ast.Boolean{Value: string(plain)}, nil
+	}
+	return &ast.Null{}, nil
+}
+
+// additionalData returns the data authenticated along with a value of type
+// typ at pointer. The type names hold no colon, so the two cannot run
+// together.
+func additionalData(typ, pointer string) []byte {
+	return []byte(typ + ":" + pointer)
+}
@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/expr"
+)
+
+// Map compiles an expression such as `x.price * 1.2` into a transform.
+// When the document is an array, the expression is evaluated for every
+// element with x bound to it; otherwise x is the whole document. The
+// results replace the values they were computed from. The expression
+// syntax is described in the README.
+func Map(src string) (Transform, error) {
+	e, err := expr.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	return Func{ID: "map(" + src + ")", Fn: func(v ast.Value) (ast.Value, error) {
+		array, ok := v.(*ast.Array)
+		if !ok {
+			return e.Eval(v)
+		}
+		mapped := &ast.Array{Elements: make([]ast.Value, len(array.Elements))}
+		for i, elem := range array.Elements {
+			result, err := e.Eval(elem)
+			if err != nil {
+				return nil, err
+			}
+			mapped.Elements[i] = result
+		}
+		return mapped, nil
+	}}, nil
+}
+
+// Filter compiles a condition such as `x.price > 10 && x.tags contains "sale"`
+// into a transform that keeps the elements of an array for which it holds,
+// in order. Conditions are true unless they evaluate to false or null.
+func Filter(src string) (Transform, error) {
+	e, err := expr.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	return Func{ID: "filter(" + src + ")", Fn: func(v ast.Value) (ast.Value, error) {
+		array, ok := v.(*ast.Array)
+		if !ok {
+			return nil, fmt.Errorf("transform: filter needs an array")
+		}
+		kept := &ast.Array{Elements: []ast.Value{}}
+		for _, elem := range array.Elements {
+			keep, err := e.Test(elem)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				kept.Elements = append(kept.Elements, elem)
+			}
+		}
+		return kept, nil
+	}}, nil
+}
+
+// Select finds the values in doc, at any depth, for which a condition such
+// as `x.price > 10` holds. Each result carries its JSON Pointer, source span
+// and parent, and can replace or delete the value in place; so can the
+// results as a whole.
+func Select(src string, doc ast.Value) (ast.Cursors, error) {
+	e, err := expr.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	return e.Select(doc), nil
+}
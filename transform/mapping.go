@@ -0,0 +1,206 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/expr"
+)
+
+// Mapping compiles a declarative spec into a transform that builds a new
+// document from the one it is given, such as one API's shape from another's.
+// The spec is an object whose keys are JSON Pointers into the new document,
+// set in order, and whose values say where each value comes from: a JSON
+// Pointer into the source document, or a rule object with the members
+//
+//	from      the source pointer
+//	convert   an expression applied to the value, bound to x, such as "number(x)"
+//	default   the value to use when the source has none
+//	value     a constant, in place of from
+//	each      a nested spec mapping every element of the array at from, with
+//	          pointers relative to the element
+//	required  true to fail when the source has no value and there is no default
+//
+// A target whose source has no value is otherwise left out. Objects on the
+// way to a target are created, and the source document is left alone.
+func Mapping(spec ast.Value) (Transform, error) {
+	rules, err := compileMapping(spec, "")
+	if err != nil {
+		return nil, err
+	}
+	return Func{ID: "mapping", Fn: func(v ast.Value) (ast.Value, error) {
+		return applyMapping(rules, v)
+	}}, nil
+}
+
+// rule fills in one target of a mapping. label names the target in errors,
+// with a "*" segment for each element of an each.
+type rule struct {
+	target, label string
+	from          string
+	convert       *expr.Expr
+	fallback      ast.Value
+	constant      ast.Value
+	each          []rule
+	required      bool
+}
+
+func compileMapping(spec ast.Value, prefix string) ([]rule, error) {
+	obj, ok := spec.(*ast.Object)
+	if !ok {
+		return nil, fmt.Errorf("transform: a mapping must be an object of target pointers")
+	}
+	rules := make([]rule, 0, len(obj.Pairs))
+	for _, target := range obj.OrderedKeys() {
+		r := rule{target: target, label: prefix + target}
+		if _, err := ast.SplitPointer(target); err != nil {
+			return nil, r.errorf("%v", err)
+		}
+		if err := r.compile(obj.Pairs[target]); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// compile reads the rule's source from the spec value for its target
+func (r *rule) compile(v ast.Value) error {
+	var from ast.Value
+	switch v := v.(type) {
+	case *ast.String:
+		from = v
+	case *ast.Object:
+		for _, key := range v.OrderedKeys() {
+			member := v.Pairs[key]
+			switch key {
+			case "from":
+				from = member
+			case "convert":
+				src, ok := member.(*ast.String)
+				if !ok {
+					return r.errorf("convert must be an expression string")
+				}
+				e, err := expr.Compile(src.Value)
+				if err != nil {
+					return r.errorf("%v", err)
+				}
+				r.convert = e
+			case "default":
+				r.fallback = member
+			case "value":
+				r.constant = member
+			case "each":
+				each, err := compileMapping(member, r.label+"/*")
+				if err != nil {
+					return err
+				}
+				r.each = each
+			case "required":
+				required, ok := member.(*ast.Boolean)
+				if !ok {
+					return r.errorf("required must be a boolean")
+				}
+				r.required = required.Value == "true"
+			default:
+				return r.errorf("unknown member %q", key)
+			}
+		}
+	default:
+		return r.errorf("expected a source pointer or a rule object")
+	}
+
+	if r.constant != nil {
+		if from != nil || r.convert != nil || r.fallback != nil || r.each != nil || r.required {
+			return r.errorf("a constant value cannot be combined with other members")
+		}
+		return nil
+	}
+	if from == nil {
+		return r.errorf("missing \"from\" or \"value\"")
+	}
+	pointer, ok := from.(*ast.String)
+	if !ok {
+		return r.errorf("from must be a string")
+	}
+	if _, err := ast.SplitPointer(pointer.Value); err != nil {
+		return r.errorf("from: %v", err)
+	}
+	r.from = pointer.Value
+	if r.each != nil && r.convert != nil {
+		return r.errorf("each cannot be combined with convert")
+	}
+	return nil
+}
+
+func applyMapping(rules []rule, src ast.Value) (ast.Value, error) {
+	var result ast.Value = &ast.Object{}
+	for _, r := range rules {
+		v, found, err := r.value(src)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if r.target == "" {
+			result = v
+			continue
+		}
+		if err := ast.SetPath(result, r.target, v); err != nil {
+			return nil, r.errorf("%v", err)
+		}
+	}
+	return result, nil
+}
+
+// value returns the value the rule sets, and false when it sets none
+func (r *rule) value(src ast.Value) (ast.Value, bool, error) {
+	if r.constant != nil {
+		return ast.Clone(r.constant), true, nil
+	}
+	v, err := ast.Lookup(src, r.from)
+	if err != nil {
+		switch {
+		case r.fallback != nil:
+			return ast.Clone(r.fallback), true, nil
+		case r.required:
+			return nil, false, r.errorf("no value at %s", display(r.from))
+		}
+		return nil, false, nil
+	}
+	if r.each != nil {
+		array, ok := v.(*ast.Array)
+		if !ok {
+			return nil, false, r.errorf("each needs an array at %s", display(r.from))
+		}
+		mapped := &ast.Array{Elements: make([]ast.Value, len(array.Elements))}
+		for i, elem := range array.Elements {
+			if mapped.Elements[i], err = applyMapping(r.each, elem); err != nil {
+				return nil, false, err
+			}
+		}
+		return mapped, true, nil
+	}
+	if r.convert != nil {
+		converted, err := r.convert.Eval(v)
+		if err != nil {
+			return nil, false, r.errorf("%v", err)
+		}
+		// The result may share values with the source, which is left alone
+		return ast.Clone(converted), true, nil
+	}
+	return ast.Clone(v), true, nil
+}
+
+func (r *rule) errorf(format string, args ...any) error {
+	return fmt.Errorf("transform: mapping %s: %s", display(r.label), fmt.Sprintf(format, args...))
+}
+
+// display returns a pointer for a message, quoting the root's empty one
+func display(pointer string) string {
+	if pointer == "" {
+		return `""`
+	}
+	return pointer
+}
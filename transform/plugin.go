@@ -0,0 +1,18 @@
+//go:build !jsonparser_minimal
+
+package transform
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin opens a Go plugin built with -buildmode=plugin. The plugin's init
+// functions run while it loads, registering its transforms in the default
+// registry. Plugins are only supported where the Go toolchain supports them.
+func LoadPlugin(path string) error {
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("transform: loading plugin %s: %v", path, err)
+	}
+	return nil
+}
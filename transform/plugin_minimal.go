@@ -0,0 +1,11 @@
+//go:build jsonparser_minimal
+
+package transform
+
+import "fmt"
+
+// LoadPlugin fails in builds with the jsonparser_minimal tag, which leave out
+// the plugin package and the dynamic linking it requires
+func LoadPlugin(path string) error {
+	return fmt.Errorf("transform: loading plugin %s: plugin support is not compiled in (jsonparser_minimal build)", path)
+}
@@ -0,0 +1,75 @@
+package transform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// PseudonymPrefix starts every pseudonym, so they are easy to tell from
+// real values
+const PseudonymPrefix = "ps_"
+
+// Pseudonymize returns a transform that replaces every string, number and
+// boolean at or below the values the JSONPath expressions select with a
+// pseudonym: PseudonymPrefix and the hex of the first 16 bytes of the
+// HMAC-SHA256 of the value's text under key: its contents for a string, the
+// canonical literal for a number, so 1, 1.0 and 1e0 agree, and true or false.
+// The same value gives the same pseudonym wherever it is found, so datasets
+// pseudonymized with one key can still be joined, even where one holds an
+// ID as a number and another as a string. Nulls are left alone. The document is edited in place, so fails if frozen.
+func Pseudonymize(key []byte, paths ...string) (Transform, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("transform: pseudonymize needs a key")
+	}
+	queries := make([]*ast.CompiledQuery, len(paths))
+	for i, path := range paths {
+		q, err := ast.Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = q
+	}
+	return Func{ID: "pseudonymize", Fn: func(v ast.Value) (ast.Value, error) {
+		if ast.IsFrozen(v) {
+			return nil, fmt.Errorf("pseudonymize: %w", ast.ErrFrozen)
+		}
+		done := make(map[string]bool) // values already replaced, for paths that overlap
+		for _, q := range queries {
+			for _, c := range q.Query(v) {
+				err := replaceLeaves(c, func(pointer string, leaf ast.Value) (ast.Value, error) {
+					if done[pointer] {
+						return nil, nil
+					}
+					var text string
+					switch leaf := leaf.(type) {
+					case *ast.String:
+						text = leaf.Value
+					case *ast.Number:
+						text = leaf.Canonical()
+					case *ast.Boolean:
+						text = leaf.Value
+					default:
+						return nil, nil
+					}
+					done[pointer] = true
+					return &ast.String{Value: pseudonym(key, text)}, nil
+				})
+				if err != nil {
+					return nil, fmt.Errorf("pseudonymize: %s: %w", q, err)
+				}
+			}
+		}
+		return v, nil
+	}}, nil
+}
+
+// pseudonym returns the pseudonym of text under key
+func pseudonym(key []byte, text string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(text))
+	return PseudonymPrefix + hex.EncodeToString(mac.Sum(nil)[:16])
+}
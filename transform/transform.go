@@ -0,0 +1,162 @@
+// Package transform rewrites parsed documents through named, pluggable steps.
+//
+// Transforms register themselves under a name, usually from an init function,
+// so the command line tool can build a pipeline from names given at runtime.
+// Transforms compiled into a Go plugin register the same way when the plugin
+// is loaded with LoadPlugin.
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+)
+
+// Transform rewrites a document. Apply may modify v in place or return a new value.
+type Transform interface {
+	Name() string
+	Apply(v ast.Value) (ast.Value, error)
+}
+
+// Func adapts a function to the Transform interface
+type Func struct {
+	ID string
+	Fn func(v ast.Value) (ast.Value, error)
+}
+
+// Name returns the transform's name
+func (f Func) Name() string {
+	return f.ID
+}
+
+// Apply calls the function
+func (f Func) Apply(v ast.Value) (ast.Value, error) {
+	return f.Fn(v)
+}
+
+// Registry maps names to transforms. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	transforms map[string]Transform
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{transforms: make(map[string]Transform)}
+}
+
+// Register adds t under its name, failing if the name is empty or already taken
+func (r *Registry) Register(t Transform) error {
+	name := t.Name()
+	if name == "" {
+		return fmt.Errorf("transform: empty name")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.transforms[name]; ok {
+		return fmt.Errorf("transform: %q is already registered", name)
+	}
+	r.transforms[name] = t
+	return nil
+}
+
+// Lookup returns the transform registered under name
+func (r *Registry) Lookup(name string) (Transform, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.transforms[name]
+	return t, ok
+}
+
+// Names returns the registered names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.transforms))
+	for name := range r.transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pipeline looks up each name and returns a transform applying them in order
+func (r *Registry) Pipeline(names ...string) (Transform, error) {
+	steps := make([]Transform, 0, len(names))
+	for _, name := range names {
+		t, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("transform: unknown transform %q", name)
+		}
+		steps = append(steps, t)
+	}
+	return Chain(steps...), nil
+}
+
+// defaultRegistry holds the transforms registered through the package functions
+var defaultRegistry = NewRegistry()
+
+// Register adds t to the default registry
+func Register(t Transform) error {
+	return defaultRegistry.Register(t)
+}
+
+// Lookup returns the transform registered under name in the default registry
+func Lookup(name string) (Transform, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// Names returns the names in the default registry in sorted order
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// Pipeline builds a pipeline from the default registry
+func Pipeline(names ...string) (Transform, error) {
+	return defaultRegistry.Pipeline(names...)
+}
+
+// Must returns t, panicking if err is not nil. It wraps constructors such as
+// Filter and Mapping in variable initializations:
+//
+//	var expensive = transform.Must(transform.Filter("x.price > 100"))
+func Must(t Transform, err error) Transform {
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// chain applies its steps in order
+type chain []Transform
+
+// Chain returns a transform applying steps in order, each to the previous result
+func Chain(steps ...Transform) Transform {
+	return chain(steps)
+}
+
+// Name joins the names of the steps
+func (c chain) Name() string {
+	name := ""
+	for i, t := range c {
+		if i > 0 {
+			name += "|"
+		}
+		name += t.Name()
+	}
+	return name
+}
+
+// Apply runs every step, stopping at the first error
+func (c chain) Apply(v ast.Value) (ast.Value, error) {
+	for _, t := range c {
+		var err error
+		if v, err = t.Apply(v); err != nil {
+			return nil, fmt.Errorf("transform %s: %v", t.Name(), err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,470 @@
+package transform
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/encoder"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+func mustParse(t *testing.T, input string) ast.Value {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Tokenize()
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	value, err := parser.ParseValue(tokens)
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	return value
+}
+
+func mustMarshal(t *testing.T, value ast.Value) string {
+	t.Helper()
+	data, err := encoder.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	return string(data)
+}
+
+// wrap replaces the document with a one-element array holding it
+var wrap = Func{ID: "wrap", Fn: func(v ast.Value) (ast.Value, error) {
+	return &ast.Array{Elements: []ast.Value{v}}, nil
+}}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(wrap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register(wrap); err == nil {
+		t.Errorf("expected a duplicate name to be rejected")
+	}
+	if err := r.Register(Func{}); err == nil {
+		t.Errorf("expected an empty name to be rejected")
+	}
+	if err := r.Register(Func{ID: "a-first", Fn: stripNulls}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := r.Lookup("wrap"); !ok {
+		t.Errorf("expected wrap to be registered")
+	}
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"a-first", "wrap"}) {
+		t.Errorf("expected sorted names, got %v", got)
+	}
+	if _, err := r.Pipeline("wrap", "missing"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected an unknown transform error, got %v", err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	r := NewRegistry()
+	r.Register(wrap)
+	r.Register(Func{ID: "strip", Fn: stripNulls})
+
+	pipeline, err := r.Pipeline("strip", "wrap", "wrap")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pipeline.Name() != "strip|wrap|wrap" {
+		t.Errorf("unexpected pipeline name %q", pipeline.Name())
+	}
+
+	got, err := pipeline.Apply(mustParse(t, `{"a": null, "b": 1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `[[{"b":1}]]`; mustMarshal(t, got) != want {
+		t.Errorf("expected %s, got %s", want, mustMarshal(t, got))
+	}
+}
+
+func TestChain_StopsAtFirstError(t *testing.T) {
+	failing := Func{ID: "fail", Fn: func(ast.Value) (ast.Value, error) {
+		return nil, errors.New("boom")
+	}}
+	called := false
+	after := Func{ID: "after", Fn: func(v ast.Value) (ast.Value, error) {
+		called = true
+		return v, nil
+	}}
+
+	_, err := Chain(failing, after).Apply(&ast.Null{})
+	if err == nil || err.Error() != "transform fail: boom" {
+		t.Errorf("expected the failing step to be named, got %v", err)
+	}
+	if called {
+		t.Errorf("expected later steps to be skipped")
+	}
+}
+
+func TestMust(t *testing.T) {
+	filter := Must(Filter("x > 1"))
+	got, err := filter.Apply(mustParse(t, `[1, 2, 3]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := mustMarshal(t, got); s != `[2,3]` {
+		t.Errorf("got %s", s)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected Must to panic on an error")
+		}
+	}()
+	Must(Filter("x >"))
+}
+
+func TestBuiltin_StripNulls(t *testing.T) {
+	tr, ok := Lookup("strip-nulls")
+	if !ok {
+		t.Fatalf("expected strip-nulls to be registered by default")
+	}
+	got, err := tr.Apply(mustParse(t, `{"a": null, "b": [null, {"c": null, "d": 1}], "e": {"f": null}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"b":[null,{"d":1}],"e":{}}`; mustMarshal(t, got) != want {
+		t.Errorf("expected %s, got %s", want, mustMarshal(t, got))
+	}
+
+	frozen := mustParse(t, `{"a": null}`)
+	ast.Freeze(frozen)
+	if _, err := tr.Apply(frozen); !errors.Is(err, ast.ErrFrozen) {
+		t.Errorf("expected ErrFrozen for a frozen document, got %v", err)
+	}
+}
+
+func TestLoadPlugin_MissingFile(t *testing.T) {
+	if err := LoadPlugin("does-not-exist.so"); err == nil {
+		t.Errorf("expected an error for a missing plugin")
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		expr, input, want string
+	}{
+		{`x.price * 1.2`, `[{"price": 10}, {"price": 2.5}]`, `[12,3]`},
+		{`{name: x.name, total: x.qty * x.price}`, `[{"name": "a", "qty": 2, "price": 3}]`, `[{"name":"a","total":6}]`},
+		{`x.count + 1`, `{"count": 41}`, `42`},
+	}
+
+	for _, tt := range tests {
+		tr, err := Map(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+		}
+		got, err := tr.Apply(mustParse(t, tt.input))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+		}
+		if mustMarshal(t, got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, mustMarshal(t, got))
+		}
+	}
+
+	if _, err := Map(`x.price *`); err == nil {
+		t.Errorf("expected a compile error")
+	}
+	tr, _ := Map(`x.price * 2`)
+	if _, err := tr.Apply(mustParse(t, `[{"price": "free"}]`)); err == nil {
+		t.Errorf("expected an evaluation error")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	input := `[{"sku": "a-1", "price": 5, "tags": ["sale"]}, {"sku": "b-2", "price": 15, "tags": []}, {"sku": "a-3", "price": 25, "tags": ["sale", "new"]}]`
+	tests := []struct {
+		expr, want string
+	}{
+		{`x.price > 10`, `[{"sku":"b-2","price":15,"tags":[]},{"sku":"a-3","price":25,"tags":["sale","new"]}]`},
+		{`x.tags contains "sale" && x.sku startsWith "a-"`, `[{"sku":"a-1","price":5,"tags":["sale"]},{"sku":"a-3","price":25,"tags":["sale","new"]}]`},
+		{`x.price >= 5 && x.price <= 10 || x.sku == "a-3"`, `[{"sku":"a-1","price":5,"tags":["sale"]},{"sku":"a-3","price":25,"tags":["sale","new"]}]`},
+		{`x.missing`, `[]`},
+	}
+
+	for _, tt := range tests {
+		tr, err := Filter(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+		}
+		got, err := tr.Apply(mustParse(t, input))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+		}
+		if mustMarshal(t, got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, mustMarshal(t, got))
+		}
+	}
+
+	tr, _ := Filter(`x.price > 1`)
+	if _, err := tr.Apply(mustParse(t, `{"price": 2}`)); err == nil {
+		t.Errorf("expected an error for a document that is not an array")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	doc := mustParse(t, `{"price": 30, "items": [{"sku": "a-1", "price": 5}, {"sku": "b-2", "price": 15}], "note": "price"}`)
+	results, err := Select(`x.price > 10`, doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var pointers []string
+	for _, r := range results {
+		pointers = append(pointers, r.Pointer)
+	}
+	if !reflect.DeepEqual(pointers, []string{"", "/items/1"}) {
+		t.Fatalf("unexpected results %q", pointers)
+	}
+
+	if err := results[1].Set(mustParse(t, `{"sku": "b-2", "price": 12}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mustMarshal(t, doc); got != `{"price":30,"items":[{"sku":"a-1","price":5},{"sku":"b-2","price":12}],"note":"price"}` {
+		t.Errorf("expected the match replaced in place, got %s", got)
+	}
+
+	if _, err := Select(`x.price >`, doc); err == nil {
+		t.Errorf("expected a compile error")
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keys := StaticKey{ID: "test", Key: []byte("0123456789abcdef0123456789abcdef")}
+	input := `{"db": {"user": "app", "password": "s3cret", "port": 5432}, "tokens": ["a", true, null, "ENC[not really]"], "name": "api", "note": "ENC[see wiki]"}`
+	encrypt, err := Encrypt(keys, "$.db.password", "$.db.port", "$.tokens", "$.db.password")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc, err := encrypt.Apply(mustParse(t, input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := doc.(*ast.Object)
+	db := root.Pairs["db"].(*ast.Object)
+	if user := db.Pairs["user"].(*ast.String).Value; user != "app" {
+		t.Errorf("expected unselected values to stay readable, got %q", user)
+	}
+	tokens := root.Pairs["tokens"].(*ast.Array).Elements
+	for _, v := range []ast.Value{db.Pairs["password"], db.Pairs["port"], tokens[2], tokens[3]} {
+		if s, ok := v.(*ast.String); !ok || !strings.HasPrefix(s.Value, "ENC[AES256_GCM,data:") {
+			t.Errorf("expected an encrypted value, got %v", v)
+		}
+	}
+	if got := mustMarshal(t, root.Pairs[MetadataKey]); !strings.Contains(got, `"key_id":"test"`) {
+		t.Errorf("expected the key ID in the metadata, got %s", got)
+	}
+	if _, err := encrypt.Apply(doc); err == nil {
+		t.Error("expected an error encrypting twice")
+	}
+
+	text := mustMarshal(t, doc)
+	if _, err := Decrypt(StaticKey{ID: "test", Key: []byte("another key of thirty-two bytes!")}).Apply(mustParse(t, text)); err == nil {
+		t.Error("expected an error for the wrong key")
+	}
+	if _, err := Decrypt(StaticKey{ID: "other", Key: keys.Key}).Apply(mustParse(t, text)); err == nil {
+		t.Error("expected an error for an unknown key ID")
+	}
+	moved := mustParse(t, text).(*ast.Object)
+	moved.Pairs["db"].(*ast.Object).Pairs["password"] = moved.Pairs["tokens"].(*ast.Array).Elements[0]
+	if _, err := Decrypt(keys).Apply(moved); err == nil || !strings.Contains(err.Error(), "/db/password") {
+		t.Errorf("expected an error for a moved value, got %v", err)
+	}
+	removed := mustParse(t, text).(*ast.Object)
+	removed.Pairs["db"].(*ast.Object).Delete("port")
+	if _, err := Decrypt(keys).Apply(removed); err == nil || !strings.Contains(err.Error(), "/db/port") {
+		t.Errorf("expected an error for a removed value, got %v", err)
+	}
+	retyped := mustParse(t, text).(*ast.Object)
+	password := retyped.Pairs["db"].(*ast.Object).Pairs["password"].(*ast.String)
+	password.Value = strings.Replace(password.Value, "type:str", "type:number", 1)
+	if _, err := Decrypt(keys).Apply(retyped); err == nil || !strings.Contains(err.Error(), "/db/password") {
+		t.Errorf("expected an error for a value given another type, got %v", err)
+	}
+	if got := mustMarshal(t, retyped); !strings.Contains(got, MetadataKey) || strings.Contains(got, "5432") {
+		t.Errorf("expected a failed decryption to leave the document encrypted, got %s", got)
+	}
+
+	plain, err := Decrypt(keys).Apply(mustParse(t, text))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := mustMarshal(t, plain), mustMarshal(t, mustParse(t, input)); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if _, err := Decrypt(keys).Apply(plain); err == nil {
+		t.Error("expected an error for a document that is not encrypted")
+	}
+}
+
+func TestEncrypt_Errors(t *testing.T) {
+	keys := StaticKey{ID: "test", Key: []byte("0123456789abcdef")}
+	if _, err := Encrypt(keys, "$["); err == nil {
+		t.Error("expected an error for an invalid path")
+	}
+	encrypt, err := Encrypt(keys, "$.a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := encrypt.Apply(mustParse(t, `[1]`)); err == nil {
+		t.Error("expected an error for a document that is not an object")
+	}
+	frozen := mustParse(t, `{"a": 1}`)
+	ast.Freeze(frozen)
+	if _, err := encrypt.Apply(frozen); !errors.Is(err, ast.ErrFrozen) {
+		t.Errorf("expected ErrFrozen for a frozen document, got %v", err)
+	}
+	short, _ := Encrypt(StaticKey{ID: "short", Key: []byte("short")}, "$.a")
+	if _, err := short.Apply(mustParse(t, `{"a": 1}`)); err == nil {
+		t.Error("expected an error for a key of the wrong size")
+	}
+}
+
+func TestPseudonymize(t *testing.T) {
+	key := []byte("secret")
+	tr, err := Pseudonymize(key, "$.users[*].email", "$.users[*].id", "$.orders[*].user", "$.users[0]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc, err := tr.Apply(mustParse(t, `{
+		"users": [{"id": 7, "email": "a@example.com", "name": "Ann", "phone": null}, {"id": 8, "email": "b@example.com"}],
+		"orders": [{"user": "7", "total": 10}]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := doc.(*ast.Object)
+	users := root.Pairs["users"].(*ast.Array).Elements
+	ann, bob := users[0].(*ast.Object), users[1].(*ast.Object)
+	order := root.Pairs["orders"].(*ast.Array).Elements[0].(*ast.Object)
+
+	want := pseudonym(key, "a@example.com")
+	if got := ann.Pairs["email"].(*ast.String).Value; got != want || !strings.HasPrefix(got, PseudonymPrefix) || len(got) != len(PseudonymPrefix)+32 {
+		t.Errorf("expected %s, pseudonymized once, got %s", want, got)
+	}
+	if ann.Pairs["id"].(*ast.String).Value != order.Pairs["user"].(*ast.String).Value {
+		t.Error("expected equal values to get equal pseudonyms")
+	}
+	if ann.Pairs["id"].(*ast.String).Value == bob.Pairs["id"].(*ast.String).Value {
+		t.Error("expected different values to get different pseudonyms")
+	}
+	if _, ok := ann.Pairs["phone"].(*ast.Null); !ok {
+		t.Error("expected nulls to be left alone")
+	}
+	if _, ok := order.Pairs["total"].(*ast.Number); !ok {
+		t.Error("expected unselected values to be left alone")
+	}
+	if pseudonym([]byte("other"), "a@example.com") == want {
+		t.Error("expected another key to give another pseudonym")
+	}
+
+	tr, _ = Pseudonymize(key, "$[*]")
+	doc, err = tr.Apply(mustParse(t, `[1, 1.0, 1e0, 10E-1, "1", 2]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := doc.(*ast.Array).Elements
+	for i, v := range values[:5] {
+		if v.(*ast.String).Value != values[0].(*ast.String).Value {
+			t.Errorf("element %d: expected every spelling of 1 to get one pseudonym", i)
+		}
+	}
+	if values[5].(*ast.String).Value == values[0].(*ast.String).Value {
+		t.Error("expected 2 to get another pseudonym than 1")
+	}
+
+	if _, err := Pseudonymize(nil, "$.a"); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+	frozen := mustParse(t, `{"a": "x"}`)
+	ast.Freeze(frozen)
+	tr, _ = Pseudonymize(key, "$.a")
+	if _, err := tr.Apply(frozen); !errors.Is(err, ast.ErrFrozen) {
+		t.Errorf("expected ErrFrozen for a frozen document, got %v", err)
+	}
+}
+
+func TestMapping(t *testing.T) {
+	spec := `{
+		"/id": {"from": "/userId", "convert": "string(x)", "required": true},
+		"/customer/name": "/user/fullName",
+		"/customer/email": "/user/contact/email",
+		"/customer/tier": {"from": "/user/tier", "default": "standard"},
+		"/source": {"value": "legacy-api"},
+		"/lines": {"from": "/items", "each": {
+			"/sku": "/code",
+			"/quantity": {"from": "/qty", "convert": "number(x)"}
+		}}
+	}`
+	m, err := Mapping(mustParse(t, spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := `{"userId":42,"user":{"fullName":"Ann Lee"},"items":[{"code":"A1","qty":"2"},{"code":"B2"}]}`
+	doc := mustParse(t, input)
+	got, err := m.Apply(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"42","customer":{"name":"Ann Lee","tier":"standard"},"source":"legacy-api","lines":[{"sku":"A1","quantity":2},{"sku":"B2"}]}`
+	if mustMarshal(t, got) != want {
+		t.Errorf("expected %s, got %s", want, mustMarshal(t, got))
+	}
+	if mustMarshal(t, doc) != mustMarshal(t, mustParse(t, input)) {
+		t.Error("expected the source document to be left alone")
+	}
+
+	if _, err := m.Apply(mustParse(t, `{"user":{}}`)); err == nil || err.Error() != "transform: mapping /id: no value at /userId" {
+		t.Errorf("expected a missing required value to fail, got %v", err)
+	}
+	if _, err := m.Apply(mustParse(t, `{"userId":1,"items":{}}`)); err == nil || err.Error() != "transform: mapping /lines: each needs an array at /items" {
+		t.Errorf("expected each over an object to fail, got %v", err)
+	}
+}
+
+func TestMapping_Root(t *testing.T) {
+	m, err := Mapping(mustParse(t, `{"": {"from": "/data", "each": {"/n": "/name"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Apply(mustParse(t, `{"data":[{"name":"a"},{"name":"b"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"n":"a"},{"n":"b"}]`; mustMarshal(t, got) != want {
+		t.Errorf("expected %s, got %s", want, mustMarshal(t, got))
+	}
+}
+
+func TestMapping_Errors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{`[]`, `transform: a mapping must be an object of target pointers`},
+		{`{"a": "/b"}`, `transform: mapping a: invalid path "a": must be empty or start with '/'`},
+		{`{"/a": "b"}`, `transform: mapping /a: from: invalid path "b": must be empty or start with '/'`},
+		{`{"/a": 1}`, `transform: mapping /a: expected a source pointer or a rule object`},
+		{`{"/a": {"form": "/b"}}`, `transform: mapping /a: unknown member "form"`},
+		{`{"/a": {"default": 1}}`, `transform: mapping /a: missing "from" or "value"`},
+		{`{"/a": {"value": 1, "from": "/b"}}`, `transform: mapping /a: a constant value cannot be combined with other members`},
+		{`{"/a": {"from": "/b", "convert": "x +"}}`, `transform: mapping /a: expr error at column 4: unexpected end of expression`},
+		{`{"/a": {"from": "/b", "each": {"/c": {"from": 1}}}}`, `transform: mapping /a/*/c: from must be a string`},
+		{`{"/a": {"from": "/b", "each": {}, "convert": "x"}}`, `transform: mapping /a: each cannot be combined with convert`},
+	}
+	for _, tt := range tests {
+		_, err := Mapping(mustParse(t, tt.spec))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: expected error %q, got %v", tt.spec, tt.want, err)
+		}
+	}
+}
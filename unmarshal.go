@@ -0,0 +1,92 @@
+package jsonparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/letsmakecakes/jsonparser/internal/ast"
+	"github.com/letsmakecakes/jsonparser/internal/decoder"
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+)
+
+// UnmarshalOptions configures how Unmarshal stores values
+type UnmarshalOptions struct {
+	// UseNumber decodes numbers into interface{} as *Number, keeping the literal
+	// digits instead of rounding them to float64
+	UseNumber bool
+	// OnPrecisionLoss, when set, is called with the document path and literal of
+	// every number that is rounded while being stored in a float or interface{}
+	OnPrecisionLoss func(path, literal string)
+	// KeyDictionary reads documents written with MarshalOptions.KeyDictionary
+	KeyDictionary bool
+	// NonFinite reads NaN, Infinity and -Infinity as numbers, as
+	// WithAllowNonFinite does, so they decode into floats
+	NonFinite bool
+}
+
+// Unmarshal parses data and stores the result in the value pointed to by v.
+//
+// Objects decode into structs or string-keyed maps, arrays into slices or
+// arrays, and numbers, strings and booleans into the matching Go kinds.
+// Struct fields are matched by their `json` tag name, or by field name
+// ignoring case. Decoding into interface{} produces map[string]interface{},
+// []interface{}, float64, string, bool or nil. Fields of type Number keep
+// the literal as written, so integers beyond 2^53 are never rounded.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalOptions{}.Unmarshal(data, v)
+}
+
+// Unmarshal parses data and stores the result in the value pointed to by v using the options
+func (o UnmarshalOptions) Unmarshal(data []byte, v interface{}) (err error) {
+	defer guard.Recover("unmarshal", &err, nil)
+
+	value, err := ParseBytes(data, WithAllowNonFinite(o.NonFinite))
+	if err != nil {
+		return err
+	}
+	opts := decoder.Options{UseNumber: o.UseNumber, OnPrecisionLoss: o.OnPrecisionLoss, KeyDictionary: o.KeyDictionary}
+	return opts.Decode(value, v)
+}
+
+// QueryAs runs a JSONPath expression against root, as Query does, and
+// stores every result in a T, as Unmarshal would. An error names the
+// result that could not be stored:
+//
+//	prices, err := jsonparser.QueryAs[float64](root, "$.items[*].price")
+func QueryAs[T any](root Value, path string) (results []T, err error) {
+	defer guard.Recover("query", &err, nil)
+
+	cursors, err := Query(root, path)
+	if err != nil {
+		return nil, err
+	}
+	results = make([]T, len(cursors))
+	for i, c := range cursors {
+		opts := decoder.Options{Path: decodePath(root, c.Pointer)}
+		if err := opts.Decode(c.Value, &results[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// decodePath returns the path to the value at pointer in the form the
+// decoder's errors use, such as $.items[0].price
+func decodePath(root Value, pointer string) string {
+	segments, _ := ast.SplitPointer(pointer)
+	var b strings.Builder
+	b.WriteString("$")
+	for _, segment := range segments {
+		switch v := root.(type) {
+		case *ast.Array:
+			i, _ := strconv.Atoi(segment)
+			fmt.Fprintf(&b, "[%d]", i)
+			root = v.Elements[i]
+		case *ast.Object:
+			b.WriteString("." + segment)
+			root = v.Pairs[segment]
+		}
+	}
+	return b.String()
+}
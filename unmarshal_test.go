@@ -0,0 +1,135 @@
+package jsonparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	var config struct {
+		Name    string   `json:"name"`
+		Port    int      `json:"port"`
+		Debug   bool     `json:"debug"`
+		Servers []string `json:"servers"`
+	}
+
+	data := []byte(`{"name": "api", "port": 8080, "debug": true, "servers": ["a", "b"]}`)
+	if err := Unmarshal(data, &config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Name != "api" || config.Port != 8080 || !config.Debug || len(config.Servers) != 2 {
+		t.Errorf("unexpected result: %+v", config)
+	}
+}
+
+func TestUnmarshal_SyntaxError(t *testing.T) {
+	var v interface{}
+	if err := Unmarshal([]byte(`{"a": }`), &v); err == nil {
+		t.Errorf("expected a syntax error")
+	}
+}
+
+func TestUnmarshal_PreservesBigIntegers(t *testing.T) {
+	var v struct {
+		ID    Number `json:"id"`
+		Count int64  `json:"count"`
+	}
+	if err := Unmarshal([]byte(`{"id": 12345678901234567890123, "count": 9007199254740993}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.ID.Value != "12345678901234567890123" {
+		t.Errorf("expected literal to be preserved, got %s", v.ID.Value)
+	}
+	if v.Count != 9007199254740993 {
+		t.Errorf("expected exact int64, got %d", v.Count)
+	}
+}
+
+func TestUnmarshalOptions_PrecisionLoss(t *testing.T) {
+	var lost []string
+	opts := UnmarshalOptions{OnPrecisionLoss: func(path, literal string) {
+		lost = append(lost, path+"="+literal)
+	}}
+
+	var v interface{}
+	if err := opts.Unmarshal([]byte(`[9007199254740993, 9007199254740992, 0.1]`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lost) != 1 || lost[0] != "$[0]=9007199254740993" {
+		t.Errorf("expected one precision warning for $[0], got %v", lost)
+	}
+
+	opts.UseNumber = true
+	lost = nil
+	if err := opts.Unmarshal([]byte(`[9007199254740993]`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, ok := v.([]interface{})[0].(*Number); !ok || n.Value != "9007199254740993" || len(lost) != 0 {
+		t.Errorf("expected *Number without warnings, got %#v and %v", v, lost)
+	}
+}
+
+func TestUnmarshalOptions_KeyDictionary(t *testing.T) {
+	type record struct {
+		Identifier  int    `json:"identifier"`
+		Description string `json:"description"`
+	}
+	in := []record{{1, "a"}, {2, "b"}, {3, "c"}}
+
+	data, err := MarshalOptions{KeyDictionary: true}.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"body":[{"~1":1,"~0":"a"},{"~1":2,"~0":"b"},{"~1":3,"~0":"c"}],"dict":["description","identifier"]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	var out []record
+	if err := (UnmarshalOptions{KeyDictionary: true}).Unmarshal(data, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 3 || out[1] != in[1] {
+		t.Errorf("expected %v, got %v", in, out)
+	}
+}
+
+func TestQueryAs(t *testing.T) {
+	root, err := Parse(`{"items": [
+		{"name": "tea", "price": 4.5, "tags": ["hot"]},
+		{"name": "juice", "price": 3, "tags": []},
+		{"name": "cake", "price": "n/a"}
+	]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type item struct {
+		Name  string   `json:"name"`
+		Price float64  `json:"price"`
+		Tags  []string `json:"tags"`
+	}
+	items, err := QueryAs[item](root, "$.items[?@.price < 5]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Name != "tea" || items[0].Tags[0] != "hot" || items[1].Price != 3 {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	names, err := QueryAs[string](root, "$..name")
+	if err != nil || len(names) != 3 || names[2] != "cake" {
+		t.Errorf("expected three names, got %q, %v", names, err)
+	}
+	if none, err := QueryAs[int](root, "$.missing"); err != nil || len(none) != 0 {
+		t.Errorf("expected no results, got %v, %v", none, err)
+	}
+
+	if _, err := QueryAs[float64](root, "$.items[*].price"); err == nil || !strings.Contains(err.Error(), "at $.items[2].price") {
+		t.Errorf("expected an error naming the result, got %v", err)
+	}
+	if _, err := QueryAs[int](root, "$["); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
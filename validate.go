@@ -0,0 +1,39 @@
+package jsonparser
+
+import (
+	"github.com/letsmakecakes/jsonparser/internal/guard"
+	"github.com/letsmakecakes/jsonparser/internal/lexer"
+	"github.com/letsmakecakes/jsonparser/internal/parser"
+)
+
+// Validate checks that data is a single well-formed JSON document, returning
+// the error Parse would report. It keeps only the current token in memory and
+// builds no AST, so it is cheaper than Parse when the result is not needed.
+// Dialects with value hooks are checked by parsing, since the hooks build
+// values, and so are documents validated WithRecovery.
+func Validate(data []byte, opts ...Option) (err error) {
+	config := newConfig(opts)
+	if config.StableErrors {
+		defer func() {
+			if err != nil {
+				err = stabilize(err)
+			}
+		}()
+	}
+	if config.Dialect != nil && len(config.Dialect.Values) > 0 || config.Recover {
+		_, err := ParseBytes(data, opts...)
+		return err
+	}
+	lex := lexer.NewBytesLexer(data)
+	lex.SetOptions(config.lexerOptions())
+	defer guard.Recover("validate", &err, func() guard.Position {
+		offset, line, column := lex.Position()
+		return guard.Position{Offset: offset, Line: line, Column: column}
+	})
+	return parser.WithSnippets(parser.Validate(lex, config.parserOptions()), lex)
+}
+
+// Valid reports whether data is a single well-formed JSON document
+func Valid(data []byte, opts ...Option) bool {
+	return Validate(data, opts...) == nil
+}
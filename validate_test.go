@@ -0,0 +1,52 @@
+package jsonparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{`{"a": [1, 2.5, "x", true, false, null]}`, true},
+		{`"scalar"`, true},
+		{`{"a": 1,}`, false},
+		{`[1] [2]`, false},
+		{`{"a": "\q"}`, false},
+		{``, false},
+		{" \t\r\n1 \t\r\n", true},
+		{"\v1", false},
+		{"[1,\f2]", false},
+		{"\u00a01", false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid([]byte(tt.input)); got != tt.valid {
+			t.Errorf("Valid(%q) = %v, expected %v", tt.input, got, tt.valid)
+		}
+		if got := Valid([]byte(tt.input), WithStrictMode(true)); got != tt.valid {
+			t.Errorf("Valid(%q, WithStrictMode(true)) = %v, expected %v", tt.input, got, tt.valid)
+		}
+	}
+}
+
+func TestValidate_DoesNotAllocatePerToken(t *testing.T) {
+	small := []byte(`[` + strings.Repeat(`{"key": "value", "n": 12345.678},`, 10) + `{}]`)
+	large := []byte(`[` + strings.Repeat(`{"key": "value", "n": 12345.678},`, 1000) + `{}]`)
+
+	smallAllocs := testing.AllocsPerRun(10, func() {
+		if err := Validate(small); err != nil {
+			t.Fatal(err)
+		}
+	})
+	largeAllocs := testing.AllocsPerRun(10, func() {
+		if err := Validate(large); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if largeAllocs != smallAllocs {
+		t.Errorf("expected allocations independent of input size, got %v for small and %v for large input", smallAllocs, largeAllocs)
+	}
+}
@@ -0,0 +1,74 @@
+package jsonparser
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/letsmakecakes/jsonparser/dialect"
+)
+
+// modulePath is the path of this module, as it appears in build info
+const modulePath = "github.com/letsmakecakes/jsonparser"
+
+// Version returns the version of this module linked into the running
+// binary, as the go command recorded it: a release tag such as v1.2.0, a
+// pseudo-version, or "(devel)" when the module is built from a checkout of
+// itself or the binary has no build info
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	version := ""
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Version
+			}
+		}
+	}
+	if version == "" {
+		return "(devel)"
+	}
+	return version
+}
+
+// FeatureSet lists the capabilities compiled into the running binary
+type FeatureSet struct {
+	// Version is the module version; see Version
+	Version string
+	// SIMD reports vectorized scanning. The lexer is portable Go on every
+	// platform, so it is always false; it is there so callers need not
+	// change when that does.
+	SIMD bool
+	// WASM reports a build for WebAssembly (GOARCH=wasm)
+	WASM bool
+	// Minimal reports a build with the jsonparser_minimal tag
+	Minimal bool
+	// Plugins reports whether transform.LoadPlugin is compiled in. It is
+	// false in minimal builds; loading still needs a platform the plugin
+	// package supports.
+	Plugins bool
+	// Query names the query language of Query and Compile
+	Query string
+	// Dialects names the dialects WithDialect can select: the built-in
+	// jsonc and json5 followed by those registered with dialect.Register
+	Dialects []string
+}
+
+// Features reports the capabilities compiled into the running binary, for
+// programs that adapt to them or print them in diagnostics
+func Features() FeatureSet {
+	return FeatureSet{
+		Version:  Version(),
+		WASM:     runtime.GOARCH == "wasm",
+		Minimal:  minimalBuild,
+		Plugins:  !minimalBuild,
+		Query:    "jsonpath (RFC 9535)",
+		Dialects: append([]string{dialect.JSONC.Name, dialect.JSON5.Name}, dialect.Names()...),
+	}
+}
@@ -0,0 +1,35 @@
+package jsonparser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	// Tests run inside the module itself, which has no version of its own
+	if got := Version(); got != "(devel)" {
+		t.Errorf("Version() = %q, want (devel)", got)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	f := Features()
+	if f.Version != Version() {
+		t.Errorf("Version = %q, want %q", f.Version, Version())
+	}
+	if f.SIMD {
+		t.Error("SIMD reported for the portable lexer")
+	}
+	if f.Plugins == f.Minimal {
+		t.Errorf("Plugins = %v with Minimal = %v, want them opposite", f.Plugins, f.Minimal)
+	}
+	if f.Query == "" {
+		t.Error("no query language reported")
+	}
+	if len(f.Dialects) < 2 || f.Dialects[0] != "jsonc" || f.Dialects[1] != "json5" {
+		t.Errorf("Dialects = %q, want jsonc and json5 first", f.Dialects)
+	}
+	if slices.Contains(f.Dialects, "") {
+		t.Errorf("Dialects = %q, want no empty names", f.Dialects)
+	}
+}